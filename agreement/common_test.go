@@ -28,6 +28,7 @@ import (
 
 	"github.com/algorand/go-algorand/config"
 	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/data/account"
 	"github.com/algorand/go-algorand/data/basics"
 	"github.com/algorand/go-algorand/data/bookkeeping"
 	"github.com/algorand/go-algorand/data/committee"
@@ -375,6 +376,11 @@ type testAccountData struct {
 	ots       []crypto.OneTimeSigner
 }
 
+// testSigner wraps in-memory participation secrets in a ParticipationSigner.
+func testSigner(voting crypto.OneTimeSigner, selection *crypto.VRFSecrets) account.ParticipationSigner {
+	return account.LocalSigner{OneTimeSigner: voting, VRF: selection}
+}
+
 func makeProposalsTesting(accs testAccountData, round basics.Round, period period, factory BlockFactory, ledger Ledger) (ps []proposal, vs []vote) {
 	ve, err := factory.AssembleBlock(round, time.Now().Add(time.Minute))
 	if err != nil {
@@ -386,7 +392,7 @@ func makeProposalsTesting(accs testAccountData, round basics.Round, period perio
 	var votes []vote
 	proposals := make([]proposal, 0)
 	for i := range accs.addresses {
-		payload, proposal, err := proposalForBlock(accs.addresses[i], accs.vrfs[i], ve, period, ledger)
+		payload, proposal, err := proposalForBlock(accs.addresses[i], testSigner(accs.ots[i], accs.vrfs[i]), ve, period, ledger)
 		if err != nil {
 			logging.Base().Errorf("proposalForBlock could not create proposal under address %v (corrupt VRF key?): %v", accs.addresses[i], err)
 			return
@@ -394,7 +400,7 @@ func makeProposalsTesting(accs testAccountData, round basics.Round, period perio
 
 		// attempt to make the vote
 		rv := rawVote{Sender: accs.addresses[i], Round: round, Period: period, Step: propose, Proposal: proposal}
-		uv, err := makeVote(rv, accs.ots[i], accs.vrfs[i], ledger)
+		uv, err := makeVote(rv, testSigner(accs.ots[i], accs.vrfs[i]), ledger)
 		if err != nil {
 			logging.Base().Errorf("AccountManager.makeVotes: Could not create vote: %v", err)
 			return
@@ -418,7 +424,7 @@ func makeVotesTesting(accs testAccountData, round basics.Round, period period, s
 	votes := make([]vote, 0)
 	for i := range accs.addresses {
 		rv := rawVote{Sender: accs.addresses[i], Round: round, Period: period, Step: step, Proposal: proposal}
-		uv, err := makeVote(rv, accs.ots[i], accs.vrfs[i], ledger)
+		uv, err := makeVote(rv, testSigner(accs.ots[i], accs.vrfs[i]), ledger)
 		if err != nil {
 			logging.Base().Errorf("AccountManager.makeVotes: Could not create vote: %v", err)
 			return
@@ -52,3 +52,15 @@ type NetworkFilterFactory interface {
 }
 
 var registeredFilterFactories = []NetworkFilterFactory{}
+
+// UnmarshalFilter creates a network filter factory out of its JSON
+// description, as found in the testdata configurations. It returns nil if
+// none of the registered filters recognizes the description.
+func UnmarshalFilter(b []byte) NetworkFilterFactory {
+	for _, regFactory := range registeredFilterFactories {
+		if filterFactory := regFactory.Unmarshal(b); filterFactory != nil {
+			return filterFactory
+		}
+	}
+	return nil
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/algorand/go-algorand/agreement"
@@ -38,14 +39,23 @@ const minMoneyAtStart = 10000
 const maxMoneyAtStart = 100000
 
 var readOnlyParticipationVotes = []*crypto.OneTimeSignatureSecrets{}
+var readOnlyParticipationVotesOnce sync.Once
 
 func init() {
 	rand.Seed(randseed)
-	for i := 0; i < 64; i++ {
-		prngSeed := []byte(fmt.Sprintf("Fuzzer-OTSS-PRNG-%d", i))
-		rng := crypto.MakePRNG(prngSeed)
-		readOnlyParticipationVotes = append(readOnlyParticipationVotes, crypto.GenerateOneTimeSignatureSecretsRNG(0, 1000, rng))
-	}
+}
+
+// participationVotes returns the voting keys shared by all the fuzzer networks.
+// Generating them is expensive, so it is deferred until the first network is made.
+func participationVotes() []*crypto.OneTimeSignatureSecrets {
+	readOnlyParticipationVotesOnce.Do(func() {
+		for i := 0; i < 64; i++ {
+			prngSeed := []byte(fmt.Sprintf("Fuzzer-OTSS-PRNG-%d", i))
+			rng := crypto.MakePRNG(prngSeed)
+			readOnlyParticipationVotes = append(readOnlyParticipationVotes, crypto.GenerateOneTimeSignatureSecretsRNG(0, 1000, rng))
+		}
+	})
+	return readOnlyParticipationVotes
 }
 
 func generatePseudoRandomVRF(keynum int) *crypto.VRFSecrets {
@@ -113,10 +123,121 @@ func (f testBlockFactory) AssembleBlock(r basics.Round, deadline time.Time) (agr
 	return testValidatedBlock{Inside: bookkeeping.Block{BlockHeader: bookkeeping.BlockHeader{Round: r}}}, nil
 }
 
-type testLedgerSyncFunc func(l *testLedger, r basics.Round, c agreement.Certificate) bool
+// NodeLedger is the ledger a fuzzer node runs agreement against. On top of
+// agreement.Ledger it exposes the hooks the fuzzer uses to keep block
+// synchronization between the nodes in step with the virtual clock.
+type NodeLedger interface {
+	agreement.Ledger
+
+	// ClearNotifications drops the round notifications handed out to an agreement service that crashed.
+	ClearNotifications()
+	// Catchup copies the blocks of source into the ledger until its next round is targetNextRound.
+	Catchup(source NodeLedger, targetNextRound basics.Round)
+
+	IsEnsuringDigest() bool
+	TryEnsuringDigest() bool
+	GetEnsuringDigestCh(start bool) chan struct{}
+}
+
+// LedgerSyncFunc fetches the block certified by c into l from the other nodes.
+// It returns false if none of them has the block yet.
+type LedgerSyncFunc func(l NodeLedger, r basics.Round, c agreement.Certificate) bool
+
+// DigestSync implements the EnsureDigest side of NodeLedger. While a ledger is
+// ensuring a digest the fuzzer holds back the messages of its node, and lets it
+// retry the synchronization whenever another node has made progress.
+type DigestSync struct {
+	EnsuringDigestStartCh chan struct{}
+	EnsuringDigestDoneCh  chan struct{}
+	ensuringDigestMu      deadlock.Mutex
+	ensuringDigest        bool
+	ensuringDigestTry     chan struct{}
+}
+
+// MakeDigestSync creates a DigestSync which isn't ensuring any digest.
+func MakeDigestSync() *DigestSync {
+	s := new(DigestSync)
+	s.EnsuringDigestStartCh = make(chan struct{})
+	s.EnsuringDigestDoneCh = make(chan struct{})
+	close(s.EnsuringDigestDoneCh)
+	s.ensuringDigestTry = make(chan struct{}, 1)
+	return s
+}
+
+// EnsureDigest calls sync until it succeeds, blocking between the attempts
+// until the fuzzer signals that the node should try again. It gives up once
+// quit is closed.
+func (s *DigestSync) EnsureDigest(sync func() bool, quit chan struct{}) {
+	// try without any locks.
+	if sync() {
+		return
+	}
+
+	s.ensuringDigestMu.Lock()
+	s.ensuringDigest = true
+	s.EnsuringDigestDoneCh = make(chan struct{})
+	close(s.EnsuringDigestStartCh)
+	select {
+	case <-s.ensuringDigestTry:
+	default:
+	}
+	s.ensuringDigestMu.Unlock()
+
+	for exitSync := false; exitSync == false; {
+		if sync() {
+			exitSync = true
+			continue
+		}
+		select {
+		case <-s.ensuringDigestTry:
+		case <-quit:
+			exitSync = true
+		}
+	}
+
+	s.ensuringDigestMu.Lock()
+	select {
+	case <-s.ensuringDigestTry:
+	default:
+	}
+	s.ensuringDigest = false
+	close(s.EnsuringDigestDoneCh)
+	s.EnsuringDigestStartCh = make(chan struct{})
+	s.ensuringDigestMu.Unlock()
+}
+
+// TryEnsuringDigest wakes up a pending EnsureDigest for another attempt.
+func (s *DigestSync) TryEnsuringDigest() bool {
+	s.ensuringDigestMu.Lock()
+	defer s.ensuringDigestMu.Unlock()
+	select {
+	case s.ensuringDigestTry <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// GetEnsuringDigestCh returns the channel closed when the ledger starts ensuring a digest, or when it is done.
+func (s *DigestSync) GetEnsuringDigestCh(start bool) chan struct{} {
+	s.ensuringDigestMu.Lock()
+	defer s.ensuringDigestMu.Unlock()
+	if start {
+		return s.EnsuringDigestStartCh
+	}
+	return s.EnsuringDigestDoneCh
+}
+
+// IsEnsuringDigest returns true while an EnsureDigest is blocked waiting for the block.
+func (s *DigestSync) IsEnsuringDigest() bool {
+	s.ensuringDigestMu.Lock()
+	defer s.ensuringDigestMu.Unlock()
+	return s.ensuringDigest
+}
 
 // If we try to read from high rounds, we panic and do not emit an error to find bugs during testing.
 type testLedger struct {
+	*DigestSync
 	mu deadlock.Mutex
 
 	entries   map[basics.Round]bookkeeping.Block
@@ -128,16 +249,12 @@ type testLedger struct {
 
 	notifications map[basics.Round]signal
 
-	Sync                  testLedgerSyncFunc
-	EnsuringDigestStartCh chan struct{}
-	EnsuringDigestDoneCh  chan struct{}
-	ensuringDigestMu      deadlock.Mutex
-	ensuringDigest        bool
-	ensuringDigestTry     chan struct{}
+	Sync LedgerSyncFunc
 }
 
-func makeTestLedger(state map[basics.Address]basics.BalanceRecord, sync testLedgerSyncFunc) *testLedger {
+func makeTestLedger(state map[basics.Address]basics.BalanceRecord, sync LedgerSyncFunc) *testLedger {
 	l := new(testLedger)
+	l.DigestSync = MakeDigestSync()
 	l.Sync = sync
 	l.entries = make(map[basics.Round]bookkeeping.Block)
 	l.certs = make(map[basics.Round]agreement.Certificate)
@@ -150,10 +267,6 @@ func makeTestLedger(state map[basics.Address]basics.BalanceRecord, sync testLedg
 	}
 
 	l.notifications = make(map[basics.Round]signal)
-	l.EnsuringDigestStartCh = make(chan struct{})
-	l.EnsuringDigestDoneCh = make(chan struct{})
-	close(l.EnsuringDigestDoneCh)
-	l.ensuringDigestTry = make(chan struct{}, 1)
 
 	return l
 }
@@ -299,38 +412,9 @@ func (l *testLedger) EnsureDigest(c agreement.Certificate, quit chan struct{}, v
 	if consistencyCheck() {
 		return
 	}
-	// try without any locks.
-	if l.Sync(l, r, c) {
-		return
-	}
-
-	l.ensuringDigestMu.Lock()
-	l.ensuringDigest = true
-	l.EnsuringDigestDoneCh = make(chan struct{})
-	close(l.EnsuringDigestStartCh)
-	select {
-	case <-l.ensuringDigestTry:
-	default:
-	}
-	l.ensuringDigestMu.Unlock()
-
-	for exitSync := false; exitSync == false; {
-		if l.Sync(l, r, c) {
-			exitSync = true
-			continue
-		}
-		<-l.ensuringDigestTry
-	}
-
-	l.ensuringDigestMu.Lock()
-	select {
-	case <-l.ensuringDigestTry:
-	default:
-	}
-	l.ensuringDigest = false
-	close(l.EnsuringDigestDoneCh)
-	l.EnsuringDigestStartCh = make(chan struct{})
-	l.ensuringDigestMu.Unlock()
+	l.DigestSync.EnsureDigest(func() bool {
+		return l.Sync(l, r, c)
+	}, quit)
 }
 
 func (l *testLedger) ConsensusParams(r basics.Round) (config.ConsensusParams, error) {
@@ -341,33 +425,8 @@ func (l *testLedger) ConsensusVersion(r basics.Round) (protocol.ConsensusVersion
 	return protocol.ConsensusV2, nil
 }
 
-func (l *testLedger) TryEnsuringDigest() bool {
-	l.ensuringDigestMu.Lock()
-	defer l.ensuringDigestMu.Unlock()
-	select {
-	case l.ensuringDigestTry <- struct{}{}:
-		return true
-	default:
-		return false
-	}
-}
-
-func (l *testLedger) GetEnsuringDigestCh(start bool) chan struct{} {
-	l.ensuringDigestMu.Lock()
-	defer l.ensuringDigestMu.Unlock()
-	if start {
-		return l.EnsuringDigestStartCh
-	}
-	return l.EnsuringDigestDoneCh
-}
-
-func (l *testLedger) IsEnsuringDigest() bool {
-	l.ensuringDigestMu.Lock()
-	defer l.ensuringDigestMu.Unlock()
-	return l.ensuringDigest
-}
-
-func (l *testLedger) Catchup(o *testLedger, targetNextRound basics.Round) {
+func (l *testLedger) Catchup(source NodeLedger, targetNextRound basics.Round) {
+	o := source.(*testLedger)
 	l.mu.Lock()
 	o.mu.Lock()
 
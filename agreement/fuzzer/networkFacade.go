@@ -38,6 +38,9 @@ import (
 
 var maxEventQueueWait = time.Second * 3
 
+// demuxEventsQueue is the events queue the agreement demux reports as empty right before it waits for its next event.
+const demuxEventsQueue = "demux"
+
 type NetworkFacadeMessage struct {
 	tag    protocol.Tag
 	data   []byte
@@ -57,6 +60,7 @@ type NetworkFacade struct {
 	clockSync                      deadlock.Mutex
 	zeroClock                      int
 	clocks                         map[int]chan time.Time
+	requestedClocks                map[int]bool
 	pendingOutgoingMsg             []NetworkFacadeMessage
 	pendingOutgoingMsgMu           deadlock.Mutex
 	pendingIncomingMsg             []NetworkFacadeMessage
@@ -75,15 +79,16 @@ type NetworkFacade struct {
 // MakeNetworkFacade creates a facade with a given nodeID.
 func MakeNetworkFacade(fuzzer *Fuzzer, nodeID int) *NetworkFacade {
 	n := &NetworkFacade{
-		fuzzer:         fuzzer,
-		nodeID:         nodeID,
-		mux:            network.MakeMultiplexer(),
-		clocks:         make(map[int]chan time.Time),
-		eventsQueues:   make(map[string]int),
-		eventsQueuesCh: make(chan int, 1000),
-		rand:           rand.New(rand.NewSource(int64(nodeID))),
-		peerToNode:     make(map[network.Peer]int, fuzzer.nodesCount),
-		debugMessages:  false,
+		fuzzer:          fuzzer,
+		nodeID:          nodeID,
+		mux:             network.MakeMultiplexer(),
+		clocks:          make(map[int]chan time.Time),
+		requestedClocks: make(map[int]bool),
+		eventsQueues:    make(map[string]int),
+		eventsQueuesCh:  make(chan int, 1000),
+		rand:            rand.New(rand.NewSource(int64(nodeID))),
+		peerToNode:      make(map[network.Peer]int, fuzzer.nodesCount),
+		debugMessages:   false,
 	}
 	n.timeoutAtInitWait.Add(1)
 	for i := 0; i < fuzzer.nodesCount; i++ {
@@ -103,6 +108,9 @@ func (n *NetworkFacade) ResetWaitForTimeoutAt() {
 }
 
 func (n *NetworkFacade) UpdateEventsQueue(queueName string, queueLength int) {
+	if queueName == demuxEventsQueue && queueLength == 0 {
+		n.pruneClocks()
+	}
 	n.eventsQueuesMu.Lock()
 	n.eventsQueues[queueName] = queueLength
 	sum := 0
@@ -113,6 +121,20 @@ func (n *NetworkFacade) UpdateEventsQueue(queueName string, queueLength int) {
 	n.eventsQueuesCh <- sum
 }
 
+// pruneClocks drops the clocks the demux no longer waits on. The demux asks for
+// its deadlines right before it waits for its next event, so the clocks asked for
+// since it last waited are exactly the ones it is waiting on now.
+func (n *NetworkFacade) pruneClocks() {
+	n.clockSync.Lock()
+	defer n.clockSync.Unlock()
+	for targetTick := range n.clocks {
+		if !n.requestedClocks[targetTick] {
+			delete(n.clocks, targetTick)
+		}
+	}
+	n.requestedClocks = make(map[int]bool)
+}
+
 func (n *NetworkFacade) DumpQueues() {
 	queues := "----------------------\n"
 	n.eventsQueuesMu.Lock()
@@ -121,7 +143,7 @@ func (n *NetworkFacade) DumpQueues() {
 	}
 	n.eventsQueuesMu.Unlock()
 	queues += "----------------------\n"
-	fmt.Printf(queues)
+	fmt.Print(queues)
 }
 
 func (n *NetworkFacade) WaitForEventsQueue(cleared bool) {
@@ -357,6 +379,7 @@ func (n *NetworkFacade) Zero() timers.Clock {
 	// on any of the clocks.
 
 	n.clocks = make(map[int]chan time.Time)
+	n.requestedClocks = make(map[int]bool)
 	if n.debugMessages {
 		fmt.Printf("NetworkFacade service-%v zero clock = %d\n", n.nodeID, n.zeroClock)
 	}
@@ -380,6 +403,7 @@ func (n *NetworkFacade) TimeoutAt(d time.Duration) <-chan time.Time {
 	defer n.clockSync.Unlock()
 
 	targetTick := int(d / n.fuzzer.tickGranularity)
+	n.requestedClocks[targetTick] = true
 	ch, have := n.clocks[targetTick]
 	if have {
 		return ch
@@ -409,6 +433,7 @@ func (n *NetworkFacade) Decode(in []byte) (timers.Clock, error) {
 	defer n.clockSync.Unlock()
 
 	n.clocks = make(map[int]chan time.Time)
+	n.requestedClocks = make(map[int]bool)
 	buf := bytes.NewReader(in)
 	var encodedZero int32
 
@@ -475,7 +500,8 @@ func (n *NetworkFacade) Tick(newClockTime int) bool {
 		delete(n.clocks, targetTick)
 		//fmt.Printf("Node %v clock %v reached\n", n.nodeID, targetTick)
 	}
-	if len(expiredClocks) > 0 && len(n.clocks) == 0 {
+	// the demux waits on all of its clocks at once, so it wakes up as soon as any of them expires.
+	if len(expiredClocks) > 0 {
 		func() {
 			n.clockSync.Unlock()
 			defer n.clockSync.Lock()
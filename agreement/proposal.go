@@ -21,6 +21,7 @@ import (
 	"fmt"
 
 	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/data/account"
 	"github.com/algorand/go-algorand/data/basics"
 	"github.com/algorand/go-algorand/data/bookkeeping"
 	"github.com/algorand/go-algorand/data/committee"
@@ -123,7 +124,7 @@ func (i seedInput) ToBeHashed() (protocol.HashID, []byte) {
 	return protocol.ProposerSeed, protocol.Encode(i)
 }
 
-func deriveNewSeed(address basics.Address, vrf *crypto.VRFSecrets, rnd round, period period, ledger LedgerReader) (newSeed committee.Seed, seedProof crypto.VRFProof, reterr error) {
+func deriveNewSeed(address basics.Address, vrf account.ParticipationSigner, rnd round, period period, ledger LedgerReader) (newSeed committee.Seed, seedProof crypto.VRFProof, reterr error) {
 	var ok bool
 	var vrfOut crypto.VrfOutput
 
@@ -141,7 +142,7 @@ func deriveNewSeed(address basics.Address, vrf *crypto.VRFSecrets, rnd round, pe
 		}
 
 		if period == 0 {
-			seedProof, ok = vrf.SK.Prove(prevSeed)
+			seedProof, ok = vrf.ProveVRF(prevSeed)
 			if !ok {
 				reterr = fmt.Errorf("could not make seed proof")
 				return
@@ -192,7 +193,7 @@ func deriveNewSeed(address basics.Address, vrf *crypto.VRFSecrets, rnd round, pe
 			// To an adversary trying to predict (or influence) future seeds, as soon as there's an honest proposer the seed becomes completely rerandomized.
 			// This is because a VRF output is pseudorandom to anyone without the secret key or the corresponding proof.
 			// The adversary's ability to influence the seed is also limited because of the uniqueness property of the VRF.
-			seedProof, ok = vrf.SK.Prove(prevSeed)
+			seedProof, ok = vrf.ProveVRF(prevSeed)
 			if !ok {
 				reterr = fmt.Errorf("Could not make seed proof")
 				return
@@ -330,7 +331,7 @@ func verifyNewSeed(p unauthenticatedProposal, ledger LedgerReader) error {
 	return nil
 }
 
-func proposalForBlock(address basics.Address, vrf *crypto.VRFSecrets, ve ValidatedBlock, period period, ledger LedgerReader) (proposal, proposalValue, error) {
+func proposalForBlock(address basics.Address, vrf account.ParticipationSigner, ve ValidatedBlock, period period, ledger LedgerReader) (proposal, proposalValue, error) {
 	rnd := ve.Block().Round()
 	newSeed, seedProof, err := deriveNewSeed(address, vrf, rnd, period, ledger)
 	if err != nil {
@@ -56,11 +56,11 @@ func createProposalsTesting(accs testAccountData, round basics.Round, period per
 	var votes []vote
 	proposals := make([]proposal, 0)
 	for i := range accs.addresses {
-		payload, proposal, _ := proposalForBlock(accs.addresses[i], accs.vrfs[i], ve, period, ledger)
+		payload, proposal, _ := proposalForBlock(accs.addresses[i], testSigner(accs.ots[i], accs.vrfs[i]), ve, period, ledger)
 
 		// attempt to make the vote
 		rv := rawVote{Sender: accs.addresses[i], Round: round, Period: period, Step: propose, Proposal: proposal}
-		uv, err := makeVote(rv, accs.ots[i], accs.vrfs[i], ledger)
+		uv, err := makeVote(rv, testSigner(accs.ots[i], accs.vrfs[i]), ledger)
 		if err != nil {
 			logging.Base().Errorf("AccountManager.makeVotes: Could not create vote: %v", err)
 			return
@@ -124,7 +124,7 @@ func TestProposalFunctions(t *testing.T) {
 	validator := testBlockValidator{}
 
 	for i := range accs.addresses {
-		proposal, proposalValue, _ := proposalForBlock(accs.addresses[i], accs.vrfs[i], ve, period, ledger)
+		proposal, proposalValue, _ := proposalForBlock(accs.addresses[i], testSigner(accs.ots[i], accs.vrfs[i]), ve, period, ledger)
 
 		//validate returning unauthenticatedProposal from proposalPayload
 		unauthenticatedProposalResult := proposal
@@ -163,7 +163,7 @@ func TestProposalUnauthenticated(t *testing.T) {
 
 	accountIndex := 0
 
-	proposal, _, _ := proposalForBlock(accounts.addresses[accountIndex], accounts.vrfs[accountIndex], testBlockFactory, period, ledger)
+	proposal, _, _ := proposalForBlock(accounts.addresses[accountIndex], testSigner(accounts.ots[accountIndex], accounts.vrfs[accountIndex]), testBlockFactory, period, ledger)
 	accountIndex++
 
 	// validate a good unauthenticated proposal
@@ -182,14 +182,14 @@ func TestProposalUnauthenticated(t *testing.T) {
 	require.NoError(t, err)
 
 	// validate a good unauthenticated proposal
-	proposal, _, _ = proposalForBlock(accounts.addresses[accountIndex], accounts.vrfs[accountIndex], testBlockFactory, period, ledger)
+	proposal, _, _ = proposalForBlock(accounts.addresses[accountIndex], testSigner(accounts.ots[accountIndex], accounts.vrfs[accountIndex]), testBlockFactory, period, ledger)
 	accountIndex++
 	unauthenticatedProposal = proposal.u()
 	block = unauthenticatedProposal.Block
 	require.NotNil(t, block)
 
 	// validate corruption of SeedProof
-	proposal3, _, _ := proposalForBlock(accounts.addresses[accountIndex], accounts.vrfs[accountIndex], testBlockFactory, period, ledger)
+	proposal3, _, _ := proposalForBlock(accounts.addresses[accountIndex], testSigner(accounts.ots[accountIndex], accounts.vrfs[accountIndex]), testBlockFactory, period, ledger)
 	accountIndex++
 	unauthenticatedProposal3 := proposal3.u()
 	unauthenticatedProposal3.SeedProof = unauthenticatedProposal.SeedProof
@@ -20,6 +20,7 @@ import (
 	"fmt"
 
 	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/data/account"
 	"github.com/algorand/go-algorand/data/basics"
 	"github.com/algorand/go-algorand/data/committee"
 	"github.com/algorand/go-algorand/logging"
@@ -141,7 +142,7 @@ func (uv unauthenticatedVote) verify(l LedgerReader) (vote, error) {
 // makeVote creates a new unauthenticated vote from its constituent components.
 //
 // makeVote returns an error it it fails.
-func makeVote(rv rawVote, voting crypto.OneTimeSigner, selection *crypto.VRFSecrets, l Ledger) (unauthenticatedVote, error) {
+func makeVote(rv rawVote, signer account.ParticipationSigner, l Ledger) (unauthenticatedVote, error) {
 	m, err := membership(l, rv.Sender, rv.Round, rv.Period, rv.Step)
 	if err != nil {
 		return unauthenticatedVote{}, fmt.Errorf("makeVote: could not get membership parameters: %v", err)
@@ -172,13 +173,17 @@ func makeVote(rv rawVote, voting crypto.OneTimeSigner, selection *crypto.VRFSecr
 		}
 	}
 
-	ephID := basics.OneTimeIDForRound(rv.Round, voting.KeyDilution(proto))
-	sig := voting.Sign(ephID, proto.FineGrainedEphemeralKeys, rv)
+	ephID := basics.OneTimeIDForRound(rv.Round, signer.KeyDilution(proto))
+	sig := signer.Sign(ephID, proto.FineGrainedEphemeralKeys, rv)
 	if (sig == crypto.OneTimeSignature{}) {
 		return unauthenticatedVote{}, fmt.Errorf("makeVote: got back empty signature for vote")
 	}
 
-	cred := committee.MakeCredential(&selection.SK, m.Selector)
+	pf, ok := signer.ProveVRF(m.Selector)
+	if !ok {
+		return unauthenticatedVote{}, fmt.Errorf("makeVote: could not construct a VRF proof for vote")
+	}
+	cred := committee.UnauthenticatedCredential{Proof: pf}
 	return unauthenticatedVote{R: rv, Cred: cred, Sig: sig}, nil
 }
 
@@ -0,0 +1,208 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package catchup
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/algorand/go-algorand/agreement"
+	"github.com/algorand/go-algorand/config"
+	"github.com/algorand/go-algorand/data"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/ledger"
+	"github.com/algorand/go-algorand/logging"
+	"github.com/algorand/go-algorand/network"
+	"github.com/algorand/go-algorand/rpcs"
+)
+
+// CatchpointService brings the ledger up to a catchpoint without
+// replaying all the blocks before it.  It downloads the catchpoint
+// from a peer, checks it against a trusted label, and fetches only
+// the blocks that the ledger needs to continue from there.
+type CatchpointService struct {
+	ctx            context.Context
+	cancel         func()
+	done           chan struct{}
+	log            logging.Logger
+	net            network.GossipNode
+	ledger         *data.Ledger
+	fetcherFactory rpcs.FetcherFactory
+	label          ledger.CatchpointLabel
+}
+
+// MakeCatchpointService creates a catchpoint catchup service for the given label
+func MakeCatchpointService(log logging.Logger, net network.GossipNode, ledger *data.Ledger, label ledger.CatchpointLabel) *CatchpointService {
+	cs := &CatchpointService{
+		log:            log.With("Context", "catchpoint"),
+		net:            net,
+		ledger:         ledger,
+		fetcherFactory: rpcs.MakeNetworkFetcherFactory(net, catchupPeersForSync, nil),
+		label:          label,
+		done:           make(chan struct{}),
+	}
+	cs.ctx, cs.cancel = context.WithCancel(context.Background())
+	return cs
+}
+
+// Run catches up to the catchpoint, returning once the ledger has
+// reached the catchpoint round or catching up failed.
+func (cs *CatchpointService) Run() error {
+	defer close(cs.done)
+
+	cs.log.Infof("catching up to catchpoint %v", cs.label)
+	cp, err := cs.fetchCatchpoint()
+	if err != nil {
+		return err
+	}
+
+	blocks, certs, err := cs.fetchBlocks(cp)
+	if err != nil {
+		return err
+	}
+
+	// blocks[i] is for round first+i; the ledger is installed with the
+	// blocks up to the catchpoint balances round, and the rest are
+	// replayed on top of it.
+	first := blocks[0].Round()
+	split := int(cp.Header.BalancesRound-first) + 1
+	err = cs.ledger.InstallCatchpoint(cp, blocks[:split], certs[:split])
+	if err != nil {
+		return fmt.Errorf("could not install catchpoint %v: %v", cs.label, err)
+	}
+
+	for i := split; i < len(blocks); i++ {
+		err = cs.ledger.AddBlock(blocks[i], certs[i])
+		if err != nil {
+			return fmt.Errorf("could not add block %d after catchpoint: %v", blocks[i].Round(), err)
+		}
+	}
+
+	cs.log.Infof("caught up to catchpoint %v", cs.label)
+	return nil
+}
+
+// Stop aborts catching up, and waits for Run to return
+func (cs *CatchpointService) Stop() {
+	cs.cancel()
+	<-cs.done
+}
+
+// fetchCatchpoint downloads the catchpoint from the first peer that
+// has a copy matching the label.
+func (cs *CatchpointService) fetchCatchpoint() (*ledger.Catchpoint, error) {
+	peers := cs.net.GetPeers(network.PeersPhonebook)
+	for _, peer := range peers {
+		hp, ok := peer.(network.HTTPPeer)
+		if !ok {
+			continue
+		}
+
+		cp, err := rpcs.FetchCatchpoint(cs.ctx, cs.log, hp, cs.label.Round)
+		if err != nil {
+			cs.log.Infof("could not fetch catchpoint %d from %s: %v", cs.label.Round, hp.GetAddress(), err)
+			continue
+		}
+
+		if cp.Header.Round != cs.label.Round || cp.Digest != cs.label.Digest {
+			cs.log.Warnf("catchpoint from %s does not match %v", hp.GetAddress(), cs.label)
+			continue
+		}
+
+		return cp, nil
+	}
+
+	select {
+	case <-cs.ctx.Done():
+		return nil, cs.ctx.Err()
+	default:
+	}
+	return nil, fmt.Errorf("no peer had catchpoint %v (out of %d peers)", cs.label, len(peers))
+}
+
+// fetchBlocks fetches the blocks that the ledger needs along with the
+// catchpoint, going backwards from the catchpoint round.  The blocks
+// are authenticated by the block hash in the catchpoint rather than by
+// their certificates, since checking the certificates requires the
+// balances that the catchpoint replaces.
+func (cs *CatchpointService) fetchBlocks(cp *ledger.Catchpoint) ([]bookkeeping.Block, []agreement.Certificate, error) {
+	fetcher := cs.fetcherFactory.New()
+	defer fetcher.Close()
+
+	var blocks []bookkeeping.Block
+	var certs []agreement.Certificate
+
+	expectedHash := cp.Header.BlockHash
+	first := basics.Round(0)
+	for r := cp.Header.Round; ; r-- {
+		blk, cert, err := cs.fetchBlock(fetcher, r, expectedHash)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		blocks = append(blocks, blk)
+		certs = append(certs, cert)
+		expectedHash = blk.Branch
+
+		// The ledger needs the blocks for the last MaxTxnLife rounds
+		// to detect duplicate transactions after the catchpoint.
+		if r == cp.Header.BalancesRound {
+			proto := config.Consensus[blk.CurrentProtocol]
+			first = (r + 1).SubSaturate(basics.Round(proto.MaxTxnLife))
+		}
+		if r <= cp.Header.BalancesRound && r <= first {
+			break
+		}
+	}
+
+	// reverse into round order
+	for i, j := 0, len(blocks)-1; i < j; i, j = i+1, j-1 {
+		blocks[i], blocks[j] = blocks[j], blocks[i]
+		certs[i], certs[j] = certs[j], certs[i]
+	}
+	return blocks, certs, nil
+}
+
+// fetchBlock fetches the block for round r, which must hash to expectedHash.
+func (cs *CatchpointService) fetchBlock(fetcher rpcs.Fetcher, r basics.Round, expectedHash bookkeeping.BlockHash) (bookkeeping.Block, agreement.Certificate, error) {
+	for !fetcher.OutOfPeers(r) {
+		ctx, cf := context.WithTimeout(cs.ctx, rpcs.DefaultFetchTimeout)
+		blk, cert, _, err := fetcher.FetchBlock(ctx, r)
+		cf()
+
+		select {
+		case <-cs.ctx.Done():
+			return bookkeeping.Block{}, agreement.Certificate{}, cs.ctx.Err()
+		default:
+		}
+
+		if err != nil {
+			cs.log.Debugf("fetchBlock(%d): could not fetch: %v", r, err)
+			continue
+		}
+
+		if blk.Round() != r || blk.Hash() != expectedHash || !blk.ContentsMatchHeader() {
+			cs.log.Warnf("fetchBlock(%d): block does not match catchpoint %v", r, cs.label)
+			continue
+		}
+
+		return *blk, *cert, nil
+	}
+
+	return bookkeeping.Block{}, agreement.Certificate{}, fmt.Errorf("could not fetch block %d for catchpoint %v", r, cs.label)
+}
@@ -0,0 +1,87 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+// algosim runs a simulated network of full nodes through a YAML scenario and
+// reports the finality latency, the forks and the stalls the network went
+// through. See tools/simulator/testdata for an example scenario.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/algorand/go-deadlock"
+
+	"github.com/algorand/go-algorand/tools/simulator"
+)
+
+var scenarioFile = flag.String("s", "", "Scenario file (YAML)")
+var dataDir = flag.String("d", "", "Directory for the logs and traces of the nodes. Default is a temporary directory, removed once done.")
+var jsonOutput = flag.Bool("json", false, "Write the report as JSON")
+
+func main() {
+	deadlock.Opts.Disable = true
+
+	flag.Parse()
+
+	if *scenarioFile == "" {
+		fmt.Fprintf(os.Stderr, "No scenario file specified (-s)\n")
+		flag.Usage()
+		os.Exit(1)
+	}
+	scenario, err := simulator.LoadScenario(*scenarioFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	dir := *dataDir
+	if dir == "" {
+		dir, err = ioutil.TempDir("", "algosim")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot create a data directory: %v\n", err)
+			os.Exit(1)
+		}
+		defer os.RemoveAll(dir)
+	} else if err = os.MkdirAll(dir, 0700); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot create data directory %s: %v\n", dir, err)
+		os.Exit(1)
+	}
+
+	sim, err := simulator.MakeSimulator(scenario, dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		if *dataDir == "" {
+			os.RemoveAll(dir)
+		}
+		os.Exit(1)
+	}
+	report := sim.Run()
+	sim.Shutdown()
+
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err = enc.Encode(report); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		}
+		return
+	}
+	report.WriteText(os.Stdout)
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/algorand/go-algorand/config"
 	"github.com/algorand/go-algorand/crypto"
 	"github.com/algorand/go-algorand/crypto/passphrase"
+	"github.com/algorand/go-algorand/daemon/algod/api/client/models"
 	algodAcct "github.com/algorand/go-algorand/data/account"
 	"github.com/algorand/go-algorand/data/basics"
 	"github.com/algorand/go-algorand/data/transactions"
@@ -58,6 +59,7 @@ var (
 	partKeyOutDir      string
 	importDefault      bool
 	mnemonic           string
+	balanceRound       uint64
 )
 
 func init() {
@@ -66,6 +68,7 @@ func init() {
 	accountCmd.AddCommand(listCmd)
 	accountCmd.AddCommand(renameCmd)
 	accountCmd.AddCommand(balanceCmd)
+	accountCmd.AddCommand(accountInfoCmd)
 	accountCmd.AddCommand(rewardsCmd)
 	accountCmd.AddCommand(changeOnlineCmd)
 	accountCmd.AddCommand(addParticipationKeyCmd)
@@ -112,6 +115,11 @@ func init() {
 	// Balance flags
 	balanceCmd.Flags().StringVarP(&accountAddress, "address", "a", "", "Account address to retrieve balance (required)")
 	balanceCmd.MarkFlagRequired("address")
+	balanceCmd.Flags().Uint64VarP(&balanceRound, "round", "r", 0, "Retrieve the balance as of this round, rather than the latest one")
+
+	// Info flags
+	accountInfoCmd.Flags().StringVarP(&accountAddress, "address", "a", "", "Account address to look up (required)")
+	accountInfoCmd.MarkFlagRequired("address")
 
 	// Rewards flags
 	rewardsCmd.Flags().StringVarP(&accountAddress, "address", "a", "", "Account address to retrieve rewards (required)")
@@ -439,7 +447,13 @@ var balanceCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		dataDir := ensureSingleDataDir()
 		client := ensureAlgodClient(dataDir)
-		response, err := client.AccountInformation(accountAddress)
+		var response models.Account
+		var err error
+		if cmd.Flags().Changed("round") {
+			response, err = client.AccountInformationAtRound(accountAddress, balanceRound)
+		} else {
+			response, err = client.AccountInformation(accountAddress)
+		}
 		if err != nil {
 			reportErrorf(errorRequestFail, err)
 		}
@@ -448,6 +462,55 @@ var balanceCmd = &cobra.Command{
 	},
 }
 
+var accountInfoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Retrieve information about the assets belonging to the specified account",
+	Long:  `Retrieve information about the assets the specified account has created or opted into.`,
+	Args:  validateNoPosArgsFn,
+	Run: func(cmd *cobra.Command, args []string) {
+		dataDir := ensureSingleDataDir()
+		client := ensureAlgodClient(dataDir)
+		response, err := client.AccountInformation(accountAddress)
+		if err != nil {
+			reportErrorf(errorRequestFail, err)
+		}
+
+		createdAssets := make([]uint64, 0, len(response.AssetParams))
+		for idx := range response.AssetParams {
+			createdAssets = append(createdAssets, idx)
+		}
+		sort.Slice(createdAssets, func(i, j int) bool { return createdAssets[i] < createdAssets[j] })
+
+		fmt.Println("Created assets:")
+		if len(createdAssets) == 0 {
+			fmt.Println("\t<none>")
+		}
+		for _, idx := range createdAssets {
+			params := response.AssetParams[idx]
+			fmt.Printf("\tID %d, %s, supply %d %s\n", idx, params.AssetName, params.Total, params.UnitName)
+		}
+
+		heldAssets := make([]uint64, 0, len(response.Assets))
+		for idx := range response.Assets {
+			heldAssets = append(heldAssets, idx)
+		}
+		sort.Slice(heldAssets, func(i, j int) bool { return heldAssets[i] < heldAssets[j] })
+
+		fmt.Println("Assets:")
+		if len(heldAssets) == 0 {
+			fmt.Println("\t<none>")
+		}
+		for _, idx := range heldAssets {
+			holding := response.Assets[idx]
+			frozen := ""
+			if holding.Frozen {
+				frozen = " (frozen)"
+			}
+			fmt.Printf("\tID %d, creator %s, balance %d%s\n", idx, holding.Creator, holding.Amount, frozen)
+		}
+	},
+}
+
 var rewardsCmd = &cobra.Command{
 	Use:   "rewards",
 	Short: "Retrieve the rewards for the specified account",
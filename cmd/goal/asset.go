@@ -0,0 +1,500 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/spf13/cobra"
+
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/libgoal"
+	"github.com/algorand/go-algorand/protocol"
+)
+
+var (
+	assetID                 uint64
+	assetCreator            string
+	assetTotal              uint64
+	assetUnitName           string
+	assetName               string
+	assetURL                string
+	assetMetadataHashBase64 string
+	assetManager            string
+	assetReserve            string
+	assetClawback           string
+	assetFreezer            string
+	assetNoManager          bool
+	assetNoReserve          bool
+	assetNoFreezer          bool
+	assetNoClawback         bool
+	assetDefaultFrozen      bool
+	assetFrozen             bool
+
+	assetNewManager  string
+	assetNewReserve  string
+	assetNewFreezer  string
+	assetNewClawback string
+)
+
+func init() {
+	assetCmd.AddCommand(createAssetCmd)
+	assetCmd.AddCommand(destroyAssetCmd)
+	assetCmd.AddCommand(configAssetCmd)
+	assetCmd.AddCommand(sendAssetCmd)
+	assetCmd.AddCommand(infoAssetCmd)
+	assetCmd.AddCommand(freezeAssetCmd)
+
+	// Wallet to be used for the asset operation
+	assetCmd.PersistentFlags().StringVarP(&walletName, "wallet", "w", "", "Set the wallet to be used for the selected operation")
+
+	createAssetCmd.Flags().StringVar(&assetCreator, "creator", "", "Account address for creating an asset")
+	createAssetCmd.Flags().Uint64Var(&assetTotal, "total", 0, "Total amount of tokens for created asset")
+	createAssetCmd.Flags().BoolVar(&assetDefaultFrozen, "defaultfrozen", false, "Freeze or not freeze holdings by default")
+	createAssetCmd.Flags().StringVar(&assetUnitName, "unitname", "", "Name for the unit of asset")
+	createAssetCmd.Flags().StringVar(&assetName, "name", "", "Name for the entire asset")
+	createAssetCmd.Flags().StringVar(&assetURL, "asseturl", "", "URL where user can access more information about the asset")
+	createAssetCmd.Flags().StringVar(&assetMetadataHashBase64, "assetmetadatab64", "", "base-64 encoded 32-byte commitment to asset metadata")
+	createAssetCmd.Flags().StringVar(&assetManager, "manager", "", "Manager account that can change or destroy the asset (defaults to the creator)")
+	createAssetCmd.Flags().StringVar(&assetReserve, "reserve", "", "Reserve account that holds non-minted units of the asset (defaults to the creator)")
+	createAssetCmd.Flags().StringVar(&assetFreezer, "freezer", "", "Freeze account that can freeze or unfreeze holdings (defaults to the creator)")
+	createAssetCmd.Flags().StringVar(&assetClawback, "clawback", "", "Clawback account that can revoke holdings (defaults to the creator)")
+	createAssetCmd.Flags().BoolVar(&assetNoManager, "no-manager", false, "Create the asset without a manager account")
+	createAssetCmd.Flags().BoolVar(&assetNoReserve, "no-reserve", false, "Create the asset without a reserve account")
+	createAssetCmd.Flags().BoolVar(&assetNoFreezer, "no-freezer", false, "Create the asset without a freeze account")
+	createAssetCmd.Flags().BoolVar(&assetNoClawback, "no-clawback", false, "Create the asset without a clawback account")
+	createAssetCmd.MarkFlagRequired("total")
+	createAssetCmd.MarkFlagRequired("creator")
+
+	destroyAssetCmd.Flags().StringVar(&assetManager, "manager", "", "Manager account to issue the destroy transaction (defaults to creator)")
+	destroyAssetCmd.Flags().StringVar(&assetCreator, "creator", "", "Account address for asset to destroy")
+	destroyAssetCmd.Flags().Uint64Var(&assetID, "assetid", 0, "Asset ID to destroy")
+	destroyAssetCmd.MarkFlagRequired("creator")
+	destroyAssetCmd.MarkFlagRequired("assetid")
+
+	configAssetCmd.Flags().StringVar(&assetManager, "manager", "", "Manager account to issue the config transaction (defaults to creator)")
+	configAssetCmd.Flags().StringVar(&assetCreator, "creator", "", "Account address for asset to configure")
+	configAssetCmd.Flags().Uint64Var(&assetID, "assetid", 0, "Asset ID to configure")
+	configAssetCmd.Flags().StringVar(&assetNewManager, "new-manager", "", "New manager address")
+	configAssetCmd.Flags().StringVar(&assetNewReserve, "new-reserve", "", "New reserve address")
+	configAssetCmd.Flags().StringVar(&assetNewFreezer, "new-freezer", "", "New freeze address")
+	configAssetCmd.Flags().StringVar(&assetNewClawback, "new-clawback", "", "New clawback address")
+	configAssetCmd.MarkFlagRequired("creator")
+	configAssetCmd.MarkFlagRequired("assetid")
+
+	sendAssetCmd.Flags().StringVar(&assetClawback, "clawback", "", "Address to issue a clawback transaction from (defaults to no clawback)")
+	sendAssetCmd.Flags().Uint64Var(&assetID, "assetid", 0, "ID of the asset being transferred")
+	sendAssetCmd.Flags().StringVarP(&account, "from", "f", "", "Account address to send the money from (if not specified, uses default account)")
+	sendAssetCmd.Flags().StringVarP(&toAddress, "to", "t", "", "Address to send to money to (required)")
+	sendAssetCmd.Flags().Uint64VarP(&amount, "amount", "a", 0, "The amount to be transferred (required), in base units of the asset.")
+	sendAssetCmd.Flags().StringVarP(&closeToAddress, "close-to", "c", "", "Close asset account and send remainder to this address")
+	sendAssetCmd.MarkFlagRequired("assetid")
+	sendAssetCmd.MarkFlagRequired("to")
+	sendAssetCmd.MarkFlagRequired("amount")
+
+	freezeAssetCmd.Flags().StringVar(&assetFreezer, "freezer", "", "Address of freezer account")
+	freezeAssetCmd.Flags().Uint64Var(&assetID, "assetid", 0, "ID of the asset being frozen")
+	freezeAssetCmd.Flags().StringVar(&account, "account", "", "Account address to freeze/unfreeze")
+	freezeAssetCmd.Flags().BoolVar(&assetFrozen, "freeze", false, "Freeze or unfreeze")
+	freezeAssetCmd.MarkFlagRequired("freezer")
+	freezeAssetCmd.MarkFlagRequired("assetid")
+	freezeAssetCmd.MarkFlagRequired("account")
+	freezeAssetCmd.MarkFlagRequired("freeze")
+
+	// Add common transaction flags to all txn-generating asset commands
+	addTxnFlags(createAssetCmd)
+	addTxnFlags(destroyAssetCmd)
+	addTxnFlags(configAssetCmd)
+	addTxnFlags(sendAssetCmd)
+	addTxnFlags(freezeAssetCmd)
+
+	infoAssetCmd.Flags().Uint64Var(&assetID, "assetid", 0, "ID of the asset to look up")
+	infoAssetCmd.Flags().StringVar(&assetCreator, "creator", "", "Account address of the asset creator")
+	infoAssetCmd.MarkFlagRequired("assetid")
+	infoAssetCmd.MarkFlagRequired("creator")
+}
+
+func addTxnFlags(cmd *cobra.Command) {
+	cmd.Flags().Uint64Var(&fee, "fee", 0, "The transaction fee (automatically determined by default), in microAlgos")
+	cmd.Flags().Uint64Var(&firstValid, "firstvalid", 0, "The first round where the transaction may be committed to the ledger")
+	cmd.Flags().Uint64Var(&lastValid, "lastvalid", 0, "The last round where the transaction may be committed to the ledger")
+	cmd.Flags().StringVarP(&txFilename, "out", "o", "", "Dump an unsigned tx to the given file. In order to dump a signed transaction, pass -s")
+	cmd.Flags().BoolVarP(&sign, "sign", "s", false, "Use with -o to indicate that the dumped transaction should be signed")
+	cmd.Flags().BoolVarP(&noWaitAfterSend, "no-wait", "N", false, "Don't wait for transaction to commit")
+}
+
+var assetCmd = &cobra.Command{
+	Use:   "asset",
+	Short: "Manage assets",
+	Args:  validateNoPosArgsFn,
+	Run: func(cmd *cobra.Command, args []string) {
+		//If no arguments passed, we should fallback to help
+		cmd.HelpFunc()(cmd, args)
+	},
+}
+
+// issueAssetTxn fills in the common fields of an asset transaction template
+// and either signs and broadcasts it, or writes it to txFilename.  It
+// returns the committed transaction, or nil if it did not wait for one.
+func issueAssetTxn(client libgoal.Client, dataDir string, sender string, tx transactions.Transaction) *transactions.Transaction {
+	tx, err := client.FillUnsignedTxTemplate(sender, firstValid, lastValid, fee, tx)
+	if err != nil {
+		reportErrorf(errorConstructingTX, err)
+	}
+
+	if txFilename != "" {
+		var stxn transactions.SignedTxn
+		if sign {
+			wh, pw := ensureWalletHandleMaybePassword(dataDir, walletName, true)
+			stxn, err = signWithAuthAddr(client, wh, pw, tx)
+			if err != nil {
+				reportErrorf(errorSigningTX, err)
+			}
+		} else {
+			// Wrap in a transactions.SignedTxn with an empty sig.
+			// This way protocol.Encode will encode the transaction type
+			stxn, err = transactions.AssembleSignedTxn(tx, crypto.Signature{}, crypto.MultisigSig{})
+			if err != nil {
+				reportErrorf(errorConstructingTX, err)
+			}
+
+			stxn = populateBlankMultisig(client, dataDir, walletName, stxn)
+		}
+
+		err = ioutil.WriteFile(txFilename, protocol.Encode(stxn), 0600)
+		if err != nil {
+			reportErrorf(fileWriteError, txFilename, err)
+		}
+		return nil
+	}
+
+	wh, pw := ensureWalletHandleMaybePassword(dataDir, walletName, true)
+	stxn, err := signWithAuthAddr(client, wh, pw, tx)
+	if err != nil {
+		reportErrorf(errorSigningTX, err)
+	}
+
+	txid, err := client.BroadcastTransaction(stxn)
+	if err != nil {
+		reportErrorf(errorBroadcastingTX, err)
+	}
+
+	reportInfof(infoAssetTxIssued, tx.Type, txid, tx.Fee.Raw)
+
+	if noWaitAfterSend {
+		return nil
+	}
+
+	waitForCommit(client, txid)
+	return &tx
+}
+
+var createAssetCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create an asset",
+	Long:  "Post a transaction declaring and issuing a new layer-one asset on the network.",
+	Args:  validateNoPosArgsFn,
+	Run: func(cmd *cobra.Command, _ []string) {
+		// -s is invalid without -o
+		if txFilename == "" && sign {
+			reportErrorln(soFlagError)
+		}
+
+		dataDir := ensureSingleDataDir()
+		client := ensureFullClient(dataDir)
+		accountList := makeAccountsList(dataDir)
+		creator := accountList.getAddressByName(assetCreator)
+
+		manager := creator
+		reserve := creator
+		freezer := creator
+		clawback := creator
+
+		if cmd.Flags().Changed("manager") {
+			manager = accountList.getAddressByName(assetManager)
+		}
+		if cmd.Flags().Changed("reserve") {
+			reserve = accountList.getAddressByName(assetReserve)
+		}
+		if cmd.Flags().Changed("freezer") {
+			freezer = accountList.getAddressByName(assetFreezer)
+		}
+		if cmd.Flags().Changed("clawback") {
+			clawback = accountList.getAddressByName(assetClawback)
+		}
+
+		if assetNoManager {
+			manager = ""
+		}
+		if assetNoReserve {
+			reserve = ""
+		}
+		if assetNoFreezer {
+			freezer = ""
+		}
+		if assetNoClawback {
+			clawback = ""
+		}
+
+		var metadataHash []byte
+		if assetMetadataHashBase64 != "" {
+			var err error
+			metadataHash, err = base64.StdEncoding.DecodeString(assetMetadataHashBase64)
+			if err != nil {
+				reportErrorf(malformedMetadataHash, assetMetadataHashBase64, err)
+			}
+		}
+
+		// Remember which assets the creator already has, so that we can
+		// report the index of the newly created one.
+		existing := make(map[uint64]bool)
+		if txFilename == "" && !noWaitAfterSend {
+			info, err := client.AccountInformation(creator)
+			if err != nil {
+				reportErrorf(errorRequestFail, err)
+			}
+			for idx := range info.AssetParams {
+				existing[idx] = true
+			}
+		}
+
+		tx, err := client.MakeUnsignedAssetCreateTx(assetTotal, assetDefaultFrozen, manager, reserve, freezer, clawback, assetUnitName, assetName, assetURL, metadataHash)
+		if err != nil {
+			reportErrorf(errorConstructingTX, err)
+		}
+
+		if issueAssetTxn(client, dataDir, creator, tx) == nil {
+			return
+		}
+
+		info, err := client.AccountInformation(creator)
+		if err != nil {
+			reportErrorf(errorRequestFail, err)
+		}
+		for idx := range info.AssetParams {
+			if !existing[idx] {
+				reportInfof(infoAssetCreated, idx)
+			}
+		}
+	},
+}
+
+var destroyAssetCmd = &cobra.Command{
+	Use:   "destroy",
+	Short: "Destroy an asset",
+	Long:  `Issue a transaction deleting an asset from the network. This transaction must be issued by the asset owner, who must hold all outstanding asset tokens.`,
+	Args:  validateNoPosArgsFn,
+	Run: func(cmd *cobra.Command, _ []string) {
+		// -s is invalid without -o
+		if txFilename == "" && sign {
+			reportErrorln(soFlagError)
+		}
+
+		dataDir := ensureSingleDataDir()
+		client := ensureFullClient(dataDir)
+		accountList := makeAccountsList(dataDir)
+
+		creator := accountList.getAddressByName(assetCreator)
+		manager := creator
+		if assetManager != "" {
+			manager = accountList.getAddressByName(assetManager)
+		}
+
+		tx, err := client.MakeUnsignedAssetDestroyTx(assetID)
+		if err != nil {
+			reportErrorf(errorConstructingTX, err)
+		}
+
+		issueAssetTxn(client, dataDir, manager, tx)
+	},
+}
+
+var configAssetCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Configure an asset",
+	Long:  `Change an asset configuration. This transaction must be issued by the asset manager. This allows any management address to be changed: manager, freezer, reserve, or clawback.`,
+	Args:  validateNoPosArgsFn,
+	Run: func(cmd *cobra.Command, _ []string) {
+		// -s is invalid without -o
+		if txFilename == "" && sign {
+			reportErrorln(soFlagError)
+		}
+
+		dataDir := ensureSingleDataDir()
+		client := ensureFullClient(dataDir)
+		accountList := makeAccountsList(dataDir)
+
+		creator := accountList.getAddressByName(assetCreator)
+		manager := creator
+		if assetManager != "" {
+			manager = accountList.getAddressByName(assetManager)
+		}
+
+		var newManager, newReserve, newFreeze, newClawback *string
+		if cmd.Flags().Changed("new-manager") {
+			addr := accountList.getAddressByName(assetNewManager)
+			newManager = &addr
+		}
+		if cmd.Flags().Changed("new-reserve") {
+			addr := accountList.getAddressByName(assetNewReserve)
+			newReserve = &addr
+		}
+		if cmd.Flags().Changed("new-freezer") {
+			addr := accountList.getAddressByName(assetNewFreezer)
+			newFreeze = &addr
+		}
+		if cmd.Flags().Changed("new-clawback") {
+			addr := accountList.getAddressByName(assetNewClawback)
+			newClawback = &addr
+		}
+
+		tx, err := client.MakeUnsignedAssetConfigTx(creator, assetID, newManager, newReserve, newFreeze, newClawback)
+		if err != nil {
+			reportErrorf(errorConstructingTX, err)
+		}
+
+		issueAssetTxn(client, dataDir, manager, tx)
+	},
+}
+
+var sendAssetCmd = &cobra.Command{
+	Use:   "send",
+	Short: "Transfer assets",
+	Long:  "Transfer asset holdings. An account can begin accepting an asset by issuing a zero-amount asset transfer to itself.",
+	Args:  validateNoPosArgsFn,
+	Run: func(cmd *cobra.Command, _ []string) {
+		// -s is invalid without -o
+		if txFilename == "" && sign {
+			reportErrorln(soFlagError)
+		}
+
+		dataDir := ensureSingleDataDir()
+		client := ensureFullClient(dataDir)
+		accountList := makeAccountsList(dataDir)
+
+		// Check if from was specified, else use default
+		if account == "" {
+			account = accountList.getDefaultAccount()
+		}
+
+		sender := accountList.getAddressByName(account)
+		toAddressResolved := accountList.getAddressByName(toAddress)
+
+		var senderForClawback string
+		if assetClawback != "" {
+			senderForClawback = sender
+			sender = accountList.getAddressByName(assetClawback)
+		}
+
+		var closeToAddressResolved string
+		if closeToAddress != "" {
+			closeToAddressResolved = accountList.getAddressByName(closeToAddress)
+		}
+
+		tx, err := client.MakeUnsignedAssetSendTx(assetID, amount, toAddressResolved, closeToAddressResolved, senderForClawback)
+		if err != nil {
+			reportErrorf(errorConstructingTX, err)
+		}
+
+		issueAssetTxn(client, dataDir, sender, tx)
+	},
+}
+
+var freezeAssetCmd = &cobra.Command{
+	Use:   "freeze",
+	Short: "Freeze assets",
+	Long:  `Freeze or unfreeze assets for a target account. The transaction must be sent by the freeze account for the asset.`,
+	Args:  validateNoPosArgsFn,
+	Run: func(cmd *cobra.Command, _ []string) {
+		// -s is invalid without -o
+		if txFilename == "" && sign {
+			reportErrorln(soFlagError)
+		}
+
+		dataDir := ensureSingleDataDir()
+		client := ensureFullClient(dataDir)
+		accountList := makeAccountsList(dataDir)
+
+		freezer := accountList.getAddressByName(assetFreezer)
+		target := accountList.getAddressByName(account)
+
+		tx, err := client.MakeUnsignedAssetFreezeTx(assetID, target, assetFrozen)
+		if err != nil {
+			reportErrorf(errorConstructingTX, err)
+		}
+
+		issueAssetTxn(client, dataDir, freezer, tx)
+	},
+}
+
+var infoAssetCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Look up current parameters for an asset",
+	Long:  `Look up asset information stored on the network, such as asset creator, management addresses, or asset name.`,
+	Args:  validateNoPosArgsFn,
+	Run: func(cmd *cobra.Command, _ []string) {
+		dataDir := ensureSingleDataDir()
+		client := ensureAlgodClient(dataDir)
+		accountList := makeAccountsList(dataDir)
+		creator := accountList.getAddressByName(assetCreator)
+
+		info, err := client.AccountInformation(creator)
+		if err != nil {
+			reportErrorf(errorRequestFail, err)
+		}
+
+		params, ok := info.AssetParams[assetID]
+		if !ok {
+			reportErrorf(errorAssetNotFound, assetID, creator)
+		}
+
+		reserveEmpty := false
+		if params.ReserveAddr == "" {
+			reserveEmpty = true
+			params.ReserveAddr = creator
+		}
+
+		reserve, err := client.AccountInformation(params.ReserveAddr)
+		if err != nil {
+			reportErrorf(errorRequestFail, err)
+		}
+
+		res := reserve.Assets[assetID]
+
+		fmt.Printf("Asset ID:         %d\n", assetID)
+		fmt.Printf("Creator:          %s\n", creator)
+		fmt.Printf("Asset name:       %s\n", params.AssetName)
+		fmt.Printf("Unit name:        %s\n", params.UnitName)
+		fmt.Printf("URL:              %s\n", params.URL)
+		fmt.Printf("Metadata hash:    %s\n", base64.StdEncoding.EncodeToString(params.MetadataHash))
+		fmt.Printf("Maximum issue:    %d %s\n", params.Total, params.UnitName)
+		fmt.Printf("Reserve amount:   %d %s\n", res.Amount, params.UnitName)
+		fmt.Printf("Issued:           %d %s\n", params.Total-res.Amount, params.UnitName)
+		fmt.Printf("Default frozen:   %v\n", params.DefaultFrozen)
+		fmt.Printf("Manager address:  %s\n", params.ManagerAddr)
+		if reserveEmpty {
+			fmt.Printf("Reserve address:  %s (Empty. Defaulting to creator)\n", params.ReserveAddr)
+		} else {
+			fmt.Printf("Reserve address:  %s\n", params.ReserveAddr)
+		}
+		fmt.Printf("Freeze address:   %s\n", params.FreezeAddr)
+		fmt.Printf("Clawback address: %s\n", params.ClawbackAddr)
+	},
+}
@@ -24,8 +24,11 @@ import (
 	"os"
 
 	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/daemon/algod/api/client/models"
 	"github.com/algorand/go-algorand/data/basics"
 	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/data/transactions/logic"
+	"github.com/algorand/go-algorand/libgoal"
 	"github.com/algorand/go-algorand/protocol"
 
 	"github.com/spf13/cobra"
@@ -45,7 +48,12 @@ var (
 	noteText        string
 	sign            bool
 	closeToAddress  string
+	rekeyToAddress  string
 	noWaitAfterSend bool
+	programSource   string
+	argB64Strings   []string
+	disassemble     bool
+	noProgramOutput bool
 )
 
 func init() {
@@ -53,6 +61,9 @@ func init() {
 	clerkCmd.AddCommand(rawsendCmd)
 	clerkCmd.AddCommand(inspectCmd)
 	clerkCmd.AddCommand(signCmd)
+	clerkCmd.AddCommand(groupCmd)
+	clerkCmd.AddCommand(compileCmd)
+	clerkCmd.AddCommand(dryrunCmd)
 
 	// Wallet to be used for the clerk operation
 	clerkCmd.PersistentFlags().StringVarP(&walletName, "wallet", "w", "", "Set the wallet to be used for the selected operation")
@@ -69,7 +80,10 @@ func init() {
 	sendCmd.Flags().StringVarP(&txFilename, "out", "o", "", "Dump an unsigned tx to the given file. In order to dump a signed transaction, pass -s")
 	sendCmd.Flags().BoolVarP(&sign, "sign", "s", false, "Use with -o to indicate that the dumped transaction should be signed")
 	sendCmd.Flags().StringVarP(&closeToAddress, "close-to", "c", "", "Close account and send remainder to this address")
+	sendCmd.Flags().StringVar(&rekeyToAddress, "rekey-to", "", "Rekey the sender account so that transactions from it must be signed by this address")
 	sendCmd.Flags().BoolVarP(&noWaitAfterSend, "no-wait", "N", false, "Don't wait for transaction to commit")
+	sendCmd.Flags().StringVarP(&programSource, "from-program", "F", "", "Program source to authorize the transaction with; the money is sent from the program's address")
+	sendCmd.Flags().StringSliceVar(&argB64Strings, "argb64", nil, "Base64 encoded argument to the program (may be given multiple times)")
 
 	sendCmd.MarkFlagRequired("to")
 	sendCmd.MarkFlagRequired("amount")
@@ -84,6 +98,18 @@ func init() {
 	signCmd.Flags().StringVarP(&outFilename, "outfile", "o", "", "Filename for writing the signed transaction")
 	signCmd.MarkFlagRequired("infile")
 	signCmd.MarkFlagRequired("outfile")
+
+	groupCmd.Flags().StringVarP(&txFilename, "infile", "i", "", "File storing transactions to be grouped")
+	groupCmd.Flags().StringVarP(&outFilename, "outfile", "o", "", "Filename for writing the grouped transactions")
+	groupCmd.MarkFlagRequired("infile")
+	groupCmd.MarkFlagRequired("outfile")
+
+	dryrunCmd.Flags().StringVarP(&txFilename, "txfile", "t", "", "Filename of file containing signed or unsigned transactions")
+	dryrunCmd.MarkFlagRequired("txfile")
+
+	compileCmd.Flags().BoolVarP(&disassemble, "disassemble", "D", false, "Disassemble a compiled program")
+	compileCmd.Flags().BoolVarP(&noProgramOutput, "no-out", "n", false, "Don't write the compiled program, only print its address")
+	compileCmd.Flags().StringVarP(&outFilename, "outfile", "o", "", "Filename to write the compiled program to (default is the input filename with .tok appended)")
 }
 
 var clerkCmd = &cobra.Command{
@@ -111,6 +137,23 @@ var sendCmd = &cobra.Command{
 		dataDir := ensureSingleDataDir()
 		accountList := makeAccountsList(dataDir)
 
+		// A transaction authorized by a program is sent from the program's address
+		var lsig transactions.LogicSig
+		if programSource != "" {
+			if sign {
+				reportErrorln(programSendSign)
+			}
+			lsig.Logic = assembleFile(programSource)
+			for _, arg := range argB64Strings {
+				data, err := base64.StdEncoding.DecodeString(arg)
+				if err != nil {
+					reportErrorf(malformedArg, arg, err)
+				}
+				lsig.Args = append(lsig.Args, data)
+			}
+			account = transactions.LogicSigAddress(lsig.Logic).GetUserAddress()
+		}
+
 		// Check if from was specified, else use default
 		if account == "" {
 			account = accountList.getDefaultAccount()
@@ -143,64 +186,69 @@ var sendCmd = &cobra.Command{
 		}
 
 		client := ensureFullClient(dataDir)
-		if txFilename == "" {
-			// Sign and broadcast the tx
-			wh, pw := ensureWalletHandleMaybePassword(dataDir, walletName, true)
-			tx, err := client.SendPaymentFromWallet(wh, pw, fromAddressResolved, toAddressResolved, fee, amount, noteBytes, closeToAddressResolved, basics.Round(firstValid), basics.Round(lastValid))
-
-			// update information from Transaction
-			txid := tx.ID().String()
-			fee = tx.Fee.Raw
+		payment, err := client.ConstructPayment(fromAddressResolved, toAddressResolved, fee, amount, noteBytes, closeToAddressResolved, basics.Round(firstValid), basics.Round(lastValid))
+		if err != nil {
+			reportErrorf(errorConstructingTX, err)
+		}
 
+		// If rekeying the sender, resolve the new spending address
+		if rekeyToAddress != "" {
+			payment.RekeyTo, err = basics.UnmarshalChecksumAddress(accountList.getAddressByName(rekeyToAddress))
 			if err != nil {
-				reportErrorf(errorBroadcastingTX, err)
+				reportErrorf(errorParseAddr, err)
 			}
+		}
 
-			// Report tx details to user
-			reportInfof(infoTxIssued, amount, fromAddressResolved, toAddressResolved, txid, fee)
+		if programSource != "" {
+			stxn := transactions.SignedTxn{Txn: payment, Lsig: lsig}
 
-			if noWaitAfterSend {
+			if txFilename != "" {
+				err = ioutil.WriteFile(txFilename, protocol.Encode(stxn), 0600)
+				if err != nil {
+					reportErrorf(fileWriteError, txFilename, err)
+				}
 				return
 			}
 
-			// Get current round information
-			stat, err := client.Status()
+			txid, err := client.BroadcastTransaction(stxn)
 			if err != nil {
-				reportErrorf(errorRequestFail, err)
+				reportErrorf(errorBroadcastingTX, err)
 			}
+			reportInfof(infoTxIssued, amount, fromAddressResolved, toAddressResolved, txid, payment.Fee.Raw)
 
-			for {
-				// Check if we know about the transaction yet
-				txn, err := client.PendingTransactionInformation(txid)
-				if err != nil {
-					reportErrorf(errorRequestFail, err)
-				}
+			if !noWaitAfterSend {
+				waitForCommit(client, txid)
+			}
+			return
+		}
 
-				if txn.ConfirmedRound > 0 {
-					reportInfof(infoTxCommitted, txid, txn.ConfirmedRound)
-					break
-				}
+		if txFilename == "" {
+			// Sign and broadcast the tx
+			wh, pw := ensureWalletHandleMaybePassword(dataDir, walletName, true)
+			stxn, err := signWithAuthAddr(client, wh, pw, payment)
+			if err != nil {
+				reportErrorf(errorSigningTX, err)
+			}
 
-				if txn.PoolError != "" {
-					reportErrorf(txPoolError, txid, txn.PoolError)
-				}
+			txid, err := client.BroadcastTransaction(stxn)
+			if err != nil {
+				reportErrorf(errorBroadcastingTX, err)
+			}
 
-				reportInfof(infoTxPending, txid, stat.LastRound)
-				stat, err = client.WaitForRound(stat.LastRound + 1)
-				if err != nil {
-					reportErrorf(errorRequestFail, err)
-				}
+			// Report tx details to user
+			reportInfof(infoTxIssued, amount, fromAddressResolved, toAddressResolved, txid, payment.Fee.Raw)
+
+			if noWaitAfterSend {
+				return
 			}
+
+			waitForCommit(client, txid)
 		} else {
-			payment, err := client.ConstructPayment(fromAddressResolved, toAddressResolved, fee, amount, noteBytes, closeToAddressResolved, basics.Round(firstValid), basics.Round(lastValid))
-			if err != nil {
-				reportErrorf(errorConstructingTX, err)
-			}
 			var stxn transactions.SignedTxn
 			if sign {
 				// Sign the transaction
 				wh, pw := ensureWalletHandleMaybePassword(dataDir, walletName, true)
-				stxn, err = client.SignTransactionWithWallet(wh, pw, payment)
+				stxn, err = signWithAuthAddr(client, wh, pw, payment)
 				if err != nil {
 					reportErrorf(errorConstructingTX, err)
 				}
@@ -223,6 +271,53 @@ var sendCmd = &cobra.Command{
 	},
 }
 
+// signWithAuthAddr signs tx with the wallet key of its sender or, if the
+// sender has been rekeyed, with the key of the address it was rekeyed to.
+func signWithAuthAddr(client libgoal.Client, wh, pw []byte, tx transactions.Transaction) (transactions.SignedTxn, error) {
+	info, err := client.AccountInformation(tx.Sender.GetUserAddress())
+	if err != nil {
+		return transactions.SignedTxn{}, err
+	}
+	if info.AuthAddr == "" {
+		return client.SignTransactionWithWallet(wh, pw, tx)
+	}
+	return client.SignTransactionWithWalletAndSigner(wh, pw, info.AuthAddr, tx)
+}
+
+// waitForCommit blocks until the transaction txid is committed, reporting
+// progress as rounds go by.  It exits with an error if the transaction is
+// evicted from the local node's pool.
+func waitForCommit(client libgoal.Client, txid string) models.Transaction {
+	// Get current round information
+	stat, err := client.Status()
+	if err != nil {
+		reportErrorf(errorRequestFail, err)
+	}
+
+	for {
+		// Check if we know about the transaction yet
+		txn, err := client.PendingTransactionInformation(txid)
+		if err != nil {
+			reportErrorf(errorRequestFail, err)
+		}
+
+		if txn.ConfirmedRound > 0 {
+			reportInfof(infoTxCommitted, txid, txn.ConfirmedRound)
+			return txn
+		}
+
+		if txn.PoolError != "" {
+			reportErrorf(txPoolError, txid, txn.PoolError)
+		}
+
+		reportInfof(infoTxPending, txid, stat.LastRound)
+		stat, err = client.WaitForRound(stat.LastRound + 1)
+		if err != nil {
+			reportErrorf(errorRequestFail, err)
+		}
+	}
+}
+
 var rawsendCmd = &cobra.Command{
 	Use:   "rawsend",
 	Short: "Send raw transactions",
@@ -242,6 +337,7 @@ var rawsendCmd = &cobra.Command{
 		client := ensureAlgodClient(ensureSingleDataDir())
 
 		txns := make(map[transactions.Txid]transactions.SignedTxn)
+		var txgroups [][]transactions.SignedTxn
 		for {
 			var txn transactions.SignedTxn
 			err = dec.Decode(&txn)
@@ -258,21 +354,48 @@ var rawsendCmd = &cobra.Command{
 			}
 
 			txns[txn.ID()] = txn
+
+			// members of a transaction group are stored consecutively, and are sent together
+			lastGroup := len(txgroups) - 1
+			if lastGroup >= 0 && !txn.Txn.Group.IsZero() && txn.Txn.Group == txgroups[lastGroup][0].Txn.Group {
+				txgroups[lastGroup] = append(txgroups[lastGroup], txn)
+			} else {
+				txgroups = append(txgroups, []transactions.SignedTxn{txn})
+			}
 		}
 
 		txnErrors := make(map[transactions.Txid]string)
 		pendingTxns := make(map[transactions.Txid]string)
-		for txid, txn := range txns {
-			// Broadcast the transaction
-			txidStr, err := client.BroadcastTransaction(txn)
+		for _, txgroup := range txgroups {
+			if len(txgroup) == 1 {
+				// Broadcast the transaction
+				txn := txgroup[0]
+				txidStr, err := client.BroadcastTransaction(txn)
+				if err != nil {
+					txnErrors[txn.ID()] = err.Error()
+					reportWarnf(errorBroadcastingTX, err)
+					continue
+				}
+
+				reportInfof(infoRawTxIssued, txidStr)
+				pendingTxns[txn.ID()] = txidStr
+				continue
+			}
+
+			// Broadcast the transaction group
+			err := client.BroadcastTransactionGroup(txgroup)
 			if err != nil {
-				txnErrors[txid] = err.Error()
+				for _, txn := range txgroup {
+					txnErrors[txn.ID()] = err.Error()
+				}
 				reportWarnf(errorBroadcastingTX, err)
 				continue
 			}
 
-			reportInfof(infoRawTxIssued, txidStr)
-			pendingTxns[txid] = txidStr
+			reportInfof(infoTxGroupSent, len(txgroup), txgroup[0].ID().String())
+			for _, txn := range txgroup {
+				pendingTxns[txn.ID()] = txn.ID().String()
+			}
 		}
 
 		if noWaitAfterSend {
@@ -371,6 +494,56 @@ var inspectCmd = &cobra.Command{
 	},
 }
 
+var dryrunCmd = &cobra.Command{
+	Use:   "dryrun -t TXFILE",
+	Short: "Test transactions against the latest ledger state, without sending them",
+	Long:  `Evaluate transactions as if they were included in the next block, and report whether each one would be accepted, the rewards it would produce, and the resulting balances of the accounts it touches. Nothing is committed or broadcast. The transactions are read from a file, encoded using msgpack as transactions.SignedTxn, as written by goal clerk send -o; they may be signed or unsigned. Members of a transaction group must be stored consecutively.`,
+	Args:  validateNoPosArgsFn,
+	Run: func(cmd *cobra.Command, args []string) {
+		data, err := ioutil.ReadFile(txFilename)
+		if err != nil {
+			reportErrorf(fileReadError, txFilename, err)
+		}
+
+		dec := protocol.NewDecoderBytes(data)
+		var txns []transactions.SignedTxn
+		for {
+			var txn transactions.SignedTxn
+			err = dec.Decode(&txn)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				reportErrorf(txDecodeError, txFilename, err)
+			}
+			txns = append(txns, txn)
+		}
+
+		client := ensureAlgodClient(ensureSingleDataDir())
+		res, err := client.DryRun(txns)
+		if err != nil {
+			reportErrorf(errorDryRunTX, err)
+		}
+
+		reportInfof(infoDryRunRound, len(res.Transactions), res.Round)
+		for _, result := range res.Transactions {
+			txn := result.Transaction
+			if result.Error != "" {
+				reportInfof(infoDryRunError, txn.TxID, result.Error)
+				continue
+			}
+
+			reportInfof(infoDryRunOK, txn.TxID)
+			if txn.Payment != nil {
+				reportInfof(infoDryRunApply, txn.FromRewards, txn.Payment.ToRewards, txn.Payment.CloseRewards, txn.Payment.CloseAmount)
+			}
+			for _, account := range result.Accounts {
+				reportInfof(infoDryRunAcct, account.Address, account.Amount, account.Status)
+			}
+		}
+	},
+}
+
 var signCmd = &cobra.Command{
 	Use:   "sign -i INFILE -o OUTFILE",
 	Short: "Sign a transaction file",
@@ -412,3 +585,116 @@ var signCmd = &cobra.Command{
 		}
 	},
 }
+
+var groupCmd = &cobra.Command{
+	Use:   "group -i INFILE -o OUTFILE",
+	Short: "Group transactions together",
+	Long:  `Form a transaction group.  The input file must contain one or more unsigned transactions that will form a group.  The output file will contain the same transactions, in order, with a group flag added so that the transactions must be committed together.  The group must be signed after it is formed.`,
+	Args:  validateNoPosArgsFn,
+	Run: func(cmd *cobra.Command, _ []string) {
+		data, err := ioutil.ReadFile(txFilename)
+		if err != nil {
+			reportErrorf(fileReadError, txFilename, err)
+		}
+
+		dec := protocol.NewDecoderBytes(data)
+
+		var stxns []transactions.SignedTxn
+		var group transactions.TxGroup
+		for {
+			var stxn transactions.SignedTxn
+			err = dec.Decode(&stxn)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				reportErrorf(txDecodeError, txFilename, err)
+			}
+
+			if !stxn.Txn.Group.IsZero() {
+				reportErrorf(txAlreadyGroup, stxn.ID().String(), txFilename, stxn.Txn.Group.String())
+			}
+
+			stxns = append(stxns, stxn)
+			group.TxGroupHashes = append(group.TxGroupHashes, crypto.Digest(stxn.ID()))
+		}
+
+		if len(stxns) == 0 {
+			reportErrorf(txGroupError, "no transactions in "+txFilename)
+		}
+
+		var outData []byte
+		gid := crypto.HashObj(group)
+		for _, stxn := range stxns {
+			// the transaction changes once it is part of the group, so any existing signature would no longer be valid
+			grouped := transactions.SignedTxn{Txn: stxn.Txn}
+			grouped.Txn.Group = gid
+			outData = append(outData, protocol.Encode(grouped)...)
+		}
+
+		err = ioutil.WriteFile(outFilename, outData, 0600)
+		if err != nil {
+			reportErrorf(fileWriteError, outFilename, err)
+		}
+	},
+}
+
+// assembleFile reads the program source in filename and returns its bytecode.
+func assembleFile(filename string) []byte {
+	text, err := ioutil.ReadFile(filename)
+	if err != nil {
+		reportErrorf(fileReadError, filename, err)
+	}
+	program, err := logic.AssembleString(string(text))
+	if err != nil {
+		reportErrorf(programError, filename, err)
+	}
+	return program
+}
+
+var compileCmd = &cobra.Command{
+	Use:   "compile [input file 1] [input file 2]...",
+	Short: "Compile a contract program",
+	Long:  `Assemble the source of one or more contract programs. Each compiled program is written next to its source with .tok appended, and the address of the account controlled by the program is printed. With -D, each input is instead a compiled program and its disassembly is printed.`,
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if outFilename != "" && len(args) > 1 {
+			reportErrorln(compileMultiOut)
+		}
+
+		for _, fname := range args {
+			if disassemble {
+				program, err := ioutil.ReadFile(fname)
+				if err != nil {
+					reportErrorf(fileReadError, fname, err)
+				}
+				text, err := logic.Disassemble(program)
+				if err != nil {
+					reportErrorf(disassembleErr, fname, err)
+				}
+				if outFilename == "" {
+					fmt.Print(text)
+					continue
+				}
+				err = ioutil.WriteFile(outFilename, []byte(text), 0666)
+				if err != nil {
+					reportErrorf(fileWriteError, outFilename, err)
+				}
+				continue
+			}
+
+			program := assembleFile(fname)
+			if !noProgramOutput {
+				outname := outFilename
+				if outname == "" {
+					outname = fname + ".tok"
+				}
+				err := ioutil.WriteFile(outname, program, 0666)
+				if err != nil {
+					reportErrorf(fileWriteError, outname, err)
+				}
+			}
+			fmt.Printf("%s: %s\n", fname, transactions.LogicSigAddress(program).GetUserAddress())
+		}
+	},
+}
@@ -61,6 +61,9 @@ func init() {
 	// clerk.go
 	rootCmd.AddCommand(clerkCmd)
 
+	// asset.go
+	rootCmd.AddCommand(assetCmd)
+
 	// node.go
 	rootCmd.AddCommand(nodeCmd)
 
@@ -34,9 +34,21 @@ type inspectSignedTxn struct {
 
 	Sig  crypto.Signature   `codec:"sig"`
 	Msig inspectMultisigSig `codec:"msig"`
+	Lsig inspectLogicSig    `codec:"lsig"`
 	Txn  inspectTransaction `codec:"txn"`
 }
 
+// inspectLogicSig is isomorphic to LogicSig but uses different
+// types to print public keys using algorand's address format in JSON.
+type inspectLogicSig struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	Logic []byte             `codec:"l"`
+	Sig   crypto.Signature   `codec:"sig"`
+	Msig  inspectMultisigSig `codec:"msig"`
+	Args  [][]byte           `codec:"arg"`
+}
+
 // inspectMultisigSig is isomorphic to MultisigSig but uses different
 // types to print public keys using algorand's address format in JSON.
 type inspectMultisigSig struct {
@@ -65,6 +77,9 @@ type inspectTransaction struct {
 	inspectTxnHeader
 	transactions.KeyregTxnFields
 	inspectPaymentTxnFields
+	inspectAssetConfigTxnFields
+	inspectAssetTransferTxnFields
+	inspectAssetFreezeTxnFields
 }
 
 // inspectTxnHeader is isomorphic to Header but uses different
@@ -79,6 +94,7 @@ type inspectTxnHeader struct {
 	Note        []byte            `codec:"note"`
 	GenesisID   string            `codec:"gen"`
 	GenesisHash crypto.Digest     `codec:"gh"`
+	Group       crypto.Digest     `codec:"grp"`
 }
 
 // inspectPaymentTxnFields is isomorphic to Header but uses different
@@ -91,6 +107,54 @@ type inspectPaymentTxnFields struct {
 	CloseRemainderTo checksumAddress   `codec:"close"`
 }
 
+// inspectAssetConfigTxnFields is isomorphic to AssetConfigTxnFields but uses
+// different types to print public keys using algorand's address format in JSON.
+type inspectAssetConfigTxnFields struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	ConfigAsset basics.AssetIndex  `codec:"caid"`
+	AssetParams inspectAssetParams `codec:"apar"`
+}
+
+// inspectAssetParams is isomorphic to AssetParams but uses different
+// types to print public keys using algorand's address format in JSON.
+type inspectAssetParams struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	Total         uint64          `codec:"t"`
+	DefaultFrozen bool            `codec:"df"`
+	UnitName      string          `codec:"un"`
+	AssetName     string          `codec:"an"`
+	URL           string          `codec:"au"`
+	MetadataHash  [32]byte        `codec:"am"`
+	Manager       checksumAddress `codec:"m"`
+	Reserve       checksumAddress `codec:"r"`
+	Freeze        checksumAddress `codec:"f"`
+	Clawback      checksumAddress `codec:"c"`
+}
+
+// inspectAssetTransferTxnFields is isomorphic to AssetTransferTxnFields but uses
+// different types to print public keys using algorand's address format in JSON.
+type inspectAssetTransferTxnFields struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	XferAsset     basics.AssetIndex `codec:"xaid"`
+	AssetAmount   uint64            `codec:"aamt"`
+	AssetSender   checksumAddress   `codec:"asnd"`
+	AssetReceiver checksumAddress   `codec:"arcv"`
+	AssetCloseTo  checksumAddress   `codec:"aclose"`
+}
+
+// inspectAssetFreezeTxnFields is isomorphic to AssetFreezeTxnFields but uses
+// different types to print public keys using algorand's address format in JSON.
+type inspectAssetFreezeTxnFields struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	FreezeAccount checksumAddress   `codec:"fadd"`
+	FreezeAsset   basics.AssetIndex `codec:"faid"`
+	AssetFrozen   bool              `codec:"afrz"`
+}
+
 // checksumAddress is a checksummed address, for use with text encodings
 // like JSON.
 type checksumAddress basics.Address
@@ -130,6 +194,7 @@ func stxnToInspect(stxn transactions.SignedTxn) inspectSignedTxn {
 		Txn:  txnToInspect(stxn.Txn),
 		Sig:  stxn.Sig,
 		Msig: msigToInspect(stxn.Msig),
+		Lsig: lsigToInspect(stxn.Lsig),
 	}
 }
 
@@ -138,6 +203,25 @@ func stxnFromInspect(sti inspectSignedTxn) transactions.SignedTxn {
 		Txn:  txnFromInspect(sti.Txn),
 		Sig:  sti.Sig,
 		Msig: msigFromInspect(sti.Msig),
+		Lsig: lsigFromInspect(sti.Lsig),
+	}
+}
+
+func lsigToInspect(lsig transactions.LogicSig) inspectLogicSig {
+	return inspectLogicSig{
+		Logic: lsig.Logic,
+		Sig:   lsig.Sig,
+		Msig:  msigToInspect(lsig.Msig),
+		Args:  lsig.Args,
+	}
+}
+
+func lsigFromInspect(lsi inspectLogicSig) transactions.LogicSig {
+	return transactions.LogicSig{
+		Logic: lsi.Logic,
+		Sig:   lsi.Sig,
+		Msig:  msigFromInspect(lsi.Msig),
+		Args:  lsi.Args,
 	}
 }
 
@@ -184,6 +268,7 @@ func txnToInspect(txn transactions.Transaction) inspectTransaction {
 			Note:        txn.Note,
 			GenesisID:   txn.GenesisID,
 			GenesisHash: txn.GenesisHash,
+			Group:       txn.Group,
 		},
 		KeyregTxnFields: txn.KeyregTxnFields,
 		inspectPaymentTxnFields: inspectPaymentTxnFields{
@@ -191,6 +276,33 @@ func txnToInspect(txn transactions.Transaction) inspectTransaction {
 			Amount:           txn.Amount,
 			CloseRemainderTo: checksumAddress(txn.CloseRemainderTo),
 		},
+		inspectAssetConfigTxnFields: inspectAssetConfigTxnFields{
+			ConfigAsset: txn.ConfigAsset,
+			AssetParams: inspectAssetParams{
+				Total:         txn.AssetParams.Total,
+				DefaultFrozen: txn.AssetParams.DefaultFrozen,
+				UnitName:      txn.AssetParams.UnitName,
+				AssetName:     txn.AssetParams.AssetName,
+				URL:           txn.AssetParams.URL,
+				MetadataHash:  txn.AssetParams.MetadataHash,
+				Manager:       checksumAddress(txn.AssetParams.Manager),
+				Reserve:       checksumAddress(txn.AssetParams.Reserve),
+				Freeze:        checksumAddress(txn.AssetParams.Freeze),
+				Clawback:      checksumAddress(txn.AssetParams.Clawback),
+			},
+		},
+		inspectAssetTransferTxnFields: inspectAssetTransferTxnFields{
+			XferAsset:     txn.XferAsset,
+			AssetAmount:   txn.AssetAmount,
+			AssetSender:   checksumAddress(txn.AssetSender),
+			AssetReceiver: checksumAddress(txn.AssetReceiver),
+			AssetCloseTo:  checksumAddress(txn.AssetCloseTo),
+		},
+		inspectAssetFreezeTxnFields: inspectAssetFreezeTxnFields{
+			FreezeAccount: checksumAddress(txn.FreezeAccount),
+			FreezeAsset:   txn.FreezeAsset,
+			AssetFrozen:   txn.AssetFrozen,
+		},
 	}
 }
 
@@ -205,6 +317,7 @@ func txnFromInspect(txi inspectTransaction) transactions.Transaction {
 			Note:        txi.Note,
 			GenesisID:   txi.GenesisID,
 			GenesisHash: txi.GenesisHash,
+			Group:       txi.Group,
 		},
 		KeyregTxnFields: txi.KeyregTxnFields,
 		PaymentTxnFields: transactions.PaymentTxnFields{
@@ -212,5 +325,32 @@ func txnFromInspect(txi inspectTransaction) transactions.Transaction {
 			Amount:           txi.Amount,
 			CloseRemainderTo: basics.Address(txi.CloseRemainderTo),
 		},
+		AssetConfigTxnFields: transactions.AssetConfigTxnFields{
+			ConfigAsset: txi.ConfigAsset,
+			AssetParams: basics.AssetParams{
+				Total:         txi.AssetParams.Total,
+				DefaultFrozen: txi.AssetParams.DefaultFrozen,
+				UnitName:      txi.AssetParams.UnitName,
+				AssetName:     txi.AssetParams.AssetName,
+				URL:           txi.AssetParams.URL,
+				MetadataHash:  txi.AssetParams.MetadataHash,
+				Manager:       basics.Address(txi.AssetParams.Manager),
+				Reserve:       basics.Address(txi.AssetParams.Reserve),
+				Freeze:        basics.Address(txi.AssetParams.Freeze),
+				Clawback:      basics.Address(txi.AssetParams.Clawback),
+			},
+		},
+		AssetTransferTxnFields: transactions.AssetTransferTxnFields{
+			XferAsset:     txi.XferAsset,
+			AssetAmount:   txi.AssetAmount,
+			AssetSender:   basics.Address(txi.AssetSender),
+			AssetReceiver: basics.Address(txi.AssetReceiver),
+			AssetCloseTo:  basics.Address(txi.AssetCloseTo),
+		},
+		AssetFreezeTxnFields: transactions.AssetFreezeTxnFields{
+			FreezeAccount: basics.Address(txi.FreezeAccount),
+			FreezeAsset:   txi.FreezeAsset,
+			AssetFrozen:   txi.AssetFrozen,
+		},
 	}
 }
@@ -67,6 +67,9 @@ func TestInspect(t *testing.T) {
 	crypto.RandBytes(full.Msig.Subsigs[0].Sig[:])
 	crypto.RandBytes(full.Msig.Subsigs[1].Key[:])
 	crypto.RandBytes(full.Msig.Subsigs[1].Sig[:])
+	full.Lsig.Logic = []byte{1, 0x22}
+	crypto.RandBytes(full.Lsig.Sig[:])
+	full.Lsig.Args = [][]byte{{1, 2, 3}, {4, 5}}
 	full.Txn.Type = protocol.UnknownTx
 	crypto.RandBytes(full.Txn.Sender[:])
 	full.Txn.Fee.Raw = crypto.RandUint64()
@@ -76,6 +79,7 @@ func TestInspect(t *testing.T) {
 	crypto.RandBytes(full.Txn.Note[:])
 	full.Txn.GenesisID = "testid"
 	crypto.RandBytes(full.Txn.GenesisHash[:])
+	crypto.RandBytes(full.Txn.Group[:])
 	crypto.RandBytes(full.Txn.VotePK[:])
 	crypto.RandBytes(full.Txn.SelectionPK[:])
 	full.Txn.VoteFirst = basics.Round(crypto.RandUint64())
@@ -84,6 +88,25 @@ func TestInspect(t *testing.T) {
 	full.Txn.Amount.Raw = crypto.RandUint64()
 	crypto.RandBytes(full.Txn.Receiver[:])
 	crypto.RandBytes(full.Txn.CloseRemainderTo[:])
+	full.Txn.ConfigAsset = basics.AssetIndex(crypto.RandUint64())
+	full.Txn.AssetParams.Total = crypto.RandUint64()
+	full.Txn.AssetParams.DefaultFrozen = true
+	full.Txn.AssetParams.UnitName = "tok"
+	full.Txn.AssetParams.AssetName = "token"
+	full.Txn.AssetParams.URL = "http://example.com/"
+	crypto.RandBytes(full.Txn.AssetParams.MetadataHash[:])
+	crypto.RandBytes(full.Txn.AssetParams.Manager[:])
+	crypto.RandBytes(full.Txn.AssetParams.Reserve[:])
+	crypto.RandBytes(full.Txn.AssetParams.Freeze[:])
+	crypto.RandBytes(full.Txn.AssetParams.Clawback[:])
+	full.Txn.XferAsset = basics.AssetIndex(crypto.RandUint64())
+	full.Txn.AssetAmount = crypto.RandUint64()
+	crypto.RandBytes(full.Txn.AssetSender[:])
+	crypto.RandBytes(full.Txn.AssetReceiver[:])
+	crypto.RandBytes(full.Txn.AssetCloseTo[:])
+	crypto.RandBytes(full.Txn.FreezeAccount[:])
+	full.Txn.FreezeAsset = basics.AssetIndex(crypto.RandUint64())
+	full.Txn.AssetFrozen = true
 	_, err = inspectTxn(full)
 	require.NoError(t, err)
 }
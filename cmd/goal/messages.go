@@ -38,6 +38,8 @@ const (
 	errorOnlineTX                  = "Couldn't sign tx: %s (for multisig accounts, write tx to file and sign manually)"
 	errorConstructingTX            = "Couldn't construct tx: %s"
 	errorBroadcastingTX            = "Couldn't broadcast tx with algod: %s"
+	errorDryRunTX                  = "Couldn't dry-run tx with algod: %s"
+	errorParseAddr                 = "Failed to parse address: %v"
 	warnMultisigDuplicatesDetected = "Warning: one or more duplicate addresses detected in multisig account creation. This will effectively give the duplicated address(es) extra signature weight. Continuing multisig account creation."
 	errLastRoundInvalid            = "roundLastValid needs to be well after the current round (%d)"
 	errExistingPartKey             = "Account already has a participation key valid at least until roundLastValid (%d) - current is %d"
@@ -69,6 +71,12 @@ const (
 	infoNodeWroteToken               = "Successfully wrote new API token: %s"
 	infoNodePendingTxnsDescription   = "Pending Transactions (Truncated max=%d, Total in pool=%d): "
 	infoNodeNoPendingTxnsDescription = "None"
+	infoNodeCatchup                  = "Catching up to catchpoint %s"
+	errorNodeCatchup                 = "Cannot start catching up: %s"
+	infoNodeUpgradeStatus            = "Last committed block: %d\nCurrent consensus protocol: %s\nNext consensus protocol: %s\nNext consensus protocol supported: %v\nApprovals: %d of %d needed in %d rounds\nVote ends before round: %d\nSwitch on round: %d"
+	infoNodeNoUpgrade                = "Last committed block: %d\nCurrent consensus protocol: %s\nNo upgrade in progress (needs %d approvals in %d rounds)"
+	infoNodeUpgradeSupport           = "Versions supported by the proposers of rounds %d to %d (online stake %d microAlgos):"
+	infoNodeUpgradeSupportVersion    = "  %s: %d proposers, %d blocks, %d microAlgos online (%.1f%%)%s"
 	infoDataDir                      = "[Data Directory: %s]"
 	errLoadingConfig                 = "Error loading Config file from '%s': %v"
 
@@ -88,9 +96,28 @@ const (
 	soFlagError     = "-s is not meaningful without -o"
 	infoRawTxIssued = "Raw transaction ID %s issued"
 	txPoolError     = "Transaction %s kicked out of local node pool: %s"
+	txGroupError    = "Cannot group transactions: %s"
+	txAlreadyGroup  = "Transaction %s in %s already has a group %s"
+	infoTxGroupSent = "Raw transaction group with %d transactions issued, first transaction ID %s"
+	infoDryRunRound = "Evaluated %d transactions as part of round %d"
+	infoDryRunOK    = "Transaction %s would be accepted"
+	infoDryRunError = "Transaction %s would be rejected: %s"
+	infoDryRunApply = "  Rewards: sender %d, receiver %d, close %d; closing amount %d MicroAlgos"
+	infoDryRunAcct  = "  %s: %d MicroAlgos (%s)"
+	programError    = "Cannot compile program %s: %s"
+	disassembleErr  = "Cannot disassemble program %s: %s"
+	malformedArg    = "Cannot base64-decode program argument %s: %s"
+	programSendSign = "-s is not meaningful with --from-program"
+	compileMultiOut = "-o is not meaningful with more than one input file"
 
 	infoAutoFeeSet = "Automatically set fee to %d MicroAlgos"
 
+	// Asset
+	infoAssetTxIssued     = "Issued %s transaction %s. Fee set to %d"
+	infoAssetCreated      = "Created asset with asset index %d"
+	errorAssetNotFound    = "Asset %d not found in account %s"
+	malformedMetadataHash = "Cannot base64-decode metadata hash %s: %s"
+
 	loggingNotConfigured = "Remote logging is not currently configured and won't be enabled"
 	loggingNotEnabled    = "Remote logging is current disabled"
 	loggingEnabled       = "Remote logging is enabled.  Node = %s, Guid = %s"
@@ -119,12 +146,20 @@ const (
 	errorCouldntExportMDK        = "Couldn't export master derivation key: %s"
 	errorCouldntMakeMnemonic     = "Couldn't make mnemonic: %s"
 	errorCouldntListWallets      = "Couldn't list wallets: %s"
+	errorCouldntBackupWallet     = "Couldn't back up wallet: %s"
+	errorCouldntRestoreWallet    = "Couldn't restore wallet: %s"
+	infoWroteBackup              = "Wrote encrypted wallet backup to %s"
+	infoRestoredWallet           = "Restored wallet '%s' (ID: %s)"
 	errorPasswordConfirmation    = "Password confirmation did not match"
 	errorBadMnemonic             = "Problem with mnemonic: %s"
 	errorBadRecoveredKey         = "Recovered invalid key"
 	errorFailedToReadResponse    = "Couldn't read response: %s"
 	errorFailedToReadPassword    = "Couldn't read password: %s"
 
+	infoChooseBackupPasswordPrompt   = "Please choose a password for the backup: "
+	infoBackupPasswordPrompt         = "Please enter the password for backup '%s': "
+	infoChooseRestoredPasswordPrompt = "Please choose a password for the restored wallet: "
+
 	// Commands
 	infoPasswordPrompt       = "Please enter the password for wallet '%s': "
 	infoSetWalletToDefault   = "Set wallet '%s' to be the default wallet"
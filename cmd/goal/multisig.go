@@ -85,7 +85,9 @@ var addSigCmd = &cobra.Command{
 				reportErrorf(txDecodeError, txFilename, err)
 			}
 
-			msig, err := client.MultisigSignTransactionWithWallet(wh, pw, stxn.Txn, addr, stxn.Msig)
+			// a transaction from a rekeyed sender is signed by the
+			// multisig it was rekeyed to
+			msig, err := client.MultisigSignTransactionWithWalletAndSigner(wh, pw, stxn.Txn, addr, stxn.Msig, stxn.AuthAddr)
 			if err != nil {
 				reportErrorf(errorSigningTX, err)
 			}
@@ -52,6 +52,8 @@ func init() {
 	nodeCmd.AddCommand(generateTokenCmd)
 	nodeCmd.AddCommand(pendingTxnsCmd)
 	nodeCmd.AddCommand(waitCmd)
+	nodeCmd.AddCommand(catchupCmd)
+	nodeCmd.AddCommand(upgradeStatusCmd)
 
 	startCmd.Flags().StringVarP(&peerDial, "peer", "p", "", "Peer address to dial for initial connection")
 	startCmd.Flags().StringVarP(&listenIP, "listen", "l", "", "Endpoint / REST address to listen on")
@@ -344,6 +346,63 @@ var pendingTxnsCmd = &cobra.Command{
 	},
 }
 
+var catchupCmd = &cobra.Command{
+	Use:   "catchup [catchpoint]",
+	Short: "Catch up to a catchpoint",
+	Long:  "Makes the node catch up to the given catchpoint (in the form round#digest), downloading the account state from its peers instead of replaying every block up to it",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		onDataDirs(func(dataDir string) {
+			client := ensureAlgodClient(dataDir)
+			_, err := client.Catchup(args[0])
+			if err != nil {
+				reportErrorf(errorNodeCatchup, err)
+			}
+
+			reportInfof(infoNodeCatchup, args[0])
+		})
+	},
+}
+
+var upgradeStatusCmd = &cobra.Command{
+	Use:   "upgrade-status",
+	Short: "Show the progress of a consensus protocol upgrade",
+	Long:  "Show the current and next consensus protocol, the approvals of the next protocol against the upgrade threshold, the rounds at which the vote ends and the upgrade takes effect, and which protocol versions the proposers of recent blocks support",
+	Args:  validateNoPosArgsFn,
+	Run: func(cmd *cobra.Command, _ []string) {
+		onDataDirs(func(dataDir string) {
+			client := ensureAlgodClient(dataDir)
+			stat, err := client.UpgradeStatus()
+			if err != nil {
+				reportErrorf(errorNodeStatus, err)
+			}
+
+			if stat.NextProtocol == "" {
+				reportInfof(infoNodeNoUpgrade, stat.LastRound, stat.CurrentProtocol, stat.UpgradeThreshold, stat.UpgradeVoteRounds)
+			} else {
+				reportInfof(infoNodeUpgradeStatus, stat.LastRound, stat.CurrentProtocol, stat.NextProtocol, stat.NextProtocolSupported,
+					stat.NextProtocolApprovals, stat.UpgradeThreshold, stat.UpgradeVoteRounds, stat.NextProtocolVoteBefore, stat.NextProtocolSwitchOn)
+			}
+
+			if len(stat.Support) == 0 {
+				return
+			}
+			reportInfof(infoNodeUpgradeSupport, stat.FirstSampledRound, stat.LastRound, stat.OnlineStake)
+			for _, support := range stat.Support {
+				var share float64
+				if stat.OnlineStake > 0 {
+					share = 100 * float64(support.OnlineStake) / float64(stat.OnlineStake)
+				}
+				note := ""
+				if !support.Supported {
+					note = ", not supported by this node"
+				}
+				reportInfof(infoNodeUpgradeSupportVersion, support.Version, support.Proposers, support.Blocks, support.OnlineStake, share, note)
+			}
+		})
+	},
+}
+
 var waitCmd = &cobra.Command{
 	Use:   "wait",
 	Short: "Waits for the node to make progress",
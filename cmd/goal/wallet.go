@@ -20,6 +20,7 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"strings"
 
@@ -33,17 +34,31 @@ import (
 var (
 	recoverWallet     bool
 	defaultWalletName string
+	backupFile        string
+	restoreName       string
+	restoreRename     bool
 )
 
 func init() {
 	walletCmd.AddCommand(newWalletCmd)
 	walletCmd.AddCommand(listWalletsCmd)
+	walletCmd.AddCommand(backupWalletCmd)
+	walletCmd.AddCommand(restoreWalletCmd)
 
 	// Default wallet to use when -w not specified
 	walletCmd.Flags().StringVarP(&defaultWalletName, "default", "f", "", "Set the wallet with this name to be the default wallet")
 
 	// Should we recover the wallet?
 	newWalletCmd.Flags().BoolVarP(&recoverWallet, "recover", "r", false, "Recover the wallet from the backup mnemonic provided at wallet creation (NOT the mnemonic provided by goal account export or by algokey). Regenerate accounts in the wallet with `goal account new`")
+
+	backupWalletCmd.Flags().StringVarP(&walletName, "wallet", "w", "", "Set the wallet to back up")
+	backupWalletCmd.Flags().StringVarP(&backupFile, "out", "o", "", "Filename to write the encrypted backup to")
+	backupWalletCmd.MarkFlagRequired("out")
+
+	restoreWalletCmd.Flags().StringVarP(&backupFile, "in", "i", "", "Filename of the encrypted backup to restore")
+	restoreWalletCmd.Flags().StringVarP(&restoreName, "name", "n", "", "Name for the restored wallet (defaults to the name in the backup)")
+	restoreWalletCmd.Flags().BoolVar(&restoreRename, "rename", false, "If the wallet name or ID is already in use, restore under a new name or ID instead of failing")
+	restoreWalletCmd.MarkFlagRequired("in")
 }
 
 var walletCmd = &cobra.Command{
@@ -202,6 +217,93 @@ var listWalletsCmd = &cobra.Command{
 	},
 }
 
+var backupWalletCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Write an encrypted backup of a wallet to a file",
+	Long:  `Write an encrypted backup of a wallet to a file. Unlike the backup phrase, the backup also covers imported keys and multisig addresses.`,
+	Args:  validateNoPosArgsFn,
+	Run: func(cmd *cobra.Command, args []string) {
+		dataDir := ensureSingleDataDir()
+		client := ensureKmdClient(dataDir)
+		wh, pw := ensureWalletHandleMaybePassword(dataDir, walletName, true)
+
+		// Fetch a password for the backup
+		fmt.Printf(infoChooseBackupPasswordPrompt)
+		backupPassword := ensurePassword()
+
+		// Confirm the password
+		fmt.Printf(infoPasswordConfirmation)
+		passwordConfirmation := ensurePassword()
+
+		// Check the password confirmation
+		if !bytes.Equal(backupPassword, passwordConfirmation) {
+			reportErrorln(errorPasswordConfirmation)
+		}
+
+		backup, err := client.BackupWallet(wh, pw, backupPassword)
+		if err != nil {
+			reportErrorf(errorCouldntBackupWallet, err)
+		}
+
+		err = ioutil.WriteFile(backupFile, backup, 0600)
+		if err != nil {
+			reportErrorf(fileWriteError, backupFile, err)
+		}
+		reportInfof(infoWroteBackup, backupFile)
+	},
+}
+
+var restoreWalletCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore a wallet from an encrypted backup",
+	Long:  `Restore a wallet from a file written by goal wallet backup. The wallet keeps its original name unless --name is given.`,
+	Args:  validateNoPosArgsFn,
+	Run: func(cmd *cobra.Command, args []string) {
+		dataDir := ensureSingleDataDir()
+		accountList := makeAccountsList(dataDir)
+		client := ensureKmdClient(dataDir)
+
+		backup, err := ioutil.ReadFile(backupFile)
+		if err != nil {
+			reportErrorf(fileReadError, backupFile, err)
+		}
+
+		// Fetch the password the backup was made with
+		fmt.Printf(infoBackupPasswordPrompt, backupFile)
+		backupPassword := ensurePassword()
+
+		// Fetch a password for the restored wallet
+		fmt.Printf(infoChooseRestoredPasswordPrompt)
+		walletPassword := ensurePassword()
+
+		// Confirm the password
+		fmt.Printf(infoPasswordConfirmation)
+		passwordConfirmation := ensurePassword()
+
+		// Check the password confirmation
+		if !bytes.Equal(walletPassword, passwordConfirmation) {
+			reportErrorln(errorPasswordConfirmation)
+		}
+
+		wallet, err := client.RestoreWallet(backup, backupPassword, []byte(restoreName), walletPassword, restoreRename)
+		if err != nil {
+			reportErrorf(errorCouldntRestoreWallet, err)
+		}
+		reportInfof(infoRestoredWallet, wallet.Name, wallet.ID)
+
+		// Check if we're the only wallet
+		wallets, err := client.ListWallets()
+		if err != nil {
+			reportErrorf(errorCouldntListWallets, err)
+		}
+
+		// We are the only wallet -- make us the default
+		if len(wallets) == 1 {
+			accountList.setDefaultWalletID([]byte(wallet.ID))
+		}
+	},
+}
+
 func printWallets(dataDir string, wallets []kmdapi.APIV1Wallet) {
 	accountList := makeAccountsList(dataDir)
 	defaultWalletID := string(accountList.getDefaultWalletID())
@@ -0,0 +1,164 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/algorand/go-algorand/config"
+	"github.com/algorand/go-algorand/daemon/partsigner"
+	"github.com/algorand/go-algorand/data/account"
+	"github.com/algorand/go-algorand/logging"
+	"github.com/algorand/go-algorand/util/db"
+)
+
+const (
+	defaultSocketFileName    = "partsigner.sock"
+	highWaterFileName        = "partsigner-highwater.sqlite"
+	partsignerLogFileName    = "partsigner.log"
+	partsignerLogFilePerm    = 0640
+	partsignerSocketFilePerm = 0600
+)
+
+func main() {
+	keyDir := flag.String("d", "", "directory holding the participation key files to sign with")
+	socketPath := flag.String("s", "", "path of the unix socket to listen on (default <dir>/"+defaultSocketFileName+")")
+	flag.Parse()
+
+	log := logging.NewLogger()
+	log.SetLevel(logging.Info)
+
+	if *keyDir == "" {
+		log.Errorf("key directory (-d) is a required argument")
+		os.Exit(1)
+	}
+	if *socketPath == "" {
+		*socketPath = filepath.Join(*keyDir, defaultSocketFileName)
+	}
+
+	logFile, err := os.OpenFile(filepath.Join(*keyDir, partsignerLogFileName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, partsignerLogFilePerm)
+	if err != nil {
+		log.Errorf("failed to open log file: %v", err)
+		os.Exit(1)
+	}
+	log.SetOutput(logFile)
+
+	parts, err := loadParticipationKeys(*keyDir, log)
+	if err != nil {
+		log.Errorf("failed to load participation keys: %v", err)
+		os.Exit(1)
+	}
+
+	hwStore, err := db.MakeAccessor(filepath.Join(*keyDir, highWaterFileName), false, false)
+	if err != nil {
+		log.Errorf("failed to open high-water mark database: %v", err)
+		os.Exit(1)
+	}
+	defer hwStore.Close()
+
+	server, err := partsigner.MakeServer(parts, hwStore, log)
+	if err != nil {
+		log.Errorf("failed to start signer: %v", err)
+		os.Exit(1)
+	}
+
+	// A socket left behind by a previous run would make Listen fail
+	err = removeStaleSocket(*socketPath)
+	if err != nil {
+		log.Errorf("cannot listen on %s: %v", *socketPath, err)
+		os.Exit(1)
+	}
+
+	// Create the socket with its final permissions, so that no other user
+	// can connect to it before it is restricted
+	oldUmask := unix.Umask(0777 &^ partsignerSocketFilePerm)
+	listener, err := net.Listen("unix", *socketPath)
+	unix.Umask(oldUmask)
+	if err != nil {
+		log.Errorf("failed to listen on %s: %v", *socketPath, err)
+		os.Exit(1)
+	}
+
+	kill := make(chan os.Signal, 1)
+	signal.Notify(kill, os.Interrupt, unix.SIGTERM, unix.SIGINT)
+	go func() {
+		<-kill
+		listener.Close()
+	}()
+
+	log.Infof("signing with %d participation keys on %s", len(parts), *socketPath)
+	err = server.Serve(listener)
+	log.Infof("signer exiting: %v", err)
+}
+
+// removeStaleSocket removes the socket at path if no signer listens on it
+// anymore. It refuses to remove anything else.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+
+	conn, err := net.Dial("unix", path)
+	if err == nil {
+		conn.Close()
+		return fmt.Errorf("another signer is listening on %s", path)
+	}
+	return os.Remove(path)
+}
+
+// loadParticipationKeys restores every participation key file in dir.
+func loadParticipationKeys(dir string, log logging.Logger) ([]account.Participation, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var parts []account.Participation
+	for _, info := range files {
+		if !config.IsPartKeyFilename(info.Name()) {
+			continue
+		}
+
+		handle, err := db.MakeErasableAccessor(filepath.Join(dir, info.Name()))
+		if err != nil {
+			return nil, err
+		}
+		part, err := account.RestoreParticipation(handle)
+		if err != nil {
+			handle.Close()
+			return nil, err
+		}
+		log.Infof("loaded participation key for %v (%d-%d) from %s", part.Address(), part.FirstValid, part.LastValid, info.Name())
+		parts = append(parts, part)
+	}
+	return parts, nil
+}
@@ -0,0 +1,231 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+// protoreplay replays a range of blocks from the ledger of an archival node
+// under candidate consensus parameters, and reports the transactions and
+// blocks whose evaluation would differ from what the network agreed upon.
+// It only reads the blocks of the node's ledger, which it opens read-only,
+// so the node may keep running meanwhile.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/algorand/go-deadlock"
+
+	"github.com/algorand/go-algorand/config"
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/data"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/ledger"
+	"github.com/algorand/go-algorand/logging"
+	"github.com/algorand/go-algorand/protocol"
+	"github.com/algorand/go-algorand/util/execpool"
+)
+
+var dataDir = flag.String("d", "", "Data directory of an archival node, holding genesis.json and the ledger")
+var fromRound = flag.Uint64("from", 1, "First round to evaluate under the candidate parameters")
+var toRound = flag.Uint64("to", 0, "Last round to evaluate under the candidate parameters (default: latest round of the ledger)")
+var protoVersion = flag.String("proto", "", "Consensus version whose parameters are the candidate (default: the protocol of each block)")
+var paramsFile = flag.String("params", "", "JSON file of consensus parameters overriding the candidate ones")
+
+func main() {
+	deadlock.Opts.Disable = true
+
+	flag.Parse()
+
+	log := logging.Base()
+	log.SetLevel(logging.Warn)
+	log.SetOutput(os.Stderr)
+
+	if *dataDir == "" {
+		fmt.Fprintf(os.Stderr, "No data directory specified (-d)\n")
+		flag.Usage()
+		os.Exit(1)
+	}
+	if *fromRound == 0 {
+		fmt.Fprintf(os.Stderr, "Round 0 is the genesis block and cannot be replayed\n")
+		os.Exit(1)
+	}
+
+	var overrides []byte
+	if *paramsFile != "" {
+		var err error
+		overrides, err = ioutil.ReadFile(*paramsFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot read %s: %v\n", *paramsFile, err)
+			os.Exit(1)
+		}
+	}
+
+	genesis, err := bookkeeping.LoadGenesisFromFile(filepath.Join(*dataDir, config.GenesisJSONFile))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot load genesis: %v\n", err)
+		os.Exit(1)
+	}
+	genalloc, err := genesisBalances(genesis)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot load genesis allocation: %v\n", err)
+		os.Exit(1)
+	}
+	genesisHash := crypto.HashObj(genesis)
+
+	srcPrefix := filepath.Join(*dataDir, genesis.ID(), config.LedgerFilenamePrefix)
+	if _, err = os.Stat(srcPrefix + ".block.sqlite"); err != nil {
+		fmt.Fprintf(os.Stderr, "No ledger at %s: %v\n", srcPrefix, err)
+		os.Exit(1)
+	}
+	src, err := ledger.OpenBlockReader(srcPrefix)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot open ledger %s: %v\n", srcPrefix, err)
+		os.Exit(1)
+	}
+	defer src.Close()
+	latest, err := src.Latest()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot read ledger %s: %v\n", srcPrefix, err)
+		os.Exit(1)
+	}
+
+	// The replay ledger starts from genesis; it only ever holds the blocks
+	// agreed upon, so that every round is evaluated on the real state.
+	dstPrefix := fmt.Sprintf("protoreplay.%d", crypto.RandUint64())
+	dst, err := data.LoadLedger(log, dstPrefix, true, genesis.Proto, genalloc, genesis.ID(), genesisHash, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot create replay ledger: %v\n", err)
+		os.Exit(1)
+	}
+	defer dst.Close()
+
+	last := basics.Round(*toRound)
+	if last == 0 || last > latest {
+		last = latest
+	}
+	first := basics.Round(*fromRound)
+
+	cryptoPool := execpool.MakePool(nil)
+	defer cryptoPool.Shutdown()
+	verificationPool := execpool.MakeBacklog(cryptoPool, 2*cryptoPool.GetParallelism(), execpool.LowPriority, nil)
+	defer verificationPool.Shutdown()
+
+	var count int
+	for rnd := basics.Round(1); rnd <= last; rnd++ {
+		blk, cert, err := src.BlockCert(rnd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot read block %d (is the node archival?): %v\n", rnd, err)
+			os.Exit(1)
+		}
+
+		if rnd >= first {
+			proto, err := candidateParams(blk.CurrentProtocol, overrides)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+			diffs, err := dst.EvalWithParams(blk, proto, verificationPool)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Cannot evaluate block %d: %v\n", rnd, err)
+				os.Exit(1)
+			}
+			for _, diff := range diffs {
+				printDifference(diff)
+			}
+			count += len(diffs)
+		}
+
+		err = dst.AddBlock(blk, cert)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot replay block %d: %v\n", rnd, err)
+			os.Exit(1)
+		}
+	}
+
+	fmt.Printf("Replayed rounds %d to %d: %d differences\n", first, last, count)
+	if count > 0 {
+		os.Exit(1)
+	}
+}
+
+// candidateParams returns the consensus parameters to evaluate a block of
+// protocol version current with: those of -proto or of current, with the
+// JSON overrides applied on top.
+func candidateParams(current protocol.ConsensusVersion, overrides []byte) (config.ConsensusParams, error) {
+	version := current
+	if *protoVersion != "" {
+		version = protocol.ConsensusVersion(*protoVersion)
+	}
+	params, ok := config.Consensus[version]
+	if !ok {
+		return config.ConsensusParams{}, fmt.Errorf("unknown consensus version %s", version)
+	}
+	if overrides == nil {
+		return params, nil
+	}
+
+	// Don't let the overrides modify the upgrades of the shared parameters.
+	approved := params.ApprovedUpgrades
+	params.ApprovedUpgrades = make(map[protocol.ConsensusVersion]bool, len(approved))
+	for v, approve := range approved {
+		params.ApprovedUpgrades[v] = approve
+	}
+	err := json.Unmarshal(overrides, &params)
+	if err != nil {
+		return config.ConsensusParams{}, fmt.Errorf("cannot parse %s: %v", *paramsFile, err)
+	}
+	return params, nil
+}
+
+func printDifference(diff ledger.EvalDifference) {
+	if diff.Txid == (transactions.Txid{}) {
+		fmt.Printf("round %d: %s\n", diff.Round, diff.Description)
+		return
+	}
+	fmt.Printf("round %d, txn %s: %s\n", diff.Round, diff.Txid, diff.Description)
+}
+
+// genesisBalances builds the genesis balances of the ledger from the
+// allocation of the genesis file, as algod does.
+func genesisBalances(genesis bookkeeping.Genesis) (data.GenesisBalances, error) {
+	genalloc := make(map[basics.Address]basics.AccountData)
+	for _, entry := range genesis.Allocation {
+		addr, err := basics.UnmarshalChecksumAddress(entry.Address)
+		if err != nil {
+			return data.GenesisBalances{}, fmt.Errorf("cannot parse genesis addr %s: %v", entry.Address, err)
+		}
+		if _, present := genalloc[addr]; present {
+			return data.GenesisBalances{}, fmt.Errorf("repeated allocation to %s", entry.Address)
+		}
+		genalloc[addr] = entry.State
+	}
+
+	feeSink, err := basics.UnmarshalChecksumAddress(genesis.FeeSink)
+	if err != nil {
+		return data.GenesisBalances{}, fmt.Errorf("cannot parse fee sink addr %s: %v", genesis.FeeSink, err)
+	}
+	rewardsPool, err := basics.UnmarshalChecksumAddress(genesis.RewardsPool)
+	if err != nil {
+		return data.GenesisBalances{}, fmt.Errorf("cannot parse rewards pool addr %s: %v", genesis.RewardsPool, err)
+	}
+
+	return data.MakeTimestampedGenesisBalances(genalloc, feeSink, rewardsPool, genesis.Timestamp), nil
+}
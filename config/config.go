@@ -201,6 +201,58 @@ type ConsensusParams struct {
 
 	// domain-separated credentials
 	CredentialDomainSeparationEnabled bool
+
+	// support for transactions that create, configure, freeze and
+	// transfer user-issued assets
+	Asset bool
+
+	// max number of assets per account
+	MaxAssetsPerAccount int
+
+	// max length of asset name
+	MaxAssetNameBytes int
+
+	// max length of asset unit name
+	MaxAssetUnitNameBytes int
+
+	// max length of asset url
+	MaxAssetURLBytes int
+
+	// count the number of transactions committed in the ledger in the
+	// block header, which is used to allocate unique asset indexes
+	TxnCounter bool
+
+	// support for transaction groups, which are accepted or
+	// rejected as a whole
+	SupportTxGroups bool
+
+	// max group size
+	MaxTxGroupSize int
+
+	// support for logic sigs: the highest program version that can be
+	// evaluated, or zero if logic sigs are not supported
+	LogicSigVersion uint64
+
+	// max size of a logic sig program and its arguments, in bytes
+	LogicSigMaxSize uint64
+
+	// max total cost of the opcodes in a logic sig program
+	LogicSigMaxCost uint64
+
+	// number of rounds between catchpoints, or zero if catchpoints are
+	// not supported.  A catchpoint at round R covers the account state
+	// MaxBalLookback rounds before it, and block R+MaxBalLookback
+	// commits to it.
+	CatchpointInterval uint64
+
+	// support for transaction leases, which prevent other transactions
+	// with the same (sender, lease) from being committed until the
+	// lease expires
+	SupportTransactionLeases bool
+
+	// support for rekeying accounts to a new spending key, and for
+	// transactions authorized by an account's AuthAddr
+	SupportRekeying bool
 }
 
 // Consensus tracks the protocol-level settings for different versions of the
@@ -440,6 +492,39 @@ func initConsensusProtocols() {
 
 	// v16 can be upgraded to v17.
 	v16.ApprovedUpgrades[protocol.ConsensusV17] = true
+
+	// ConsensusFuture is used to test features that are implemented
+	// but not yet released in a production protocol version.
+	vFuture := v17
+	vFuture.ApprovedUpgrades = map[protocol.ConsensusVersion]bool{}
+
+	// Enable assets and the transaction counter they rely on.
+	vFuture.Asset = true
+	vFuture.TxnCounter = true
+	vFuture.MaxAssetsPerAccount = 1000
+	vFuture.MaxAssetNameBytes = 32
+	vFuture.MaxAssetUnitNameBytes = 8
+	vFuture.MaxAssetURLBytes = 32
+
+	// Enable transaction groups.
+	vFuture.SupportTxGroups = true
+	vFuture.MaxTxGroupSize = 16
+
+	// Enable logic sigs.
+	vFuture.LogicSigVersion = 1
+	vFuture.LogicSigMaxSize = 1000
+	vFuture.LogicSigMaxCost = 20000
+
+	// Enable catchpoints.
+	vFuture.CatchpointInterval = 10000
+
+	// Enable transaction leases.
+	vFuture.SupportTransactionLeases = true
+
+	// Enable rekeying.
+	vFuture.SupportRekeying = true
+
+	Consensus[protocol.ConsensusFuture] = vFuture
 }
 
 func initConsensusTestProtocols() {
@@ -544,6 +629,20 @@ type Local struct {
 	TLSCertFile string
 	TLSKeyFile  string
 
+	// TLSCACertFile is a PEM file of the certificate authorities trusted to sign the certificates of peers.
+	// If empty, the system roots are used to verify the relays we connect to, and incoming peers are not asked
+	// for a certificate.
+	TLSCACertFile string
+
+	// EnableOutgoingTLS dials peers whose address has no scheme over TLS, both for gossip and for fetching blocks
+	// and transactions.  If TLSCertFile and TLSKeyFile are set, the node also presents that certificate to its peers.
+	EnableOutgoingTLS bool
+
+	// RequireMutualTLS requires every connection to be mutually authenticated: incoming peers must present a
+	// certificate signed by TLSCACertFile, and every outgoing connection is made over TLS with our certificate.
+	// It is meant for private networks, and needs TLSCertFile, TLSKeyFile and TLSCACertFile.
+	RequireMutualTLS bool
+
 	// Logging
 	BaseLoggerDebugLevel uint32
 	// if this is 0, do not produce agreement.cadaver
@@ -569,10 +668,16 @@ type Local struct {
 	// additional information to allow them to prioritize our connection.
 	AnnounceParticipationKey bool
 
-	// PriorityPeers specifies peer IP addresses that should always get
-	// outgoing broadcast messages from this node.
+	// PriorityPeers specifies peer IP addresses, or base64-encoded peer
+	// identity keys, that should always get outgoing broadcast messages
+	// from this node.  A peer matches by key only once it has proven that
+	// it holds the key.
 	PriorityPeers map[string]bool
 
+	// DisablePeerBans turns off the temporary bans of peers that send
+	// invalid messages, violate the network protocol or are too slow.
+	DisablePeerBans bool
+
 	// To make sure the algod process does not run out of FDs, algod ensures
 	// that RLIMIT_NOFILE exceeds the max number of incoming connections (i.e.,
 	// IncomingConnectionsLimit) by at least ReservedFDs.  ReservedFDs are meant
@@ -621,6 +726,14 @@ type Local struct {
 	// TxPoolSize is the number of transactions that fit in the transaction pool
 	TxPoolSize int
 
+	// TxPoolMaxPerSender is the number of transactions each sender may have
+	// pending in the transaction pool.  0 means no limit.
+	TxPoolMaxPerSender int
+
+	// TxPoolReplaceByNote allows a pending transaction that sets no lease to
+	// be replaced by one with the same sender, first valid round and note.
+	TxPoolReplaceByNote bool
+
 	// number of seconds allowed for syncing transactions
 	TxSyncTimeoutSeconds int64
 
@@ -681,6 +794,26 @@ type Local struct {
 
 	// ForceRelayMessages indicates whether the network library relay messages even in the case that no NetAddress was specified.
 	ForceRelayMessages bool
+
+	// CatchpointFileHistoryLength is the number of catchpoint files that the node writes and serves to
+	// peers doing a fast catchup.  0 means that the node does not write catchpoint files.
+	CatchpointFileHistoryLength int
+
+	// BlockRetentionRounds is the number of most recent rounds of blocks and certificates that a non-archival
+	// node keeps.  Older blocks are deleted in the background, except for those the ledger itself still needs.
+	// 0 means that the node keeps only the blocks that the ledger needs.
+	BlockRetentionRounds uint64
+
+	// EnableAccountHistory indicates whether the ledger records the account state of every round, so that the
+	// API can return the state of an account at any round. Rounds before the node enabled it are backfilled in the
+	// background by replaying the stored blocks.
+	// Note -- Account history is only recorded on Archival nodes
+	EnableAccountHistory bool
+
+	// ParticipationSignerSocket is the path of the unix socket of a participation signer (cmd/partsigner).
+	// When set, the node does not load participation keys from its data directory, and instead asks the
+	// signer for VRF proofs and vote signatures.  A relative path is relative to the data directory.
+	ParticipationSignerSocket string
 }
 
 // Filenames of config files within the configdir (e.g. ~/.algorand)
@@ -698,6 +831,14 @@ const LedgerFilenamePrefix = "ledger"
 // It is used to recover from node crashes.
 const CrashFilename = "crash.sqlite"
 
+// PeerIdentityFilename is the name of the file holding the key this node
+// proves to its gossip peers.
+const PeerIdentityFilename = "peer_identity.key"
+
+// CatchpointDirectory is the name of the directory where catchpoint
+// files are written, within the genesis directory.
+const CatchpointDirectory = "catchpoints"
+
 // LoadConfigFromDisk returns a Local config structure based on merging the defaults
 // with settings loaded from the config file from the custom dir.  If the custom file
 // cannot be loaded, the default config is returned (with the error from loading the
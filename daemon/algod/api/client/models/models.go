@@ -33,38 +33,144 @@ type Account struct {
 	// Round indicates the round for which this information is relevant
 	//
 	// required: true
-	Round uint64 `json:"round"`
+	Round uint64 `json:"round" codec:"round"`
 
 	// Address indicates the account public key
 	// Required: true
-	Address string `json:"address"`
+	Address string `json:"address" codec:"address"`
 
 	// Amount indicates the total number of MicroAlgos in the account,
 	// including not-yet-claimed (pending) rewards.
 	// Required: true
-	Amount uint64 `json:"amount"`
+	Amount uint64 `json:"amount" codec:"amount"`
 
 	// PendingRewards specifies the amount of MicroAlgos of pending
 	// rewards in this account.
 	// Required: true
-	PendingRewards uint64 `json:"pendingrewards"`
+	PendingRewards uint64 `json:"pendingrewards" codec:"pendingrewards"`
 
 	// AmountWithoutPendingRewards specifies the amount of MicroAlgos in
 	// the account, without the pending rewards.
 	// Required: true
-	AmountWithoutPendingRewards uint64 `json:"amountwithoutpendingrewards"`
+	AmountWithoutPendingRewards uint64 `json:"amountwithoutpendingrewards" codec:"amountwithoutpendingrewards"`
 
 	// Rewards indicates the total rewards of MicroAlgos the account has received
 	//
 	// required: true
-	Rewards uint64 `json:"rewards"`
+	Rewards uint64 `json:"rewards" codec:"rewards"`
 
 	// Status indicates the delegation status of the account's MicroAlgos
 	// Offline - indicates that the associated account is delegated.
 	// Online  - indicates that the associated account used as part of the delegation pool.
 	// NotParticipating - indicates that the associated account is neither a delegator nor a delegate.
 	// Required: true
-	Status string `json:"status"`
+	Status string `json:"status" codec:"status"`
+
+	// AssetParams specifies the parameters of assets created by this account.
+	//
+	// required: false
+	AssetParams map[uint64]AssetParams `json:"thisassettotal,omitempty" codec:"thisassettotal,omitempty"`
+
+	// Assets specifies the holdings of assets by this account,
+	// indexed by the asset ID.
+	//
+	// required: false
+	Assets map[uint64]AssetHolding `json:"assets,omitempty" codec:"assets,omitempty"`
+
+	// AuthAddr indicates the address that is authorized to sign
+	// transactions for this account, if it has been rekeyed.
+	//
+	// required: false
+	AuthAddr string `json:"authaddr,omitempty" codec:"authaddr,omitempty"`
+}
+
+// AssetParams specifies the parameters for an asset.
+// swagger:model AssetParams
+type AssetParams struct {
+	// Creator specifies the address that created this asset.
+	// This is the address where the parameters for this asset
+	// can be found, and also the address where unwanted asset
+	// units can be sent in the worst case.
+	//
+	// required: true
+	Creator string `json:"creator" codec:"creator"`
+
+	// Total specifies the total number of units of this asset.
+	//
+	// required: true
+	Total uint64 `json:"total" codec:"total"`
+
+	// DefaultFrozen specifies whether slots for this asset
+	// in user accounts are frozen by default.
+	//
+	// required: false
+	DefaultFrozen bool `json:"defaultfrozen" codec:"defaultfrozen"`
+
+	// UnitName specifies a hint for the name of a unit of
+	// this asset.
+	//
+	// required: false
+	UnitName string `json:"unitname,omitempty" codec:"unitname,omitempty"`
+
+	// AssetName specifies a hint for the name of the asset.
+	//
+	// required: false
+	AssetName string `json:"assetname,omitempty" codec:"assetname,omitempty"`
+
+	// URL specifies a URL where more information about the asset can be
+	// retrieved
+	//
+	// required: false
+	URL string `json:"url,omitempty" codec:"url,omitempty"`
+
+	// MetadataHash specifies a commitment to some unspecified asset
+	// metadata. The format of this metadata is up to the application.
+	//
+	// required: false
+	MetadataHash []byte `json:"metadatahash,omitempty" codec:"metadatahash,omitempty"`
+
+	// ManagerAddr specifies the address used to manage the keys of this
+	// asset and to destroy it.
+	//
+	// required: false
+	ManagerAddr string `json:"managerkey,omitempty" codec:"managerkey,omitempty"`
+
+	// ReserveAddr specifies the address holding reserve (non-minted)
+	// units of this asset.
+	//
+	// required: false
+	ReserveAddr string `json:"reserveaddr,omitempty" codec:"reserveaddr,omitempty"`
+
+	// FreezeAddr specifies the address used to freeze holdings of
+	// this asset.  If empty, freezing is not permitted.
+	//
+	// required: false
+	FreezeAddr string `json:"freezeaddr,omitempty" codec:"freezeaddr,omitempty"`
+
+	// ClawbackAddr specifies the address used to clawback holdings of
+	// this asset.  If empty, clawback is not permitted.
+	//
+	// required: false
+	ClawbackAddr string `json:"clawbackaddr,omitempty" codec:"clawbackaddr,omitempty"`
+}
+
+// AssetHolding describes an asset held by an account.
+// swagger:model AssetHolding
+type AssetHolding struct {
+	// Creator specifies the address that created this asset.
+	//
+	// required: true
+	Creator string `json:"creator" codec:"creator"`
+
+	// Amount specifies the number of units held.
+	//
+	// required: true
+	Amount uint64 `json:"amount" codec:"amount"`
+
+	// Frozen specifies whether this holding is frozen.
+	//
+	// required: false
+	Frozen bool `json:"frozen" codec:"frozen"`
 }
 
 // Block contains a block information
@@ -73,51 +179,51 @@ type Block struct {
 
 	// CurrentProtocol is a string that represents the current protocol
 	// Required: true
-	CurrentProtocol string `json:"currentProtocol"`
+	CurrentProtocol string `json:"currentProtocol" codec:"currentProtocol"`
 
 	// Hash is the current block hash
 	// Required: true
-	Hash string `json:"hash"`
+	Hash string `json:"hash" codec:"hash"`
 
 	// NextProtocol is a string that represents the next proposed protocol
 	// Required: true
-	NextProtocol string `json:"nextProtocol"`
+	NextProtocol string `json:"nextProtocol" codec:"nextProtocol"`
 
 	// NextProtocolApprovals is the number of blocks which approved the protocol upgrade
 	// Required: true
-	NextProtocolApprovals uint64 `json:"nextProtocolApprovals"`
+	NextProtocolApprovals uint64 `json:"nextProtocolApprovals" codec:"nextProtocolApprovals"`
 
 	// NextProtocolSwitchOn is the round on which the protocol upgrade will take effect
 	// Required: true
-	NextProtocolSwitchOn uint64 `json:"nextProtocolSwitchOn"`
+	NextProtocolSwitchOn uint64 `json:"nextProtocolSwitchOn" codec:"nextProtocolSwitchOn"`
 
 	// NextProtocolVoteBefore is the deadline round for this protocol upgrade (No votes will be consider after this round)
 	// Required: true
-	NextProtocolVoteBefore uint64 `json:"nextProtocolVoteBefore"`
+	NextProtocolVoteBefore uint64 `json:"nextProtocolVoteBefore" codec:"nextProtocolVoteBefore"`
 
 	// Period is the period on which the block was confirmed
 	// Required: true
-	Period uint64 `json:"period"`
+	Period uint64 `json:"period" codec:"period"`
 
 	// PreviousBlockHash is the previous block hash
 	// Required: true
-	PreviousBlockHash string `json:"previousBlockHash"`
+	PreviousBlockHash string `json:"previousBlockHash" codec:"previousBlockHash"`
 
 	// Proposer is the address of this block proposer
 	// Required: true
-	Proposer string `json:"proposer"`
+	Proposer string `json:"proposer" codec:"proposer"`
 
 	// Round is the current round on which this block was appended to the chain
 	// Required: true
-	Round uint64 `json:"round"`
+	Round uint64 `json:"round" codec:"round"`
 
 	// Seed is the sortition seed
 	// Required: true
-	Seed string `json:"seed"`
+	Seed string `json:"seed" codec:"seed"`
 
 	// TimeStamp in seconds since epoch
 	// Required: true
-	Timestamp int64 `json:"timestamp"`
+	Timestamp int64 `json:"timestamp" codec:"timestamp"`
 
 	// TransactionsRoot authenticates the set of transactions appearing in the block.
 	// More specifically, it's the root of a merkle tree whose leaves are the block's Txids, in lexicographic order.
@@ -125,33 +231,33 @@ type Block struct {
 	// Note that the TxnRoot does not authenticate the signatures on the transactions, only the transactions themselves.
 	// Two blocks with the same transactions but in a different order and with different signatures will have the same TxnRoot.
 	// Required: true
-	TransactionsRoot string `json:"txnRoot"`
+	TransactionsRoot string `json:"txnRoot" codec:"txnRoot"`
 
 	// RewardsLevel specifies how many rewards, in MicroAlgos,
 	// have been distributed to each config.Protocol.RewardUnit
 	// of MicroAlgos since genesis.
 	// Required: true
-	RewardsLevel uint64 `json:"reward"`
+	RewardsLevel uint64 `json:"reward" codec:"reward"`
 
 	// The number of new MicroAlgos added to the participation stake from rewards at the next round.
 	// Required: true
-	RewardsRate uint64 `json:"rate"`
+	RewardsRate uint64 `json:"rate" codec:"rate"`
 
 	// The number of leftover MicroAlgos after the distribution of RewardsRate/rewardUnits
 	// MicroAlgos for every reward unit in the next round.
 	// Required: true
-	RewardsResidue uint64 `json:"frac"`
+	RewardsResidue uint64 `json:"frac" codec:"frac"`
 
 	// UpgradeApprove indicates a yes vote for the current proposal
 	// Required: true
-	UpgradeApprove *bool `json:"upgradeApprove"`
+	UpgradeApprove *bool `json:"upgradeApprove" codec:"upgradeApprove"`
 
 	// UpgradePropose indicates a proposed upgrade
 	// Required: true
-	UpgradePropose string `json:"upgradePropose"`
+	UpgradePropose string `json:"upgradePropose" codec:"upgradePropose"`
 
 	// txns
-	Txns TransactionList `json:"txns,omitempty"`
+	Txns TransactionList `json:"txns,omitempty" codec:"txns,omitempty"`
 }
 
 // NodeStatus contains the information about a node status
@@ -160,31 +266,31 @@ type NodeStatus struct {
 
 	// CatchupTime in nanoseconds
 	// Required: true
-	CatchupTime int64 `json:"catchupTime"`
+	CatchupTime int64 `json:"catchupTime" codec:"catchupTime"`
 
 	// LastRound indicates the last round seen
 	// Required: true
-	LastRound uint64 `json:"lastRound"`
+	LastRound uint64 `json:"lastRound" codec:"lastRound"`
 
 	// LastVersion indicates the last consensus version supported
 	// Required: true
-	LastVersion string `json:"lastConsensusVersion"`
+	LastVersion string `json:"lastConsensusVersion" codec:"lastConsensusVersion"`
 
 	// NextVersion of consensus protocol to use
 	// Required: true
-	NextVersion string `json:"nextConsensusVersion"`
+	NextVersion string `json:"nextConsensusVersion" codec:"nextConsensusVersion"`
 
 	// NextVersionRound is the round at which the next consensus version will apply
 	// Required: true
-	NextVersionRound uint64 `json:"nextConsensusVersionRound"`
+	NextVersionRound uint64 `json:"nextConsensusVersionRound" codec:"nextConsensusVersionRound"`
 
 	// NextVersionSupported indicates whether the next consensus version is supported by this node
 	// Required: true
-	NextVersionSupported bool `json:"nextConsensusVersionSupported"`
+	NextVersionSupported bool `json:"nextConsensusVersionSupported" codec:"nextConsensusVersionSupported"`
 
 	// TimeSinceLastRound in nanoseconds
 	// Required: true
-	TimeSinceLastRound int64 `json:"timeSinceLastRound"`
+	TimeSinceLastRound int64 `json:"timeSinceLastRound" codec:"timeSinceLastRound"`
 }
 
 // PaymentTransactionType contains the additional fields for a payment Transaction
@@ -193,31 +299,93 @@ type PaymentTransactionType struct {
 
 	// Amount is the amount of MicroAlgos intended to be transferred
 	// Required: true
-	Amount uint64 `json:"amount"`
+	Amount uint64 `json:"amount" codec:"amount"`
 
 	// To is the receiver's address
 	// Required: true
-	To string `json:"to"`
+	To string `json:"to" codec:"to"`
 
 	// CloseRemainderTo is the address that receives the remainder of the
 	// account balance, if the transaction is closing the account.
 	// Required: false
-	CloseRemainderTo string `json:"close,omitempty"`
+	CloseRemainderTo string `json:"close,omitempty" codec:"close,omitempty"`
 
 	// CloseAmount is the amount transferred to CloseRemainderTo, if
 	// this transaction was confirmed.
 	// Required: false
-	CloseAmount uint64 `json:"closeamount,omitempty"`
+	CloseAmount uint64 `json:"closeamount,omitempty" codec:"closeamount,omitempty"`
 
 	// ToRewards is the amount of pending rewards applied to the To account
 	// as part of this transaction.
 	// Required: false
-	ToRewards uint64 `json:"torewards,omitempty"`
+	ToRewards uint64 `json:"torewards,omitempty" codec:"torewards,omitempty"`
 
 	// CloseRewards is the amount of pending rewards applied to the CloseRemainderTo
 	// account as part of this transaction.
 	// Required: false
-	CloseRewards uint64 `json:"closerewards,omitempty"`
+	CloseRewards uint64 `json:"closerewards,omitempty" codec:"closerewards,omitempty"`
+}
+
+// AssetConfigTransactionType contains the additional fields for an asset config transaction
+// swagger:model AssetConfigTransactionType
+type AssetConfigTransactionType struct {
+	// AssetID is the asset being configured (or empty if creating)
+	//
+	// required: false
+	AssetID uint64 `json:"id" codec:"id"`
+
+	// Params specifies the new asset parameters (or empty if deleting)
+	//
+	// required: false
+	Params AssetParams `json:"params" codec:"params"`
+}
+
+// AssetTransferTransactionType contains the additional fields for an asset transfer transaction
+// swagger:model AssetTransferTransactionType
+type AssetTransferTransactionType struct {
+	// AssetID is the asset being transferred
+	//
+	// required: true
+	AssetID uint64 `json:"id" codec:"id"`
+
+	// Amount is the amount being transferred.
+	//
+	// required: true
+	Amount uint64 `json:"amt" codec:"amt"`
+
+	// Sender is the source account (if using clawback).
+	//
+	// required: false
+	Sender string `json:"snd" codec:"snd"`
+
+	// Receiver is the recipient account.
+	//
+	// required: true
+	Receiver string `json:"rcv" codec:"rcv"`
+
+	// CloseTo is the destination for remaining funds (if closing).
+	//
+	// required: false
+	CloseTo string `json:"closeto" codec:"closeto"`
+}
+
+// AssetFreezeTransactionType contains the additional fields for an asset freeze transaction
+// swagger:model AssetFreezeTransactionType
+type AssetFreezeTransactionType struct {
+	// AssetID is the asset being frozen or unfrozen.
+	//
+	// required: true
+	AssetID uint64 `json:"id" codec:"id"`
+
+	// Account specifies the account where the asset is being frozen or thawed.
+	//
+	// required: true
+	Account string `json:"acct" codec:"acct"`
+
+	// NewFreezeStatus specifies the new freeze status.
+	//
+	// required: true
+	NewFreezeStatus bool `json:"freeze" codec:"freeze"`
 }
 
 // PendingTransactions represents a potentially truncated list of transactions currently in the
@@ -226,10 +394,196 @@ type PaymentTransactionType struct {
 type PendingTransactions struct {
 	// TruncatedTxns
 	// required: true
-	TruncatedTxns TransactionList `json:"truncatedTxns"`
+	TruncatedTxns TransactionList `json:"truncatedTxns" codec:"truncatedTxns"`
 	// TotalTxns
 	// required: true
-	TotalTxns uint64 `json:"totalTxns"`
+	TotalTxns uint64 `json:"totalTxns" codec:"totalTxns"`
+}
+
+// DryRunTransaction contains the result of evaluating a transaction in a dry run
+// swagger:model DryRunTransaction
+type DryRunTransaction struct {
+	// Transaction is the evaluated transaction, along with the rewards
+	// and closing amounts that applying it would produce
+	//
+	// required: true
+	Transaction Transaction `json:"txn" codec:"txn"`
+
+	// Error is set if the transaction, or the group it belongs to,
+	// would be rejected by the ledger
+	//
+	// required: false
+	Error string `json:"error,omitempty" codec:"error,omitempty"`
+
+	// Accounts holds the resulting state of the accounts that the
+	// transaction touches, after its whole group was applied
+	//
+	// required: false
+	Accounts []Account `json:"accounts,omitempty" codec:"accounts,omitempty"`
+}
+
+// DryRunResults contains the results of evaluating a list of transactions
+// in a dry run
+// swagger:model DryRunResults
+type DryRunResults struct {
+	// Round is the round in which the transactions were evaluated
+	//
+	// required: true
+	Round uint64 `json:"round" codec:"round"`
+
+	// Transactions holds the results, in the order the transactions were given
+	//
+	// required: true
+	Transactions []DryRunTransaction `json:"transactions" codec:"transactions"`
+}
+
+// PeerBan describes a peer that the node refuses to connect to until the
+// ban expires
+// swagger:model PeerBan
+type PeerBan struct {
+	// Peer is the host name or address of the peer, or its base64
+	// encoded identity key
+	//
+	// required: true
+	Peer string `json:"peer" codec:"peer"`
+
+	// Reason is the misbehavior that got the peer banned
+	//
+	// required: true
+	Reason string `json:"reason" codec:"reason"`
+
+	// Expires is the time the ban is lifted, in seconds since the epoch
+	//
+	// required: true
+	Expires int64 `json:"expires" codec:"expires"`
+
+	// Bans counts the times this peer was banned
+	//
+	// required: true
+	Bans uint64 `json:"bans" codec:"bans"`
+}
+
+// PeerBanList contains the peers that are currently banned
+// swagger:model PeerBanList
+type PeerBanList struct {
+	// required: true
+	Bans []PeerBan `json:"bans" codec:"bans"`
+}
+
+// DevModeStatus contains the state of a dev mode node
+// swagger:model DevModeStatus
+type DevModeStatus struct {
+	// Round is the latest round sealed by the node
+	//
+	// required: true
+	Round uint64 `json:"round" codec:"round"`
+
+	// ClockOffset is how far ahead of the wall clock, in seconds, the
+	// clock used for block timestamps is
+	//
+	// required: true
+	ClockOffset int64 `json:"clockOffset" codec:"clockOffset"`
+}
+
+// UpgradeSupport is the support for a protocol version among the proposers
+// of recent blocks
+// swagger:model UpgradeSupport
+type UpgradeSupport struct {
+	// Version is the consensus protocol version
+	//
+	// required: true
+	Version string `json:"version" codec:"version"`
+
+	// Proposers is the number of distinct accounts whose most recent
+	// block supports Version
+	//
+	// required: true
+	Proposers uint64 `json:"proposers" codec:"proposers"`
+
+	// Blocks is the number of sampled blocks that support Version
+	//
+	// required: true
+	Blocks uint64 `json:"blocks" codec:"blocks"`
+
+	// OnlineStake is the current online stake, in microAlgos, of the
+	// proposers that support Version
+	//
+	// required: true
+	OnlineStake uint64 `json:"onlineStake" codec:"onlineStake"`
+
+	// Supported indicates whether this node can run Version
+	//
+	// required: true
+	Supported bool `json:"supported" codec:"supported"`
+}
+
+// UpgradeStatus reports on the progress of a consensus protocol upgrade
+// swagger:model UpgradeStatus
+type UpgradeStatus struct {
+	// LastRound indicates the last round seen
+	//
+	// required: true
+	LastRound uint64 `json:"lastRound" codec:"lastRound"`
+
+	// CurrentProtocol is the consensus protocol version as of LastRound
+	//
+	// required: true
+	CurrentProtocol string `json:"currentProtocol" codec:"currentProtocol"`
+
+	// NextProtocol is the protocol version being voted on or waiting to
+	// take effect, if any
+	//
+	// required: true
+	NextProtocol string `json:"nextProtocol" codec:"nextProtocol"`
+
+	// NextProtocolApprovals is the number of blocks that approved NextProtocol
+	//
+	// required: true
+	NextProtocolApprovals uint64 `json:"nextProtocolApprovals" codec:"nextProtocolApprovals"`
+
+	// NextProtocolVoteBefore is the round by which the vote on NextProtocol ends
+	//
+	// required: true
+	NextProtocolVoteBefore uint64 `json:"nextProtocolVoteBefore" codec:"nextProtocolVoteBefore"`
+
+	// NextProtocolSwitchOn is the round at which NextProtocol takes effect
+	// if it is approved
+	//
+	// required: true
+	NextProtocolSwitchOn uint64 `json:"nextProtocolSwitchOn" codec:"nextProtocolSwitchOn"`
+
+	// NextProtocolSupported indicates whether this node can run NextProtocol
+	//
+	// required: true
+	NextProtocolSupported bool `json:"nextProtocolSupported" codec:"nextProtocolSupported"`
+
+	// UpgradeThreshold is the number of approvals an upgrade needs
+	// among UpgradeVoteRounds blocks
+	//
+	// required: true
+	UpgradeThreshold uint64 `json:"upgradeThreshold" codec:"upgradeThreshold"`
+
+	// UpgradeVoteRounds is the number of rounds the vote on an upgrade lasts
+	//
+	// required: true
+	UpgradeVoteRounds uint64 `json:"upgradeVoteRounds" codec:"upgradeVoteRounds"`
+
+	// FirstSampledRound is the first of the recent blocks whose proposers
+	// are tallied in Support
+	//
+	// required: true
+	FirstSampledRound uint64 `json:"firstSampledRound" codec:"firstSampledRound"`
+
+	// OnlineStake is the total online stake, in microAlgos, as of LastRound
+	//
+	// required: true
+	OnlineStake uint64 `json:"onlineStake" codec:"onlineStake"`
+
+	// Support tallies the protocol versions supported by the proposers of
+	// recent blocks, in decreasing order of online stake
+	//
+	// required: true
+	Support []UpgradeSupport `json:"support" codec:"support"`
 }
 
 // Supply represents the current supply of MicroAlgos in the system
@@ -238,15 +592,15 @@ type Supply struct {
 
 	// OnlineMoney
 	// Required: true
-	OnlineMoney uint64 `json:"onlineMoney"`
+	OnlineMoney uint64 `json:"onlineMoney" codec:"onlineMoney"`
 
 	// Round
 	// Required: true
-	Round uint64 `json:"round"`
+	Round uint64 `json:"round" codec:"round"`
 
 	// TotalMoney
 	// Required: true
-	TotalMoney uint64 `json:"totalMoney"`
+	TotalMoney uint64 `json:"totalMoney" codec:"totalMoney"`
 }
 
 // Transaction contains all fields common to all transactions and serves as an envelope to all transactions
@@ -255,58 +609,72 @@ type Supply struct {
 type Transaction struct {
 
 	// ConfirmedRound indicates the block number this transaction appeared in
-	ConfirmedRound uint64 `json:"round,omitempty"`
+	ConfirmedRound uint64 `json:"round,omitempty" codec:"round,omitempty"`
 
 	// PoolError indicates the transaction was evicted from this node's transaction
 	// pool (if non-empty).  A non-empty PoolError does not guarantee that the
 	// transaction will never be committed; other nodes may not have evicted the
 	// transaction and may attempt to commit it in the future.
-	PoolError string `json:"poolerror,omitempty"`
+	PoolError string `json:"poolerror,omitempty" codec:"poolerror,omitempty"`
 
 	// Fee is the transaction fee
 	// Required: true
-	Fee uint64 `json:"fee"`
+	Fee uint64 `json:"fee" codec:"fee"`
 
 	// FirstRound indicates the first valid round for this transaction
 	// Required: true
-	FirstRound uint64 `json:"first-round"`
+	FirstRound uint64 `json:"first-round" codec:"first-round"`
 
 	// From is the sender's address
 	// Required: true
-	From string `json:"from"`
+	From string `json:"from" codec:"from"`
 
 	// LastRound indicates the last valid round for this transaction
 	// Required: true
-	LastRound uint64 `json:"last-round"`
+	LastRound uint64 `json:"last-round" codec:"last-round"`
 
 	// Note is a free form data
-	Note []uint8 `json:"noteb64"`
+	Note []uint8 `json:"noteb64" codec:"noteb64"`
 
 	// TxID is the transaction ID
 	// Required: true
-	TxID string `json:"tx"`
+	TxID string `json:"tx" codec:"tx"`
 
 	// payment
-	Payment *PaymentTransactionType `json:"payment,omitempty"`
+	Payment *PaymentTransactionType `json:"payment,omitempty" codec:"payment,omitempty"`
+
+	// AssetConfig contains the additional fields for an asset config transaction
+	AssetConfig *AssetConfigTransactionType `json:"curcfg,omitempty" codec:"curcfg,omitempty"`
+
+	// AssetTransfer contains the additional fields for an asset transfer transaction
+	AssetTransfer *AssetTransferTransactionType `json:"curxfer,omitempty" codec:"curxfer,omitempty"`
+
+	// AssetFreeze contains the additional fields for an asset freeze transaction
+	AssetFreeze *AssetFreezeTransactionType `json:"curfrz,omitempty" codec:"curfrz,omitempty"`
 
 	// FromRewards is the amount of pending rewards applied to the From
 	// account as part of this transaction.
 	// Required: false
-	FromRewards uint64 `json:"fromrewards,omitempty"`
+	FromRewards uint64 `json:"fromrewards,omitempty" codec:"fromrewards,omitempty"`
 
 	// type
 	// Required: true
-	Type TxType `json:"type"`
+	Type TxType `json:"type" codec:"type"`
 
 	// Genesis ID
 	//
 	// required: true
-	GenesisID string `json:"genesisID"`
+	GenesisID string `json:"genesisID" codec:"genesisID"`
 
 	// Genesis hash
 	//
 	// required: true
-	GenesisHash []byte `json:"genesishashb64"`
+	GenesisHash []byte `json:"genesishashb64" codec:"genesishashb64"`
+
+	// Group is the transaction group this transaction belongs to, if any
+	//
+	// required: false
+	Group []byte `json:"group,omitempty" codec:"group,omitempty"`
 }
 
 // TransactionFee contains the suggested fee
@@ -315,7 +683,7 @@ type TransactionFee struct {
 
 	// Fee is transaction fee
 	// Required: true
-	Fee uint64 `json:"fee"`
+	Fee uint64 `json:"fee" codec:"fee"`
 }
 
 // TransactionParams contains the parameters that help a client construct
@@ -325,28 +693,87 @@ type TransactionParams struct {
 	// Fee is the suggested transaction fee
 	//
 	// required: true
-	Fee uint64 `json:"fee"`
+	Fee uint64 `json:"fee" codec:"fee"`
 
 	// Genesis ID
 	//
 	// required: true
-	GenesisID string `json:"genesisID"`
+	GenesisID string `json:"genesisID" codec:"genesisID"`
 
 	// Genesis hash
 	//
 	// required: true
-	GenesisHash []byte `json:"genesishashb64"`
+	GenesisHash []byte `json:"genesishashb64" codec:"genesishashb64"`
 
 	// LastRound indicates the last round seen
 	//
 	// required: true
-	LastRound uint64 `json:"lastRound"`
+	LastRound uint64 `json:"lastRound" codec:"lastRound"`
 
 	// ConsensusVersion indicates the consensus protocol version
 	// as of LastRound.
 	//
 	// required: true
-	ConsensusVersion string `json:"consensusVersion"`
+	ConsensusVersion string `json:"consensusVersion" codec:"consensusVersion"`
+
+	// PoolFeePerByte is the lowest fee per byte of encoded transaction
+	// that the node's transaction pool currently accepts.
+	//
+	// required: true
+	PoolFeePerByte uint64 `json:"poolFeePerByte" codec:"poolFeePerByte"`
+
+	// PoolPressureFeeCap is the most that the rise of PoolFeePerByte while
+	// the pool stays busy requires a transaction to pay, whatever its size.
+	// Evicting a transaction from a full pool may still take more.
+	//
+	// required: true
+	PoolPressureFeeCap uint64 `json:"poolPressureFeeCap" codec:"poolPressureFeeCap"`
+
+	// ReplacementFeeBump is the percentage by which the fee of a transaction
+	// must exceed the fee of the pending transaction it replaces, i.e. one
+	// with the same lease, or with the same sender, first valid round and note.
+	//
+	// required: true
+	ReplacementFeeBump uint64 `json:"replacementFeeBump" codec:"replacementFeeBump"`
+
+	// MaxPendingPerSender is the number of transactions each sender may have
+	// pending in the node's transaction pool, or 0 if there is no limit.
+	//
+	// required: true
+	MaxPendingPerSender uint64 `json:"maxPendingPerSender" codec:"maxPendingPerSender"`
+}
+
+// PendingTransactionEvent reports a change to the transaction pool of the node
+// swagger:model PendingTransactionEvent
+type PendingTransactionEvent struct {
+	// Type is what happened to the transaction: admitted, rebroadcast,
+	// evicted or committed
+	//
+	// required: true
+	Type string `json:"type" codec:"type"`
+
+	// TxID is the transaction ID
+	//
+	// required: true
+	TxID string `json:"tx" codec:"tx"`
+
+	// Round is the round that committed the transaction for committed
+	// events, and the last round of the node otherwise
+	//
+	// required: true
+	Round uint64 `json:"round" codec:"round"`
+
+	// Reason is why an evicted transaction left the pool: expired,
+	// fee-too-low, overspend, lease-taken, replaced, replacement-failed or
+	// invalid
+	Reason string `json:"reason,omitempty" codec:"reason,omitempty"`
+
+	// Error describes why an evicted transaction left the pool, as
+	// reported afterwards for the pending transaction
+	Error string `json:"error,omitempty" codec:"error,omitempty"`
+
+	// ReplacedBy is the ID of the transaction that replaced an evicted one
+	ReplacedBy string `json:"replacedBy,omitempty" codec:"replacedBy,omitempty"`
 }
 
 // TransactionID Description
@@ -355,7 +782,7 @@ type TransactionID struct {
 
 	// TxId is the string encoding of the transaction hash
 	// Required: true
-	TxID string `json:"txId"`
+	TxID string `json:"txId" codec:"txId"`
 }
 
 // TransactionList contains a list of transactions
@@ -364,7 +791,20 @@ type TransactionList struct {
 
 	// TransactionList is a list of rewards
 	// Required: true
-	Transactions []Transaction `json:"transactions"`
+	Transactions []Transaction `json:"transactions" codec:"transactions"`
+}
+
+// TransactionSearchResults contains a page of the transactions that match a search
+// swagger:model TransactionSearchResults
+type TransactionSearchResults struct {
+
+	// Transactions is the list of matching transactions
+	// Required: true
+	Transactions []Transaction `json:"transactions" codec:"transactions"`
+
+	// NextCursor fetches the next page of matching transactions when passed
+	// as the cursor of the same search. It is empty on the last page.
+	NextCursor string `json:"nextCursor,omitempty" codec:"nextCursor,omitempty"`
 }
 
 // TxType is the type of the transaction written to the ledger
@@ -380,13 +820,13 @@ type Version struct {
 
 	// genesis ID
 	// Required: true
-	GenesisID *string `json:"genesis_id"`
+	GenesisID *string `json:"genesis_id" codec:"genesis_id"`
 
 	// genesis hash
 	// Required: true
-	GenesisHash []byte `json:"genesis_hash_b64"`
+	GenesisHash []byte `json:"genesis_hash_b64" codec:"genesis_hash_b64"`
 
 	// versions
 	// Required: true
-	Versions []string `json:"versions"`
+	Versions []string `json:"versions" codec:"versions"`
 }
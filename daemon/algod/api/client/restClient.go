@@ -23,10 +23,12 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"mime"
 	"net/http"
 	"net/url"
 	"strings"
 
+	"github.com/algorand/go-codec/codec"
 	"github.com/google/go-querystring/query"
 
 	"github.com/algorand/go-algorand/daemon/algod/api/client/models"
@@ -38,8 +40,24 @@ const (
 	authHeader           = "X-Algo-API-Token"
 	healthCheckEndpoint  = "/health"
 	apiVersionPathPrefix = "/v1"
+
+	// acceptHeader asks for msgpack-encoded responses, which are faster to
+	// decode than JSON.  Routes that do not support msgpack respond with JSON.
+	acceptHeader       = "application/msgpack, application/json;q=0.9"
+	msgpackContentType = "application/msgpack"
 )
 
+// msgpackHandle decodes msgpack responses.  Unlike protocol.CodecHandle, it
+// ignores fields that the client does not know about, as encoding/json does,
+// so that older clients keep working against newer servers.
+var msgpackHandle *codec.MsgpackHandle
+
+func init() {
+	msgpackHandle = new(codec.MsgpackHandle)
+	msgpackHandle.ErrorIfNoField = false
+	msgpackHandle.PositiveIntUnsigned = true
+}
+
 // unversionedPaths ais a set of paths that should not be prefixed by the API version
 var unversionedPaths = map[string]bool{
 	"/versions": true,
@@ -48,7 +66,8 @@ var unversionedPaths = map[string]bool{
 
 // rawRequestPaths is a set of paths where the body should not be urlencoded
 var rawRequestPaths = map[string]bool{
-	"/transactions": true,
+	"/transactions":        true,
+	"/transactions/dryrun": true,
 }
 
 // RestClient manages the REST interface for a calling user.
@@ -77,6 +96,20 @@ func extractError(resp *http.Response) error {
 	return fmt.Errorf("HTTP %v: %s", resp.Status, errorBuf)
 }
 
+// isMsgpack checks whether the body of the response is msgpack-encoded.
+func isMsgpack(resp *http.Response) bool {
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return err == nil && mediaType == msgpackContentType
+}
+
+// newResponseDecoder returns a decoder for the body of resp, according to its content type.
+func newResponseDecoder(resp *http.Response) interface{ Decode(interface{}) error } {
+	if isMsgpack(resp) {
+		return codec.NewDecoder(resp.Body, msgpackHandle)
+	}
+	return json.NewDecoder(resp.Body)
+}
+
 // stripTransaction gets a transaction of the form "tx-XXXXXXXX" and truncates the "tx-" part, if it starts with "tx-"
 func stripTransaction(tx string) string {
 	if strings.HasPrefix(tx, "tx-") {
@@ -130,6 +163,7 @@ func (client RestClient) submitForm(response interface{}, path string, request i
 	if path != healthCheckEndpoint {
 		req.Header.Set(authHeader, client.apiToken)
 	}
+	req.Header.Set("Accept", acceptHeader)
 
 	httpClient := &http.Client{}
 	resp, err := httpClient.Do(req)
@@ -145,8 +179,11 @@ func (client RestClient) submitForm(response interface{}, path string, request i
 		return err
 	}
 
-	dec := json.NewDecoder(resp.Body)
-	return dec.Decode(&response)
+	if response == nil {
+		return nil
+	}
+	dec := newResponseDecoder(resp)
+	return dec.Decode(response)
 }
 
 // get performs a GET request to the specific path against the server
@@ -220,12 +257,46 @@ func (client RestClient) TransactionsByAddr(addr string, first, last, max uint64
 	return
 }
 
+// TransactionSearchParams are the filters of a transaction search; zero-valued
+// fields do not restrict the search. NotePrefix is base64 encoded, and Cursor
+// is the NextCursor returned by the previous page of the same search.
+type TransactionSearchParams struct {
+	Address    string `url:"address,omitempty"`
+	Type       string `url:"type,omitempty"`
+	MinAmount  uint64 `url:"minAmount,omitempty"`
+	MaxAmount  uint64 `url:"maxAmount,omitempty"`
+	FirstRound uint64 `url:"firstRound,omitempty"`
+	LastRound  uint64 `url:"lastRound,omitempty"`
+	CloseTo    string `url:"closeTo,omitempty"`
+	NotePrefix string `url:"notePrefix,omitempty"`
+	Cursor     string `url:"cursor,omitempty"`
+	Max        uint64 `url:"max,omitempty"`
+}
+
+// SearchTransactions returns a page of the confirmed transactions that match
+// the given filters. It requires the node to run the indexer.
+func (client RestClient) SearchTransactions(params TransactionSearchParams) (response models.TransactionSearchResults, err error) {
+	err = client.get(&response, "/transactions/search", params)
+	return
+}
+
 // AccountInformation also gets the AccountInformationResponse associated with the passed address
 func (client RestClient) AccountInformation(address string) (response models.Account, err error) {
 	err = client.get(&response, fmt.Sprintf("/account/%s", address), nil)
 	return
 }
 
+type accountInformationParams struct {
+	Round uint64 `url:"round"`
+}
+
+// AccountInformationAtRound gets the AccountInformationResponse associated with the passed address,
+// as of the given round
+func (client RestClient) AccountInformationAtRound(address string, round uint64) (response models.Account, err error) {
+	err = client.get(&response, fmt.Sprintf("/account/%s", address), accountInformationParams{round})
+	return
+}
+
 // TransactionInformation gets information about a specific transaction involving a specific account
 func (client RestClient) TransactionInformation(accountAddress, transactionID string) (response models.Transaction, err error) {
 	transactionID = stripTransaction(transactionID)
@@ -266,12 +337,169 @@ func (client RestClient) SendRawTransaction(txn transactions.SignedTxn) (respons
 	return
 }
 
+// SendRawTransactionGroup gets a SignedTxn group and broadcasts it to the network
+func (client RestClient) SendRawTransactionGroup(txgroup []transactions.SignedTxn) error {
+	// response is not terribly useful: it's the txid of the first transaction,
+	// which can be computed by the client anyway..
+	var enc []byte
+	for _, tx := range txgroup {
+		enc = append(enc, protocol.Encode(tx)...)
+	}
+
+	var response models.TransactionID
+	return client.post(&response, "/transactions", enc)
+}
+
+// DryRun evaluates signed or unsigned transactions against the latest ledger
+// state, without broadcasting them
+func (client RestClient) DryRun(txns []transactions.SignedTxn) (response models.DryRunResults, err error) {
+	var enc []byte
+	for _, tx := range txns {
+		enc = append(enc, protocol.Encode(tx)...)
+	}
+
+	err = client.post(&response, "/transactions/dryrun", enc)
+	return
+}
+
+// UpgradeStatus gets the progress of a consensus protocol upgrade
+func (client RestClient) UpgradeStatus() (response models.UpgradeStatus, err error) {
+	err = client.get(&response, "/status/upgrade", nil)
+	return
+}
+
+// PeerBans gets the peers that the node has temporarily banned
+func (client RestClient) PeerBans() (response models.PeerBanList, err error) {
+	err = client.get(&response, "/peers/bans", nil)
+	return
+}
+
+// DevModeAdvanceRound makes a dev mode node seal a block with its pending
+// transactions
+func (client RestClient) DevModeAdvanceRound() (response models.DevModeStatus, err error) {
+	err = client.post(&response, "/devmode/round", nil)
+	return
+}
+
+// DevModeAdvanceClock moves the clock of a dev mode node forward by the
+// given number of seconds
+func (client RestClient) DevModeAdvanceClock(seconds uint64) (response models.DevModeStatus, err error) {
+	err = client.post(&response, fmt.Sprintf("/devmode/clock/%d", seconds), nil)
+	return
+}
+
 // Block gets the block info for the given round
 func (client RestClient) Block(round uint64) (response models.Block, err error) {
 	err = client.get(&response, fmt.Sprintf("/block/%d", round), nil)
 	return
 }
 
+// StreamBlocks streams committed blocks starting at the given round, calling
+// handler for each one in order. It returns when ctx is cancelled, when handler
+// returns an error, or when the server ends the stream. The stream may end
+// while the node is still running (e.g. if the client fell behind); callers
+// should then resume from the round after the last block they received.
+func (client RestClient) StreamBlocks(ctx context.Context, round uint64, handler func(models.Block) error) error {
+	queryURL := client.serverURL
+	queryURL.Path = fmt.Sprintf("%s/block-stream/%d", apiVersionPathPrefix, round)
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(authHeader, client.apiToken)
+	req.Header.Set("Accept", acceptHeader)
+
+	httpClient := http.Client{}
+	resp, err := httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	err = extractError(resp)
+	if err != nil {
+		return err
+	}
+
+	dec := newResponseDecoder(resp)
+	for {
+		var block models.Block
+		err = dec.Decode(&block)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		err = handler(block)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// StreamPendingTransactionEvents streams the changes to the transaction pool
+// of the node, calling handler for each one in order. If txids is not empty,
+// only the events of those transactions are streamed. It returns when ctx is
+// cancelled, when handler returns an error, or when the server ends the stream
+// (e.g. if the client fell behind).
+func (client RestClient) StreamPendingTransactionEvents(ctx context.Context, txids []string, handler func(models.PendingTransactionEvent) error) error {
+	queryURL := client.serverURL
+	queryURL.Path = fmt.Sprintf("%s/transactions/pending-stream", apiVersionPathPrefix)
+	values := url.Values{}
+	for _, txid := range txids {
+		values.Add("txid", txid)
+	}
+	queryURL.RawQuery = values.Encode()
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(authHeader, client.apiToken)
+
+	httpClient := http.Client{}
+	resp, err := httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	err = extractError(resp)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var event models.PendingTransactionEvent
+		err = dec.Decode(&event)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		err = handler(event)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Catchup asks the node to catch up to the given catchpoint label
+func (client RestClient) Catchup(catchpoint string) (response models.NodeStatus, err error) {
+	err = client.post(&response, fmt.Sprintf("/catchup/%s", catchpoint), nil)
+	return
+}
+
 // GetGoRoutines gets a dump of the goroutines from pprof
 // Not supported
 func (client RestClient) GetGoRoutines(ctx context.Context) (goRoutines string, err error) {
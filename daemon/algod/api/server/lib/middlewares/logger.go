@@ -40,6 +40,13 @@ func (lrw *LoggingResponseWriter) WriteHeader(code int) {
 	lrw.ResponseWriter.WriteHeader(code)
 }
 
+// Flush sends any buffered data to the client, if the underlying ResponseWriter supports it
+func (lrw *LoggingResponseWriter) Flush() {
+	if f, ok := lrw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 // Logger is a gorilla/mux middleware to add log to the API
 func Logger(log logging.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -30,4 +30,15 @@ var (
 	errFailedGettingInformationFromIndexer = "failed retrieving information from the indexer"
 	errIndexerNotRunning                   = "indexer isn't running, this call is disabled"
 	errNoRoundsSpecified                   = "Indexer is not enabled, firstRound and lastRound must be specified"
+	errFailedStartingCatchup               = "failed to start catching up to the catchpoint"
+	errBlockPruned                         = "this is a non-archival node and the requested block has been pruned; the earliest available round is %d"
+	errFailedDryRun                        = "failed to evaluate the transactions"
+	errDryRunTooManyTxns                   = "more transactions than fit in a block"
+	errFailedDevModeRound                  = "failed to advance the round"
+	errFailedDevModeClock                  = "failed to advance the clock"
+	errFailedUpgradeStatus                 = "failed to retrieve the upgrade status"
+	errStreamingNotSupported               = "streaming responses are not supported by this connection"
+	errFailedParsingSearchParams           = "failed to parse the search parameters"
+	errAccountHistoryUnavailable           = "the account state of the requested round is not available; the earliest available round is %d. Enable EnableAccountHistory on an archival node to keep older rounds"
+	errMalformedCursor                     = "the cursor is malformed; use the cursor returned by a previous search"
 )
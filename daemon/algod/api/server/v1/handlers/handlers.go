@@ -17,8 +17,10 @@
 package handlers
 
 import (
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"time"
@@ -31,9 +33,11 @@ import (
 	"github.com/algorand/go-algorand/daemon/algod/api/server/lib"
 	"github.com/algorand/go-algorand/data/basics"
 	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/data/pools"
 	"github.com/algorand/go-algorand/data/transactions"
 	"github.com/algorand/go-algorand/ledger"
 	"github.com/algorand/go-algorand/node"
+	"github.com/algorand/go-algorand/node/indexer"
 	"github.com/algorand/go-algorand/protocol"
 )
 
@@ -54,7 +58,39 @@ func nodeStatus(node node.Full) (res NodeStatus, err error) {
 	}, nil
 }
 
-func paymentTxEncode(tx transactions.Transaction, ad transactions.ApplyData) Transaction {
+func txEncode(tx transactions.Transaction, ad transactions.ApplyData) Transaction {
+	res := Transaction{
+		Type:        tx.Type,
+		TxID:        tx.ID().String(),
+		From:        tx.Src().GetChecksumAddress().String(),
+		Fee:         tx.TxFee().Raw,
+		FirstRound:  uint64(tx.First()),
+		LastRound:   uint64(tx.Last()),
+		Note:        tx.Aux(),
+		FromRewards: ad.SenderRewards.Raw,
+		GenesisID:   tx.GenesisID,
+		GenesisHash: tx.GenesisHash[:],
+	}
+
+	if !tx.Group.IsZero() {
+		res.Group = tx.Group[:]
+	}
+
+	switch tx.Type {
+	case protocol.AssetConfigTx:
+		res.AssetConfig = assetConfigTxEncode(tx)
+	case protocol.AssetTransferTx:
+		res.AssetTransfer = assetTransferTxEncode(tx)
+	case protocol.AssetFreezeTx:
+		res.AssetFreeze = assetFreezeTxEncode(tx)
+	default:
+		res.Payment = paymentTxEncode(tx, ad)
+	}
+
+	return res
+}
+
+func paymentTxEncode(tx transactions.Transaction, ad transactions.ApplyData) *PaymentTransactionType {
 	payment := PaymentTransactionType{
 		To:           tx.Receiver.GetChecksumAddress().String(),
 		Amount:       tx.TxAmount().Raw,
@@ -67,28 +103,133 @@ func paymentTxEncode(tx transactions.Transaction, ad transactions.ApplyData) Tra
 		payment.CloseAmount = ad.ClosingAmount.Raw
 	}
 
-	return Transaction{
-		Type:        tx.Type,
-		TxID:        tx.ID().String(),
-		From:        tx.Src().GetChecksumAddress().String(),
-		Fee:         tx.TxFee().Raw,
-		FirstRound:  uint64(tx.First()),
-		LastRound:   uint64(tx.Last()),
-		Note:        tx.Aux(),
-		Payment:     &payment,
-		FromRewards: ad.SenderRewards.Raw,
-		GenesisID:   tx.GenesisID,
-		GenesisHash: tx.GenesisHash[:],
+	return &payment
+}
+
+func assetParams(creator basics.Address, params basics.AssetParams) AssetParams {
+	paramsModel := AssetParams{
+		Total:         params.Total,
+		DefaultFrozen: params.DefaultFrozen,
+		UnitName:      params.UnitName,
+		AssetName:     params.AssetName,
+		URL:           params.URL,
+	}
+
+	if params.MetadataHash != [32]byte{} {
+		paramsModel.MetadataHash = params.MetadataHash[:]
+	}
+
+	if !creator.IsZero() {
+		paramsModel.Creator = creator.String()
+	}
+	if !params.Manager.IsZero() {
+		paramsModel.ManagerAddr = params.Manager.String()
+	}
+	if !params.Reserve.IsZero() {
+		paramsModel.ReserveAddr = params.Reserve.String()
+	}
+	if !params.Freeze.IsZero() {
+		paramsModel.FreezeAddr = params.Freeze.String()
+	}
+	if !params.Clawback.IsZero() {
+		paramsModel.ClawbackAddr = params.Clawback.String()
+	}
+
+	return paramsModel
+}
+
+func assetConfigTxEncode(tx transactions.Transaction) *AssetConfigTransactionType {
+	params := assetParams(basics.Address{}, tx.AssetConfigTxnFields.AssetParams)
+
+	config := AssetConfigTransactionType{
+		AssetID: uint64(tx.AssetConfigTxnFields.ConfigAsset),
+		Params:  params,
+	}
+
+	return &config
+}
+
+func assetTransferTxEncode(tx transactions.Transaction) *AssetTransferTransactionType {
+	xfer := AssetTransferTransactionType{
+		AssetID:  uint64(tx.AssetTransferTxnFields.XferAsset),
+		Amount:   tx.AssetTransferTxnFields.AssetAmount,
+		Receiver: tx.AssetTransferTxnFields.AssetReceiver.String(),
+	}
+
+	if !tx.AssetTransferTxnFields.AssetSender.IsZero() {
+		xfer.Sender = tx.AssetTransferTxnFields.AssetSender.String()
+	}
+
+	if !tx.AssetTransferTxnFields.AssetCloseTo.IsZero() {
+		xfer.CloseTo = tx.AssetTransferTxnFields.AssetCloseTo.String()
+	}
+
+	return &xfer
+}
+
+func assetFreezeTxEncode(tx transactions.Transaction) *AssetFreezeTransactionType {
+	freeze := AssetFreezeTransactionType{
+		AssetID:         uint64(tx.AssetFreezeTxnFields.FreezeAsset),
+		Account:         tx.AssetFreezeTxnFields.FreezeAccount.String(),
+		NewFreezeStatus: tx.AssetFreezeTxnFields.AssetFrozen,
+	}
+
+	return &freeze
+}
+
+// accountDataEncode fills in the fields of account that come from the
+// account's record: its spending key, and the assets it created or holds.
+// Asset creators are looked up as of round.
+func accountDataEncode(n node.Full, account *Account, addr basics.Address, record basics.AccountData, round basics.Round) {
+	if record.AuthAddr != (basics.Address{}) {
+		account.AuthAddr = record.AuthAddr.GetUserAddress()
+	}
+
+	if len(record.AssetParams) > 0 {
+		account.AssetParams = make(map[uint64]AssetParams, len(record.AssetParams))
+		for idx, params := range record.AssetParams {
+			account.AssetParams[uint64(idx)] = assetParams(addr, params)
+		}
+	}
+
+	if len(record.Assets) > 0 {
+		account.Assets = make(map[uint64]AssetHolding, len(record.Assets))
+		for idx, holding := range record.Assets {
+			var creator string
+			creatorAddr, err := n.GetAssetCreator(idx, round)
+			if err == nil {
+				creator = creatorAddr.String()
+			}
+			account.Assets[uint64(idx)] = AssetHolding{
+				Creator: creator,
+				Amount:  holding.Amount,
+				Frozen:  holding.Frozen,
+			}
+		}
 	}
 }
 
 func txWithStatusEncode(tr node.TxnWithStatus) Transaction {
-	s := paymentTxEncode(tr.Txn.Txn, tr.ApplyData)
+	s := txEncode(tr.Txn.Txn, tr.ApplyData)
 	s.ConfirmedRound = uint64(tr.ConfirmedRound)
 	s.PoolError = tr.PoolError
 	return s
 }
 
+func txEventEncode(ev pools.TxEvent) PendingTransactionEvent {
+	event := PendingTransactionEvent{
+		Type:   string(ev.Type),
+		TxID:   ev.Txid.String(),
+		Round:  uint64(ev.Round),
+		Reason: string(ev.Reason),
+		Error:  ev.Error,
+	}
+	if ev.ReplacedBy != (transactions.Txid{}) {
+		event.ReplacedBy = ev.ReplacedBy.String()
+	}
+	return event
+}
+
 func blockEncode(b bookkeeping.Block, c agreement.Certificate) (Block, error) {
 	block := Block{
 		Hash:              crypto.Digest(b.Hash()).String(),
@@ -143,6 +284,7 @@ func Status(ctx lib.ReqContext, w http.ResponseWriter, r *http.Request) {
 	//     Summary: Gets the current node status.
 	//     Produces:
 	//     - application/json
+	//     - application/msgpack
 	//     Schemes:
 	//     - http
 	//     Responses:
@@ -160,7 +302,7 @@ func Status(ctx lib.ReqContext, w http.ResponseWriter, r *http.Request) {
 	}
 
 	response := StatusResponse{&nodeStatus}
-	SendJSON(response, w, ctx.Log)
+	SendResponse(response, w, r, ctx.Log)
 }
 
 // WaitForBlock is an httpHandler for route GET /v1/status/wait-for-block-after/{round:[0-9]+}
@@ -171,6 +313,7 @@ func WaitForBlock(ctx lib.ReqContext, w http.ResponseWriter, r *http.Request) {
 	//     Description: Waits for a block to appear after round {round} and returns the node's status at the time.
 	//     Produces:
 	//     - application/json
+	//     - application/msgpack
 	//     Schemes:
 	//     - http
 	//     Parameters:
@@ -210,7 +353,7 @@ func WaitForBlock(ctx lib.ReqContext, w http.ResponseWriter, r *http.Request) {
 	}
 
 	response := StatusResponse{&nodeStatus}
-	SendJSON(response, w, ctx.Log)
+	SendResponse(response, w, r, ctx.Log)
 }
 
 // RawTransaction is an httpHandler for route POST /v1/transactions
@@ -220,6 +363,7 @@ func RawTransaction(ctx lib.ReqContext, w http.ResponseWriter, r *http.Request)
 	//     Summary: Broadcasts a raw transaction to the network.
 	//     Produces:
 	//     - application/json
+	//     - application/msgpack
 	//     Consumes:
 	//     - application/x-binary
 	//     Schemes:
@@ -231,7 +375,7 @@ func RawTransaction(ctx lib.ReqContext, w http.ResponseWriter, r *http.Request)
 	//           type: string
 	//           format: binary
 	//         required: true
-	//         description: The byte encoded signed transaction to broadcast to network
+	//         description: The byte encoded signed transaction to broadcast to network, or the concatenated encodings of the members of a transaction group
 	//     Responses:
 	//       200:
 	//         "$ref": "#/responses/TransactionIDResponse"
@@ -243,20 +387,153 @@ func RawTransaction(ctx lib.ReqContext, w http.ResponseWriter, r *http.Request)
 	//         schema: {type: string}
 	//       401: { description: Invalid API Token }
 	//       default: { description: Unknown Error }
-	var st transactions.SignedTxn
-	err := protocol.NewDecoder(r.Body).Decode(&st)
+	var txgroup []transactions.SignedTxn
+	dec := protocol.NewDecoder(r.Body)
+	for {
+		var st transactions.SignedTxn
+		err := dec.Decode(&st)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			lib.ErrorResponse(w, http.StatusBadRequest, err, err.Error(), ctx.Log)
+			return
+		}
+		txgroup = append(txgroup, st)
+	}
+
+	if len(txgroup) == 0 {
+		err := errors.New("empty txgroup")
+		lib.ErrorResponse(w, http.StatusBadRequest, err, err.Error(), ctx.Log)
+		return
+	}
+
+	err := ctx.Node.BroadcastSignedTxGroup(txgroup)
+	if _, ok := err.(node.DevModeSealError); ok {
+		lib.ErrorResponse(w, http.StatusInternalServerError, err, err.Error(), ctx.Log)
+		return
+	}
 	if err != nil {
 		lib.ErrorResponse(w, http.StatusBadRequest, err, err.Error(), ctx.Log)
 		return
 	}
 
-	txid, err := ctx.Node.BroadcastSignedTxn(st)
+	// For backwards compatibility, return txid of first tx in group
+	txid := txgroup[0].ID()
+	SendResponse(TransactionIDResponse{&TransactionID{TxID: txid.String()}}, w, r, ctx.Log)
+}
+
+// DryRun is an httpHandler for route POST /v1/transactions/dryrun
+func DryRun(ctx lib.ReqContext, w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /v1/transactions/dryrun DryRun
+	// ---
+	//     Summary: Evaluates transactions against the latest ledger state, without broadcasting them.
+	//     Description: >
+	//       Evaluates the given transactions as if they were included in the next block, and
+	//       returns the outcome of each one: the error that would reject it, the rewards and
+	//       closing amounts it would produce, and the resulting state of the accounts it touches.
+	//       Nothing is committed to the ledger or sent to the network. Transactions may be signed
+	//       or unsigned; the signature of an unsigned transaction is not checked. Consecutive
+	//       transactions with the same group ID are evaluated as a group. The transactions
+	//       must fit in a single block of the current protocol.
+	//     Produces:
+	//     - application/json
+	//     - application/msgpack
+	//     Consumes:
+	//     - application/x-binary
+	//     Schemes:
+	//     - http
+	//     Parameters:
+	//       - name: rawtxns
+	//         in: body
+	//         schema:
+	//           type: string
+	//           format: binary
+	//         required: true
+	//         description: The concatenated byte encodings of the signed or unsigned transactions to evaluate
+	//     Responses:
+	//       200:
+	//         "$ref": "#/responses/DryRunResponse"
+	//       400:
+	//         description: Bad Request
+	//         schema: {type: string}
+	//       500:
+	//         description: Internal Error
+	//         schema: {type: string}
+	//       401: { description: Invalid API Token }
+	//       default: { description: Unknown Error }
+	stat, err := ctx.Node.Status()
 	if err != nil {
+		lib.ErrorResponse(w, http.StatusInternalServerError, err, errFailedRetrievingNodeStatus, ctx.Log)
+		return
+	}
+	proto := config.Consensus[stat.LastVersion]
+
+	// Every call runs its own evaluator, so only accept what a block
+	// could hold.
+	r.Body = http.MaxBytesReader(w, r.Body, int64(proto.MaxTxnBytesPerBlock))
+
+	var txns []transactions.SignedTxn
+	txnBytes := 0
+	dec := protocol.NewDecoder(r.Body)
+	for {
+		var st transactions.SignedTxn
+		err := dec.Decode(&st)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			lib.ErrorResponse(w, http.StatusBadRequest, err, err.Error(), ctx.Log)
+			return
+		}
+		txnBytes += len(protocol.Encode(st))
+		if txnBytes > proto.MaxTxnBytesPerBlock {
+			err := errors.New(errDryRunTooManyTxns)
+			lib.ErrorResponse(w, http.StatusBadRequest, err, err.Error(), ctx.Log)
+			return
+		}
+		txns = append(txns, st)
+	}
+
+	if len(txns) == 0 {
+		err := errors.New("no transactions to evaluate")
 		lib.ErrorResponse(w, http.StatusBadRequest, err, err.Error(), ctx.Log)
 		return
 	}
 
-	SendJSON(TransactionIDResponse{&TransactionID{TxID: txid.String()}}, w, ctx.Log)
+	res, err := ctx.Node.DryRun(txns)
+	if err != nil {
+		lib.ErrorResponse(w, http.StatusInternalServerError, err, errFailedDryRun, ctx.Log)
+		return
+	}
+
+	results := DryRunResults{
+		Round:        uint64(res.Round),
+		Transactions: make([]DryRunTransaction, len(res.Txns)),
+	}
+	for i, txr := range res.Txns {
+		result := DryRunTransaction{
+			Transaction: txEncode(txr.Txn.Txn, txr.ApplyData),
+		}
+		if txr.Err != nil {
+			result.Error = txr.Err.Error()
+		}
+		for _, br := range txr.Accounts {
+			account := Account{
+				Round:                       uint64(res.Round),
+				Address:                     br.Addr.GetUserAddress(),
+				Amount:                      br.MicroAlgos.Raw,
+				AmountWithoutPendingRewards: br.MicroAlgos.Raw,
+				Rewards:                     br.RewardedMicroAlgos.Raw,
+				Status:                      br.Status.String(),
+			}
+			accountDataEncode(ctx.Node, &account, br.Addr, br.AccountData, res.Round-1)
+			result.Accounts = append(result.Accounts, account)
+		}
+		results.Transactions[i] = result
+	}
+
+	SendResponse(DryRunResponse{&results}, w, r, ctx.Log)
 }
 
 // AccountInformation is an httpHandler for route GET /v1/account/{addr:[A-Z0-9]{KeyLength}}
@@ -267,6 +544,7 @@ func AccountInformation(ctx lib.ReqContext, w http.ResponseWriter, r *http.Reque
 	//     Description: Given a specific account public key, this call returns the accounts status, balance and spendable amounts
 	//     Produces:
 	//     - application/json
+	//     - application/msgpack
 	//     Schemes:
 	//     - http
 	//     Parameters:
@@ -276,12 +554,25 @@ func AccountInformation(ctx lib.ReqContext, w http.ResponseWriter, r *http.Reque
 	//         pattern: "[A-Z0-9]{58}"
 	//         required: true
 	//         description: An account public key
+	//       - name: round
+	//         in: query
+	//         type: integer
+	//         format: int64
+	//         minimum: 0
+	//         required: false
+	//         description: >
+	//           Return the account state as of this round rather than the latest one. Rounds older than the
+	//           balance lookback of the consensus protocol are available only on archival nodes that record
+	//           account history.
 	//     Responses:
 	//       200:
 	//         "$ref": '#/responses/AccountInformationResponse'
 	//       400:
 	//         description: Bad Request
 	//         schema: {type: string}
+	//       404:
+	//         description: The account state of the round is not available
+	//         schema: {type: string}
 	//       500:
 	//         description: Internal Error
 	//         schema: {type: string}
@@ -300,7 +591,36 @@ func AccountInformation(ctx lib.ReqContext, w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	amount, rewards, amountWithoutPendingRewards, status, round, err := ctx.Node.GetBalanceAndStatus(basics.Address(addr))
+	var amount, rewards, amountWithoutPendingRewards basics.MicroAlgos
+	var status basics.Status
+	var round basics.Round
+	if queryRound := r.FormValue("round"); queryRound != "" {
+		rnd, err := strconv.ParseUint(queryRound, 10, 64)
+		if err != nil {
+			lib.ErrorResponse(w, http.StatusBadRequest, err, errFailedParsingRoundNumber, ctx.Log)
+			return
+		}
+		round = basics.Round(rnd)
+
+		stat, err := ctx.Node.Status()
+		if err != nil {
+			lib.ErrorResponse(w, http.StatusInternalServerError, err, errFailedRetrievingNodeStatus, ctx.Log)
+			return
+		}
+		if round > stat.LastRound {
+			err = fmt.Errorf("round %d is past the latest round %d", round, stat.LastRound)
+			lib.ErrorResponse(w, http.StatusBadRequest, err, err.Error(), ctx.Log)
+			return
+		}
+
+		amount, rewards, amountWithoutPendingRewards, status, err = ctx.Node.GetBalanceAndStatusAt(basics.Address(addr), round)
+		if noHistory, ok := err.(ledger.ErrNoAccountHistory); ok {
+			lib.ErrorResponse(w, http.StatusNotFound, err, fmt.Sprintf(errAccountHistoryUnavailable, noHistory.Earliest), ctx.Log)
+			return
+		}
+	} else {
+		amount, rewards, amountWithoutPendingRewards, status, round, err = ctx.Node.GetBalanceAndStatus(basics.Address(addr))
+	}
 
 	if err != nil {
 		lib.ErrorResponse(w, http.StatusInternalServerError, err, errFailedLookingUpLedger, ctx.Log)
@@ -324,7 +644,15 @@ func AccountInformation(ctx lib.ReqContext, w http.ResponseWriter, r *http.Reque
 		Status:                      status.String(),
 	}
 
-	SendJSON(AccountInformationResponse{&accountInfo}, w, ctx.Log)
+	record, err := ctx.Node.GetAccountData(basics.Address(addr), round)
+	if err != nil {
+		lib.ErrorResponse(w, http.StatusInternalServerError, err, errFailedLookingUpLedger, ctx.Log)
+		return
+	}
+
+	accountDataEncode(ctx.Node, &accountInfo, basics.Address(addr), record, round)
+
+	SendResponse(AccountInformationResponse{&accountInfo}, w, r, ctx.Log)
 }
 
 // TransactionInformation is an httpHandler for route GET /v1/account/{addr:[A-Z0-9]{KeyLength}}/transaction/{txid:[A-Z0-9]+}
@@ -337,6 +665,7 @@ func TransactionInformation(ctx lib.ReqContext, w http.ResponseWriter, r *http.R
 	//       information. This call scans up to <CurrentProtocol>.MaxTxnLife blocks in the past.
 	//     Produces:
 	//     - application/json
+	//     - application/msgpack
 	//     Schemes:
 	//     - http
 	//     Parameters:
@@ -411,7 +740,7 @@ func TransactionInformation(ctx lib.ReqContext, w http.ResponseWriter, r *http.R
 			Body: &responseTxs,
 		}
 
-		SendJSON(response, w, ctx.Log)
+		SendResponse(response, w, r, ctx.Log)
 		return
 	}
 
@@ -437,6 +766,7 @@ func PendingTransactionInformation(ctx lib.ReqContext, w http.ResponseWriter, r
 	//       node no longer remembers it, and this will return an error.
 	//     Produces:
 	//     - application/json
+	//     - application/msgpack
 	//     Schemes:
 	//     - http
 	//     Parameters:
@@ -478,7 +808,7 @@ func PendingTransactionInformation(ctx lib.ReqContext, w http.ResponseWriter, r
 			Body: &responseTxs,
 		}
 
-		SendJSON(response, w, ctx.Log)
+		SendResponse(response, w, r, ctx.Log)
 		return
 	}
 
@@ -498,6 +828,7 @@ func GetPendingTransactions(ctx lib.ReqContext, w http.ResponseWriter, r *http.R
 	//       returns all pending transactions.
 	//     Produces:
 	//     - application/json
+	//     - application/msgpack
 	//     Schemes:
 	//     - http
 	//     Parameters:
@@ -537,7 +868,7 @@ func GetPendingTransactions(ctx lib.ReqContext, w http.ResponseWriter, r *http.R
 
 	responseTxs := make([]Transaction, len(txs))
 	for i, twr := range txs {
-		responseTxs[i] = paymentTxEncode(twr.Txn, transactions.ApplyData{})
+		responseTxs[i] = txEncode(twr.Txn, transactions.ApplyData{})
 	}
 
 	response := PendingTransactionsResponse{
@@ -549,7 +880,7 @@ func GetPendingTransactions(ctx lib.ReqContext, w http.ResponseWriter, r *http.R
 		},
 	}
 
-	SendJSON(response, w, ctx.Log)
+	SendResponse(response, w, r, ctx.Log)
 }
 
 // SuggestedFee is an httpHandler for route GET /v1/transactions/fee
@@ -564,6 +895,7 @@ func SuggestedFee(ctx lib.ReqContext, w http.ResponseWriter, r *http.Request) {
 	//       network protocol.
 	//     Produces:
 	//     - application/json
+	//     - application/msgpack
 	//     Schemes:
 	//     - http
 	//     Responses:
@@ -572,7 +904,7 @@ func SuggestedFee(ctx lib.ReqContext, w http.ResponseWriter, r *http.Request) {
 	//       401: { description: Invalid API Token }
 	//       default: { description: Unknown Error }
 	fee := TransactionFee{Fee: ctx.Node.SuggestedFee().Raw}
-	SendJSON(TransactionFeeResponse{&fee}, w, ctx.Log)
+	SendResponse(TransactionFeeResponse{&fee}, w, r, ctx.Log)
 }
 
 // SuggestedParams is an httpHandler for route GET /v1/transactions/params
@@ -582,6 +914,7 @@ func SuggestedParams(ctx lib.ReqContext, w http.ResponseWriter, r *http.Request)
 	//     Summary: Get parameters for constructing a new transaction
 	//     Produces:
 	//     - application/json
+	//     - application/msgpack
 	//     Schemes:
 	//     - http
 	//     Responses:
@@ -603,7 +936,13 @@ func SuggestedParams(ctx lib.ReqContext, w http.ResponseWriter, r *http.Request)
 	params.GenesisHash = gh[:]
 	params.LastRound = uint64(stat.LastRound)
 	params.ConsensusVersion = string(stat.LastVersion)
-	SendJSON(TransactionParamsResponse{&params}, w, ctx.Log)
+
+	admission := ctx.Node.PoolAdmission()
+	params.PoolFeePerByte = admission.FeePerByte
+	params.PoolPressureFeeCap = admission.PressureFeeCap
+	params.ReplacementFeeBump = admission.ReplacementFeeBump
+	params.MaxPendingPerSender = uint64(admission.MaxPendingPerSender)
+	SendResponse(TransactionParamsResponse{&params}, w, r, ctx.Log)
 }
 
 // GetBlock is an httpHandler for route GET /v1/block/{round}
@@ -613,6 +952,7 @@ func GetBlock(ctx lib.ReqContext, w http.ResponseWriter, r *http.Request) {
 	//     Summary: Get the block for the given round.
 	//     Produces:
 	//     - application/json
+	//     - application/msgpack
 	//     Schemes:
 	//     - http
 	//     Parameters:
@@ -629,6 +969,9 @@ func GetBlock(ctx lib.ReqContext, w http.ResponseWriter, r *http.Request) {
 	//       400:
 	//         description: Bad Request
 	//         schema: {type: string}
+	//       404:
+	//         description: The block has been pruned by this non-archival node
+	//         schema: {type: string}
 	//       500:
 	//         description: Internal Error
 	//         schema: {type: string}
@@ -642,6 +985,12 @@ func GetBlock(ctx lib.ReqContext, w http.ResponseWriter, r *http.Request) {
 
 	b, c, err := ctx.Node.GetBlock(basics.Round(queryRound))
 	if err != nil {
+		switch errt := err.(type) {
+		case ledger.ErrRoundPruned:
+			lib.ErrorResponse(w, http.StatusNotFound, err, fmt.Sprintf(errBlockPruned, errt.Earliest), ctx.Log)
+			return
+		}
+
 		lib.ErrorResponse(w, http.StatusInternalServerError, err, errFailedLookingUpLedger, ctx.Log)
 		return
 	}
@@ -652,77 +1001,487 @@ func GetBlock(ctx lib.ReqContext, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	SendJSON(BlockResponse{&block}, w, ctx.Log)
+	SendResponse(BlockResponse{&block}, w, r, ctx.Log)
 }
 
-// GetSupply is an httpHandler for route GET /v1/ledger/supply
-func GetSupply(ctx lib.ReqContext, w http.ResponseWriter, r *http.Request) {
-	// swagger:operation GET /v1/ledger/supply GetSupply
-	//---
-	//     Summary: Get the current supply reported by the ledger.
+// StreamBlocks is an httpHandler for route GET /v1/block-stream/{round}
+func StreamBlocks(ctx lib.ReqContext, w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /v1/block-stream/{round} StreamBlocks
+	// ---
+	//     Summary: Stream committed blocks, starting at the given round.
+	//     Description: >
+	//       Writes every block from the given round onwards as a sequence of newline-delimited
+	//       JSON objects (or of concatenated msgpack objects, if requested through the Accept
+	//       header), as soon as each block is committed to the ledger. The connection is
+	//       kept open until the client goes away. If the client cannot keep up, the server ends
+	//       the stream, and the client should reconnect starting at the round after the last
+	//       block it received.
 	//     Produces:
 	//     - application/json
+	//     - application/msgpack
 	//     Schemes:
 	//     - http
+	//     Parameters:
+	//       - name: round
+	//         in: path
+	//         type: integer
+	//         format: int64
+	//         minimum: 0
+	//         required: true
+	//         description: The first round to stream.
 	//     Responses:
 	//       200:
-	//         "$ref": '#/responses/SupplyResponse'
+	//         description: A stream of Block objects, one per line
+	//         schema: {"$ref": '#/definitions/Block'}
+	//       400:
+	//         description: Bad Request
+	//         schema: {type: string}
+	//       404:
+	//         description: The block has been pruned by this non-archival node
+	//         schema: {type: string}
+	//       500:
+	//         description: Internal Error
+	//         schema: {type: string}
 	//       401: { description: Invalid API Token }
 	//       default: { description: Unknown Error }
-	balances := ctx.Node.GetSupply()
-	supply := Supply{
-		Round:       uint64(balances.Round),
-		TotalMoney:  balances.TotalMoney.Raw,
-		OnlineMoney: balances.OnlineMoney.Raw,
+	queryRound, err := strconv.ParseUint(mux.Vars(r)["round"], 10, 64)
+	if err != nil {
+		lib.ErrorResponse(w, http.StatusBadRequest, err, errFailedParsingRoundNumber, ctx.Log)
+		return
 	}
-	SendJSON(SupplyResponse{&supply}, w, ctx.Log)
-}
+	next := basics.Round(queryRound)
 
-func parseTime(t string) (res time.Time, err error) {
-	// check for just date
-	res, err = time.Parse("2006-01-02", t)
-	if err == nil {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		lib.ErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("response writer %T does not support flushing", w), errStreamingNotSupported, ctx.Log)
 		return
 	}
 
-	// check for date and time
-	res, err = time.Parse(time.RFC3339, t)
-	if err == nil {
-		return
+	// Subscribe before looking at the ledger, so that no block committed
+	// while we catch up from the ledger is missed.
+	sub := ctx.Node.SubscribeBlocks()
+	defer sub.Close()
+
+	if next <= ctx.Node.LatestRound() {
+		_, _, err = ctx.Node.GetBlock(next)
+		if err != nil {
+			switch errt := err.(type) {
+			case ledger.ErrRoundPruned:
+				lib.ErrorResponse(w, http.StatusNotFound, err, fmt.Sprintf(errBlockPruned, errt.Earliest), ctx.Log)
+				return
+			}
+
+			lib.ErrorResponse(w, http.StatusInternalServerError, err, errFailedLookingUpLedger, ctx.Log)
+			return
+		}
 	}
 
-	return
+	useMsgpack := wantsMsgpack(r)
+	w.Header().Set("Content-Type", responseContentType(useMsgpack))
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	// sendUpTo writes all blocks from next up to and including rnd. Once the
+	// status has been sent, errors can only be reported by ending the stream.
+	sendUpTo := func(rnd basics.Round) error {
+		for ; next <= rnd; next++ {
+			b, c, err := ctx.Node.GetBlock(next)
+			if err != nil {
+				return err
+			}
+			block, err := blockEncode(b, c)
+			if err != nil {
+				return err
+			}
+			err = writeObject(block, w, useMsgpack)
+			if err != nil {
+				return err
+			}
+			flusher.Flush()
+		}
+		return nil
+	}
+
+	err = sendUpTo(ctx.Node.LatestRound())
+	for err == nil {
+		select {
+		case <-r.Context().Done():
+			return
+		case blk, ok := <-sub.C:
+			if !ok {
+				ctx.Log.Infof("StreamBlocks: closing stream for %s, client fell behind at round %d", r.RemoteAddr, next)
+				return
+			}
+			err = sendUpTo(blk.Round())
+		}
+	}
+	ctx.Log.Infof("StreamBlocks: closing stream for %s at round %d: %v", r.RemoteAddr, next, err)
 }
 
-// Transactions is an httpHandler for route GET /v1/account/{addr:[A-Z0-9]+}/transactions
-func Transactions(ctx lib.ReqContext, w http.ResponseWriter, r *http.Request) {
-	// swagger:operation GET /v1/account/{address}/transactions Transactions
+// StreamPendingTransactionEvents is an httpHandler for route GET /v1/transactions/pending-stream
+func StreamPendingTransactionEvents(ctx lib.ReqContext, w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /v1/transactions/pending-stream StreamPendingTransactionEvents
 	// ---
-	//     Summary: Get a list of confirmed transactions.
-	//     Description: Returns the list of confirmed transactions between within a date range. This call is available only when the indexer is running.
+	//     Summary: Stream changes to the transaction pool.
+	//     Description: >
+	//       Streams an event every time a transaction is admitted to the transaction pool, broadcast
+	//       again, evicted from it (along with the reason why), or committed to the ledger. The events
+	//       are written as a sequence of JSON objects (or of concatenated msgpack objects, if requested
+	//       through the Accept header), in the order in which they happen. The connection is kept open
+	//       until the client goes away. If the client cannot keep up, the server ends the stream.
 	//     Produces:
 	//     - application/json
+	//     - application/msgpack
 	//     Schemes:
 	//     - http
 	//     Parameters:
-	//       - name: address
-	//         in: path
-	//         type: string
-	//         pattern: "[A-Z0-9]{58}"
-	//         required: true
-	//         description: An account public key
-	//       - name: firstRound
+	//       - name: txid
 	//         in: query
-	//         type: integer
-	//         format: int64
-	//         minimum: 0
+	//         type: array
+	//         items: {type: string}
+	//         collectionFormat: multi
 	//         required: false
-	//         description: Do not fetch any transactions before this round.
-	//       - name: lastRound
-	//         in: query
-	//         type: integer
-	//         format: int64
-	//         minimum: 0
+	//         description: Only stream the events of these transactions. May be repeated.
+	//     Responses:
+	//       200:
+	//         description: A stream of PendingTransactionEvent objects, one per line
+	//         schema: {"$ref": '#/definitions/PendingTransactionEvent'}
+	//       400:
+	//         description: Bad Request
+	//         schema: {type: string}
+	//       500:
+	//         description: Internal Error
+	//         schema: {type: string}
+	//       401: { description: Invalid API Token }
+	//       default: { description: Unknown Error }
+	var filter map[transactions.Txid]bool
+	for _, queryTxID := range r.URL.Query()["txid"] {
+		var txID transactions.Txid
+		if txID.UnmarshalText([]byte(queryTxID)) != nil {
+			lib.ErrorResponse(w, http.StatusBadRequest, errors.New(errNoTxnSpecified), errNoTxnSpecified, ctx.Log)
+			return
+		}
+		if filter == nil {
+			filter = make(map[transactions.Txid]bool)
+		}
+		filter[txID] = true
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		lib.ErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("response writer %T does not support flushing", w), errStreamingNotSupported, ctx.Log)
+		return
+	}
+
+	sub := ctx.Node.SubscribeTxEvents()
+	defer sub.Close()
+
+	useMsgpack := wantsMsgpack(r)
+	w.Header().Set("Content-Type", responseContentType(useMsgpack))
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev, ok := <-sub.C:
+			if !ok {
+				ctx.Log.Infof("StreamPendingTransactionEvents: closing stream for %s, client fell behind", r.RemoteAddr)
+				return
+			}
+			if filter != nil && !filter[ev.Txid] {
+				continue
+			}
+			err := writeObject(txEventEncode(ev), w, useMsgpack)
+			if err != nil {
+				ctx.Log.Infof("StreamPendingTransactionEvents: closing stream for %s: %v", r.RemoteAddr, err)
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// GetSupply is an httpHandler for route GET /v1/ledger/supply
+func GetSupply(ctx lib.ReqContext, w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /v1/ledger/supply GetSupply
+	//---
+	//     Summary: Get the current supply reported by the ledger.
+	//     Produces:
+	//     - application/json
+	//     - application/msgpack
+	//     Schemes:
+	//     - http
+	//     Responses:
+	//       200:
+	//         "$ref": '#/responses/SupplyResponse'
+	//       401: { description: Invalid API Token }
+	//       default: { description: Unknown Error }
+	balances := ctx.Node.GetSupply()
+	supply := Supply{
+		Round:       uint64(balances.Round),
+		TotalMoney:  balances.TotalMoney.Raw,
+		OnlineMoney: balances.OnlineMoney.Raw,
+	}
+	SendResponse(SupplyResponse{&supply}, w, r, ctx.Log)
+}
+
+// GetPeerBans is an httpHandler for route GET /v1/peers/bans
+func GetPeerBans(ctx lib.ReqContext, w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /v1/peers/bans GetPeerBans
+	//---
+	//     Summary: Get the peers that the node has temporarily banned for misbehaving.
+	//     Produces:
+	//     - application/json
+	//     - application/msgpack
+	//     Schemes:
+	//     - http
+	//     Responses:
+	//       200:
+	//         "$ref": '#/responses/PeerBansResponse'
+	//       401: { description: Invalid API Token }
+	//       default: { description: Unknown Error }
+	bans := ctx.Node.BannedPeers()
+	list := PeerBanList{Bans: make([]PeerBan, 0, len(bans))}
+	for _, ban := range bans {
+		list.Bans = append(list.Bans, PeerBan{
+			Peer:    ban.Peer,
+			Reason:  ban.Reason,
+			Expires: ban.Expires.Unix(),
+			Bans:    uint64(ban.Bans),
+		})
+	}
+	SendResponse(PeerBansResponse{&list}, w, r, ctx.Log)
+}
+
+// GetUpgradeStatus is an httpHandler for route GET /v1/status/upgrade
+func GetUpgradeStatus(ctx lib.ReqContext, w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /v1/status/upgrade GetUpgradeStatus
+	//---
+	//     Summary: Get the progress of a consensus protocol upgrade.
+	//     Description: >
+	//       Reports the current and next consensus protocol, the approvals of the next protocol
+	//       against the upgrade threshold, the rounds at which the vote ends and the upgrade takes
+	//       effect, and which protocol versions the proposers of recent blocks support, weighted
+	//       by their online stake.
+	//     Produces:
+	//     - application/json
+	//     - application/msgpack
+	//     Schemes:
+	//     - http
+	//     Responses:
+	//       200:
+	//         "$ref": '#/responses/UpgradeStatusResponse'
+	//       500:
+	//         description: Internal Error
+	//         schema: {type: string}
+	//       401: { description: Invalid API Token }
+	//       default: { description: Unknown Error }
+	stat, err := ctx.Node.UpgradeStatus()
+	if err != nil {
+		lib.ErrorResponse(w, http.StatusInternalServerError, err, errFailedUpgradeStatus, ctx.Log)
+		return
+	}
+
+	status := UpgradeStatus{
+		LastRound:              uint64(stat.LastRound),
+		CurrentProtocol:        string(stat.CurrentProtocol),
+		NextProtocol:           string(stat.NextProtocol),
+		NextProtocolApprovals:  stat.NextProtocolApprovals,
+		NextProtocolVoteBefore: uint64(stat.NextProtocolVoteBefore),
+		NextProtocolSwitchOn:   uint64(stat.NextProtocolSwitchOn),
+		NextProtocolSupported:  stat.NextProtocolSupported,
+		UpgradeThreshold:       stat.UpgradeThreshold,
+		UpgradeVoteRounds:      stat.UpgradeVoteRounds,
+		FirstSampledRound:      uint64(stat.FirstSampledRound),
+		OnlineStake:            stat.OnlineStake.Raw,
+		Support:                make([]UpgradeSupport, 0, len(stat.Support)),
+	}
+	for _, support := range stat.Support {
+		status.Support = append(status.Support, UpgradeSupport{
+			Version:     string(support.Version),
+			Proposers:   uint64(support.Proposers),
+			Blocks:      uint64(support.Blocks),
+			OnlineStake: support.OnlineStake.Raw,
+			Supported:   support.Supported,
+		})
+	}
+	SendResponse(UpgradeStatusResponse{&status}, w, r, ctx.Log)
+}
+
+// Catchup is an httpHandler for route POST /v1/catchup/{catchpoint}
+func Catchup(ctx lib.ReqContext, w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /v1/catchup/{catchpoint} Catchup
+	// ---
+	//     Summary: Starts catching up to a catchpoint.
+	//     Description: Makes the node catch up to the given catchpoint, downloading the account state from its peers instead of replaying every block up to it. The call returns once catching up has started.
+	//     Produces:
+	//     - application/json
+	//     - application/msgpack
+	//     Schemes:
+	//     - http
+	//     Parameters:
+	//       - name: catchpoint
+	//         in: path
+	//         type: string
+	//         required: true
+	//         description: The catchpoint label, in the form round#digest
+	//     Responses:
+	//       200:
+	//         "$ref": '#/responses/StatusResponse'
+	//       400:
+	//         description: Bad Request
+	//         schema: {type: string}
+	//       500:
+	//         description: Internal Error
+	//         schema: {type: string}
+	//       401: { description: Invalid API Token }
+	//       default: { description: Unknown Error }
+	err := ctx.Node.StartCatchup(mux.Vars(r)["catchpoint"])
+	if err != nil {
+		lib.ErrorResponse(w, http.StatusBadRequest, err, errFailedStartingCatchup, ctx.Log)
+		return
+	}
+
+	nodeStatus, err := nodeStatus(ctx.Node)
+	if err != nil {
+		lib.ErrorResponse(w, http.StatusInternalServerError, err, errFailedRetrievingNodeStatus, ctx.Log)
+		return
+	}
+
+	SendResponse(StatusResponse{&nodeStatus}, w, r, ctx.Log)
+}
+
+// DevModeAdvanceRound is an httpHandler for route POST /v1/devmode/round
+func DevModeAdvanceRound(ctx lib.ReqContext, w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /v1/devmode/round DevModeAdvanceRound
+	// ---
+	//     Summary: Seals a block on a dev mode node.
+	//     Description: Makes a node of a dev mode network seal a block with its pending transactions, if any, and add it to its ledger.
+	//     Produces:
+	//     - application/json
+	//     - application/msgpack
+	//     Schemes:
+	//     - http
+	//     Responses:
+	//       200:
+	//         "$ref": '#/responses/DevModeStatusResponse'
+	//       400:
+	//         description: Bad Request
+	//         schema: {type: string}
+	//       500:
+	//         description: Internal Error
+	//         schema: {type: string}
+	//       401: { description: Invalid API Token }
+	//       default: { description: Unknown Error }
+	round, err := ctx.Node.DevModeAdvanceRound()
+	if err == node.ErrNotDevMode {
+		lib.ErrorResponse(w, http.StatusBadRequest, err, err.Error(), ctx.Log)
+		return
+	}
+	if err != nil {
+		lib.ErrorResponse(w, http.StatusInternalServerError, err, errFailedDevModeRound, ctx.Log)
+		return
+	}
+
+	offset, err := ctx.Node.DevModeAdvanceClock(0)
+	if err != nil {
+		lib.ErrorResponse(w, http.StatusInternalServerError, err, errFailedDevModeRound, ctx.Log)
+		return
+	}
+	status := DevModeStatus{Round: uint64(round), ClockOffset: int64(offset / time.Second)}
+	SendResponse(DevModeStatusResponse{&status}, w, r, ctx.Log)
+}
+
+// DevModeAdvanceClock is an httpHandler for route POST /v1/devmode/clock/{seconds:[0-9]+}
+func DevModeAdvanceClock(ctx lib.ReqContext, w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /v1/devmode/clock/{seconds} DevModeAdvanceClock
+	// ---
+	//     Summary: Moves the clock of a dev mode node forward.
+	//     Description: Moves the clock that a node of a dev mode network uses for block timestamps forward. Consensus rules limit how much timestamps may grow from one block to the next, so a large jump shows up over several blocks.
+	//     Produces:
+	//     - application/json
+	//     - application/msgpack
+	//     Schemes:
+	//     - http
+	//     Parameters:
+	//       - name: seconds
+	//         in: path
+	//         type: integer
+	//         format: int64
+	//         minimum: 0
+	//         required: true
+	//         description: The number of seconds to move the clock forward by
+	//     Responses:
+	//       200:
+	//         "$ref": '#/responses/DevModeStatusResponse'
+	//       400:
+	//         description: Bad Request
+	//         schema: {type: string}
+	//       401: { description: Invalid API Token }
+	//       default: { description: Unknown Error }
+	seconds, err := strconv.ParseInt(mux.Vars(r)["seconds"], 10, 32)
+	if err != nil {
+		lib.ErrorResponse(w, http.StatusBadRequest, err, errFailedDevModeClock, ctx.Log)
+		return
+	}
+
+	offset, err := ctx.Node.DevModeAdvanceClock(time.Duration(seconds) * time.Second)
+	if err != nil {
+		lib.ErrorResponse(w, http.StatusBadRequest, err, err.Error(), ctx.Log)
+		return
+	}
+	status := DevModeStatus{Round: uint64(ctx.Node.LatestRound()), ClockOffset: int64(offset / time.Second)}
+	SendResponse(DevModeStatusResponse{&status}, w, r, ctx.Log)
+}
+
+func parseTime(t string) (res time.Time, err error) {
+	// check for just date
+	res, err = time.Parse("2006-01-02", t)
+	if err == nil {
+		return
+	}
+
+	// check for date and time
+	res, err = time.Parse(time.RFC3339, t)
+	if err == nil {
+		return
+	}
+
+	return
+}
+
+// Transactions is an httpHandler for route GET /v1/account/{addr:[A-Z0-9]+}/transactions
+func Transactions(ctx lib.ReqContext, w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /v1/account/{address}/transactions Transactions
+	// ---
+	//     Summary: Get a list of confirmed transactions.
+	//     Description: Returns the list of confirmed transactions between within a date range. This call is available only when the indexer is running.
+	//     Produces:
+	//     - application/json
+	//     - application/msgpack
+	//     Schemes:
+	//     - http
+	//     Parameters:
+	//       - name: address
+	//         in: path
+	//         type: string
+	//         pattern: "[A-Z0-9]{58}"
+	//         required: true
+	//         description: An account public key
+	//       - name: firstRound
+	//         in: query
+	//         type: integer
+	//         format: int64
+	//         minimum: 0
+	//         required: false
+	//         description: Do not fetch any transactions before this round.
+	//       - name: lastRound
+	//         in: query
+	//         type: integer
+	//         format: int64
+	//         minimum: 0
 	//         required: false
 	//         description: Do not fetch any transactions after this round.
 	//       - name: fromDate
@@ -870,7 +1629,7 @@ func Transactions(ctx lib.ReqContext, w http.ResponseWriter, r *http.Request) {
 		},
 	}
 
-	SendJSON(response, w, ctx.Log)
+	SendResponse(response, w, r, ctx.Log)
 }
 
 // GetTransactionByID is an httpHandler for route GET /v1/transaction/{txid}
@@ -881,6 +1640,7 @@ func GetTransactionByID(ctx lib.ReqContext, w http.ResponseWriter, r *http.Reque
 	//     Description: Returns the transaction information of the given txid. Works only if the indexer is enabled.
 	//     Produces:
 	//     - application/json
+	//     - application/msgpack
 	//     Schemes:
 	//     - http
 	//     Parameters:
@@ -934,7 +1694,7 @@ func GetTransactionByID(ctx lib.ReqContext, w http.ResponseWriter, r *http.Reque
 			Body: &responseTxs,
 		}
 
-		SendJSON(response, w, ctx.Log)
+		SendResponse(response, w, r, ctx.Log)
 		return
 	}
 
@@ -942,3 +1702,209 @@ func GetTransactionByID(ctx lib.ReqContext, w http.ResponseWriter, r *http.Reque
 	lib.ErrorResponse(w, http.StatusNotFound, errors.New(errTransactionNotFound), errTransactionNotFound, ctx.Log)
 	return
 }
+
+// parseSearchFilter reads the parameters of a transaction search from the request.
+func parseSearchFilter(r *http.Request) (filter indexer.TransactionFilter, err error) {
+	parseAddr := func(name string) (string, error) {
+		s := r.FormValue(name)
+		if s == "" {
+			return "", nil
+		}
+		addr, err := basics.UnmarshalChecksumAddress(s)
+		if err != nil {
+			return "", fmt.Errorf("%s: %v", name, err)
+		}
+		return addr.GetChecksumAddress().String(), nil
+	}
+	parseUint := func(name string) (uint64, error) {
+		s := r.FormValue(name)
+		if s == "" {
+			return 0, nil
+		}
+		v, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %v", name, err)
+		}
+		return v, nil
+	}
+
+	addrParam := r.FormValue("address")
+	if pathAddr, ok := mux.Vars(r)["addr"]; ok {
+		addrParam = pathAddr
+	}
+	if addrParam != "" {
+		addr, err := basics.UnmarshalChecksumAddress(addrParam)
+		if err != nil {
+			return filter, fmt.Errorf("address: %v", err)
+		}
+		filter.Address = addr.GetChecksumAddress().String()
+	}
+
+	if filter.CloseTo, err = parseAddr("closeTo"); err != nil {
+		return
+	}
+	if filter.MinAmount, err = parseUint("minAmount"); err != nil {
+		return
+	}
+	if filter.MaxAmount, err = parseUint("maxAmount"); err != nil {
+		return
+	}
+	if filter.FirstRound, err = parseUint("firstRound"); err != nil {
+		return
+	}
+	if filter.LastRound, err = parseUint("lastRound"); err != nil {
+		return
+	}
+	if filter.Limit, err = parseUint("max"); err != nil {
+		return
+	}
+
+	if notePrefix := r.FormValue("notePrefix"); notePrefix != "" {
+		filter.NotePrefix, err = base64.StdEncoding.DecodeString(notePrefix)
+		if err != nil {
+			return filter, fmt.Errorf("notePrefix: %v", err)
+		}
+	}
+
+	filter.Type = r.FormValue("type")
+	filter.Cursor = r.FormValue("cursor")
+	return filter, nil
+}
+
+// SearchTransactions is an httpHandler for routes GET /v1/transactions/search and GET /v1/account/{addr:[A-Z0-9]{KeyLength}}/transactions/search
+func SearchTransactions(ctx lib.ReqContext, w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /v1/transactions/search SearchTransactions
+	// ---
+	//     Summary: Search the confirmed transactions.
+	//     Description: >
+	//       Returns a page of the confirmed transactions that match all the given filters, ordered by round and by
+	//       position in the block. Pass the returned nextCursor as the cursor to fetch the next page. The same search,
+	//       restricted to an account, is available at /v1/account/{address}/transactions/search.
+	//       This call is available only when the indexer is running.
+	//     Produces:
+	//     - application/json
+	//     - application/msgpack
+	//     Schemes:
+	//     - http
+	//     Parameters:
+	//       - name: address
+	//         in: query
+	//         type: string
+	//         pattern: "[A-Z0-9]{58}"
+	//         required: false
+	//         description: Only fetch transactions in which this account takes part.
+	//       - name: type
+	//         in: query
+	//         type: string
+	//         required: false
+	//         description: Only fetch transactions of this type, e.g. pay, keyreg, acfg, axfer or afrz.
+	//       - name: minAmount
+	//         in: query
+	//         type: integer
+	//         format: int64
+	//         minimum: 0
+	//         required: false
+	//         description: Only fetch transactions that transfer at least this amount, in MicroAlgos or asset units.
+	//       - name: maxAmount
+	//         in: query
+	//         type: integer
+	//         format: int64
+	//         minimum: 0
+	//         required: false
+	//         description: Only fetch transactions that transfer at most this amount, in MicroAlgos or asset units.
+	//       - name: firstRound
+	//         in: query
+	//         type: integer
+	//         format: int64
+	//         minimum: 0
+	//         required: false
+	//         description: Do not fetch any transactions before this round.
+	//       - name: lastRound
+	//         in: query
+	//         type: integer
+	//         format: int64
+	//         minimum: 0
+	//         required: false
+	//         description: Do not fetch any transactions after this round.
+	//       - name: closeTo
+	//         in: query
+	//         type: string
+	//         pattern: "[A-Z0-9]{58}"
+	//         required: false
+	//         description: Only fetch transactions that close out to this account.
+	//       - name: notePrefix
+	//         in: query
+	//         type: string
+	//         format: byte
+	//         required: false
+	//         description: Only fetch transactions whose note starts with these (base64 encoded) bytes.
+	//       - name: cursor
+	//         in: query
+	//         type: string
+	//         required: false
+	//         description: The nextCursor returned by the previous page of the same search.
+	//       - name: max
+	//         in: query
+	//         type: integer
+	//         format: int64
+	//         required: false
+	//         description: maximum transactions to show (default to 100, at most 1000)
+	//     Responses:
+	//       200:
+	//         "$ref": '#/responses/TransactionSearchResponse'
+	//       400:
+	//         description: Bad Request
+	//         schema: {type: string}
+	//       500:
+	//         description: Internal Error
+	//         schema: {type: string}
+	//       401: { description: Invalid API Token }
+	//       default: { description: Unknown Error }
+
+	idx, err := ctx.Node.Indexer()
+	if err != nil {
+		lib.ErrorResponse(w, http.StatusInternalServerError, err, errIndexerNotRunning, ctx.Log)
+		return
+	}
+
+	filter, err := parseSearchFilter(r)
+	if err != nil {
+		lib.ErrorResponse(w, http.StatusBadRequest, err, errFailedParsingSearchParams, ctx.Log)
+		return
+	}
+
+	found, cursor, err := idx.GetTransactions(filter)
+	if err == indexer.ErrMalformedCursor {
+		lib.ErrorResponse(w, http.StatusBadRequest, err, errMalformedCursor, ctx.Log)
+		return
+	}
+	if err != nil {
+		lib.ErrorResponse(w, http.StatusInternalServerError, err, errFailedGettingInformationFromIndexer, ctx.Log)
+		return
+	}
+
+	responseTxs := make([]Transaction, 0, len(found))
+	for _, itx := range found {
+		var txID transactions.Txid
+		if err := txID.UnmarshalText([]byte(itx.TXID)); err != nil {
+			lib.ErrorResponse(w, http.StatusInternalServerError, err, errFailedGettingInformationFromIndexer, ctx.Log)
+			return
+		}
+
+		txn, err := ctx.Node.GetTransactionByID(txID, basics.Round(itx.Round))
+		if err != nil {
+			lib.ErrorResponse(w, http.StatusInternalServerError, err, errFailedLookingUpLedger, ctx.Log)
+			return
+		}
+		responseTxs = append(responseTxs, txWithStatusEncode(txn))
+	}
+
+	response := TransactionSearchResponse{
+		Body: &TransactionSearchResults{
+			Transactions: responseTxs,
+			NextCursor:   cursor,
+		},
+	}
+
+	SendResponse(response, w, r, ctx.Log)
+}
@@ -15,3 +15,138 @@
 // along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
 
 package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/logging"
+	"github.com/algorand/go-algorand/protocol"
+)
+
+func testBlock() Block {
+	return Block{
+		Hash:     "hash",
+		Proposer: "proposer",
+		Round:    7,
+		Transactions: TransactionList{Transactions: []Transaction{{
+			Type:    protocol.PaymentTx,
+			TxID:    "txid",
+			From:    "sender",
+			Fee:     1000,
+			Note:    []byte{1, 2, 3},
+			Payment: &PaymentTransactionType{To: "receiver", Amount: 5},
+		}}},
+		UpgradeState: UpgradeState{CurrentProtocol: "proto"},
+	}
+}
+
+func TestSendResponseEncoding(t *testing.T) {
+	block := testBlock()
+
+	// JSON is the default
+	r := httptest.NewRequest("GET", "/v1/block/7", nil)
+	w := httptest.NewRecorder()
+	SendResponse(BlockResponse{&block}, w, r, logging.TestingLog(t))
+	require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var fromJSON Block
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &fromJSON))
+	require.Equal(t, block, fromJSON)
+
+	for _, accept := range []string{"application/msgpack", "application/msgpack, application/json;q=0.9", "text/plain, application/msgpack"} {
+		r = httptest.NewRequest("GET", "/v1/block/7", nil)
+		r.Header.Set("Accept", accept)
+		w = httptest.NewRecorder()
+		SendResponse(BlockResponse{&block}, w, r, logging.TestingLog(t))
+		require.Equal(t, "application/msgpack", w.Header().Get("Content-Type"))
+		require.Equal(t, protocol.Encode(block), w.Body.Bytes())
+
+		var fromMsgpack Block
+		require.NoError(t, protocol.Decode(w.Body.Bytes(), &fromMsgpack))
+		require.Equal(t, block, fromMsgpack)
+	}
+}
+
+func TestMsgpackKeys(t *testing.T) {
+	// msgpack responses use the same field names as JSON responses.
+	var blockFields map[string]interface{}
+	require.NoError(t, protocol.Decode(protocol.Encode(testBlock()), &blockFields))
+	for _, key := range []string{"hash", "proposer", "round", "txns", "currentProtocol"} {
+		require.Contains(t, blockFields, key)
+	}
+	require.NotContains(t, blockFields, "Round")
+	require.NotContains(t, blockFields, "Transactions")
+
+	var txnFields map[string]interface{}
+	require.NoError(t, protocol.Decode(protocol.Encode(testBlock().Transactions.Transactions[0]), &txnFields))
+	for _, key := range []string{"type", "tx", "from", "fee", "noteb64", "payment"} {
+		require.Contains(t, txnFields, key)
+	}
+	require.NotContains(t, txnFields, "TxID")
+}
+
+func TestWantsMsgpack(t *testing.T) {
+	r := httptest.NewRequest("GET", "/v1/status", nil)
+	require.False(t, wantsMsgpack(r))
+
+	r.Header.Set("Accept", "application/json")
+	require.False(t, wantsMsgpack(r))
+
+	r.Header = http.Header{}
+	r.Header.Add("Accept", "application/json")
+	r.Header.Add("Accept", "application/msgpack")
+	require.True(t, wantsMsgpack(r))
+
+	for accept, expected := range map[string]bool{
+		"application/json, application/msgpack;q=0":         false,
+		"application/msgpack;q=0":                           false,
+		"application/msgpack;q=0.5, application/json":       false,
+		"application/json;q=0.5, application/msgpack;q=0.8": true,
+		"application/msgpack;q=0.5, */*;q=0.1":              true,
+		"application/msgpack;q=0.5, application/*":          false,
+		"*/*":                         false,
+		"application/msgpack;q=bogus": false,
+	} {
+		r.Header = http.Header{}
+		r.Header.Set("Accept", accept)
+		require.Equal(t, expected, wantsMsgpack(r), accept)
+	}
+}
+
+func TestParseSearchFilter(t *testing.T) {
+	addr := basics.Address{1, 2, 3}.GetUserAddress()
+
+	r := httptest.NewRequest("GET", "/v1/transactions/search?address="+addr+"&type=pay&minAmount=10&maxAmount=20&firstRound=3&lastRound=4&closeTo="+addr+"&notePrefix=aGk%3D&cursor=5:6&max=7", nil)
+	filter, err := parseSearchFilter(r)
+	require.NoError(t, err)
+	require.Equal(t, addr, filter.Address)
+	require.Equal(t, "pay", filter.Type)
+	require.Equal(t, uint64(10), filter.MinAmount)
+	require.Equal(t, uint64(20), filter.MaxAmount)
+	require.Equal(t, uint64(3), filter.FirstRound)
+	require.Equal(t, uint64(4), filter.LastRound)
+	require.Equal(t, addr, filter.CloseTo)
+	require.Equal(t, []byte("hi"), filter.NotePrefix)
+	require.Equal(t, "5:6", filter.Cursor)
+	require.Equal(t, uint64(7), filter.Limit)
+
+	// the account in the path takes precedence over the query
+	other := basics.Address{4, 5, 6}.GetUserAddress()
+	r = mux.SetURLVars(r, map[string]string{"addr": other})
+	filter, err = parseSearchFilter(r)
+	require.NoError(t, err)
+	require.Equal(t, other, filter.Address)
+
+	for _, query := range []string{"address=bad", "closeTo=bad", "minAmount=-1", "lastRound=x", "notePrefix=!!"} {
+		r = httptest.NewRequest("GET", "/v1/transactions/search?"+query, nil)
+		_, err = parseSearchFilter(r)
+		require.Error(t, err, query)
+	}
+}
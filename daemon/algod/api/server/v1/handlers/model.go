@@ -20,6 +20,9 @@
 // This is the ground truth for the API spec. Whenever you update this file,
 // make sure to update any clients if you make breaking changes. This includes
 // copying model changes into api/client/models/.
+//
+// Every field carries a codec tag matching its json tag, so that msgpack
+// responses use the same field names as JSON responses.
 package handlers
 
 import (
@@ -33,37 +36,37 @@ type NodeStatus struct {
 	// LastRound indicates the last round seen
 	//
 	// required: true
-	LastRound uint64 `json:"lastRound"`
+	LastRound uint64 `json:"lastRound" codec:"lastRound"`
 
 	// LastVersion indicates the last consensus version supported
 	//
 	// required: true
-	LastVersion string `json:"lastConsensusVersion"`
+	LastVersion string `json:"lastConsensusVersion" codec:"lastConsensusVersion"`
 
 	// NextVersion of consensus protocol to use
 	//
 	// required: true
-	NextVersion string `json:"nextConsensusVersion"`
+	NextVersion string `json:"nextConsensusVersion" codec:"nextConsensusVersion"`
 
 	// NextVersionRound is the round at which the next consensus version will apply
 	//
 	// required: true
-	NextVersionRound uint64 `json:"nextConsensusVersionRound"`
+	NextVersionRound uint64 `json:"nextConsensusVersionRound" codec:"nextConsensusVersionRound"`
 
 	// NextVersionSupported indicates whether the next consensus version is supported by this node
 	//
 	// required: true
-	NextVersionSupported bool `json:"nextConsensusVersionSupported"`
+	NextVersionSupported bool `json:"nextConsensusVersionSupported" codec:"nextConsensusVersionSupported"`
 
 	// TimeSinceLastRound in nanoseconds
 	//
 	// required: true
-	TimeSinceLastRound int64 `json:"timeSinceLastRound"`
+	TimeSinceLastRound int64 `json:"timeSinceLastRound" codec:"timeSinceLastRound"`
 
 	// CatchupTime in nanoseconds
 	//
 	// required: true
-	CatchupTime int64 `json:"catchupTime"`
+	CatchupTime int64 `json:"catchupTime" codec:"catchupTime"`
 }
 
 // TransactionID Description
@@ -72,7 +75,7 @@ type TransactionID struct {
 	// TxId is the string encoding of the transaction hash
 	//
 	// required: true
-	TxID string `json:"txId"`
+	TxID string `json:"txId" codec:"txId"`
 }
 
 // Account Description
@@ -81,34 +84,34 @@ type Account struct {
 	// Round indicates the round for which this information is relevant
 	//
 	// required: true
-	Round uint64 `json:"round"`
+	Round uint64 `json:"round" codec:"round"`
 
 	// Address indicates the account public key
 	//
 	// required: true
-	Address string `json:"address"`
+	Address string `json:"address" codec:"address"`
 
 	// Amount indicates the total number of MicroAlgos in the account
 	//
 	// required: true
-	Amount uint64 `json:"amount"`
+	Amount uint64 `json:"amount" codec:"amount"`
 
 	// PendingRewards specifies the amount of MicroAlgos of pending
 	// rewards in this account.
 	//
 	// required: true
-	PendingRewards uint64 `json:"pendingrewards"`
+	PendingRewards uint64 `json:"pendingrewards" codec:"pendingrewards"`
 
 	// AmountWithoutPendingRewards specifies the amount of MicroAlgos in
 	// the account, without the pending rewards.
 	//
 	// required: true
-	AmountWithoutPendingRewards uint64 `json:"amountwithoutpendingrewards"`
+	AmountWithoutPendingRewards uint64 `json:"amountwithoutpendingrewards" codec:"amountwithoutpendingrewards"`
 
 	// Rewards indicates the total rewards of MicroAlgos the account has recieved
 	//
 	// required: true
-	Rewards uint64 `json:"rewards"`
+	Rewards uint64 `json:"rewards" codec:"rewards"`
 
 	// Status indicates the delegation status of the account's MicroAlgos
 	// Offline - indicates that the associated account is delegated.
@@ -116,7 +119,113 @@ type Account struct {
 	// NotParticipating - indicates that the associated account is neither a delegator nor a delegate.
 	//
 	// required: true
-	Status string `json:"status"`
+	Status string `json:"status" codec:"status"`
+
+	// AssetParams specifies the parameters of assets created by this account.
+	//
+	// required: false
+	AssetParams map[uint64]AssetParams `json:"thisassettotal,omitempty" codec:"thisassettotal,omitempty"`
+
+	// Assets specifies the holdings of assets by this account,
+	// indexed by the asset ID.
+	//
+	// required: false
+	Assets map[uint64]AssetHolding `json:"assets,omitempty" codec:"assets,omitempty"`
+
+	// AuthAddr indicates the address that is authorized to sign
+	// transactions for this account, if it has been rekeyed.
+	//
+	// required: false
+	AuthAddr string `json:"authaddr,omitempty" codec:"authaddr,omitempty"`
+}
+
+// AssetParams specifies the parameters for an asset.
+// swagger:model AssetParams
+type AssetParams struct {
+	// Creator specifies the address that created this asset.
+	// This is the address where the parameters for this asset
+	// can be found, and also the address where unwanted asset
+	// units can be sent in the worst case.
+	//
+	// required: true
+	Creator string `json:"creator" codec:"creator"`
+
+	// Total specifies the total number of units of this asset.
+	//
+	// required: true
+	Total uint64 `json:"total" codec:"total"`
+
+	// DefaultFrozen specifies whether slots for this asset
+	// in user accounts are frozen by default.
+	//
+	// required: false
+	DefaultFrozen bool `json:"defaultfrozen" codec:"defaultfrozen"`
+
+	// UnitName specifies a hint for the name of a unit of
+	// this asset.
+	//
+	// required: false
+	UnitName string `json:"unitname,omitempty" codec:"unitname,omitempty"`
+
+	// AssetName specifies a hint for the name of the asset.
+	//
+	// required: false
+	AssetName string `json:"assetname,omitempty" codec:"assetname,omitempty"`
+
+	// URL specifies a URL where more information about the asset can be
+	// retrieved
+	//
+	// required: false
+	URL string `json:"url,omitempty" codec:"url,omitempty"`
+
+	// MetadataHash specifies a commitment to some unspecified asset
+	// metadata. The format of this metadata is up to the application.
+	//
+	// required: false
+	MetadataHash []byte `json:"metadatahash,omitempty" codec:"metadatahash,omitempty"`
+
+	// ManagerAddr specifies the address used to manage the keys of this
+	// asset and to destroy it.
+	//
+	// required: false
+	ManagerAddr string `json:"managerkey,omitempty" codec:"managerkey,omitempty"`
+
+	// ReserveAddr specifies the address holding reserve (non-minted)
+	// units of this asset.
+	//
+	// required: false
+	ReserveAddr string `json:"reserveaddr,omitempty" codec:"reserveaddr,omitempty"`
+
+	// FreezeAddr specifies the address used to freeze holdings of
+	// this asset.  If empty, freezing is not permitted.
+	//
+	// required: false
+	FreezeAddr string `json:"freezeaddr,omitempty" codec:"freezeaddr,omitempty"`
+
+	// ClawbackAddr specifies the address used to clawback holdings of
+	// this asset.  If empty, clawback is not permitted.
+	//
+	// required: false
+	ClawbackAddr string `json:"clawbackaddr,omitempty" codec:"clawbackaddr,omitempty"`
+}
+
+// AssetHolding describes an asset held by an account.
+// swagger:model AssetHolding
+type AssetHolding struct {
+	// Creator specifies the address that created this asset.
+	//
+	// required: true
+	Creator string `json:"creator" codec:"creator"`
+
+	// Amount specifies the number of units held.
+	//
+	// required: true
+	Amount uint64 `json:"amount" codec:"amount"`
+
+	// Frozen specifies whether this holding is frozen.
+	//
+	// required: false
+	Frozen bool `json:"frozen" codec:"frozen"`
 }
 
 // Transaction contains all fields common to all transactions and serves as an envelope to all transactions
@@ -126,42 +235,42 @@ type Transaction struct {
 	// Type is the transaction type
 	//
 	// required: true
-	Type protocol.TxType `json:"type"`
+	Type protocol.TxType `json:"type" codec:"type"`
 
 	// TxID is the transaction ID
 	//
 	// required: true
-	TxID string `json:"tx"`
+	TxID string `json:"tx" codec:"tx"`
 
 	// From is the sender's address
 	//
 	// required: true
-	From string `json:"from"`
+	From string `json:"from" codec:"from"`
 
 	// Fee is the transaction fee
 	//
 	// required: true
-	Fee uint64 `json:"fee"`
+	Fee uint64 `json:"fee" codec:"fee"`
 
 	// FirstRound indicates the first valid round for this transaction
 	//
 	// required: true
-	FirstRound uint64 `json:"first-round"`
+	FirstRound uint64 `json:"first-round" codec:"first-round"`
 
 	// LastRound indicates the last valid round for this transaction
 	//
 	// required: true
-	LastRound uint64 `json:"last-round"`
+	LastRound uint64 `json:"last-round" codec:"last-round"`
 
 	// Note is a free form data
 	//
 	// required: false
-	Note lib.Bytes `json:"noteb64,omitempty"`
+	Note lib.Bytes `json:"noteb64,omitempty" codec:"noteb64,omitempty"`
 
 	// ConfirmedRound indicates the block number this transaction appeared in
 	//
 	// required: false
-	ConfirmedRound uint64 `json:"round,omitempty"`
+	ConfirmedRound uint64 `json:"round,omitempty" codec:"round,omitempty"`
 
 	// PoolError indicates the transaction was evicted from this node's transaction
 	// pool (if non-empty).  A non-empty PoolError does not guarantee that the
@@ -169,28 +278,42 @@ type Transaction struct {
 	// transaction and may attempt to commit it in the future.
 	//
 	// required: false
-	PoolError string `json:"poolerror,omitempty"`
+	PoolError string `json:"poolerror,omitempty" codec:"poolerror,omitempty"`
 
 	// This is a list of all supported transactions.
 	// To add another one, create a struct with XXXTransactionType and embed it here.
 	// To prevent extraneous fields, all must have the "omitempty" tag.
-	Payment *PaymentTransactionType `json:"payment,omitempty"`
+	Payment *PaymentTransactionType `json:"payment,omitempty" codec:"payment,omitempty"`
+
+	// AssetConfig contains the additional fields for an asset config transaction
+	AssetConfig *AssetConfigTransactionType `json:"curcfg,omitempty" codec:"curcfg,omitempty"`
+
+	// AssetTransfer contains the additional fields for an asset transfer transaction
+	AssetTransfer *AssetTransferTransactionType `json:"curxfer,omitempty" codec:"curxfer,omitempty"`
+
+	// AssetFreeze contains the additional fields for an asset freeze transaction
+	AssetFreeze *AssetFreezeTransactionType `json:"curfrz,omitempty" codec:"curfrz,omitempty"`
 
 	// FromRewards is the amount of pending rewards applied to the From
 	// account as part of this transaction.
 	//
 	// required: false
-	FromRewards uint64 `json:"fromrewards"`
+	FromRewards uint64 `json:"fromrewards" codec:"fromrewards"`
 
 	// Genesis ID
 	//
 	// required: true
-	GenesisID string `json:"genesisID"`
+	GenesisID string `json:"genesisID" codec:"genesisID"`
 
 	// Genesis hash
 	//
 	// required: true
-	GenesisHash lib.Bytes `json:"genesishashb64"`
+	GenesisHash lib.Bytes `json:"genesishashb64" codec:"genesishashb64"`
+
+	// Group is the transaction group this transaction belongs to, if any
+	//
+	// required: false
+	Group lib.Bytes `json:"group,omitempty" codec:"group,omitempty"`
 }
 
 // PaymentTransactionType contains the additional fields for a payment Transaction
@@ -199,34 +322,96 @@ type PaymentTransactionType struct {
 	// To is the receiver's address
 	//
 	// required: true
-	To string `json:"to"`
+	To string `json:"to" codec:"to"`
 
 	// CloseRemainderTo is the address the sender closed to
 	//
 	// required: false
-	CloseRemainderTo string `json:"close,omitempty"`
+	CloseRemainderTo string `json:"close,omitempty" codec:"close,omitempty"`
 
 	// CloseAmount is the amount sent to CloseRemainderTo, for committed transaction
 	//
 	// required: false
-	CloseAmount uint64 `json:"closeamount,omitempty"`
+	CloseAmount uint64 `json:"closeamount,omitempty" codec:"closeamount,omitempty"`
 
 	// Amount is the amount of MicroAlgos intended to be transferred
 	//
 	// required: true
-	Amount uint64 `json:"amount"`
+	Amount uint64 `json:"amount" codec:"amount"`
 
 	// ToRewards is the amount of pending rewards applied to the To account
 	// as part of this transaction.
 	//
 	// required: false
-	ToRewards uint64 `json:"torewards"`
+	ToRewards uint64 `json:"torewards" codec:"torewards"`
 
 	// CloseRewards is the amount of pending rewards applied to the CloseRemainderTo
 	// account as part of this transaction.
 	//
 	// required: false
-	CloseRewards uint64 `json:"closerewards"`
+	CloseRewards uint64 `json:"closerewards" codec:"closerewards"`
+}
+
+// AssetConfigTransactionType contains the additional fields for an asset config transaction
+// swagger:model AssetConfigTransactionType
+type AssetConfigTransactionType struct {
+	// AssetID is the asset being configured (or empty if creating)
+	//
+	// required: false
+	AssetID uint64 `json:"id" codec:"id"`
+
+	// Params specifies the new asset parameters (or empty if deleting)
+	//
+	// required: false
+	Params AssetParams `json:"params" codec:"params"`
+}
+
+// AssetTransferTransactionType contains the additional fields for an asset transfer transaction
+// swagger:model AssetTransferTransactionType
+type AssetTransferTransactionType struct {
+	// AssetID is the asset being transferred
+	//
+	// required: true
+	AssetID uint64 `json:"id" codec:"id"`
+
+	// Amount is the amount being transferred.
+	//
+	// required: true
+	Amount uint64 `json:"amt" codec:"amt"`
+
+	// Sender is the source account (if using clawback).
+	//
+	// required: false
+	Sender string `json:"snd" codec:"snd"`
+
+	// Receiver is the recipient account.
+	//
+	// required: true
+	Receiver string `json:"rcv" codec:"rcv"`
+
+	// CloseTo is the destination for remaining funds (if closing).
+	//
+	// required: false
+	CloseTo string `json:"closeto" codec:"closeto"`
+}
+
+// AssetFreezeTransactionType contains the additional fields for an asset freeze transaction
+// swagger:model AssetFreezeTransactionType
+type AssetFreezeTransactionType struct {
+	// AssetID is the asset being frozen or unfrozen.
+	//
+	// required: true
+	AssetID uint64 `json:"id" codec:"id"`
+
+	// Account specifies the account where the asset is being frozen or thawed.
+	//
+	// required: true
+	Account string `json:"acct" codec:"acct"`
+
+	// NewFreezeStatus specifies the new freeze status.
+	//
+	// required: true
+	NewFreezeStatus bool `json:"freeze" codec:"freeze"`
 }
 
 // TransactionList contains a list of transactions
@@ -235,7 +420,20 @@ type TransactionList struct {
 	// TransactionList is a list of transactions
 	//
 	// required: true
-	Transactions []Transaction `json:"transactions,omitempty"`
+	Transactions []Transaction `json:"transactions,omitempty" codec:"transactions,omitempty"`
+}
+
+// TransactionSearchResults contains a page of the transactions that match a search
+// swagger:model TransactionSearchResults
+type TransactionSearchResults struct {
+	// Transactions is the list of matching transactions
+	//
+	// required: true
+	Transactions []Transaction `json:"transactions,omitempty" codec:"transactions,omitempty"`
+
+	// NextCursor fetches the next page of matching transactions when passed
+	// as the cursor of the same search. It is empty on the last page.
+	NextCursor string `json:"nextCursor,omitempty" codec:"nextCursor,omitempty"`
 }
 
 // TransactionFee contains the suggested fee
@@ -247,7 +445,7 @@ type TransactionFee struct {
 	// at least MinTxnFee for the current network protocol.
 	//
 	// required: true
-	Fee uint64 `json:"fee"`
+	Fee uint64 `json:"fee" codec:"fee"`
 }
 
 // TransactionParams contains the parameters that help a client construct
@@ -260,28 +458,87 @@ type TransactionParams struct {
 	// at least MinTxnFee for the current network protocol.
 	//
 	// required: true
-	Fee uint64 `json:"fee"`
+	Fee uint64 `json:"fee" codec:"fee"`
 
 	// Genesis ID
 	//
 	// required: true
-	GenesisID string `json:"genesisID"`
+	GenesisID string `json:"genesisID" codec:"genesisID"`
 
 	// Genesis hash
 	//
 	// required: true
-	GenesisHash lib.Bytes `json:"genesishashb64"`
+	GenesisHash lib.Bytes `json:"genesishashb64" codec:"genesishashb64"`
 
 	// LastRound indicates the last round seen
 	//
 	// required: true
-	LastRound uint64 `json:"lastRound"`
+	LastRound uint64 `json:"lastRound" codec:"lastRound"`
 
 	// ConsensusVersion indicates the consensus protocol version
 	// as of LastRound.
 	//
 	// required: true
-	ConsensusVersion string `json:"consensusVersion"`
+	ConsensusVersion string `json:"consensusVersion" codec:"consensusVersion"`
+
+	// PoolFeePerByte is the lowest fee per byte of encoded transaction
+	// that the node's transaction pool currently accepts.
+	//
+	// required: true
+	PoolFeePerByte uint64 `json:"poolFeePerByte" codec:"poolFeePerByte"`
+
+	// PoolPressureFeeCap is the most that the rise of PoolFeePerByte while
+	// the pool stays busy requires a transaction to pay, whatever its size.
+	// Evicting a transaction from a full pool may still take more.
+	//
+	// required: true
+	PoolPressureFeeCap uint64 `json:"poolPressureFeeCap" codec:"poolPressureFeeCap"`
+
+	// ReplacementFeeBump is the percentage by which the fee of a transaction
+	// must exceed the fee of the pending transaction it replaces, i.e. one
+	// with the same lease, or with the same sender, first valid round and note.
+	//
+	// required: true
+	ReplacementFeeBump uint64 `json:"replacementFeeBump" codec:"replacementFeeBump"`
+
+	// MaxPendingPerSender is the number of transactions each sender may have
+	// pending in the node's transaction pool, or 0 if there is no limit.
+	//
+	// required: true
+	MaxPendingPerSender uint64 `json:"maxPendingPerSender" codec:"maxPendingPerSender"`
+}
+
+// PendingTransactionEvent reports a change to the transaction pool of the node
+// swagger:model PendingTransactionEvent
+type PendingTransactionEvent struct {
+	// Type is what happened to the transaction: admitted, rebroadcast,
+	// evicted or committed
+	//
+	// required: true
+	Type string `json:"type" codec:"type"`
+
+	// TxID is the transaction ID
+	//
+	// required: true
+	TxID string `json:"tx" codec:"tx"`
+
+	// Round is the round that committed the transaction for committed
+	// events, and the last round of the node otherwise
+	//
+	// required: true
+	Round uint64 `json:"round" codec:"round"`
+
+	// Reason is why an evicted transaction left the pool: expired,
+	// fee-too-low, overspend, lease-taken, replaced, replacement-failed or
+	// invalid
+	Reason string `json:"reason,omitempty" codec:"reason,omitempty"`
+
+	// Error describes why an evicted transaction left the pool, as
+	// reported afterwards for the pending transaction
+	Error string `json:"error,omitempty" codec:"error,omitempty"`
+
+	// ReplacedBy is the ID of the transaction that replaced an evicted one
+	ReplacedBy string `json:"replacedBy,omitempty" codec:"replacedBy,omitempty"`
 }
 
 // Block contains a block information
@@ -290,32 +547,32 @@ type Block struct {
 	// Hash is the current block hash
 	//
 	// required: true
-	Hash string `json:"hash"`
+	Hash string `json:"hash" codec:"hash"`
 
 	// PreviousBlockHash is the previous block hash
 	//
 	// required: true
-	PreviousBlockHash string `json:"previousBlockHash"`
+	PreviousBlockHash string `json:"previousBlockHash" codec:"previousBlockHash"`
 
 	// Seed is the sortition seed
 	//
 	// required: true
-	Seed string `json:"seed"`
+	Seed string `json:"seed" codec:"seed"`
 
 	// Proposer is the address of this block proposer
 	//
 	// required: true
-	Proposer string `json:"proposer"`
+	Proposer string `json:"proposer" codec:"proposer"`
 
 	// Round is the current round on which this block was appended to the chain
 	//
 	// required: true
-	Round uint64 `json:"round"`
+	Round uint64 `json:"round" codec:"round"`
 
 	// Period is the period on which the block was confirmed
 	//
 	// required: true
-	Period uint64 `json:"period"`
+	Period uint64 `json:"period" codec:"period"`
 
 	// TransactionsRoot authenticates the set of transactions appearing in the block.
 	// More specifically, it's the root of a merkle tree whose leaves are the block's Txids, in lexicographic order.
@@ -324,27 +581,27 @@ type Block struct {
 	// Two blocks with the same transactions but in a different order and with different signatures will have the same TxnRoot.
 	//
 	// required: true
-	TransactionsRoot string `json:"txnRoot"`
+	TransactionsRoot string `json:"txnRoot" codec:"txnRoot"`
 
 	// RewardsLevel specifies how many rewards, in MicroAlgos,
 	// have been distributed to each config.Protocol.RewardUnit
 	// of MicroAlgos since genesis.
-	RewardsLevel uint64 `json:"reward"`
+	RewardsLevel uint64 `json:"reward" codec:"reward"`
 
 	// The number of new MicroAlgos added to the participation stake from rewards at the next round.
-	RewardsRate uint64 `json:"rate"`
+	RewardsRate uint64 `json:"rate" codec:"rate"`
 
 	// The number of leftover MicroAlgos after the distribution of RewardsRate/rewardUnits
 	// MicroAlgos for every reward unit in the next round.
-	RewardsResidue uint64 `json:"frac"`
+	RewardsResidue uint64 `json:"frac" codec:"frac"`
 
 	// Transactions is the list of transactions in this block
-	Transactions TransactionList `json:"txns"`
+	Transactions TransactionList `json:"txns" codec:"txns"`
 
 	// TimeStamp in seconds since epoch
 	//
 	// required: true
-	Timestamp int64 `json:"timestamp"`
+	Timestamp int64 `json:"timestamp" codec:"timestamp"`
 
 	UpgradeState
 	UpgradeVote
@@ -356,27 +613,27 @@ type UpgradeState struct {
 	// CurrentProtocol is a string that represents the current protocol
 	//
 	// required: true
-	CurrentProtocol string `json:"currentProtocol"`
+	CurrentProtocol string `json:"currentProtocol" codec:"currentProtocol"`
 
 	// NextProtocol is a string that represents the next proposed protocol
 	//
 	// required: true
-	NextProtocol string `json:"nextProtocol"`
+	NextProtocol string `json:"nextProtocol" codec:"nextProtocol"`
 
 	// NextProtocolApprovals is the number of blocks which approved the protocol upgrade
 	//
 	// required: true
-	NextProtocolApprovals uint64 `json:"nextProtocolApprovals"`
+	NextProtocolApprovals uint64 `json:"nextProtocolApprovals" codec:"nextProtocolApprovals"`
 
 	// NextProtocolVoteBefore is the deadline round for this protocol upgrade (No votes will be consider after this round)
 	//
 	// required: true
-	NextProtocolVoteBefore uint64 `json:"nextProtocolVoteBefore"`
+	NextProtocolVoteBefore uint64 `json:"nextProtocolVoteBefore" codec:"nextProtocolVoteBefore"`
 
 	// NextProtocolSwitchOn is the round on which the protocol upgrade will take effect
 	//
 	// required: true
-	NextProtocolSwitchOn uint64 `json:"nextProtocolSwitchOn"`
+	NextProtocolSwitchOn uint64 `json:"nextProtocolSwitchOn" codec:"nextProtocolSwitchOn"`
 }
 
 // UpgradeVote represents the vote of the block proposer with respect to protocol upgrades.
@@ -385,12 +642,12 @@ type UpgradeVote struct {
 	// UpgradePropose indicates a proposed upgrade
 	//
 	// required: true
-	UpgradePropose string `json:"upgradePropose"`
+	UpgradePropose string `json:"upgradePropose" codec:"upgradePropose"`
 
 	// UpgradeApprove indicates a yes vote for the current proposal
 	//
 	// required: true
-	UpgradeApprove bool `json:"upgradeApprove"`
+	UpgradeApprove bool `json:"upgradeApprove" codec:"upgradeApprove"`
 }
 
 // Supply represents the current supply of MicroAlgos in the system
@@ -399,17 +656,17 @@ type Supply struct {
 	// Round
 	//
 	// required: true
-	Round uint64 `json:"round"`
+	Round uint64 `json:"round" codec:"round"`
 
 	// TotalMoney
 	//
 	// required: true
-	TotalMoney uint64 `json:"totalMoney"`
+	TotalMoney uint64 `json:"totalMoney" codec:"totalMoney"`
 
 	// OnlineMoney
 	//
 	// required: true
-	OnlineMoney uint64 `json:"onlineMoney"`
+	OnlineMoney uint64 `json:"onlineMoney" codec:"onlineMoney"`
 }
 
 // PendingTransactions represents a potentially truncated list of transactions currently in the
@@ -418,8 +675,194 @@ type Supply struct {
 type PendingTransactions struct {
 	// TruncatedTxns
 	// required: true
-	TruncatedTxns TransactionList `json:"truncatedTxns"`
+	TruncatedTxns TransactionList `json:"truncatedTxns" codec:"truncatedTxns"`
 	// TotalTxns
 	// required: true
-	TotalTxns uint64 `json:"totalTxns"`
+	TotalTxns uint64 `json:"totalTxns" codec:"totalTxns"`
+}
+
+// DryRunTransaction contains the result of evaluating a transaction in a dry run
+// swagger:model DryRunTransaction
+type DryRunTransaction struct {
+	// Transaction is the evaluated transaction, along with the rewards
+	// and closing amounts that applying it would produce
+	//
+	// required: true
+	Transaction Transaction `json:"txn" codec:"txn"`
+
+	// Error is set if the transaction, or the group it belongs to,
+	// would be rejected by the ledger
+	//
+	// required: false
+	Error string `json:"error,omitempty" codec:"error,omitempty"`
+
+	// Accounts holds the resulting state of the accounts that the
+	// transaction touches, after its whole group was applied
+	//
+	// required: false
+	Accounts []Account `json:"accounts,omitempty" codec:"accounts,omitempty"`
+}
+
+// DryRunResults contains the results of evaluating a list of transactions
+// in a dry run
+// swagger:model DryRunResults
+type DryRunResults struct {
+	// Round is the round in which the transactions were evaluated
+	//
+	// required: true
+	Round uint64 `json:"round" codec:"round"`
+
+	// Transactions holds the results, in the order the transactions were given
+	//
+	// required: true
+	Transactions []DryRunTransaction `json:"transactions" codec:"transactions"`
+}
+
+// PeerBan describes a peer that the node refuses to connect to until the
+// ban expires
+// swagger:model PeerBan
+type PeerBan struct {
+	// Peer is the host name or address of the peer, or its base64
+	// encoded identity key
+	//
+	// required: true
+	Peer string `json:"peer" codec:"peer"`
+
+	// Reason is the misbehavior that got the peer banned
+	//
+	// required: true
+	Reason string `json:"reason" codec:"reason"`
+
+	// Expires is the time the ban is lifted, in seconds since the epoch
+	//
+	// required: true
+	Expires int64 `json:"expires" codec:"expires"`
+
+	// Bans counts the times this peer was banned
+	//
+	// required: true
+	Bans uint64 `json:"bans" codec:"bans"`
+}
+
+// PeerBanList contains the peers that are currently banned
+// swagger:model PeerBanList
+type PeerBanList struct {
+	// required: true
+	Bans []PeerBan `json:"bans" codec:"bans"`
+}
+
+// DevModeStatus contains the state of a dev mode node
+// swagger:model DevModeStatus
+type DevModeStatus struct {
+	// Round is the latest round sealed by the node
+	//
+	// required: true
+	Round uint64 `json:"round" codec:"round"`
+
+	// ClockOffset is how far ahead of the wall clock, in seconds, the
+	// clock used for block timestamps is
+	//
+	// required: true
+	ClockOffset int64 `json:"clockOffset" codec:"clockOffset"`
+}
+
+// UpgradeSupport is the support for a protocol version among the proposers
+// of recent blocks
+// swagger:model UpgradeSupport
+type UpgradeSupport struct {
+	// Version is the consensus protocol version
+	//
+	// required: true
+	Version string `json:"version" codec:"version"`
+
+	// Proposers is the number of distinct accounts whose most recent
+	// block supports Version
+	//
+	// required: true
+	Proposers uint64 `json:"proposers" codec:"proposers"`
+
+	// Blocks is the number of sampled blocks that support Version
+	//
+	// required: true
+	Blocks uint64 `json:"blocks" codec:"blocks"`
+
+	// OnlineStake is the current online stake, in microAlgos, of the
+	// proposers that support Version
+	//
+	// required: true
+	OnlineStake uint64 `json:"onlineStake" codec:"onlineStake"`
+
+	// Supported indicates whether this node can run Version
+	//
+	// required: true
+	Supported bool `json:"supported" codec:"supported"`
+}
+
+// UpgradeStatus reports on the progress of a consensus protocol upgrade
+// swagger:model UpgradeStatus
+type UpgradeStatus struct {
+	// LastRound indicates the last round seen
+	//
+	// required: true
+	LastRound uint64 `json:"lastRound" codec:"lastRound"`
+
+	// CurrentProtocol is the consensus protocol version as of LastRound
+	//
+	// required: true
+	CurrentProtocol string `json:"currentProtocol" codec:"currentProtocol"`
+
+	// NextProtocol is the protocol version being voted on or waiting to
+	// take effect, if any
+	//
+	// required: true
+	NextProtocol string `json:"nextProtocol" codec:"nextProtocol"`
+
+	// NextProtocolApprovals is the number of blocks that approved NextProtocol
+	//
+	// required: true
+	NextProtocolApprovals uint64 `json:"nextProtocolApprovals" codec:"nextProtocolApprovals"`
+
+	// NextProtocolVoteBefore is the round by which the vote on NextProtocol ends
+	//
+	// required: true
+	NextProtocolVoteBefore uint64 `json:"nextProtocolVoteBefore" codec:"nextProtocolVoteBefore"`
+
+	// NextProtocolSwitchOn is the round at which NextProtocol takes effect
+	// if it is approved
+	//
+	// required: true
+	NextProtocolSwitchOn uint64 `json:"nextProtocolSwitchOn" codec:"nextProtocolSwitchOn"`
+
+	// NextProtocolSupported indicates whether this node can run NextProtocol
+	//
+	// required: true
+	NextProtocolSupported bool `json:"nextProtocolSupported" codec:"nextProtocolSupported"`
+
+	// UpgradeThreshold is the number of approvals an upgrade needs
+	// among UpgradeVoteRounds blocks
+	//
+	// required: true
+	UpgradeThreshold uint64 `json:"upgradeThreshold" codec:"upgradeThreshold"`
+
+	// UpgradeVoteRounds is the number of rounds the vote on an upgrade lasts
+	//
+	// required: true
+	UpgradeVoteRounds uint64 `json:"upgradeVoteRounds" codec:"upgradeVoteRounds"`
+
+	// FirstSampledRound is the first of the recent blocks whose proposers
+	// are tallied in Support
+	//
+	// required: true
+	FirstSampledRound uint64 `json:"firstSampledRound" codec:"firstSampledRound"`
+
+	// OnlineStake is the total online stake, in microAlgos, as of LastRound
+	//
+	// required: true
+	OnlineStake uint64 `json:"onlineStake" codec:"onlineStake"`
+
+	// Support tallies the protocol versions supported by the proposers of
+	// recent blocks, in decreasing order of online stake
+	//
+	// required: true
+	Support []UpgradeSupport `json:"support" codec:"support"`
 }
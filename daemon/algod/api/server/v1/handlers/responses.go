@@ -28,9 +28,18 @@ package handlers
 import (
 	"encoding/json"
 	"io"
+	"mime"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/algorand/go-algorand/logging"
+	"github.com/algorand/go-algorand/protocol"
+)
+
+const (
+	jsonContentType    = "application/json"
+	msgpackContentType = "application/msgpack"
 )
 
 // Response is a generic interface wrapping any data returned by the server.
@@ -54,12 +63,76 @@ func writeJSON(obj interface{}, w io.Writer) error {
 	return enc.Encode(obj)
 }
 
-// SendJSON is like writeJSON, but it writes to the log instead of returning an error.
+// writeMsgpack writes the canonical msgpack encoding of obj to w.
+func writeMsgpack(obj interface{}, w io.Writer) error {
+	_, err := w.Write(protocol.Encode(obj))
+	return err
+}
+
+// writeObject writes obj to w as msgpack if useMsgpack is set, and as JSON otherwise.
+func writeObject(obj interface{}, w io.Writer, useMsgpack bool) error {
+	if useMsgpack {
+		return writeMsgpack(obj, w)
+	}
+	return writeJSON(obj, w)
+}
+
+// wantsMsgpack returns true if the Accept header of r names the msgpack media type
+// with a quality at least as high as the one it gives JSON.
+// JSON remains the default for everything else, including wildcard ranges.
+func wantsMsgpack(r *http.Request) bool {
+	// quality of the most specific range that matches each media type,
+	// where specificity 0 means no range matched
+	var msgpackQ, jsonQ float64
+	var jsonSpecificity int
+	for _, accept := range r.Header["Accept"] {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(mediaRange)
+			if err != nil {
+				continue
+			}
+			q := 1.0
+			if qParam, ok := params["q"]; ok {
+				q, err = strconv.ParseFloat(qParam, 64)
+				if err != nil || q < 0 || q > 1 {
+					continue
+				}
+			}
+
+			switch mediaType {
+			case msgpackContentType:
+				msgpackQ = q
+			case jsonContentType:
+				jsonQ, jsonSpecificity = q, 3
+			case "application/*":
+				if jsonSpecificity < 2 {
+					jsonQ, jsonSpecificity = q, 2
+				}
+			case "*/*":
+				if jsonSpecificity < 1 {
+					jsonQ, jsonSpecificity = q, 1
+				}
+			}
+		}
+	}
+	return msgpackQ > 0 && msgpackQ >= jsonQ
+}
+
+func responseContentType(useMsgpack bool) string {
+	if useMsgpack {
+		return msgpackContentType
+	}
+	return jsonContentType
+}
+
+// SendResponse is like writeObject, but it writes to the log instead of returning an error.
+// The response is encoded as msgpack if the request asks for it, and as JSON otherwise.
 // The caller must ensure that no writes to w happen after this function is called.
 // Unwraps a Response object and converts it to an HTTP Response.
-func SendJSON(obj Response, w http.ResponseWriter, log logging.Logger) {
-	w.Header().Set("Content-Type", "application/json")
-	err := writeJSON(obj.getBody(), w)
+func SendResponse(obj Response, w http.ResponseWriter, r *http.Request, log logging.Logger) {
+	useMsgpack := wantsMsgpack(r)
+	w.Header().Set("Content-Type", responseContentType(useMsgpack))
+	err := writeObject(obj.getBody(), w, useMsgpack)
 	if err != nil {
 		log.Warnf("algod failed to write an object to the response stream: %v", err)
 	}
@@ -125,6 +198,18 @@ func (r TransactionsResponse) getBody() interface{} {
 	return r.Body
 }
 
+// TransactionSearchResponse contains a page of the transactions that match a search
+//
+// swagger:response TransactionSearchResponse
+type TransactionSearchResponse struct {
+	// in: body
+	Body *TransactionSearchResults
+}
+
+func (r TransactionSearchResponse) getBody() interface{} {
+	return r.Body
+}
+
 // TransactionFeeResponse contains a suggested fee
 //
 // swagger:response TransactionFeeResponse
@@ -174,6 +259,54 @@ func (r SupplyResponse) getBody() interface{} {
 	return r.Body
 }
 
+// DryRunResponse contains the results of a dry run
+//
+// swagger:response DryRunResponse
+type DryRunResponse struct {
+	// in: body
+	Body *DryRunResults
+}
+
+func (r DryRunResponse) getBody() interface{} {
+	return r.Body
+}
+
+// PeerBansResponse contains the peers that are currently banned
+//
+// swagger:response PeerBansResponse
+type PeerBansResponse struct {
+	// in: body
+	Body *PeerBanList
+}
+
+func (r PeerBansResponse) getBody() interface{} {
+	return r.Body
+}
+
+// UpgradeStatusResponse contains the progress of a consensus protocol upgrade
+//
+// swagger:response UpgradeStatusResponse
+type UpgradeStatusResponse struct {
+	// in: body
+	Body *UpgradeStatus
+}
+
+func (r UpgradeStatusResponse) getBody() interface{} {
+	return r.Body
+}
+
+// DevModeStatusResponse contains the state of a dev mode node
+//
+// swagger:response DevModeStatusResponse
+type DevModeStatusResponse struct {
+	// in: body
+	Body *DevModeStatus
+}
+
+func (r DevModeStatusResponse) getBody() interface{} {
+	return r.Body
+}
+
 /* Errors */
 
 // PendingTransactionsResponse contains a (potentially truncated) list of transactions and
@@ -42,6 +42,13 @@ var Routes = lib.Routes{
 		HandlerFunc: handlers.WaitForBlock,
 	},
 
+	lib.Route{
+		Name:        "upgrade-status",
+		Method:      "GET",
+		Path:        "/status/upgrade",
+		HandlerFunc: handlers.GetUpgradeStatus,
+	},
+
 	lib.Route{
 		Name:        "raw-transaction",
 		Method:      "POST",
@@ -49,6 +56,13 @@ var Routes = lib.Routes{
 		HandlerFunc: handlers.RawTransaction,
 	},
 
+	lib.Route{
+		Name:        "dryrun",
+		Method:      "POST",
+		Path:        "/transactions/dryrun",
+		HandlerFunc: handlers.DryRun,
+	},
+
 	lib.Route{
 		Name:        "account-information",
 		Method:      "GET",
@@ -91,6 +105,13 @@ var Routes = lib.Routes{
 		HandlerFunc: handlers.GetBlock,
 	},
 
+	lib.Route{
+		Name:        "block-stream",
+		Method:      "GET",
+		Path:        "/block-stream/{round:[0-9]+}",
+		HandlerFunc: handlers.StreamBlocks,
+	},
+
 	lib.Route{
 		Name:        "ledger-supply",
 		Method:      "GET",
@@ -98,6 +119,34 @@ var Routes = lib.Routes{
 		HandlerFunc: handlers.GetSupply,
 	},
 
+	lib.Route{
+		Name:        "peer-bans",
+		Method:      "GET",
+		Path:        "/peers/bans",
+		HandlerFunc: handlers.GetPeerBans,
+	},
+
+	lib.Route{
+		Name:        "devmode-advance-round",
+		Method:      "POST",
+		Path:        "/devmode/round",
+		HandlerFunc: handlers.DevModeAdvanceRound,
+	},
+
+	lib.Route{
+		Name:        "devmode-advance-clock",
+		Method:      "POST",
+		Path:        "/devmode/clock/{seconds:[0-9]+}",
+		HandlerFunc: handlers.DevModeAdvanceClock,
+	},
+
+	lib.Route{
+		Name:        "catchup",
+		Method:      "POST",
+		Path:        "/catchup/{catchpoint}",
+		HandlerFunc: handlers.Catchup,
+	},
+
 	lib.Route{
 		Name:        "list-pending-transactions",
 		Method:      "GET",
@@ -105,6 +154,13 @@ var Routes = lib.Routes{
 		HandlerFunc: handlers.GetPendingTransactions,
 	},
 
+	lib.Route{
+		Name:        "pending-transaction-stream",
+		Method:      "GET",
+		Path:        "/transactions/pending-stream",
+		HandlerFunc: handlers.StreamPendingTransactionEvents,
+	},
+
 	lib.Route{
 		Name:        "pending-transaction-information",
 		Method:      "GET",
@@ -120,4 +176,18 @@ var Routes = lib.Routes{
 		Path:        "/transaction/{txid:[A-Z0-9]+}",
 		HandlerFunc: handlers.GetTransactionByID,
 	},
+
+	lib.Route{
+		Name:        "search-transactions",
+		Method:      "GET",
+		Path:        "/transactions/search",
+		HandlerFunc: handlers.SearchTransactions,
+	},
+
+	lib.Route{
+		Name:        "search-account-transactions",
+		Method:      "GET",
+		Path:        fmt.Sprintf("/account/{addr:[A-Z0-9]{%d}}/transactions/search", KeyLength),
+		HandlerFunc: handlers.SearchTransactions,
+	},
 }
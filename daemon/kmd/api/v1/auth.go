@@ -20,6 +20,7 @@ import (
 	"crypto/subtle"
 	"net/http"
 
+	"github.com/algorand/go-algorand/daemon/kmd/config"
 	"github.com/algorand/go-algorand/logging"
 	"github.com/algorand/go-algorand/util/tokens"
 )
@@ -29,7 +30,13 @@ const (
 	KMDTokenHeader = "X-KMD-API-Token"
 )
 
-func authMiddleware(log logging.Logger, apiToken string) func(http.Handler) http.Handler {
+// scopedToken pairs a scoped API token with its policy
+type scopedToken struct {
+	token  []byte
+	policy *accessPolicy
+}
+
+func authMiddleware(log logging.Logger, apiToken string, scopedTokens []config.ScopedToken) func(http.Handler) http.Handler {
 	// Make sure no one is trying to call us with an invalid token
 	err := tokens.ValidateAPIToken(apiToken)
 	if err != nil {
@@ -38,6 +45,22 @@ func authMiddleware(log logging.Logger, apiToken string) func(http.Handler) http
 
 	apiTokenBytes := []byte(apiToken)
 
+	var scoped []scopedToken
+	for _, st := range scopedTokens {
+		err = tokens.ValidateAPIToken(st.Token)
+		if err != nil {
+			log.Fatalf("cannot start server with invalid scoped API token %s: %v", st.Name, err)
+		}
+		if st.Token == apiToken {
+			log.Fatalf("cannot start server with scoped API token %s equal to the API token", st.Name)
+		}
+		policy, err := makeAccessPolicy(st)
+		if err != nil {
+			log.Fatalf("cannot start server with invalid policy for scoped API token %s: %v", st.Name, err)
+		}
+		scoped = append(scoped, scopedToken{token: []byte(st.Token), policy: policy})
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
@@ -51,6 +74,15 @@ func authMiddleware(log logging.Logger, apiToken string) func(http.Handler) http
 				return
 			}
 
+			// Check the scoped tokens, noting the policy of the one that
+			// matches for the handlers to enforce
+			for _, st := range scoped {
+				if subtle.ConstantTimeCompare(providedToken, st.token) == 1 {
+					next.ServeHTTP(w, withPolicy(r, st.policy))
+					return
+				}
+			}
+
 			// Token was incorrect, return an error
 			errorResponse(w, http.StatusUnauthorized, errInvalidAPIToken)
 		})
@@ -26,3 +26,11 @@ var errCouldNotDecode = fmt.Errorf("could not decode request body")
 var errCouldNotDecodeAddress = fmt.Errorf("could not decode address")
 var errCouldNotDecodeTx = fmt.Errorf("could not decode transaction")
 var errInvalidAPIToken = fmt.Errorf("invalid API token")
+var errBackupNotSupported = fmt.Errorf("wallet driver does not support backups")
+var errPolicyForbidden = fmt.Errorf("API token is not allowed to use this endpoint")
+var errPolicyWallet = fmt.Errorf("API token is not allowed to use this wallet")
+var errPolicyTxType = fmt.Errorf("API token is not allowed to sign this transaction type")
+var errPolicyAmount = fmt.Errorf("transaction amount and fee exceed the API token's limit")
+var errPolicyCloseOut = fmt.Errorf("API token with an amount limit is not allowed to close out accounts")
+var errPolicyReceiver = fmt.Errorf("API token is not allowed to send to or hand control to this address")
+var errPolicyRekey = fmt.Errorf("API token is not allowed to sign rekeying transactions")
@@ -22,6 +22,7 @@ import (
 	"github.com/gorilla/mux"
 
 	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/daemon/kmd/config"
 	"github.com/algorand/go-algorand/daemon/kmd/lib/kmdapi"
 	"github.com/algorand/go-algorand/daemon/kmd/session"
 	"github.com/algorand/go-algorand/daemon/kmd/wallet"
@@ -33,9 +34,38 @@ import (
 )
 
 // reqContext is passed to each of the handlers below via wrapCtx, allowing
-// handlers to interact with kmd's session store
+// handlers to interact with kmd's session store and audit log
 type reqContext struct {
-	sm *session.Manager
+	sm    *session.Manager
+	audit logging.Logger
+}
+
+// authWithWalletHandleToken fetches the wallet for a wallet handle token, and
+// checks that the API token that authenticated r may use it. Wallet handle
+// tokens are bearer tokens, so this check can't happen only at init time.
+func (ctx reqContext) authWithWalletHandleToken(r *http.Request, walletHandleToken []byte) (wallet.Wallet, int64, error) {
+	w, expiresSeconds, err := ctx.sm.AuthWithWalletHandleToken(walletHandleToken)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !policyFromRequest(r).allowsWallet(w) {
+		return nil, 0, errPolicyWallet
+	}
+	return w, expiresSeconds, nil
+}
+
+// renewWalletHandleToken is like authWithWalletHandleToken, but also renews
+// the wallet handle token
+func (ctx reqContext) renewWalletHandleToken(r *http.Request, walletHandleToken []byte) (wallet.Wallet, int64, error) {
+	w, _, err := ctx.authWithWalletHandleToken(r, walletHandleToken)
+	if err != nil {
+		return nil, 0, err
+	}
+	_, expiresSeconds, err := ctx.sm.RenewWalletHandleToken(walletHandleToken)
+	if err != nil {
+		return nil, 0, err
+	}
+	return w, expiresSeconds, nil
 }
 
 // errorResponse sets the specified status code (should != 200), and fills in the
@@ -103,9 +133,13 @@ func getWalletsHandler(ctx reqContext, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Fill in the APIV1 representation of each wallet
+	// Fill in the APIV1 representation of each wallet the API token may use
+	policy := policyFromRequest(r)
 	var apiWallets []kmdapi.APIV1Wallet
 	for _, metadata := range walletMetadatas {
+		if !policy.allowsWalletID(metadata.ID) {
+			continue
+		}
 		apiWallets = append(apiWallets, apiWalletFromMetadata(metadata))
 	}
 
@@ -233,6 +267,12 @@ func postWalletInitHandler(ctx reqContext, w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	// Check that the API token may use this wallet
+	if !policyFromRequest(r).allowsWalletID([]byte(req.WalletID)) {
+		errorResponse(w, http.StatusForbidden, errPolicyWallet)
+		return
+	}
+
 	// Attempt to auth
 	handleToken, err := ctx.sm.InitWalletHandle(wallet, []byte(req.WalletPassword))
 	if err != nil {
@@ -279,7 +319,7 @@ func postWalletInfoHandler(ctx reqContext, w http.ResponseWriter, r *http.Reques
 	}
 
 	// Fetch the wallet from the WalletHandleToken
-	wallet, expiresSeconds, err := ctx.sm.AuthWithWalletHandleToken([]byte(req.WalletHandleToken))
+	wallet, expiresSeconds, err := ctx.authWithWalletHandleToken(r, []byte(req.WalletHandleToken))
 	if err != nil {
 		errorResponse(w, http.StatusUnauthorized, err)
 		return
@@ -336,7 +376,7 @@ func postMasterKeyExportHandler(ctx reqContext, w http.ResponseWriter, r *http.R
 	}
 
 	// Fetch the wallet from the WalletHandleToken
-	wallet, _, err := ctx.sm.AuthWithWalletHandleToken([]byte(req.WalletHandleToken))
+	wallet, _, err := ctx.authWithWalletHandleToken(r, []byte(req.WalletHandleToken))
 	if err != nil {
 		errorResponse(w, http.StatusUnauthorized, err)
 		return
@@ -358,6 +398,142 @@ func postMasterKeyExportHandler(ctx reqContext, w http.ResponseWriter, r *http.R
 	successResponse(w, resp)
 }
 
+// postWalletBackupHandler handles `POST /v1/wallet/backup`
+func postWalletBackupHandler(ctx reqContext, w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /v1/wallet/backup BackupWallet
+	//---
+	//    Summary: Back up a wallet
+	//    Description: >
+	//      Export everything in the wallet -- the master derivation key, the index of the last
+	//      derived key, imported keys, and multisig preimages -- as a single versioned archive,
+	//      encrypted with the backup password. Unlike `POST /v1/master-key/export`, the archive
+	//      also covers keys imported from other wallets.
+	//    Produces:
+	//    - application/json
+	//    Parameters:
+	//      - name: Backup Wallet Request
+	//        in: body
+	//        required: true
+	//        schema:
+	//          "$ref": "#/definitions/BackupWalletRequest"
+	//    Responses:
+	//      "200":
+	//        "$ref": "#/responses/BackupWalletResponse"
+	var req kmdapi.APIV1POSTWalletBackupRequest
+
+	// Decode the request
+	decoder := protocol.NewJSONDecoder(r.Body)
+	err := decoder.Decode(&req)
+	if err != nil {
+		errorResponse(w, http.StatusBadRequest, errCouldNotDecode)
+		return
+	}
+
+	// Fetch the wallet from the WalletHandleToken
+	wallet, _, err := ctx.authWithWalletHandleToken(r, []byte(req.WalletHandleToken))
+	if err != nil {
+		errorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	// Only some drivers can back up a wallet
+	backupWallet, ok := wallet.(driver.BackupWallet)
+	if !ok {
+		errorResponse(w, http.StatusBadRequest, errBackupNotSupported)
+		return
+	}
+
+	// Build the backup
+	backup, err := backupWallet.Backup([]byte(req.WalletPassword), []byte(req.BackupPassword))
+	if err != nil {
+		errorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Build the response
+	resp := kmdapi.APIV1POSTWalletBackupResponse{
+		Backup: backup,
+	}
+
+	// Return and encode the response
+	successResponse(w, resp)
+}
+
+// postWalletRestoreHandler handles `POST /v1/wallet/restore`
+func postWalletRestoreHandler(ctx reqContext, w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /v1/wallet/restore RestoreWallet
+	//---
+	//    Summary: Restore a wallet from a backup
+	//    Description: >
+	//      Create a wallet from an archive made by `POST /v1/wallet/backup`, after checking its
+	//      integrity. The wallet keeps its original name and ID unless a name is given. If
+	//      rename_on_conflict is set, a name that is already taken gets a numeric suffix and an
+	//      ID that is already taken is replaced with a new one.
+	//    Produces:
+	//    - application/json
+	//    Parameters:
+	//      - name: Restore Wallet Request
+	//        in: body
+	//        required: true
+	//        schema:
+	//          "$ref": "#/definitions/RestoreWalletRequest"
+	//    Responses:
+	//      "200":
+	//        "$ref": "#/responses/RestoreWalletResponse"
+	var req kmdapi.APIV1POSTWalletRestoreRequest
+
+	// Decode the request
+	decoder := protocol.NewJSONDecoder(r.Body)
+	err := decoder.Decode(&req)
+	if err != nil {
+		errorResponse(w, http.StatusBadRequest, errCouldNotDecode)
+		return
+	}
+
+	// Fetch the wallet driver
+	walletDriver, err := driver.FetchWalletDriver(req.WalletDriverName)
+	if err != nil {
+		errorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Only some drivers can restore a wallet
+	restoreDriver, ok := walletDriver.(driver.RestoreDriver)
+	if !ok {
+		errorResponse(w, http.StatusBadRequest, errBackupNotSupported)
+		return
+	}
+
+	// Restore the wallet via its driver
+	walletID, err := restoreDriver.RestoreWallet(req.Backup, []byte(req.BackupPassword), []byte(req.WalletName), []byte(req.WalletPassword), req.RenameOnConflict)
+	if err != nil {
+		errorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Fetch the wallet
+	wallet, err := walletDriver.FetchWallet(walletID)
+	if err != nil {
+		errorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Fetch metadata about the wallet we just restored
+	metadata, err := wallet.Metadata()
+	if err != nil {
+		errorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Build the response
+	resp := kmdapi.APIV1POSTWalletRestoreResponse{
+		Wallet: apiWalletFromMetadata(metadata),
+	}
+
+	// Return and encode the response
+	successResponse(w, resp)
+}
+
 // postWalletReleaseHandler handles `POST /v1/wallet/release`
 func postWalletReleaseHandler(ctx reqContext, w http.ResponseWriter, r *http.Request) {
 	// swagger:operation POST /v1/wallet/release ReleaseWalletHandleToken
@@ -427,7 +603,7 @@ func postWalletRenewHandler(ctx reqContext, w http.ResponseWriter, r *http.Reque
 	}
 
 	// Renew the walletHandleToken + fetch the wallet
-	wallet, expiresSeconds, err := ctx.sm.RenewWalletHandleToken([]byte(req.WalletHandleToken))
+	wallet, expiresSeconds, err := ctx.renewWalletHandleToken(r, []byte(req.WalletHandleToken))
 	if err != nil {
 		errorResponse(w, http.StatusUnauthorized, err)
 		return
@@ -554,7 +730,7 @@ func postKeyImportHandler(ctx reqContext, w http.ResponseWriter, r *http.Request
 	}
 
 	// Fetch the wallet from the WalletHandleToken
-	wallet, _, err := ctx.sm.AuthWithWalletHandleToken([]byte(req.WalletHandleToken))
+	wallet, _, err := ctx.authWithWalletHandleToken(r, []byte(req.WalletHandleToken))
 	if err != nil {
 		errorResponse(w, http.StatusUnauthorized, err)
 		return
@@ -611,7 +787,7 @@ func postKeyExportHandler(ctx reqContext, w http.ResponseWriter, r *http.Request
 	}
 
 	// Fetch the wallet from the WalletHandleToken
-	wallet, _, err := ctx.sm.AuthWithWalletHandleToken([]byte(req.WalletHandleToken))
+	wallet, _, err := ctx.authWithWalletHandleToken(r, []byte(req.WalletHandleToken))
 	if err != nil {
 		errorResponse(w, http.StatusUnauthorized, err)
 		return
@@ -663,7 +839,7 @@ func postKeyHandler(ctx reqContext, w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Fetch the wallet from the WalletHandleToken
-	wallet, _, err := ctx.sm.AuthWithWalletHandleToken([]byte(req.WalletHandleToken))
+	wallet, _, err := ctx.authWithWalletHandleToken(r, []byte(req.WalletHandleToken))
 	if err != nil {
 		errorResponse(w, http.StatusUnauthorized, err)
 		return
@@ -720,7 +896,7 @@ func deleteKeyHandler(ctx reqContext, w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Fetch the wallet from the WalletHandleToken
-	wallet, _, err := ctx.sm.AuthWithWalletHandleToken([]byte(req.WalletHandleToken))
+	wallet, _, err := ctx.authWithWalletHandleToken(r, []byte(req.WalletHandleToken))
 	if err != nil {
 		errorResponse(w, http.StatusUnauthorized, err)
 		return
@@ -768,7 +944,7 @@ func postKeyListHandler(ctx reqContext, w http.ResponseWriter, r *http.Request)
 	}
 
 	// Fetch the wallet from the WalletHandleToken
-	wallet, _, err := ctx.sm.AuthWithWalletHandleToken([]byte(req.WalletHandleToken))
+	wallet, _, err := ctx.authWithWalletHandleToken(r, []byte(req.WalletHandleToken))
 	if err != nil {
 		errorResponse(w, http.StatusUnauthorized, err)
 		return
@@ -797,7 +973,8 @@ func postTransactionSignHandler(ctx reqContext, w http.ResponseWriter, r *http.R
 	//    Summary: Sign a transaction
 	//    Description: >
 	//      Signs the passed transaction with a key from the wallet, determined
-	//      by the sender encoded in the transaction.
+	//      by the sender encoded in the transaction, or by the public key in
+	//      the request if the sender has been rekeyed.
 	//    Produces:
 	//    - application/json
 	//    Parameters:
@@ -820,11 +997,13 @@ func postTransactionSignHandler(ctx reqContext, w http.ResponseWriter, r *http.R
 	}
 
 	// Fetch the wallet from the WalletHandleToken
-	wallet, _, err := ctx.sm.AuthWithWalletHandleToken([]byte(req.WalletHandleToken))
+	wallet, _, err := ctx.authWithWalletHandleToken(r, []byte(req.WalletHandleToken))
 	if err != nil {
+		auditSigning(ctx.audit, r, nil, nil, crypto.Digest{}, err)
 		errorResponse(w, http.StatusUnauthorized, err)
 		return
 	}
+	walletID := walletIDForAudit(wallet)
 
 	// Decode the transaction
 	var tx transactions.Transaction
@@ -832,12 +1011,22 @@ func postTransactionSignHandler(ctx reqContext, w http.ResponseWriter, r *http.R
 
 	// Ensure we were able to decode the transaction
 	if err != nil {
+		auditSigning(ctx.audit, r, walletID, nil, crypto.Digest{}, errCouldNotDecodeTx)
 		errorResponse(w, http.StatusBadRequest, errCouldNotDecodeTx)
 		return
 	}
 
+	// Check the transaction against the API token's policy
+	err = policyFromRequest(r).checkTransaction(tx)
+	if err != nil {
+		auditSigning(ctx.audit, r, walletID, &tx, crypto.Digest(req.PublicKey), err)
+		errorResponse(w, http.StatusForbidden, err)
+		return
+	}
+
 	// Sign the transaction
-	stx, err := wallet.SignTransaction(tx, []byte(req.WalletPassword))
+	stx, err := wallet.SignTransaction(tx, req.PublicKey, []byte(req.WalletPassword))
+	auditSigning(ctx.audit, r, walletID, &tx, crypto.Digest(req.PublicKey), err)
 	if err != nil {
 		errorResponse(w, http.StatusBadRequest, err)
 		return
@@ -880,7 +1069,7 @@ func postMultisigListHandler(ctx reqContext, w http.ResponseWriter, r *http.Requ
 	}
 
 	// Fetch the wallet from the WalletHandleToken
-	wallet, _, err := ctx.sm.AuthWithWalletHandleToken([]byte(req.WalletHandleToken))
+	wallet, _, err := ctx.authWithWalletHandleToken(r, []byte(req.WalletHandleToken))
 	if err != nil {
 		errorResponse(w, http.StatusUnauthorized, err)
 		return
@@ -932,7 +1121,7 @@ func postMultisigImportHandler(ctx reqContext, w http.ResponseWriter, r *http.Re
 	}
 
 	// Fetch the wallet from the WalletHandleToken
-	wallet, _, err := ctx.sm.AuthWithWalletHandleToken([]byte(req.WalletHandleToken))
+	wallet, _, err := ctx.authWithWalletHandleToken(r, []byte(req.WalletHandleToken))
 	if err != nil {
 		errorResponse(w, http.StatusUnauthorized, err)
 		return
@@ -992,7 +1181,7 @@ func postMultisigExportHandler(ctx reqContext, w http.ResponseWriter, r *http.Re
 	}
 
 	// Fetch the wallet from the WalletHandleToken
-	wallet, _, err := ctx.sm.AuthWithWalletHandleToken([]byte(req.WalletHandleToken))
+	wallet, _, err := ctx.authWithWalletHandleToken(r, []byte(req.WalletHandleToken))
 	if err != nil {
 		errorResponse(w, http.StatusUnauthorized, err)
 		return
@@ -1046,11 +1235,13 @@ func postMultisigTransactionSignHandler(ctx reqContext, w http.ResponseWriter, r
 	}
 
 	// Fetch the wallet from the WalletHandleToken
-	wallet, _, err := ctx.sm.AuthWithWalletHandleToken([]byte(req.WalletHandleToken))
+	wallet, _, err := ctx.authWithWalletHandleToken(r, []byte(req.WalletHandleToken))
 	if err != nil {
+		auditSigning(ctx.audit, r, nil, nil, crypto.Digest{}, err)
 		errorResponse(w, http.StatusUnauthorized, err)
 		return
 	}
+	walletID := walletIDForAudit(wallet)
 
 	// Decode the transaction
 	var tx transactions.Transaction
@@ -1058,12 +1249,22 @@ func postMultisigTransactionSignHandler(ctx reqContext, w http.ResponseWriter, r
 
 	// Ensure we were able to decode the transaction
 	if err != nil {
+		auditSigning(ctx.audit, r, walletID, nil, crypto.Digest{}, errCouldNotDecodeTx)
 		errorResponse(w, http.StatusBadRequest, errCouldNotDecodeTx)
 		return
 	}
 
+	// Check the transaction against the API token's policy
+	err = policyFromRequest(r).checkTransaction(tx)
+	if err != nil {
+		auditSigning(ctx.audit, r, walletID, &tx, crypto.Digest(req.PublicKey), err)
+		errorResponse(w, http.StatusForbidden, err)
+		return
+	}
+
 	// Sign the transaction
-	msig, err := wallet.MultisigSignTransaction(tx, req.PublicKey, req.PartialMsig, []byte(req.WalletPassword))
+	msig, err := wallet.MultisigSignTransaction(tx, req.PublicKey, req.PartialMsig, []byte(req.WalletPassword), req.Signer)
+	auditSigning(ctx.audit, r, walletID, &tx, crypto.Digest(req.PublicKey), err)
 	if err != nil {
 		errorResponse(w, http.StatusBadRequest, err)
 		return
@@ -1114,7 +1315,7 @@ func deleteMultisigHandler(ctx reqContext, w http.ResponseWriter, r *http.Reques
 	}
 
 	// Fetch the wallet from the WalletHandleToken
-	wallet, _, err := ctx.sm.AuthWithWalletHandleToken([]byte(req.WalletHandleToken))
+	wallet, _, err := ctx.authWithWalletHandleToken(r, []byte(req.WalletHandleToken))
 	if err != nil {
 		errorResponse(w, http.StatusUnauthorized, err)
 		return
@@ -1136,8 +1337,13 @@ func deleteMultisigHandler(ctx reqContext, w http.ResponseWriter, r *http.Reques
 
 // wrapCtx is used to pass common context to each request without using any
 // global variables.
-func wrapCtx(ctx reqContext, handler func(reqContext, http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+func wrapCtx(ctx reqContext, perm permission, handler func(reqContext, http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
+		// Scoped API tokens may only use some classes of routes
+		if !policyFromRequest(r).allows(perm) {
+			errorResponse(w, http.StatusForbidden, errPolicyForbidden)
+			return
+		}
 		handler(ctx, w, r)
 	}
 }
@@ -1157,38 +1363,46 @@ func reqCallbackMiddleware(reqCB func()) func(http.Handler) http.Handler {
 }
 
 // RegisterHandlers sets up the API handlers on the passed router
-func RegisterHandlers(router *mux.Router, sm *session.Manager, log logging.Logger, apiToken string, reqCB func()) {
+func RegisterHandlers(router *mux.Router, sm *session.Manager, log logging.Logger, apiToken string, scopedTokens []config.ScopedToken, audit logging.Logger, reqCB func()) {
 	// All /v1 requests require a valid auth token
-	router.Use(authMiddleware(log, apiToken))
+	router.Use(authMiddleware(log, apiToken, scopedTokens))
 
 	// reqCB gets called each time a request matches a route
 	router.Use(reqCallbackMiddleware(reqCB))
 
+	// Signing requests go to the main log if there's no audit log
+	if audit == nil {
+		audit = log
+	}
+
 	// ctx holds the global context passed to each of the handlers
 	ctx := reqContext{
-		sm: sm,
-	}
-
-	router.HandleFunc("/wallets", wrapCtx(ctx, getWalletsHandler)).Methods("GET")
-	router.HandleFunc("/wallet", wrapCtx(ctx, postWalletHandler)).Methods("POST")
-	router.HandleFunc("/wallet/init", wrapCtx(ctx, postWalletInitHandler)).Methods("POST")
-	router.HandleFunc("/wallet/release", wrapCtx(ctx, postWalletReleaseHandler)).Methods("POST")
-	router.HandleFunc("/wallet/renew", wrapCtx(ctx, postWalletRenewHandler)).Methods("POST")
-	router.HandleFunc("/wallet/rename", wrapCtx(ctx, postWalletRenameHandler)).Methods("POST")
-	router.HandleFunc("/wallet/info", wrapCtx(ctx, postWalletInfoHandler)).Methods("POST")
-	router.HandleFunc("/master-key/export", wrapCtx(ctx, postMasterKeyExportHandler)).Methods("POST")
-
-	router.HandleFunc("/key/list", wrapCtx(ctx, postKeyListHandler)).Methods("POST")
-	router.HandleFunc("/key/import", wrapCtx(ctx, postKeyImportHandler)).Methods("POST")
-	router.HandleFunc("/key/export", wrapCtx(ctx, postKeyExportHandler)).Methods("POST")
-	router.HandleFunc("/key", wrapCtx(ctx, postKeyHandler)).Methods("POST")
-	router.HandleFunc("/key", wrapCtx(ctx, deleteKeyHandler)).Methods("DELETE")
-
-	router.HandleFunc("/multisig/list", wrapCtx(ctx, postMultisigListHandler)).Methods("POST")
-	router.HandleFunc("/multisig/sign", wrapCtx(ctx, postMultisigTransactionSignHandler)).Methods("POST")
-	router.HandleFunc("/multisig/import", wrapCtx(ctx, postMultisigImportHandler)).Methods("POST")
-	router.HandleFunc("/multisig/export", wrapCtx(ctx, postMultisigExportHandler)).Methods("POST")
-	router.HandleFunc("/multisig", wrapCtx(ctx, deleteMultisigHandler)).Methods("DELETE")
-
-	router.HandleFunc("/transaction/sign", wrapCtx(ctx, postTransactionSignHandler)).Methods("POST")
+		sm:    sm,
+		audit: audit,
+	}
+
+	router.HandleFunc("/wallets", wrapCtx(ctx, permRead, getWalletsHandler)).Methods("GET")
+	router.HandleFunc("/wallet", wrapCtx(ctx, permManage, postWalletHandler)).Methods("POST")
+	router.HandleFunc("/wallet/init", wrapCtx(ctx, permRead, postWalletInitHandler)).Methods("POST")
+	router.HandleFunc("/wallet/release", wrapCtx(ctx, permRead, postWalletReleaseHandler)).Methods("POST")
+	router.HandleFunc("/wallet/renew", wrapCtx(ctx, permRead, postWalletRenewHandler)).Methods("POST")
+	router.HandleFunc("/wallet/rename", wrapCtx(ctx, permManage, postWalletRenameHandler)).Methods("POST")
+	router.HandleFunc("/wallet/info", wrapCtx(ctx, permRead, postWalletInfoHandler)).Methods("POST")
+	router.HandleFunc("/wallet/backup", wrapCtx(ctx, permExport, postWalletBackupHandler)).Methods("POST")
+	router.HandleFunc("/wallet/restore", wrapCtx(ctx, permManage, postWalletRestoreHandler)).Methods("POST")
+	router.HandleFunc("/master-key/export", wrapCtx(ctx, permExport, postMasterKeyExportHandler)).Methods("POST")
+
+	router.HandleFunc("/key/list", wrapCtx(ctx, permRead, postKeyListHandler)).Methods("POST")
+	router.HandleFunc("/key/import", wrapCtx(ctx, permManage, postKeyImportHandler)).Methods("POST")
+	router.HandleFunc("/key/export", wrapCtx(ctx, permExport, postKeyExportHandler)).Methods("POST")
+	router.HandleFunc("/key", wrapCtx(ctx, permManage, postKeyHandler)).Methods("POST")
+	router.HandleFunc("/key", wrapCtx(ctx, permManage, deleteKeyHandler)).Methods("DELETE")
+
+	router.HandleFunc("/multisig/list", wrapCtx(ctx, permRead, postMultisigListHandler)).Methods("POST")
+	router.HandleFunc("/multisig/sign", wrapCtx(ctx, permSign, postMultisigTransactionSignHandler)).Methods("POST")
+	router.HandleFunc("/multisig/import", wrapCtx(ctx, permManage, postMultisigImportHandler)).Methods("POST")
+	router.HandleFunc("/multisig/export", wrapCtx(ctx, permRead, postMultisigExportHandler)).Methods("POST")
+	router.HandleFunc("/multisig", wrapCtx(ctx, permManage, deleteMultisigHandler)).Methods("DELETE")
+
+	router.HandleFunc("/transaction/sign", wrapCtx(ctx, permSign, postTransactionSignHandler)).Methods("POST")
 }
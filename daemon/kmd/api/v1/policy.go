@@ -0,0 +1,281 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package v1
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/daemon/kmd/config"
+	"github.com/algorand/go-algorand/daemon/kmd/wallet"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/logging"
+	"github.com/algorand/go-algorand/protocol"
+)
+
+// permission classifies what a route does, so that scoped tokens can be
+// limited to some classes of routes
+type permission int
+
+const (
+	// permRead routes open wallets and list their contents
+	permRead permission = iota
+	// permSign routes sign transactions
+	permSign
+	// permManage routes create, rename, or change wallets
+	permManage
+	// permExport routes reveal secret keys
+	permExport
+)
+
+// policyContextKey is the request context key holding the *accessPolicy of
+// the token that authenticated the request
+type policyContextKey struct{}
+
+// accessPolicy is a config.ScopedToken, prepared for checking requests. A
+// nil *accessPolicy stands for the kmd.token, which may do anything.
+type accessPolicy struct {
+	name           string
+	signOnly       bool
+	allowExport    bool
+	wallets        map[string]bool
+	txTypes        map[protocol.TxType]bool
+	maxAmount      uint64
+	maxAssetAmount uint64
+	receivers      map[basics.Address]bool
+}
+
+func makeAccessPolicy(st config.ScopedToken) (*accessPolicy, error) {
+	p := &accessPolicy{
+		name:           st.Name,
+		signOnly:       st.Policy.SignOnly,
+		allowExport:    st.Policy.AllowExport,
+		maxAmount:      st.Policy.MaxAmount,
+		maxAssetAmount: st.Policy.MaxAssetAmount,
+	}
+	if len(st.Policy.Wallets) > 0 {
+		p.wallets = make(map[string]bool)
+		for _, id := range st.Policy.Wallets {
+			p.wallets[id] = true
+		}
+	}
+	if len(st.Policy.TxTypes) > 0 {
+		p.txTypes = make(map[protocol.TxType]bool)
+		for _, txType := range st.Policy.TxTypes {
+			p.txTypes[protocol.TxType(txType)] = true
+		}
+	}
+	if len(st.Policy.Receivers) > 0 {
+		p.receivers = make(map[basics.Address]bool)
+		for _, receiver := range st.Policy.Receivers {
+			addr, err := basics.UnmarshalChecksumAddress(receiver)
+			if err != nil {
+				return nil, err
+			}
+			p.receivers[addr] = true
+		}
+	}
+	return p, nil
+}
+
+// policyFromRequest returns the policy of the token that authenticated r
+func policyFromRequest(r *http.Request) *accessPolicy {
+	p, _ := r.Context().Value(policyContextKey{}).(*accessPolicy)
+	return p
+}
+
+// withPolicy attaches p to the context of r
+func withPolicy(r *http.Request, p *accessPolicy) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), policyContextKey{}, p))
+}
+
+// tokenName names the token that authenticated a request in the audit log
+func (p *accessPolicy) tokenName() string {
+	if p == nil {
+		return "kmd.token"
+	}
+	return p.name
+}
+
+// allows reports whether the policy permits a route of class perm
+func (p *accessPolicy) allows(perm permission) bool {
+	if p == nil {
+		return true
+	}
+	switch perm {
+	case permRead, permSign:
+		return true
+	case permManage:
+		// A token limited to some wallets can't create new ones
+		return !p.signOnly && p.wallets == nil
+	case permExport:
+		return !p.signOnly && p.allowExport
+	}
+	return false
+}
+
+// allowsWalletID reports whether the policy permits using the wallet id
+func (p *accessPolicy) allowsWalletID(id []byte) bool {
+	return p == nil || p.wallets == nil || p.wallets[string(id)]
+}
+
+// allowsWallet reports whether the policy permits using w
+func (p *accessPolicy) allowsWallet(w wallet.Wallet) bool {
+	if p == nil || p.wallets == nil {
+		return true
+	}
+	metadata, err := w.Metadata()
+	if err != nil {
+		return false
+	}
+	return p.allowsWalletID(metadata.ID)
+}
+
+// checkReceiver checks a destination of funds against the policy
+func (p *accessPolicy) checkReceiver(addr basics.Address) error {
+	if addr == (basics.Address{}) || p.receivers == nil || p.receivers[addr] {
+		return nil
+	}
+	return errPolicyReceiver
+}
+
+// checkTransaction checks a transaction that the holder of the token wants
+// signed against the policy
+func (p *accessPolicy) checkTransaction(tx transactions.Transaction) error {
+	if p == nil {
+		return nil
+	}
+
+	if p.txTypes != nil && !p.txTypes[tx.Type] {
+		return errPolicyTxType
+	}
+
+	// Rekeying would hand the account to a key outside of the policy
+	if tx.RekeyTo != (basics.Address{}) {
+		return errPolicyRekey
+	}
+
+	// The amount limits and receivers only make sense for transfers, so a
+	// token that has them must list any other transaction type it may sign
+	limited := p.maxAmount != 0 || p.maxAssetAmount != 0 || p.receivers != nil
+	if limited && p.txTypes == nil && tx.Type != protocol.PaymentTx && tx.Type != protocol.AssetTransferTx {
+		return errPolicyTxType
+	}
+
+	// The fee is paid out of the account too, so it counts toward the
+	// microAlgo limit
+	if p.maxAmount != 0 && tx.Fee.Raw > p.maxAmount {
+		return errPolicyAmount
+	}
+
+	switch tx.Type {
+	case protocol.PaymentTx:
+		if p.maxAmount != 0 {
+			if tx.Amount.Raw > p.maxAmount-tx.Fee.Raw {
+				return errPolicyAmount
+			}
+			if tx.CloseRemainderTo != (basics.Address{}) {
+				return errPolicyCloseOut
+			}
+		}
+		for _, addr := range []basics.Address{tx.Receiver, tx.CloseRemainderTo} {
+			err := p.checkReceiver(addr)
+			if err != nil {
+				return err
+			}
+		}
+	case protocol.AssetTransferTx:
+		if p.maxAssetAmount != 0 {
+			if tx.AssetAmount > p.maxAssetAmount {
+				return errPolicyAmount
+			}
+			if tx.AssetCloseTo != (basics.Address{}) {
+				return errPolicyCloseOut
+			}
+		}
+		for _, addr := range []basics.Address{tx.AssetReceiver, tx.AssetCloseTo} {
+			err := p.checkReceiver(addr)
+			if err != nil {
+				return err
+			}
+		}
+	case protocol.AssetConfigTx:
+		// The roles of an asset control its units, so they may only be
+		// handed to receivers
+		params := tx.AssetParams
+		for _, addr := range []basics.Address{params.Manager, params.Reserve, params.Freeze, params.Clawback} {
+			err := p.checkReceiver(addr)
+			if err != nil {
+				return err
+			}
+		}
+	case protocol.AssetFreezeTx:
+		err := p.checkReceiver(tx.FreezeAccount)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// auditSigning records a signing request, and whether it succeeded, in the
+// audit log
+func auditSigning(audit logging.Logger, r *http.Request, walletID []byte, tx *transactions.Transaction, signer crypto.Digest, err error) {
+	fields := logging.Fields{
+		"token":  policyFromRequest(r).tokenName(),
+		"path":   r.URL.Path,
+		"remote": r.RemoteAddr,
+		"wallet": string(walletID),
+	}
+	if tx != nil {
+		fields["txid"] = tx.ID().String()
+		fields["type"] = string(tx.Type)
+		fields["sender"] = tx.Sender.String()
+		switch tx.Type {
+		case protocol.PaymentTx:
+			fields["receiver"] = tx.Receiver.String()
+			fields["amount"] = tx.Amount.Raw
+		case protocol.AssetTransferTx:
+			fields["receiver"] = tx.AssetReceiver.String()
+			fields["asset"] = uint64(tx.XferAsset)
+			fields["amount"] = tx.AssetAmount
+		}
+	}
+	if signer != (crypto.Digest{}) {
+		fields["signer"] = basics.Address(signer).String()
+	}
+
+	if err != nil {
+		fields["result"] = "denied"
+		fields["error"] = err.Error()
+		audit.WithFields(fields).Warn("signing request")
+		return
+	}
+	fields["result"] = "signed"
+	audit.WithFields(fields).Info("signing request")
+}
+
+// walletIDForAudit returns the ID of w, or nil if it can't be read
+func walletIDForAudit(w wallet.Wallet) []byte {
+	metadata, err := w.Metadata()
+	if err != nil {
+		return nil
+	}
+	return metadata.ID
+}
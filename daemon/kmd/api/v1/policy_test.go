@@ -0,0 +1,157 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/go-algorand/daemon/kmd/config"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/protocol"
+)
+
+func TestAccessPolicyPermissions(t *testing.T) {
+	var admin *accessPolicy
+	for _, perm := range []permission{permRead, permSign, permManage, permExport} {
+		require.True(t, admin.allows(perm))
+	}
+	require.True(t, admin.allowsWalletID([]byte("any")))
+
+	signer, err := makeAccessPolicy(config.ScopedToken{Name: "signer", Policy: config.AccessPolicy{SignOnly: true, AllowExport: true}})
+	require.NoError(t, err)
+	require.True(t, signer.allows(permRead))
+	require.True(t, signer.allows(permSign))
+	require.False(t, signer.allows(permManage))
+	require.False(t, signer.allows(permExport))
+
+	scoped, err := makeAccessPolicy(config.ScopedToken{Name: "scoped", Policy: config.AccessPolicy{Wallets: []string{"w1"}}})
+	require.NoError(t, err)
+	require.False(t, scoped.allows(permManage))
+	require.False(t, scoped.allows(permExport))
+	require.True(t, scoped.allowsWalletID([]byte("w1")))
+	require.False(t, scoped.allowsWalletID([]byte("w2")))
+
+	exporter, err := makeAccessPolicy(config.ScopedToken{Name: "exporter", Policy: config.AccessPolicy{AllowExport: true}})
+	require.NoError(t, err)
+	require.True(t, exporter.allows(permManage))
+	require.True(t, exporter.allows(permExport))
+}
+
+func TestAccessPolicyCheckTransaction(t *testing.T) {
+	allowed := basics.Address{1}
+	other := basics.Address{2}
+
+	p, err := makeAccessPolicy(config.ScopedToken{
+		Name: "payments",
+		Policy: config.AccessPolicy{
+			TxTypes:   []string{string(protocol.PaymentTx)},
+			MaxAmount: 1000,
+			Receivers: []string{allowed.GetUserAddress()},
+		},
+	})
+	require.NoError(t, err)
+
+	pay := func(receiver basics.Address, amount uint64) transactions.Transaction {
+		return transactions.Transaction{
+			Type: protocol.PaymentTx,
+			Header: transactions.Header{
+				Fee: basics.MicroAlgos{Raw: 10},
+			},
+			PaymentTxnFields: transactions.PaymentTxnFields{
+				Receiver: receiver,
+				Amount:   basics.MicroAlgos{Raw: amount},
+			},
+		}
+	}
+
+	require.NoError(t, p.checkTransaction(pay(allowed, 990)))
+	require.Equal(t, errPolicyAmount, p.checkTransaction(pay(allowed, 991)))
+	require.Equal(t, errPolicyAmount, p.checkTransaction(pay(allowed, 1000)))
+
+	// The fee counts toward the limit
+	highFee := pay(allowed, 0)
+	highFee.Fee.Raw = 1001
+	require.Equal(t, errPolicyAmount, p.checkTransaction(highFee))
+	require.Equal(t, errPolicyReceiver, p.checkTransaction(pay(other, 1)))
+
+	closeOut := pay(allowed, 1)
+	closeOut.CloseRemainderTo = allowed
+	require.Equal(t, errPolicyCloseOut, p.checkTransaction(closeOut))
+
+	rekey := pay(allowed, 1)
+	rekey.RekeyTo = other
+	require.Equal(t, errPolicyRekey, p.checkTransaction(rekey))
+
+	keyreg := transactions.Transaction{Type: protocol.KeyRegistrationTx}
+	require.Equal(t, errPolicyTxType, p.checkTransaction(keyreg))
+
+	// A token with an amount limit but no types may only sign transfers,
+	// and the fee of any of them counts toward the limit
+	limited, err := makeAccessPolicy(config.ScopedToken{
+		Name: "limited",
+		Policy: config.AccessPolicy{
+			MaxAmount: 1000,
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, limited.checkTransaction(pay(other, 990)))
+	require.Equal(t, errPolicyTxType, limited.checkTransaction(keyreg))
+	acfg := transactions.Transaction{Type: protocol.AssetConfigTx}
+	require.Equal(t, errPolicyTxType, limited.checkTransaction(acfg))
+	axfer := transactions.Transaction{Type: protocol.AssetTransferTx}
+	axfer.Fee.Raw = 1001
+	require.Equal(t, errPolicyAmount, limited.checkTransaction(axfer))
+
+	// Likewise for a token with receivers but no types, and a token that
+	// may sign other types still can't hand asset roles to other addresses
+	// or freeze their assets
+	receivers, err := makeAccessPolicy(config.ScopedToken{
+		Name: "receivers",
+		Policy: config.AccessPolicy{
+			Receivers: []string{allowed.GetUserAddress()},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, receivers.checkTransaction(pay(allowed, 1)))
+	require.Equal(t, errPolicyTxType, receivers.checkTransaction(keyreg))
+	require.Equal(t, errPolicyTxType, receivers.checkTransaction(acfg))
+
+	assets, err := makeAccessPolicy(config.ScopedToken{
+		Name: "assets",
+		Policy: config.AccessPolicy{
+			TxTypes:   []string{string(protocol.AssetConfigTx), string(protocol.AssetFreezeTx)},
+			Receivers: []string{allowed.GetUserAddress()},
+		},
+	})
+	require.NoError(t, err)
+	acfg.AssetParams.Manager = allowed
+	require.NoError(t, assets.checkTransaction(acfg))
+	acfg.AssetParams.Clawback = other
+	require.Equal(t, errPolicyReceiver, assets.checkTransaction(acfg))
+	afrz := transactions.Transaction{Type: protocol.AssetFreezeTx}
+	afrz.FreezeAccount = allowed
+	require.NoError(t, assets.checkTransaction(afrz))
+	afrz.FreezeAccount = other
+	require.Equal(t, errPolicyReceiver, assets.checkTransaction(afrz))
+
+	// Without a policy, anything goes
+	var admin *accessPolicy
+	require.NoError(t, admin.checkTransaction(rekey))
+}
@@ -102,6 +102,12 @@ func getPathAndMethod(req kmdapi.APIV1Request) (reqPath string, reqMethod string
 	case kmdapi.APIV1POSTMasterKeyExportRequest:
 		reqPath = "v1/master-key/export"
 		reqMethod = "POST"
+	case kmdapi.APIV1POSTWalletBackupRequest:
+		reqPath = "v1/wallet/backup"
+		reqMethod = "POST"
+	case kmdapi.APIV1POSTWalletRestoreRequest:
+		reqPath = "v1/wallet/restore"
+		reqMethod = "POST"
 	case kmdapi.APIV1POSTKeyImportRequest:
 		reqPath = "v1/key/import"
 		reqMethod = "POST"
@@ -141,13 +141,14 @@ func (kcl KMDClient) DeleteMultisigAddr(walletHandle []byte, pw []byte, addr str
 }
 
 // MultisigSignTransaction wraps kmdapi.APIV1POSTMultisigTransactionSignRequest
-func (kcl KMDClient) MultisigSignTransaction(walletHandle, pw []byte, tx []byte, pk crypto.PublicKey, partial crypto.MultisigSig) (resp kmdapi.APIV1POSTMultisigTransactionSignResponse, err error) {
+func (kcl KMDClient) MultisigSignTransaction(walletHandle, pw []byte, tx []byte, pk crypto.PublicKey, partial crypto.MultisigSig, signer crypto.Digest) (resp kmdapi.APIV1POSTMultisigTransactionSignResponse, err error) {
 	req := kmdapi.APIV1POSTMultisigTransactionSignRequest{
 		WalletHandleToken: string(walletHandle),
 		WalletPassword:    string(pw),
 		Transaction:       tx,
 		PublicKey:         pk,
 		PartialMsig:       partial,
+		Signer:            signer,
 	}
 	err = kcl.DoV1Request(req, &resp)
 	return
@@ -182,13 +183,39 @@ func (kcl KMDClient) ExportMasterDerivationKey(walletHandle []byte, walletPasswo
 	return
 }
 
+// BackupWallet wraps kmdapi.APIV1POSTWalletBackupRequest
+func (kcl KMDClient) BackupWallet(walletHandle []byte, walletPassword []byte, backupPassword []byte) (resp kmdapi.APIV1POSTWalletBackupResponse, err error) {
+	req := kmdapi.APIV1POSTWalletBackupRequest{
+		WalletHandleToken: string(walletHandle),
+		WalletPassword:    string(walletPassword),
+		BackupPassword:    string(backupPassword),
+	}
+	err = kcl.DoV1Request(req, &resp)
+	return
+}
+
+// RestoreWallet wraps kmdapi.APIV1POSTWalletRestoreRequest
+func (kcl KMDClient) RestoreWallet(walletDriverName string, backup []byte, backupPassword []byte, walletName []byte, walletPassword []byte, renameOnConflict bool) (resp kmdapi.APIV1POSTWalletRestoreResponse, err error) {
+	req := kmdapi.APIV1POSTWalletRestoreRequest{
+		WalletDriverName: walletDriverName,
+		Backup:           backup,
+		BackupPassword:   string(backupPassword),
+		WalletName:       string(walletName),
+		WalletPassword:   string(walletPassword),
+		RenameOnConflict: renameOnConflict,
+	}
+	err = kcl.DoV1Request(req, &resp)
+	return
+}
+
 // SignTransaction wraps kmdapi.APIV1POSTTransactionSignRequest
-func (kcl KMDClient) SignTransaction(walletHandle, pw []byte, tx transactions.Transaction) (resp kmdapi.APIV1POSTTransactionSignResponse, err error) {
+func (kcl KMDClient) SignTransaction(walletHandle, pw []byte, pk crypto.PublicKey, tx transactions.Transaction) (resp kmdapi.APIV1POSTTransactionSignResponse, err error) {
 	txBytes := protocol.Encode(tx)
 	req := kmdapi.APIV1POSTTransactionSignRequest{
 		WalletHandleToken: string(walletHandle),
 		WalletPassword:    string(pw),
 		Transaction:       txBytes,
+		PublicKey:         pk,
 	}
 	err = kcl.DoV1Request(req, &resp)
 	return
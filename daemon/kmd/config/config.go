@@ -18,8 +18,12 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"path/filepath"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/util/tokens"
 )
 
 const (
@@ -28,20 +32,57 @@ const (
 	defaultScryptN             = 65536
 	defaultScryptR             = 1
 	defaultScryptP             = 32
+	defaultAuditLogFilename    = "kmd-audit.log"
 )
 
 // KMDConfig contains global configuration information for kmd
 type KMDConfig struct {
-	DataDir             string       `json:"-"`
-	DriverConfig        DriverConfig `json:"drivers"`
-	SessionLifetimeSecs uint64       `json:"session_lifetime_secs"`
-	Address             string       `json:"address"`
-	AllowedOrigins      []string     `json:"allowed_origins"`
+	DataDir             string        `json:"-"`
+	DriverConfig        DriverConfig  `json:"drivers"`
+	SessionLifetimeSecs uint64        `json:"session_lifetime_secs"`
+	Address             string        `json:"address"`
+	AllowedOrigins      []string      `json:"allowed_origins"`
+	ScopedTokens        []ScopedToken `json:"scoped_tokens"`
+	AuditLogFile        string        `json:"audit_log_file"`
+}
+
+// ScopedToken is an API token, in addition to the one in kmd.token, whose
+// holder may only do what its Policy allows
+type ScopedToken struct {
+	Name   string       `json:"name"`
+	Token  string       `json:"token"`
+	Policy AccessPolicy `json:"policy"`
+}
+
+// AccessPolicy restricts what a ScopedToken may do. An empty list or a zero
+// limit places no restriction. Scoped tokens can never export keys unless
+// AllowExport is set.
+type AccessPolicy struct {
+	// SignOnly limits the token to opening wallets, listing their keys and
+	// signing transactions
+	SignOnly    bool `json:"sign_only"`
+	AllowExport bool `json:"allow_export"`
+	// Wallets lists the IDs of the wallets the token may use
+	Wallets []string `json:"wallets"`
+	// TxTypes lists the transaction types the token may sign
+	TxTypes []string `json:"tx_types"`
+	// MaxAmount limits the microAlgos spent by a signed transaction,
+	// counting its fee, and MaxAssetAmount the units sent by a signed
+	// asset transfer. Closing out an account is not allowed when the
+	// matching limit is set. A token with either limit may only sign
+	// payments and asset transfers, unless TxTypes says otherwise.
+	MaxAmount      uint64 `json:"max_amount"`
+	MaxAssetAmount uint64 `json:"max_asset_amount"`
+	// Receivers lists the addresses that signed transactions may send to,
+	// hand asset roles to, or freeze. A token with Receivers may only sign
+	// payments and asset transfers, unless TxTypes says otherwise.
+	Receivers []string `json:"receivers"`
 }
 
 // DriverConfig contains config info specific to each wallet driver
 type DriverConfig struct {
 	SQLiteWalletDriverConfig SQLiteWalletDriverConfig `json:"sqlite"`
+	PKCS11WalletDriverConfig PKCS11WalletDriverConfig `json:"pkcs11"`
 }
 
 // SQLiteWalletDriverConfig is configuration specific to the SQLiteWalletDriver
@@ -51,6 +92,15 @@ type SQLiteWalletDriverConfig struct {
 	ScryptParams ScryptParams `json:"scrypt"`
 }
 
+// PKCS11WalletDriverConfig is configuration specific to the
+// PKCS11WalletDriver. The driver is disabled unless ModulePath names a
+// PKCS#11 module (e.g. /usr/lib/softhsm/libsofthsm2.so). If TokenLabels is
+// non-empty, only tokens with one of those labels are exposed as wallets.
+type PKCS11WalletDriverConfig struct {
+	ModulePath  string   `json:"module_path"`
+	TokenLabels []string `json:"token_labels"`
+}
+
 // ScryptParams stores the parameters used for key derivation. This allows
 // upgrading security parameters over time
 type ScryptParams struct {
@@ -86,9 +136,49 @@ func (k KMDConfig) Validate() error {
 			return ErrSQLiteWalletNotAbsolute
 		}
 	}
+	// If a PKCS#11 module is passed, ensure that it is absolute, so that we
+	// never search the library path for a module to load
+	modulePath := k.DriverConfig.PKCS11WalletDriverConfig.ModulePath
+	if modulePath != "" {
+		if !filepath.IsAbs(modulePath) {
+			return ErrPKCS11ModuleNotAbsolute
+		}
+	}
+	// Scoped tokens must be well formed and distinct, and their receivers
+	// must be valid addresses
+	names := make(map[string]bool)
+	toks := make(map[string]bool)
+	for _, st := range k.ScopedTokens {
+		if st.Name == "" || names[st.Name] {
+			return fmt.Errorf("scoped token names must be unique and non-empty: %q", st.Name)
+		}
+		names[st.Name] = true
+		err := tokens.ValidateAPIToken(st.Token)
+		if err != nil {
+			return fmt.Errorf("scoped token %s: %v", st.Name, err)
+		}
+		if toks[st.Token] {
+			return fmt.Errorf("scoped token %s: token is used by another scoped token", st.Name)
+		}
+		toks[st.Token] = true
+		for _, receiver := range st.Policy.Receivers {
+			_, err = basics.UnmarshalChecksumAddress(receiver)
+			if err != nil {
+				return fmt.Errorf("scoped token %s: bad receiver %s: %v", st.Name, receiver, err)
+			}
+		}
+	}
 	return nil
 }
 
+// AuditLogPath returns the file that kmd appends its audit trail to
+func (k KMDConfig) AuditLogPath() string {
+	if k.AuditLogFile != "" {
+		return k.AuditLogFile
+	}
+	return filepath.Join(k.DataDir, defaultAuditLogFilename)
+}
+
 // LoadKMDConfig tries to read the the kmd configuration from disk, merging the
 // default kmd configuration with what it finds
 func LoadKMDConfig(dataDir string) (cfg KMDConfig, err error) {
@@ -22,3 +22,6 @@ import (
 
 // ErrSQLiteWalletNotAbsolute is returned when the passed sqlite wallet directory is relative
 var ErrSQLiteWalletNotAbsolute = fmt.Errorf("sqlite wallets path must be absolute path")
+
+// ErrPKCS11ModuleNotAbsolute is returned when the PKCS#11 module path is relative
+var ErrPKCS11ModuleNotAbsolute = fmt.Errorf("pkcs11 module path must be absolute path")
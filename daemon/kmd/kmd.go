@@ -63,14 +63,22 @@ func Start(startConfig StartConfig) (died chan error, sock string, err error) {
 		return
 	}
 
+	// Open the audit log, where signing requests are recorded
+	auditLog, err := openAuditLog(kmdCfg.AuditLogPath())
+	if err != nil {
+		return
+	}
+
 	// Configure the wallet API server
 	serverCfg := server.WalletServerConfig{
 		APIToken:       apiToken,
+		ScopedTokens:   kmdCfg.ScopedTokens,
 		DataDir:        startConfig.DataDir,
 		Address:        kmdCfg.Address,
 		AllowedOrigins: kmdCfg.AllowedOrigins,
 		SessionManager: session.MakeManager(kmdCfg),
 		Log:            startConfig.Log,
+		AuditLog:       auditLog,
 		Timeout:        startConfig.Timeout,
 	}
 
@@ -84,3 +92,17 @@ func Start(startConfig StartConfig) (died chan error, sock string, err error) {
 	died, sock, err = ws.Start(startConfig.Kill)
 	return
 }
+
+// openAuditLog returns a logger that appends JSON records to path
+func openAuditLog(path string) (logging.Logger, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	audit := logging.NewLogger()
+	audit.SetOutput(f)
+	audit.SetJSONFormatter()
+	audit.SetLevel(logging.Info)
+	return audit, nil
+}
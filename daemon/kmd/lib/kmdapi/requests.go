@@ -105,6 +105,29 @@ type APIV1POSTMasterKeyExportRequest struct {
 	WalletPassword    string `json:"wallet_password"`
 }
 
+// APIV1POSTWalletBackupRequest is the request for `POST /v1/wallet/backup`
+//
+// swagger:model BackupWalletRequest
+type APIV1POSTWalletBackupRequest struct {
+	APIV1RequestEnvelope
+	WalletHandleToken string `json:"wallet_handle_token"`
+	WalletPassword    string `json:"wallet_password"`
+	BackupPassword    string `json:"backup_password"`
+}
+
+// APIV1POSTWalletRestoreRequest is the request for `POST /v1/wallet/restore`
+//
+// swagger:model RestoreWalletRequest
+type APIV1POSTWalletRestoreRequest struct {
+	APIV1RequestEnvelope
+	WalletDriverName string `json:"wallet_driver_name"`
+	Backup           Bytes  `json:"backup"`
+	BackupPassword   string `json:"backup_password"`
+	WalletName       string `json:"wallet_name"`
+	WalletPassword   string `json:"wallet_password"`
+	RenameOnConflict bool   `json:"rename_on_conflict"`
+}
+
 // APIV1POSTKeyImportRequest is the request for `POST /v1/key/import`
 //
 // swagger:model ImportKeyRequest
@@ -159,6 +182,10 @@ type APIV1POSTTransactionSignRequest struct {
 	WalletHandleToken string `json:"wallet_handle_token"`
 	Transaction       Bytes  `json:"transaction"`
 	WalletPassword    string `json:"wallet_password"`
+
+	// PublicKey is the key to sign with, if the sender has been rekeyed.
+	// If empty, the key of the transaction's sender is used.
+	PublicKey crypto.PublicKey `json:"public_key"`
 }
 
 // APIV1POSTMultisigListRequest is the request for `POST /v1/multisig/list`
@@ -209,4 +236,8 @@ type APIV1POSTMultisigTransactionSignRequest struct {
 	PublicKey         crypto.PublicKey   `json:"public_key"`
 	PartialMsig       crypto.MultisigSig `json:"partial_multisig"`
 	WalletPassword    string             `json:"wallet_password"`
+
+	// Signer is the multisig address to sign for, if the sender has been
+	// rekeyed to it.  If empty, the transaction's sender is used.
+	Signer crypto.Digest `json:"signer"`
 }
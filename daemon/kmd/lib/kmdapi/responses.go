@@ -109,6 +109,20 @@ type APIV1POSTMasterKeyExportResponse struct {
 	MasterDerivationKey APIV1MasterDerivationKey `json:"master_derivation_key"`
 }
 
+// APIV1POSTWalletBackupResponse is the response to `POST /v1/wallet/backup`
+// friendly:BackupWalletResponse
+type APIV1POSTWalletBackupResponse struct {
+	APIV1ResponseEnvelope
+	Backup Bytes `json:"backup"`
+}
+
+// APIV1POSTWalletRestoreResponse is the response to `POST /v1/wallet/restore`
+// friendly:RestoreWalletResponse
+type APIV1POSTWalletRestoreResponse struct {
+	APIV1ResponseEnvelope
+	Wallet APIV1Wallet `json:"wallet"`
+}
+
 // APIV1POSTKeyImportResponse is the repsonse to `POST /v1/key/import`
 // friendly:ImportKeyResponse
 type APIV1POSTKeyImportResponse struct {
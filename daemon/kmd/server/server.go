@@ -31,6 +31,7 @@ import (
 	"github.com/gofrs/flock"
 
 	"github.com/algorand/go-algorand/daemon/kmd/api"
+	"github.com/algorand/go-algorand/daemon/kmd/config"
 	"github.com/algorand/go-algorand/daemon/kmd/session"
 	"github.com/algorand/go-algorand/logging"
 	"github.com/algorand/go-algorand/util/tokens"
@@ -52,11 +53,13 @@ const (
 // WalletServerConfig is the configuration passed to MakeWalletServer
 type WalletServerConfig struct {
 	APIToken       string
+	ScopedTokens   []config.ScopedToken
 	DataDir        string
 	Address        string
 	AllowedOrigins []string
 	SessionManager *session.Manager
 	Log            logging.Logger
+	AuditLog       logging.Logger
 	Timeout        *time.Duration
 }
 
@@ -214,7 +217,7 @@ func (ws *WalletServer) start(kill chan os.Signal) (died chan error, sock string
 	// Initialize HTTP server
 	watchdogCB := ws.makeWatchdogCallback(kill)
 	srv := http.Server{
-		Handler: api.Handler(ws.SessionManager, ws.Log, ws.AllowedOrigins, ws.APIToken, watchdogCB),
+		Handler: api.Handler(ws.SessionManager, ws.Log, ws.AllowedOrigins, ws.APIToken, ws.ScopedTokens, ws.AuditLog, watchdogCB),
 	}
 
 	// Read the kill channel and shut down the server gracefully
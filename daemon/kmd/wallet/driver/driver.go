@@ -27,6 +27,7 @@ import (
 var walletDrivers = map[string]Driver{
 	sqliteWalletDriverName: &SQLiteWalletDriver{},
 	ledgerWalletDriverName: &LedgerWalletDriver{},
+	pkcs11WalletDriverName: &PKCS11WalletDriver{},
 }
 
 // Driver is the interface that all wallet drivers must expose in order to be
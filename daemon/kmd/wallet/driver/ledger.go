@@ -25,6 +25,7 @@ import (
 	"github.com/algorand/go-algorand/crypto"
 	"github.com/algorand/go-algorand/daemon/kmd/config"
 	"github.com/algorand/go-algorand/daemon/kmd/wallet"
+	"github.com/algorand/go-algorand/data/basics"
 	"github.com/algorand/go-algorand/data/transactions"
 	"github.com/algorand/go-algorand/protocol"
 )
@@ -195,20 +196,24 @@ func (lw *LedgerWallet) DeleteMultisigAddr(addr crypto.Digest, pw []byte) error
 }
 
 // SignTransaction implements the Wallet interface.
-func (lw *LedgerWallet) SignTransaction(tx transactions.Transaction, pw []byte) ([]byte, error) {
+func (lw *LedgerWallet) SignTransaction(tx transactions.Transaction, pk crypto.PublicKey, pw []byte) ([]byte, error) {
 	sig, err := lw.signTransactionHelper(tx)
 	if err != nil {
 		return nil, err
 	}
 
-	return protocol.Encode(transactions.SignedTxn{
+	stx := transactions.SignedTxn{
 		Txn: tx,
 		Sig: sig,
-	}), nil
+	}
+	if pk != (crypto.PublicKey{}) && basics.Address(pk) != tx.Sender {
+		stx.AuthAddr = basics.Address(pk)
+	}
+	return protocol.Encode(stx), nil
 }
 
 // MultisigSignTransaction implements the Wallet interface.
-func (lw *LedgerWallet) MultisigSignTransaction(tx transactions.Transaction, pk crypto.PublicKey, partial crypto.MultisigSig, pw []byte) (crypto.MultisigSig, error) {
+func (lw *LedgerWallet) MultisigSignTransaction(tx transactions.Transaction, pk crypto.PublicKey, partial crypto.MultisigSig, pw []byte, signer crypto.Digest) (crypto.MultisigSig, error) {
 	return crypto.MultisigSig{}, errNotSupported
 }
 
@@ -240,6 +245,16 @@ func (lw *LedgerWallet) signTransactionHelper(tx transactions.Transaction) (sig
 		return
 	}
 
+	if tx.Lease != ([32]byte{}) {
+		err = fmt.Errorf("transaction leases not supported")
+		return
+	}
+
+	if tx.RekeyTo != (basics.Address{}) {
+		err = fmt.Errorf("rekeying not supported")
+		return
+	}
+
 	msg = append(msg, tx.Sender[:]...)
 	msg = append(msg, uint64le(tx.Fee.Raw)...)
 	msg = append(msg, uint64le(uint64(tx.FirstValid))...)
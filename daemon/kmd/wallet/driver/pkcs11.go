@@ -0,0 +1,459 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package driver
+
+import (
+	"fmt"
+
+	"github.com/algorand/go-deadlock"
+
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/daemon/kmd/config"
+	"github.com/algorand/go-algorand/daemon/kmd/wallet"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/protocol"
+)
+
+const (
+	pkcs11WalletDriverName    = "pkcs11"
+	pkcs11WalletDriverVersion = 1
+)
+
+// PKCS#11 object classes and attributes used to find keys on a token
+const (
+	ckoPublicKey  = 0x00000002
+	ckoPrivateKey = 0x00000003
+	ckaClass      = 0x00000000
+	ckaKeyType    = 0x00000100
+	ckaID         = 0x00000102
+	ckaECPoint    = 0x00000181
+)
+
+var pkcs11WalletSupportedTxs = []protocol.TxType{protocol.PaymentTx, protocol.KeyRegistrationTx, protocol.AssetConfigTx, protocol.AssetTransferTx, protocol.AssetFreezeTx}
+
+var errPKCS11KeyNotFound = fmt.Errorf("no ed25519 key on the token matches the requested public key")
+var errPKCS11BadSignature = fmt.Errorf("token returned a signature that does not verify")
+var errPKCS11NeedPreimage = fmt.Errorf("pkcs11 wallets cannot store multisig preimages, pass a partial multisig to sign")
+
+// PKCS11WalletDriver exposes each token of a PKCS#11 module (an HSM, or
+// SoftHSM for testing) as a wallet.  Ed25519 keys stay on the token: the
+// driver lists their public keys and asks the token to sign with them.
+// The wallet password is the token's user PIN.
+type PKCS11WalletDriver struct {
+	module  *pkcs11Module
+	wallets map[string]*PKCS11Wallet
+}
+
+// PKCS11Wallet represents one token under the PKCS11WalletDriver.  The lock
+// serializes use of the token, since login state is shared by all sessions.
+type PKCS11Wallet struct {
+	mu     deadlock.Mutex
+	module *pkcs11Module
+	slot   uint
+	info   pkcs11TokenInfo
+}
+
+// CreateWallet implements the Driver interface.  Tokens are initialized
+// with the vendor's tools, not through kmd.
+func (pwd *PKCS11WalletDriver) CreateWallet(name []byte, id []byte, pw []byte, mdk crypto.MasterDerivationKey) error {
+	return errNotSupported
+}
+
+// FetchWallet looks up a wallet by ID and returns it
+func (pwd *PKCS11WalletDriver) FetchWallet(id []byte) (w wallet.Wallet, err error) {
+	pw, ok := pwd.wallets[string(id)]
+	if !ok {
+		return nil, errWalletNotFound
+	}
+
+	return pw, nil
+}
+
+// InitWithConfig loads the configured PKCS#11 module, if any, and
+// enumerates the tokens it can see
+func (pwd *PKCS11WalletDriver) InitWithConfig(cfg config.KMDConfig) error {
+	pwd.wallets = make(map[string]*PKCS11Wallet)
+
+	pcfg := cfg.DriverConfig.PKCS11WalletDriverConfig
+	if pcfg.ModulePath == "" {
+		return nil
+	}
+
+	// Modules can only be initialized once per process
+	if pwd.module == nil || pwd.module.path != pcfg.ModulePath {
+		module, err := loadPKCS11Module(pcfg.ModulePath)
+		if err != nil {
+			return err
+		}
+		pwd.module = module
+	}
+
+	slots, err := pwd.module.slots()
+	if err != nil {
+		return err
+	}
+
+	for _, slot := range slots {
+		info, err := pwd.module.tokenInfo(slot)
+		if err != nil {
+			return err
+		}
+
+		if len(pcfg.TokenLabels) > 0 && !containsString(pcfg.TokenLabels, info.Label) {
+			continue
+		}
+
+		pw := &PKCS11Wallet{
+			module: pwd.module,
+			slot:   slot,
+			info:   info,
+		}
+		pwd.wallets[pw.id()] = pw
+	}
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ListWalletMetadatas returns all wallets supported by this driver
+func (pwd *PKCS11WalletDriver) ListWalletMetadatas() (metadatas []wallet.Metadata, err error) {
+	for _, w := range pwd.wallets {
+		md, err := w.Metadata()
+		if err != nil {
+			return nil, err
+		}
+
+		metadatas = append(metadatas, md)
+	}
+
+	return metadatas, nil
+}
+
+// RenameWallet implements the Driver interface.  Token labels are set when
+// the token is initialized.
+func (pwd *PKCS11WalletDriver) RenameWallet(newName []byte, id []byte, pw []byte) error {
+	return errNotSupported
+}
+
+// id returns the wallet ID of the token, which is stable across slot
+// renumbering as long as the token's serial number is unique
+func (pw *PKCS11Wallet) id() string {
+	return fmt.Sprintf("pkcs11-%s-%s", pw.info.Manufacturer, pw.info.Serial)
+}
+
+// withSession opens a session with the token, logs in with pin if it is
+// not nil, and runs fn
+func (pw *PKCS11Wallet) withSession(pin []byte, fn func(session uint) error) error {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+
+	session, err := pw.module.openSession(pw.slot)
+	if err != nil {
+		return err
+	}
+	defer pw.module.closeSession(session)
+
+	if pin != nil {
+		err = pw.module.login(session, pin)
+		if err != nil {
+			return err
+		}
+		defer pw.module.logout(session)
+	}
+
+	return fn(session)
+}
+
+// Init checks the PIN against the token
+func (pw *PKCS11Wallet) Init(pin []byte) error {
+	return pw.CheckPassword(pin)
+}
+
+// CheckPassword checks the PIN by logging in to the token
+func (pw *PKCS11Wallet) CheckPassword(pin []byte) error {
+	if pin == nil {
+		pin = []byte{}
+	}
+	return pw.withSession(pin, func(session uint) error {
+		return nil
+	})
+}
+
+// ExportMasterDerivationKey implements the Wallet interface.
+func (pw *PKCS11Wallet) ExportMasterDerivationKey(pin []byte) (crypto.MasterDerivationKey, error) {
+	return crypto.MasterDerivationKey{}, errNotSupported
+}
+
+// Metadata implements the Wallet interface.
+func (pw *PKCS11Wallet) Metadata() (wallet.Metadata, error) {
+	return wallet.Metadata{
+		ID:                    []byte(pw.id()),
+		Name:                  []byte(fmt.Sprintf("%s (%s %s, serial %s)", pw.info.Label, pw.info.Manufacturer, pw.info.Model, pw.info.Serial)),
+		DriverName:            pkcs11WalletDriverName,
+		DriverVersion:         pkcs11WalletDriverVersion,
+		SupportedTransactions: pkcs11WalletSupportedTxs,
+	}, nil
+}
+
+// decodeEdwardsPoint extracts an Ed25519 public key from a CKA_EC_POINT
+// value.  PKCS#11 v3.0 specifies the raw 32 byte encoding, but many modules
+// (SoftHSM among them) wrap it in a DER OCTET STRING.
+func decodeEdwardsPoint(point []byte) (pk crypto.PublicKey, ok bool) {
+	switch {
+	case len(point) == len(pk):
+	case len(point) == len(pk)+2 && point[0] == 0x04 && int(point[1]) == len(pk):
+		point = point[2:]
+	default:
+		return
+	}
+	copy(pk[:], point)
+	return pk, true
+}
+
+// publicKeys returns the Ed25519 public keys on the token, along with their
+// CKA_ID values, which link them to their private keys
+func (pw *PKCS11Wallet) publicKeys(session uint) (pks []crypto.PublicKey, ids [][]byte, err error) {
+	objs, err := pw.module.findObjects(session, []pkcs11Attribute{
+		pkcs11ULongAttribute(ckaClass, ckoPublicKey),
+		pkcs11ULongAttribute(ckaKeyType, ckkECEdwards),
+	})
+	if err != nil {
+		return
+	}
+
+	for _, obj := range objs {
+		point, err := pw.module.getAttribute(session, obj, ckaECPoint)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// Skip Ed448 and malformed keys
+		pk, ok := decodeEdwardsPoint(point)
+		if !ok {
+			continue
+		}
+
+		id, err := pw.module.getAttribute(session, obj, ckaID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		pks = append(pks, pk)
+		ids = append(ids, id)
+	}
+	return
+}
+
+// ListKeys lists the Ed25519 public keys on the token, which are readable
+// without logging in
+func (pw *PKCS11Wallet) ListKeys() (keys []crypto.Digest, err error) {
+	err = pw.withSession(nil, func(session uint) error {
+		pks, _, err := pw.publicKeys(session)
+		for _, pk := range pks {
+			keys = append(keys, publicKeyToAddress(pk))
+		}
+		return err
+	})
+	return
+}
+
+// ImportKey implements the Wallet interface.
+func (pw *PKCS11Wallet) ImportKey(sk crypto.PrivateKey) (crypto.Digest, error) {
+	return crypto.Digest{}, errNotSupported
+}
+
+// ExportKey implements the Wallet interface.
+func (pw *PKCS11Wallet) ExportKey(pk crypto.Digest, pin []byte) (crypto.PrivateKey, error) {
+	return crypto.PrivateKey{}, errNotSupported
+}
+
+// GenerateKey implements the Wallet interface.
+func (pw *PKCS11Wallet) GenerateKey(displayMnemonic bool) (crypto.Digest, error) {
+	return crypto.Digest{}, errNotSupported
+}
+
+// DeleteKey implements the Wallet interface.
+func (pw *PKCS11Wallet) DeleteKey(pk crypto.Digest, pin []byte) error {
+	return errNotSupported
+}
+
+// ImportMultisigAddr implements the Wallet interface.
+func (pw *PKCS11Wallet) ImportMultisigAddr(version, threshold uint8, pks []crypto.PublicKey) (crypto.Digest, error) {
+	return crypto.Digest{}, errNotSupported
+}
+
+// LookupMultisigPreimage implements the Wallet interface.
+func (pw *PKCS11Wallet) LookupMultisigPreimage(crypto.Digest) (version, threshold uint8, pks []crypto.PublicKey, err error) {
+	return 0, 0, nil, errNotSupported
+}
+
+// ListMultisigAddrs implements the Wallet interface.
+func (pw *PKCS11Wallet) ListMultisigAddrs() (addrs []crypto.Digest, err error) {
+	return nil, nil
+}
+
+// DeleteMultisigAddr implements the Wallet interface.
+func (pw *PKCS11Wallet) DeleteMultisigAddr(addr crypto.Digest, pin []byte) error {
+	return errNotSupported
+}
+
+// sign asks the token to sign msg with the private key matching pk, and
+// checks the result before returning it
+func (pw *PKCS11Wallet) sign(msg crypto.Hashable, pk crypto.PublicKey, pin []byte) (sig crypto.Signature, err error) {
+	hashid, data := msg.ToBeHashed()
+	tbs := append([]byte(hashid), data...)
+
+	err = pw.withSession(pin, func(session uint) error {
+		pks, ids, err := pw.publicKeys(session)
+		if err != nil {
+			return err
+		}
+
+		var id []byte
+		found := false
+		for i := range pks {
+			if pks[i] == pk {
+				id = ids[i]
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errPKCS11KeyNotFound
+		}
+
+		keys, err := pw.module.findObjects(session, []pkcs11Attribute{
+			pkcs11ULongAttribute(ckaClass, ckoPrivateKey),
+			pkcs11ULongAttribute(ckaKeyType, ckkECEdwards),
+			{typ: ckaID, value: id},
+		})
+		if err != nil {
+			return err
+		}
+		if len(keys) != 1 {
+			return errPKCS11KeyNotFound
+		}
+
+		raw, err := pw.module.sign(session, keys[0], ckmEdDSA, tbs)
+		if err != nil {
+			return err
+		}
+		if len(raw) != len(sig) {
+			return errPKCS11BadSignature
+		}
+		copy(sig[:], raw)
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	// Don't hand out a signature from the wrong key, e.g. if two keys on the
+	// token share a CKA_ID
+	if !crypto.SignatureVerifier(pk).Verify(msg, sig) {
+		err = errPKCS11BadSignature
+	}
+	return
+}
+
+// SignTransaction signs the passed transaction on the token with the key
+// pk, or with the sender's key if pk is empty
+func (pw *PKCS11Wallet) SignTransaction(tx transactions.Transaction, pk crypto.PublicKey, pin []byte) ([]byte, error) {
+	if pk == (crypto.PublicKey{}) {
+		pk = crypto.PublicKey(tx.Sender)
+	}
+
+	sig, err := pw.sign(tx, pk, pin)
+	if err != nil {
+		return nil, err
+	}
+
+	stx := transactions.SignedTxn{
+		Txn: tx,
+		Sig: sig,
+	}
+	if basics.Address(pk) != tx.Sender {
+		stx.AuthAddr = basics.Address(pk)
+	}
+	return protocol.Encode(stx), nil
+}
+
+// MultisigSignTransaction adds a subsignature by pk, made on the token, to
+// the partial multisig of the address signer, or of the transaction's sender
+// if signer is empty.  Since the token does not hold multisig preimages, the
+// partial multisig must be passed in.
+func (pw *PKCS11Wallet) MultisigSignTransaction(tx transactions.Transaction, pk crypto.PublicKey, partial crypto.MultisigSig, pin []byte, signer crypto.Digest) (sig crypto.MultisigSig, err error) {
+	if signer == (crypto.Digest{}) {
+		signer = crypto.Digest(tx.Src())
+	}
+
+	if partial.Version == 0 && partial.Threshold == 0 && len(partial.Subsigs) == 0 {
+		err = errPKCS11NeedPreimage
+		return
+	}
+
+	// Check preimage matches the signer address
+	addr, err := crypto.MultisigAddrGenWithSubsigs(partial.Version, partial.Threshold, partial.Subsigs)
+	if err != nil {
+		return
+	}
+	if addr != signer {
+		err = errMsigWrongAddr
+		return
+	}
+
+	// Check that key is one of the ones in the preimage
+	idx := -1
+	for i, subsig := range partial.Subsigs {
+		if pk == subsig.Key {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		err = errMsigWrongKey
+		return
+	}
+
+	subsig, err := pw.sign(tx, pk, pin)
+	if err != nil {
+		return
+	}
+
+	// Build a multisig holding only our subsignature, and merge it into
+	// the partial
+	mine := crypto.MultisigSig{
+		Version:   partial.Version,
+		Threshold: partial.Threshold,
+		Subsigs:   make([]crypto.MultisigSubsig, len(partial.Subsigs)),
+	}
+	for i := range partial.Subsigs {
+		mine.Subsigs[i].Key = partial.Subsigs[i].Key
+	}
+	mine.Subsigs[idx].Sig = subsig
+
+	sig, err = crypto.MultisigMerge(partial, mine)
+	return
+}
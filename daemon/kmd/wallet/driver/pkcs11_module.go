@@ -0,0 +1,347 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package driver
+
+// #cgo CFLAGS: -Wall -std=c99
+// #cgo CFLAGS: -I${SRCDIR}/include
+// #cgo linux LDFLAGS: -ldl
+// #cgo freebsd LDFLAGS: -ldl
+// #include <dlfcn.h>
+// #include <stdlib.h>
+// #include <string.h>
+//
+// #define CK_PTR *
+// #define CK_DEFINE_FUNCTION(returnType, name) returnType name
+// #define CK_DECLARE_FUNCTION(returnType, name) returnType name
+// #define CK_DECLARE_FUNCTION_POINTER(returnType, name) returnType (* name)
+// #define CK_CALLBACK_FUNCTION(returnType, name) returnType (* name)
+// #ifndef NULL_PTR
+// #define NULL_PTR 0
+// #endif
+// #include "pkcs11.h"
+//
+// // The module's function list can only be called from C, so each entry
+// // point we use gets a small trampoline.
+//
+// static CK_RV p11_load(const char *path, void **handle, CK_FUNCTION_LIST_PTR *fl) {
+//   CK_C_GetFunctionList getFunctionList;
+//   *handle = dlopen(path, RTLD_NOW | RTLD_LOCAL);
+//   if (*handle == NULL) {
+//     return CKR_GENERAL_ERROR;
+//   }
+//   getFunctionList = (CK_C_GetFunctionList) dlsym(*handle, "C_GetFunctionList");
+//   if (getFunctionList == NULL) {
+//     dlclose(*handle);
+//     *handle = NULL;
+//     return CKR_GENERAL_ERROR;
+//   }
+//   return getFunctionList(fl);
+// }
+//
+// static CK_RV p11_initialize(CK_FUNCTION_LIST_PTR fl) {
+//   CK_C_INITIALIZE_ARGS args;
+//   memset(&args, 0, sizeof(args));
+//   args.flags = CKF_OS_LOCKING_OK;
+//   return fl->C_Initialize(&args);
+// }
+//
+// static CK_RV p11_get_slot_list(CK_FUNCTION_LIST_PTR fl, CK_SLOT_ID_PTR slots, CK_ULONG_PTR count) {
+//   return fl->C_GetSlotList(CK_TRUE, slots, count);
+// }
+//
+// static CK_RV p11_get_token_info(CK_FUNCTION_LIST_PTR fl, CK_SLOT_ID slot, CK_TOKEN_INFO_PTR info) {
+//   return fl->C_GetTokenInfo(slot, info);
+// }
+//
+// static CK_RV p11_open_session(CK_FUNCTION_LIST_PTR fl, CK_SLOT_ID slot, CK_SESSION_HANDLE_PTR session) {
+//   return fl->C_OpenSession(slot, CKF_SERIAL_SESSION, NULL, NULL, session);
+// }
+//
+// static CK_RV p11_close_session(CK_FUNCTION_LIST_PTR fl, CK_SESSION_HANDLE session) {
+//   return fl->C_CloseSession(session);
+// }
+//
+// static CK_RV p11_login(CK_FUNCTION_LIST_PTR fl, CK_SESSION_HANDLE session, CK_UTF8CHAR_PTR pin, CK_ULONG pinLen) {
+//   return fl->C_Login(session, CKU_USER, pin, pinLen);
+// }
+//
+// static CK_RV p11_logout(CK_FUNCTION_LIST_PTR fl, CK_SESSION_HANDLE session) {
+//   return fl->C_Logout(session);
+// }
+//
+// static CK_RV p11_find_objects(CK_FUNCTION_LIST_PTR fl, CK_SESSION_HANDLE session, CK_ATTRIBUTE_PTR tmpl, CK_ULONG tmplLen, CK_OBJECT_HANDLE_PTR objs, CK_ULONG maxObjs, CK_ULONG_PTR count) {
+//   CK_RV rv = fl->C_FindObjectsInit(session, tmpl, tmplLen);
+//   if (rv != CKR_OK) {
+//     return rv;
+//   }
+//   rv = fl->C_FindObjects(session, objs, maxObjs, count);
+//   fl->C_FindObjectsFinal(session);
+//   return rv;
+// }
+//
+// static CK_RV p11_get_attribute(CK_FUNCTION_LIST_PTR fl, CK_SESSION_HANDLE session, CK_OBJECT_HANDLE obj, CK_ATTRIBUTE_PTR attr) {
+//   return fl->C_GetAttributeValue(session, obj, attr, 1);
+// }
+//
+// static CK_RV p11_sign(CK_FUNCTION_LIST_PTR fl, CK_SESSION_HANDLE session, CK_OBJECT_HANDLE key, CK_MECHANISM_TYPE mech, CK_BYTE_PTR msg, CK_ULONG msgLen, CK_BYTE_PTR sig, CK_ULONG_PTR sigLen) {
+//   CK_MECHANISM mechanism = {mech, NULL, 0};
+//   CK_RV rv = fl->C_SignInit(session, &mechanism, key);
+//   if (rv != CKR_OK) {
+//     return rv;
+//   }
+//   return fl->C_Sign(session, msg, msgLen, sig, sigLen);
+// }
+import "C"
+
+import (
+	"bytes"
+	"fmt"
+	"unsafe"
+)
+
+// Values from PKCS#11 v3.0, which the bundled v2.40 headers predate
+const (
+	ckkECEdwards = 0x00000040
+	ckmEdDSA     = 0x00001057
+)
+
+const (
+	pkcs11MaxSlots     = 64
+	pkcs11MaxObjects   = 256
+	pkcs11MaxSignature = 512
+)
+
+// pkcs11Error wraps a CK_RV return value from a PKCS#11 module
+type pkcs11Error uint
+
+var pkcs11ErrorNames = map[pkcs11Error]string{
+	C.CKR_GENERAL_ERROR:            "general error",
+	C.CKR_ARGUMENTS_BAD:            "bad arguments",
+	C.CKR_DEVICE_ERROR:             "device error",
+	C.CKR_DEVICE_REMOVED:           "device removed",
+	C.CKR_KEY_TYPE_INCONSISTENT:    "key type inconsistent",
+	C.CKR_MECHANISM_INVALID:        "mechanism not supported by token",
+	C.CKR_PIN_INCORRECT:            "incorrect PIN",
+	C.CKR_PIN_LOCKED:               "PIN locked",
+	C.CKR_SESSION_HANDLE_INVALID:   "invalid session handle",
+	C.CKR_TOKEN_NOT_PRESENT:        "token not present",
+	C.CKR_USER_NOT_LOGGED_IN:       "user not logged in",
+	C.CKR_USER_PIN_NOT_INITIALIZED: "user PIN not initialized",
+	C.CKR_BUFFER_TOO_SMALL:         "buffer too small",
+}
+
+func (e pkcs11Error) Error() string {
+	name, ok := pkcs11ErrorNames[e]
+	if !ok {
+		name = "error"
+	}
+	return fmt.Sprintf("pkcs11: %s (0x%08x)", name, uint(e))
+}
+
+func pkcs11Check(rv C.CK_RV) error {
+	if rv == C.CKR_OK {
+		return nil
+	}
+	return pkcs11Error(rv)
+}
+
+// pkcs11Module is a PKCS#11 module loaded into the kmd process.  Modules are
+// initialized for OS locking, so a module may be used from several
+// goroutines as long as each session is only used by one at a time.
+type pkcs11Module struct {
+	path   string
+	handle unsafe.Pointer
+	fl     C.CK_FUNCTION_LIST_PTR
+}
+
+// pkcs11TokenInfo describes the token present in a slot
+type pkcs11TokenInfo struct {
+	Label        string
+	Manufacturer string
+	Model        string
+	Serial       string
+}
+
+// pkcs11Attribute is an attribute in a search template
+type pkcs11Attribute struct {
+	typ   uint
+	value []byte
+}
+
+func pkcs11ULongAttribute(typ uint, value uint) pkcs11Attribute {
+	buf := make([]byte, C.sizeof_CK_ULONG)
+	*(*C.CK_ULONG)(unsafe.Pointer(&buf[0])) = C.CK_ULONG(value)
+	return pkcs11Attribute{typ: typ, value: buf}
+}
+
+// loadPKCS11Module loads and initializes the module at path
+func loadPKCS11Module(path string) (*pkcs11Module, error) {
+	cpath := C.CString(path)
+	defer C.free(unsafe.Pointer(cpath))
+
+	m := &pkcs11Module{path: path}
+	rv := C.p11_load(cpath, &m.handle, &m.fl)
+	if m.handle == nil {
+		return nil, fmt.Errorf("could not load pkcs11 module %s", path)
+	}
+	if rv != C.CKR_OK || m.fl == nil {
+		C.dlclose(m.handle)
+		return nil, fmt.Errorf("could not get function list from pkcs11 module %s: %v", path, pkcs11Check(rv))
+	}
+
+	rv = C.p11_initialize(m.fl)
+	if rv != C.CKR_OK && rv != C.CKR_CRYPTOKI_ALREADY_INITIALIZED {
+		C.dlclose(m.handle)
+		return nil, fmt.Errorf("could not initialize pkcs11 module %s: %v", path, pkcs11Check(rv))
+	}
+	return m, nil
+}
+
+// slots returns the IDs of the slots with a token present
+func (m *pkcs11Module) slots() ([]uint, error) {
+	var ids [pkcs11MaxSlots]C.CK_SLOT_ID
+	count := C.CK_ULONG(len(ids))
+	err := pkcs11Check(C.p11_get_slot_list(m.fl, &ids[0], &count))
+	if err != nil {
+		return nil, err
+	}
+
+	slots := make([]uint, count)
+	for i := range slots {
+		slots[i] = uint(ids[i])
+	}
+	return slots, nil
+}
+
+// blankPadded converts a blank padded PKCS#11 string field to a string
+func blankPadded(field []byte) string {
+	return string(bytes.TrimRight(field, " \x00"))
+}
+
+// tokenInfo returns information about the token in slot
+func (m *pkcs11Module) tokenInfo(slot uint) (info pkcs11TokenInfo, err error) {
+	var ti C.CK_TOKEN_INFO
+	err = pkcs11Check(C.p11_get_token_info(m.fl, C.CK_SLOT_ID(slot), &ti))
+	if err != nil {
+		return
+	}
+
+	info.Label = blankPadded(C.GoBytes(unsafe.Pointer(&ti.label[0]), C.int(len(ti.label))))
+	info.Manufacturer = blankPadded(C.GoBytes(unsafe.Pointer(&ti.manufacturerID[0]), C.int(len(ti.manufacturerID))))
+	info.Model = blankPadded(C.GoBytes(unsafe.Pointer(&ti.model[0]), C.int(len(ti.model))))
+	info.Serial = blankPadded(C.GoBytes(unsafe.Pointer(&ti.serialNumber[0]), C.int(len(ti.serialNumber))))
+	return
+}
+
+// openSession opens a read-only session with the token in slot
+func (m *pkcs11Module) openSession(slot uint) (uint, error) {
+	var session C.CK_SESSION_HANDLE
+	err := pkcs11Check(C.p11_open_session(m.fl, C.CK_SLOT_ID(slot), &session))
+	return uint(session), err
+}
+
+func (m *pkcs11Module) closeSession(session uint) error {
+	return pkcs11Check(C.p11_close_session(m.fl, C.CK_SESSION_HANDLE(session)))
+}
+
+// login logs the user into session using pin.  Logins are shared by all of
+// the sessions with a token, so it is not an error to be logged in already.
+func (m *pkcs11Module) login(session uint, pin []byte) error {
+	cpin := C.CBytes(pin)
+	defer C.free(cpin)
+
+	rv := C.p11_login(m.fl, C.CK_SESSION_HANDLE(session), (*C.CK_UTF8CHAR)(cpin), C.CK_ULONG(len(pin)))
+	if rv == C.CKR_USER_ALREADY_LOGGED_IN {
+		return nil
+	}
+	return pkcs11Check(rv)
+}
+
+func (m *pkcs11Module) logout(session uint) error {
+	return pkcs11Check(C.p11_logout(m.fl, C.CK_SESSION_HANDLE(session)))
+}
+
+// findObjects returns the handles of the objects matching template
+func (m *pkcs11Module) findObjects(session uint, template []pkcs11Attribute) ([]uint, error) {
+	// The template is passed to the module, so it must live in C memory
+	var tmpl C.CK_ATTRIBUTE_PTR
+	if len(template) > 0 {
+		tmpl = (C.CK_ATTRIBUTE_PTR)(C.calloc(C.size_t(len(template)), C.sizeof_CK_ATTRIBUTE))
+		defer C.free(unsafe.Pointer(tmpl))
+
+		attrs := (*[1 << 16]C.CK_ATTRIBUTE)(unsafe.Pointer(tmpl))[:len(template):len(template)]
+		for i, a := range template {
+			value := C.CBytes(a.value)
+			defer C.free(value)
+			attrs[i]._type = C.CK_ATTRIBUTE_TYPE(a.typ)
+			attrs[i].pValue = C.CK_VOID_PTR(value)
+			attrs[i].ulValueLen = C.CK_ULONG(len(a.value))
+		}
+	}
+
+	var objs [pkcs11MaxObjects]C.CK_OBJECT_HANDLE
+	var count C.CK_ULONG
+	err := pkcs11Check(C.p11_find_objects(m.fl, C.CK_SESSION_HANDLE(session), tmpl, C.CK_ULONG(len(template)), &objs[0], C.CK_ULONG(len(objs)), &count))
+	if err != nil {
+		return nil, err
+	}
+
+	handles := make([]uint, count)
+	for i := range handles {
+		handles[i] = uint(objs[i])
+	}
+	return handles, nil
+}
+
+// getAttribute reads the value of attribute typ of object obj
+func (m *pkcs11Module) getAttribute(session uint, obj uint, typ uint) ([]byte, error) {
+	attr := (C.CK_ATTRIBUTE_PTR)(C.calloc(1, C.sizeof_CK_ATTRIBUTE))
+	defer C.free(unsafe.Pointer(attr))
+	attr._type = C.CK_ATTRIBUTE_TYPE(typ)
+
+	// Ask for the length first, then for the value
+	err := pkcs11Check(C.p11_get_attribute(m.fl, C.CK_SESSION_HANDLE(session), C.CK_OBJECT_HANDLE(obj), attr))
+	if err != nil {
+		return nil, err
+	}
+	if attr.ulValueLen == 0 {
+		return nil, nil
+	}
+
+	value := C.malloc(C.size_t(attr.ulValueLen))
+	defer C.free(value)
+	attr.pValue = C.CK_VOID_PTR(value)
+	err = pkcs11Check(C.p11_get_attribute(m.fl, C.CK_SESSION_HANDLE(session), C.CK_OBJECT_HANDLE(obj), attr))
+	if err != nil {
+		return nil, err
+	}
+	return C.GoBytes(value, C.int(attr.ulValueLen)), nil
+}
+
+// sign signs msg on the token with key, using mechanism mech
+func (m *pkcs11Module) sign(session uint, key uint, mech uint, msg []byte) ([]byte, error) {
+	cmsg := C.CBytes(msg)
+	defer C.free(cmsg)
+
+	var sig [pkcs11MaxSignature]C.CK_BYTE
+	sigLen := C.CK_ULONG(len(sig))
+	err := pkcs11Check(C.p11_sign(m.fl, C.CK_SESSION_HANDLE(session), C.CK_OBJECT_HANDLE(key), C.CK_MECHANISM_TYPE(mech), (C.CK_BYTE_PTR)(cmsg), C.CK_ULONG(len(msg)), &sig[0], &sigLen))
+	if err != nil {
+		return nil, err
+	}
+	return C.GoBytes(unsafe.Pointer(&sig[0]), C.int(sigLen)), nil
+}
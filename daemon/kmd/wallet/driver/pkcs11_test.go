@@ -0,0 +1,117 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package driver
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/daemon/kmd/config"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/protocol"
+)
+
+func TestDecodeEdwardsPoint(t *testing.T) {
+	var raw crypto.PublicKey
+	for i := range raw {
+		raw[i] = byte(i)
+	}
+
+	pk, ok := decodeEdwardsPoint(raw[:])
+	require.True(t, ok)
+	require.Equal(t, raw, pk)
+
+	pk, ok = decodeEdwardsPoint(append([]byte{0x04, 0x20}, raw[:]...))
+	require.True(t, ok)
+	require.Equal(t, raw, pk)
+
+	_, ok = decodeEdwardsPoint(append([]byte{0x03, 0x20}, raw[:]...))
+	require.False(t, ok)
+
+	// Ed448 points are 57 bytes
+	_, ok = decodeEdwardsPoint(make([]byte, 59))
+	require.False(t, ok)
+}
+
+// TestPKCS11Wallet runs against a real module, e.g. SoftHSM with a token
+// holding at least one Ed25519 key pair:
+//
+//	softhsm2-util --init-token --free --label algo --pin 1234 --so-pin 1234
+//	pkcs11-tool --module $MODULE --login --pin 1234 --keypairgen --key-type EC:edwards25519 --id 01
+//	PKCS11_TEST_MODULE=$MODULE PKCS11_TEST_PIN=1234 go test -run TestPKCS11Wallet
+func TestPKCS11Wallet(t *testing.T) {
+	modulePath := os.Getenv("PKCS11_TEST_MODULE")
+	if modulePath == "" {
+		t.Skip("PKCS11_TEST_MODULE not set")
+	}
+	pin := []byte(os.Getenv("PKCS11_TEST_PIN"))
+
+	var cfg config.KMDConfig
+	cfg.DriverConfig.PKCS11WalletDriverConfig.ModulePath = modulePath
+
+	var pwd PKCS11WalletDriver
+	require.NoError(t, pwd.InitWithConfig(cfg))
+	mds, err := pwd.ListWalletMetadatas()
+	require.NoError(t, err)
+	require.NotEmpty(t, mds)
+
+	w, err := pwd.FetchWallet(mds[0].ID)
+	require.NoError(t, err)
+	require.NoError(t, w.Init(pin))
+	require.Error(t, w.CheckPassword(append(pin, 'x')))
+
+	keys, err := w.ListKeys()
+	require.NoError(t, err)
+	require.NotEmpty(t, keys)
+	pk := crypto.PublicKey(keys[0])
+
+	tx := transactions.Transaction{
+		Type: protocol.PaymentTx,
+		Header: transactions.Header{
+			Sender:     basics.Address(pk),
+			Fee:        basics.MicroAlgos{Raw: 1000},
+			FirstValid: 1,
+			LastValid:  1000,
+		},
+		PaymentTxnFields: transactions.PaymentTxnFields{
+			Receiver: basics.Address(pk),
+		},
+	}
+
+	enc, err := w.SignTransaction(tx, crypto.PublicKey{}, pin)
+	require.NoError(t, err)
+	var stx transactions.SignedTxn
+	require.NoError(t, protocol.Decode(enc, &stx))
+	require.True(t, crypto.SignatureVerifier(pk).Verify(tx, stx.Sig))
+
+	// Sign one subsig of a 1-of-2 multisig with the token's key
+	other := crypto.GenerateSignatureSecrets(crypto.Seed{1})
+	pks := []crypto.PublicKey{pk, other.SignatureVerifier}
+	addr, err := crypto.MultisigAddrGen(1, 1, pks)
+	require.NoError(t, err)
+	tx.Sender = basics.Address(addr)
+
+	partial := crypto.MultisigSig{Version: 1, Threshold: 1, Subsigs: []crypto.MultisigSubsig{{Key: pks[0]}, {Key: pks[1]}}}
+	msig, err := w.MultisigSignTransaction(tx, pk, partial, pin, crypto.Digest{})
+	require.NoError(t, err)
+	require.True(t, crypto.SignatureVerifier(pk).Verify(tx, msig.Subsigs[0].Sig))
+	require.Equal(t, crypto.Signature{}, msig.Subsigs[1].Sig)
+}
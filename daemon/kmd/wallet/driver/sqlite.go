@@ -32,6 +32,7 @@ import (
 	"github.com/algorand/go-algorand/crypto"
 	"github.com/algorand/go-algorand/daemon/kmd/config"
 	"github.com/algorand/go-algorand/daemon/kmd/wallet"
+	"github.com/algorand/go-algorand/data/basics"
 	"github.com/algorand/go-algorand/data/transactions"
 	"github.com/algorand/go-algorand/protocol"
 	"github.com/algorand/go-codec/codec"
@@ -50,7 +51,7 @@ const (
 	sqliteWalletHasMasterKey    = true
 )
 
-var sqliteWalletSupportedTxs = []protocol.TxType{protocol.PaymentTx, protocol.KeyRegistrationTx}
+var sqliteWalletSupportedTxs = []protocol.TxType{protocol.PaymentTx, protocol.KeyRegistrationTx, protocol.AssetConfigTx, protocol.AssetTransferTx, protocol.AssetFreezeTx}
 var disallowedFilenameRegex = regexp.MustCompile("[^a-zA-Z0-9_-]*")
 var databaseFilenameRegex = regexp.MustCompile("^.*\\.db$")
 
@@ -364,7 +365,12 @@ func (swd *SQLiteWalletDriver) CreateWallet(name []byte, id []byte, pw []byte, m
 	// Grab our lock to avoid races with duplicate wallet names/ids
 	swd.mux.Lock()
 	defer swd.mux.Unlock()
+	return swd.createWalletLocked(name, id, pw, mdk)
+}
 
+// createWalletLocked is the guts of CreateWallet. Precondition: we must hold
+// swd.mux
+func (swd *SQLiteWalletDriver) createWalletLocked(name []byte, id []byte, pw []byte, mdk crypto.MasterDerivationKey) error {
 	if len(name) > sqliteMaxWalletNameLen {
 		return errNameTooLong
 	}
@@ -1085,17 +1091,24 @@ func (sw *SQLiteWallet) ListMultisigAddrs() (addrs []crypto.Digest, err error) {
 	return
 }
 
-// SignTransaction signs the passed transaction, inferring the required private
-// key from the transaction itself
-func (sw *SQLiteWallet) SignTransaction(tx transactions.Transaction, pw []byte) (stx []byte, err error) {
+// SignTransaction signs the passed transaction with the private key for pk,
+// or, if pk is empty, with the private key inferred from the transaction's
+// sender
+func (sw *SQLiteWallet) SignTransaction(tx transactions.Transaction, pk crypto.PublicKey, pw []byte) (stx []byte, err error) {
 	// Check the password
 	err = sw.CheckPassword(pw)
 	if err != nil {
 		return
 	}
 
+	// Default to the sender's key
+	signer := crypto.Digest(tx.Src())
+	if pk != (crypto.PublicKey{}) {
+		signer = publicKeyToAddress(pk)
+	}
+
 	// Fetch the required key
-	sk, err := sw.fetchSecretKey(crypto.Digest(tx.Src()))
+	sk, err := sw.fetchSecretKey(signer)
 	if err != nil {
 		return
 	}
@@ -1107,28 +1120,39 @@ func (sw *SQLiteWallet) SignTransaction(tx transactions.Transaction, pw []byte)
 		return
 	}
 
-	// Sign the transaction
-	stx = protocol.Encode(tx.Sign(secrets))
+	// Sign the transaction, noting the rekeyed authorizer if the
+	// signing key does not belong to the sender
+	signed := tx.Sign(secrets)
+	if signer != crypto.Digest(tx.Src()) {
+		signed.AuthAddr = basics.Address(signer)
+	}
+	stx = protocol.Encode(signed)
 	return
 }
 
 // MultisigSignTransaction starts a multisig signature or adds a signature to a
 // partially signed multisig transaction signature of the passed transaction
-// using the key
-func (sw *SQLiteWallet) MultisigSignTransaction(tx transactions.Transaction, pk crypto.PublicKey, partial crypto.MultisigSig, pw []byte) (sig crypto.MultisigSig, err error) {
+// using the key.  The multisig is for the address signer, or for the
+// transaction's sender if signer is empty.
+func (sw *SQLiteWallet) MultisigSignTransaction(tx transactions.Transaction, pk crypto.PublicKey, partial crypto.MultisigSig, pw []byte, signer crypto.Digest) (sig crypto.MultisigSig, err error) {
 	// Check the password
 	err = sw.CheckPassword(pw)
 	if err != nil {
 		return
 	}
 
+	// Default to the sender's multisig
+	if signer == (crypto.Digest{}) {
+		signer = crypto.Digest(tx.Src())
+	}
+
 	if partial.Version == 0 && partial.Threshold == 0 && len(partial.Subsigs) == 0 {
 		// We weren't given a partial multisig, so create a new one
 
 		// Look up the preimage in the database
 		var pks []crypto.PublicKey
 		var version, threshold uint8
-		version, threshold, pks, err = sw.LookupMultisigPreimage(crypto.Digest(tx.Src()))
+		version, threshold, pks, err = sw.LookupMultisigPreimage(signer)
 		if err != nil {
 			return
 		}
@@ -1149,20 +1173,19 @@ func (sw *SQLiteWallet) MultisigSignTransaction(tx transactions.Transaction, pk
 		}
 
 		// Sign the transaction
-		from := crypto.Digest(tx.Src())
-		sig, err = crypto.MultisigSign(tx, from, version, threshold, pks, *secrets)
+		sig, err = crypto.MultisigSign(tx, signer, version, threshold, pks, *secrets)
 		return
 	}
 
 	// We were given a partial multisig, so add to it
 
-	// Check preimage matches tx src address
+	// Check preimage matches the signer address
 	var addr crypto.Digest
 	addr, err = crypto.MultisigAddrGenWithSubsigs(partial.Version, partial.Threshold, partial.Subsigs)
 	if err != nil {
 		return
 	}
-	if addr != crypto.Digest(tx.Src()) {
+	if addr != signer {
 		err = errMsigWrongAddr
 		return
 	}
@@ -0,0 +1,435 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package driver
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"os"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/daemon/kmd/wallet"
+)
+
+const (
+	sqliteBackupMagic   = "algorand-kmd-sqlite-backup"
+	sqliteBackupVersion = 1
+)
+
+// sqliteBackupBundle is the archive produced by SQLiteWallet.Backup. The
+// header is in the clear so that we can recognize a backup without the
+// password; everything else lives in Blob, which is encrypted and
+// authenticated under a key derived from the backup password.
+type sqliteBackupBundle struct {
+	Magic   string `codec:"magic"`
+	Version uint32 `codec:"version"`
+	Blob    []byte `codec:"blob"`
+}
+
+// sqliteBackupContents is the plaintext inside a backup bundle
+type sqliteBackupContents struct {
+	// Version repeats the bundle version, so that the cleartext header
+	// can't be swapped out from under the encrypted contents
+	Version             uint32                     `codec:"version"`
+	WalletID            []byte                     `codec:"wallet_id"`
+	WalletName          []byte                     `codec:"wallet_name"`
+	MasterDerivationKey crypto.MasterDerivationKey `codec:"mdk"`
+	MaxKeyIdx           uint64                     `codec:"max_key_idx"`
+	Keys                []sqliteBackupKey          `codec:"keys"`
+	MultisigAddrs       []sqliteBackupMultisig     `codec:"msig_addrs"`
+}
+
+// sqliteBackupKey is a secret key from the keys table. Derived keys have
+// their index recorded, so that restoring them keeps GenerateKey in step
+// with the original wallet.
+type sqliteBackupKey struct {
+	SecretKey crypto.PrivateKey `codec:"sk"`
+	Derived   bool              `codec:"derived"`
+	KeyIdx    uint64            `codec:"key_idx"`
+}
+
+// sqliteBackupMultisig is a multisig preimage from the msig_addrs table
+type sqliteBackupMultisig struct {
+	Version   uint8              `codec:"version"`
+	Threshold uint8              `codec:"threshold"`
+	PKs       []crypto.PublicKey `codec:"pks"`
+}
+
+// BackupWallet is implemented by wallets that can export all of their
+// contents, not just their master derivation key
+type BackupWallet interface {
+	Backup(pw []byte, backupPw []byte) ([]byte, error)
+}
+
+// RestoreDriver is implemented by drivers that can create a wallet from a
+// backup made by one of their wallets
+type RestoreDriver interface {
+	RestoreWallet(backup []byte, backupPw []byte, name []byte, pw []byte, renameOnConflict bool) ([]byte, error)
+}
+
+// Backup exports the master derivation key, the derived key index, every
+// key and every multisig preimage in the wallet, as a single bundle
+// encrypted with backupPw
+func (sw *SQLiteWallet) Backup(pw []byte, backupPw []byte) (backup []byte, err error) {
+	// Check the password
+	err = sw.CheckPassword(pw)
+	if err != nil {
+		return
+	}
+
+	if len(backupPw) == 0 {
+		err = errBackupPasswordBlank
+		return
+	}
+
+	// Connect to the database
+	db, err := sqlx.Connect("sqlite3", dbConnectionURL(sw.dbPath))
+	if err != nil {
+		err = errDatabaseConnect
+		return
+	}
+	defer db.Close()
+
+	contents := sqliteBackupContents{
+		Version: sqliteBackupVersion,
+	}
+	copy(contents.MasterDerivationKey[:], sw.masterDerivationKey)
+
+	// Fetch the metadata and the encrypted highest index
+	var encryptedIdxBlob []byte
+	row := db.QueryRow("SELECT wallet_id, wallet_name, max_key_idx_encrypted FROM metadata LIMIT 1")
+	err = row.Scan(&contents.WalletID, &contents.WalletName, &encryptedIdxBlob)
+	if err != nil {
+		err = errDatabase
+		return
+	}
+
+	idxBlob, err := decryptBlobWithPassword(encryptedIdxBlob, PTMaxKeyIdx, sw.masterEncryptionKey)
+	if err != nil {
+		return
+	}
+	err = msgpackDecode(idxBlob, &contents.MaxKeyIdx)
+	if err != nil {
+		return
+	}
+
+	// Fetch every key. fetchSecretKey checks each one against its address.
+	rows, err := db.Query("SELECT address, key_idx FROM keys")
+	if err != nil {
+		err = errDatabase
+		return
+	}
+	type keyRow struct {
+		addr crypto.Digest
+		idx  sql.NullInt64
+	}
+	var keyRows []keyRow
+	for rows.Next() {
+		var addrBytes []byte
+		var kr keyRow
+		err = rows.Scan(&addrBytes, &kr.idx)
+		if err != nil {
+			rows.Close()
+			err = errDatabase
+			return
+		}
+		copy(kr.addr[:], addrBytes)
+		keyRows = append(keyRows, kr)
+	}
+	rows.Close()
+
+	for _, kr := range keyRows {
+		var sk crypto.PrivateKey
+		sk, err = sw.fetchSecretKey(kr.addr)
+		if err != nil {
+			return
+		}
+		contents.Keys = append(contents.Keys, sqliteBackupKey{
+			SecretKey: sk,
+			Derived:   kr.idx.Valid,
+			KeyIdx:    uint64(kr.idx.Int64),
+		})
+	}
+
+	// Fetch every multisig preimage
+	msigAddrs, err := sw.ListMultisigAddrs()
+	if err != nil {
+		return
+	}
+	for _, addr := range msigAddrs {
+		var msig sqliteBackupMultisig
+		msig.Version, msig.Threshold, msig.PKs, err = sw.LookupMultisigPreimage(addr)
+		if err != nil {
+			return
+		}
+		contents.MultisigAddrs = append(contents.MultisigAddrs, msig)
+	}
+
+	// Encrypt everything under the backup password
+	blob, err := encryptBlobWithPasswordBlankOK(msgpackEncode(contents), PTWalletBackup, backupPw, &sw.cfg.ScryptParams)
+	if err != nil {
+		return
+	}
+
+	bundle := sqliteBackupBundle{
+		Magic:   sqliteBackupMagic,
+		Version: sqliteBackupVersion,
+		Blob:    blob,
+	}
+	return msgpackEncode(bundle), nil
+}
+
+// openBackup decrypts a backup bundle and checks that everything in it is
+// consistent: derived keys must match the master derivation key, and
+// imported keys and multisig preimages must be well formed
+func openBackup(backup []byte, backupPw []byte) (contents sqliteBackupContents, err error) {
+	var bundle sqliteBackupBundle
+	err = msgpackDecode(backup, &bundle)
+	if err != nil || bundle.Magic != sqliteBackupMagic {
+		err = errBackupMalformed
+		return
+	}
+	if bundle.Version != sqliteBackupVersion {
+		err = errBackupVersion
+		return
+	}
+
+	// secretbox authenticates the ciphertext, so a successful decryption
+	// means the contents are what Backup wrote
+	encoded, err := decryptBlobWithPassword(bundle.Blob, PTWalletBackup, backupPw)
+	if err != nil {
+		return
+	}
+	err = msgpackDecode(encoded, &contents)
+	if err != nil {
+		err = errBackupCorrupt
+		return
+	}
+	if contents.Version != bundle.Version {
+		err = errBackupCorrupt
+		return
+	}
+
+	seen := make(map[crypto.Digest]bool)
+	for _, key := range contents.Keys {
+		var pk crypto.PublicKey
+		pk, err = crypto.SecretKeyToPublicKey(key.SecretKey)
+		if err != nil {
+			err = errBackupCorrupt
+			return
+		}
+
+		if key.Derived {
+			if key.KeyIdx > contents.MaxKeyIdx {
+				err = errBackupCorrupt
+				return
+			}
+			var derivedPK crypto.PublicKey
+			derivedPK, _, err = extractKeyWithIndex(contents.MasterDerivationKey[:], key.KeyIdx)
+			if err != nil {
+				return
+			}
+			if derivedPK != pk {
+				err = errBackupCorrupt
+				return
+			}
+		}
+
+		addr := publicKeyToAddress(pk)
+		if seen[addr] {
+			err = errBackupCorrupt
+			return
+		}
+		seen[addr] = true
+	}
+
+	for _, msig := range contents.MultisigAddrs {
+		_, err = crypto.MultisigAddrGen(msig.Version, msig.Threshold, msig.PKs)
+		if err != nil {
+			err = errBackupCorrupt
+			return
+		}
+	}
+
+	return
+}
+
+// freeRestoreName returns name if no wallet has it, or, if renameOnConflict
+// is set, the first of "name-1", "name-2", ... that is free and short
+// enough. Precondition: we must hold swd.mux
+func (swd *SQLiteWalletDriver) freeRestoreName(name []byte, renameOnConflict bool) ([]byte, error) {
+	for i := 0; i < 1000; i++ {
+		candidate := name
+		if i > 0 {
+			suffix := []byte(fmt.Sprintf("-%d", i))
+			base := name
+			if len(base)+len(suffix) > sqliteMaxWalletNameLen {
+				base = base[:sqliteMaxWalletNameLen-len(suffix)]
+			}
+			candidate = append(append([]byte{}, base...), suffix...)
+		}
+
+		paths, err := swd.findDBPathsByName(candidate)
+		if err != nil {
+			return nil, err
+		}
+		if len(paths) == 0 {
+			return candidate, nil
+		}
+		if !renameOnConflict {
+			return nil, errSameName
+		}
+	}
+	return nil, errNameConflict
+}
+
+// RestoreWallet creates a wallet, protected by pw, from a backup made by
+// SQLiteWallet.Backup. The wallet keeps its original name and ID, unless
+// name is non-empty. If renameOnConflict is set, a name that is taken gets
+// a numeric suffix and an ID that is taken is replaced with a fresh one;
+// otherwise either conflict is an error. It returns the restored wallet's
+// ID.
+func (swd *SQLiteWalletDriver) RestoreWallet(backup []byte, backupPw []byte, name []byte, pw []byte, renameOnConflict bool) (id []byte, err error) {
+	contents, err := openBackup(backup, backupPw)
+	if err != nil {
+		return
+	}
+
+	swd.mux.Lock()
+	defer swd.mux.Unlock()
+
+	// Pick a name and ID that don't collide with existing wallets
+	if len(name) == 0 {
+		name = contents.WalletName
+	}
+	name, err = swd.freeRestoreName(name, renameOnConflict)
+	if err != nil {
+		return
+	}
+
+	id = contents.WalletID
+	sameIDDBPaths, err := swd.findDBPathsByID(id)
+	if err != nil {
+		return
+	}
+	if len(sameIDDBPaths) != 0 {
+		if !renameOnConflict {
+			err = errSameID
+			return
+		}
+		id, err = wallet.GenerateWalletID()
+		if err != nil {
+			return
+		}
+	}
+
+	// Create an empty wallet with the backed up master derivation key
+	err = swd.createWalletLocked(name, id, pw, contents.MasterDerivationKey)
+	if err != nil {
+		return
+	}
+
+	// Don't leave a half-restored wallet behind
+	dbPath := swd.nameIDToPath(name, id)
+	err = swd.fillRestoredWallet(dbPath, pw, contents)
+	if err != nil {
+		os.Remove(dbPath)
+		return nil, err
+	}
+	return id, nil
+}
+
+// fillRestoredWallet inserts the keys, key index and multisig preimages from
+// a backup into a freshly created wallet database
+func (swd *SQLiteWalletDriver) fillRestoredWallet(dbPath string, pw []byte, contents sqliteBackupContents) error {
+	sw := &SQLiteWallet{
+		dbPath: dbPath,
+		cfg:    swd.sqliteCfg,
+	}
+	err := sw.Init(pw)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(sw.masterDerivationKey, contents.MasterDerivationKey[:]) {
+		return errTampering
+	}
+
+	db, err := sqlx.Connect("sqlite3", dbConnectionURL(dbPath))
+	if err != nil {
+		return errDatabaseConnect
+	}
+	defer db.Close()
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return errDatabase
+	}
+	defer tx.Rollback()
+
+	for _, key := range contents.Keys {
+		pk, err := crypto.SecretKeyToPublicKey(key.SecretKey)
+		if err != nil {
+			return errSKToPK
+		}
+		addr := publicKeyToAddress(pk)
+
+		skEncrypted, err := encryptBlobWithKey(msgpackEncode(key.SecretKey), PTSecretKey, sw.masterEncryptionKey)
+		if err != nil {
+			return err
+		}
+
+		var keyIdx sql.NullInt64
+		if key.Derived {
+			keyIdx = sql.NullInt64{Int64: int64(key.KeyIdx), Valid: true}
+		}
+		_, err = tx.Exec("INSERT INTO keys (address, secret_key_encrypted, key_idx) VALUES(?, ?, ?)", addr[:], skEncrypted, keyIdx)
+		err = checkDBError(err)
+		if err != nil {
+			return err
+		}
+	}
+
+	encryptedIdxBlob, err := encryptBlobWithKey(msgpackEncode(contents.MaxKeyIdx), PTMaxKeyIdx, sw.masterEncryptionKey)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("UPDATE metadata SET max_key_idx_encrypted = ?", encryptedIdxBlob)
+	if err != nil {
+		return errDatabase
+	}
+
+	for _, msig := range contents.MultisigAddrs {
+		addr, err := crypto.MultisigAddrGen(msig.Version, msig.Threshold, msig.PKs)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec("INSERT INTO msig_addrs (address, version, threshold, pks) VALUES (?, ?, ?, ?)", addr[:], msig.Version, msig.Threshold, msgpackEncode(msig.PKs))
+		err = checkDBError(err)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errDatabase
+	}
+	return nil
+}
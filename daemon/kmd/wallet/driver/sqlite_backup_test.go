@@ -0,0 +1,137 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package driver
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/daemon/kmd/config"
+)
+
+func makeTestSQLiteDriver(t *testing.T, dataDir string) *SQLiteWalletDriver {
+	var cfg config.KMDConfig
+	cfg.DataDir = dataDir
+	cfg.DriverConfig.SQLiteWalletDriverConfig = config.SQLiteWalletDriverConfig{
+		UnsafeScrypt: true,
+		ScryptParams: config.ScryptParams{ScryptN: 2, ScryptR: 1, ScryptP: 1},
+	}
+
+	var swd SQLiteWalletDriver
+	require.NoError(t, swd.InitWithConfig(cfg))
+	return &swd
+}
+
+func TestSQLiteWalletBackupRestore(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "kmd-backup-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dataDir)
+
+	swd := makeTestSQLiteDriver(t, dataDir)
+	pw := []byte("wallet password")
+	backupPw := []byte("backup password")
+
+	err = swd.CreateWallet([]byte("orig"), []byte("orig-id"), pw, crypto.MasterDerivationKey{})
+	require.NoError(t, err)
+	w, err := swd.FetchWallet([]byte("orig-id"))
+	require.NoError(t, err)
+	require.NoError(t, w.Init(pw))
+
+	// Two derived keys, one imported key and a multisig address
+	_, err = w.GenerateKey(false)
+	require.NoError(t, err)
+	_, err = w.GenerateKey(false)
+	require.NoError(t, err)
+	imported := crypto.GenerateSignatureSecrets(crypto.Seed{42})
+	_, err = w.ImportKey(crypto.PrivateKey(imported.SK))
+	require.NoError(t, err)
+	pks := []crypto.PublicKey{crypto.PublicKey(imported.SignatureVerifier), crypto.PublicKey(crypto.GenerateSignatureSecrets(crypto.Seed{43}).SignatureVerifier)}
+	msigAddr, err := w.ImportMultisigAddr(1, 2, pks)
+	require.NoError(t, err)
+
+	origKeys, err := w.ListKeys()
+	require.NoError(t, err)
+	require.Len(t, origKeys, 3)
+
+	backup, err := w.(BackupWallet).Backup(pw, backupPw)
+	require.NoError(t, err)
+
+	_, err = w.(BackupWallet).Backup(pw, nil)
+	require.Equal(t, errBackupPasswordBlank, err)
+	_, err = w.(BackupWallet).Backup([]byte("wrong"), backupPw)
+	require.Error(t, err)
+
+	// Without renaming, the original wallet is in the way
+	_, err = swd.RestoreWallet(backup, backupPw, nil, pw, false)
+	require.Equal(t, errSameName, err)
+	_, err = swd.RestoreWallet(backup, backupPw, []byte("copy"), pw, false)
+	require.Equal(t, errSameID, err)
+
+	// Bad password and tampering are caught before anything is written
+	_, err = swd.RestoreWallet(backup, []byte("wrong"), []byte("copy"), pw, true)
+	require.Equal(t, errDecrypt, err)
+	tampered := append([]byte{}, backup...)
+	tampered[len(tampered)-1] ^= 1
+	_, err = swd.RestoreWallet(tampered, backupPw, []byte("copy"), pw, true)
+	require.Error(t, err)
+	_, err = swd.RestoreWallet([]byte("not a backup"), backupPw, []byte("copy"), pw, true)
+	require.Equal(t, errBackupMalformed, err)
+
+	metadatas, err := swd.ListWalletMetadatas()
+	require.NoError(t, err)
+	require.Len(t, metadatas, 1)
+
+	// Restore with renaming, under a new password
+	newPw := []byte("new password")
+	id, err := swd.RestoreWallet(backup, backupPw, nil, newPw, true)
+	require.NoError(t, err)
+	require.NotEqual(t, []byte("orig-id"), id)
+
+	restored, err := swd.FetchWallet(id)
+	require.NoError(t, err)
+	require.NoError(t, restored.Init(newPw))
+	md, err := restored.Metadata()
+	require.NoError(t, err)
+	require.Equal(t, []byte("orig-1"), md.Name)
+
+	keys, err := restored.ListKeys()
+	require.NoError(t, err)
+	require.ElementsMatch(t, origKeys, keys)
+
+	mdk, err := restored.ExportMasterDerivationKey(newPw)
+	require.NoError(t, err)
+	origMDK, err := w.ExportMasterDerivationKey(pw)
+	require.NoError(t, err)
+	require.Equal(t, origMDK, mdk)
+
+	version, threshold, restoredPKs, err := restored.LookupMultisigPreimage(msigAddr)
+	require.NoError(t, err)
+	require.Equal(t, uint8(1), version)
+	require.Equal(t, uint8(2), threshold)
+	require.Equal(t, pks, restoredPKs)
+
+	// Both wallets derive the same next key
+	next, err := w.GenerateKey(false)
+	require.NoError(t, err)
+	restoredNext, err := restored.GenerateKey(false)
+	require.NoError(t, err)
+	require.Equal(t, next, restoredNext)
+}
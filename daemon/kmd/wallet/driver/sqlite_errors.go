@@ -44,3 +44,8 @@ var errNameTooLong = fmt.Errorf("wallet name too long, must be <= %d bytes", sql
 var errIDTooLong = fmt.Errorf("wallet id too long, must be <= %d bytes", sqliteMaxWalletIDLen)
 var errMsigWrongAddr = fmt.Errorf("given multisig preimage hashes to wrong address")
 var errMsigWrongKey = fmt.Errorf("given key is not a possible signer for this multisig")
+var errBackupPasswordBlank = fmt.Errorf("backup password must not be blank")
+var errBackupMalformed = fmt.Errorf("not a sqlite wallet backup")
+var errBackupVersion = fmt.Errorf("unsupported sqlite wallet backup version")
+var errBackupCorrupt = fmt.Errorf("wallet backup failed integrity checks")
+var errNameConflict = fmt.Errorf("could not find a free wallet name to restore to")
@@ -51,9 +51,15 @@ type Wallet interface {
 	ListMultisigAddrs() (addrs []crypto.Digest, err error)
 	DeleteMultisigAddr(addr crypto.Digest, pw []byte) error
 
-	SignTransaction(tx transactions.Transaction, pw []byte) ([]byte, error)
+	// SignTransaction signs tx with the key pk, or with the key of the
+	// transaction's sender if pk is empty.  Signing with a key other than
+	// the sender's produces a transaction authorized by a rekeyed account.
+	SignTransaction(tx transactions.Transaction, pk crypto.PublicKey, pw []byte) ([]byte, error)
 
-	MultisigSignTransaction(tx transactions.Transaction, pk crypto.PublicKey, partial crypto.MultisigSig, pw []byte) (crypto.MultisigSig, error)
+	// MultisigSignTransaction adds a signature by pk to the multisig of
+	// the address signer, or of the transaction's sender if signer is
+	// empty.
+	MultisigSignTransaction(tx transactions.Transaction, pk crypto.PublicKey, partial crypto.MultisigSig, pw []byte, signer crypto.Digest) (crypto.MultisigSig, error)
 }
 
 // Metadata represents high-level information about a wallet, like its name, id
@@ -0,0 +1,172 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package partsigner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/algorand/go-algorand/config"
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/data/account"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/logging"
+	"github.com/algorand/go-algorand/protocol"
+)
+
+// requestTimeout bounds how long the node waits for the signer.  Votes are
+// only useful while their step lasts, so there is no point in waiting longer.
+const requestTimeout = 5 * time.Second
+
+// Client talks to a signer over its local socket.
+type Client struct {
+	http http.Client
+	log  logging.Logger
+}
+
+// MakeClient creates a Client for the signer listening on the unix socket at
+// socketPath.
+func MakeClient(socketPath string, log logging.Logger) *Client {
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socketPath)
+		},
+	}
+	return &Client{
+		http: http.Client{Transport: transport, Timeout: requestTimeout},
+		log:  log,
+	}
+}
+
+// Participations returns the participation keys held by the signer.  The
+// returned Participations carry only public keys, and sign through c.
+func (c *Client) Participations() ([]account.Participation, error) {
+	var resp keysResponse
+	err := c.do(keysPath, nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := make([]account.Participation, 0, len(resp.Keys))
+	for _, key := range resp.Keys {
+		voting := &crypto.OneTimeSignatureSecrets{}
+		voting.OneTimeSignatureVerifier = key.VotePK
+		parts = append(parts, account.Participation{
+			Parent:      key.Parent,
+			VRF:         &crypto.VRFSecrets{PK: key.SelectionPK},
+			Voting:      voting,
+			FirstValid:  key.FirstValid,
+			LastValid:   key.LastValid,
+			KeyDilution: key.KeyDilution,
+			Remote: remoteKey{
+				client:      c,
+				ref:         keyRef{Parent: key.Parent, FirstValid: key.FirstValid},
+				keyDilution: key.KeyDilution,
+			},
+		})
+	}
+	return parts, nil
+}
+
+// do posts the encoding of req to path and decodes the response into resp.
+func (c *Client) do(path string, req interface{}, resp interface{}) error {
+	var body []byte
+	if req != nil {
+		body = protocol.Encode(req)
+	}
+	httpResp, err := c.http.Post("http://partsigner"+path, "application/msgpack", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("partsigner: %v", err)
+	}
+	defer httpResp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return fmt.Errorf("partsigner: could not read response: %v", err)
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return fmt.Errorf("partsigner: %s: %s", httpResp.Status, bytes.TrimSpace(respBody))
+	}
+	return protocol.Decode(respBody, resp)
+}
+
+// remoteKey is an account.RemoteSigner for one participation key held by
+// the signer.
+type remoteKey struct {
+	client      *Client
+	ref         keyRef
+	keyDilution uint64
+}
+
+// ProveVRF implements account.ParticipationSigner.ProveVRF.
+func (k remoteKey) ProveVRF(message crypto.Hashable) (crypto.VrfProof, bool) {
+	h := makeHashable(message)
+	req := proveRequest{Key: k.ref, HashID: h.hashID, Data: h.data}
+
+	var resp proveResponse
+	err := k.client.do(provePath, req, &resp)
+	if err != nil {
+		k.client.log.Warnf("remoteKey.ProveVRF(%v): %v", k.ref.Parent, err)
+		return crypto.VrfProof{}, false
+	}
+	return resp.Proof, true
+}
+
+// Sign implements account.ParticipationSigner.Sign.
+func (k remoteKey) Sign(id crypto.OneTimeSignatureIdentifier, fineGrained bool, message crypto.Hashable) crypto.OneTimeSignature {
+	h := makeHashable(message)
+	req := signRequest{
+		Key:         k.ref,
+		ID:          id,
+		FineGrained: fineGrained,
+		HashID:      h.hashID,
+		Data:        h.data,
+	}
+
+	var resp signResponse
+	err := k.client.do(signPath, req, &resp)
+	if err != nil {
+		k.client.log.Warnf("remoteKey.Sign(%v): %v", k.ref.Parent, err)
+		return crypto.OneTimeSignature{}
+	}
+	return resp.Sig
+}
+
+// KeyDilution implements account.ParticipationSigner.KeyDilution.
+func (k remoteKey) KeyDilution(params config.ConsensusParams) uint64 {
+	if k.keyDilution != 0 {
+		return k.keyDilution
+	}
+	return params.DefaultKeyDilution
+}
+
+// DeleteOldKeys implements account.RemoteSigner.DeleteOldKeys.
+func (k remoteKey) DeleteOldKeys(current basics.Round, proto config.ConsensusParams) error {
+	req := deleteRequest{
+		Key:         k.ref,
+		Round:       current,
+		KeyDilution: k.KeyDilution(proto),
+		FineGrained: proto.FineGrainedEphemeralKeys,
+	}
+	return k.client.do(deletePath, req, &struct{}{})
+}
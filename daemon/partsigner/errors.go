@@ -0,0 +1,33 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package partsigner
+
+import (
+	"fmt"
+)
+
+// refusedError is returned when signing a vote could make its sender
+// equivocate.
+type refusedError struct {
+	vote   vote
+	reason string
+}
+
+// Error implements the error interface.
+func (e refusedError) Error() string {
+	return fmt.Sprintf("refusing to sign vote of %v for round %d period %d step %d: %s", e.vote.Sender, e.vote.Round, e.vote.Period, e.vote.Step, e.reason)
+}
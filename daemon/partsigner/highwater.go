@@ -0,0 +1,108 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package partsigner
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/util/db"
+)
+
+var highWaterSchema = []string{
+	`CREATE TABLE IF NOT EXISTS highwater (
+		parent blob primary key,
+		round integer,
+		period integer)`,
+	`CREATE TABLE IF NOT EXISTS signedsteps (
+		parent blob,
+		step integer,
+		digest blob,
+		primary key (parent, step))`,
+}
+
+// highWater persists, for every account, the latest round and period in
+// which the signer signed a vote, together with the digest of the vote it
+// signed at each step of that period.
+//
+// Agreement does not vote in a monotonic order of steps within a period
+// (for instance, cert votes and recovery votes interleave with next votes),
+// so the mark is kept at the granularity of a period, and each step within
+// the current period may be signed at most once.
+type highWater struct {
+	store db.Accessor
+}
+
+func makeHighWater(store db.Accessor) (*highWater, error) {
+	err := store.Atomic(func(tx *sql.Tx) error {
+		for _, stmt := range highWaterSchema {
+			_, err := tx.Exec(stmt)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("makeHighWater: could not install schema: %v", err)
+	}
+	return &highWater{store: store}, nil
+}
+
+// admit checks v against the high-water mark of its sender.  If v may be
+// signed, admit durably records it before returning nil.  Signing the same
+// vote again is allowed, so that a node which restarts may repeat its votes.
+func (hw *highWater) admit(v vote, digest crypto.Digest) error {
+	return hw.store.Atomic(func(tx *sql.Tx) error {
+		var round, period uint64
+		err := tx.QueryRow("SELECT round, period FROM highwater WHERE parent=?", v.Sender[:]).Scan(&round, &period)
+		switch {
+		case err == sql.ErrNoRows:
+		case err != nil:
+			return err
+		case uint64(v.Round) < round || (uint64(v.Round) == round && v.Period < period):
+			return refusedError{vote: v, reason: fmt.Sprintf("below high-water mark of round %d period %d", round, period)}
+		case uint64(v.Round) == round && v.Period == period:
+			var prev []byte
+			err = tx.QueryRow("SELECT digest FROM signedsteps WHERE parent=? AND step=?", v.Sender[:], v.Step).Scan(&prev)
+			if err == nil {
+				if !bytes.Equal(prev, digest[:]) {
+					return refusedError{vote: v, reason: "already signed a different vote for this step"}
+				}
+				return nil
+			}
+			if err != sql.ErrNoRows {
+				return err
+			}
+			_, err = tx.Exec("INSERT INTO signedsteps (parent, step, digest) VALUES (?, ?, ?)", v.Sender[:], v.Step, digest[:])
+			return err
+		}
+
+		_, err = tx.Exec("INSERT OR REPLACE INTO highwater (parent, round, period) VALUES (?, ?, ?)", v.Sender[:], v.Round, v.Period)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec("DELETE FROM signedsteps WHERE parent=?", v.Sender[:])
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec("INSERT INTO signedsteps (parent, step, digest) VALUES (?, ?, ?)", v.Sender[:], v.Step, digest[:])
+		return err
+	})
+}
@@ -0,0 +1,147 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package partsigner
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/go-algorand/config"
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/data/account"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/committee"
+	"github.com/algorand/go-algorand/logging"
+	"github.com/algorand/go-algorand/protocol"
+	"github.com/algorand/go-algorand/util/db"
+)
+
+// testVote encodes like the raw votes of the agreement package.
+type testVote vote
+
+func (v testVote) ToBeHashed() (protocol.HashID, []byte) {
+	return protocol.Vote, protocol.Encode(vote(v))
+}
+
+type testSigner struct {
+	t      *testing.T
+	dir    string
+	part   account.Participation
+	server *Server
+	l      net.Listener
+}
+
+func (ts *testSigner) start() account.Participation {
+	hwStore, err := db.MakeAccessor(filepath.Join(ts.dir, "highwater.sqlite"), false, false)
+	require.NoError(ts.t, err)
+	ts.server, err = MakeServer([]account.Participation{ts.part}, hwStore, logging.TestingLog(ts.t))
+	require.NoError(ts.t, err)
+
+	socket := filepath.Join(ts.dir, "signer.sock")
+	os.Remove(socket)
+	ts.l, err = net.Listen("unix", socket)
+	require.NoError(ts.t, err)
+	go ts.server.Serve(ts.l)
+
+	parts, err := MakeClient(socket, logging.TestingLog(ts.t)).Participations()
+	require.NoError(ts.t, err)
+	require.Len(ts.t, parts, 1)
+	return parts[0]
+}
+
+func (ts *testSigner) stop() {
+	ts.l.Close()
+	ts.server.hw.store.Close()
+}
+
+func TestRemoteSigner(t *testing.T) {
+	dir, err := ioutil.TempDir("", "partsigner")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	proto := config.Consensus[protocol.ConsensusCurrentVersion]
+	partDB, err := db.MakeAccessor(t.Name()+"_part", false, true)
+	require.NoError(t, err)
+	defer partDB.Close()
+	parent := basics.Address(crypto.GenerateSignatureSecrets(crypto.Seed{1}).SignatureVerifier)
+	local, err := account.FillDBWithParticipationKeys(partDB, parent, 0, 1000, proto.DefaultKeyDilution)
+	require.NoError(t, err)
+
+	ts := &testSigner{t: t, dir: dir, part: local}
+	remote := ts.start()
+	require.Equal(t, local.Parent, remote.Parent)
+	require.Equal(t, local.VRF.PK, remote.VRF.PK)
+	require.Equal(t, local.Voting.OneTimeSignatureVerifier, remote.Voting.OneTimeSignatureVerifier)
+	require.Equal(t, local.GenerateRegistrationTransaction(basics.MicroAlgos{}, 0, 0, proto), remote.GenerateRegistrationTransaction(basics.MicroAlgos{}, 0, 0, proto))
+
+	signer := remote.Signer()
+	sign := func(v testVote) bool {
+		id := basics.OneTimeIDForRound(v.Round, signer.KeyDilution(proto))
+		sig := signer.Sign(id, proto.FineGrainedEphemeralKeys, v)
+		if (sig == crypto.OneTimeSignature{}) {
+			return false
+		}
+		require.True(t, remote.Voting.Verify(id, proto.FineGrainedEphemeralKeys, v, sig))
+		return true
+	}
+
+	seed := committee.Seed{7}
+	proof, ok := signer.ProveVRF(seed)
+	require.True(t, ok)
+	ok, _ = remote.VRF.PK.Verify(proof, seed)
+	require.True(t, ok)
+
+	soft := testVote{Sender: parent, Round: 10, Period: 0, Step: 1}
+	soft.Proposal.BlockDigest = crypto.Digest{1}
+	require.True(t, sign(soft))
+	require.True(t, sign(soft), "repeating a vote must be allowed")
+
+	equivocation := soft
+	equivocation.Proposal.BlockDigest = crypto.Digest{2}
+	require.False(t, sign(equivocation))
+
+	// Steps within a period need not be signed in order
+	down := testVote{Sender: parent, Round: 10, Period: 0, Step: 255}
+	cert := soft
+	cert.Step = 2
+	require.True(t, sign(down))
+	require.True(t, sign(cert))
+
+	nextPeriod := testVote{Sender: parent, Round: 10, Period: 1, Step: 1}
+	require.True(t, sign(nextPeriod))
+	require.False(t, sign(cert))
+
+	other := testVote{Sender: basics.Address{1}, Round: 11, Period: 0, Step: 1}
+	require.False(t, sign(other))
+
+	// The high-water mark survives a restart of the signer
+	ts.stop()
+	remote = ts.start()
+	signer = remote.Signer()
+	require.False(t, sign(down))
+	require.True(t, sign(testVote{Sender: parent, Round: 11, Period: 0, Step: 0}))
+
+	require.NoError(t, remote.DeleteOldKeys(12, proto))
+	require.False(t, sign(testVote{Sender: parent, Round: 11, Period: 0, Step: 1}))
+	require.True(t, sign(testVote{Sender: parent, Round: 12, Period: 0, Step: 0}))
+	ts.stop()
+}
@@ -0,0 +1,139 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package partsigner lets a node participate in consensus without holding its
+// participation keys.  A separate signer process keeps the keys and answers
+// requests for VRF proofs and vote signatures over a local socket.  The signer
+// records a persistent high-water mark for every account, so that it never
+// signs two different votes for the same round, period and step, nor any vote
+// for a round and period older than one it has already voted in.
+package partsigner
+
+import (
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/protocol"
+)
+
+// Paths served by the signer.  Requests and responses are msgpack-encoded.
+const (
+	keysPath   = "/v1/keys"
+	provePath  = "/v1/prove"
+	signPath   = "/v1/sign"
+	deletePath = "/v1/delete"
+)
+
+// keyRef identifies a participation key held by the signer.
+type keyRef struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	Parent     basics.Address `codec:"parent"`
+	FirstValid basics.Round   `codec:"first"`
+}
+
+// keyInfo describes the public half of a participation key held by the signer.
+type keyInfo struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	Parent      basics.Address                  `codec:"parent"`
+	FirstValid  basics.Round                    `codec:"first"`
+	LastValid   basics.Round                    `codec:"last"`
+	KeyDilution uint64                          `codec:"kd"`
+	SelectionPK crypto.VrfPubkey                `codec:"sel"`
+	VotePK      crypto.OneTimeSignatureVerifier `codec:"vote"`
+}
+
+type keysResponse struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	Keys []keyInfo `codec:"keys"`
+}
+
+type proveRequest struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	Key    keyRef          `codec:"key"`
+	HashID protocol.HashID `codec:"hid"`
+	Data   []byte          `codec:"data"`
+}
+
+type proveResponse struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	Proof crypto.VrfProof `codec:"proof"`
+}
+
+type signRequest struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	Key         keyRef                            `codec:"key"`
+	ID          crypto.OneTimeSignatureIdentifier `codec:"id"`
+	FineGrained bool                              `codec:"fine"`
+	HashID      protocol.HashID                   `codec:"hid"`
+	Data        []byte                            `codec:"data"`
+}
+
+type signResponse struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	Sig crypto.OneTimeSignature `codec:"sig"`
+}
+
+type deleteRequest struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	Key         keyRef       `codec:"key"`
+	Round       basics.Round `codec:"rnd"`
+	KeyDilution uint64       `codec:"kd"`
+	FineGrained bool         `codec:"fine"`
+}
+
+// hashable carries an already-encoded Hashable across the socket.
+type hashable struct {
+	hashID protocol.HashID
+	data   []byte
+}
+
+func makeHashable(h crypto.Hashable) hashable {
+	hashID, data := h.ToBeHashed()
+	return hashable{hashID: hashID, data: data}
+}
+
+// ToBeHashed implements the Hashable interface.
+func (h hashable) ToBeHashed() (protocol.HashID, []byte) {
+	return h.hashID, h.data
+}
+
+// vote mirrors the encoding of the raw votes built by the agreement
+// package, which are the only messages the signer signs with one-time keys.
+type vote struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	Sender   basics.Address `codec:"snd"`
+	Round    basics.Round   `codec:"rnd"`
+	Period   uint64         `codec:"per"`
+	Step     uint64         `codec:"step"`
+	Proposal voteProposal   `codec:"prop"`
+}
+
+type voteProposal struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	OriginalPeriod   uint64         `codec:"oper"`
+	OriginalProposer basics.Address `codec:"oprop"`
+	BlockDigest      crypto.Digest  `codec:"dig"`
+	EncodingDigest   crypto.Digest  `codec:"encdig"`
+}
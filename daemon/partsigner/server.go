@@ -0,0 +1,237 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package partsigner
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+
+	"github.com/algorand/go-deadlock"
+
+	"github.com/algorand/go-algorand/config"
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/data/account"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/logging"
+	"github.com/algorand/go-algorand/protocol"
+	"github.com/algorand/go-algorand/util/db"
+)
+
+// maxRequestBytes bounds the size of a request body.
+const maxRequestBytes = 1 << 20
+
+// Server holds participation keys and signs on behalf of a node.
+type Server struct {
+	// mu serializes signing, so that the high-water mark check and the
+	// signature it admits happen atomically.
+	mu deadlock.Mutex
+
+	keys map[keyRef]account.Participation
+	hw   *highWater
+	mux  *http.ServeMux
+	log  logging.Logger
+}
+
+// MakeServer creates a Server for the given participation keys, which keeps
+// its high-water marks in hwStore.
+func MakeServer(parts []account.Participation, hwStore db.Accessor, log logging.Logger) (*Server, error) {
+	hw, err := makeHighWater(hwStore)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		keys: make(map[keyRef]account.Participation),
+		hw:   hw,
+		mux:  http.NewServeMux(),
+		log:  log,
+	}
+	for _, part := range parts {
+		if part.Remote != nil {
+			return nil, fmt.Errorf("MakeServer: participation key of %v is itself remote", part.Address())
+		}
+		s.keys[keyRef{Parent: part.Parent, FirstValid: part.FirstValid}] = part
+	}
+
+	s.mux.HandleFunc(keysPath, s.handleKeys)
+	s.mux.HandleFunc(provePath, s.handleProve)
+	s.mux.HandleFunc(signPath, s.handleSign)
+	s.mux.HandleFunc(deletePath, s.handleDelete)
+	return s, nil
+}
+
+// Serve answers requests arriving on l until l is closed.
+func (s *Server) Serve(l net.Listener) error {
+	return http.Serve(l, s.mux)
+}
+
+func (s *Server) handleKeys(w http.ResponseWriter, r *http.Request) {
+	var resp keysResponse
+	for _, part := range s.keys {
+		resp.Keys = append(resp.Keys, keyInfo{
+			Parent:      part.Parent,
+			FirstValid:  part.FirstValid,
+			LastValid:   part.LastValid,
+			KeyDilution: part.KeyDilution,
+			SelectionPK: part.VRF.PK,
+			VotePK:      part.Voting.OneTimeSignatureVerifier,
+		})
+	}
+	writeResponse(w, resp)
+}
+
+func (s *Server) handleProve(w http.ResponseWriter, r *http.Request) {
+	var req proveRequest
+	part, ok := s.decodeRequest(w, r, &req, &req.Key)
+	if !ok {
+		return
+	}
+
+	proof, ok := part.VRF.SK.Prove(hashable{hashID: req.HashID, data: req.Data})
+	if !ok {
+		s.fail(w, http.StatusInternalServerError, fmt.Errorf("could not construct VRF proof for %v", req.Key.Parent))
+		return
+	}
+	writeResponse(w, proveResponse{Proof: proof})
+}
+
+func (s *Server) handleSign(w http.ResponseWriter, r *http.Request) {
+	var req signRequest
+	part, ok := s.decodeRequest(w, r, &req, &req.Key)
+	if !ok {
+		return
+	}
+
+	msg := hashable{hashID: req.HashID, data: req.Data}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch req.HashID {
+	case protocol.Vote:
+		if !s.admitVote(w, part, req, msg) {
+			return
+		}
+	case protocol.NetPrioResponse:
+		// Priority responses prove that the node holds the key; they cannot
+		// equivocate, so they are not subject to the high-water mark.
+	default:
+		s.fail(w, http.StatusBadRequest, fmt.Errorf("refusing to sign %q message with a voting key", req.HashID))
+		return
+	}
+
+	sig := part.Voting.Sign(req.ID, req.FineGrained, msg)
+	if (sig == crypto.OneTimeSignature{}) {
+		s.fail(w, http.StatusInternalServerError, fmt.Errorf("no one-time key %v for %v", req.ID, part.Parent))
+		return
+	}
+	writeResponse(w, signResponse{Sig: sig})
+}
+
+// admitVote checks a vote signing request against the high-water mark.  It
+// writes an error response and returns false if the vote may not be signed.
+func (s *Server) admitVote(w http.ResponseWriter, part account.Participation, req signRequest, msg hashable) bool {
+	var v vote
+	err := protocol.Decode(req.Data, &v)
+	if err != nil {
+		s.fail(w, http.StatusBadRequest, fmt.Errorf("could not decode vote: %v", err))
+		return false
+	}
+	if v.Sender != part.Parent {
+		s.fail(w, http.StatusBadRequest, fmt.Errorf("vote sender %v does not match key of %v", v.Sender, part.Parent))
+		return false
+	}
+	// Keys without an explicit key dilution inherit it from the consensus
+	// parameters, which the signer does not know; their id is not checked.
+	if part.KeyDilution != 0 && req.ID != basics.OneTimeIDForRound(v.Round, part.KeyDilution) {
+		s.fail(w, http.StatusBadRequest, fmt.Errorf("one-time key %v does not match round %d", req.ID, v.Round))
+		return false
+	}
+
+	err = s.hw.admit(v, crypto.HashObj(msg))
+	if err != nil {
+		if _, refused := err.(refusedError); refused {
+			s.fail(w, http.StatusConflict, err)
+		} else {
+			s.fail(w, http.StatusInternalServerError, fmt.Errorf("could not update high-water mark: %v", err))
+		}
+		return false
+	}
+	return true
+}
+
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
+	var req deleteRequest
+	part, ok := s.decodeRequest(w, r, &req, &req.Key)
+	if !ok {
+		return
+	}
+
+	proto := config.ConsensusParams{
+		DefaultKeyDilution:       req.KeyDilution,
+		FineGrainedEphemeralKeys: req.FineGrained,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := part.DeleteOldKeys(req.Round, proto)
+	if err != nil {
+		s.fail(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeResponse(w, struct{}{})
+}
+
+// decodeRequest decodes the body of r into req, and looks up the key it
+// refers to.  It writes an error response and returns false on failure.
+func (s *Server) decodeRequest(w http.ResponseWriter, r *http.Request, req interface{}, key *keyRef) (account.Participation, bool) {
+	if r.Method != http.MethodPost {
+		s.fail(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return account.Participation{}, false
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+	if err != nil {
+		s.fail(w, http.StatusBadRequest, err)
+		return account.Participation{}, false
+	}
+	err = protocol.Decode(body, req)
+	if err != nil {
+		s.fail(w, http.StatusBadRequest, err)
+		return account.Participation{}, false
+	}
+
+	part, ok := s.keys[*key]
+	if !ok {
+		s.fail(w, http.StatusNotFound, fmt.Errorf("no participation key for %v from round %d", key.Parent, key.FirstValid))
+		return account.Participation{}, false
+	}
+	return part, true
+}
+
+func (s *Server) fail(w http.ResponseWriter, status int, err error) {
+	s.log.Warnf("partsigner: %v", err)
+	http.Error(w, err.Error(), status)
+}
+
+func writeResponse(w http.ResponseWriter, obj interface{}) {
+	w.Header().Set("Content-Type", "application/msgpack")
+	w.Write(protocol.Encode(obj))
+}
@@ -57,6 +57,11 @@ type Participation struct {
 	KeyDilution uint64
 
 	Store db.Accessor
+
+	// Remote, if set, holds the secrets of this Participation in another
+	// process.  VRF and Voting then only carry the public keys, and Store
+	// is unused.
+	Remote RemoteSigner
 }
 
 // ValidInterval returns the first and last rounds for which this participation account is valid.
@@ -82,6 +87,10 @@ func (part Participation) OverlapsInterval(first, last basics.Round) bool {
 
 // DeleteOldKeys securely deletes ephemeral keys for rounds strictly older than the given round.
 func (part Participation) DeleteOldKeys(current basics.Round, proto config.ConsensusParams) error {
+	if part.Remote != nil {
+		return part.Remote.DeleteOldKeys(current, proto)
+	}
+
 	keyDilution := part.KeyDilution
 	if keyDilution == 0 {
 		keyDilution = proto.DefaultKeyDilution
@@ -209,5 +218,8 @@ func Migrate(partDB db.Accessor) error {
 
 // Close closes the underlying database handle.
 func (part Participation) Close() {
+	if part.Remote != nil {
+		return
+	}
 	part.Store.Close()
 }
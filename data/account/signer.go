@@ -0,0 +1,68 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package account
+
+import (
+	"github.com/algorand/go-algorand/config"
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/data/basics"
+)
+
+// A ParticipationSigner produces the VRF proofs and one-time signatures which
+// a Participation uses to take part in consensus.
+type ParticipationSigner interface {
+	// ProveVRF returns a VRF proof of message under the selection key.
+	// It returns false if no proof could be made.
+	ProveVRF(message crypto.Hashable) (crypto.VrfProof, bool)
+
+	// Sign signs message with the one-time voting key for id.
+	// It returns an empty signature if the message could not be signed.
+	Sign(id crypto.OneTimeSignatureIdentifier, fineGrained bool, message crypto.Hashable) crypto.OneTimeSignature
+
+	// KeyDilution returns the key dilution of the voting key under params.
+	KeyDilution(params config.ConsensusParams) uint64
+}
+
+// A RemoteSigner is a ParticipationSigner whose secrets are held outside of
+// this process, such as by a separate signer daemon.
+type RemoteSigner interface {
+	ParticipationSigner
+
+	// DeleteOldKeys asks the signer to securely delete ephemeral keys for
+	// rounds strictly older than the given round.
+	DeleteOldKeys(current basics.Round, proto config.ConsensusParams) error
+}
+
+// LocalSigner is a ParticipationSigner whose secrets are held in memory.
+type LocalSigner struct {
+	crypto.OneTimeSigner
+	VRF *crypto.VRFSecrets
+}
+
+// ProveVRF implements ParticipationSigner.ProveVRF.
+func (s LocalSigner) ProveVRF(message crypto.Hashable) (crypto.VrfProof, bool) {
+	return s.VRF.SK.Prove(message)
+}
+
+// Signer returns the ParticipationSigner for this Participation, which is
+// either its RemoteSigner or its locally-held secrets.
+func (part Participation) Signer() ParticipationSigner {
+	if part.Remote != nil {
+		return part.Remote
+	}
+	return LocalSigner{OneTimeSigner: part.VotingSigner(), VRF: part.VRF}
+}
@@ -98,6 +98,11 @@ func (addr Address) String() string {
 	return fmt.Sprintf("%v", crypto.Digest(addr))
 }
 
+// IsZero checks if an address is the zero value.
+func (addr Address) IsZero() bool {
+	return addr == Address{}
+}
+
 // MarshalText returns the address string as an array of bytes
 func (addr Address) MarshalText() ([]byte, error) {
 	return []byte(addr.String()), nil
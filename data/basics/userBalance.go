@@ -17,6 +17,8 @@
 package basics
 
 import (
+	"reflect"
+
 	"github.com/algorand/go-algorand/config"
 	"github.com/algorand/go-algorand/crypto"
 	"github.com/algorand/go-algorand/logging"
@@ -103,6 +105,90 @@ type AccountData struct {
 	VoteFirstValid  Round  `codec:"voteFst"`
 	VoteLastValid   Round  `codec:"voteLst"`
 	VoteKeyDilution uint64 `codec:"voteKD"`
+
+	// If this account created an asset, AssetParams stores
+	// the parameters defining that asset, indexed by the asset's
+	// AssetIndex.
+	//
+	// An account with any asset in AssetParams cannot be
+	// closed, until the asset is destroyed.  An asset can
+	// be destroyed if this account holds AssetParams.Total units
+	// of that asset (in the Assets map below).
+	AssetParams map[AssetIndex]AssetParams `codec:"apar"`
+
+	// Assets is the set of assets that can be held by this
+	// account.  Assets (i.e., slots in this map) are explicitly
+	// added and removed from an account by special transactions.
+	// The map is keyed by the AssetIndex, which is a globally unique
+	// identifier of the asset, allocated when the asset is created.
+	//
+	// Each asset bumps the required MinBalance in this account.
+	//
+	// An account that creates an asset must have its own asset
+	// in the Assets map until that asset is destroyed.
+	Assets map[AssetIndex]AssetHolding `codec:"asset"`
+
+	// AuthAddr is the address against which signatures/multisigs/logicsigs
+	// should be checked.  If empty, the address of the account whose
+	// AccountData this is is used.  A transaction may change an account's
+	// AuthAddr to "rekey" the account to a new spending key.
+	AuthAddr Address `codec:"spend"`
+}
+
+// AssetIndex is the unique integer index of an asset that can be used to look
+// up the creator of the asset, whose balance record contains the AssetParams
+type AssetIndex uint64
+
+// AssetHolding describes an asset held by an account.
+type AssetHolding struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	Amount uint64 `codec:"a"`
+	Frozen bool   `codec:"f"`
+}
+
+// AssetParams describes the parameters of an asset.
+type AssetParams struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	// Total specifies the total number of units of this asset
+	// created.
+	Total uint64 `codec:"t"`
+
+	// DefaultFrozen specifies whether slots for this asset
+	// in user accounts are frozen by default or not.
+	DefaultFrozen bool `codec:"df"`
+
+	// UnitName specifies a hint for the name of a unit of
+	// this asset.
+	UnitName string `codec:"un"`
+
+	// AssetName specifies a hint for the name of the asset.
+	AssetName string `codec:"an"`
+
+	// URL specifies a URL where more information about the asset can be
+	// retrieved
+	URL string `codec:"au"`
+
+	// MetadataHash specifies a commitment to some unspecified asset
+	// metadata. The format of this metadata is up to the application.
+	MetadataHash [32]byte `codec:"am"`
+
+	// Manager specifies an account that is allowed to change the
+	// non-zero addresses in this AssetParams.
+	Manager Address `codec:"m"`
+
+	// Reserve specifies an account whose holdings of this asset
+	// should be reported as "not minted".
+	Reserve Address `codec:"r"`
+
+	// Freeze specifies an account that is allowed to change the
+	// frozen state of holdings of this asset.
+	Freeze Address `codec:"f"`
+
+	// Clawback specifies an account that is allowed to take units
+	// of this asset from any account.
+	Clawback Address `codec:"c"`
 }
 
 // AccountDetail encapsulates meaningful details about a given account, for external consumption
@@ -132,6 +218,45 @@ func MakeAccountData(status Status, algos MicroAlgos) AccountData {
 	return AccountData{Status: status, MicroAlgos: algos}
 }
 
+// IsZero checks if an AccountData value is the same as its zero value.
+func (u AccountData) IsZero() bool {
+	if u.Assets != nil && len(u.Assets) == 0 {
+		u.Assets = nil
+	}
+	if u.AssetParams != nil && len(u.AssetParams) == 0 {
+		u.AssetParams = nil
+	}
+
+	return reflect.DeepEqual(u, AccountData{})
+}
+
+// CloneAssetHoldings returns a copy of the Assets map, so that the caller
+// can modify it without affecting other copies of this AccountData.
+func (u AccountData) CloneAssetHoldings() map[AssetIndex]AssetHolding {
+	res := make(map[AssetIndex]AssetHolding, len(u.Assets))
+	for k, v := range u.Assets {
+		res[k] = v
+	}
+	return res
+}
+
+// CloneAssetParams returns a copy of the AssetParams map, so that the caller
+// can modify it without affecting other copies of this AccountData.
+func (u AccountData) CloneAssetParams() map[AssetIndex]AssetParams {
+	res := make(map[AssetIndex]AssetParams, len(u.AssetParams))
+	for k, v := range u.AssetParams {
+		res[k] = v
+	}
+	return res
+}
+
+// MinBalance computes the minimum balance requirements for an account based
+// on some consensus parameters.  Every asset held by the account raises the
+// requirement by another proto.MinBalance.
+func (u AccountData) MinBalance(proto config.ConsensusParams) MicroAlgos {
+	return MicroAlgos{Raw: MulSaturate(proto.MinBalance, 1+uint64(len(u.Assets)))}
+}
+
 // Money returns the amount of MicroAlgos associated with the user's account
 func (u AccountData) Money(proto config.ConsensusParams, rewardsLevel uint64) (money MicroAlgos, rewards MicroAlgos) {
 	e := u.WithUpdatedRewards(proto, rewardsLevel)
@@ -61,6 +61,23 @@ type (
 		// Genesis hash to which this block belongs.
 		GenesisHash crypto.Digest `codec:"gh"`
 
+		// TxnCounter counts the number of transactions committed in the
+		// ledger, from the time at which support for this feature was
+		// introduced.
+		//
+		// Specifically, TxnCounter is the number of the next transaction
+		// that will be committed after this block.  It is 0 when no
+		// transactions have ever been committed (since TxnCounter
+		// started being supported).
+		TxnCounter uint64 `codec:"tc"`
+
+		// CatchpointHash commits to the catchpoint MaxBalLookback
+		// rounds earlier, if that round is a catchpoint round: the
+		// hash of its block header and of the account state that a
+		// node can start from instead of replaying every block.
+		// It is zero in every other block.
+		CatchpointHash crypto.Digest `codec:"cph"`
+
 		// Rewards.
 		//
 		// When a block is applied, some amount of rewards are accrued to
@@ -60,6 +60,11 @@ type Genesis struct {
 
 	// Arbitrary genesis comment string - will be excluded from file if empty
 	Comment string `codec:"comment"`
+
+	// DevMode makes nodes of this network skip agreement and seal a block
+	// as soon as they receive a transaction.  It is meant for single-node
+	// development and test networks.
+	DevMode bool `codec:"devmode"`
 }
 
 // LoadGenesisFromFile attempts to load a Genesis structure from a (presumably) genesis.json file.
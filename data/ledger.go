@@ -195,20 +195,33 @@ func (l *Ledger) BalanceRecord(r basics.Round, addr basics.Address) (basics.Bala
 	}, nil
 }
 
+// AuthAddr returns the address whose key authorizes the transactions of
+// addr as of the latest round, or zero if addr has not been rekeyed.
+func (l *Ledger) AuthAddr(addr basics.Address) (basics.Address, error) {
+	data, err := l.LookupWithoutRewards(l.Latest(), addr)
+	if err != nil {
+		return basics.Address{}, err
+	}
+	return data.AuthAddr, nil
+}
+
 // BalanceAndStatus returns Balance and DelegationStatus as one call
 func (l *Ledger) BalanceAndStatus(addr basics.Address) (money basics.MicroAlgos, rewards basics.MicroAlgos, moneyWithoutPendingRewards basics.MicroAlgos, status basics.Status, latest basics.Round, err error) {
 	latest = l.Latest()
-	data, err := l.Lookup(latest, addr)
-	if err != nil {
-		return
-	}
+	money, rewards, moneyWithoutPendingRewards, status, err = l.BalanceAndStatusAt(addr, latest)
+	return
+}
 
-	totals, err := l.Totals(latest)
+// BalanceAndStatusAt is like BalanceAndStatus, but returns the balance and
+// status of the account as of round rnd.  Rounds that the accounts tracker
+// no longer keeps in memory require the ledger to record account history.
+func (l *Ledger) BalanceAndStatusAt(addr basics.Address, rnd basics.Round) (money basics.MicroAlgos, rewards basics.MicroAlgos, moneyWithoutPendingRewards basics.MicroAlgos, status basics.Status, err error) {
+	data, err := l.Lookup(rnd, addr)
 	if err != nil {
 		return
 	}
 
-	hdr, err := l.BlockHdr(latest)
+	hdr, err := l.BlockHdr(rnd)
 	if err != nil {
 		return
 	}
@@ -217,10 +230,10 @@ func (l *Ledger) BalanceAndStatus(addr basics.Address) (money basics.MicroAlgos,
 		err = ledger.ProtocolError(hdr.CurrentProtocol)
 	}
 
-	money, rewards = data.Money(proto, totals.RewardsLevel)
+	money, rewards = data.Money(proto, hdr.RewardsLevel)
 	status = data.Status
 
-	dataWithoutRewards, err := l.LookupWithoutRewards(latest, addr)
+	dataWithoutRewards, err := l.LookupWithoutRewards(rnd, addr)
 	if err != nil {
 		return
 	}
@@ -343,19 +356,19 @@ func (l *Ledger) EnsureBlock(block *bookkeeping.Block, c agreement.Certificate)
 
 // AssemblePayset adds transactions to a BlockEvaluator.
 func (*Ledger) AssemblePayset(pool *pools.TransactionPool, eval *ledger.BlockEvaluator, deadline time.Time) (stats telemetryspec.AssembleBlockStats) {
-	pending := pool.PendingUnsorted()
-	pheap := txnHeap{make([]*transactions.SignedTxn, 0, len(pending))}
+	pending := pool.PendingTxGroups()
+	pheap := txGroupHeap{make([]txGroupHeapEntry, 0, len(pending))}
 	for i := range pending {
-		pheap.Add(&pending[i])
+		pheap.Add(pending[i])
+		stats.StartCount += len(pending[i])
 	}
-	stats.StartCount = len(pending)
 	stats.StopReason = telemetryspec.AssembleBlockEmpty
 	first := true
 	totalFees := uint64(0)
 
 	for true {
-		txn := pheap.Next()
-		if txn == nil {
+		txgroup := pheap.Next()
+		if txgroup == nil {
 			break
 		}
 		if time.Now().After(deadline) {
@@ -363,7 +376,12 @@ func (*Ledger) AssemblePayset(pool *pools.TransactionPool, eval *ledger.BlockEva
 			break
 		}
 
-		err := eval.Transaction(*txn, nil)
+		txgroupad := make([]transactions.SignedTxnWithAD, len(txgroup))
+		for i, txn := range txgroup {
+			txgroupad[i].SignedTxn = txn
+		}
+
+		err := eval.TransactionGroup(txgroupad)
 		if err == ledger.ErrNoSpace {
 			stats.StopReason = telemetryspec.AssembleBlockFull
 			break
@@ -378,21 +396,28 @@ func (*Ledger) AssemblePayset(pool *pools.TransactionPool, eval *ledger.BlockEva
 			case ledger.TransactionInLedgerError:
 				logAt = logging.Base().Debug
 				stats.CommittedCount++
+			case ledger.LeaseInLedgerError:
+				logAt = logging.Base().Info
+				pool.Remove(txgroup[0].ID(), err)
+				stats.InvalidCount++
 			case transactions.MinFeeError:
 				logAt = logging.Base().Info
-				pool.Remove(txn.ID(), err)
+				pool.Remove(txgroup[0].ID(), err)
 				stats.InvalidCount++
 			default:
 				// logAt = Warn
-				pool.Remove(txn.ID(), err)
+				pool.Remove(txgroup[0].ID(), err)
 				stats.InvalidCount++
 			}
 
 			logAt(msg)
-		} else {
+			continue
+		}
+
+		for _, txn := range txgroup {
 			fee := txn.Txn.Fee.Raw
 			encodedLen := txn.GetEncodedLength()
-			priority := uint64(txn.PtrPriority())
+			priority := uint64(txn.Priority())
 
 			stats.IncludedCount++
 			totalFees += fee
@@ -432,29 +457,42 @@ func (*Ledger) AssemblePayset(pool *pools.TransactionPool, eval *ledger.BlockEva
 	return
 }
 
-type txnHeap struct {
-	they []*transactions.SignedTxn
+// txGroupHeapEntry is a pending transaction group, along with its priority,
+// which is the priority of its lowest-priority member.
+type txGroupHeapEntry struct {
+	txgroup  []transactions.SignedTxn
+	priority transactions.TxnPriority
 }
 
-func (th *txnHeap) Add(stxn *transactions.SignedTxn) {
-	heap.Push(th, stxn)
+type txGroupHeap struct {
+	they []txGroupHeapEntry
+}
+
+func (th *txGroupHeap) Add(txgroup []transactions.SignedTxn) {
+	priority := txgroup[0].Priority()
+	for _, txn := range txgroup[1:] {
+		if txn.Priority().LessThan(priority) {
+			priority = txn.Priority()
+		}
+	}
+	heap.Push(th, txGroupHeapEntry{txgroup: txgroup, priority: priority})
 }
 
-func (th *txnHeap) Next() *transactions.SignedTxn {
+func (th *txGroupHeap) Next() []transactions.SignedTxn {
 	if len(th.they) == 0 {
 		return nil
 	}
 	out := heap.Pop(th)
-	return out.(*transactions.SignedTxn)
+	return out.(txGroupHeapEntry).txgroup
 }
 
 // Push implements heap.Interface
-func (th *txnHeap) Push(x interface{}) {
-	th.they = append(th.they, x.(*transactions.SignedTxn))
+func (th *txGroupHeap) Push(x interface{}) {
+	th.they = append(th.they, x.(txGroupHeapEntry))
 }
 
 // Pop implements heap.Interface
-func (th *txnHeap) Pop() interface{} {
+func (th *txGroupHeap) Pop() interface{} {
 	lasti := len(th.they) - 1
 	out := th.they[lasti]
 	th.they = th.they[:lasti]
@@ -463,22 +501,22 @@ func (th *txnHeap) Pop() interface{} {
 
 // Len is the number of elements in the collection.
 // heap.Interface sort.Interface
-func (th *txnHeap) Len() int {
+func (th *txGroupHeap) Len() int {
 	return len(th.they)
 }
 
 // Less reports whether the element with
 // index i should sort before the element with index j.
 // heap.Interface sort.Interface
-func (th *txnHeap) Less(i, j int) bool {
+func (th *txGroupHeap) Less(i, j int) bool {
 	// "container/heap" natural sort is least first.
 	// Reverse that to return highest Priority first by checking for (j < i)
-	return th.they[j].PtrPriority().LessThan(th.they[i].PtrPriority())
+	return th.they[j].priority.LessThan(th.they[i].priority)
 }
 
 // Swap swaps the elements with indexes i and j.
 // heap.Interface sort.Interface
-func (th *txnHeap) Swap(i, j int) {
+func (th *txGroupHeap) Swap(i, j int) {
 	t := th.they[i]
 	th.they[i] = th.they[j]
 	th.they[j] = t
@@ -40,10 +40,21 @@ type pendingTransactions struct {
 type accountsToPendingTransactions map[basics.Address]pendingTransactions
 
 func (algosPendingSpend accountsToPendingTransactions) deductionsWithTransaction(tx transactions.Transaction) (accountDeductions, error) {
-	// get the spender's account
-	addr := tx.Src()
-	pending := algosPendingSpend[addr]
+	return algosPendingSpend[tx.Src()].deductionsWithTransaction(tx)
+}
+
+// deductionsReplacingTransaction is like deductionsWithTransaction, but
+// computes the deductions as if the pending transaction replaced, from the
+// same sender, had been removed first.
+func (algosPendingSpend accountsToPendingTransactions) deductionsReplacingTransaction(tx transactions.Transaction, replaced transactions.Transaction) (accountDeductions, error) {
+	pending, err := algosPendingSpend[replaced.Src()].withoutTransaction(replaced)
+	if err != nil {
+		return accountDeductions{}, err
+	}
+	return pending.deductionsWithTransaction(tx)
+}
 
+func (pending pendingTransactions) deductionsWithTransaction(tx transactions.Transaction) (accountDeductions, error) {
 	// cannot close account when that account has pending transactions
 	if pending.txids != nil && tx.CloseRemainderTo != (basics.Address{}) {
 		return pending.deductions, fmt.Errorf("cannot close account while transactions are pending")
@@ -127,3 +138,40 @@ func (algosPendingSpend accountsToPendingTransactions) remove(tx transactions.Tr
 
 	return nil
 }
+
+// withoutTransaction returns the pending transactions of an account as they
+// would be once tx is removed, leaving pending itself unchanged.  The txids
+// of the result are nil if no transaction would be left pending.
+func (pending pendingTransactions) withoutTransaction(tx transactions.Transaction) (pendingTransactions, error) {
+	amount, closed, err := tx.SenderDeduction()
+	if err != nil {
+		return pending, err
+	}
+
+	// subtract the fee and amount of the transaction from their balance of deductions pending spend
+	// if the transaction was closing the account, undo the close
+	var ot basics.OverflowTracker
+	res := pendingTransactions{deductions: pending.deductions}
+	res.deductions.amount = ot.SubA(pending.deductions.amount, amount)
+	if closed {
+		res.deductions.close = false
+	}
+
+	// copy every other transaction
+	for txid := range pending.txids {
+		if txid == tx.ID() {
+			continue
+		}
+		if res.txids == nil {
+			res.txids = make(map[transactions.Txid]bool)
+		}
+		res.txids[txid] = true
+	}
+
+	// return an error if the operation overflowed, this happens only if there is a bug in accounting transactions
+	if ot.Overflowed {
+		return res, fmt.Errorf("overflowed while removing transaction %v that was pending spend", tx)
+	}
+
+	return res, nil
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/algorand/go-deadlock"
 
 	"github.com/algorand/go-algorand/config"
+	"github.com/algorand/go-algorand/crypto"
 	"github.com/algorand/go-algorand/data/basics"
 	"github.com/algorand/go-algorand/data/bookkeeping"
 	"github.com/algorand/go-algorand/data/transactions"
@@ -31,11 +32,14 @@ import (
 	"github.com/algorand/go-algorand/logging/telemetryspec"
 )
 
-// Ledger allows retrieving the amount of spendable MicroAlgos
-// and also checking if a transaction has already been committed.
+// Ledger allows retrieving the amount of spendable MicroAlgos and the
+// spending key of an account, and also checking if a transaction has
+// already been committed, or if its lease is held by a committed transaction.
 type Ledger interface {
 	BalanceAndStatus(basics.Address) (basics.MicroAlgos, basics.MicroAlgos, basics.MicroAlgos, basics.Status, basics.Round, error)
+	AuthAddr(basics.Address) (basics.Address, error)
 	Committed(transactions.SignedTxn) (bool, error)
+	Leased(transactions.SignedTxn) (bool, error)
 	ConsensusParams(basics.Round) (config.ConsensusParams, error)
 	BlockHdr(rnd basics.Round) (blk bookkeeping.BlockHeader, err error)
 	LastRound() basics.Round
@@ -47,6 +51,9 @@ type TransactionPool struct {
 	mu                              deadlock.RWMutex
 	txPriorityQueue                 *txPriorityQueue
 	pendingTxns                     map[transactions.Txid]transactions.SignedTxn // note: digests do not include signatures to reduce spam
+	pendingTxGroups                 map[crypto.Digest][]transactions.Txid        // members of each pending transaction group, in group order
+	pendingLeases                   map[transactions.Txlease]transactions.Txid   // pending transaction holding each lease
+	pendingReplaceable              map[txReplaceKey]transactions.Txid           // pending transaction without a group holding each replacement key
 	expiredTxCount                  map[basics.Round]int
 	exponentialPriorityGrowthFactor uint64
 	algosPendingSpend               accountsToPendingTransactions
@@ -54,6 +61,11 @@ type TransactionPool struct {
 	statusCache                     *statusCache
 	logStats                        bool
 	size                            int
+	maxPendingPerSender             int
+	replaceByNote                   bool
+	feePerByte                      uint64
+	pressureFeeCap                  uint64
+	eventListeners                  []TxEventListener
 }
 
 // MakeTransactionPool is the constructor, it uses Ledger to ensure that no account has pending transactions that together overspend.
@@ -63,10 +75,18 @@ type TransactionPool struct {
 //
 // The pool also contains status information for the last transactionPoolSize
 // transactions that were removed from the pool without being committed.
+//
+// A pending transaction that sets no group can be replaced by another one
+// with the same lease, as long as the new transaction pays at least
+// minReplacementFeeBump percent more in fees.  See SetReplaceByNote for
+// replacing transactions that set no lease.
 func MakeTransactionPool(ledger Ledger, exponentialPriorityGrowthFactor uint64, transactionPoolSize int, logStats bool) *TransactionPool {
 	pool := TransactionPool{
 		txPriorityQueue:                 makeTxPriorityQueue(transactionPoolSize),
 		pendingTxns:                     make(map[transactions.Txid]transactions.SignedTxn),
+		pendingTxGroups:                 make(map[crypto.Digest][]transactions.Txid),
+		pendingLeases:                   make(map[transactions.Txlease]transactions.Txid),
+		pendingReplaceable:              make(map[txReplaceKey]transactions.Txid),
 		expiredTxCount:                  make(map[basics.Round]int),
 		exponentialPriorityGrowthFactor: exponentialPriorityGrowthFactor,
 		algosPendingSpend:               make(map[basics.Address]pendingTransactions),
@@ -78,6 +98,115 @@ func MakeTransactionPool(ledger Ledger, exponentialPriorityGrowthFactor uint64,
 	return &pool
 }
 
+// SetMaxPendingPerSender limits the number of transactions each sender may
+// have pending in the pool.  A limit of 0 means no limit.
+func (pool *TransactionPool) SetMaxPendingPerSender(limit int) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	pool.maxPendingPerSender = limit
+}
+
+// SetReplaceByNote sets whether a pending transaction that sets neither a
+// lease nor a group can be replaced by another one with the same sender,
+// first valid round and (non-empty) note.  It is off by default: unlike a
+// lease, a note is not meant to make transactions exclusive, and senders
+// often reuse the same note for distinct transactions.
+func (pool *TransactionPool) SetReplaceByNote(enabled bool) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	pool.replaceByNote = enabled
+}
+
+// AdmissionParams describes what the pool currently requires of a new
+// transaction to accept it.
+type AdmissionParams struct {
+	// FeePerByte is the lowest fee per byte of encoded transaction that the
+	// pool currently accepts.  It rises while the pool stays more than half
+	// full, up to MinTxnFee, and is at least what it takes to evict a
+	// transaction from a full pool.
+	FeePerByte uint64
+
+	// PressureFeeCap is the most that the rise of FeePerByte while the pool
+	// stays busy requires a transaction to pay, whatever its size.  Evicting
+	// a transaction from a full pool may still take more.
+	PressureFeeCap uint64
+
+	// ReplacementFeeBump is the percentage by which the fee of a transaction
+	// must exceed the fee of the pending transaction it replaces.
+	ReplacementFeeBump uint64
+
+	// MaxPendingPerSender is the number of transactions each sender may have
+	// pending in the pool, or 0 if there is no limit.
+	MaxPendingPerSender int
+}
+
+// AdmissionParams returns the current admission requirements of the pool.
+func (pool *TransactionPool) AdmissionParams() AdmissionParams {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	feePerByte := pool.feePerByte
+	if len(pool.pendingTxns) > 0 && len(pool.pendingTxns) >= pool.size {
+		_, minPriority := pool.txPriorityQueue.getMin()
+		evictFeePerByte := minPriority.Mul(pool.exponentialPriorityGrowthFactor).FeePerByte()
+		if evictFeePerByte > feePerByte {
+			feePerByte = evictFeePerByte
+		}
+	}
+
+	return AdmissionParams{
+		FeePerByte:          feePerByte,
+		PressureFeeCap:      pool.pressureFeeCap,
+		ReplacementFeeBump:  minReplacementFeeBump,
+		MaxPendingPerSender: pool.maxPendingPerSender,
+	}
+}
+
+// txReplaceKey identifies a transaction for the purpose of replace-by-fee:
+// a new transaction replaces the pending one with the same key.  The key
+// of a transaction that sets a lease is its sender and lease; otherwise, it
+// is its sender, first valid round and note.
+type txReplaceKey struct {
+	sender     basics.Address
+	lease      [32]byte
+	firstValid basics.Round
+	note       crypto.Digest
+}
+
+// replaceKeyOf returns the replacement key of tx, and false if tx can
+// neither replace nor be replaced: because it belongs to a group, or sets
+// no lease and either has an empty note or byNote is false.
+func replaceKeyOf(tx transactions.Transaction, byNote bool) (txReplaceKey, bool) {
+	if !tx.Group.IsZero() {
+		return txReplaceKey{}, false
+	}
+	if tx.Lease != ([32]byte{}) {
+		return txReplaceKey{sender: tx.Sender, lease: tx.Lease}, true
+	}
+	if !byNote || len(tx.Note) == 0 {
+		return txReplaceKey{}, false
+	}
+	return txReplaceKey{sender: tx.Sender, firstValid: tx.FirstValid, note: crypto.Hash(tx.Note)}, true
+}
+
+// minReplacementFeeBump is the percentage by which the fee of a transaction
+// must exceed the fee of the pending transaction it replaces.
+const minReplacementFeeBump = 10
+
+// pressureFeeCapFactor bounds the fee that the pool pressure requires of a
+// transaction, as a multiple of MinTxnFee.
+const pressureFeeCapFactor = 10
+
+// replacementFee returns the smallest fee that replaces a pending
+// transaction paying fee.
+func replacementFee(fee basics.MicroAlgos) basics.MicroAlgos {
+	bump := fee.Raw/100*minReplacementFeeBump + (fee.Raw%100*minReplacementFeeBump+99)/100
+	if bump == 0 {
+		bump = 1
+	}
+	return basics.MicroAlgos{Raw: fee.Raw + bump}
+}
+
 // TODO I moved this number to be a constant in the module, we should consider putting it in the local config
 const expiredHistory = 10
 
@@ -103,33 +232,69 @@ func (pool *TransactionPool) PendingTxIDs() []transactions.Txid {
 	return ids
 }
 
-// Pending returns an array of transactions valid for the given round, sorted by priority in decreasing order
+// Pending returns an array of transactions valid for the given round, sorted by priority in decreasing order.
+// Members of a transaction group are returned together, in group order, ranked by the group's
+// lowest-priority member.
 // If no txns, returns empty slice.
 func (pool *TransactionPool) Pending() []transactions.SignedTxn {
-	pool.mu.Lock()
+	txgroups := pool.PendingTxGroups()
 
-	txns := make([]transactions.SignedTxn, len(pool.pendingTxns))
-	sorti := make([]int, len(pool.pendingTxns))
-	i := 0
-	for _, txn := range pool.pendingTxns {
-		txns[i] = txn
+	priorities := make([]transactions.TxnPriority, len(txgroups))
+	sorti := make([]int, len(txgroups))
+	for i := range txgroups {
+		priorities[i] = txGroupPriority(txgroups[i])
 		sorti[i] = i
-		i++
 	}
 
-	pool.mu.Unlock()
-
 	// TODO: return unsorted pending, let calling code sort or not as needed, or make a heap, or whatever
 	sort.SliceStable(sorti, func(i, j int) bool {
-		return txns[sorti[j]].PtrPriority().LessThan(txns[sorti[i]].PtrPriority())
+		return priorities[sorti[j]].LessThan(priorities[sorti[i]])
 	})
-	out := make([]transactions.SignedTxn, len(pool.pendingTxns))
-	for i, p := range sorti {
-		out[i] = txns[p]
+
+	out := make([]transactions.SignedTxn, 0, len(txgroups))
+	for _, p := range sorti {
+		out = append(out, txgroups[p]...)
 	}
 	return out
 }
 
+// PendingTxGroups returns the pending transactions, split into the
+// transaction groups they were remembered with, in no particular order.
+// Transactions that are not part of a group are returned as a group of one.
+func (pool *TransactionPool) PendingTxGroups() [][]transactions.SignedTxn {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	txgroups := make([][]transactions.SignedTxn, 0, len(pool.pendingTxns))
+	for _, txn := range pool.pendingTxns {
+		if txn.Txn.Group.IsZero() {
+			txgroups = append(txgroups, []transactions.SignedTxn{txn})
+		}
+	}
+
+	for _, txids := range pool.pendingTxGroups {
+		txgroup := make([]transactions.SignedTxn, len(txids))
+		for i, txid := range txids {
+			txgroup[i] = pool.pendingTxns[txid]
+		}
+		txgroups = append(txgroups, txgroup)
+	}
+
+	return txgroups
+}
+
+// txGroupPriority returns the priority of a transaction group, which is
+// the priority of its lowest-priority member.
+func txGroupPriority(txgroup []transactions.SignedTxn) transactions.TxnPriority {
+	priority := txgroup[0].Priority()
+	for _, txn := range txgroup[1:] {
+		if txn.Priority().LessThan(priority) {
+			priority = txn.Priority()
+		}
+	}
+	return priority
+}
+
 // PendingUnsorted returns an array of transactions valid for the given round.
 // If no txns, returns empty slice.
 func (pool *TransactionPool) PendingUnsorted() []transactions.SignedTxn {
@@ -152,93 +317,389 @@ func (pool *TransactionPool) PendingCount() int {
 	return len(pool.pendingTxns)
 }
 
-// Test checks whether a transaction could be remembered in the pool, but does not actually store this transaction
-// in the pool
-func (pool *TransactionPool) Test(t transactions.SignedTxn) error {
-	t.InitCaches()
+// Test performs basic duplicate detection and well-formedness checks
+// on a transaction group, but does not actually store the group in the pool.
+// Each member is checked on its own, so the pending spend of earlier
+// members of the group is not taken into account.
+func (pool *TransactionPool) Test(txgroup []transactions.SignedTxn) error {
+	for i := range txgroup {
+		txgroup[i].InitCaches()
+	}
 
 	pool.mu.RLock()
 	defer pool.mu.RUnlock()
 
-	_, _, _, err := pool.test(t)
-	return err
+	err := pool.checkTxGroup(txgroup)
+	if err != nil {
+		return err
+	}
+
+	if replaced, ok := pool.replacementFor(txgroup); ok {
+		err = pool.checkReplacement(txgroup[0], replaced)
+		if err != nil {
+			return err
+		}
+		_, err = pool.test(txgroup[0], replaced.ID())
+		return err
+	}
+
+	err = pool.checkSufficientPriority(txgroup)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range txgroup {
+		_, err = pool.test(t, transactions.Txid{})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
-func (pool *TransactionPool) test(t transactions.SignedTxn) (accountDeductions, bool, transactions.Txid, error) {
-	// check if we already have this transaction in the pool.
-	if _, has := pool.pendingTxns[t.ID()]; has {
-		return accountDeductions{}, false, transactions.Txid{}, errors.New("TransactionPool.test: transaction already in the pool")
+// checkTxGroup checks that txgroup is a complete transaction group: either a
+// single transaction with no Group value, or transactions that all carry the
+// same Group value, which commits to exactly this list of transactions.
+func (pool *TransactionPool) checkTxGroup(txgroup []transactions.SignedTxn) error {
+	if len(txgroup) == 0 {
+		return errors.New("TransactionPool.checkTxGroup: empty transaction group")
+	}
+
+	if len(txgroup) == 1 && txgroup[0].Txn.Group.IsZero() {
+		return nil
+	}
+
+	proto, err := pool.ledger.ConsensusParams(pool.ledger.LastRound())
+	if err != nil {
+		return err
+	}
+
+	if len(txgroup) > proto.MaxTxGroupSize {
+		return fmt.Errorf("TransactionPool.checkTxGroup: group size %d exceeds maximum %d", len(txgroup), proto.MaxTxGroupSize)
 	}
 
-	var minTransactionID transactions.Txid
-	isFull := len(pool.pendingTxns) >= pool.size
+	var group transactions.TxGroup
+	for i, t := range txgroup {
+		if t.Txn.Group.IsZero() {
+			return fmt.Errorf("TransactionPool.checkTxGroup: [%d] had zero Group but was submitted in a group of %d", i, len(txgroup))
+		}
+
+		if t.Txn.Group != txgroup[0].Txn.Group {
+			return fmt.Errorf("TransactionPool.checkTxGroup: inconsistent group values: %v != %v", t.Txn.Group, txgroup[0].Txn.Group)
+		}
+
+		txWithoutGroup := t.Txn
+		txWithoutGroup.Group = crypto.Digest{}
+		txWithoutGroup.ResetCaches()
+		group.TxGroupHashes = append(group.TxGroupHashes, crypto.Digest(txWithoutGroup.ID()))
+	}
+
+	if crypto.HashObj(group) != txgroup[0].Txn.Group {
+		return fmt.Errorf("TransactionPool.checkTxGroup: incomplete group: %v != %v", txgroup[0].Txn.Group, crypto.HashObj(group))
+	}
 
-	if isFull {
-		// transaction pool is full.
-		var minPriority transactions.TxnPriority
-		minTransactionID, minPriority = pool.txPriorityQueue.getMin()
+	return nil
+}
+
+// checkSufficientPriority checks that the pool either has room for the
+// transaction group, or that every member of the group has a high enough
+// priority to evict the lowest-priority transaction currently in the pool.
+func (pool *TransactionPool) checkSufficientPriority(txgroup []transactions.SignedTxn) error {
+	if len(pool.pendingTxns)+len(txgroup) <= pool.size {
+		return nil
+	}
 
+	_, minPriority := pool.txPriorityQueue.getMin()
+	for _, t := range txgroup {
 		if t.Priority().LessThan(minPriority.Mul(pool.exponentialPriorityGrowthFactor)) {
-			return accountDeductions{}, isFull, transactions.Txid{}, fmt.Errorf("TransactionPool.test: transaction pool is full and tx priority too low: min in pool %v vs. %v", minPriority, t.Priority())
+			return fmt.Errorf("TransactionPool.test: transaction pool is full and tx priority too low: min in pool %v vs. %v", minPriority, t.Priority())
+		}
+	}
+
+	return nil
+}
+
+// replacementFor returns the pending transaction that txgroup would replace,
+// and false if txgroup does not replace one.  Only a single transaction
+// without a group replaces a pending transaction, and only one that is not
+// part of a group either, since only those have a replacement key.
+func (pool *TransactionPool) replacementFor(txgroup []transactions.SignedTxn) (transactions.SignedTxn, bool) {
+	if len(txgroup) != 1 {
+		return transactions.SignedTxn{}, false
+	}
+	t := txgroup[0]
+
+	key, ok := replaceKeyOf(t.Txn, pool.replaceByNote)
+	if !ok {
+		return transactions.SignedTxn{}, false
+	}
+	holder, has := pool.pendingReplaceable[key]
+	if !has || holder == t.ID() {
+		return transactions.SignedTxn{}, false
+	}
+	return pool.pendingTxns[holder], true
+}
+
+// checkReplacement checks that t pays enough to replace the pending
+// transaction replaced.
+func (pool *TransactionPool) checkReplacement(t transactions.SignedTxn, replaced transactions.SignedTxn) error {
+	minFee := replacementFee(replaced.Txn.Fee)
+	if t.Txn.Fee.LessThan(minFee) {
+		return fmt.Errorf("TransactionPool.checkReplacement: transaction with ID %v replaces pending transaction %v and must pay a fee of at least %d, not %d", t.ID(), replaced.ID(), minFee.Raw, t.Txn.Fee.Raw)
+	}
+	return nil
+}
+
+// checkAuthorizer checks that t is authorized by the current spending key
+// of its sender, or by a key that a pending transaction of the sender
+// rekeys it to.  Otherwise anyone could take up the pending spend, the
+// leases and the pool share of any sender until the block evaluator
+// rejects the transaction.
+func (pool *TransactionPool) checkAuthorizer(t transactions.SignedTxn) error {
+	authAddr, err := pool.ledger.AuthAddr(t.Txn.Sender)
+	if err != nil {
+		return fmt.Errorf("TransactionPool.test: failed to look up the spending key of %v: %v", t.Txn.Sender, err)
+	}
+	if authAddr == (basics.Address{}) {
+		authAddr = t.Txn.Sender
+	}
+
+	authorizer := t.Authorizer()
+	if authorizer == authAddr {
+		return nil
+	}
+	for txid := range pool.algosPendingSpend[t.Txn.Sender].txids {
+		if pool.pendingTxns[txid].Txn.RekeyTo == authorizer {
+			return nil
 		}
 	}
+	return fmt.Errorf("TransactionPool.test: transaction %v should have been authorized by %v but was actually authorized by %v", t.ID(), authAddr, authorizer)
+}
+
+// test checks whether t may be added to the pool, and returns the pending
+// spend of its sender with t added.  If replacing is not zero, t is checked
+// as a replacement of that pending transaction.
+func (pool *TransactionPool) test(t transactions.SignedTxn, replacing transactions.Txid) (accountDeductions, error) {
+	// check if we already have this transaction in the pool.
+	if _, has := pool.pendingTxns[t.ID()]; has {
+		return accountDeductions{}, errors.New("TransactionPool.test: transaction already in the pool")
+	}
 
 	// check if we have committed the transaction recently already
 	committed, err := pool.ledger.Committed(t)
 	if err != nil {
-		return accountDeductions{}, isFull, transactions.Txid{}, fmt.Errorf("TransactionPool.test: failed to call Committed(): %v", err)
+		return accountDeductions{}, fmt.Errorf("TransactionPool.test: failed to call Committed(): %v", err)
 	}
 	if committed {
-		return accountDeductions{}, isFull, transactions.Txid{}, fmt.Errorf("TransactionPool.test: transaction with ID %v has already been committed", t.ID())
+		return accountDeductions{}, fmt.Errorf("TransactionPool.test: transaction with ID %v has already been committed", t.ID())
 	}
 
-	// compute the deductions following this transaction
-	deductions, err := pool.computeDeductions(t)
+	err = pool.checkAuthorizer(t)
 	if err != nil {
-		return accountDeductions{}, isFull, transactions.Txid{}, err
+		return accountDeductions{}, err
 	}
 
-	return deductions, isFull, minTransactionID, nil
+	// check if another transaction holds the same lease
+	if lease, ok := t.Txn.LeaseHeld(); ok {
+		if holder, has := pool.pendingLeases[lease]; has && holder != replacing {
+			return accountDeductions{}, fmt.Errorf("TransactionPool.test: transaction with ID %v uses the lease of pending transaction %v", t.ID(), holder)
+		}
+		leased, err := pool.ledger.Leased(t)
+		if err != nil {
+			return accountDeductions{}, fmt.Errorf("TransactionPool.test: failed to call Leased(): %v", err)
+		}
+		if leased {
+			return accountDeductions{}, fmt.Errorf("TransactionPool.test: transaction with ID %v uses the lease of a committed transaction", t.ID())
+		}
+	}
+
+	// check that the sender does not exceed its share of the pool
+	if pool.maxPendingPerSender > 0 {
+		pending := pool.algosPendingSpend[t.Txn.Src()].txids
+		count := len(pending)
+		if pending[replacing] {
+			count--
+		}
+		if count >= pool.maxPendingPerSender {
+			return accountDeductions{}, fmt.Errorf("TransactionPool.test: sender %v already has %d pending transactions", t.Txn.Src(), count)
+		}
+	}
+
+	// check that the transaction pays the fee required under the current pool pressure
+	if pool.feePerByte > 0 {
+		minFee := basics.MulSaturate(pool.feePerByte, uint64(t.GetEncodedLength()))
+		if minFee > pool.pressureFeeCap {
+			minFee = pool.pressureFeeCap
+		}
+		if t.Txn.Fee.Raw < minFee {
+			return accountDeductions{}, fmt.Errorf("TransactionPool.test: transaction fee %d is below the pool minimum of %d (%d per byte)", t.Txn.Fee.Raw, minFee, pool.feePerByte)
+		}
+	}
+
+	// compute the deductions following this transaction
+	return pool.computeDeductions(t, replacing)
+}
+
+// RememberOne stores the provided transaction, as a group of one.
+// Precondition: Only RememberOne() properly-signed and well-formed transactions (i.e., ensure t.WellFormed())
+func (pool *TransactionPool) RememberOne(t transactions.SignedTxn) error {
+	return pool.Remember([]transactions.SignedTxn{t})
 }
 
-// Remember stores the provided transaction
+// Remember stores the provided transaction group.  The group is either
+// remembered as a whole, or rejected as a whole.
 // Precondition: Only Remember() properly-signed and well-formed transactions (i.e., ensure t.WellFormed())
-func (pool *TransactionPool) Remember(t transactions.SignedTxn) error {
-	t.InitCaches()
+func (pool *TransactionPool) Remember(txgroup []transactions.SignedTxn) error {
+	for i := range txgroup {
+		txgroup[i].InitCaches()
+	}
 
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
 
-	deductions, isFull, minTransactionID, err := pool.test(t)
+	err := pool.checkTxGroup(txgroup)
+	if err != nil {
+		return fmt.Errorf("TransactionPool.Remember: %v", err)
+	}
+
+	if replaced, ok := pool.replacementFor(txgroup); ok {
+		err = pool.checkReplacement(txgroup[0], replaced)
+		if err == nil {
+			err = pool.replace(txgroup[0], replaced)
+		}
+		if err != nil {
+			return fmt.Errorf("TransactionPool.Remember: %v", err)
+		}
+		return nil
+	}
+
+	err = pool.checkSufficientPriority(txgroup)
 	if err != nil {
 		return fmt.Errorf("TransactionPool.Remember: %v", err)
 	}
 
-	if isFull {
-		// remove old transaction. we want to do that before adding the new entry to ensure we
-		// won't exceed (temporarly) the total number of pending transactions.
-		pool.remove(minTransactionID, fmt.Errorf("transaction evicted due to low priority"))
+	for i, t := range txgroup {
+		err = pool.rememberOne(t)
+		if err != nil {
+			// Forget the members of this group that we already remembered.
+			// The group is not registered yet, so each of them is removed
+			// on its own, without recording an error status.
+			for _, prev := range txgroup[:i] {
+				pool.remove(prev.ID(), nil)
+			}
+			return fmt.Errorf("TransactionPool.Remember: %v", err)
+		}
+	}
+
+	if !txgroup[0].Txn.Group.IsZero() {
+		txids := make([]transactions.Txid, len(txgroup))
+		for i, t := range txgroup {
+			txids[i] = t.ID()
+		}
+		pool.pendingTxGroups[txgroup[0].Txn.Group] = txids
+	}
+
+	for _, t := range txgroup {
+		pool.notify(TxEvent{Type: TxAdmitted, Txid: t.ID()})
+	}
+
+	// Make room for the new transactions by evicting the lowest-priority ones.
+	for len(pool.pendingTxns) > pool.size {
+		minTransactionID, _ := pool.txPriorityQueue.getMin()
+		pool.remove(minTransactionID, evictionErrorf(EvictFeeTooLow, "transaction evicted due to low priority"))
+	}
+
+	if _, has := pool.pendingTxns[txgroup[0].ID()]; !has {
+		return fmt.Errorf("TransactionPool.Remember: transaction evicted due to low priority")
+	}
+
+	return nil
+}
+
+// replace swaps the pending transaction replaced for t.  Since t takes the
+// place of replaced, the pool does not grow and nothing needs to be evicted.
+// t is checked as if replaced were already removed, and replaced is left
+// alone if t is rejected.  If t cannot be added after all, replaced is
+// restored.  The pool admitted replaced already, so it is restored without
+// checking it against the admission rules again, which may have become
+// stricter since.
+func (pool *TransactionPool) replace(t transactions.SignedTxn, replaced transactions.SignedTxn) error {
+	deductions, err := pool.test(t, replaced.ID())
+	if err != nil {
+		return err
+	}
+
+	pool.removeOne(replaced.ID(), nil)
+	err = pool.add(t, deductions)
+	if err != nil {
+		deductions, restoreErr := pool.algosPendingSpend.deductionsWithTransaction(replaced.Txn)
+		if restoreErr == nil {
+			restoreErr = pool.add(replaced, deductions)
+		}
+		if restoreErr != nil {
+			logging.Base().Warnf("TransactionPool.replace: cannot restore transaction %v: %v", replaced.ID(), restoreErr)
+			txErr := evictionErrorf(EvictReplacementFailed, "replacement by transaction %v failed (%v), and the transaction could not be restored: %v", t.ID(), err, restoreErr)
+			pool.statusCache.put(replaced, txErr.Error())
+			pool.notify(TxEvent{Type: TxEvicted, Txid: replaced.ID(), Reason: EvictReplacementFailed, Error: txErr.Error()})
+		}
+		return err
 	}
 
+	status := fmt.Sprintf("replaced by transaction %v", t.ID())
+	pool.statusCache.put(replaced, status)
+	pool.notify(TxEvent{Type: TxEvicted, Txid: replaced.ID(), Reason: EvictReplaced, Error: status, ReplacedBy: t.ID()})
+	pool.notify(TxEvent{Type: TxAdmitted, Txid: t.ID()})
+	return nil
+}
+
+// rememberOne adds a single transaction to the pool, without regard
+// to the pool size or to the transaction group it may belong to.
+func (pool *TransactionPool) rememberOne(t transactions.SignedTxn) error {
+	deductions, err := pool.test(t, transactions.Txid{})
+	if err != nil {
+		return err
+	}
+	return pool.add(t, deductions)
+}
+
+// add adds a single transaction to the pool, whose sender has the pending
+// spend deductions with t added, without checking t.
+func (pool *TransactionPool) add(t transactions.SignedTxn, deductions accountDeductions) error {
 	// push to the priority queue
 	if !pool.txPriorityQueue.Push(t) {
 		// this should never happen, since we already tested that above.
 		logging.Base().Errorf("TransactionPool.Remember: Attempted to push a transaction %v into the priority queue while it's already there", t)
-		return fmt.Errorf("TransactionPool.Remember: cannot push txn %v as it's already in the pool", t)
+		return fmt.Errorf("cannot push txn %v as it's already in the pool", t)
 	}
 
 	// we're almost done; the transaction was already saved into the priority queue. now, save the transaction
 	// into the pending transactions list.
 	pool.pendingTxns[t.ID()] = t
+	if lease, ok := t.Txn.LeaseHeld(); ok {
+		pool.pendingLeases[lease] = t.ID()
+	}
+	if key, ok := replaceKeyOf(t.Txn, pool.replaceByNote); ok {
+		pool.pendingReplaceable[key] = t.ID()
+	}
 	// last, update the spent algos from the sender account
 	pool.algosPendingSpend.accountForTransactionDeductions(t.Txn, deductions)
 
 	return nil
 }
 
-func (pool *TransactionPool) computeDeductions(t transactions.SignedTxn) (accountDeductions, error) {
+// computeDeductions returns the pending spend of the sender of t with t
+// added, and with the pending transaction replacing removed, if it is not
+// zero.  It checks that the sender and the accounts t pays can afford it.
+func (pool *TransactionPool) computeDeductions(t transactions.SignedTxn, replacing transactions.Txid) (accountDeductions, error) {
 	// compute how this transaction would affect the number of MicroAlgos pending spend by the sender
-	algosPendingSpend, err := pool.algosPendingSpend.deductionsWithTransaction(t.Txn)
+	var algosPendingSpend accountDeductions
+	var err error
+	if replaced, ok := pool.pendingTxns[replacing]; ok {
+		algosPendingSpend, err = pool.algosPendingSpend.deductionsReplacingTransaction(t.Txn, replaced.Txn)
+	} else {
+		algosPendingSpend, err = pool.algosPendingSpend.deductionsWithTransaction(t.Txn)
+	}
 
 	// make sure that the sender has the balance to cover all their pending transactions
 	if err != nil {
@@ -342,7 +803,31 @@ func (pool *TransactionPool) Verified(txn transactions.SignedTxn) bool {
 		return false
 	}
 
-	return pendingSigTxn.Sig == txn.Sig && pendingSigTxn.Msig.Equal(txn.Msig)
+	return pendingSigTxn.Sig == txn.Sig && pendingSigTxn.Msig.Equal(txn.Msig) && pendingSigTxn.Lsig.Equal(&txn.Lsig)
+}
+
+// Rebroadcast reports whether every member of txgroup is already pending,
+// with the same signatures, in which case the caller may broadcast the group
+// again.  The group is then reported as rebroadcast to the event listeners.
+func (pool *TransactionPool) Rebroadcast(txgroup []transactions.SignedTxn) bool {
+	if len(txgroup) == 0 {
+		return false
+	}
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	for _, t := range txgroup {
+		pending, ok := pool.pendingTxns[t.ID()]
+		if !ok || pending.Sig != t.Sig || !pending.Msig.Equal(t.Msig) || !pending.Lsig.Equal(&t.Lsig) {
+			return false
+		}
+	}
+
+	for _, t := range txgroup {
+		pool.notify(TxEvent{Type: TxRebroadcast, Txid: t.ID()})
+	}
+	return true
 }
 
 // OnNewBlock excises transactions from the pool that are included in the specified Block or if they've expired
@@ -369,6 +854,16 @@ func (pool *TransactionPool) OnNewBlock(block bookkeeping.Block) {
 		} else {
 			stats.UnknownCommittedCount++
 		}
+
+		// a committed transaction takes its lease away from any pending
+		// transaction that was waiting on it
+		if lease, ok := tx.Txn.LeaseHeld(); ok {
+			holder, has := pool.pendingLeases[lease]
+			if has && holder != txid {
+				remove[holder] = evictionErrorf(EvictLeaseTaken, "lease taken by committed transaction %v", txid)
+				stats.RemovedInvalidCount++
+			}
+		}
 	}
 
 	// collect expired transactions
@@ -376,7 +871,7 @@ func (pool *TransactionPool) OnNewBlock(block bookkeeping.Block) {
 	for txid, tx := range pool.pendingTxns {
 		err := tx.Txn.Alive(block)
 		if err != nil {
-			remove[txid] = err
+			remove[txid] = evictionError{reason: EvictExpired, err: err}
 			expired++
 			stats.ExpiredCount++
 		}
@@ -385,6 +880,9 @@ func (pool *TransactionPool) OnNewBlock(block bookkeeping.Block) {
 	// remove all collected transactions
 	for txid, txErr := range remove {
 		pool.remove(txid, txErr)
+		if txErr == nil {
+			pool.notify(TxEvent{Type: TxCommitted, Txid: txid, Round: block.Round()})
+		}
 	}
 
 	// remove transactions from senders in this block until everyone can spend what they have given the last block
@@ -411,7 +909,7 @@ func (pool *TransactionPool) OnNewBlock(block bookkeeping.Block) {
 			// remove transactions, beginning with the lowest priority one until the sender can spend all their pending transactions
 			for _, txid := range txids {
 				if pool.algosPendingSpend[account].deductions.amount.GreaterThan(spendable) {
-					pool.remove(txid, fmt.Errorf("pending spend %d exceeds spendable %d",
+					pool.remove(txid, evictionErrorf(EvictOverspend, "pending spend %d exceeds spendable %d",
 						pool.algosPendingSpend[account].deductions.amount.Raw, spendable.Raw))
 					stats.RemovedInvalidCount++
 				} else {
@@ -421,8 +919,26 @@ func (pool *TransactionPool) OnNewBlock(block bookkeeping.Block) {
 		}
 	}
 
-	// save exipred history and clean old statistics about expired transactions
+	// The pool-pressure fee doubles with every block after which the pool is
+	// still more than half full, and halves with every block after which it is
+	// not.  It never exceeds MinTxnFee per byte, and no transaction is required
+	// to pay more than pressureFeeCapFactor times MinTxnFee for it, so that a
+	// pool that stays busy for long does not price out every sender.
 	proto := config.Consensus[block.CurrentProtocol]
+	pool.pressureFeeCap = basics.MulSaturate(proto.MinTxnFee, pressureFeeCapFactor)
+	if len(pool.pendingTxns) > pool.size/2 {
+		pool.feePerByte = basics.MulSaturate(pool.feePerByte, 2)
+		if pool.feePerByte == 0 {
+			pool.feePerByte = 1
+		}
+		if pool.feePerByte > proto.MinTxnFee {
+			pool.feePerByte = proto.MinTxnFee
+		}
+	} else {
+		pool.feePerByte /= 2
+	}
+
+	// save exipred history and clean old statistics about expired transactions
 	pool.expiredTxCount[block.Round()] = expired
 	delete(pool.expiredTxCount, block.Round()-expiredHistory*basics.Round(proto.MaxTxnLife))
 
@@ -449,15 +965,44 @@ func (pool *TransactionPool) remove(txid transactions.Txid, txErr error) {
 	if !has {
 		return
 	}
+
+	// Transactions in a group are removed together.
+	if !tx.Txn.Group.IsZero() {
+		txids, ok := pool.pendingTxGroups[tx.Txn.Group]
+		if ok {
+			delete(pool.pendingTxGroups, tx.Txn.Group)
+			for _, id := range txids {
+				pool.removeOne(id, txErr)
+			}
+			return
+		}
+	}
+
+	pool.removeOne(txid, txErr)
+}
+
+func (pool *TransactionPool) removeOne(txid transactions.Txid, txErr error) {
+	tx, has := pool.pendingTxns[txid]
+	if !has {
+		return
+	}
 	if err := pool.algosPendingSpend.remove(tx.Txn); err != nil {
 		logging.Base().Errorf("TransactionPool::remove: %v", err)
 	}
 	pool.txPriorityQueue.Remove(txid)
 	delete(pool.pendingTxns, txid)
+	if lease, ok := tx.Txn.LeaseHeld(); ok {
+		delete(pool.pendingLeases, lease)
+	}
+	// The key may have been added before replacing by note was disabled.
+	if key, ok := replaceKeyOf(tx.Txn, true); ok && pool.pendingReplaceable[key] == txid {
+		delete(pool.pendingReplaceable, key)
+	}
 
 	// If the transaction was removed due to an error (instead of being
 	// committed to the ledger), remember the error in the statusCache.
 	if txErr != nil {
 		pool.statusCache.put(tx, txErr.Error())
+		pool.notify(TxEvent{Type: TxEvicted, Txid: txid, Reason: evictionReason(txErr), Error: txErr.Error()})
 	}
 }
@@ -43,6 +43,7 @@ func keypair() *crypto.SignatureSecrets {
 type mockSpendableBalancesUnbounded struct {
 	balance    uint64
 	exceptions map[basics.Address]uint64
+	authAddrs  map[basics.Address]basics.Address
 }
 
 func (b mockSpendableBalancesUnbounded) BalanceAndStatus(address basics.Address) (total basics.MicroAlgos, rewards basics.MicroAlgos, totalWithoutPendingRewards basics.MicroAlgos, status basics.Status, round basics.Round, err error) {
@@ -58,14 +59,22 @@ func (b mockSpendableBalancesUnbounded) BalanceAndStatus(address basics.Address)
 	return
 }
 
+func (b mockSpendableBalancesUnbounded) AuthAddr(address basics.Address) (basics.Address, error) {
+	return b.authAddrs[address], nil
+}
+
 func (b mockSpendableBalancesUnbounded) Committed(transactions.SignedTxn) (bool, error) {
 	return false, nil
 }
 
+func (b mockSpendableBalancesUnbounded) Leased(transactions.SignedTxn) (bool, error) {
+	return false, nil
+}
+
 const mockBalancesMinBalance = 1000
 
 func (b mockSpendableBalancesUnbounded) ConsensusParams(basics.Round) (config.ConsensusParams, error) {
-	return config.ConsensusParams{MinBalance: mockBalancesMinBalance}, nil
+	return config.ConsensusParams{MinBalance: mockBalancesMinBalance, MaxTxGroupSize: config.Consensus[protocol.ConsensusFuture].MaxTxGroupSize}, nil
 }
 
 func (b mockSpendableBalancesUnbounded) BlockHdr(basics.Round) (bookkeeping.BlockHeader, error) {
@@ -113,7 +122,7 @@ func TestMinBalanceOK(t *testing.T) {
 		},
 	}
 	signedTx := tx.Sign(secrets[0])
-	require.NoError(t, transactionPool.Remember(signedTx))
+	require.NoError(t, transactionPool.RememberOne(signedTx))
 }
 
 func TestSenderGoesBelowMinBalance(t *testing.T) {
@@ -150,7 +159,7 @@ func TestSenderGoesBelowMinBalance(t *testing.T) {
 		},
 	}
 	signedTx := tx.Sign(secrets[0])
-	require.Error(t, transactionPool.Remember(signedTx))
+	require.Error(t, transactionPool.RememberOne(signedTx))
 }
 
 func TestCloseAccount(t *testing.T) {
@@ -188,7 +197,7 @@ func TestCloseAccount(t *testing.T) {
 		},
 	}
 	signedTx := closeTx.Sign(secrets[0])
-	require.NoError(t, transactionPool.Remember(signedTx))
+	require.NoError(t, transactionPool.RememberOne(signedTx))
 
 	// sender goes below min
 	tx := transactions.Transaction{
@@ -206,10 +215,10 @@ func TestCloseAccount(t *testing.T) {
 		},
 	}
 	signedTx2 := tx.Sign(secrets[0])
-	require.Error(t, transactionPool.Remember(signedTx2))
+	require.Error(t, transactionPool.RememberOne(signedTx2))
 
 	transactionPool.Remove(closeTx.ID(), fmt.Errorf("removing close account tx"))
-	require.NoError(t, transactionPool.Remember(signedTx2))
+	require.NoError(t, transactionPool.RememberOne(signedTx2))
 }
 
 func TestCloseAccountWhileTxIsPending(t *testing.T) {
@@ -246,7 +255,7 @@ func TestCloseAccountWhileTxIsPending(t *testing.T) {
 		},
 	}
 	signedTx := tx.Sign(secrets[0])
-	require.NoError(t, transactionPool.Remember(signedTx))
+	require.NoError(t, transactionPool.RememberOne(signedTx))
 
 	// sender goes below min
 	closeTx := transactions.Transaction{
@@ -265,7 +274,7 @@ func TestCloseAccountWhileTxIsPending(t *testing.T) {
 		},
 	}
 	signedCloseTx := closeTx.Sign(secrets[0])
-	require.Error(t, transactionPool.Remember(signedCloseTx))
+	require.Error(t, transactionPool.RememberOne(signedCloseTx))
 }
 
 func TestClosingAccountBelowMinBalance(t *testing.T) {
@@ -304,7 +313,7 @@ func TestClosingAccountBelowMinBalance(t *testing.T) {
 		},
 	}
 	signedTx := closeTx.Sign(secrets[0])
-	require.Error(t, transactionPool.Remember(signedTx))
+	require.Error(t, transactionPool.RememberOne(signedTx))
 }
 
 func TestRecipientGoesBelowMinBalance(t *testing.T) {
@@ -341,7 +350,7 @@ func TestRecipientGoesBelowMinBalance(t *testing.T) {
 		},
 	}
 	signedTx := tx.Sign(secrets[0])
-	require.Error(t, transactionPool.Remember(signedTx))
+	require.Error(t, transactionPool.RememberOne(signedTx))
 }
 
 func TestRememberForget(t *testing.T) {
@@ -381,7 +390,7 @@ func TestRememberForget(t *testing.T) {
 				tx.Note[0] = byte(i)
 				tx.Note[1] = byte(j)
 				signedTx := tx.Sign(secrets[i])
-				transactionPool.Remember(signedTx)
+				transactionPool.RememberOne(signedTx)
 				txib, err := block.EncodeSignedTxn(signedTx, transactions.ApplyData{})
 				require.NoError(t, err)
 				block.Payset = append(block.Payset, txib)
@@ -432,7 +441,7 @@ func TestPendingIsOrdered(t *testing.T) {
 				tx.Note[0] = byte(i)
 				tx.Note[1] = byte(j)
 				signedTx := tx.Sign(secrets[i])
-				transactionPool.Remember(signedTx)
+				transactionPool.RememberOne(signedTx)
 			}
 		}
 	}
@@ -497,7 +506,7 @@ func TestCleanUp(t *testing.T) {
 				tx.Note[0] = byte(i)
 				tx.Note[1] = byte(j)
 				signedTx := tx.Sign(secrets[i])
-				require.NoError(t, transactionPool.Remember(signedTx))
+				require.NoError(t, transactionPool.RememberOne(signedTx))
 				issuedTransactions++
 			}
 		}
@@ -577,7 +586,7 @@ func TestFixOverflowOnNewBlock(t *testing.T) {
 				}
 
 				signedTx := tx.Sign(secrets[i])
-				require.NoError(t, transactionPool.Remember(signedTx))
+				require.NoError(t, transactionPool.RememberOne(signedTx))
 				savedTransactions++
 			}
 		}
@@ -669,7 +678,7 @@ func TestExponentialPriorityGrowth(t *testing.T) {
 			},
 		}
 		signedTx := tx.Sign(secrets[0])
-		require.NoError(t, transactionPool.Remember(signedTx))
+		require.NoError(t, transactionPool.RememberOne(signedTx))
 	}
 
 	txLowFee := transactions.Transaction{
@@ -687,7 +696,7 @@ func TestExponentialPriorityGrowth(t *testing.T) {
 		},
 	}
 	signed := txLowFee.Sign(secrets[0])
-	require.Error(t, transactionPool.Remember(signed))
+	require.Error(t, transactionPool.RememberOne(signed))
 
 	txHighFee := transactions.Transaction{
 		Type: protocol.PaymentTx,
@@ -703,7 +712,7 @@ func TestExponentialPriorityGrowth(t *testing.T) {
 			Amount:   basics.MicroAlgos{Raw: 1},
 		},
 	}
-	require.NoError(t, transactionPool.Remember(txHighFee.Sign(secrets[0])))
+	require.NoError(t, transactionPool.RememberOne(txHighFee.Sign(secrets[0])))
 }
 
 func TestOverspender(t *testing.T) {
@@ -750,7 +759,7 @@ func TestOverspender(t *testing.T) {
 	balance.exceptions[overSpender] = limit.amount.Raw
 
 	// consume the transaction of allowed limit
-	require.Error(t, transactionPool.Remember(signedTx))
+	require.Error(t, transactionPool.RememberOne(signedTx))
 
 	// min transaction
 	minTx := transactions.Transaction{
@@ -768,7 +777,7 @@ func TestOverspender(t *testing.T) {
 		},
 	}
 	signedMinTx := minTx.Sign(secrets[0])
-	require.Error(t, transactionPool.Remember(signedMinTx))
+	require.Error(t, transactionPool.RememberOne(signedMinTx))
 }
 
 func TestAddError(t *testing.T) {
@@ -818,7 +827,7 @@ func TestAddError(t *testing.T) {
 	}
 
 	// overflow accounting, and result in error
-	require.Error(t, transactionPool.Remember(signedTx))
+	require.Error(t, transactionPool.RememberOne(signedTx))
 }
 
 func TestRemove(t *testing.T) {
@@ -855,7 +864,7 @@ func TestRemove(t *testing.T) {
 		},
 	}
 	signedTx := tx.Sign(secrets[0])
-	require.NoError(t, transactionPool.Remember(signedTx))
+	require.NoError(t, transactionPool.RememberOne(signedTx))
 	require.Equal(t, transactionPool.Pending(), []transactions.SignedTxn{signedTx})
 
 	tx2 := transactions.Transaction{
@@ -879,6 +888,574 @@ func TestRemove(t *testing.T) {
 
 }
 
+func TestTxGroup(t *testing.T) {
+	numOfAccounts := 3
+	// Genereate accounts
+	secrets := make([]*crypto.SignatureSecrets, numOfAccounts)
+	addresses := make([]basics.Address, numOfAccounts)
+
+	for i := 0; i < numOfAccounts; i++ {
+		secret := keypair()
+		addr := basics.Address(secret.SignatureVerifier)
+		secrets[i] = secret
+		addresses[i] = addr
+	}
+
+	limitedAccounts := make(map[basics.Address]uint64)
+	limitedAccounts[addresses[2]] = mockBalancesMinBalance
+
+	transactionPool := MakeTransactionPool(mockSpendableBalancesUnbounded{balance: 1 << 60, exceptions: limitedAccounts}, exponentialGrowth, testPoolSize, false)
+
+	makeTx := func(sender int) transactions.Transaction {
+		return transactions.Transaction{
+			Type: protocol.PaymentTx,
+			Header: transactions.Header{
+				Sender:     addresses[sender],
+				Fee:        basics.MicroAlgos{Raw: proto.MinTxnFee},
+				FirstValid: 0,
+				LastValid:  10,
+				Note:       []byte{byte(sender)},
+			},
+			PaymentTxnFields: transactions.PaymentTxnFields{
+				Receiver: addresses[(sender+1)%numOfAccounts],
+				Amount:   basics.MicroAlgos{Raw: mockBalancesMinBalance},
+			},
+		}
+	}
+
+	makeGroup := func(txns ...transactions.Transaction) []transactions.SignedTxn {
+		var group transactions.TxGroup
+		for _, tx := range txns {
+			group.TxGroupHashes = append(group.TxGroupHashes, crypto.Digest(tx.ID()))
+		}
+		gid := crypto.HashObj(group)
+
+		var signed []transactions.SignedTxn
+		for _, tx := range txns {
+			tx.Group = gid
+			for i := range addresses {
+				if addresses[i] == tx.Sender {
+					signed = append(signed, tx.Sign(secrets[i]))
+				}
+			}
+		}
+		return signed
+	}
+
+	// an incomplete group is rejected
+	txgroup := makeGroup(makeTx(0), makeTx(1))
+	require.Error(t, transactionPool.Remember(txgroup[:1]))
+	require.Error(t, transactionPool.Test(txgroup[:1]))
+	require.Len(t, transactionPool.Pending(), 0)
+
+	// a group whose last member cannot be remembered is rejected as a whole
+	badgroup := makeGroup(makeTx(0), makeTx(2))
+	require.Error(t, transactionPool.Remember(badgroup))
+	require.Len(t, transactionPool.Pending(), 0)
+
+	// a complete group is remembered as a whole
+	require.NoError(t, transactionPool.Test(txgroup))
+	require.NoError(t, transactionPool.Remember(txgroup))
+	require.Equal(t, [][]transactions.SignedTxn{txgroup}, transactionPool.PendingTxGroups())
+	require.Len(t, transactionPool.Pending(), 2)
+
+	// removing one member of the group removes the whole group
+	transactionPool.Remove(txgroup[1].ID(), fmt.Errorf("removing group member"))
+	require.Len(t, transactionPool.Pending(), 0)
+	require.Len(t, transactionPool.PendingTxGroups(), 0)
+}
+
+func TestLease(t *testing.T) {
+	numOfAccounts := 2
+	// Genereate accounts
+	secrets := make([]*crypto.SignatureSecrets, numOfAccounts)
+	addresses := make([]basics.Address, numOfAccounts)
+
+	for i := 0; i < numOfAccounts; i++ {
+		secret := keypair()
+		addr := basics.Address(secret.SignatureVerifier)
+		secrets[i] = secret
+		addresses[i] = addr
+	}
+
+	transactionPool := MakeTransactionPool(mockSpendableBalancesUnbounded{balance: 1 << 60}, exponentialGrowth, testPoolSize, false)
+
+	var lease [32]byte
+	crypto.RandBytes(lease[:])
+
+	makeTx := func(sender int, note byte) transactions.SignedTxn {
+		tx := transactions.Transaction{
+			Type: protocol.PaymentTx,
+			Header: transactions.Header{
+				Sender:     addresses[sender],
+				Fee:        basics.MicroAlgos{Raw: proto.MinTxnFee},
+				FirstValid: 0,
+				LastValid:  10,
+				Note:       []byte{note},
+				Lease:      lease,
+			},
+			PaymentTxnFields: transactions.PaymentTxnFields{
+				Receiver: addresses[(sender+1)%numOfAccounts],
+				Amount:   basics.MicroAlgos{Raw: 1},
+			},
+		}
+		return tx.Sign(secrets[sender])
+	}
+
+	// only one pending transaction may hold a lease
+	tx1 := makeTx(0, 1)
+	tx2 := makeTx(0, 2)
+	require.NoError(t, transactionPool.RememberOne(tx1))
+	require.Error(t, transactionPool.Test([]transactions.SignedTxn{tx2}))
+	require.Error(t, transactionPool.RememberOne(tx2))
+
+	// the same lease from another sender does not conflict
+	other := makeTx(1, 1)
+	require.NoError(t, transactionPool.RememberOne(other))
+
+	// removing the holder releases the lease
+	transactionPool.Remove(tx1.ID(), fmt.Errorf("removing lease holder"))
+	require.NoError(t, transactionPool.RememberOne(tx2))
+
+	// committing another transaction with the lease evicts the pending holder
+	block := bookkeeping.Block{
+		BlockHeader: bookkeeping.BlockHeader{
+			Round: basics.Round(1),
+		},
+	}
+	txib, err := block.EncodeSignedTxn(tx1, transactions.ApplyData{})
+	require.NoError(t, err)
+	block.Payset = []transactions.SignedTxnInBlock{txib}
+	transactionPool.OnNewBlock(block)
+	require.Equal(t, []transactions.SignedTxn{other}, transactionPool.Pending())
+
+	_, txErr, found := transactionPool.Lookup(tx2.ID())
+	require.True(t, found)
+	require.NotEmpty(t, txErr)
+}
+
+func TestForgedAuthAddr(t *testing.T) {
+	numOfAccounts := 3
+	// Genereate accounts
+	secrets := make([]*crypto.SignatureSecrets, numOfAccounts)
+	addresses := make([]basics.Address, numOfAccounts)
+
+	for i := 0; i < numOfAccounts; i++ {
+		secret := keypair()
+		addr := basics.Address(secret.SignatureVerifier)
+		secrets[i] = secret
+		addresses[i] = addr
+	}
+
+	// addresses[1] is rekeyed to addresses[2] on the ledger
+	ledger := mockSpendableBalancesUnbounded{
+		balance:   1 << 60,
+		authAddrs: map[basics.Address]basics.Address{addresses[1]: addresses[2]},
+	}
+	transactionPool := MakeTransactionPool(ledger, exponentialGrowth, testPoolSize, false)
+
+	var lease [32]byte
+	crypto.RandBytes(lease[:])
+
+	makeTx := func(sender int, signer int, rekeyTo basics.Address, lease [32]byte) transactions.SignedTxn {
+		tx := transactions.Transaction{
+			Type: protocol.PaymentTx,
+			Header: transactions.Header{
+				Sender:     addresses[sender],
+				Fee:        basics.MicroAlgos{Raw: proto.MinTxnFee},
+				FirstValid: 0,
+				LastValid:  10,
+				Note:       []byte{byte(signer)},
+				Lease:      lease,
+				RekeyTo:    rekeyTo,
+			},
+			PaymentTxnFields: transactions.PaymentTxnFields{
+				Receiver: addresses[(sender+1)%numOfAccounts],
+				Amount:   basics.MicroAlgos{Raw: 1},
+			},
+		}
+		stxn := tx.Sign(secrets[signer])
+		if signer != sender {
+			stxn.AuthAddr = addresses[signer]
+		}
+		return stxn
+	}
+
+	// a transaction signed by another key, claiming that key as its
+	// authorizer, is rejected
+	forged := makeTx(0, 2, basics.Address{}, lease)
+	require.Error(t, transactionPool.Test([]transactions.SignedTxn{forged}))
+	require.Error(t, transactionPool.RememberOne(forged))
+	require.Empty(t, transactionPool.Pending())
+
+	// so it does not take the lease of the real sender
+	require.NoError(t, transactionPool.RememberOne(makeTx(0, 0, basics.Address{}, lease)))
+
+	// a rekeyed account is authorized by its new key only
+	require.Error(t, transactionPool.RememberOne(makeTx(1, 1, basics.Address{}, [32]byte{})))
+	require.NoError(t, transactionPool.RememberOne(makeTx(1, 2, basics.Address{}, [32]byte{})))
+
+	// a pending rekey lets the new key authorize later transactions
+	require.Error(t, transactionPool.RememberOne(makeTx(2, 1, basics.Address{}, [32]byte{})))
+	require.NoError(t, transactionPool.RememberOne(makeTx(2, 2, addresses[1], [32]byte{})))
+	require.NoError(t, transactionPool.RememberOne(makeTx(2, 1, basics.Address{}, [32]byte{})))
+}
+
+func TestReplaceByFee(t *testing.T) {
+	numOfAccounts := 2
+	// Genereate accounts
+	secrets := make([]*crypto.SignatureSecrets, numOfAccounts)
+	addresses := make([]basics.Address, numOfAccounts)
+
+	for i := 0; i < numOfAccounts; i++ {
+		secret := keypair()
+		addr := basics.Address(secret.SignatureVerifier)
+		secrets[i] = secret
+		addresses[i] = addr
+	}
+
+	transactionPool := MakeTransactionPool(mockSpendableBalancesUnbounded{balance: 1 << 60}, exponentialGrowth, testPoolSize, false)
+
+	var lease [32]byte
+	crypto.RandBytes(lease[:])
+
+	makeTx := func(fee uint64, amount uint64, note []byte, lease [32]byte) transactions.SignedTxn {
+		tx := transactions.Transaction{
+			Type: protocol.PaymentTx,
+			Header: transactions.Header{
+				Sender:     addresses[0],
+				Fee:        basics.MicroAlgos{Raw: fee},
+				FirstValid: 0,
+				LastValid:  10,
+				Note:       note,
+				Lease:      lease,
+			},
+			PaymentTxnFields: transactions.PaymentTxnFields{
+				Receiver: addresses[1],
+				Amount:   basics.MicroAlgos{Raw: amount},
+			},
+		}
+		return tx.Sign(secrets[0])
+	}
+
+	// a transaction with the same lease replaces the pending one if it pays enough more
+	leased := makeTx(proto.MinTxnFee, 1, []byte{1}, lease)
+	require.NoError(t, transactionPool.RememberOne(leased))
+	require.Error(t, transactionPool.RememberOne(makeTx(proto.MinTxnFee+1, 2, []byte{2}, lease)))
+
+	leasedReplacement := makeTx(replacementFee(leased.Txn.Fee).Raw, 2, []byte{2}, lease)
+	require.NoError(t, transactionPool.Test([]transactions.SignedTxn{leasedReplacement}))
+	require.NoError(t, transactionPool.RememberOne(leasedReplacement))
+	require.Equal(t, []transactions.SignedTxn{leasedReplacement}, transactionPool.Pending())
+
+	_, txErr, found := transactionPool.Lookup(leased.ID())
+	require.True(t, found)
+	require.Contains(t, txErr, leasedReplacement.ID().String())
+
+	// a transaction with the lease of a pending grouped transaction does not replace it
+	var groupLease [32]byte
+	crypto.RandBytes(groupLease[:])
+	grouped := []transactions.Transaction{makeTx(proto.MinTxnFee, 1, []byte{3}, groupLease).Txn, makeTx(proto.MinTxnFee, 1, []byte{4}, [32]byte{}).Txn}
+	var group transactions.TxGroup
+	for _, tx := range grouped {
+		group.TxGroupHashes = append(group.TxGroupHashes, crypto.Digest(tx.ID()))
+	}
+	var signedGroup []transactions.SignedTxn
+	for _, tx := range grouped {
+		tx.Group = crypto.HashObj(group)
+		signedGroup = append(signedGroup, tx.Sign(secrets[0]))
+	}
+	require.NoError(t, transactionPool.Remember(signedGroup))
+	require.Error(t, transactionPool.RememberOne(makeTx(replacementFee(signedGroup[0].Txn.Fee).Raw, 2, []byte{3}, groupLease)))
+	require.True(t, transactionPool.Verified(signedGroup[0]))
+	require.Len(t, transactionPool.Pending(), 3)
+
+	// without a lease, the sender, first valid round and note identify the
+	// replaced transaction, once replacing by note is enabled
+	transactionPool.SetReplaceByNote(true)
+	note := []byte("replace me")
+	noted := makeTx(proto.MinTxnFee, 1, note, [32]byte{})
+	require.NoError(t, transactionPool.RememberOne(noted))
+	require.Error(t, transactionPool.RememberOne(makeTx(proto.MinTxnFee, 2, note, [32]byte{})))
+
+	notedReplacement := makeTx(replacementFee(noted.Txn.Fee).Raw, 2, note, [32]byte{})
+	require.NoError(t, transactionPool.RememberOne(notedReplacement))
+	require.Len(t, transactionPool.Pending(), 4)
+	require.False(t, transactionPool.Verified(noted))
+	require.True(t, transactionPool.Verified(notedReplacement))
+
+	// transactions without a note are never replaced
+	require.NoError(t, transactionPool.RememberOne(makeTx(proto.MinTxnFee, 1, nil, [32]byte{})))
+	require.NoError(t, transactionPool.RememberOne(makeTx(proto.MinTxnFee, 2, nil, [32]byte{})))
+	require.Len(t, transactionPool.Pending(), 6)
+
+	// a replacement that the pool rejects leaves the pending transaction in place
+	overspend := makeTx(replacementFee(notedReplacement.Txn.Fee).Raw, 1<<61, note, [32]byte{})
+	require.Error(t, transactionPool.RememberOne(overspend))
+	require.True(t, transactionPool.Verified(notedReplacement))
+	require.Len(t, transactionPool.Pending(), 6)
+
+	// the pending spend of the replaced transaction is available to its replacement
+	pending := transactionPool.algosPendingSpend[addresses[0]].deductions.amount.Raw
+	freed := notedReplacement.Txn.Fee.Raw + notedReplacement.Txn.Amount.Raw
+	fee := replacementFee(notedReplacement.Txn.Fee).Raw
+	spendAll := makeTx(fee, 1<<60-proto.MinBalance-(pending-freed)-fee, note, [32]byte{})
+	require.NoError(t, transactionPool.Test([]transactions.SignedTxn{spendAll}))
+	require.NoError(t, transactionPool.RememberOne(spendAll))
+	require.False(t, transactionPool.Verified(notedReplacement))
+	require.True(t, transactionPool.Verified(spendAll))
+	require.Len(t, transactionPool.Pending(), 6)
+}
+
+func TestMaxPendingPerSender(t *testing.T) {
+	numOfAccounts := 2
+	// Genereate accounts
+	secrets := make([]*crypto.SignatureSecrets, numOfAccounts)
+	addresses := make([]basics.Address, numOfAccounts)
+
+	for i := 0; i < numOfAccounts; i++ {
+		secret := keypair()
+		addr := basics.Address(secret.SignatureVerifier)
+		secrets[i] = secret
+		addresses[i] = addr
+	}
+
+	transactionPool := MakeTransactionPool(mockSpendableBalancesUnbounded{balance: 1 << 60}, exponentialGrowth, testPoolSize, false)
+	transactionPool.SetMaxPendingPerSender(2)
+	transactionPool.SetReplaceByNote(true)
+	require.Equal(t, 2, transactionPool.AdmissionParams().MaxPendingPerSender)
+
+	makeTx := func(sender int, fee uint64, note byte) transactions.SignedTxn {
+		tx := transactions.Transaction{
+			Type: protocol.PaymentTx,
+			Header: transactions.Header{
+				Sender:     addresses[sender],
+				Fee:        basics.MicroAlgos{Raw: fee},
+				FirstValid: 0,
+				LastValid:  10,
+				Note:       []byte{note},
+			},
+			PaymentTxnFields: transactions.PaymentTxnFields{
+				Receiver: addresses[(sender+1)%numOfAccounts],
+				Amount:   basics.MicroAlgos{Raw: 1},
+			},
+		}
+		return tx.Sign(secrets[sender])
+	}
+
+	require.NoError(t, transactionPool.RememberOne(makeTx(0, proto.MinTxnFee, 1)))
+	require.NoError(t, transactionPool.RememberOne(makeTx(0, proto.MinTxnFee, 2)))
+	require.Error(t, transactionPool.Test([]transactions.SignedTxn{makeTx(0, proto.MinTxnFee, 3)}))
+	require.Error(t, transactionPool.RememberOne(makeTx(0, proto.MinTxnFee, 3)))
+
+	// other senders are not affected, and replacing a pending transaction does not count
+	require.NoError(t, transactionPool.RememberOne(makeTx(1, proto.MinTxnFee, 1)))
+	require.NoError(t, transactionPool.RememberOne(makeTx(0, 2*proto.MinTxnFee, 2)))
+	require.Len(t, transactionPool.Pending(), 3)
+}
+
+func TestPoolPressureFee(t *testing.T) {
+	numOfAccounts := 2
+	// Genereate accounts
+	secrets := make([]*crypto.SignatureSecrets, numOfAccounts)
+	addresses := make([]basics.Address, numOfAccounts)
+
+	for i := 0; i < numOfAccounts; i++ {
+		secret := keypair()
+		addr := basics.Address(secret.SignatureVerifier)
+		secrets[i] = secret
+		addresses[i] = addr
+	}
+
+	const poolSize = 4
+	transactionPool := MakeTransactionPool(mockSpendableBalancesUnbounded{balance: 1 << 60}, exponentialGrowth, poolSize, false)
+
+	genesisHash := crypto.Hash([]byte("pool pressure"))
+	makeTx := func(fee uint64, note byte) transactions.SignedTxn {
+		tx := transactions.Transaction{
+			Type: protocol.PaymentTx,
+			Header: transactions.Header{
+				Sender:      addresses[0],
+				Fee:         basics.MicroAlgos{Raw: fee},
+				FirstValid:  0,
+				LastValid:   basics.Round(proto.MaxTxnLife),
+				Note:        []byte{note},
+				GenesisHash: genesisHash,
+			},
+			PaymentTxnFields: transactions.PaymentTxnFields{
+				Receiver: addresses[1],
+				Amount:   basics.MicroAlgos{Raw: 1},
+			},
+		}
+		return tx.Sign(secrets[0])
+	}
+
+	for i := 0; i <= poolSize/2; i++ {
+		require.NoError(t, transactionPool.RememberOne(makeTx(1, byte(i))))
+	}
+	require.Equal(t, uint64(0), transactionPool.AdmissionParams().FeePerByte)
+
+	makeBlock := func(round basics.Round) bookkeeping.Block {
+		return bookkeeping.Block{
+			BlockHeader: bookkeeping.BlockHeader{
+				Round:        round,
+				GenesisHash:  genesisHash,
+				UpgradeState: bookkeeping.UpgradeState{CurrentProtocol: protocol.ConsensusCurrentVersion},
+			},
+		}
+	}
+
+	// every block that leaves the pool more than half full doubles the fee per byte
+	for round := 1; round <= 3; round++ {
+		transactionPool.OnNewBlock(makeBlock(basics.Round(round)))
+		require.Equal(t, uint64(1)<<uint(round-1), transactionPool.AdmissionParams().FeePerByte)
+	}
+
+	minFee := 4 * uint64(makeTx(1000, poolSize).GetEncodedLength())
+	require.Error(t, transactionPool.RememberOne(makeTx(1, poolSize)))
+	require.Error(t, transactionPool.RememberOne(makeTx(minFee-1, poolSize)))
+	require.NoError(t, transactionPool.RememberOne(makeTx(minFee, poolSize)))
+	require.Equal(t, uint64(minReplacementFeeBump), transactionPool.AdmissionParams().ReplacementFeeBump)
+
+	// blocks that leave the pool at most half full halve it again
+	for _, txn := range transactionPool.PendingUnsorted() {
+		transactionPool.Remove(txn.ID(), nil)
+	}
+	transactionPool.OnNewBlock(makeBlock(4))
+	require.Equal(t, uint64(2), transactionPool.AdmissionParams().FeePerByte)
+
+	// once the pool is full, the fee per byte is at least what it takes to evict a transaction
+	var txn transactions.SignedTxn
+	for i := 0; i < poolSize; i++ {
+		txn = makeTx(100000, byte(i))
+		require.NoError(t, transactionPool.RememberOne(txn))
+	}
+	require.Equal(t, txn.Priority().Mul(exponentialGrowth).FeePerByte(), transactionPool.AdmissionParams().FeePerByte)
+
+	// however long the pool stays busy, the pool-pressure fee does not exceed
+	// MinTxnFee per byte, nor pressureFeeCapFactor times MinTxnFee per transaction
+	for round := 5; round <= 64; round++ {
+		transactionPool.OnNewBlock(makeBlock(basics.Round(round)))
+	}
+	for _, txn := range transactionPool.PendingUnsorted() {
+		transactionPool.Remove(txn.ID(), nil)
+	}
+	pressureFeeCap := pressureFeeCapFactor * proto.MinTxnFee
+	require.Equal(t, proto.MinTxnFee, transactionPool.AdmissionParams().FeePerByte)
+	require.Equal(t, pressureFeeCap, transactionPool.AdmissionParams().PressureFeeCap)
+	require.True(t, proto.MinTxnFee*uint64(makeTx(pressureFeeCap, 0).GetEncodedLength()) > pressureFeeCap)
+	require.Error(t, transactionPool.RememberOne(makeTx(pressureFeeCap-1, 0)))
+	require.NoError(t, transactionPool.RememberOne(makeTx(pressureFeeCap, 0)))
+}
+
+type txEventRecorder []TxEvent
+
+func (r *txEventRecorder) OnTxEvent(ev TxEvent) {
+	*r = append(*r, ev)
+}
+
+func TestTxEvents(t *testing.T) {
+	numOfAccounts := 2
+	// Genereate accounts
+	secrets := make([]*crypto.SignatureSecrets, numOfAccounts)
+	addresses := make([]basics.Address, numOfAccounts)
+
+	for i := 0; i < numOfAccounts; i++ {
+		secret := keypair()
+		addr := basics.Address(secret.SignatureVerifier)
+		secrets[i] = secret
+		addresses[i] = addr
+	}
+
+	const poolSize = 4
+	transactionPool := MakeTransactionPool(mockSpendableBalancesUnbounded{balance: 1 << 60}, exponentialGrowth, poolSize, false)
+	transactionPool.SetReplaceByNote(true)
+	var events txEventRecorder
+	transactionPool.RegisterEventListeners([]TxEventListener{&events})
+
+	makeTx := func(fee uint64, lastValid basics.Round, note byte) transactions.SignedTxn {
+		tx := transactions.Transaction{
+			Type: protocol.PaymentTx,
+			Header: transactions.Header{
+				Sender:     addresses[0],
+				Fee:        basics.MicroAlgos{Raw: fee},
+				FirstValid: 0,
+				LastValid:  lastValid,
+				Note:       []byte{note},
+			},
+			PaymentTxnFields: transactions.PaymentTxnFields{
+				Receiver: addresses[1],
+				Amount:   basics.MicroAlgos{Raw: 1},
+			},
+		}
+		return tx.Sign(secrets[0])
+	}
+
+	committed := makeTx(2*proto.MinTxnFee, 10, 1)
+	expiring := makeTx(2*proto.MinTxnFee, 1, 2)
+	cheap := makeTx(proto.MinTxnFee, 10, 3)
+	replaced := makeTx(2*proto.MinTxnFee, 10, 4)
+	replacement := makeTx(3*proto.MinTxnFee, 10, 4)
+	for _, tx := range []transactions.SignedTxn{committed, expiring, cheap, replaced, replacement} {
+		require.NoError(t, transactionPool.RememberOne(tx))
+	}
+	require.True(t, transactionPool.Rebroadcast([]transactions.SignedTxn{committed}))
+	require.False(t, transactionPool.Rebroadcast([]transactions.SignedTxn{replaced}))
+
+	// a full pool evicts its lowest-priority transaction to make room
+	highFee := makeTx(100*proto.MinTxnFee, 10, 5)
+	require.NoError(t, transactionPool.RememberOne(highFee))
+
+	block := bookkeeping.Block{
+		BlockHeader: bookkeeping.BlockHeader{
+			Round: basics.Round(2),
+		},
+	}
+	txib, err := block.EncodeSignedTxn(committed, transactions.ApplyData{})
+	require.NoError(t, err)
+	block.Payset = []transactions.SignedTxnInBlock{txib}
+	transactionPool.OnNewBlock(block)
+
+	type summary struct {
+		Type   TxEventType
+		Txid   transactions.Txid
+		Reason EvictionReason
+	}
+	var got []summary
+	for _, ev := range events {
+		got = append(got, summary{ev.Type, ev.Txid, ev.Reason})
+		if ev.Type == TxEvicted {
+			_, txErr, found := transactionPool.Lookup(ev.Txid)
+			require.True(t, found)
+			require.Equal(t, txErr, ev.Error)
+		}
+	}
+
+	require.Equal(t, []summary{
+		{TxAdmitted, committed.ID(), ""},
+		{TxAdmitted, expiring.ID(), ""},
+		{TxAdmitted, cheap.ID(), ""},
+		{TxAdmitted, replaced.ID(), ""},
+		{TxEvicted, replaced.ID(), EvictReplaced},
+		{TxAdmitted, replacement.ID(), ""},
+		{TxRebroadcast, committed.ID(), ""},
+		{TxAdmitted, highFee.ID(), ""},
+		{TxEvicted, cheap.ID(), EvictFeeTooLow},
+	}, got[:9])
+	require.Equal(t, replacement.ID(), events[4].ReplacedBy)
+
+	// the transactions removed by a block are reported in no particular order
+	require.ElementsMatch(t, []summary{
+		{TxCommitted, committed.ID(), ""},
+		{TxEvicted, expiring.ID(), EvictExpired},
+	}, got[9:])
+	for _, ev := range events[9:] {
+		if ev.Type == TxCommitted {
+			require.Equal(t, basics.Round(2), ev.Round)
+		}
+	}
+}
+
 func BenchmarkTransactionPoolRemember(b *testing.B) {
 	numOfAccounts := 5
 	// Genereate accounts
@@ -916,7 +1493,7 @@ func BenchmarkTransactionPoolRemember(b *testing.B) {
 			crypto.RandBytes(tx.Note)
 			signedTx := tx.Sign(secrets[i])
 			signedTransactions = append(signedTransactions, signedTx)
-			err := transactionPool.Remember(signedTx)
+			err := transactionPool.RememberOne(signedTx)
 			require.NoError(b, err)
 		}
 	}
@@ -926,7 +1503,7 @@ func BenchmarkTransactionPoolRemember(b *testing.B) {
 
 	b.StartTimer()
 	for _, signedTx := range signedTransactions {
-		transactionPool.Remember(signedTx)
+		transactionPool.RememberOne(signedTx)
 	}
 }
 
@@ -971,7 +1548,7 @@ func BenchmarkTransactionPoolPending(b *testing.B) {
 				tx.Note = make([]byte, 8, 8)
 				crypto.RandBytes(tx.Note)
 				signedTx := tx.Sign(secrets[i])
-				err := transactionPool.Remember(signedTx)
+				err := transactionPool.RememberOne(signedTx)
 				require.NoError(b, err)
 			}
 		}
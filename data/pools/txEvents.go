@@ -0,0 +1,137 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package pools
+
+import (
+	"fmt"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/transactions"
+)
+
+// TxEventType is the kind of change to the pool that a TxEvent reports.
+type TxEventType string
+
+const (
+	// TxAdmitted reports that a transaction was added to the pool.
+	TxAdmitted TxEventType = "admitted"
+	// TxRebroadcast reports that a pending transaction was broadcast again.
+	TxRebroadcast TxEventType = "rebroadcast"
+	// TxEvicted reports that a transaction left the pool without being committed.
+	TxEvicted TxEventType = "evicted"
+	// TxCommitted reports that a pending transaction was committed to the ledger.
+	TxCommitted TxEventType = "committed"
+)
+
+// EvictionReason explains why a transaction was evicted from the pool.
+type EvictionReason string
+
+const (
+	// EvictExpired means the transaction is no longer valid in the next round.
+	EvictExpired EvictionReason = "expired"
+	// EvictFeeTooLow means the transaction made room for transactions with a
+	// higher priority, or did not pay the minimum fee when it was evaluated.
+	EvictFeeTooLow EvictionReason = "fee-too-low"
+	// EvictOverspend means the sender can no longer pay for all of its
+	// pending transactions.
+	EvictOverspend EvictionReason = "overspend"
+	// EvictLeaseTaken means a committed transaction took the lease of the
+	// transaction.
+	EvictLeaseTaken EvictionReason = "lease-taken"
+	// EvictReplaced means the transaction was replaced by one that pays a
+	// higher fee.
+	EvictReplaced EvictionReason = "replaced"
+	// EvictReplacementFailed means the transaction was taken out of the pool
+	// for a replacement that the pool then rejected, and could not be put
+	// back.
+	EvictReplacementFailed EvictionReason = "replacement-failed"
+	// EvictInvalid means the transaction could not be evaluated in a block.
+	EvictInvalid EvictionReason = "invalid"
+)
+
+// TxEvent reports a change to the transaction pool.
+type TxEvent struct {
+	Type TxEventType
+	Txid transactions.Txid
+
+	// Round is the round that committed the transaction for TxCommitted
+	// events, and the latest round of the ledger otherwise.
+	Round basics.Round
+
+	// Reason and Error explain a TxEvicted event.  Error is the same
+	// string that Lookup reports for the transaction afterwards.
+	Reason EvictionReason
+	Error  string
+
+	// ReplacedBy is the transaction that replaced an evicted one, if any.
+	ReplacedBy transactions.Txid
+}
+
+// TxEventListener is notified of changes to the transaction pool.
+// OnTxEvent is called while the pool is locked, so it must not block
+// or call back into the pool.
+type TxEventListener interface {
+	OnTxEvent(TxEvent)
+}
+
+// RegisterEventListeners adds listeners that are notified of every
+// change to the pool.
+func (pool *TransactionPool) RegisterEventListeners(listeners []TxEventListener) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	pool.eventListeners = append(pool.eventListeners, listeners...)
+}
+
+func (pool *TransactionPool) notify(ev TxEvent) {
+	if len(pool.eventListeners) == 0 {
+		return
+	}
+	if ev.Type != TxCommitted {
+		ev.Round = pool.ledger.LastRound()
+	}
+	for _, listener := range pool.eventListeners {
+		listener.OnTxEvent(ev)
+	}
+}
+
+// evictionError is the error status of a transaction that the pool
+// evicted on its own, along with the reason for the eviction.
+type evictionError struct {
+	reason EvictionReason
+	err    error
+}
+
+func (e evictionError) Error() string {
+	return e.err.Error()
+}
+
+func evictionErrorf(reason EvictionReason, format string, args ...interface{}) error {
+	return evictionError{reason: reason, err: fmt.Errorf(format, args...)}
+}
+
+// evictionReason returns the reason for removing a transaction with the
+// error status txErr.
+func evictionReason(txErr error) EvictionReason {
+	switch err := txErr.(type) {
+	case evictionError:
+		return err.reason
+	case transactions.MinFeeError:
+		return EvictFeeTooLow
+	default:
+		return EvictInvalid
+	}
+}
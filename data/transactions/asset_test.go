@@ -0,0 +1,87 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package transactions
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/go-algorand/config"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/protocol"
+)
+
+func TestAssetWellFormed(t *testing.T) {
+	current := config.Consensus[protocol.ConsensusCurrentVersion]
+	future := config.Consensus[protocol.ConsensusFuture]
+
+	var sender basics.Address
+	sender[0] = 1
+
+	tx := Transaction{
+		Type: protocol.AssetConfigTx,
+		Header: Header{
+			Sender:     sender,
+			Fee:        basics.MicroAlgos{Raw: future.MinTxnFee},
+			FirstValid: 1,
+			LastValid:  100,
+		},
+		AssetConfigTxnFields: AssetConfigTxnFields{
+			AssetParams: basics.AssetParams{
+				Total:     100,
+				UnitName:  "unit",
+				AssetName: "name",
+			},
+		},
+	}
+
+	// Assets are not yet enabled in the current protocol
+	require.Error(t, tx.WellFormed(spec, current))
+	require.NoError(t, tx.WellFormed(spec, future))
+
+	long := tx
+	long.AssetParams.UnitName = strings.Repeat("u", future.MaxAssetUnitNameBytes+1)
+	require.Error(t, long.WellFormed(spec, future))
+
+	long = tx
+	long.AssetParams.AssetName = strings.Repeat("n", future.MaxAssetNameBytes+1)
+	require.Error(t, long.WellFormed(spec, future))
+
+	long = tx
+	long.AssetParams.URL = strings.Repeat("l", future.MaxAssetURLBytes+1)
+	require.Error(t, long.WellFormed(spec, future))
+
+	xfer := Transaction{
+		Type:   protocol.AssetTransferTx,
+		Header: tx.Header,
+		AssetTransferTxnFields: AssetTransferTxnFields{
+			XferAsset:     1,
+			AssetAmount:   1,
+			AssetReceiver: sender,
+		},
+	}
+	require.Error(t, xfer.WellFormed(spec, current))
+	require.NoError(t, xfer.WellFormed(spec, future))
+
+	// Asset fields are not allowed on payment transactions
+	pay := xfer
+	pay.Type = protocol.PaymentTx
+	pay.Receiver = sender
+	require.Error(t, pay.WellFormed(spec, future))
+}
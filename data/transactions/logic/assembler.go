@@ -0,0 +1,889 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package logic
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/algorand/go-algorand/data/basics"
+)
+
+// AssemblerDefaultVersion is the version of programs produced by the assembler
+const AssemblerDefaultVersion = 1
+
+// labelReference is a use of a label by a branch op, to be resolved once
+// all labels are known
+type labelReference struct {
+	sourceLine int
+
+	// position of the op byte; the branch offset is relative to the end of the op
+	position int
+
+	label string
+}
+
+// OpStream is destination for program and scratch space
+type OpStream struct {
+	out     bytes.Buffer
+	intc    []uint64
+	bytec   [][]byte
+	noIntcs bool // prepared and explicitly set intcblock
+	noBytec bool // prepared and explicitly set bytecblock
+
+	sourceLine int
+
+	// map label string to position within out buffer
+	labels map[string]int
+
+	labelReferences []labelReference
+}
+
+func (ops *OpStream) opByName(name string) error {
+	spec, ok := opsByName[name]
+	if !ok {
+		return fmt.Errorf("unknown opcode %v", name)
+	}
+	return ops.out.WriteByte(spec.Opcode)
+}
+
+// Intc writes opcodes for loading a uint64 constant onto the stack.
+func (ops *OpStream) Intc(constIndex uint) error {
+	switch constIndex {
+	case 0:
+		return ops.opByName("intc_0")
+	case 1:
+		return ops.opByName("intc_1")
+	case 2:
+		return ops.opByName("intc_2")
+	case 3:
+		return ops.opByName("intc_3")
+	default:
+		if constIndex > 0xff {
+			return errors.New("cannot have more than 256 int constants")
+		}
+		err := ops.opByName("intc")
+		if err != nil {
+			return err
+		}
+		return ops.out.WriteByte(uint8(constIndex))
+	}
+}
+
+// Uint writes opcodes for loading a uint literal
+func (ops *OpStream) Uint(val uint64) error {
+	if ops.noIntcs {
+		return errors.New("int pseudo-op cannot be mixed with an explicit intcblock")
+	}
+	found := false
+	var constIndex uint
+	for i, cv := range ops.intc {
+		if cv == val {
+			constIndex = uint(i)
+			found = true
+			break
+		}
+	}
+	if !found {
+		constIndex = uint(len(ops.intc))
+		ops.intc = append(ops.intc, val)
+	}
+	return ops.Intc(constIndex)
+}
+
+// Bytec writes opcodes for loading a []byte constant onto the stack.
+func (ops *OpStream) Bytec(constIndex uint) error {
+	switch constIndex {
+	case 0:
+		return ops.opByName("bytec_0")
+	case 1:
+		return ops.opByName("bytec_1")
+	case 2:
+		return ops.opByName("bytec_2")
+	case 3:
+		return ops.opByName("bytec_3")
+	default:
+		if constIndex > 0xff {
+			return errors.New("cannot have more than 256 byte constants")
+		}
+		err := ops.opByName("bytec")
+		if err != nil {
+			return err
+		}
+		return ops.out.WriteByte(uint8(constIndex))
+	}
+}
+
+// ByteLiteral writes opcodes and data for loading a []byte literal
+// Values are accumulated so that they can be put into a bytecblock
+func (ops *OpStream) ByteLiteral(val []byte) error {
+	if ops.noBytec {
+		return errors.New("byte pseudo-op cannot be mixed with an explicit bytecblock")
+	}
+	found := false
+	var constIndex uint
+	for i, cv := range ops.bytec {
+		if bytes.Equal(cv, val) {
+			found = true
+			constIndex = uint(i)
+			break
+		}
+	}
+	if !found {
+		constIndex = uint(len(ops.bytec))
+		ops.bytec = append(ops.bytec, val)
+	}
+	return ops.Bytec(constIndex)
+}
+
+// Arg writes opcodes for loading from Lsig.Args
+func (ops *OpStream) Arg(val uint64) error {
+	switch val {
+	case 0:
+		return ops.opByName("arg_0")
+	case 1:
+		return ops.opByName("arg_1")
+	case 2:
+		return ops.opByName("arg_2")
+	case 3:
+		return ops.opByName("arg_3")
+	default:
+		if val > 0xff {
+			return errors.New("cannot have more than 256 args")
+		}
+		err := ops.opByName("arg")
+		if err != nil {
+			return err
+		}
+		return ops.out.WriteByte(uint8(val))
+	}
+}
+
+// Txn writes opcodes for loading a field from the current transaction
+func (ops *OpStream) Txn(val uint64) error {
+	if val >= uint64(len(TxnFieldNames)) {
+		return errors.New("invalid txn field")
+	}
+	err := ops.opByName("txn")
+	if err != nil {
+		return err
+	}
+	return ops.out.WriteByte(uint8(val))
+}
+
+// Gtxn writes opcodes for loading a field from a transaction in the group
+func (ops *OpStream) Gtxn(gid, val uint64) error {
+	if val >= uint64(len(TxnFieldNames)) {
+		return errors.New("invalid txn field")
+	}
+	if gid > 0xff {
+		return fmt.Errorf("gtxn cannot look up beyond group index 255")
+	}
+	err := ops.opByName("gtxn")
+	if err != nil {
+		return err
+	}
+	err = ops.out.WriteByte(uint8(gid))
+	if err != nil {
+		return err
+	}
+	return ops.out.WriteByte(uint8(val))
+}
+
+// Global writes opcodes for loading an evaluator-global field
+func (ops *OpStream) Global(val uint64) error {
+	if val >= uint64(len(GlobalFieldNames)) {
+		return errors.New("invalid global field")
+	}
+	err := ops.opByName("global")
+	if err != nil {
+		return err
+	}
+	return ops.out.WriteByte(uint8(val))
+}
+
+// ReferToLabel records a branch to a label, to be resolved by Bytes
+func (ops *OpStream) ReferToLabel(sourceLine, pc int, label string) {
+	ops.labelReferences = append(ops.labelReferences, labelReference{sourceLine, pc, label})
+}
+
+type assembleFunc func(ops *OpStream, args []string) error
+
+func assembleInt(ops *OpStream, args []string) error {
+	if len(args) != 1 {
+		return errors.New("int needs one argument")
+	}
+	val, err := strconv.ParseUint(args[0], 0, 64)
+	if err != nil {
+		return err
+	}
+	return ops.Uint(val)
+}
+
+// Explicit invocation of const lookup and push
+func assembleIntC(ops *OpStream, args []string) error {
+	if len(args) != 1 {
+		return errors.New("intc operation needs one argument")
+	}
+	constIndex, err := strconv.ParseUint(args[0], 0, 64)
+	if err != nil {
+		return err
+	}
+	return ops.Intc(uint(constIndex))
+}
+
+func assembleByteC(ops *OpStream, args []string) error {
+	if len(args) != 1 {
+		return errors.New("bytec operation needs one argument")
+	}
+	constIndex, err := strconv.ParseUint(args[0], 0, 64)
+	if err != nil {
+		return err
+	}
+	return ops.Bytec(uint(constIndex))
+}
+
+// parseBinaryArgs parses a byte literal, which may span several args, and
+// returns the value and the number of args consumed. Supported forms are:
+//
+//	base64 AAAA...
+//	b64 AAAA...
+//	base64(AAAA...)
+//	b64(AAAA...)
+//	base32 AAAA...
+//	b32 AAAA...
+//	base32(AAAA...)
+//	b32(AAAA...)
+//	0x0123...
+//	"string literal"
+func parseBinaryArgs(args []string) (val []byte, consumed int, err error) {
+	arg := args[0]
+	if strings.HasPrefix(arg, "base32(") || strings.HasPrefix(arg, "b32(") {
+		open := strings.IndexRune(arg, '(')
+		close := strings.IndexRune(arg, ')')
+		if close == -1 {
+			err = errors.New("byte base32 arg lacks close paren")
+			return
+		}
+		val, err = base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(arg[open+1 : close])
+		if err != nil {
+			return
+		}
+		consumed = 1
+	} else if strings.HasPrefix(arg, "base64(") || strings.HasPrefix(arg, "b64(") {
+		open := strings.IndexRune(arg, '(')
+		close := strings.IndexRune(arg, ')')
+		if close == -1 {
+			err = errors.New("byte base64 arg lacks close paren")
+			return
+		}
+		val, err = base64.StdEncoding.DecodeString(arg[open+1 : close])
+		if err != nil {
+			return
+		}
+		consumed = 1
+	} else if strings.HasPrefix(arg, "0x") {
+		val, err = hex.DecodeString(arg[2:])
+		if err != nil {
+			return
+		}
+		consumed = 1
+	} else if arg == "base32" || arg == "b32" {
+		if len(args) < 2 {
+			err = fmt.Errorf("need literal after 'byte %s'", arg)
+			return
+		}
+		val, err = base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(args[1])
+		if err != nil {
+			return
+		}
+		consumed = 2
+	} else if arg == "base64" || arg == "b64" {
+		if len(args) < 2 {
+			err = fmt.Errorf("need literal after 'byte %s'", arg)
+			return
+		}
+		val, err = base64.StdEncoding.DecodeString(args[1])
+		if err != nil {
+			return
+		}
+		consumed = 2
+	} else if len(arg) > 1 && arg[0] == '"' && arg[len(arg)-1] == '"' {
+		var s string
+		s, err = strconv.Unquote(arg)
+		if err != nil {
+			return
+		}
+		val = []byte(s)
+		consumed = 1
+	} else {
+		err = fmt.Errorf("byte arg did not parse: %v", arg)
+		return
+	}
+	return
+}
+
+// byte {base64,b64,base32,b32}(...)
+// byte {base64,b64,base32,b32} ...
+// byte 0x....
+// byte "this is a string"
+func assembleByte(ops *OpStream, args []string) error {
+	if len(args) == 0 {
+		return errors.New("byte operation needs byte literal argument")
+	}
+	val, consumed, err := parseBinaryArgs(args)
+	if err != nil {
+		return err
+	}
+	if consumed != len(args) {
+		return errors.New("byte operation with extraneous argument")
+	}
+	return ops.ByteLiteral(val)
+}
+
+func assembleIntCBlock(ops *OpStream, args []string) error {
+	if len(ops.intc) > 0 || ops.noIntcs {
+		return errors.New("intcblock cannot be mixed with int pseudo-ops or repeated")
+	}
+	err := ops.opByName("intcblock")
+	if err != nil {
+		return err
+	}
+	var scratch [binary.MaxVarintLen64]byte
+	l := binary.PutUvarint(scratch[:], uint64(len(args)))
+	ops.out.Write(scratch[:l])
+	for _, xs := range args {
+		cu, err := strconv.ParseUint(xs, 0, 64)
+		if err != nil {
+			return err
+		}
+		l = binary.PutUvarint(scratch[:], cu)
+		ops.out.Write(scratch[:l])
+	}
+	ops.noIntcs = true
+	return nil
+}
+
+func assembleByteCBlock(ops *OpStream, args []string) error {
+	if len(ops.bytec) > 0 || ops.noBytec {
+		return errors.New("bytecblock cannot be mixed with byte pseudo-ops or repeated")
+	}
+	err := ops.opByName("bytecblock")
+	if err != nil {
+		return err
+	}
+	bvals := make([][]byte, 0, len(args))
+	rest := args
+	for len(rest) > 0 {
+		val, consumed, err := parseBinaryArgs(rest)
+		if err != nil {
+			return err
+		}
+		bvals = append(bvals, val)
+		rest = rest[consumed:]
+	}
+	var scratch [binary.MaxVarintLen64]byte
+	l := binary.PutUvarint(scratch[:], uint64(len(bvals)))
+	ops.out.Write(scratch[:l])
+	for _, bv := range bvals {
+		l := binary.PutUvarint(scratch[:], uint64(len(bv)))
+		ops.out.Write(scratch[:l])
+		ops.out.Write(bv)
+	}
+	ops.noBytec = true
+	return nil
+}
+
+// addr A1EU...
+// parses base32-with-checksum account address strings into a byte literal
+func assembleAddr(ops *OpStream, args []string) error {
+	if len(args) != 1 {
+		return errors.New("addr operation needs one argument")
+	}
+	addr, err := basics.UnmarshalChecksumAddress(args[0])
+	if err != nil {
+		return err
+	}
+	return ops.ByteLiteral(addr[:])
+}
+
+func assembleArg(ops *OpStream, args []string) error {
+	if len(args) != 1 {
+		return errors.New("arg operation needs one argument")
+	}
+	val, err := strconv.ParseUint(args[0], 0, 64)
+	if err != nil {
+		return err
+	}
+	return ops.Arg(val)
+}
+
+func assembleBnz(ops *OpStream, args []string) error {
+	if len(args) != 1 {
+		return errors.New("bnz operation needs label argument")
+	}
+	ops.ReferToLabel(ops.sourceLine, ops.out.Len(), args[0])
+	err := ops.opByName("bnz")
+	if err != nil {
+		return err
+	}
+	// zero bytes will get replaced with actual offset in resolveLabels()
+	ops.out.WriteByte(0)
+	ops.out.WriteByte(0)
+	return nil
+}
+
+func assembleLoad(ops *OpStream, args []string) error {
+	if len(args) != 1 {
+		return errors.New("load operation needs one argument")
+	}
+	val, err := strconv.ParseUint(args[0], 0, 64)
+	if err != nil {
+		return err
+	}
+	if val > 255 {
+		return errors.New("load limited to 0..255")
+	}
+	ops.opByName("load")
+	return ops.out.WriteByte(byte(val))
+}
+
+func assembleStore(ops *OpStream, args []string) error {
+	if len(args) != 1 {
+		return errors.New("store operation needs one argument")
+	}
+	val, err := strconv.ParseUint(args[0], 0, 64)
+	if err != nil {
+		return err
+	}
+	if val > 255 {
+		return errors.New("store limited to 0..255")
+	}
+	ops.opByName("store")
+	return ops.out.WriteByte(byte(val))
+}
+
+func assembleTxn(ops *OpStream, args []string) error {
+	if len(args) != 1 {
+		return errors.New("txn expects one argument")
+	}
+	val, ok := txnFieldNameToIndex[args[0]]
+	if !ok {
+		return fmt.Errorf("txn unknown arg %v", args[0])
+	}
+	return ops.Txn(uint64(val))
+}
+
+func assembleGtxn(ops *OpStream, args []string) error {
+	if len(args) != 2 {
+		return errors.New("gtxn expects two arguments")
+	}
+	gtid, err := strconv.ParseUint(args[0], 0, 64)
+	if err != nil {
+		return err
+	}
+	val, ok := txnFieldNameToIndex[args[1]]
+	if !ok {
+		return fmt.Errorf("gtxn unknown arg %v", args[1])
+	}
+	return ops.Gtxn(gtid, uint64(val))
+}
+
+func assembleGlobal(ops *OpStream, args []string) error {
+	if len(args) != 1 {
+		return errors.New("global expects one argument")
+	}
+	val, ok := globalFieldNameToIndex[args[0]]
+	if !ok {
+		return fmt.Errorf("global unknown arg %v", args[0])
+	}
+	return ops.Global(uint64(val))
+}
+
+// argOps take an immediate value and need to parse that during assembly
+var argOps = map[string]assembleFunc{
+	"int":        assembleInt,
+	"intcblock":  assembleIntCBlock,
+	"intc":       assembleIntC,
+	"byte":       assembleByte,
+	"bytecblock": assembleByteCBlock,
+	"bytec":      assembleByteC,
+	"addr":       assembleAddr, // parse basics.Address, actually just another []byte constant
+	"arg":        assembleArg,
+	"bnz":        assembleBnz,
+	"load":       assembleLoad,
+	"store":      assembleStore,
+	"txn":        assembleTxn,
+	"gtxn":       assembleGtxn,
+	"global":     assembleGlobal,
+}
+
+// fieldsFromLine splits a line into fields, keeping a double-quoted
+// string literal together as one field and stopping at a // comment.
+func fieldsFromLine(line string) []string {
+	var fields []string
+	i := 0
+	for i < len(line) {
+		switch {
+		case line[i] == ' ' || line[i] == '\t':
+			i++
+		case strings.HasPrefix(line[i:], "//"):
+			return fields
+		case line[i] == '"':
+			end := i + 1
+			for end < len(line) && line[end] != '"' {
+				if line[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end < len(line) {
+				end++
+			}
+			fields = append(fields, line[i:end])
+			i = end
+		default:
+			end := i
+			for end < len(line) && line[end] != ' ' && line[end] != '\t' {
+				end++
+			}
+			fields = append(fields, line[i:end])
+			i = end
+		}
+	}
+	return fields
+}
+
+// assemble reads text from an input and accumulates the program
+func (ops *OpStream) assemble(fin io.Reader) error {
+	scanner := bufio.NewScanner(fin)
+	ops.sourceLine = 0
+	for scanner.Scan() {
+		ops.sourceLine++
+		line := scanner.Text()
+		fields := fieldsFromLine(line)
+		if len(fields) == 0 {
+			continue
+		}
+		opstring := fields[0]
+		argf, ok := argOps[opstring]
+		if ok {
+			err := argf(ops, fields[1:])
+			if err != nil {
+				return lineErr(ops.sourceLine, err)
+			}
+			continue
+		}
+		_, ok = opsByName[opstring]
+		if ok {
+			if len(fields) > 1 {
+				return lineErr(ops.sourceLine, fmt.Errorf("%s expects no arguments", opstring))
+			}
+			err := ops.opByName(opstring)
+			if err != nil {
+				return lineErr(ops.sourceLine, err)
+			}
+			continue
+		}
+		if opstring[len(opstring)-1] == ':' {
+			if len(fields) > 1 {
+				return lineErr(ops.sourceLine, errors.New("label must be alone on its line"))
+			}
+			label := opstring[:len(opstring)-1]
+			if ops.labels == nil {
+				ops.labels = make(map[string]int)
+			}
+			if _, dup := ops.labels[label]; dup {
+				return lineErr(ops.sourceLine, fmt.Errorf("duplicate label %s", label))
+			}
+			ops.labels[label] = ops.out.Len()
+			continue
+		}
+		return lineErr(ops.sourceLine, fmt.Errorf("unknown opcode %v", opstring))
+	}
+	return scanner.Err()
+}
+
+func lineErr(line int, err error) error {
+	return fmt.Errorf(":%d %s", line, err.Error())
+}
+
+func (ops *OpStream) resolveLabels() error {
+	raw := ops.out.Bytes()
+	for _, lr := range ops.labelReferences {
+		dest, ok := ops.labels[lr.label]
+		if !ok {
+			return lineErr(lr.sourceLine, fmt.Errorf("reference to undefined label %v", lr.label))
+		}
+		// all branches are currently 3 bytes, with a forward-only unsigned offset
+		jump := dest - (lr.position + 3)
+		if jump < 0 {
+			return lineErr(lr.sourceLine, fmt.Errorf("label %v is before reference but only forward jumps are allowed", lr.label))
+		}
+		if jump > 0xffff {
+			return lineErr(lr.sourceLine, errors.New("label is too far away"))
+		}
+		raw[lr.position+1] = uint8(jump >> 8)
+		raw[lr.position+2] = uint8(jump & 0x0ff)
+	}
+	ops.labelReferences = nil
+	return nil
+}
+
+// Bytes returns the finished program bytes
+func (ops *OpStream) Bytes() (program []byte, err error) {
+	var scratch [binary.MaxVarintLen64]byte
+	prebytes := bytes.Buffer{}
+	vlen := binary.PutUvarint(scratch[:], AssemblerDefaultVersion)
+	prebytes.Write(scratch[:vlen])
+	if len(ops.intc) > 0 && !ops.noIntcs {
+		prebytes.WriteByte(0x20) // intcblock
+		vlen := binary.PutUvarint(scratch[:], uint64(len(ops.intc)))
+		prebytes.Write(scratch[:vlen])
+		for _, iv := range ops.intc {
+			vlen = binary.PutUvarint(scratch[:], iv)
+			prebytes.Write(scratch[:vlen])
+		}
+	}
+	if len(ops.bytec) > 0 && !ops.noBytec {
+		prebytes.WriteByte(0x26) // bytecblock
+		vlen := binary.PutUvarint(scratch[:], uint64(len(ops.bytec)))
+		prebytes.Write(scratch[:vlen])
+		for _, bv := range ops.bytec {
+			vlen = binary.PutUvarint(scratch[:], uint64(len(bv)))
+			prebytes.Write(scratch[:vlen])
+			prebytes.Write(bv)
+		}
+	}
+	err = ops.resolveLabels()
+	if err != nil {
+		return
+	}
+	out := ops.out.Bytes()
+	program = make([]byte, prebytes.Len()+len(out))
+	copy(program, prebytes.Bytes())
+	copy(program[prebytes.Len():], out)
+	return
+}
+
+// AssembleString takes an entire program in a string and assembles it to bytecode
+func AssembleString(text string) ([]byte, error) {
+	sr := strings.NewReader(text)
+	ops := OpStream{}
+	err := ops.assemble(sr)
+	if err != nil {
+		return nil, err
+	}
+	return ops.Bytes()
+}
+
+type disassembleState struct {
+	program       []byte
+	pc            int
+	out           io.Writer
+	labelCount    int
+	pendingLabels map[int]string
+
+	nextpc int
+}
+
+func (dis *disassembleState) putLabel(label string, target int) {
+	if dis.pendingLabels == nil {
+		dis.pendingLabels = make(map[int]string)
+	}
+	dis.pendingLabels[target] = label
+}
+
+// disassembleFunc returns the text of an op with immediate arguments,
+// and sets dis.nextpc
+type disassembleFunc func(dis *disassembleState, spec *OpSpec) (string, error)
+
+func disDefault(dis *disassembleState, spec *OpSpec) (string, error) {
+	dis.nextpc = dis.pc + 1
+	return spec.Name, nil
+}
+
+func disIntcblock(dis *disassembleState, spec *OpSpec) (string, error) {
+	intc, nextpc, err := parseIntcblock(dis.program, dis.pc)
+	if err != nil {
+		return "", err
+	}
+	dis.nextpc = nextpc
+	parts := []string{spec.Name}
+	for _, iv := range intc {
+		parts = append(parts, fmt.Sprintf("%d", iv))
+	}
+	return strings.Join(parts, " "), nil
+}
+
+func disBytecblock(dis *disassembleState, spec *OpSpec) (string, error) {
+	bytec, nextpc, err := parseBytecBlock(dis.program, dis.pc)
+	if err != nil {
+		return "", err
+	}
+	dis.nextpc = nextpc
+	parts := []string{spec.Name}
+	for _, bv := range bytec {
+		parts = append(parts, fmt.Sprintf("0x%s", hex.EncodeToString(bv)))
+	}
+	return strings.Join(parts, " "), nil
+}
+
+func checkImmediates(dis *disassembleState, spec *OpSpec, count int) error {
+	if dis.pc+count >= len(dis.program) {
+		return fmt.Errorf("program end while reading immediate for %s at pc=%d", spec.Name, dis.pc)
+	}
+	dis.nextpc = dis.pc + count + 1
+	return nil
+}
+
+func disIntImmediate(dis *disassembleState, spec *OpSpec) (string, error) {
+	if err := checkImmediates(dis, spec, 1); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s %d", spec.Name, dis.program[dis.pc+1]), nil
+}
+
+func disTxn(dis *disassembleState, spec *OpSpec) (string, error) {
+	if err := checkImmediates(dis, spec, 1); err != nil {
+		return "", err
+	}
+	txarg := dis.program[dis.pc+1]
+	if int(txarg) >= len(TxnFieldNames) {
+		return "", fmt.Errorf("invalid txn arg index %d at pc=%d", txarg, dis.pc)
+	}
+	return fmt.Sprintf("%s %s", spec.Name, TxnFieldNames[txarg]), nil
+}
+
+func disGtxn(dis *disassembleState, spec *OpSpec) (string, error) {
+	if err := checkImmediates(dis, spec, 2); err != nil {
+		return "", err
+	}
+	gi := dis.program[dis.pc+1]
+	txarg := dis.program[dis.pc+2]
+	if int(txarg) >= len(TxnFieldNames) {
+		return "", fmt.Errorf("invalid txn arg index %d at pc=%d", txarg, dis.pc)
+	}
+	return fmt.Sprintf("%s %d %s", spec.Name, gi, TxnFieldNames[txarg]), nil
+}
+
+func disGlobal(dis *disassembleState, spec *OpSpec) (string, error) {
+	if err := checkImmediates(dis, spec, 1); err != nil {
+		return "", err
+	}
+	garg := dis.program[dis.pc+1]
+	if int(garg) >= len(GlobalFieldNames) {
+		return "", fmt.Errorf("invalid global arg index %d at pc=%d", garg, dis.pc)
+	}
+	return fmt.Sprintf("%s %s", spec.Name, GlobalFieldNames[garg]), nil
+}
+
+func disBnz(dis *disassembleState, spec *OpSpec) (string, error) {
+	if err := checkImmediates(dis, spec, 2); err != nil {
+		return "", err
+	}
+	offset := (int(dis.program[dis.pc+1]) << 8) | int(dis.program[dis.pc+2])
+	target := dis.nextpc + offset
+	label, labelExists := dis.pendingLabels[target]
+	if !labelExists {
+		dis.labelCount++
+		label = fmt.Sprintf("label%d", dis.labelCount)
+		dis.putLabel(label, target)
+	}
+	return fmt.Sprintf("%s %s", spec.Name, label), nil
+}
+
+var disassemblers = map[string]disassembleFunc{
+	"intcblock":  disIntcblock,
+	"intc":       disIntImmediate,
+	"bytecblock": disBytecblock,
+	"bytec":      disIntImmediate,
+	"arg":        disIntImmediate,
+	"txn":        disTxn,
+	"gtxn":       disGtxn,
+	"global":     disGlobal,
+	"bnz":        disBnz,
+	"load":       disIntImmediate,
+	"store":      disIntImmediate,
+}
+
+// Disassemble produces a text form of program bytes.
+// AssembleString(Disassemble()) should result in the same program bytes.
+func Disassemble(program []byte) (text string, err error) {
+	out := strings.Builder{}
+	dis := disassembleState{program: program, out: &out}
+	version, vlen := binary.Uvarint(program)
+	if vlen <= 0 {
+		return "", errors.New("invalid version")
+	}
+	if version > EvalMaxVersion {
+		return "", fmt.Errorf("program version %d greater than max supported version %d", version, EvalMaxVersion)
+	}
+	fmt.Fprintf(dis.out, "// version %d\n", version)
+
+	// labels must be known before their targets are reached, so the
+	// program is walked once to collect them and once to print it
+	var lines []string
+	var linePCs []int
+	dis.pc = vlen
+	for dis.pc < len(program) {
+		spec := &opsByOpcode[program[dis.pc]]
+		if spec.Name == "" {
+			return "", fmt.Errorf("invalid opcode %02x at pc=%d", program[dis.pc], dis.pc)
+		}
+		disf, ok := disassemblers[spec.Name]
+		if !ok {
+			disf = disDefault
+		}
+		line, err := disf(&dis, spec)
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, line)
+		linePCs = append(linePCs, dis.pc)
+		dis.pc = dis.nextpc
+	}
+
+	targets := make([]int, 0, len(dis.pendingLabels))
+	for target := range dis.pendingLabels {
+		targets = append(targets, target)
+	}
+	sort.Ints(targets)
+
+	ti := 0
+	for i, line := range lines {
+		for ti < len(targets) && targets[ti] <= linePCs[i] {
+			fmt.Fprintf(dis.out, "%s:\n", dis.pendingLabels[targets[ti]])
+			ti++
+		}
+		fmt.Fprintf(dis.out, "%s\n", line)
+	}
+	for ; ti < len(targets); ti++ {
+		fmt.Fprintf(dis.out, "%s:\n", dis.pendingLabels[targets[ti]])
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,114 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package logic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const hashTimeLockSource = `// pass with the preimage of the hash, or after the timeout to the owner
+arg 0
+sha256
+byte base64 47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=
+==
+dup
+bnz done
+pop
+txn FirstValid
+int 10000
+>
+txn Receiver
+addr 7JOPVEP3ABJUW5YZ5WFIONLPWTZ5MYX5HFK4K7JLGSIAG7RRB42MNLQ224
+==
+&&
+done:
+txn Fee
+int 1000
+<=
+&&
+`
+
+func TestAssembleDisassemble(t *testing.T) {
+	program, err := AssembleString(hashTimeLockSource)
+	require.NoError(t, err)
+	require.Equal(t, byte(1), program[0])
+
+	text, err := Disassemble(program)
+	require.NoError(t, err)
+	require.Contains(t, text, "bnz label1")
+	require.Contains(t, text, "label1:")
+	require.Contains(t, text, "txn FirstValid")
+
+	// the disassembly assembles back to the same program
+	program2, err := AssembleString(text)
+	require.NoError(t, err)
+	require.Equal(t, program, program2)
+}
+
+func TestAssembleErrors(t *testing.T) {
+	sources := []string{
+		"nosuchop",
+		"int",
+		"int 1 2",
+		"byte 0xabc",
+		"txn NoSuchField",
+		"global NoSuchField",
+		"bnz nowhere",
+		"addr NOTANADDRESS",
+		"intcblock 1\nint 2",
+		"label:\nint 1\nbnz label",
+	}
+	for _, source := range sources {
+		_, err := AssembleString(source)
+		require.Error(t, err, source)
+	}
+}
+
+func TestAssembleByteEncodings(t *testing.T) {
+	sources := []string{
+		"byte 0x616263",
+		"byte base64 YWJj",
+		"byte b64(YWJj)",
+		"byte base32 MFRGG",
+		"byte b32(MFRGG)",
+		`byte "abc"`,
+	}
+	var expected []byte
+	for _, source := range sources {
+		program, err := AssembleString(source)
+		require.NoError(t, err, source)
+		if expected == nil {
+			expected = program
+		}
+		require.Equal(t, expected, program, source)
+	}
+}
+
+func TestDisassembleErrors(t *testing.T) {
+	_, err := Disassemble(nil)
+	require.Error(t, err)
+
+	// intcblock claims more constants than the program holds
+	_, err = Disassemble([]byte{0x01, 0x20, 0x05, 0x01})
+	require.Error(t, err)
+
+	// undefined opcode
+	_, err = Disassemble([]byte{0x01, 0xff})
+	require.Error(t, err)
+}
@@ -0,0 +1,914 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package logic implements the evaluator for logic sigs: small, bounded,
+// deterministic programs that approve or reject a transaction in place of
+// a signature.
+package logic
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/algorand/go-algorand/config"
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/protocol"
+)
+
+// EvalMaxVersion is the max version we can interpret and run
+const EvalMaxVersion = 1
+
+// MaxStackDepth should move to consensus params
+const MaxStackDepth = 1000
+
+// stackValue is the type for the operand stack.
+// Each stackValue is either a valid []byte value or a uint64 value.
+// If (.Bytes != nil) the stackValue is a []byte value, otherwise uint64 value.
+type stackValue struct {
+	Uint  uint64
+	Bytes []byte
+}
+
+func (sv *stackValue) argType() StackType {
+	if sv.Bytes != nil {
+		return StackBytes
+	}
+	return StackUint64
+}
+
+func (sv *stackValue) typeName() string {
+	if sv.Bytes != nil {
+		return "[]byte"
+	}
+	return "uint64"
+}
+
+func (sv *stackValue) String() string {
+	if sv.Bytes != nil {
+		return fmt.Sprintf("0x%x", sv.Bytes)
+	}
+	return fmt.Sprintf("%d", sv.Uint)
+}
+
+// EvalParams contains data that comes into condition evaluation.
+type EvalParams struct {
+	// the transaction being evaluated
+	Txn *transactions.SignedTxn
+
+	Proto *config.ConsensusParams
+
+	// Trace, if not nil, receives a line of output for every op evaluated
+	Trace *strings.Builder
+
+	// TxnGroup is the group the transaction being evaluated belongs to,
+	// and GroupIndex is its position within the group
+	TxnGroup   []transactions.SignedTxn
+	GroupIndex int
+}
+
+type opFunc func(cx *evalContext)
+type checkFunc func(cx *evalContext) int
+
+type evalContext struct {
+	EvalParams
+
+	stack     []stackValue
+	program   []byte
+	pc        int
+	nextpc    int
+	err       error
+	intc      []uint64
+	bytec     [][]byte
+	version   uint64
+	scratch   [256]stackValue
+	stepCount int
+	cost      int
+
+	programHash crypto.Digest
+}
+
+// Eval checks to see if a transaction passes logic.
+// A program passes successfully if it finishes with one int element on the stack that is non-zero.
+func Eval(program []byte, params EvalParams) (pass bool, err error) {
+	defer func() {
+		if x := recover(); x != nil {
+			pass = false
+			err = fmt.Errorf("panic in program evaluation: %v", x)
+		}
+	}()
+
+	if params.Txn == nil || params.Proto == nil {
+		return false, errors.New("transaction and consensus parameters are required to evaluate a program")
+	}
+
+	var cx evalContext
+	version, vlen := binary.Uvarint(program)
+	if vlen <= 0 {
+		return false, errors.New("invalid version")
+	}
+	if version > EvalMaxVersion {
+		return false, fmt.Errorf("program version %d greater than max supported version %d", version, EvalMaxVersion)
+	}
+	if version > params.Proto.LogicSigVersion {
+		return false, fmt.Errorf("program version %d greater than protocol supported version %d", version, params.Proto.LogicSigVersion)
+	}
+
+	cx.version = version
+	cx.pc = vlen
+	cx.EvalParams = params
+	cx.stack = make([]stackValue, 0, 10)
+	cx.program = program
+	cx.programHash = crypto.HashObj(transactions.Program(program))
+
+	for (cx.err == nil) && (cx.pc < len(cx.program)) {
+		cx.step()
+		cx.stepCount++
+	}
+	if cx.err != nil {
+		if cx.Trace != nil {
+			fmt.Fprintf(cx.Trace, "%3d %s\n", cx.pc, cx.err)
+		}
+		return false, cx.err
+	}
+	if len(cx.stack) != 1 {
+		if cx.Trace != nil {
+			fmt.Fprintf(cx.Trace, "end stack:\n")
+			for i, sv := range cx.stack {
+				fmt.Fprintf(cx.Trace, "[%d] %s\n", i, sv.String())
+			}
+		}
+		return false, fmt.Errorf("stack len is %d instead of 1", len(cx.stack))
+	}
+	if cx.stack[0].Bytes != nil {
+		return false, errors.New("stack finished with bytes not int")
+	}
+	return cx.stack[0].Uint != 0, nil
+}
+
+// Check should be faster than Eval.
+// Returns 'cost' which is an estimate of relative execution time.
+// It checks that every op is defined and that its immediate arguments
+// are well formed, without running the program.
+func Check(program []byte, params EvalParams) (cost int, err error) {
+	defer func() {
+		if x := recover(); x != nil {
+			cost = 0
+			err = fmt.Errorf("panic in program check: %v", x)
+		}
+	}()
+
+	var cx evalContext
+	version, vlen := binary.Uvarint(program)
+	if vlen <= 0 {
+		return 0, errors.New("invalid version")
+	}
+	if version > EvalMaxVersion {
+		return 0, fmt.Errorf("program version %d greater than max supported version %d", version, EvalMaxVersion)
+	}
+	if params.Proto != nil && version > params.Proto.LogicSigVersion {
+		return 0, fmt.Errorf("program version %d greater than protocol supported version %d", version, params.Proto.LogicSigVersion)
+	}
+
+	cx.version = version
+	cx.pc = vlen
+	cx.EvalParams = params
+	cx.program = program
+
+	for (cx.err == nil) && (cx.pc < len(cx.program)) {
+		spec := &opsByOpcode[cx.program[cx.pc]]
+		if spec.Name == "" {
+			cx.err = fmt.Errorf("%3d illegal opcode 0x%02x", cx.pc, cx.program[cx.pc])
+			break
+		}
+		size := 1
+		if spec.check != nil {
+			size = spec.check(&cx)
+		}
+		if cx.err != nil {
+			break
+		}
+		if cx.pc+size > len(cx.program) {
+			cx.err = fmt.Errorf("%3d %s program ends short of immediate values", cx.pc, spec.Name)
+			break
+		}
+		cost += spec.Cost
+		cx.pc += size
+	}
+	if cx.err != nil {
+		return 0, cx.err
+	}
+	return cost, nil
+}
+
+func (cx *evalContext) step() {
+	opcode := cx.program[cx.pc]
+	spec := &opsByOpcode[opcode]
+	if spec.Name == "" {
+		cx.err = fmt.Errorf("%3d illegal opcode 0x%02x", cx.pc, opcode)
+		return
+	}
+
+	// check args for stack underflow and types
+	if len(cx.stack) < len(spec.Args) {
+		cx.err = fmt.Errorf("stack underflow in %s", spec.Name)
+		return
+	}
+	first := len(cx.stack) - len(spec.Args)
+	for i, argType := range spec.Args {
+		if !typecheck(argType, cx.stack[first+i].argType()) {
+			cx.err = fmt.Errorf("%s arg %d wanted type %s got %s", spec.Name, i, argType.String(), cx.stack[first+i].typeName())
+			return
+		}
+	}
+
+	spec.op(cx)
+	cx.cost += spec.Cost
+	if cx.Trace != nil {
+		var stackString string
+		if len(cx.stack) == 0 {
+			stackString = "<empty stack>"
+		} else {
+			num := 1
+			if len(spec.Returns) > 1 {
+				num = len(spec.Returns)
+			}
+			if len(cx.stack) < num {
+				num = len(cx.stack)
+			}
+			parts := make([]string, num)
+			for i := 0; i < num; i++ {
+				parts[i] = cx.stack[len(cx.stack)-1-i].String()
+			}
+			stackString = strings.Join(parts, ", ")
+		}
+		fmt.Fprintf(cx.Trace, "%3d %s => %s\n", cx.pc, spec.Name, stackString)
+	}
+	if cx.err != nil {
+		return
+	}
+
+	if len(cx.stack) > MaxStackDepth {
+		cx.err = errors.New("stack overflow")
+		return
+	}
+	if cx.nextpc != 0 {
+		cx.pc = cx.nextpc
+		cx.nextpc = 0
+	} else {
+		cx.pc++
+	}
+}
+
+func typecheck(expected, got StackType) bool {
+	// Some ops push 'any' and we wait for run time to see what it is.
+	// Some of those 'any' are based on fields that we _could_ know now but haven't written a more detailed system of typecheck for (yet).
+	if (expected == StackAny) || (got == StackAny) {
+		return true
+	}
+	return expected == got
+}
+
+func checkOneImmediate(cx *evalContext) int {
+	return 2
+}
+
+func checkTwoImmediates(cx *evalContext) int {
+	return 3
+}
+
+func checkIntConstBlock(cx *evalContext) int {
+	_, nextpc, err := parseIntcblock(cx.program, cx.pc)
+	if err != nil {
+		cx.err = err
+		return 1
+	}
+	return nextpc - cx.pc
+}
+
+func checkByteConstBlock(cx *evalContext) int {
+	_, nextpc, err := parseBytecBlock(cx.program, cx.pc)
+	if err != nil {
+		cx.err = err
+		return 1
+	}
+	return nextpc - cx.pc
+}
+
+func checkBnz(cx *evalContext) int {
+	if cx.pc+3 > len(cx.program) {
+		cx.err = errors.New("bnz ends short of offset")
+		return 1
+	}
+	// the offset is unsigned, so branches only go forward and programs cannot loop
+	offset := (int(cx.program[cx.pc+1]) << 8) | int(cx.program[cx.pc+2])
+	if cx.pc+3+offset > len(cx.program) {
+		cx.err = errors.New("bnz target beyond end of program")
+		return 1
+	}
+	return 3
+}
+
+func opErr(cx *evalContext) {
+	cx.err = errors.New("err opcode executed")
+}
+
+func opSHA256(cx *evalContext) {
+	last := len(cx.stack) - 1
+	hash := sha256.Sum256(cx.stack[last].Bytes)
+	cx.stack[last].Bytes = hash[:]
+}
+
+func opSHA512_256(cx *evalContext) {
+	last := len(cx.stack) - 1
+	hash := sha512.Sum512_256(cx.stack[last].Bytes)
+	cx.stack[last].Bytes = hash[:]
+}
+
+// msg is data meant to be signed and then verified with the
+// ed25519verify opcode.
+type msg struct {
+	_struct     struct{}      `codec:",omitempty,omitemptyarray"`
+	ProgramHash crypto.Digest `codec:"p"`
+	Data        []byte        `codec:"d"`
+}
+
+func (sm msg) ToBeHashed() (protocol.HashID, []byte) {
+	return protocol.ProgramData, append(sm.ProgramHash[:], sm.Data...)
+}
+
+func opEd25519verify(cx *evalContext) {
+	last := len(cx.stack) - 1 // index of PK
+	prev := last - 1          // index of signature
+	pprev := prev - 1         // index of data
+
+	var sv crypto.SignatureVerifier
+	if len(cx.stack[last].Bytes) != len(sv) {
+		cx.err = errors.New("invalid public key")
+		return
+	}
+	copy(sv[:], cx.stack[last].Bytes)
+
+	var sig crypto.Signature
+	if len(cx.stack[prev].Bytes) != len(sig) {
+		cx.err = errors.New("invalid signature")
+		return
+	}
+	copy(sig[:], cx.stack[prev].Bytes)
+
+	if sv.Verify(msg{ProgramHash: cx.programHash, Data: cx.stack[pprev].Bytes}, sig) {
+		cx.stack[pprev].Uint = 1
+	} else {
+		cx.stack[pprev].Uint = 0
+	}
+	cx.stack[pprev].Bytes = nil
+	cx.stack = cx.stack[:prev]
+}
+
+func opPlus(cx *evalContext) {
+	last := len(cx.stack) - 1
+	prev := last - 1
+	sum := cx.stack[prev].Uint + cx.stack[last].Uint
+	if sum < cx.stack[prev].Uint {
+		cx.err = errors.New("+ overflowed")
+		return
+	}
+	cx.stack[prev].Uint = sum
+	cx.stack = cx.stack[:last]
+}
+
+func opMinus(cx *evalContext) {
+	last := len(cx.stack) - 1
+	prev := last - 1
+	if cx.stack[last].Uint > cx.stack[prev].Uint {
+		cx.err = errors.New("- would result negative")
+		return
+	}
+	cx.stack[prev].Uint -= cx.stack[last].Uint
+	cx.stack = cx.stack[:last]
+}
+
+func opDiv(cx *evalContext) {
+	last := len(cx.stack) - 1
+	prev := last - 1
+	if cx.stack[last].Uint == 0 {
+		cx.err = errors.New("/ 0")
+		return
+	}
+	cx.stack[prev].Uint /= cx.stack[last].Uint
+	cx.stack = cx.stack[:last]
+}
+
+func opModulo(cx *evalContext) {
+	last := len(cx.stack) - 1
+	prev := last - 1
+	if cx.stack[last].Uint == 0 {
+		cx.err = errors.New("% 0")
+		return
+	}
+	cx.stack[prev].Uint = cx.stack[prev].Uint % cx.stack[last].Uint
+	cx.stack = cx.stack[:last]
+}
+
+func opMul(cx *evalContext) {
+	last := len(cx.stack) - 1
+	prev := last - 1
+	a := cx.stack[prev].Uint
+	b := cx.stack[last].Uint
+	v := a * b
+	if (a != 0) && (b != 0) && (v/a != b) {
+		cx.err = errors.New("* overflowed")
+		return
+	}
+	cx.stack[prev].Uint = v
+	cx.stack = cx.stack[:last]
+}
+
+func boolToUint(x bool) uint64 {
+	if x {
+		return 1
+	}
+	return 0
+}
+
+func opLt(cx *evalContext) {
+	last := len(cx.stack) - 1
+	prev := last - 1
+	cx.stack[prev].Uint = boolToUint(cx.stack[prev].Uint < cx.stack[last].Uint)
+	cx.stack = cx.stack[:last]
+}
+
+func opGt(cx *evalContext) {
+	last := len(cx.stack) - 1
+	prev := last - 1
+	cx.stack[prev].Uint = boolToUint(cx.stack[prev].Uint > cx.stack[last].Uint)
+	cx.stack = cx.stack[:last]
+}
+
+func opLe(cx *evalContext) {
+	last := len(cx.stack) - 1
+	prev := last - 1
+	cx.stack[prev].Uint = boolToUint(cx.stack[prev].Uint <= cx.stack[last].Uint)
+	cx.stack = cx.stack[:last]
+}
+
+func opGe(cx *evalContext) {
+	last := len(cx.stack) - 1
+	prev := last - 1
+	cx.stack[prev].Uint = boolToUint(cx.stack[prev].Uint >= cx.stack[last].Uint)
+	cx.stack = cx.stack[:last]
+}
+
+func opAnd(cx *evalContext) {
+	last := len(cx.stack) - 1
+	prev := last - 1
+	cx.stack[prev].Uint = boolToUint((cx.stack[prev].Uint != 0) && (cx.stack[last].Uint != 0))
+	cx.stack = cx.stack[:last]
+}
+
+func opOr(cx *evalContext) {
+	last := len(cx.stack) - 1
+	prev := last - 1
+	cx.stack[prev].Uint = boolToUint((cx.stack[prev].Uint != 0) || (cx.stack[last].Uint != 0))
+	cx.stack = cx.stack[:last]
+}
+
+func opEq(cx *evalContext) {
+	last := len(cx.stack) - 1
+	prev := last - 1
+	ta := cx.stack[prev].argType()
+	tb := cx.stack[last].argType()
+	if ta != tb {
+		cx.err = fmt.Errorf("cannot compare (%s == %s)", cx.stack[prev].typeName(), cx.stack[last].typeName())
+		return
+	}
+	var cond bool
+	if ta == StackBytes {
+		cond = bytes.Equal(cx.stack[prev].Bytes, cx.stack[last].Bytes)
+	} else {
+		cond = cx.stack[prev].Uint == cx.stack[last].Uint
+	}
+	cx.stack[prev].Uint = boolToUint(cond)
+	cx.stack[prev].Bytes = nil
+	cx.stack = cx.stack[:last]
+}
+
+func opNeq(cx *evalContext) {
+	opEq(cx)
+	if cx.err != nil {
+		return
+	}
+	last := len(cx.stack) - 1
+	cx.stack[last].Uint = boolToUint(cx.stack[last].Uint == 0)
+}
+
+func opNot(cx *evalContext) {
+	last := len(cx.stack) - 1
+	cx.stack[last].Uint = boolToUint(cx.stack[last].Uint == 0)
+}
+
+func opLen(cx *evalContext) {
+	last := len(cx.stack) - 1
+	cx.stack[last].Uint = uint64(len(cx.stack[last].Bytes))
+	cx.stack[last].Bytes = nil
+}
+
+func opItob(cx *evalContext) {
+	last := len(cx.stack) - 1
+	ibytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(ibytes, cx.stack[last].Uint)
+	// cx.stack[last].Uint is not cleared out as optimization
+	// stackValue.argType() checks Bytes field first
+	cx.stack[last].Bytes = ibytes
+}
+
+func opBtoi(cx *evalContext) {
+	last := len(cx.stack) - 1
+	ibytes := cx.stack[last].Bytes
+	if len(ibytes) > 8 {
+		cx.err = fmt.Errorf("btoi arg too long, got [%d]bytes", len(ibytes))
+		return
+	}
+	value := uint64(0)
+	for _, b := range ibytes {
+		value = value << 8
+		value = value | (uint64(b) & 0x0ff)
+	}
+	cx.stack[last].Uint = value
+	cx.stack[last].Bytes = nil
+}
+
+func opBitOr(cx *evalContext) {
+	last := len(cx.stack) - 1
+	prev := last - 1
+	cx.stack[prev].Uint = cx.stack[prev].Uint | cx.stack[last].Uint
+	cx.stack = cx.stack[:last]
+}
+
+func opBitAnd(cx *evalContext) {
+	last := len(cx.stack) - 1
+	prev := last - 1
+	cx.stack[prev].Uint = cx.stack[prev].Uint & cx.stack[last].Uint
+	cx.stack = cx.stack[:last]
+}
+
+func opBitXor(cx *evalContext) {
+	last := len(cx.stack) - 1
+	prev := last - 1
+	cx.stack[prev].Uint = cx.stack[prev].Uint ^ cx.stack[last].Uint
+	cx.stack = cx.stack[:last]
+}
+
+func opBitNot(cx *evalContext) {
+	last := len(cx.stack) - 1
+	cx.stack[last].Uint = cx.stack[last].Uint ^ 0xffffffffffffffff
+}
+
+func opIntConstBlock(cx *evalContext) {
+	cx.intc, cx.nextpc, cx.err = parseIntcblock(cx.program, cx.pc)
+}
+
+func opIntConstN(cx *evalContext, n uint) {
+	if n >= uint(len(cx.intc)) {
+		cx.err = fmt.Errorf("intc [%d] beyond %d constants", n, len(cx.intc))
+		return
+	}
+	cx.stack = append(cx.stack, stackValue{Uint: cx.intc[n]})
+}
+
+func opIntConstLoad(cx *evalContext) {
+	n := uint(cx.program[cx.pc+1])
+	opIntConstN(cx, n)
+	cx.nextpc = cx.pc + 2
+}
+
+func opIntConst0(cx *evalContext) {
+	opIntConstN(cx, 0)
+}
+
+func opIntConst1(cx *evalContext) {
+	opIntConstN(cx, 1)
+}
+
+func opIntConst2(cx *evalContext) {
+	opIntConstN(cx, 2)
+}
+
+func opIntConst3(cx *evalContext) {
+	opIntConstN(cx, 3)
+}
+
+func opByteConstBlock(cx *evalContext) {
+	cx.bytec, cx.nextpc, cx.err = parseBytecBlock(cx.program, cx.pc)
+}
+
+func opByteConstN(cx *evalContext, n uint) {
+	if n >= uint(len(cx.bytec)) {
+		cx.err = fmt.Errorf("bytec [%d] beyond %d constants", n, len(cx.bytec))
+		return
+	}
+	cx.stack = append(cx.stack, stackValue{Bytes: cx.bytec[n]})
+}
+
+func opByteConstLoad(cx *evalContext) {
+	n := uint(cx.program[cx.pc+1])
+	opByteConstN(cx, n)
+	cx.nextpc = cx.pc + 2
+}
+
+func opByteConst0(cx *evalContext) {
+	opByteConstN(cx, 0)
+}
+
+func opByteConst1(cx *evalContext) {
+	opByteConstN(cx, 1)
+}
+
+func opByteConst2(cx *evalContext) {
+	opByteConstN(cx, 2)
+}
+
+func opByteConst3(cx *evalContext) {
+	opByteConstN(cx, 3)
+}
+
+func opArgN(cx *evalContext, n uint64) {
+	if n >= uint64(len(cx.Txn.Lsig.Args)) {
+		cx.err = fmt.Errorf("cannot load arg[%d] of %d", n, len(cx.Txn.Lsig.Args))
+		return
+	}
+	val := nilToEmpty(cx.Txn.Lsig.Args[n])
+	cx.stack = append(cx.stack, stackValue{Bytes: val})
+}
+
+func opArg(cx *evalContext) {
+	n := uint64(cx.program[cx.pc+1])
+	opArgN(cx, n)
+	cx.nextpc = cx.pc + 2
+}
+
+func opArg0(cx *evalContext) {
+	opArgN(cx, 0)
+}
+
+func opArg1(cx *evalContext) {
+	opArgN(cx, 1)
+}
+
+func opArg2(cx *evalContext) {
+	opArgN(cx, 2)
+}
+
+func opArg3(cx *evalContext) {
+	opArgN(cx, 3)
+}
+
+func opBnz(cx *evalContext) {
+	last := len(cx.stack) - 1
+	cx.nextpc = cx.pc + 3
+	isNonZero := cx.stack[last].Uint != 0
+	cx.stack = cx.stack[:last] // pop
+	if isNonZero {
+		offset := (int(cx.program[cx.pc+1]) << 8) | int(cx.program[cx.pc+2])
+		cx.nextpc += offset
+		if cx.nextpc > len(cx.program) {
+			cx.err = errors.New("bnz target beyond end of program")
+		}
+	}
+}
+
+func opPop(cx *evalContext) {
+	last := len(cx.stack) - 1
+	cx.stack = cx.stack[:last]
+}
+
+func opDup(cx *evalContext) {
+	last := len(cx.stack) - 1
+	sv := cx.stack[last]
+	cx.stack = append(cx.stack, sv)
+}
+
+// nilToEmpty keeps []byte values that happen to be nil from being
+// mistaken for uint64 values on the stack.
+func nilToEmpty(x []byte) []byte {
+	if x == nil {
+		return make([]byte, 0)
+	}
+	return x
+}
+
+func (cx *evalContext) txnFieldToStack(txn *transactions.Transaction, field uint64, groupIndex int) (sv stackValue, err error) {
+	err = nil
+	switch TxnField(field) {
+	case Sender:
+		sv.Bytes = txn.Sender[:]
+	case Fee:
+		sv.Uint = txn.Fee.Raw
+	case FirstValid:
+		sv.Uint = uint64(txn.FirstValid)
+	case LastValid:
+		sv.Uint = uint64(txn.LastValid)
+	case Note:
+		sv.Bytes = nilToEmpty(txn.Note)
+	case Receiver:
+		sv.Bytes = txn.Receiver[:]
+	case Amount:
+		sv.Uint = txn.Amount.Raw
+	case CloseRemainderTo:
+		sv.Bytes = txn.CloseRemainderTo[:]
+	case VotePK:
+		sv.Bytes = txn.VotePK[:]
+	case SelectionPK:
+		sv.Bytes = txn.SelectionPK[:]
+	case VoteFirst:
+		sv.Uint = uint64(txn.VoteFirst)
+	case VoteLast:
+		sv.Uint = uint64(txn.VoteLast)
+	case VoteKeyDilution:
+		sv.Uint = txn.VoteKeyDilution
+	case Type:
+		sv.Bytes = []byte(txn.Type)
+	case TypeEnum:
+		sv.Uint = txnTypeToEnum(txn.Type)
+	case XferAsset:
+		sv.Uint = uint64(txn.XferAsset)
+	case AssetAmount:
+		sv.Uint = txn.AssetAmount
+	case AssetSender:
+		sv.Bytes = txn.AssetSender[:]
+	case AssetReceiver:
+		sv.Bytes = txn.AssetReceiver[:]
+	case AssetCloseTo:
+		sv.Bytes = txn.AssetCloseTo[:]
+	case GroupIndex:
+		sv.Uint = uint64(groupIndex)
+	case TxID:
+		txid := txn.ID()
+		sv.Bytes = txid[:]
+	default:
+		err = fmt.Errorf("invalid txn field %d", field)
+	}
+	return
+}
+
+// txnTypeToEnum returns the TypeEnum value of a transaction type, or zero
+// for an unknown type.
+func txnTypeToEnum(txtype protocol.TxType) uint64 {
+	for i, tt := range TypeEnumNames {
+		if tt == txtype {
+			return uint64(i + 1)
+		}
+	}
+	return 0
+}
+
+func opTxn(cx *evalContext) {
+	field := uint64(cx.program[cx.pc+1])
+	sv, err := cx.txnFieldToStack(&cx.Txn.Txn, field, cx.GroupIndex)
+	if err != nil {
+		cx.err = err
+		return
+	}
+	cx.stack = append(cx.stack, sv)
+	cx.nextpc = cx.pc + 2
+}
+
+func opGtxn(cx *evalContext) {
+	gtxid := int(uint(cx.program[cx.pc+1]))
+	if gtxid >= len(cx.TxnGroup) {
+		cx.err = fmt.Errorf("gtxn lookup TxnGroup[%d] but it only has %d", gtxid, len(cx.TxnGroup))
+		return
+	}
+	txn := &cx.TxnGroup[gtxid].Txn
+	field := uint64(cx.program[cx.pc+2])
+	sv, err := cx.txnFieldToStack(txn, field, gtxid)
+	if err != nil {
+		cx.err = err
+		return
+	}
+	cx.stack = append(cx.stack, sv)
+	cx.nextpc = cx.pc + 3
+}
+
+var zeroAddress basics.Address
+
+func opGlobal(cx *evalContext) {
+	gindex := uint64(cx.program[cx.pc+1])
+	var sv stackValue
+	switch GlobalField(gindex) {
+	case MinTxnFee:
+		sv.Uint = cx.Proto.MinTxnFee
+	case MinBalance:
+		sv.Uint = cx.Proto.MinBalance
+	case MaxTxnLife:
+		sv.Uint = cx.Proto.MaxTxnLife
+	case ZeroAddress:
+		sv.Bytes = zeroAddress[:]
+	case GroupSize:
+		sv.Uint = uint64(len(cx.TxnGroup))
+	default:
+		cx.err = fmt.Errorf("invalid global[%d]", gindex)
+		return
+	}
+
+	cx.stack = append(cx.stack, sv)
+	cx.nextpc = cx.pc + 2
+}
+
+func opLoad(cx *evalContext) {
+	gindex := int(uint(cx.program[cx.pc+1]))
+	cx.stack = append(cx.stack, cx.scratch[gindex])
+	cx.nextpc = cx.pc + 2
+}
+
+func opStore(cx *evalContext) {
+	gindex := int(uint(cx.program[cx.pc+1]))
+	last := len(cx.stack) - 1
+	cx.scratch[gindex] = cx.stack[last]
+	cx.stack = cx.stack[:last]
+	cx.nextpc = cx.pc + 2
+}
+
+func parseIntcblock(program []byte, pc int) (intc []uint64, nextpc int, err error) {
+	pos := pc + 1
+	numInts, bytesUsed := binary.Uvarint(program[pos:])
+	if bytesUsed <= 0 {
+		err = fmt.Errorf("could not decode int const block size at pc=%d", pos)
+		return
+	}
+	pos += bytesUsed
+	if numInts > uint64(len(program)) {
+		err = errors.New("intcblock too long")
+		return
+	}
+	intc = make([]uint64, numInts)
+	for i := uint64(0); i < numInts; i++ {
+		if pos >= len(program) {
+			err = errors.New("intcblock ran past end of program")
+			return
+		}
+		intc[i], bytesUsed = binary.Uvarint(program[pos:])
+		if bytesUsed <= 0 {
+			err = fmt.Errorf("could not decode int const[%d] at pc=%d", i, pos)
+			return
+		}
+		pos += bytesUsed
+	}
+	nextpc = pos
+	return
+}
+
+func parseBytecBlock(program []byte, pc int) (bytec [][]byte, nextpc int, err error) {
+	pos := pc + 1
+	numItems, bytesUsed := binary.Uvarint(program[pos:])
+	if bytesUsed <= 0 {
+		err = fmt.Errorf("could not decode []byte const block size at pc=%d", pos)
+		return
+	}
+	pos += bytesUsed
+	if numItems > uint64(len(program)) {
+		err = errors.New("bytecblock too long")
+		return
+	}
+	bytec = make([][]byte, numItems)
+	for i := uint64(0); i < numItems; i++ {
+		if pos >= len(program) {
+			err = errors.New("bytecblock ran past end of program")
+			return
+		}
+		itemLen, bytesUsed := binary.Uvarint(program[pos:])
+		if bytesUsed <= 0 {
+			err = fmt.Errorf("could not decode []byte const[%d] at pc=%d", i, pos)
+			return
+		}
+		pos += bytesUsed
+		if itemLen > uint64(len(program)-pos) {
+			err = errors.New("bytecblock ran past end of program")
+			return
+		}
+		end := pos + int(itemLen)
+		bytec[i] = nilToEmpty(program[pos:end])
+		pos = end
+	}
+	nextpc = pos
+	return
+}
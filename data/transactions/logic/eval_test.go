@@ -0,0 +1,238 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package logic
+
+import (
+	"crypto/sha256"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/go-algorand/config"
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/protocol"
+)
+
+func testProto() *config.ConsensusParams {
+	proto := config.Consensus[protocol.ConsensusFuture]
+	return &proto
+}
+
+func testEval(t *testing.T, source string, txn *transactions.SignedTxn) (bool, error) {
+	program, err := AssembleString(source)
+	require.NoError(t, err)
+	proto := testProto()
+	cost, err := Check(program, EvalParams{Txn: txn, Proto: proto})
+	require.NoError(t, err)
+	require.True(t, cost > 0)
+	return Eval(program, EvalParams{Txn: txn, Proto: proto, Trace: &strings.Builder{}})
+}
+
+func TestEvalSimple(t *testing.T) {
+	var txn transactions.SignedTxn
+	txn.Txn.Fee = basics.MicroAlgos{Raw: 1000}
+
+	passing := []string{
+		"int 1",
+		"int 2\nint 3\n+\nint 5\n==",
+		"int 7\nint 2\n%\nint 1\n==",
+		"byte 0x0102\nlen\nint 2\n==",
+		"int 1\nitob\nbtoi",
+		"int 0\n!",
+		"txn Fee\nint 1000\n==",
+		"int 5\nstore 3\nload 3\nint 5\n==",
+		"int 1\ndup\n&&",
+		"int 1\nbnz skip\nerr\nskip:\nint 1",
+	}
+	for _, source := range passing {
+		pass, err := testEval(t, source, &txn)
+		require.NoError(t, err, source)
+		require.True(t, pass, source)
+	}
+
+	rejecting := []string{
+		"int 0",
+		"int 1\nint 2\n==",
+		"txn Fee\nint 999\n==",
+	}
+	for _, source := range rejecting {
+		pass, err := testEval(t, source, &txn)
+		require.NoError(t, err, source)
+		require.False(t, pass, source)
+	}
+
+	failing := []string{
+		"err",
+		"int 1\nint 0\n/",
+		"int 0\nint 1\n-",
+		"int 1\nbyte 0x01\n+",
+		"int 1\nint 1",
+		"byte 0x01",
+		"+",
+		"byte 0x010203040506070809\nbtoi",
+		"arg 0",
+	}
+	for _, source := range failing {
+		pass, err := testEval(t, source, &txn)
+		require.Error(t, err, source)
+		require.False(t, pass, source)
+	}
+}
+
+func TestEvalHashTimeLock(t *testing.T) {
+	program, err := AssembleString(hashTimeLockSource)
+	require.NoError(t, err)
+	proto := testProto()
+
+	owner, err := basics.UnmarshalChecksumAddress("7JOPVEP3ABJUW5YZ5WFIONLPWTZ5MYX5HFK4K7JLGSIAG7RRB42MNLQ224")
+	require.NoError(t, err)
+
+	var txn transactions.SignedTxn
+	txn.Txn.Type = protocol.PaymentTx
+	txn.Txn.Fee = basics.MicroAlgos{Raw: 1000}
+	txn.Txn.FirstValid = 100
+	txn.Lsig.Args = [][]byte{nil}
+
+	// the hash in the program is the sha256 of the empty string
+	require.Equal(t, sha256.Sum256(nil), sha256.Sum256(txn.Lsig.Args[0]))
+	pass, err := Eval(program, EvalParams{Txn: &txn, Proto: proto})
+	require.NoError(t, err)
+	require.True(t, pass)
+
+	// a wrong preimage before the timeout is rejected
+	txn.Lsig.Args = [][]byte{[]byte("wrong")}
+	pass, err = Eval(program, EvalParams{Txn: &txn, Proto: proto})
+	require.NoError(t, err)
+	require.False(t, pass)
+
+	// after the timeout, the funds can only go to the owner
+	txn.Txn.FirstValid = 20000
+	pass, err = Eval(program, EvalParams{Txn: &txn, Proto: proto})
+	require.NoError(t, err)
+	require.False(t, pass)
+
+	txn.Txn.Receiver = owner
+	pass, err = Eval(program, EvalParams{Txn: &txn, Proto: proto})
+	require.NoError(t, err)
+	require.True(t, pass)
+
+	// a fee over the limit is rejected either way
+	txn.Txn.Fee = basics.MicroAlgos{Raw: 1001}
+	pass, err = Eval(program, EvalParams{Txn: &txn, Proto: proto})
+	require.NoError(t, err)
+	require.False(t, pass)
+}
+
+func TestEvalGroup(t *testing.T) {
+	source := `global GroupSize
+int 2
+==
+gtxn 1 Amount
+int 500
+==
+&&
+txn GroupIndex
+int 0
+==
+&&`
+	program, err := AssembleString(source)
+	require.NoError(t, err)
+
+	var group [2]transactions.SignedTxn
+	group[1].Txn.Amount = basics.MicroAlgos{Raw: 500}
+	params := EvalParams{Txn: &group[0], Proto: testProto(), TxnGroup: group[:], GroupIndex: 0}
+	pass, err := Eval(program, params)
+	require.NoError(t, err)
+	require.True(t, pass)
+
+	group[1].Txn.Amount = basics.MicroAlgos{Raw: 501}
+	pass, err = Eval(program, params)
+	require.NoError(t, err)
+	require.False(t, pass)
+
+	// a group too short for the gtxn reference fails
+	params.TxnGroup = group[:1]
+	pass, err = Eval(program, params)
+	require.Error(t, err)
+	require.False(t, pass)
+}
+
+func TestEvalEd25519Verify(t *testing.T) {
+	var seed crypto.Seed
+	crypto.RandBytes(seed[:])
+	secrets := crypto.GenerateSignatureSecrets(seed)
+
+	source := "arg 0\narg 1\naddr " + basics.Address(secrets.SignatureVerifier).GetUserAddress() + "\ned25519verify"
+	program, err := AssembleString(source)
+	require.NoError(t, err)
+
+	data := []byte("data to sign")
+	sig := secrets.Sign(msg{ProgramHash: crypto.HashObj(transactions.Program(program)), Data: data})
+
+	var txn transactions.SignedTxn
+	txn.Lsig.Args = [][]byte{data, sig[:]}
+	pass, err := Eval(program, EvalParams{Txn: &txn, Proto: testProto()})
+	require.NoError(t, err)
+	require.True(t, pass)
+
+	txn.Lsig.Args[0] = []byte("other data")
+	pass, err = Eval(program, EvalParams{Txn: &txn, Proto: testProto()})
+	require.NoError(t, err)
+	require.False(t, pass)
+}
+
+func TestEvalVersion(t *testing.T) {
+	var txn transactions.SignedTxn
+	proto := testProto()
+
+	_, err := Eval(nil, EvalParams{Txn: &txn, Proto: proto})
+	require.Error(t, err)
+
+	_, err = Eval([]byte{EvalMaxVersion + 1, 0x20}, EvalParams{Txn: &txn, Proto: proto})
+	require.Error(t, err)
+
+	_, err = Check([]byte{EvalMaxVersion + 1, 0x20}, EvalParams{Proto: proto})
+	require.Error(t, err)
+
+	// logic sigs are disabled in protocols without a version
+	disabled := *proto
+	disabled.LogicSigVersion = 0
+	program, err := AssembleString("int 1")
+	require.NoError(t, err)
+	_, err = Eval(program, EvalParams{Txn: &txn, Proto: &disabled})
+	require.Error(t, err)
+
+	_, err = Eval(program, EvalParams{Proto: proto})
+	require.Error(t, err)
+}
+
+func TestCheckErrors(t *testing.T) {
+	// illegal opcode
+	_, err := Check([]byte{0x01, 0xff}, EvalParams{})
+	require.Error(t, err)
+
+	// truncated immediates
+	_, err = Check([]byte{0x01, 0x31}, EvalParams{})
+	require.Error(t, err)
+
+	// branch past the end of the program
+	_, err = Check([]byte{0x01, 0x40, 0x00, 0x10}, EvalParams{})
+	require.Error(t, err)
+}
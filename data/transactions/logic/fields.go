@@ -0,0 +1,153 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package logic
+
+import (
+	"github.com/algorand/go-algorand/protocol"
+)
+
+// TxnField is an enum type for `txn` and `gtxn`
+type TxnField int
+
+const (
+	// Sender Transaction.Sender
+	Sender TxnField = iota
+	// Fee Transaction.Fee
+	Fee
+	// FirstValid Transaction.FirstValid
+	FirstValid
+	// LastValid Transaction.LastValid
+	LastValid
+	// Note Transaction.Note
+	Note
+	// Receiver Transaction.Receiver
+	Receiver
+	// Amount Transaction.Amount
+	Amount
+	// CloseRemainderTo Transaction.CloseRemainderTo
+	CloseRemainderTo
+	// VotePK Transaction.VotePK
+	VotePK
+	// SelectionPK Transaction.SelectionPK
+	SelectionPK
+	// VoteFirst Transaction.VoteFirst
+	VoteFirst
+	// VoteLast Transaction.VoteLast
+	VoteLast
+	// VoteKeyDilution Transaction.VoteKeyDilution
+	VoteKeyDilution
+	// Type Transaction.Type
+	Type
+	// TypeEnum int(Transaction.Type)
+	TypeEnum
+	// XferAsset Transaction.XferAsset
+	XferAsset
+	// AssetAmount Transaction.AssetAmount
+	AssetAmount
+	// AssetSender Transaction.AssetSender
+	AssetSender
+	// AssetReceiver Transaction.AssetReceiver
+	AssetReceiver
+	// AssetCloseTo Transaction.AssetCloseTo
+	AssetCloseTo
+	// GroupIndex i for txngroup[i] == Txn
+	GroupIndex
+	// TxID Transaction.ID()
+	TxID
+
+	invalidTxnField // fence for some setup that loops from Sender..invalidTxnField
+)
+
+// TxnFieldNames are arguments to the 'txn' and 'gtxn' opcodes
+var TxnFieldNames = []string{
+	"Sender", "Fee", "FirstValid", "LastValid", "Note",
+	"Receiver", "Amount", "CloseRemainderTo", "VotePK", "SelectionPK",
+	"VoteFirst", "VoteLast", "VoteKeyDilution",
+	"Type", "TypeEnum", "XferAsset", "AssetAmount", "AssetSender",
+	"AssetReceiver", "AssetCloseTo", "GroupIndex", "TxID",
+}
+
+// TxnFieldTypes is StackBytes or StackUint64 parallel to TxnFieldNames
+var TxnFieldTypes = []StackType{
+	StackBytes, StackUint64, StackUint64, StackUint64, StackBytes,
+	StackBytes, StackUint64, StackBytes, StackBytes, StackBytes,
+	StackUint64, StackUint64, StackUint64,
+	StackBytes, StackUint64, StackUint64, StackUint64, StackBytes,
+	StackBytes, StackBytes, StackUint64, StackBytes,
+}
+
+var txnFieldNameToIndex map[string]int
+
+// TypeEnumNames are the values of the TypeEnum transaction field,
+// in order starting at one
+var TypeEnumNames = []protocol.TxType{
+	protocol.PaymentTx,
+	protocol.KeyRegistrationTx,
+	protocol.AssetConfigTx,
+	protocol.AssetTransferTx,
+	protocol.AssetFreezeTx,
+}
+
+// GlobalField is an enum for `global` opcode
+type GlobalField int
+
+const (
+	// MinTxnFee ConsensusParams.MinTxnFee
+	MinTxnFee GlobalField = iota
+	// MinBalance ConsensusParams.MinBalance
+	MinBalance
+	// MaxTxnLife ConsensusParams.MaxTxnLife
+	MaxTxnLife
+	// ZeroAddress [32]byte{0...}
+	ZeroAddress
+	// GroupSize len(txn group)
+	GroupSize
+
+	invalidGlobalField
+)
+
+// GlobalFieldNames are arguments to the 'global' opcode
+var GlobalFieldNames = []string{
+	"MinTxnFee",
+	"MinBalance",
+	"MaxTxnLife",
+	"ZeroAddress",
+	"GroupSize",
+}
+
+// GlobalFieldTypes is StackUint64 StackBytes in parallel with GlobalFieldNames
+var GlobalFieldTypes = []StackType{
+	StackUint64,
+	StackUint64,
+	StackUint64,
+	StackBytes,
+	StackUint64,
+}
+
+var globalFieldNameToIndex map[string]int
+
+func init() {
+	txnFieldNameToIndex = make(map[string]int)
+	for i, tfn := range TxnFieldNames {
+		txnFieldNameToIndex[tfn] = i
+	}
+
+	globalFieldNameToIndex = make(map[string]int)
+	for i, gfn := range GlobalFieldNames {
+		globalFieldNameToIndex[gfn] = i
+	}
+}
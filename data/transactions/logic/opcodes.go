@@ -0,0 +1,143 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package logic
+
+// StackType describes the type of a value on the operand stack
+type StackType byte
+
+const (
+	// StackNone in an OpSpec shows that the op pops or yields nothing
+	StackNone StackType = iota
+
+	// StackAny in an OpSpec shows that the op pops or yield any type
+	StackAny
+
+	// StackUint64 in an OpSpec shows that the op pops or yields a uint64
+	StackUint64
+
+	// StackBytes in an OpSpec shows that the op pops or yields a []byte
+	StackBytes
+)
+
+func (st StackType) String() string {
+	switch st {
+	case StackNone:
+		return "None"
+	case StackAny:
+		return "any"
+	case StackUint64:
+		return "uint64"
+	case StackBytes:
+		return "[]byte"
+	}
+	return "internal error, unknown type"
+}
+
+// OpSpec defines one byte opcode
+type OpSpec struct {
+	Opcode  byte
+	Name    string
+	op      opFunc      // evaluate the op
+	Args    []StackType // what gets popped from the stack
+	Returns []StackType // what gets pushed to the stack
+
+	// Cost is the cost of the op towards ConsensusParams.LogicSigMaxCost
+	Cost int
+
+	// checkFunc returns the size of the op and its immediate arguments,
+	// and validates them. ops without a checkFunc are one byte long.
+	check checkFunc
+}
+
+var oneBytes = []StackType{StackBytes}
+var threeBytes = []StackType{StackBytes, StackBytes, StackBytes}
+var oneInt = []StackType{StackUint64}
+var twoInts = []StackType{StackUint64, StackUint64}
+var oneAny = []StackType{StackAny}
+var twoAny = []StackType{StackAny, StackAny}
+
+// OpSpecs is the table of operations that can be assembled and evaluated.
+//
+// Any changes should be reflected in README.md which serves as the language spec.
+var OpSpecs = []OpSpec{
+	{0x00, "err", opErr, nil, nil, 1, nil},
+	{0x01, "sha256", opSHA256, oneBytes, oneBytes, 7, nil},
+	{0x03, "sha512_256", opSHA512_256, oneBytes, oneBytes, 9, nil},
+	{0x04, "ed25519verify", opEd25519verify, threeBytes, oneInt, 1900, nil},
+	{0x08, "+", opPlus, twoInts, oneInt, 1, nil},
+	{0x09, "-", opMinus, twoInts, oneInt, 1, nil},
+	{0x0a, "/", opDiv, twoInts, oneInt, 1, nil},
+	{0x0b, "*", opMul, twoInts, oneInt, 1, nil},
+	{0x0c, "<", opLt, twoInts, oneInt, 1, nil},
+	{0x0d, ">", opGt, twoInts, oneInt, 1, nil},
+	{0x0e, "<=", opLe, twoInts, oneInt, 1, nil},
+	{0x0f, ">=", opGe, twoInts, oneInt, 1, nil},
+	{0x10, "&&", opAnd, twoInts, oneInt, 1, nil},
+	{0x11, "||", opOr, twoInts, oneInt, 1, nil},
+	{0x12, "==", opEq, twoAny, oneInt, 1, nil},
+	{0x13, "!=", opNeq, twoAny, oneInt, 1, nil},
+	{0x14, "!", opNot, oneInt, oneInt, 1, nil},
+	{0x15, "len", opLen, oneBytes, oneInt, 1, nil},
+	{0x16, "itob", opItob, oneInt, oneBytes, 1, nil},
+	{0x17, "btoi", opBtoi, oneBytes, oneInt, 1, nil},
+	{0x18, "%", opModulo, twoInts, oneInt, 1, nil},
+	{0x19, "|", opBitOr, twoInts, oneInt, 1, nil},
+	{0x1a, "&", opBitAnd, twoInts, oneInt, 1, nil},
+	{0x1b, "^", opBitXor, twoInts, oneInt, 1, nil},
+	{0x1c, "~", opBitNot, oneInt, oneInt, 1, nil},
+
+	{0x20, "intcblock", opIntConstBlock, nil, nil, 1, checkIntConstBlock},
+	{0x21, "intc", opIntConstLoad, nil, oneInt, 1, checkOneImmediate},
+	{0x22, "intc_0", opIntConst0, nil, oneInt, 1, nil},
+	{0x23, "intc_1", opIntConst1, nil, oneInt, 1, nil},
+	{0x24, "intc_2", opIntConst2, nil, oneInt, 1, nil},
+	{0x25, "intc_3", opIntConst3, nil, oneInt, 1, nil},
+	{0x26, "bytecblock", opByteConstBlock, nil, nil, 1, checkByteConstBlock},
+	{0x27, "bytec", opByteConstLoad, nil, oneBytes, 1, checkOneImmediate},
+	{0x28, "bytec_0", opByteConst0, nil, oneBytes, 1, nil},
+	{0x29, "bytec_1", opByteConst1, nil, oneBytes, 1, nil},
+	{0x2a, "bytec_2", opByteConst2, nil, oneBytes, 1, nil},
+	{0x2b, "bytec_3", opByteConst3, nil, oneBytes, 1, nil},
+	{0x2c, "arg", opArg, nil, oneBytes, 1, checkOneImmediate},
+	{0x2d, "arg_0", opArg0, nil, oneBytes, 1, nil},
+	{0x2e, "arg_1", opArg1, nil, oneBytes, 1, nil},
+	{0x2f, "arg_2", opArg2, nil, oneBytes, 1, nil},
+	{0x30, "arg_3", opArg3, nil, oneBytes, 1, nil},
+	{0x31, "txn", opTxn, nil, oneAny, 1, checkOneImmediate},
+	{0x32, "global", opGlobal, nil, oneAny, 1, checkOneImmediate},
+	{0x33, "gtxn", opGtxn, nil, oneAny, 1, checkTwoImmediates},
+	{0x34, "load", opLoad, nil, oneAny, 1, checkOneImmediate},
+	{0x35, "store", opStore, oneAny, nil, 1, checkOneImmediate},
+
+	{0x40, "bnz", opBnz, oneInt, nil, 1, checkBnz},
+	{0x48, "pop", opPop, oneAny, nil, 1, nil},
+	{0x49, "dup", opDup, oneAny, twoAny, 1, nil},
+}
+
+// opsByOpcode is indexed by the opcode byte; unused opcodes have an empty Name
+var opsByOpcode [256]OpSpec
+
+// opsByName maps the mnemonic of each op to its OpSpec
+var opsByName map[string]OpSpec
+
+func init() {
+	opsByName = make(map[string]OpSpec, len(OpSpecs))
+	for _, oi := range OpSpecs {
+		opsByOpcode[oi.Opcode] = oi
+		opsByName[oi.Name] = oi
+	}
+}
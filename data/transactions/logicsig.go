@@ -0,0 +1,95 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package transactions
+
+import (
+	"bytes"
+
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/protocol"
+)
+
+// LogicSig contains logic for validating a transaction.
+// LogicSig is signed by an account, allowing that account to
+// delegate authority to the logic, or is not signed, in which
+// case the transaction must be sent from the address that is
+// the hash of the logic.
+type LogicSig struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	// Logic signed by Sig or Msig, OR hashed to be the Address of an account.
+	Logic []byte `codec:"l"`
+
+	Sig  crypto.Signature   `codec:"sig"`
+	Msig crypto.MultisigSig `codec:"msig"`
+
+	// Args are not signed, but checked by Logic
+	Args [][]byte `codec:"arg"`
+}
+
+// Blank returns true if there is no content in this LogicSig
+func (lsig *LogicSig) Blank() bool {
+	return len(lsig.Logic) == 0
+}
+
+// Len returns the length of Logic plus the length of the Args
+// This is limited by config.ConsensusParams.LogicSigMaxSize
+func (lsig *LogicSig) Len() int {
+	lsiglen := len(lsig.Logic)
+	for _, arg := range lsig.Args {
+		lsiglen += len(arg)
+	}
+	return lsiglen
+}
+
+// Equal returns true if both LogicSig are equivalent
+func (lsig *LogicSig) Equal(b *LogicSig) bool {
+	sigs := lsig.Sig == b.Sig && lsig.Msig.Equal(b.Msig)
+	if !sigs {
+		return false
+	}
+	if !bytes.Equal(lsig.Logic, b.Logic) {
+		return false
+	}
+
+	if len(lsig.Args) != len(b.Args) {
+		return false
+	}
+	for i := range lsig.Args {
+		if !bytes.Equal(lsig.Args[i], b.Args[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Program is the bytecode of a logic sig, which is hashed with its own
+// domain separation prefix, both to compute the address of an account
+// controlled by the program, and to delegate an account to the program.
+type Program []byte
+
+// ToBeHashed implements the crypto.Hashable interface
+func (prog Program) ToBeHashed() (protocol.HashID, []byte) {
+	return protocol.Program, []byte(prog)
+}
+
+// LogicSigAddress returns the address of the account controlled by the
+// given program.
+func LogicSigAddress(program []byte) basics.Address {
+	return basics.Address(crypto.HashObj(Program(program)))
+}
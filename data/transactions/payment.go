@@ -85,6 +85,18 @@ func (payment PaymentTxnFields) apply(header Header, balances Balances, spec Spe
 				return err
 			}
 
+			// Confirm that there is no asset-related state in the account
+			if len(rec.Assets) > 0 {
+				return fmt.Errorf("cannot close: %d outstanding assets", len(rec.Assets))
+			}
+
+			if len(rec.AssetParams) > 0 {
+				// This should be impossible because every asset created
+				// by an account (in AssetParams) must also appear in Assets,
+				// which we checked above.
+				return fmt.Errorf("cannot close: %d outstanding created assets", len(rec.AssetParams))
+			}
+
 			closeAmount := rec.AccountData.MicroAlgos
 			ad.ClosingAmount = closeAmount
 			err = balances.Move(header.Sender, payment.CloseRemainderTo, closeAmount, &ad.SenderRewards, &ad.CloseRewards)
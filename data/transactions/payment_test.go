@@ -88,6 +88,10 @@ func (balances mockBalances) Move(src, dst basics.Address, amount basics.MicroAl
 	return nil
 }
 
+func (balances mockBalances) GetAssetCreator(aidx basics.AssetIndex) (basics.Address, error) {
+	return basics.Address{}, nil
+}
+
 func (balances mockBalances) ConsensusParams() config.ConsensusParams {
 	return config.Consensus[balances.ConsensusVersion]
 }
@@ -114,7 +118,7 @@ func TestPaymentApply(t *testing.T) {
 			Amount:   basics.MicroAlgos{Raw: uint64(50)},
 		},
 	}
-	_, err := tx.Apply(mockBalV0, SpecialAddresses{FeeSink: feeSink})
+	_, err := tx.Apply(mockBalV0, SpecialAddresses{FeeSink: feeSink}, 0)
 	require.NoError(t, err)
 }
 
@@ -49,8 +49,14 @@ type SignedTxn struct {
 
 	Sig  crypto.Signature   `codec:"sig"`
 	Msig crypto.MultisigSig `codec:"msig"`
+	Lsig LogicSig           `codec:"lsig"`
 	Txn  Transaction        `codec:"txn"`
 
+	// AuthAddr is the address whose key (or multisig, or logic sig)
+	// authorized this transaction, if the sender has been rekeyed to
+	// it.  It is empty when the transaction is authorized by the sender.
+	AuthAddr basics.Address `codec:"sgnr"`
+
 	// The length of the encoded SignedTxn, used for computing the
 	// transaction's priority in the transaction pool.
 	cachedEncodingLen int
@@ -94,6 +100,16 @@ func (a TxnPriority) Mul(b uint64) TxnPriority {
 	return TxnPriority(basics.MulSaturate(uint64(a), b))
 }
 
+// FeePerByte returns the fee per byte of encoded transaction that gives a
+// transaction a priority of at least a, rounded up to whole MicroAlgos.
+func (a TxnPriority) FeePerByte() uint64 {
+	feePerByte := uint64(a) / maxTxnBytesForPriority
+	if uint64(a)%maxTxnBytesForPriority != 0 {
+		feePerByte++
+	}
+	return feePerByte
+}
+
 // InitCaches initializes caches inside of SignedTxn.
 func (s *SignedTxn) InitCaches() {
 	if s.cachedEncodingLen == 0 {
@@ -109,6 +125,24 @@ func (s *SignedTxn) ResetCaches() {
 	s.Txn.ResetCaches()
 }
 
+// Authorizer returns the address against which the signature, multisig
+// or logic sig of this transaction must be checked.
+func (s SignedTxn) Authorizer() basics.Address {
+	if s.AuthAddr == (basics.Address{}) {
+		return s.Txn.Sender
+	}
+	return s.AuthAddr
+}
+
+// CheckAuthAddr checks that the AuthAddr of this transaction is allowed
+// by the protocol.
+func (s SignedTxn) CheckAuthAddr(proto config.ConsensusParams) error {
+	if s.AuthAddr != (basics.Address{}) && !proto.SupportRekeying {
+		return errors.New("signedtxn has an auth address, but rekeying is not supported")
+	}
+	return nil
+}
+
 // ID returns the Txid (i.e., hash) of the underlying transaction.
 func (s SignedTxn) ID() Txid {
 	return s.Txn.ID()
@@ -158,6 +192,11 @@ func (s *SignedTxn) PtrPriority() TxnPriority {
 	return TxnPriority(basics.MulSaturate(s.Txn.TxFee().Raw, uint64(maxTxnBytesForPriority/encodingLen)))
 }
 
+// errLogicSig is returned when Verify or PoolVerify are asked to check a
+// transaction authorized by a logic sig, which requires the evaluator in
+// the logic package; see the verify package.
+var errLogicSig = errors.New("signedtxn with a logic sig must be checked with verify.Txn")
+
 // Verify that a SignedTxn has a good signature and that the underlying
 // transaction is properly constructed.
 // Note that this does not check whether a payset is valid against the ledger:
@@ -176,8 +215,16 @@ func (s SignedTxn) Verify(spec SpecialAddresses, proto config.ConsensusParams) e
 		return errors.New("signedtxn should only have one of Sig or Msig")
 	}
 
-	if !crypto.SignatureVerifier(s.Txn.Src()).Verify(s.Txn, s.Sig) {
-		if ok, _ := crypto.MultisigVerify(s.Txn, crypto.Digest(s.Txn.Src()), s.Msig); !ok {
+	if !s.Lsig.Blank() {
+		return errLogicSig
+	}
+
+	if err := s.CheckAuthAddr(proto); err != nil {
+		return err
+	}
+
+	if !crypto.SignatureVerifier(s.Authorizer()).Verify(s.Txn, s.Sig) {
+		if ok, _ := crypto.MultisigVerify(s.Txn, crypto.Digest(s.Authorizer()), s.Msig); !ok {
 			return errors.New("signature (and multisig) failed to verify")
 		}
 		return nil
@@ -192,7 +239,9 @@ func (s SignedTxn) Verify(spec SpecialAddresses, proto config.ConsensusParams) e
 // a SignedTxn may be well-formed, but a payset might contain an overspend.
 //
 // This version of verify is performing the verification over the provided execution pool.
-func (s SignedTxn) PoolVerify(spec SpecialAddresses, proto config.ConsensusParams, verificationPool execpool.BacklogPool) error {
+// It gives up, returning the context's error, if enqueueCtx is done before the
+// signature is verified.
+func (s SignedTxn) PoolVerify(enqueueCtx context.Context, spec SpecialAddresses, proto config.ConsensusParams, verificationPool execpool.BacklogPool) error {
 	if err := s.Txn.WellFormed(spec, proto); err != nil {
 		return err
 	}
@@ -206,18 +255,33 @@ func (s SignedTxn) PoolVerify(spec SpecialAddresses, proto config.ConsensusParam
 		return errors.New("signedtxn should only have one of Sig or Msig")
 	}
 
+	if !s.Lsig.Blank() {
+		return errLogicSig
+	}
+
+	if err := s.CheckAuthAddr(proto); err != nil {
+		return err
+	}
+
 	outCh := make(chan error, 1)
-	verificationPool.EnqueueBacklog(context.Background(), s.asyncVerify, outCh, nil)
-	if err, hasErr := <-outCh; hasErr {
+	if err := verificationPool.EnqueueBacklog(enqueueCtx, s.asyncVerify, outCh, nil); err != nil {
 		return err
 	}
-	return nil
+	select {
+	case err, hasErr := <-outCh:
+		if hasErr {
+			return err
+		}
+		return nil
+	case <-enqueueCtx.Done():
+		return enqueueCtx.Err()
+	}
 }
 
 func (s SignedTxn) asyncVerify(arg interface{}) interface{} {
 	outCh := arg.(chan error)
-	if !crypto.SignatureVerifier(s.Txn.Src()).Verify(s.Txn, s.Sig) {
-		if ok, _ := crypto.MultisigVerify(s.Txn, crypto.Digest(s.Txn.Src()), s.Msig); !ok {
+	if !crypto.SignatureVerifier(s.Authorizer()).Verify(s.Txn, s.Sig) {
+		if ok, _ := crypto.MultisigVerify(s.Txn, crypto.Digest(s.Authorizer()), s.Msig); !ok {
 			outCh <- errors.New("signature (and multisig) failed to verify")
 		}
 	}
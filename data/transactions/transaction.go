@@ -60,6 +60,10 @@ type Balances interface {
 	// TODO: Does this need to be part of the balances interface, or can it just be implemented here as a function that calls Put and Get?
 	Move(src, dst basics.Address, amount basics.MicroAlgos, srcRewards *basics.MicroAlgos, dstRewards *basics.MicroAlgos) error
 
+	// GetAssetCreator gets the address of the account whose balance record
+	// contains the asset params for the given asset index.
+	GetAssetCreator(aidx basics.AssetIndex) (basics.Address, error)
+
 	// Balances correspond to a Round, which mean that they also correspond
 	// to a ConsensusParams.  This returns those parameters.
 	ConsensusParams() config.ConsensusParams
@@ -76,6 +80,23 @@ type Header struct {
 	Note        []byte            `codec:"note"` // Uniqueness or app-level data about txn
 	GenesisID   string            `codec:"gen"`
 	GenesisHash crypto.Digest     `codec:"gh"`
+
+	// Group specifies that this transaction is part of a
+	// transaction group (and, if so, specifies the hash
+	// of a TxGroup).
+	Group crypto.Digest `codec:"grp"`
+
+	// Lease enforces mutual exclusion of transactions.  If this field is
+	// nonzero, then once the transaction is confirmed, it acquires the
+	// lease identified by the (Sender, Lease) pair of the transaction until
+	// the LastValid round passes.  While this transaction possesses the
+	// lease, no other transaction specifying this lease can be confirmed.
+	Lease [32]byte `codec:"lx"`
+
+	// RekeyTo, if nonzero, sets the sender's AuthAddr to the given address
+	// once this transaction is applied.  Rekeying an account to its own
+	// address clears its AuthAddr.
+	RekeyTo basics.Address `codec:"rekey"`
 }
 
 // Transaction describes a transaction that can appear in a block.
@@ -91,6 +112,9 @@ type Transaction struct {
 	// Fields for different types of transactions
 	KeyregTxnFields
 	PaymentTxnFields
+	AssetConfigTxnFields
+	AssetTransferTxnFields
+	AssetFreezeTxnFields
 
 	// The transaction's Txid is computed when we decode,
 	// and cached here, to avoid needlessly recomputing it.
@@ -101,6 +125,22 @@ type Transaction struct {
 	valid bool
 }
 
+// Txlease identifies a transaction lease: a sender may hold at most one
+// transaction with a given lease until that transaction expires.
+type Txlease struct {
+	Sender basics.Address
+	Lease  [32]byte
+}
+
+// LeaseHeld returns the lease that tx acquires once confirmed, and false
+// if tx does not set one.
+func (tx Transaction) LeaseHeld() (Txlease, bool) {
+	if tx.Lease == ([32]byte{}) {
+		return Txlease{}, false
+	}
+	return Txlease{Sender: tx.Sender, Lease: tx.Lease}, true
+}
+
 // ApplyData contains information about the transaction's execution.
 type ApplyData struct {
 	_struct struct{} `codec:",omitempty,omitemptyarray"`
@@ -114,6 +154,23 @@ type ApplyData struct {
 	CloseRewards    basics.MicroAlgos `codec:"rc"`
 }
 
+// TxGroup describes a group of transactions that must appear
+// together in a specific order in a block.
+type TxGroup struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	// TxGroupHashes specifies a list of hashes of transactions that must appear
+	// together, sequentially, in a block in order for the group to be
+	// valid.  Each hash in the list is a hash of a transaction with
+	// the `Group` field omitted.
+	TxGroupHashes []crypto.Digest `codec:"txlist"`
+}
+
+// ToBeHashed implements the crypto.Hashable interface.
+func (tg TxGroup) ToBeHashed() (protocol.HashID, []byte) {
+	return protocol.TxGroup, protocol.Encode(tg)
+}
+
 // ToBeHashed implements the crypto.Hashable interface.
 func (tx Transaction) ToBeHashed() (protocol.HashID, []byte) {
 	return protocol.Transaction, protocol.Encode(tx)
@@ -225,6 +282,26 @@ func (tx Transaction) WellFormed(spec SpecialAddresses, proto config.ConsensusPa
 	case protocol.KeyRegistrationTx:
 		// All OK
 
+	case protocol.AssetConfigTx:
+		if !proto.Asset {
+			return fmt.Errorf("asset transaction not supported")
+		}
+
+		err := tx.AssetConfigTxnFields.wellFormed(proto)
+		if err != nil {
+			return err
+		}
+
+	case protocol.AssetTransferTx:
+		if !proto.Asset {
+			return fmt.Errorf("asset transaction not supported")
+		}
+
+	case protocol.AssetFreezeTx:
+		if !proto.Asset {
+			return fmt.Errorf("asset transaction not supported")
+		}
+
 	default:
 		return fmt.Errorf("unknown tx type %v", tx.Type)
 	}
@@ -238,6 +315,18 @@ func (tx Transaction) WellFormed(spec SpecialAddresses, proto config.ConsensusPa
 		nonZeroFields[protocol.KeyRegistrationTx] = true
 	}
 
+	if tx.AssetConfigTxnFields != (AssetConfigTxnFields{}) {
+		nonZeroFields[protocol.AssetConfigTx] = true
+	}
+
+	if tx.AssetTransferTxnFields != (AssetTransferTxnFields{}) {
+		nonZeroFields[protocol.AssetTransferTx] = true
+	}
+
+	if tx.AssetFreezeTxnFields != (AssetFreezeTxnFields{}) {
+		nonZeroFields[protocol.AssetFreezeTx] = true
+	}
+
 	for t, nonZero := range nonZeroFields {
 		if nonZero && t != tx.Type {
 			return fmt.Errorf("transaction of type %v has non-zero fields for type %v", tx.Type, t)
@@ -260,6 +349,21 @@ func (tx Transaction) WellFormed(spec SpecialAddresses, proto config.ConsensusPa
 		// this check is just to be safe, but reaching here seems impossible, since it requires computing a preimage of rwpool
 		return fmt.Errorf("transaction from incentive pool is invalid")
 	}
+	if !proto.SupportTxGroups {
+		if tx.Group != (crypto.Digest{}) {
+			return fmt.Errorf("transaction groups not supported")
+		}
+	}
+	if !proto.SupportTransactionLeases {
+		if tx.Lease != ([32]byte{}) {
+			return fmt.Errorf("transaction leases not supported")
+		}
+	}
+	if !proto.SupportRekeying {
+		if tx.RekeyTo != (basics.Address{}) {
+			return fmt.Errorf("transaction tries to rekey an account, but rekeying is not supported")
+		}
+	}
 	return nil
 }
 
@@ -289,6 +393,16 @@ func (tx Transaction) RelevantAddrs(spec SpecialAddresses, proto config.Consensu
 		if tx.PaymentTxnFields.CloseRemainderTo != (basics.Address{}) {
 			addrs = append(addrs, tx.PaymentTxnFields.CloseRemainderTo)
 		}
+	case protocol.AssetTransferTx:
+		addrs = append(addrs, tx.AssetTransferTxnFields.AssetReceiver)
+		if !tx.AssetTransferTxnFields.AssetSender.IsZero() {
+			addrs = append(addrs, tx.AssetTransferTxnFields.AssetSender)
+		}
+		if !tx.AssetTransferTxnFields.AssetCloseTo.IsZero() {
+			addrs = append(addrs, tx.AssetTransferTxnFields.AssetCloseTo)
+		}
+	case protocol.AssetFreezeTx:
+		addrs = append(addrs, tx.AssetFreezeTxnFields.FreezeAccount)
 	}
 
 	return addrs
@@ -319,7 +433,7 @@ func (tx Transaction) SenderDeduction() (amount basics.MicroAlgos, empty bool, e
 		if overflow {
 			err = fmt.Errorf("overflowed computing sender deduction for transaction %v (fee %v, amount %v)", tx.ID(), tx.Fee, paymentAmount)
 		}
-	case protocol.KeyRegistrationTx:
+	case protocol.KeyRegistrationTx, protocol.AssetConfigTx, protocol.AssetTransferTx, protocol.AssetFreezeTx:
 		// no additional spend over the fee
 	default:
 		err = fmt.Errorf("unknown transaction type %v", tx.Type)
@@ -338,7 +452,9 @@ func (tx Transaction) EstimateEncodedSize() int {
 }
 
 // Apply changes the balances according to this transaction.
-func (tx Transaction) Apply(balances Balances, spec SpecialAddresses) (ad ApplyData, err error) {
+// The ctr argument is the number of transactions committed to the ledger
+// before this one, which is used to allocate indexes for newly created assets.
+func (tx Transaction) Apply(balances Balances, spec SpecialAddresses, ctr uint64) (ad ApplyData, err error) {
 	params := balances.ConsensusParams()
 
 	// move fee to pool
@@ -347,6 +463,29 @@ func (tx Transaction) Apply(balances Balances, spec SpecialAddresses) (ad ApplyD
 		return
 	}
 
+	// rekey the sender before applying the transaction, so that closing
+	// out the account also clears its AuthAddr
+	if tx.RekeyTo != (basics.Address{}) {
+		var record basics.BalanceRecord
+		record, err = balances.Get(tx.Sender)
+		if err != nil {
+			return
+		}
+
+		// rekeying an account to its own address resets it to sign
+		// with its own key
+		if tx.RekeyTo == tx.Sender {
+			record.AuthAddr = basics.Address{}
+		} else {
+			record.AuthAddr = tx.RekeyTo
+		}
+
+		err = balances.Put(record)
+		if err != nil {
+			return
+		}
+	}
+
 	switch tx.Type {
 	case protocol.PaymentTx:
 		err = tx.PaymentTxnFields.apply(tx.Header, balances, spec, &ad)
@@ -354,6 +493,15 @@ func (tx Transaction) Apply(balances Balances, spec SpecialAddresses) (ad ApplyD
 	case protocol.KeyRegistrationTx:
 		err = tx.KeyregTxnFields.apply(tx.Header, balances, spec, &ad)
 
+	case protocol.AssetConfigTx:
+		err = tx.AssetConfigTxnFields.apply(tx.Header, balances, spec, &ad, ctr)
+
+	case protocol.AssetTransferTx:
+		err = tx.AssetTransferTxnFields.apply(tx.Header, balances, spec, &ad)
+
+	case protocol.AssetFreezeTx:
+		err = tx.AssetFreezeTxnFields.apply(tx.Header, balances, spec, &ad)
+
 	default:
 		err = fmt.Errorf("Unknown transaction type %v", tx.Type)
 	}
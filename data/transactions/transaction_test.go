@@ -51,3 +51,57 @@ func TestTransaction_EstimateEncodedSize(t *testing.T) {
 
 	require.Equal(t, 200, tx.EstimateEncodedSize())
 }
+
+func TestWellFormedTxGroup(t *testing.T) {
+	addr, err := basics.UnmarshalChecksumAddress("NDQCJNNY5WWWFLP4GFZ7MEF2QJSMZYK6OWIV2AQ7OMAVLEFCGGRHFPKJJA")
+	require.NoError(t, err)
+
+	proto := config.Consensus[protocol.ConsensusCurrentVersion]
+	tx := Transaction{
+		Type: protocol.PaymentTx,
+		Header: Header{
+			Sender:     addr,
+			Fee:        basics.MicroAlgos{Raw: proto.MinTxnFee},
+			FirstValid: basics.Round(1000),
+			LastValid:  basics.Round(1000 + proto.MaxTxnLife),
+		},
+		PaymentTxnFields: PaymentTxnFields{
+			Receiver: addr,
+			Amount:   basics.MicroAlgos{Raw: 100},
+		},
+	}
+	require.NoError(t, tx.WellFormed(SpecialAddresses{}, proto))
+
+	crypto.RandBytes(tx.Group[:])
+	require.Error(t, tx.WellFormed(SpecialAddresses{}, proto))
+
+	future := config.Consensus[protocol.ConsensusFuture]
+	require.NoError(t, tx.WellFormed(SpecialAddresses{}, future))
+}
+
+func TestWellFormedLease(t *testing.T) {
+	addr, err := basics.UnmarshalChecksumAddress("NDQCJNNY5WWWFLP4GFZ7MEF2QJSMZYK6OWIV2AQ7OMAVLEFCGGRHFPKJJA")
+	require.NoError(t, err)
+
+	proto := config.Consensus[protocol.ConsensusCurrentVersion]
+	tx := Transaction{
+		Type: protocol.PaymentTx,
+		Header: Header{
+			Sender:     addr,
+			Fee:        basics.MicroAlgos{Raw: proto.MinTxnFee},
+			FirstValid: basics.Round(1000),
+			LastValid:  basics.Round(1000 + proto.MaxTxnLife),
+		},
+		PaymentTxnFields: PaymentTxnFields{
+			Receiver: addr,
+			Amount:   basics.MicroAlgos{Raw: 100},
+		},
+	}
+	require.NoError(t, tx.WellFormed(SpecialAddresses{}, proto))
+
+	crypto.RandBytes(tx.Lease[:])
+	require.Error(t, tx.WellFormed(SpecialAddresses{}, proto))
+
+	future := config.Consensus[protocol.ConsensusFuture]
+	require.NoError(t, tx.WellFormed(SpecialAddresses{}, future))
+}
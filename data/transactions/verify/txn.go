@@ -0,0 +1,205 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package verify checks that signed transactions are properly authorized,
+// by a signature, a multisig or a logic sig.
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/algorand/go-algorand/config"
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/data/transactions/logic"
+	"github.com/algorand/go-algorand/util/execpool"
+)
+
+// Context encapsulates the context needed to verify a signed transaction:
+// the protocol in effect, and the transaction group that the transaction
+// belongs to, which a logic sig may inspect.
+type Context struct {
+	Spec  transactions.SpecialAddresses
+	Proto config.ConsensusParams
+
+	Group      []transactions.SignedTxn
+	GroupIndex int
+}
+
+// Txn verifies that a SignedTxn is well-formed and properly authorized,
+// by a signature, a multisig or a logic sig.
+func Txn(s *transactions.SignedTxn, ctx Context) error {
+	if s.Lsig.Blank() {
+		return s.Verify(ctx.Spec, ctx.Proto)
+	}
+
+	err := checkLogicSigTxn(s, ctx)
+	if err != nil {
+		return err
+	}
+	return LogicSig(s, ctx)
+}
+
+// TxnPool is like Txn, but performs the signature verification over the
+// provided execution pool.  It returns enqueueCtx's error if enqueueCtx is
+// done before the verification completes.
+func TxnPool(enqueueCtx context.Context, s *transactions.SignedTxn, ctx Context, verificationPool execpool.BacklogPool) error {
+	if s.Lsig.Blank() {
+		return s.PoolVerify(enqueueCtx, ctx.Spec, ctx.Proto, verificationPool)
+	}
+
+	err := checkLogicSigTxn(s, ctx)
+	if err != nil {
+		return err
+	}
+
+	outCh := make(chan error, 1)
+	err = verificationPool.EnqueueBacklog(enqueueCtx, func(arg interface{}) interface{} {
+		outCh <- LogicSig(s, ctx)
+		return nil
+	}, nil, nil)
+	if err != nil {
+		return err
+	}
+	select {
+	case err = <-outCh:
+		return err
+	case <-enqueueCtx.Done():
+		return enqueueCtx.Err()
+	}
+}
+
+// TxnGroup verifies every transaction in a transaction group, giving each
+// of them the context of the whole group.
+func TxnGroup(stxs []transactions.SignedTxn, spec transactions.SpecialAddresses, proto config.ConsensusParams) error {
+	for i := range stxs {
+		ctx := Context{
+			Spec:       spec,
+			Proto:      proto,
+			Group:      stxs,
+			GroupIndex: i,
+		}
+		err := Txn(&stxs[i], ctx)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TxnGroupPool is like TxnGroup, but performs the signature verification
+// over the provided execution pool.
+func TxnGroupPool(enqueueCtx context.Context, stxs []transactions.SignedTxn, spec transactions.SpecialAddresses, proto config.ConsensusParams, verificationPool execpool.BacklogPool) error {
+	for i := range stxs {
+		ctx := Context{
+			Spec:       spec,
+			Proto:      proto,
+			Group:      stxs,
+			GroupIndex: i,
+		}
+		err := TxnPool(enqueueCtx, &stxs[i], ctx, verificationPool)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkLogicSigTxn performs the checks of SignedTxn.Verify that do not
+// depend on how the transaction is authorized.
+func checkLogicSigTxn(s *transactions.SignedTxn, ctx Context) error {
+	if err := s.Txn.WellFormed(ctx.Spec, ctx.Proto); err != nil {
+		return err
+	}
+
+	if s.Txn.Src() == (basics.Address{}) {
+		return errors.New("empty address")
+	}
+
+	if s.Sig != (crypto.Signature{}) || !s.Msig.Blank() {
+		return errors.New("signedtxn should only have one of Sig or Msig or LogicSig")
+	}
+	return s.CheckAuthAddr(ctx.Proto)
+}
+
+// LogicSig checks that the logic sig of a SignedTxn approves the
+// transaction, and that the authorizer (the sender, or the address it
+// was rekeyed to) either delegated to the program or is the address of
+// the program.
+func LogicSig(txn *transactions.SignedTxn, ctx Context) error {
+	lsig := txn.Lsig
+	if ctx.Proto.LogicSigVersion == 0 {
+		return errors.New("LogicSig not enabled")
+	}
+	if len(lsig.Logic) == 0 {
+		return errors.New("LogicSig.Logic empty")
+	}
+	if uint64(lsig.Len()) > ctx.Proto.LogicSigMaxSize {
+		return fmt.Errorf("LogicSig.Logic too long, %d > %d", lsig.Len(), ctx.Proto.LogicSigMaxSize)
+	}
+
+	hasMsig := !lsig.Msig.Blank()
+	hasSig := lsig.Sig != (crypto.Signature{})
+	if hasMsig && hasSig {
+		return errors.New("LogicSig should only have one of Sig or Msig")
+	}
+
+	program := transactions.Program(lsig.Logic)
+	switch {
+	case hasSig:
+		if !crypto.SignatureVerifier(txn.Authorizer()).Verify(program, lsig.Sig) {
+			return errors.New("logic signature failed to verify")
+		}
+	case hasMsig:
+		if ok, _ := crypto.MultisigVerify(program, crypto.Digest(txn.Authorizer()), lsig.Msig); !ok {
+			return errors.New("logic multisig failed to verify")
+		}
+	default:
+		if txn.Authorizer() != transactions.LogicSigAddress(lsig.Logic) {
+			return errors.New("LogicSig not signed and authorizer is not the program address")
+		}
+	}
+
+	ep := logic.EvalParams{
+		Txn:        txn,
+		Proto:      &ctx.Proto,
+		TxnGroup:   ctx.Group,
+		GroupIndex: ctx.GroupIndex,
+	}
+	if ep.TxnGroup == nil {
+		ep.TxnGroup = []transactions.SignedTxn{*txn}
+		ep.GroupIndex = 0
+	}
+	cost, err := logic.Check(lsig.Logic, ep)
+	if err != nil {
+		return fmt.Errorf("transaction %v: rejected by logic: %v", txn.ID(), err)
+	}
+	if uint64(cost) > ctx.Proto.LogicSigMaxCost {
+		return fmt.Errorf("transaction %v: rejected by logic: cost %d > %d", txn.ID(), cost, ctx.Proto.LogicSigMaxCost)
+	}
+
+	pass, err := logic.Eval(lsig.Logic, ep)
+	if err != nil {
+		return fmt.Errorf("transaction %v: rejected by logic err=%v", txn.ID(), err)
+	}
+	if !pass {
+		return fmt.Errorf("transaction %v: rejected by logic", txn.ID())
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package verify
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/go-algorand/config"
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/data/transactions/logic"
+	"github.com/algorand/go-algorand/protocol"
+	"github.com/algorand/go-algorand/util/execpool"
+)
+
+func makeKeys() *crypto.SignatureSecrets {
+	var seed crypto.Seed
+	crypto.RandBytes(seed[:])
+	return crypto.GenerateSignatureSecrets(seed)
+}
+
+func makePayment(sender basics.Address, proto config.ConsensusParams) transactions.Transaction {
+	return transactions.Transaction{
+		Type: protocol.PaymentTx,
+		Header: transactions.Header{
+			Sender:     sender,
+			Fee:        basics.MicroAlgos{Raw: proto.MinTxnFee},
+			FirstValid: 1,
+			LastValid:  100,
+		},
+		PaymentTxnFields: transactions.PaymentTxnFields{
+			Receiver: basics.Address{0x01},
+			Amount:   basics.MicroAlgos{Raw: 1000},
+		},
+	}
+}
+
+func TestTxnLogicSigEscrow(t *testing.T) {
+	proto := config.Consensus[protocol.ConsensusFuture]
+	program, err := logic.AssembleString("txn Amount\nint 1000\n<=")
+	require.NoError(t, err)
+	escrow := transactions.LogicSigAddress(program)
+
+	stxn := transactions.SignedTxn{
+		Txn:  makePayment(escrow, proto),
+		Lsig: transactions.LogicSig{Logic: program},
+	}
+	ctx := Context{Proto: proto}
+	require.NoError(t, Txn(&stxn, ctx))
+
+	// the program rejects the transaction
+	rejected := stxn
+	rejected.Txn.Amount = basics.MicroAlgos{Raw: 1001}
+	require.Error(t, Txn(&rejected, ctx))
+
+	// the sender is neither the program address nor signed the program
+	wrongSender := stxn
+	wrongSender.Txn.Sender = basics.Address{0x02}
+	require.Error(t, Txn(&wrongSender, ctx))
+
+	// a logic sig cannot be combined with a signature of the transaction
+	withSig := stxn
+	withSig.Sig = crypto.Signature{0x01}
+	require.Error(t, Txn(&withSig, ctx))
+
+	// the logic sig must be checked through this package
+	require.Error(t, stxn.Verify(ctx.Spec, ctx.Proto))
+
+	// logic sigs are rejected by protocols that do not enable them
+	disabled := ctx
+	disabled.Proto.LogicSigVersion = 0
+	require.Error(t, Txn(&stxn, disabled))
+
+	tooLong := ctx
+	tooLong.Proto.LogicSigMaxSize = uint64(len(program) - 1)
+	require.Error(t, Txn(&stxn, tooLong))
+
+	tooCostly := ctx
+	tooCostly.Proto.LogicSigMaxCost = 1
+	require.Error(t, Txn(&stxn, tooCostly))
+}
+
+func TestTxnLogicSigDelegated(t *testing.T) {
+	proto := config.Consensus[protocol.ConsensusFuture]
+	secrets := makeKeys()
+	sender := basics.Address(secrets.SignatureVerifier)
+
+	program, err := logic.AssembleString("txn Fee\nglobal MinTxnFee\n==")
+	require.NoError(t, err)
+
+	stxn := transactions.SignedTxn{
+		Txn: makePayment(sender, proto),
+		Lsig: transactions.LogicSig{
+			Logic: program,
+			Sig:   secrets.Sign(transactions.Program(program)),
+		},
+	}
+	ctx := Context{Proto: proto}
+	require.NoError(t, Txn(&stxn, ctx))
+
+	// the delegation does not extend to other programs
+	other, err := logic.AssembleString("int 1")
+	require.NoError(t, err)
+	forged := stxn
+	forged.Lsig.Logic = other
+	require.Error(t, Txn(&forged, ctx))
+
+	// nor to other senders
+	forged = stxn
+	forged.Txn.Sender = basics.Address(makeKeys().SignatureVerifier)
+	require.Error(t, Txn(&forged, ctx))
+}
+
+func TestTxnRekeyed(t *testing.T) {
+	proto := config.Consensus[protocol.ConsensusFuture]
+	senderKeys := makeKeys()
+	authKeys := makeKeys()
+	sender := basics.Address(senderKeys.SignatureVerifier)
+	auth := basics.Address(authKeys.SignatureVerifier)
+
+	txn := makePayment(sender, proto)
+	ctx := Context{Proto: proto}
+
+	// a transaction signed by the auth address verifies against it
+	stxn := txn.Sign(authKeys)
+	stxn.AuthAddr = auth
+	require.NoError(t, Txn(&stxn, ctx))
+
+	// but not against the sender
+	stxn.AuthAddr = basics.Address{}
+	require.Error(t, Txn(&stxn, ctx))
+
+	// and the sender's signature does not verify against the auth address
+	stxn = txn.Sign(senderKeys)
+	stxn.AuthAddr = auth
+	require.Error(t, Txn(&stxn, ctx))
+
+	// protocols without rekeying reject an auth address
+	stxn = txn.Sign(authKeys)
+	stxn.AuthAddr = auth
+	disabled := ctx
+	disabled.Proto.SupportRekeying = false
+	require.Error(t, Txn(&stxn, disabled))
+
+	// a logic sig delegated by the auth address authorizes the sender
+	program, err := logic.AssembleString("int 1")
+	require.NoError(t, err)
+	lstxn := transactions.SignedTxn{
+		Txn:      txn,
+		AuthAddr: auth,
+		Lsig: transactions.LogicSig{
+			Logic: program,
+			Sig:   authKeys.Sign(transactions.Program(program)),
+		},
+	}
+	require.NoError(t, Txn(&lstxn, ctx))
+
+	lstxn.Lsig.Sig = senderKeys.Sign(transactions.Program(program))
+	require.Error(t, Txn(&lstxn, ctx))
+}
+
+func TestTxnGroupLogicSig(t *testing.T) {
+	proto := config.Consensus[protocol.ConsensusFuture]
+	secrets := makeKeys()
+	buyer := basics.Address(secrets.SignatureVerifier)
+
+	// the escrow only pays out when it is paid in the same group
+	program, err := logic.AssembleString(`global GroupSize
+int 2
+==
+gtxn 0 Receiver
+txn Sender
+==
+&&
+gtxn 0 Amount
+int 500
+>=
+&&`)
+	require.NoError(t, err)
+	escrow := transactions.LogicSigAddress(program)
+
+	pay := makePayment(buyer, proto)
+	pay.Receiver = escrow
+	pay.Amount = basics.MicroAlgos{Raw: 500}
+	payout := makePayment(escrow, proto)
+
+	var group transactions.TxGroup
+	group.TxGroupHashes = []crypto.Digest{crypto.HashObj(pay), crypto.HashObj(payout)}
+	gid := crypto.HashObj(group)
+	pay.Group = gid
+	payout.Group = gid
+
+	stxns := []transactions.SignedTxn{
+		pay.Sign(secrets),
+		{Txn: payout, Lsig: transactions.LogicSig{Logic: program}},
+	}
+	require.NoError(t, TxnGroup(stxns, transactions.SpecialAddresses{}, proto))
+
+	// the payout alone is rejected
+	require.Error(t, TxnGroup(stxns[1:], transactions.SpecialAddresses{}, proto))
+}
+
+// droppingBacklog accepts tasks without ever running them, like a backlog
+// that is shutting down.
+type droppingBacklog struct {
+	execpool.BacklogPool
+}
+
+func (b droppingBacklog) EnqueueBacklog(enqueueCtx context.Context, t execpool.ExecFunc, arg interface{}, out chan interface{}) error {
+	return nil
+}
+
+func TestTxnGroupPoolContext(t *testing.T) {
+	proto := config.Consensus[protocol.ConsensusFuture]
+	secrets := makeKeys()
+	sender := basics.Address(secrets.SignatureVerifier)
+
+	program, err := logic.AssembleString("int 1")
+	require.NoError(t, err)
+	escrow := transactions.LogicSigAddress(program)
+
+	stxns := []transactions.SignedTxn{
+		makePayment(sender, proto).Sign(secrets),
+		{Txn: makePayment(escrow, proto), Lsig: transactions.LogicSig{Logic: program}},
+	}
+
+	backlog := execpool.MakeBacklog(nil, 1, execpool.LowPriority, nil)
+	defer backlog.Shutdown()
+	require.NoError(t, TxnGroupPool(context.Background(), stxns, transactions.SpecialAddresses{}, proto, backlog))
+
+	// a canceled context stops the verification instead of waiting for
+	// tasks that never run
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i := range stxns {
+		err = TxnGroupPool(ctx, stxns[i:i+1], transactions.SpecialAddresses{}, proto, droppingBacklog{})
+		require.Equal(t, context.Canceled, err)
+	}
+}
@@ -19,12 +19,14 @@ package data
 import (
 	"context"
 	"fmt"
+	"io"
 	"sync"
 
 	"github.com/algorand/go-algorand/config"
 	"github.com/algorand/go-algorand/crypto"
 	"github.com/algorand/go-algorand/data/pools"
 	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/data/transactions/verify"
 	"github.com/algorand/go-algorand/logging"
 	"github.com/algorand/go-algorand/network"
 	"github.com/algorand/go-algorand/protocol"
@@ -41,13 +43,13 @@ var transactionMessagesHandled = metrics.MakeCounter(metrics.TransactionMessages
 var transactionMessagesDroppedFromBacklog = metrics.MakeCounter(metrics.TransactionMessagesDroppedFromBacklog)
 var transactionMessagesDroppedFromPool = metrics.MakeCounter(metrics.TransactionMessagesDroppedFromPool)
 
-// The txBacklogMsg structure used to track a single incoming transaction from the gossip network,
+// The txBacklogMsg structure used to track a single incoming transaction group from the gossip network,
 type txBacklogMsg struct {
-	rawmsg          *network.IncomingMessage      // the raw message from the network
-	unverifiedTxn   *transactions.SignedTxn       // the unverified ( and signed ) transaction
-	proto           config.ConsensusParams        // the consensus parameters that corresponds to the latest round. Filled in during checkAlreadyCommitted execution.
-	spec            transactions.SpecialAddresses // corresponds to the latest round
-	verificationErr error                         // The verification error generated by the verification function, if any.
+	rawmsg            *network.IncomingMessage      // the raw message from the network
+	unverifiedTxGroup []transactions.SignedTxn      // the unverified ( and signed ) transaction group
+	proto             config.ConsensusParams        // the consensus parameters that corresponds to the latest round. Filled in during checkAlreadyCommitted execution.
+	spec              transactions.SpecialAddresses // corresponds to the latest round
+	verificationErr   error                         // The verification error generated by the verification function, if any.
 }
 
 // TxHandler handles transaction messages
@@ -122,15 +124,15 @@ func (handler *TxHandler) backlogWorker() {
 			}
 			if wi.verificationErr != nil {
 				// disconnect from peer.
-				logging.Base().Warnf("Received a malformed txn %v: %v", wi.unverifiedTxn, wi.verificationErr)
+				logging.Base().Warnf("Received a malformed tx group %v: %v", wi.unverifiedTxGroup, wi.verificationErr)
 				handler.net.Disconnect(wi.rawmsg.Sender)
 				continue
 			}
-			// at this point, we've verified the transaction, so we can safely treat the transaction as a verified transaction.
-			verifiedTxn := wi.unverifiedTxn
+			// at this point, we've verified the transaction group, so we can safely treat the transactions as verified transactions.
+			verifiedTxGroup := wi.unverifiedTxGroup
 
-			// save the transaction, if it has high enough fee and not already in the cache
-			err := handler.txPool.Remember(*verifiedTxn)
+			// save the transaction group, if it has high enough fee and not already in the cache
+			err := handler.txPool.Remember(verifiedTxGroup)
 			if err != nil {
 				logging.Base().Debugf("could not remember tx: %v", err)
 				continue
@@ -159,7 +161,7 @@ func (handler *TxHandler) backlogWorker() {
 			}
 			if wi.verificationErr != nil {
 				// disconnect from peer.
-				logging.Base().Warnf("Received a malformed txn %v: %v", wi.unverifiedTxn, wi.verificationErr)
+				logging.Base().Warnf("Received a malformed tx group %v: %v", wi.unverifiedTxGroup, wi.verificationErr)
 				handler.net.Disconnect(wi.rawmsg.Sender)
 				continue
 			}
@@ -167,11 +169,11 @@ func (handler *TxHandler) backlogWorker() {
 			// we've processed this message, so increase the counter.
 			transactionMessagesHandled.Inc(nil)
 
-			// at this point, we've verified the transaction, so we can safely treat the transaction as a verified transaction.
-			verifiedTxn := wi.unverifiedTxn
+			// at this point, we've verified the transaction group, so we can safely treat the transactions as verified transactions.
+			verifiedTxGroup := wi.unverifiedTxGroup
 
-			// save the transaction, if it has high enough fee and not already in the cache
-			err := handler.txPool.Remember(*verifiedTxn)
+			// save the transaction group, if it has high enough fee and not already in the cache
+			err := handler.txPool.Remember(verifiedTxGroup)
 			if err != nil {
 				logging.Base().Debugf("could not remember tx: %v", err)
 				continue
@@ -183,10 +185,10 @@ func (handler *TxHandler) backlogWorker() {
 	}
 }
 
-// asyncVerifySignature verifies that the given transaction is valid, and update the txBacklogMsg data structure accordingly.
+// asyncVerifySignature verifies that the given transaction group is valid, and update the txBacklogMsg data structure accordingly.
 func (handler *TxHandler) asyncVerifySignature(arg interface{}) interface{} {
 	tx := arg.(*txBacklogMsg)
-	tx.verificationErr = tx.unverifiedTxn.Verify(tx.spec, tx.proto)
+	tx.verificationErr = verify.TxnGroup(tx.unverifiedTxGroup, tx.spec, tx.proto)
 	select {
 	case handler.postVerificationQueue <- tx:
 	default:
@@ -197,9 +199,31 @@ func (handler *TxHandler) asyncVerifySignature(arg interface{}) interface{} {
 	return nil
 }
 
+// decodeTxGroup decodes a transaction group, which is sent over the network
+// as the concatenation of the encodings of its member transactions.
+func decodeTxGroup(data []byte) ([]transactions.SignedTxn, error) {
+	dec := protocol.NewDecoderBytes(data)
+	var txgroup []transactions.SignedTxn
+	for {
+		var txn transactions.SignedTxn
+		err := dec.Decode(&txn)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		txgroup = append(txgroup, txn)
+	}
+
+	if len(txgroup) == 0 {
+		return nil, fmt.Errorf("empty transaction group")
+	}
+	return txgroup, nil
+}
+
 func (handler *TxHandler) processIncomingTxn(rawmsg network.IncomingMessage) network.OutgoingMessage {
-	var unverifiedTxn transactions.SignedTxn
-	err := protocol.Decode(rawmsg.Data, &unverifiedTxn)
+	unverifiedTxGroup, err := decodeTxGroup(rawmsg.Data)
 	if err != nil {
 		logging.Base().Warnf("Received a non-decodable txn: %v", err)
 		return network.OutgoingMessage{Action: network.Disconnect}
@@ -207,8 +231,8 @@ func (handler *TxHandler) processIncomingTxn(rawmsg network.IncomingMessage) net
 
 	select {
 	case handler.backlogQueue <- &txBacklogMsg{
-		rawmsg:        &rawmsg,
-		unverifiedTxn: &unverifiedTxn,
+		rawmsg:            &rawmsg,
+		unverifiedTxGroup: unverifiedTxGroup,
 	}:
 	default:
 		// if we failed here we want to increase the corresponding metric. It might suggest that we
@@ -219,14 +243,16 @@ func (handler *TxHandler) processIncomingTxn(rawmsg network.IncomingMessage) net
 	return network.OutgoingMessage{Action: network.Ignore}
 }
 
-// checkAlreadyCommitted test to see if the given transaction ( in the txBacklogMsg ) was already commited, and
+// checkAlreadyCommitted test to see if the given transaction group ( in the txBacklogMsg ) was already commited, and
 // whether it would qualify as a candidate for the transaction pool.
 func (handler *TxHandler) checkAlreadyCommitted(tx *txBacklogMsg) (processingDone bool) {
-	tx.unverifiedTxn.Txn.InitCaches()
-	logging.Base().Debugf("got a tx with ID %v", tx.unverifiedTxn.ID())
+	for i := range tx.unverifiedTxGroup {
+		tx.unverifiedTxGroup[i].InitCaches()
+		logging.Base().Debugf("got a tx with ID %v", tx.unverifiedTxGroup[i].ID())
+	}
 
-	// do a quick test to check that this transaction could potentially be committed, to reject dup pending transactions
-	err := handler.txPool.Test(*tx.unverifiedTxn)
+	// do a quick test to check that this transaction group could potentially be committed, to reject dup pending transactions
+	err := handler.txPool.Test(tx.unverifiedTxGroup)
 	if err != nil {
 		logging.Base().Debugf("txPool rejected transaction: %v", err)
 		return true
@@ -250,45 +276,47 @@ func (handler *TxHandler) checkAlreadyCommitted(tx *txBacklogMsg) (processingDon
 		GenHash:       handler.genesisHash,
 	}
 
-	err = tx.unverifiedTxn.Txn.Alive(tc)
-	if err != nil {
-		logging.Base().Debugf("Received a dead txn %s: %v", tx.unverifiedTxn.ID(), err)
-		return true
-	}
+	for _, txn := range tx.unverifiedTxGroup {
+		err = txn.Txn.Alive(tc)
+		if err != nil {
+			logging.Base().Debugf("Received a dead txn %s: %v", txn.ID(), err)
+			return true
+		}
 
-	committed, err := handler.ledger.Committed(*tx.unverifiedTxn)
-	if err != nil {
-		logging.Base().Errorf("Could not verify committed status of txn %v: %v", tx.unverifiedTxn, err)
-		return true
-	}
+		committed, err := handler.ledger.Committed(txn)
+		if err != nil {
+			logging.Base().Errorf("Could not verify committed status of txn %v: %v", txn, err)
+			return true
+		}
 
-	if committed {
-		logging.Base().Debugf("Already confirmed tx %v", tx.unverifiedTxn.ID())
-		return true
+		if committed {
+			logging.Base().Debugf("Already confirmed tx %v", txn.ID())
+			return true
+		}
 	}
 	return false
 }
 
-func (handler *TxHandler) processDecoded(unverifiedTxn transactions.SignedTxn) (outmsg network.OutgoingMessage, processingDone bool) {
+func (handler *TxHandler) processDecoded(unverifiedTxGroup []transactions.SignedTxn) (outmsg network.OutgoingMessage, processingDone bool) {
 	tx := &txBacklogMsg{
-		unverifiedTxn: &unverifiedTxn,
+		unverifiedTxGroup: unverifiedTxGroup,
 	}
 	if handler.checkAlreadyCommitted(tx) {
 		return network.OutgoingMessage{}, true
 	}
 
-	err := tx.unverifiedTxn.PoolVerify(tx.spec, tx.proto, handler.txVerificationPool)
+	err := verify.TxnGroupPool(handler.ctx, unverifiedTxGroup, tx.spec, tx.proto, handler.txVerificationPool)
 	if err != nil {
 		// transaction is invalid
-		logging.Base().Warnf("Received a malformed txn %v: %v", unverifiedTxn, err)
+		logging.Base().Warnf("Received a malformed tx group %v: %v", unverifiedTxGroup, err)
 		return network.OutgoingMessage{Action: network.Disconnect}, true
 	}
 
-	// at this point, we've verified the transaction, so we can safely treat the transaction as a verified transaction.
-	verifiedTxn := unverifiedTxn
+	// at this point, we've verified the transaction group, so we can safely treat the transactions as verified transactions.
+	verifiedTxGroup := unverifiedTxGroup
 
-	// save the transaction, if it has high enough fee and not already in the cache
-	err = handler.txPool.Remember(verifiedTxn)
+	// save the transaction group, if it has high enough fee and not already in the cache
+	err = handler.txPool.Remember(verifiedTxGroup)
 	if err != nil {
 		logging.Base().Debugf("could not remember tx: %v", err)
 		return network.OutgoingMessage{}, true
@@ -299,7 +327,7 @@ func (handler *TxHandler) processDecoded(unverifiedTxn transactions.SignedTxn) (
 // SolicitedTxHandler handles messages received through channels other than the gossip network.
 // It therefore circumvents the notion of incoming/outgoing messages
 type SolicitedTxHandler interface {
-	Handle(txn transactions.SignedTxn) error
+	Handle(txgroup []transactions.SignedTxn) error
 }
 
 // SolicitedTxHandler converts a transaction handler to a SolicitedTxHandler
@@ -311,8 +339,8 @@ type solicitedTxHandler struct {
 	txHandler *TxHandler
 }
 
-func (handler *solicitedTxHandler) Handle(txn transactions.SignedTxn) error {
-	outmsg, _ := handler.txHandler.processDecoded(txn)
+func (handler *solicitedTxHandler) Handle(txgroup []transactions.SignedTxn) error {
+	outmsg, _ := handler.txHandler.processDecoded(txgroup)
 	if outmsg.Action == network.Disconnect {
 		return fmt.Errorf("invlid transaction")
 	}
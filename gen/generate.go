@@ -93,11 +93,11 @@ func GenerateGenesisFiles(genesisData GenesisData, outDir string) error {
 		genesisData.RewardsPool = defaultPoolAddr
 	}
 
-	return generateGenesisFiles(outDir, proto, genesisData.NetworkName, genesisData.VersionModifier, allocation, genesisData.FirstPartKeyRound, genesisData.LastPartKeyRound, genesisData.FeeSink, genesisData.RewardsPool, genesisData.Comment)
+	return generateGenesisFiles(outDir, proto, genesisData.NetworkName, genesisData.VersionModifier, allocation, genesisData.FirstPartKeyRound, genesisData.LastPartKeyRound, genesisData.FeeSink, genesisData.RewardsPool, genesisData.Comment, genesisData.DevMode)
 }
 
 func generateGenesisFiles(outDir string, proto protocol.ConsensusVersion, netName string, schemaVersionModifier string,
-	allocation []genesisAllocation, firstWalletValid uint64, lastWalletValid uint64, feeSink, rewardsPool basics.Address, comment string) (err error) {
+	allocation []genesisAllocation, firstWalletValid uint64, lastWalletValid uint64, feeSink, rewardsPool basics.Address, comment string, devMode bool) (err error) {
 
 	genesisAddrs := make(map[string]basics.Address)
 	records := make(map[string]basics.AccountData)
@@ -230,6 +230,7 @@ func generateGenesisFiles(outDir string, proto protocol.ConsensusVersion, netNam
 		FeeSink:     feeSink.GetChecksumAddress().String(),
 		RewardsPool: rewardsPool.GetChecksumAddress().String(),
 		Comment:     comment,
+		DevMode:     devMode,
 	}
 
 	for _, wallet := range allocation {
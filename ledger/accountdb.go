@@ -23,15 +23,18 @@ import (
 	"github.com/mattn/go-sqlite3"
 
 	"github.com/algorand/go-algorand/config"
+	"github.com/algorand/go-algorand/crypto"
 	"github.com/algorand/go-algorand/data/basics"
 	"github.com/algorand/go-algorand/protocol"
 	"github.com/algorand/go-algorand/util/db"
 )
 
-// accountsDbQueries is used to cache a prepared SQL statement to look up
-// the state of a single account.
+// accountsDbQueries is used to cache prepared SQL statements to look up
+// the state of a single account, and the creator of a single asset.
 type accountsDbQueries struct {
-	lookupStmt *sql.Stmt
+	lookupStmt             *sql.Stmt
+	lookupAssetCreatorStmt *sql.Stmt
+	lookupHistoryStmt      *sql.Stmt
 }
 
 var accountsSchema = []string{
@@ -50,8 +53,25 @@ var accountsSchema = []string{
 	`CREATE TABLE IF NOT EXISTS accountbase (
 		address blob primary key,
 		data blob)`,
+	`CREATE TABLE IF NOT EXISTS assetcreators (
+		asset integer primary key,
+		creator blob)`,
+	`CREATE TABLE IF NOT EXISTS accounthist (
+		address blob,
+		rnd integer,
+		data blob,
+		primary key (address, rnd))`,
+	`CREATE TABLE IF NOT EXISTS catchpoints (
+		rnd integer primary key,
+		digest blob)`,
 }
 
+// catchpointDigestsKept is the number of most recent catchpoint digests
+// that the database keeps.  A block commits to a catchpoint
+// MaxBalLookback rounds after it, so this is enough as long as the
+// catchpoint interval is at least half of MaxBalLookback.
+const catchpointDigestsKept = 2
+
 type accountDelta struct {
 	old basics.AccountData
 	new basics.AccountData
@@ -82,6 +102,14 @@ func accountsInit(tx *sql.Tx, initAccounts map[basics.Address]basics.AccountData
 				return err
 			}
 
+			for aidx := range data.AssetParams {
+				_, err = tx.Exec("INSERT INTO assetcreators (asset, creator) VALUES (?, ?)",
+					aidx, addr[:])
+				if err != nil {
+					return err
+				}
+			}
+
 			totals.addAccount(proto, data, &ot)
 		}
 
@@ -105,6 +133,125 @@ func accountsInit(tx *sql.Tx, initAccounts map[basics.Address]basics.AccountData
 	return nil
 }
 
+// accountsReset replaces the account state in the database using tx
+// with bals as of round rnd, such as from a catchpoint.
+func accountsReset(tx *sql.Tx, bals map[basics.Address]basics.AccountData, rnd basics.Round, totals AccountTotals) error {
+	for _, table := range []string{"accountbase", "assetcreators", "catchpoints"} {
+		_, err := tx.Exec("DELETE FROM " + table)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The account history does not cover the rounds skipped by the reset.
+	err := accountsHistoryClear(tx)
+	if err != nil {
+		return err
+	}
+
+	for addr, data := range bals {
+		_, err := tx.Exec("INSERT INTO accountbase (address, data) VALUES (?, ?)",
+			addr[:], protocol.Encode(data))
+		if err != nil {
+			return err
+		}
+
+		for aidx := range data.AssetParams {
+			_, err = tx.Exec("INSERT INTO assetcreators (asset, creator) VALUES (?, ?)",
+				aidx, addr[:])
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = tx.Exec("UPDATE acctrounds SET rnd=? WHERE id='acctbase'", rnd)
+	if err != nil {
+		return err
+	}
+
+	return accountsPutTotals(tx, totals)
+}
+
+// accountsHistoryBase returns the first round of the account history,
+// and false if the database does not record account history.
+func accountsHistoryBase(tx *sql.Tx) (rnd basics.Round, ok bool, err error) {
+	err = tx.QueryRow("SELECT rnd FROM acctrounds WHERE id='histbase'").Scan(&rnd)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return rnd, true, nil
+}
+
+// accountsHistoryStart starts recording account history at the round of
+// the account state in the database, by copying that state into the
+// history.
+func accountsHistoryStart(tx *sql.Tx) (rnd basics.Round, err error) {
+	rnd, err = accountsRound(tx)
+	if err != nil {
+		return
+	}
+
+	_, err = tx.Exec("INSERT INTO accounthist (address, rnd, data) SELECT address, ?, data FROM accountbase", rnd)
+	if err != nil {
+		return
+	}
+
+	_, err = tx.Exec("INSERT INTO acctrounds (id, rnd) VALUES ('histbase', ?)", rnd)
+	return
+}
+
+// accountsHistoryLowerBase moves the first round of the account history
+// from base down to rnd, once the history of the rounds in between has
+// been recorded.  It returns false, leaving the history alone, if the
+// history no longer starts at base.
+func accountsHistoryLowerBase(tx *sql.Tx, base basics.Round, rnd basics.Round) (bool, error) {
+	res, err := tx.Exec("UPDATE acctrounds SET rnd=? WHERE id='histbase' AND rnd=?", rnd, base)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// accountsHistoryClear deletes the account history, if any.
+func accountsHistoryClear(tx *sql.Tx) error {
+	_, err := tx.Exec("DELETE FROM accounthist")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM acctrounds WHERE id='histbase'")
+	return err
+}
+
+// accountsHistoryNewRound records in the account history the state of the
+// accounts that changed in round rnd.  Accounts that were emptied are
+// recorded too, so that lookups in later rounds do not find their older
+// state.
+func accountsHistoryNewRound(tx *sql.Tx, rnd basics.Round, updates map[basics.Address]accountDelta) error {
+	stmt, err := tx.Prepare("REPLACE INTO accounthist (address, rnd, data) VALUES (?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for addr, data := range updates {
+		_, err = stmt.Exec(addr[:], rnd, protocol.Encode(data.new))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func accountsRound(tx *sql.Tx) (rnd basics.Round, err error) {
 	err = tx.QueryRow("SELECT rnd FROM acctrounds WHERE id='acctbase'").Scan(&rnd)
 	return
@@ -119,6 +266,16 @@ func accountsDbInit(q db.Queryable) (*accountsDbQueries, error) {
 		return nil, err
 	}
 
+	qs.lookupAssetCreatorStmt, err = q.Prepare("SELECT creator FROM assetcreators WHERE asset=?")
+	if err != nil {
+		return nil, err
+	}
+
+	qs.lookupHistoryStmt, err = q.Prepare("SELECT data FROM accounthist WHERE address=? AND rnd<=? ORDER BY rnd DESC LIMIT 1")
+	if err != nil {
+		return nil, err
+	}
+
 	return qs, nil
 }
 
@@ -141,6 +298,52 @@ func (qs *accountsDbQueries) lookup(addr basics.Address) (data basics.AccountDat
 	return
 }
 
+// lookupHistory returns the state of an account as of round rnd, from
+// the account history.  The caller must check that the history covers rnd.
+func (qs *accountsDbQueries) lookupHistory(addr basics.Address, rnd basics.Round) (data basics.AccountData, err error) {
+	err = db.Retry(func() error {
+		var buf []byte
+		err := qs.lookupHistoryStmt.QueryRow(addr[:], rnd).Scan(&buf)
+		if err == nil {
+			return protocol.Decode(buf, &data)
+		}
+
+		if err == sql.ErrNoRows {
+			// The account was empty at rnd
+			return nil
+		}
+
+		return err
+	})
+
+	return
+}
+
+func (qs *accountsDbQueries) lookupAssetCreator(aidx basics.AssetIndex) (addr basics.Address, ok bool, err error) {
+	err = db.Retry(func() error {
+		var buf []byte
+		err := qs.lookupAssetCreatorStmt.QueryRow(aidx).Scan(&buf)
+		if err == sql.ErrNoRows {
+			// The asset does not exist
+			ok = false
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(buf) != len(addr) {
+			return fmt.Errorf("Asset creator address length mismatch: %d != %d", len(buf), len(addr))
+		}
+
+		copy(addr[:], buf)
+		ok = true
+		return nil
+	})
+
+	return
+}
+
 func accountsAll(tx *sql.Tx) (bals map[basics.Address]basics.AccountData, err error) {
 	rows, err := tx.Query("SELECT address, data FROM accountbase")
 	if err != nil {
@@ -177,6 +380,85 @@ func accountsAll(tx *sql.Tx) (bals map[basics.Address]basics.AccountData, err er
 	return
 }
 
+// accountsForEach calls f on every account in the database, in the
+// order of their addresses, without loading them all in memory.
+func accountsForEach(tx *sql.Tx, f func(basics.Address, basics.AccountData) error) error {
+	rows, err := tx.Query("SELECT address, data FROM accountbase ORDER BY address")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var addrbuf []byte
+		var buf []byte
+		err = rows.Scan(&addrbuf, &buf)
+		if err != nil {
+			return err
+		}
+
+		var data basics.AccountData
+		err = protocol.Decode(buf, &data)
+		if err != nil {
+			return err
+		}
+
+		var addr basics.Address
+		if len(addrbuf) != len(addr) {
+			return fmt.Errorf("Account DB address length mismatch: %d != %d", len(addrbuf), len(addr))
+		}
+
+		copy(addr[:], addrbuf)
+		err = f(addr, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// accountsCatchpoints returns the catchpoint digests in the database,
+// indexed by catchpoint round.
+func accountsCatchpoints(tx *sql.Tx) (map[basics.Round]crypto.Digest, error) {
+	rows, err := tx.Query("SELECT rnd, digest FROM catchpoints")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	digests := make(map[basics.Round]crypto.Digest)
+	for rows.Next() {
+		var rnd basics.Round
+		var buf []byte
+		err = rows.Scan(&rnd, &buf)
+		if err != nil {
+			return nil, err
+		}
+
+		var digest crypto.Digest
+		if len(buf) != len(digest) {
+			return nil, fmt.Errorf("catchpoint %d digest length mismatch: %d != %d", rnd, len(buf), len(digest))
+		}
+		copy(digest[:], buf)
+		digests[rnd] = digest
+	}
+
+	return digests, rows.Err()
+}
+
+// accountsPutCatchpoint records the digest of the catchpoint at round
+// rnd, and forgets all but the catchpointDigestsKept most recent ones.
+func accountsPutCatchpoint(tx *sql.Tx, rnd basics.Round, digest crypto.Digest) error {
+	_, err := tx.Exec("REPLACE INTO catchpoints (rnd, digest) VALUES (?, ?)", rnd, digest[:])
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM catchpoints WHERE rnd NOT IN (SELECT rnd FROM catchpoints ORDER BY rnd DESC LIMIT ?)", catchpointDigestsKept)
+	return err
+}
+
 func accountsTotals(tx *sql.Tx) (totals AccountTotals, err error) {
 	row := tx.QueryRow("SELECT online, onlinerewardunits, offline, offlinerewardunits, notparticipating, notparticipatingrewardunits, rewardslevel FROM accounttotals")
 	err = row.Scan(&totals.Online.Money.Raw, &totals.Online.RewardUnits,
@@ -198,7 +480,7 @@ func accountsPutTotals(tx *sql.Tx, totals AccountTotals) error {
 	return err
 }
 
-func accountsNewRound(tx *sql.Tx, rnd basics.Round, updates map[basics.Address]accountDelta, rewardsLevel uint64, proto config.ConsensusParams) error {
+func accountsNewRound(tx *sql.Tx, rnd basics.Round, updates map[basics.Address]accountDelta, assetUpdates map[basics.AssetIndex]modifiedAsset, rewardsLevel uint64, proto config.ConsensusParams) error {
 	var base basics.Round
 	err := tx.QueryRow("SELECT rnd FROM acctrounds WHERE id='acctbase'").Scan(&base)
 	if err != nil {
@@ -230,7 +512,7 @@ func accountsNewRound(tx *sql.Tx, rnd basics.Round, updates map[basics.Address]a
 	defer replaceStmt.Close()
 
 	for addr, data := range updates {
-		if (data.new == basics.AccountData{}) {
+		if data.new.IsZero() {
 			// prune empty accounts
 			_, err = deleteStmt.Exec(addr[:])
 		} else {
@@ -248,6 +530,17 @@ func accountsNewRound(tx *sql.Tx, rnd basics.Round, updates map[basics.Address]a
 		return fmt.Errorf("overflow computing totals")
 	}
 
+	for aidx, delta := range assetUpdates {
+		if delta.created {
+			_, err = tx.Exec("INSERT INTO assetcreators (asset, creator) VALUES (?, ?)", aidx, delta.creator[:])
+		} else {
+			_, err = tx.Exec("DELETE FROM assetcreators WHERE asset=?", aidx)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
 	res, err := tx.Exec("UPDATE acctrounds SET rnd=? WHERE id='acctbase'", rnd)
 	if err != nil {
 		return err
@@ -17,6 +17,7 @@
 package ledger
 
 import (
+	"bytes"
 	"database/sql"
 	"testing"
 
@@ -194,8 +195,112 @@ func TestAccountDBRound(t *testing.T) {
 	for i := 1; i < 10; i++ {
 		updates, newaccts, _ := randomDeltas(20, accts, 0)
 		accts = newaccts
-		err = accountsNewRound(tx, basics.Round(i), updates, 0, proto)
+		err = accountsNewRound(tx, basics.Round(i), updates, nil, 0, proto)
 		require.NoError(t, err)
 		checkAccounts(t, tx, basics.Round(i), accts)
 	}
 }
+
+func TestAccountDBAssetCreators(t *testing.T) {
+	proto := config.Consensus[protocol.ConsensusFuture]
+
+	dbs := dbOpenTest(t)
+	defer dbs.close()
+
+	tx, err := dbs.wdb.Handle.Begin()
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	creator := randomAddress()
+	accts := randomAccounts(5)
+	data := randomAccountData(0)
+	data.AssetParams = map[basics.AssetIndex]basics.AssetParams{
+		1: {Total: 100},
+	}
+	accts[creator] = data
+
+	err = accountsInit(tx, accts, proto)
+	require.NoError(t, err)
+
+	aq, err := accountsDbInit(tx)
+	require.NoError(t, err)
+
+	addr, ok, err := aq.lookupAssetCreator(1)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, creator, addr)
+
+	_, ok, err = aq.lookupAssetCreator(2)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	other := randomAddress()
+	assetUpdates := map[basics.AssetIndex]modifiedAsset{
+		1: {created: false, creator: creator},
+		2: {created: true, creator: other},
+	}
+	err = accountsNewRound(tx, 1, nil, assetUpdates, 0, proto)
+	require.NoError(t, err)
+
+	_, ok, err = aq.lookupAssetCreator(1)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	addr, ok, err = aq.lookupAssetCreator(2)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, other, addr)
+}
+
+func TestAccountDBCatchpoints(t *testing.T) {
+	proto := config.Consensus[protocol.ConsensusCurrentVersion]
+
+	dbs := dbOpenTest(t)
+	defer dbs.close()
+
+	tx, err := dbs.wdb.Handle.Begin()
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	accts := randomAccounts(20)
+	err = accountsInit(tx, accts, proto)
+	require.NoError(t, err)
+
+	// accounts are visited in address order, and match the map-based
+	// encoding of the same catchpoint
+	var prev basics.Address
+	seen := make(map[basics.Address]basics.AccountData)
+	err = accountsForEach(tx, func(addr basics.Address, data basics.AccountData) error {
+		if len(seen) > 0 {
+			require.True(t, bytes.Compare(prev[:], addr[:]) < 0)
+		}
+		prev = addr
+		seen[addr] = data
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, accts, seen)
+
+	hdr := randomCatchpointHeader(accts)
+	fromDB, err := catchpointDigest(hdr, func(f func(basics.Address, basics.AccountData) error) error {
+		return accountsForEach(tx, f)
+	})
+	require.NoError(t, err)
+	fromMap, err := catchpointDigest(hdr, mapCatchpointAccounts(accts))
+	require.NoError(t, err)
+	require.Equal(t, fromMap, fromDB)
+
+	// only the most recent digests are kept
+	var digests []crypto.Digest
+	for i := 0; i < 4; i++ {
+		var d crypto.Digest
+		crypto.RandBytes(d[:])
+		digests = append(digests, d)
+		err = accountsPutCatchpoint(tx, basics.Round(16*(i+1)), d)
+		require.NoError(t, err)
+	}
+
+	stored, err := accountsCatchpoints(tx)
+	require.NoError(t, err)
+	require.Equal(t, map[basics.Round]crypto.Digest{48: digests[2], 64: digests[3]}, stored)
+}
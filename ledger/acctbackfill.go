@@ -0,0 +1,153 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package ledger
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/algorand/go-algorand/agreement"
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+)
+
+// backfillBatchRounds is the number of rounds of account history that
+// backfillHistory records in each database transaction.
+const backfillBatchRounds = 256
+
+// errBackfillStopped is returned internally when the account history
+// changed under backfillHistory, or the tracker is closing.
+var errBackfillStopped = fmt.Errorf("account history backfill stopped")
+
+// backfillHistory records the account history of the rounds before base,
+// where the history started, by replaying the stored blocks from genesis
+// in a scratch in-memory ledger.  Once done, the history starts at round
+// 0.  It gives up, leaving the history as it was, if a block is no longer
+// stored, if the history is cleared or restarted in the meantime, or if
+// stop is closed.
+//
+// The scratch ledger keeps its account state in memory, so the memory
+// used by a backfill grows with the total account state of the chain,
+// not with the number of rounds replayed.  Enabling history on a large
+// ledger should be planned with that in mind.
+func (au *accountUpdates) backfillHistory(base basics.Round, stop <-chan struct{}) {
+	defer au.historyBackfills.Done()
+
+	err := au.replayHistory(base, stop)
+	if err == errBackfillStopped {
+		return
+	}
+	if err != nil {
+		au.log.Warnf("unable to backfill account history before round %d: %v", base, err)
+		return
+	}
+
+	au.historyMu.Lock()
+	defer au.historyMu.Unlock()
+	if au.hasHistory && au.historyBase == base {
+		au.historyBase = 0
+	}
+}
+
+func (au *accountUpdates) replayHistory(base basics.Round, stop <-chan struct{}) error {
+	genesis, err := au.ledger.Block(0)
+	if err != nil {
+		return err
+	}
+
+	scratchPrefix := fmt.Sprintf("backfill.%d", crypto.RandUint64())
+	scratch, err := OpenLedger(au.log, scratchPrefix, true, []bookkeeping.Block{genesis}, au.initAccounts, au.ledger.GenesisHash())
+	if err != nil {
+		return err
+	}
+	defer scratch.Close()
+	scratch.SetArchival(false)
+
+	// The genesis accounts are the state of round 0.
+	deltas := []map[basics.Address]accountDelta{make(map[basics.Address]accountDelta)}
+	for addr, data := range au.initAccounts {
+		deltas[0][addr] = accountDelta{new: data}
+	}
+
+	first := basics.Round(0)
+	for rnd := basics.Round(1); rnd < base; rnd++ {
+		select {
+		case <-stop:
+			return errBackfillStopped
+		default:
+		}
+
+		if len(deltas) == backfillBatchRounds {
+			err = au.recordBackfill(base, first, deltas)
+			if err != nil {
+				return err
+			}
+			first = rnd
+			deltas = nil
+		}
+
+		blk, aux, err := au.ledger.blockAux(rnd)
+		if err != nil {
+			return err
+		}
+		delta, err := scratch.trackerEvalVerified(blk, aux)
+		if err != nil {
+			return err
+		}
+		err = scratch.AddValidatedBlock(ValidatedBlock{blk: blk, delta: delta, aux: aux}, agreement.Certificate{})
+		if err != nil {
+			return err
+		}
+		deltas = append(deltas, delta.accts)
+	}
+
+	err = au.recordBackfill(base, first, deltas)
+	if err != nil {
+		return err
+	}
+
+	return au.dbs.wdb.Atomic(func(tx *sql.Tx) error {
+		ok, err := accountsHistoryLowerBase(tx, base, 0)
+		if err == nil && !ok {
+			err = errBackfillStopped
+		}
+		return err
+	})
+}
+
+// recordBackfill records the account changes of the rounds from first
+// on in the account history, provided that it still starts at base.
+func (au *accountUpdates) recordBackfill(base basics.Round, first basics.Round, deltas []map[basics.Address]accountDelta) error {
+	return au.dbs.wdb.Atomic(func(tx *sql.Tx) error {
+		histBase, ok, err := accountsHistoryBase(tx)
+		if err != nil {
+			return err
+		}
+		if !ok || histBase != base {
+			return errBackfillStopped
+		}
+
+		for i, updates := range deltas {
+			err = accountsHistoryNewRound(tx, first+basics.Round(i), updates)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,130 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package ledger
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/go-algorand/agreement"
+	"github.com/algorand/go-algorand/config"
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/logging"
+	"github.com/algorand/go-algorand/protocol"
+	"github.com/algorand/go-algorand/util/execpool"
+)
+
+func TestBackfillAccountHistory(t *testing.T) {
+	blks, accts, addrs, keys := genesis(10)
+
+	backlogPool := execpool.MakeBacklog(nil, 0, execpool.LowPriority, nil)
+	defer backlogPool.Shutdown()
+
+	dbName := fmt.Sprintf("%s.%d", t.Name(), crypto.RandUint64())
+	l, err := OpenLedger(logging.Base(), dbName, true, blks, accts, blks[0].BlockHeader.GenesisHash)
+	require.NoError(t, err)
+	defer l.Close()
+
+	// Record the account state of every round while it is still recent.
+	proto := config.Consensus[protocol.ConsensusCurrentVersion]
+	latest := basics.Round(proto.MaxBalLookback + 20)
+	expected := make([]map[basics.Address]basics.AccountData, latest+1)
+	expected[0] = make(map[basics.Address]basics.AccountData)
+	for _, addr := range addrs {
+		expected[0][addr], err = l.Lookup(0, addr)
+		require.NoError(t, err)
+	}
+
+	prev := blks[0].BlockHeader
+	addBlock := func() {
+		newBlock := bookkeeping.MakeBlock(prev)
+		eval, err := l.StartEvaluator(newBlock.BlockHeader, nil, backlogPool)
+		require.NoError(t, err)
+
+		rnd := newBlock.Round()
+		sender := int(rnd) % len(addrs)
+		txn := transactions.Transaction{
+			Type: protocol.PaymentTx,
+			Header: transactions.Header{
+				Sender:      addrs[sender],
+				Fee:         minFee,
+				FirstValid:  rnd,
+				LastValid:   rnd,
+				GenesisHash: blks[0].BlockHeader.GenesisHash,
+			},
+			PaymentTxnFields: transactions.PaymentTxnFields{
+				Receiver: addrs[(sender+1)%len(addrs)],
+				Amount:   basics.MicroAlgos{Raw: 1000 + uint64(rnd)},
+			},
+		}
+		require.NoError(t, eval.Transaction(txn.Sign(keys[sender]), nil))
+		vb, err := eval.GenerateBlock()
+		require.NoError(t, err)
+		require.NoError(t, l.AddValidatedBlock(*vb, agreement.Certificate{}))
+		prev = vb.Block().BlockHeader
+	}
+
+	for rnd := basics.Round(1); rnd <= latest; rnd++ {
+		addBlock()
+		expected[rnd] = make(map[basics.Address]basics.AccountData)
+		for _, addr := range addrs {
+			expected[rnd][addr], err = l.Lookup(rnd, addr)
+			require.NoError(t, err)
+		}
+	}
+
+	// Flush the account state well past genesis, then start the history
+	// on the next flush; the ledger backfills the rounds before it from
+	// the stored blocks.
+	flush := func() {
+		l.trackerMu.Lock()
+		l.accts.lastFlushTime = time.Time{}
+		l.trackerMu.Unlock()
+		addBlock()
+		l.WaitForCommit(prev.Round)
+	}
+	flush()
+	l.SetAccountHistory(true)
+	flush()
+	l.trackerMu.RLock()
+	require.True(t, l.accts.dbRound > 0)
+	l.trackerMu.RUnlock()
+
+	for i := 0; i < 500; i++ {
+		_, err = l.Lookup(0, addrs[0])
+		if err == nil {
+			break
+		}
+		require.IsType(t, ErrNoAccountHistory{}, err)
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.NoError(t, err)
+
+	for rnd := basics.Round(0); rnd <= latest; rnd++ {
+		for _, addr := range addrs {
+			data, err := l.Lookup(rnd, addr)
+			require.NoError(t, err)
+			require.Equal(t, expected[rnd][addr], data, "round %d", rnd)
+		}
+	}
+}
@@ -19,9 +19,11 @@ package ledger
 import (
 	"database/sql"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/algorand/go-algorand/config"
+	"github.com/algorand/go-algorand/crypto"
 	"github.com/algorand/go-algorand/data/basics"
 	"github.com/algorand/go-algorand/data/bookkeeping"
 	"github.com/algorand/go-algorand/logging"
@@ -56,6 +58,10 @@ type accountUpdates struct {
 	// deltas stores updates for every round after dbRound.
 	deltas []map[basics.Address]accountDelta
 
+	// assetDeltas stores asset creation/deletion updates for every
+	// round after dbRound.
+	assetDeltas []map[basics.AssetIndex]modifiedAsset
+
 	// accounts stores the most recent account state for every
 	// address that appears in deltas.
 	accounts map[basics.Address]modifiedAccount
@@ -80,11 +86,44 @@ type accountUpdates struct {
 	// lastFlushTime is the time we last flushed updates to
 	// the accounts DB (bumping dbRound).
 	lastFlushTime time.Time
+
+	// ledger is used to look up the block headers of catchpoints.
+	ledger ledgerForTracker
+
+	// catchpointDir is the directory where catchpoint files are
+	// written when the accounts DB reaches the account state of a
+	// catchpoint, and catchpointHistory the number of files to keep.
+	// No catchpoint files are written if catchpointDir is empty.
+	catchpointDir     string
+	catchpointHistory int
+
+	// catchpointDigests holds the most recent catchpoint digests,
+	// indexed by catchpoint round.  A digest is computed when the
+	// accounts DB is flushed to the account state of its catchpoint.
+	catchpointDigests map[basics.Round]crypto.Digest
+
+	// recordHistory determines whether the account state of every round
+	// is kept in the accounts DB as it is flushed, so that lookups can
+	// go further back than dbRound.
+	recordHistory bool
+
+	// hasHistory is true if the accounts DB holds the account history
+	// of every round from historyBase to dbRound.  historyMu protects
+	// them, since backfillHistory lowers historyBase in the background.
+	historyMu   sync.Mutex
+	hasHistory  bool
+	historyBase basics.Round
+
+	// historyBackfills tracks the goroutines backfilling the account
+	// history, and historyStop tells them to give up.
+	historyBackfills sync.WaitGroup
+	historyStop      chan struct{}
 }
 
 func (au *accountUpdates) loadFromDisk(l ledgerForTracker) error {
 	au.dbs = l.trackerDB()
 	au.log = l.trackerLog()
+	au.ledger = l
 
 	if au.initAccounts == nil {
 		return fmt.Errorf("accountUpdates.loadFromDisk: initAccounts not set")
@@ -108,12 +147,26 @@ func (au *accountUpdates) loadFromDisk(l ledgerForTracker) error {
 		}
 
 		au.roundTotals = []AccountTotals{totals}
-		return nil
+
+		au.historyBase, au.hasHistory, err0 = accountsHistoryBase(tx)
+		if err0 != nil {
+			return err0
+		}
+
+		au.catchpointDigests, err0 = accountsCatchpoints(tx)
+		return err0
 	})
 	if err != nil {
 		return err
 	}
 
+	au.historyStop = make(chan struct{})
+	if au.hasHistory && au.historyBase > 0 {
+		// A backfill was interrupted; resume it.
+		au.historyBackfills.Add(1)
+		go au.backfillHistory(au.historyBase, au.historyStop)
+	}
+
 	au.accountsq, err = accountsDbInit(au.dbs.rdb.Handle)
 	if err != nil {
 		return err
@@ -127,6 +180,7 @@ func (au *accountUpdates) loadFromDisk(l ledgerForTracker) error {
 
 	latest := l.Latest()
 	au.deltas = nil
+	au.assetDeltas = nil
 	au.accounts = make(map[basics.Address]modifiedAccount)
 	loaded := au.dbRound
 	for loaded < latest {
@@ -146,10 +200,18 @@ func (au *accountUpdates) loadFromDisk(l ledgerForTracker) error {
 		loaded = next
 	}
 
+	// The node may have stopped before computing the digest of the
+	// catchpoint that the accounts DB is at.
+	au.catchpointComputed()
 	return nil
 }
 
 func (au *accountUpdates) close() {
+	if au.historyStop != nil {
+		close(au.historyStop)
+		au.historyStop = nil
+	}
+	au.historyBackfills.Wait()
 }
 
 func (au *accountUpdates) roundOffset(rnd basics.Round) (offset uint64, err error) {
@@ -168,6 +230,10 @@ func (au *accountUpdates) roundOffset(rnd basics.Round) (offset uint64, err erro
 }
 
 func (au *accountUpdates) lookup(rnd basics.Round, addr basics.Address, withRewards bool) (data basics.AccountData, err error) {
+	if rnd < au.dbRound {
+		return au.lookupHistory(rnd, addr, withRewards)
+	}
+
 	offset, err := au.roundOffset(rnd)
 	if err != nil {
 		return
@@ -210,6 +276,58 @@ func (au *accountUpdates) lookup(rnd basics.Round, addr basics.Address, withRewa
 	return au.accountsq.lookup(addr)
 }
 
+// lookupHistory returns the state of an account as of a round before
+// dbRound, from the account history in the accounts DB.
+func (au *accountUpdates) lookupHistory(rnd basics.Round, addr basics.Address, withRewards bool) (data basics.AccountData, err error) {
+	au.historyMu.Lock()
+	hasHistory, historyBase := au.hasHistory, au.historyBase
+	au.historyMu.Unlock()
+
+	if !hasHistory || rnd < historyBase {
+		earliest := au.dbRound
+		if hasHistory {
+			earliest = historyBase
+		}
+		err = ErrNoAccountHistory{Round: rnd, Earliest: earliest}
+		return
+	}
+
+	data, err = au.accountsq.lookupHistory(addr, rnd)
+	if err != nil || !withRewards {
+		return
+	}
+
+	// The rewards level of a past round is recorded in its block header.
+	hdr, err := au.ledger.BlockHdr(rnd)
+	if err != nil {
+		return
+	}
+	return data.WithUpdatedRewards(config.Consensus[hdr.CurrentProtocol], hdr.RewardsLevel), nil
+}
+
+func (au *accountUpdates) getAssetCreator(rnd basics.Round, aidx basics.AssetIndex) (creator basics.Address, ok bool, err error) {
+	offset, err := au.roundOffset(rnd)
+	if err != nil {
+		return
+	}
+
+	// Check if the asset has been created or deleted recently.  Traverse
+	// the deltas backwards to ensure that later updates take priority.
+	for offset > 0 {
+		offset--
+		d, present := au.assetDeltas[offset][aidx]
+		if present {
+			if d.created {
+				return d.creator, true, nil
+			}
+			return basics.Address{}, false, nil
+		}
+	}
+
+	// No updates of this asset in the in-memory deltas; use on-disk DB.
+	return au.accountsq.lookupAssetCreator(aidx)
+}
+
 func (au *accountUpdates) allBalances(rnd basics.Round) (bals map[basics.Address]basics.AccountData, err error) {
 	offsetLimit, err := au.roundOffset(rnd)
 	if err != nil {
@@ -249,9 +367,31 @@ func (au *accountUpdates) committedUpTo(rnd basics.Round) basics.Round {
 		au.log.Panicf("committedUpTo: block %d too far in the future, lookback %d, dbRound %d, deltas %d", rnd, lookback, au.dbRound, len(au.deltas))
 	}
 
+	// The accounts DB must stay at the account state of a catchpoint
+	// until its digest is computed.
+	if !au.catchpointComputed() {
+		return au.dbRound
+	}
+
+	// Stop at the account state of the next catchpoint, so that its
+	// digest can be computed from the accounts DB.
+	offset := uint64(newBase - au.dbRound)
+	catchpoint := false
+	for i := uint64(0); i < offset; i++ {
+		_, ok := au.catchpointHeader(au.dbRound+basics.Round(i)+1, au.protos[i+1])
+		if ok {
+			offset = i + 1
+			newBase = au.dbRound + basics.Round(offset)
+			catchpoint = true
+			break
+		}
+	}
+
 	// If we recently flushed, wait to aggregate some more blocks.
+	// Catchpoints are flushed right away, since a later block commits
+	// to their digest.
 	flushTime := time.Now()
-	if !flushTime.After(au.lastFlushTime.Add(5 * time.Second)) {
+	if !catchpoint && !flushTime.After(au.lastFlushTime.Add(5*time.Second)) {
 		return au.dbRound
 	}
 
@@ -260,15 +400,42 @@ func (au *accountUpdates) committedUpTo(rnd basics.Round) basics.Round {
 	// au.accounts.
 	flushcount := make(map[basics.Address]int)
 
-	offset := uint64(newBase - au.dbRound)
+	hasHistory := au.hasHistory
+	var historyBase basics.Round
 	err := au.dbs.wdb.Atomic(func(tx *sql.Tx) error {
+		hasHistory = au.hasHistory
+
+		// Start recording account history from the current account
+		// state, or stop recording it if it has been disabled.
+		if au.recordHistory && !hasHistory {
+			var err error
+			historyBase, err = accountsHistoryStart(tx)
+			if err != nil {
+				return err
+			}
+			hasHistory = true
+		} else if !au.recordHistory && hasHistory {
+			err := accountsHistoryClear(tx)
+			if err != nil {
+				return err
+			}
+			hasHistory = false
+		}
+
 		for i := uint64(0); i < offset; i++ {
 			rnd := au.dbRound + basics.Round(i) + 1
-			err := accountsNewRound(tx, rnd, au.deltas[i], au.roundTotals[i+1].RewardsLevel, au.protos[i+1])
+			err := accountsNewRound(tx, rnd, au.deltas[i], au.assetDeltas[i], au.roundTotals[i+1].RewardsLevel, au.protos[i+1])
 			if err != nil {
 				return err
 			}
 
+			if hasHistory {
+				err = accountsHistoryNewRound(tx, rnd, au.deltas[i])
+				if err != nil {
+					return err
+				}
+			}
+
 			for addr := range au.deltas[i] {
 				flushcount[addr] = flushcount[addr] + 1
 			}
@@ -301,13 +468,140 @@ func (au *accountUpdates) committedUpTo(rnd basics.Round) basics.Round {
 	}
 
 	au.deltas = au.deltas[offset:]
+	au.assetDeltas = au.assetDeltas[offset:]
 	au.protos = au.protos[offset:]
 	au.roundTotals = au.roundTotals[offset:]
 	au.dbRound = newBase
 	au.lastFlushTime = flushTime
+
+	if hasHistory != au.hasHistory {
+		au.historyMu.Lock()
+		au.hasHistory = hasHistory
+		au.historyBase = historyBase
+		au.historyMu.Unlock()
+
+		if hasHistory && historyBase > 0 {
+			au.historyBackfills.Add(1)
+			go au.backfillHistory(historyBase, au.historyStop)
+		}
+	}
+
+	if catchpoint {
+		au.catchpointComputed()
+	}
 	return au.dbRound
 }
 
+// catchpointHeader returns the header of the catchpoint that covers the
+// account state of round balancesRound, if there is one.  proto is the
+// protocol of balancesRound.
+func (au *accountUpdates) catchpointHeader(balancesRound basics.Round, proto config.ConsensusParams) (CatchpointHeader, bool) {
+	rnd := balancesRound + basics.Round(proto.MaxBalLookback)
+	blkhdr, err := au.ledger.BlockHdr(rnd)
+	if err != nil {
+		return CatchpointHeader{}, false
+	}
+
+	br, ok := catchpointBalancesRound(rnd, config.Consensus[blkhdr.CurrentProtocol])
+	if !ok || br != balancesRound {
+		return CatchpointHeader{}, false
+	}
+
+	return CatchpointHeader{
+		Round:         rnd,
+		BlockHash:     blkhdr.Hash(),
+		BalancesRound: balancesRound,
+	}, true
+}
+
+// catchpointComputed returns false if the accounts DB is at the account
+// state of a catchpoint whose digest is not known, and cannot be computed
+// right now.  Otherwise, it computes the digest if needed, and returns true.
+func (au *accountUpdates) catchpointComputed() bool {
+	hdr, isCatchpoint := au.catchpointHeader(au.dbRound, au.protos[0])
+	if !isCatchpoint {
+		return true
+	}
+	if _, ok := au.catchpointDigests[hdr.Round]; ok {
+		return true
+	}
+
+	hdr.Totals = au.roundTotals[0]
+	digest, err := au.computeCatchpoint(hdr)
+	if err != nil {
+		// The accounts DB stays at the catchpoint, and the next flush
+		// tries again.
+		au.log.Warnf("unable to compute catchpoint for round %d: %v", hdr.Round, err)
+		return false
+	}
+
+	au.catchpointDigests[hdr.Round] = digest
+	for len(au.catchpointDigests) > catchpointDigestsKept {
+		oldest := hdr.Round
+		for rnd := range au.catchpointDigests {
+			if rnd < oldest {
+				oldest = rnd
+			}
+		}
+		delete(au.catchpointDigests, oldest)
+	}
+	au.log.Infof("computed catchpoint %v", CatchpointLabel{Round: hdr.Round, Digest: digest})
+	return true
+}
+
+// computeCatchpoint computes the digest of the catchpoint described by
+// hdr from a single snapshot of the accounts DB, which must be at the
+// account state of the catchpoint, and stores it.  If catchpoint files
+// are enabled, it also writes the catchpoint file from the same snapshot.
+func (au *accountUpdates) computeCatchpoint(hdr CatchpointHeader) (crypto.Digest, error) {
+	var digest crypto.Digest
+	err := au.dbs.rdb.Atomic(func(tx *sql.Tx) error {
+		accounts := func(f func(basics.Address, basics.AccountData) error) error {
+			return accountsForEach(tx, f)
+		}
+
+		var err0 error
+		if au.catchpointDir != "" {
+			digest, err0 = saveCatchpointFile(au.catchpointDir, hdr, accounts)
+			if err0 == nil {
+				return nil
+			}
+			au.log.Warnf("unable to write catchpoint file for round %d: %v", hdr.Round, err0)
+		}
+
+		digest, err0 = catchpointDigest(hdr, accounts)
+		return err0
+	})
+	if err != nil {
+		return crypto.Digest{}, err
+	}
+
+	err = au.dbs.wdb.Atomic(func(tx *sql.Tx) error {
+		return accountsPutCatchpoint(tx, hdr.Round, digest)
+	})
+	if err != nil {
+		return crypto.Digest{}, err
+	}
+
+	if au.catchpointDir != "" {
+		err = pruneCatchpointFiles(au.catchpointDir, au.catchpointHistory)
+		if err != nil {
+			au.log.Warnf("unable to remove old catchpoint files: %v", err)
+		}
+	}
+	return digest, nil
+}
+
+// catchpointDigest returns the digest of the catchpoint at round rnd.
+// It returns an error if the digest has not been computed.
+func (au *accountUpdates) catchpointDigest(rnd basics.Round) (crypto.Digest, error) {
+	digest, ok := au.catchpointDigests[rnd]
+	if !ok {
+		return crypto.Digest{}, fmt.Errorf("catchpoint %d: digest has not been computed", rnd)
+	}
+	return digest, nil
+}
+
 func (au *accountUpdates) newBlock(blk bookkeeping.Block, delta stateDelta) {
 	proto := config.Consensus[blk.CurrentProtocol]
 	rnd := blk.Round()
@@ -322,6 +616,7 @@ func (au *accountUpdates) newBlock(blk bookkeeping.Block, delta stateDelta) {
 	}
 
 	au.deltas = append(au.deltas, delta.accts)
+	au.assetDeltas = append(au.assetDeltas, delta.assets)
 	au.protos = append(au.protos, proto)
 
 	var ot basics.OverflowTracker
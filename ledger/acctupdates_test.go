@@ -49,6 +49,10 @@ func (ml *mockLedgerForTracker) Latest() basics.Round {
 	return basics.Round(len(ml.blocks)) - 1
 }
 
+func (ml *mockLedgerForTracker) GenesisHash() crypto.Digest {
+	return crypto.Digest{}
+}
+
 func (ml *mockLedgerForTracker) trackerEvalVerified(blk bookkeeping.Block, aux evalAux) (stateDelta, error) {
 	delta := stateDelta{
 		hdr: &bookkeeping.BlockHeader{},
@@ -237,3 +241,102 @@ func TestAcctUpdates(t *testing.T) {
 		checkAcctUpdates(t, au, i, basics.Round(proto.MaxBalLookback+14), accts, rewardsLevels, proto)
 	}
 }
+
+func TestAcctUpdatesHistory(t *testing.T) {
+	proto := config.Consensus[protocol.ConsensusCurrentVersion]
+
+	ml := makeMockLedgerForTracker(t)
+	defer ml.close()
+	ml.blocks = randomInitChain(protocol.ConsensusCurrentVersion, 10)
+
+	accts := []map[basics.Address]basics.AccountData{randomAccounts(20)}
+	rewardsLevels := []uint64{0}
+
+	pooldata := basics.AccountData{}
+	pooldata.MicroAlgos.Raw = 1000 * 1000 * 1000 * 1000
+	pooldata.Status = basics.NotParticipating
+	accts[0][testPoolAddr] = pooldata
+
+	sinkdata := basics.AccountData{}
+	sinkdata.MicroAlgos.Raw = 1000 * 1000 * 1000 * 1000
+	sinkdata.Status = basics.NotParticipating
+	accts[0][testSinkAddr] = sinkdata
+
+	au := &accountUpdates{initAccounts: accts[0], initProto: proto, recordHistory: true}
+	err := au.loadFromDisk(ml)
+	require.NoError(t, err)
+
+	rewardLevel := uint64(0)
+	for i := 1; i < 10; i++ {
+		accts = append(accts, accts[0])
+		rewardsLevels = append(rewardsLevels, rewardLevel)
+	}
+
+	latest := basics.Round(proto.MaxBalLookback + 15)
+	for i := basics.Round(10); i <= latest; i++ {
+		rewardLevelDelta := crypto.RandUint64() % 5
+		rewardLevel += rewardLevelDelta
+		updates, totals := randomDeltasBalanced(1, accts[i-1], rewardLevel)
+
+		prevTotals, err := au.totals(basics.Round(i - 1))
+		require.NoError(t, err)
+
+		oldPool := accts[i-1][testPoolAddr]
+		newPool := totals[testPoolAddr]
+		newPool.MicroAlgos.Raw -= prevTotals.RewardUnits() * rewardLevelDelta
+		updates[testPoolAddr] = accountDelta{old: oldPool, new: newPool}
+		totals[testPoolAddr] = newPool
+
+		blk := bookkeeping.Block{
+			BlockHeader: bookkeeping.BlockHeader{
+				Round: basics.Round(i),
+			},
+		}
+		blk.RewardsLevel = rewardLevel
+		blk.CurrentProtocol = protocol.ConsensusCurrentVersion
+
+		au.newBlock(blk, stateDelta{
+			accts: updates,
+			hdr:   &blk.BlockHeader,
+		})
+		ml.blocks = append(ml.blocks, blockEntry{block: blk})
+		accts = append(accts, totals)
+		rewardsLevels = append(rewardsLevels, rewardLevel)
+	}
+
+	// Flush everything that the tracker does not need to keep in memory
+	au.lastFlushTime = time.Time{}
+	dbRound := au.committedUpTo(latest)
+	require.Equal(t, latest-basics.Round(proto.MaxBalLookback), dbRound)
+	require.True(t, au.hasHistory)
+	require.Equal(t, basics.Round(0), au.historyBase)
+
+	for rnd := basics.Round(0); rnd <= latest; rnd++ {
+		for addr, data := range accts[rnd] {
+			d, err := au.lookup(rnd, addr, false)
+			require.NoError(t, err)
+			require.Equal(t, data, d, "round %d", rnd)
+
+			d, err = au.lookup(rnd, addr, true)
+			require.NoError(t, err)
+			require.Equal(t, data.WithUpdatedRewards(proto, rewardsLevels[rnd]), d, "round %d", rnd)
+		}
+
+		d, err := au.lookup(rnd, randomAddress(), false)
+		require.NoError(t, err)
+		require.Equal(t, basics.AccountData{}, d)
+	}
+
+	// Disabling the history drops it on the next flush
+	au.recordHistory = false
+	au.lastFlushTime = time.Time{}
+	au.committedUpTo(latest + 1)
+	require.False(t, au.hasHistory)
+
+	var addr basics.Address
+	for addr = range accts[0] {
+		break
+	}
+	_, err = au.lookup(0, addr, false)
+	require.Equal(t, ErrNoAccountHistory{Round: 0, Earliest: au.dbRound}, err)
+}
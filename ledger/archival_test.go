@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -65,6 +66,10 @@ func (wl *wrappedLedger) Latest() basics.Round {
 	return wl.l.Latest()
 }
 
+func (wl *wrappedLedger) GenesisHash() crypto.Digest {
+	return wl.l.GenesisHash()
+}
+
 func (wl *wrappedLedger) trackerDB() dbPair {
 	return wl.l.trackerDB()
 }
@@ -163,3 +168,62 @@ func checkTrackers(t *testing.T, wl *wrappedLedger, rnd basics.Round) (basics.Ro
 
 	return minMinSave, nil
 }
+
+func TestBlockRetention(t *testing.T) {
+	blk := bookkeeping.Block{}
+	blk.CurrentProtocol = protocol.ConsensusCurrentVersion
+	blk.RewardsPool = testPoolAddr
+	blk.FeeSink = testSinkAddr
+
+	accts := make(map[basics.Address]basics.AccountData)
+	accts[testPoolAddr] = basics.MakeAccountData(basics.NotParticipating, basics.MicroAlgos{Raw: 1234567890})
+	accts[testSinkAddr] = basics.MakeAccountData(basics.NotParticipating, basics.MicroAlgos{Raw: 1234567890})
+
+	dbName := fmt.Sprintf("%s.%d", t.Name(), crypto.RandUint64())
+	l, err := OpenLedger(logging.Base(), dbName, true, []bookkeeping.Block{blk}, accts, crypto.Digest{})
+	require.NoError(t, err)
+	defer l.Close()
+
+	for i := 0; i < 100; i++ {
+		blk.BlockHeader.Round++
+		require.NoError(t, l.AddBlock(blk, agreement.Certificate{}))
+	}
+	l.WaitForCommit(blk.Round())
+
+	// Archival ledgers keep every block, and the retention policy never
+	// forgets more than the trackers allow.
+	require.Equal(t, basics.Round(0), l.notifyCommit(blk.Round()))
+
+	l.SetArchival(false)
+	l.SetBlockRetention(30)
+	minToSave := l.notifyCommit(blk.Round())
+	require.True(t, minToSave <= blk.Round()+1-30)
+
+	l.SetBlockRetention(1000)
+	require.Equal(t, basics.Round(0), l.notifyCommit(blk.Round()))
+
+	// Prune the first 60 rounds in the background, and check that the
+	// pruned rounds are reported as such.
+	l.blockQ.forgetBefore(60)
+	for i := 0; i < 100; i++ {
+		_, err = l.Block(59)
+		if err != nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.Equal(t, ErrRoundPruned{Round: 59, Earliest: 60}, err)
+	_, _, err = l.BlockCert(0)
+	require.Equal(t, ErrRoundPruned{Round: 0, Earliest: 60}, err)
+
+	// Pruning past the latest block fails, and the rounds it could not
+	// delete remain available.
+	l.blockQ.forgetBefore(blk.Round() + 100)
+	time.Sleep(100 * time.Millisecond)
+	_, err = l.Block(60)
+	require.NoError(t, err)
+	_, err = l.Block(59)
+	require.Equal(t, ErrRoundPruned{Round: 59, Earliest: 60}, err)
+	_, err = l.Block(blk.Round() + 1)
+	require.IsType(t, ErrNoEntry{}, err)
+}
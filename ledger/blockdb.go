@@ -166,7 +166,11 @@ func blockPut(tx *sql.Tx, blk bookkeeping.Block, cert agreement.Certificate, aux
 		}
 	}
 
-	_, err = tx.Exec("INSERT INTO blocks (rnd, proto, hdrdata, blkdata, certdata, auxdata) VALUES (?, ?, ?, ?, ?, ?)",
+	return blockInsert(tx, blk, cert, aux)
+}
+
+func blockInsert(tx *sql.Tx, blk bookkeeping.Block, cert agreement.Certificate, aux evalAux) error {
+	_, err := tx.Exec("INSERT INTO blocks (rnd, proto, hdrdata, blkdata, certdata, auxdata) VALUES (?, ?, ?, ?, ?, ?)",
 		blk.Round(), blk.CurrentProtocol,
 		protocol.Encode(blk.BlockHeader),
 		protocol.Encode(blk),
@@ -175,6 +179,28 @@ func blockPut(tx *sql.Tx, blk bookkeeping.Block, cert agreement.Certificate, aux
 	return err
 }
 
+// blockReset replaces all blocks in the database using tx with blocks,
+// which must be consecutive but need not start at round 0.
+func blockReset(tx *sql.Tx, blocks []bookkeeping.Block, certs []agreement.Certificate) error {
+	_, err := tx.Exec("DELETE FROM blocks")
+	if err != nil {
+		return err
+	}
+
+	for i, blk := range blocks {
+		if i == 0 {
+			err = blockInsert(tx, blk, certs[i], evalAux{})
+		} else {
+			err = blockPut(tx, blk, certs[i], evalAux{})
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func blockNext(tx *sql.Tx) (basics.Round, error) {
 	var max sql.NullInt64
 	err := tx.QueryRow("SELECT MAX(rnd) FROM blocks").Scan(&max)
@@ -203,6 +229,20 @@ func blockLatest(tx *sql.Tx) (basics.Round, error) {
 	return 0, fmt.Errorf("no blocks present")
 }
 
+func blockEarliest(tx *sql.Tx) (basics.Round, error) {
+	var min sql.NullInt64
+	err := tx.QueryRow("SELECT MIN(rnd) FROM blocks").Scan(&min)
+	if err != nil {
+		return 0, err
+	}
+
+	if min.Valid {
+		return basics.Round(min.Int64), nil
+	}
+
+	return 0, fmt.Errorf("no blocks present")
+}
+
 func blockForgetBefore(tx *sql.Tx, rnd basics.Round) error {
 	next, err := blockNext(tx)
 	if err != nil {
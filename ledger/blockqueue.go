@@ -35,15 +35,30 @@ type blockEntry struct {
 	aux   evalAux
 }
 
+// blockPruneBatchSize is the number of rounds of blocks that the pruner
+// deletes in a single database transaction.
+const blockPruneBatchSize = 1000
+
 type blockQueue struct {
 	l *Ledger
 
 	lastCommitted basics.Round
 	q             []blockEntry
 
+	// earliest is the earliest round that has not been pruned, and
+	// pruneTarget the round before which the pruner deletes blocks.
+	// pruning is the round before which the pruner is deleting blocks
+	// right now; rounds from earliest to pruning may already be gone.
+	earliest    basics.Round
+	pruning     basics.Round
+	pruneTarget basics.Round
+	pruneWake   chan struct{}
+
 	mu      deadlock.Mutex
 	cond    *sync.Cond
 	running bool
+	stop    chan struct{}
+	wg      sync.WaitGroup
 }
 
 func bqInit(l *Ledger) (*blockQueue, error) {
@@ -51,30 +66,46 @@ func bqInit(l *Ledger) (*blockQueue, error) {
 	bq.cond = sync.NewCond(&bq.mu)
 	bq.l = l
 	bq.running = true
+	bq.stop = make(chan struct{})
+	bq.pruneWake = make(chan struct{}, 1)
 
 	err := bq.l.blockDBs.rdb.Atomic(func(tx *sql.Tx) error {
 		var err0 error
 		bq.lastCommitted, err0 = blockLatest(tx)
+		if err0 != nil {
+			return err0
+		}
+		bq.earliest, err0 = blockEarliest(tx)
 		return err0
 	})
 	if err != nil {
 		return nil, err
 	}
+	bq.pruning = bq.earliest
+	bq.pruneTarget = bq.earliest
 
+	bq.wg.Add(2)
 	go bq.syncer()
+	go bq.pruner()
 	return bq, nil
 }
 
+// close stops the syncer and the pruner and waits for them to exit.
 func (bq *blockQueue) close() {
 	bq.mu.Lock()
-	defer bq.mu.Unlock()
 	if bq.running {
 		bq.running = false
 		bq.cond.Broadcast()
+		close(bq.stop)
 	}
+	bq.mu.Unlock()
+
+	bq.wg.Wait()
 }
 
 func (bq *blockQueue) syncer() {
+	defer bq.wg.Done()
+
 	bq.mu.Lock()
 	for {
 		for bq.running && len(bq.q) == 0 {
@@ -122,14 +153,72 @@ func (bq *blockQueue) syncer() {
 			bq.mu.Unlock()
 
 			minToSave := bq.l.notifyCommit(committed)
-			err = bq.l.blockDBs.wdb.Atomic(func(tx *sql.Tx) error {
-				return blockForgetBefore(tx, minToSave)
-			})
-			if err != nil {
-				bq.l.log.Warnf("blockQueue.syncer: blockForgetBefore(%d): %v", minToSave, err)
+			bq.forgetBefore(minToSave)
+
+			bq.mu.Lock()
+		}
+	}
+}
+
+// forgetBefore asks the pruner to delete the blocks before rnd.
+func (bq *blockQueue) forgetBefore(rnd basics.Round) {
+	bq.mu.Lock()
+	defer bq.mu.Unlock()
+	if rnd <= bq.pruneTarget {
+		return
+	}
+
+	bq.pruneTarget = rnd
+	select {
+	case bq.pruneWake <- struct{}{}:
+	default:
+	}
+}
+
+// pruner deletes the blocks before pruneTarget in the background, a
+// batch at a time, so that it does not hold the database for long.
+func (bq *blockQueue) pruner() {
+	defer bq.wg.Done()
+
+	for {
+		select {
+		case <-bq.stop:
+			return
+		case <-bq.pruneWake:
+		}
+
+		for {
+			bq.mu.Lock()
+			if !bq.running || bq.earliest >= bq.pruneTarget {
+				bq.mu.Unlock()
+				break
 			}
 
+			forgetBefore := bq.earliest + blockPruneBatchSize
+			if forgetBefore > bq.pruneTarget {
+				forgetBefore = bq.pruneTarget
+			}
+
+			// Lookups that miss a block of the batch while it is being
+			// deleted see ErrRoundPruned rather than a missing entry;
+			// see prunedErr.  The batch only counts as pruned once the
+			// delete commits, so that a failed delete is retried.
+			bq.pruning = forgetBefore
+			bq.mu.Unlock()
+
+			err := bq.l.blockDBs.wdb.Atomic(func(tx *sql.Tx) error {
+				return blockForgetBefore(tx, forgetBefore)
+			})
+
 			bq.mu.Lock()
+			if err != nil {
+				bq.pruning = bq.earliest
+				bq.mu.Unlock()
+				bq.l.log.Warnf("blockQueue.pruner: blockForgetBefore(%d): %v", forgetBefore, err)
+				break
+			}
+			bq.earliest = forgetBefore
+			bq.mu.Unlock()
 		}
 	}
 }
@@ -204,6 +293,13 @@ func (bq *blockQueue) checkEntry(r basics.Round) (e *blockEntry, lastCommitted b
 		}
 	}
 
+	if r < bq.earliest {
+		return nil, lastCommitted, latest, ErrRoundPruned{
+			Round:    r,
+			Earliest: bq.earliest,
+		}
+	}
+
 	if r <= bq.lastCommitted {
 		return nil, lastCommitted, latest, nil
 	}
@@ -211,6 +307,24 @@ func (bq *blockQueue) checkEntry(r basics.Round) (e *blockEntry, lastCommitted b
 	return &bq.q[r-bq.lastCommitted-1], lastCommitted, latest, nil
 }
 
+// prunedErr turns a missing entry for round r into ErrRoundPruned if the
+// pruner is deleting the blocks of r.
+func (bq *blockQueue) prunedErr(r basics.Round, err error) error {
+	if _, ok := err.(ErrNoEntry); !ok {
+		return err
+	}
+
+	bq.mu.Lock()
+	defer bq.mu.Unlock()
+	if r < bq.pruning {
+		return ErrRoundPruned{
+			Round:    r,
+			Earliest: bq.pruning,
+		}
+	}
+	return err
+}
+
 func updateErrNoEntry(err error, lastCommitted basics.Round, latest basics.Round) error {
 	if err != nil {
 		switch errt := err.(type) {
@@ -239,7 +353,7 @@ func (bq *blockQueue) getBlock(r basics.Round) (blk bookkeeping.Block, err error
 		blk, err0 = blockGet(tx, r)
 		return err0
 	})
-	err = updateErrNoEntry(err, lastCommitted, latest)
+	err = updateErrNoEntry(bq.prunedErr(r, err), lastCommitted, latest)
 	return
 }
 
@@ -258,7 +372,7 @@ func (bq *blockQueue) getBlockHdr(r basics.Round) (hdr bookkeeping.BlockHeader,
 		hdr, err0 = blockGetHdr(tx, r)
 		return err0
 	})
-	err = updateErrNoEntry(err, lastCommitted, latest)
+	err = updateErrNoEntry(bq.prunedErr(r, err), lastCommitted, latest)
 	return
 }
 
@@ -277,7 +391,7 @@ func (bq *blockQueue) getBlockCert(r basics.Round) (blk bookkeeping.Block, cert
 		blk, cert, err0 = blockGetCert(tx, r)
 		return err0
 	})
-	err = updateErrNoEntry(err, lastCommitted, latest)
+	err = updateErrNoEntry(bq.prunedErr(r, err), lastCommitted, latest)
 	return
 }
 
@@ -296,6 +410,6 @@ func (bq *blockQueue) getBlockAux(r basics.Round) (blk bookkeeping.Block, aux ev
 		blk, aux, err0 = blockGetAux(tx, r)
 		return err0
 	})
-	err = updateErrNoEntry(err, lastCommitted, latest)
+	err = updateErrNoEntry(bq.prunedErr(r, err), lastCommitted, latest)
 	return
 }
@@ -0,0 +1,68 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package ledger
+
+import (
+	"database/sql"
+
+	"github.com/algorand/go-algorand/agreement"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/util/db"
+)
+
+// BlockReader reads the blocks that a ledger stored on disk.  It opens the
+// block database read-only, so it neither modifies the ledger nor gets in
+// the way of the process that owns it, such as a running algod.
+type BlockReader struct {
+	db db.Accessor
+}
+
+// OpenBlockReader opens the block database of the ledger at dbPathPrefix
+// read-only.
+func OpenBlockReader(dbPathPrefix string) (*BlockReader, error) {
+	accessor, err := db.MakeAccessor(dbPathPrefix+".block.sqlite", true, false)
+	if err != nil {
+		return nil, err
+	}
+	return &BlockReader{db: accessor}, nil
+}
+
+// Close closes the block database.
+func (br *BlockReader) Close() {
+	br.db.Close()
+}
+
+// Latest returns the latest round stored in the block database.
+func (br *BlockReader) Latest() (rnd basics.Round, err error) {
+	err = br.db.Atomic(func(tx *sql.Tx) error {
+		var err0 error
+		rnd, err0 = blockLatest(tx)
+		return err0
+	})
+	return
+}
+
+// BlockCert returns the block and the certificate of round rnd.
+func (br *BlockReader) BlockCert(rnd basics.Round) (blk bookkeeping.Block, cert agreement.Certificate, err error) {
+	err = br.db.Atomic(func(tx *sql.Tx) error {
+		var err0 error
+		blk, cert, err0 = blockGetCert(tx, rnd)
+		return err0
+	})
+	return
+}
@@ -0,0 +1,80 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package ledger
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/go-algorand/agreement"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/logging"
+	"github.com/algorand/go-algorand/util/execpool"
+)
+
+func TestBlockReader(t *testing.T) {
+	blks, accts, _, _ := genesis(10)
+
+	dir, err := ioutil.TempDir("", "blockreader")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	dbPrefix := filepath.Join(dir, "ledger")
+	l, err := OpenLedger(logging.Base(), dbPrefix, false, blks, accts, blks[0].BlockHeader.GenesisHash)
+	require.NoError(t, err)
+	defer l.Close()
+
+	backlogPool := execpool.MakeBacklog(nil, 0, execpool.LowPriority, nil)
+	defer backlogPool.Shutdown()
+
+	prev := blks[0].BlockHeader
+	for i := 0; i < 10; i++ {
+		eval, err := l.StartEvaluator(bookkeeping.MakeBlock(prev).BlockHeader, nil, backlogPool)
+		require.NoError(t, err)
+		vb, err := eval.GenerateBlock()
+		require.NoError(t, err)
+		require.NoError(t, l.AddValidatedBlock(*vb, agreement.Certificate{Round: vb.Block().Round()}))
+		prev = vb.Block().BlockHeader
+	}
+	l.WaitForCommit(prev.Round)
+
+	// The ledger stays open, as it would in a running node.
+	br, err := OpenBlockReader(dbPrefix)
+	require.NoError(t, err)
+	defer br.Close()
+
+	latest, err := br.Latest()
+	require.NoError(t, err)
+	require.Equal(t, prev.Round, latest)
+
+	for rnd := basics.Round(0); rnd <= latest; rnd++ {
+		expectedBlk, expectedCert, err := l.BlockCert(rnd)
+		require.NoError(t, err)
+		blk, cert, err := br.BlockCert(rnd)
+		require.NoError(t, err)
+		require.Equal(t, expectedBlk, blk)
+		require.Equal(t, expectedCert, cert)
+	}
+
+	_, _, err = br.BlockCert(latest + 1)
+	require.Equal(t, ErrNoEntry{Round: latest + 1}, err)
+}
@@ -78,8 +78,15 @@ func (b *bulletin) Wait(round basics.Round) chan struct{} {
 }
 
 func (b *bulletin) loadFromDisk(l ledgerForTracker) error {
-	b.pendingNotificationRequests = make(map[basics.Round]notifier)
-	b.latestRound = l.Latest()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Keep pending requests across reloads (e.g., when installing a
+	// catchpoint), notifying the ones that are now satisfied.
+	if b.pendingNotificationRequests == nil {
+		b.pendingNotificationRequests = make(map[basics.Round]notifier)
+	}
+	b.notifyUpTo(l.Latest())
 	return nil
 }
 
@@ -93,6 +100,13 @@ func (b *bulletin) committedUpTo(rnd basics.Round) basics.Round {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
+	b.notifyUpTo(rnd)
+	return rnd
+}
+
+// notifyUpTo signals the requests for rounds up to rnd.  The caller
+// must hold b.mu.
+func (b *bulletin) notifyUpTo(rnd basics.Round) {
 	for pending, signal := range b.pendingNotificationRequests {
 		if pending > rnd {
 			continue
@@ -103,5 +117,4 @@ func (b *bulletin) committedUpTo(rnd basics.Round) basics.Round {
 	}
 
 	b.latestRound = rnd
-	return rnd
 }
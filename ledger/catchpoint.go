@@ -0,0 +1,503 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package ledger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/algorand/go-algorand/agreement"
+	"github.com/algorand/go-algorand/config"
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/protocol"
+)
+
+// A catchpoint is a certified snapshot of the account state, which
+// allows a new node to start from a recent round instead of replaying
+// every block since genesis.
+//
+// If round R is a catchpoint round (R is a multiple of the protocol's
+// CatchpointInterval), the catchpoint covers the account state as of
+// round R-MaxBalLookback, and the hash of block R.  Block
+// R+MaxBalLookback commits to the digest of the catchpoint in its
+// CatchpointHash, so that every catchpoint is validated by agreement.
+// The accounts tracker computes the digest as it flushes the account
+// state of the catchpoint, which happens once block R is written; the
+// lag gives it time to do so before any block needs the digest.  Block
+// evaluation only looks up the digest, and a block that commits to a
+// catchpoint whose digest is not known does not validate.
+//
+// A node starting from the catchpoint installs the account state, and
+// fetches the blocks up to R, checking them against the hash of block R.
+// It then replays the last MaxBalLookback blocks, after which it has
+// the account state it needs to authenticate certificates of the next
+// blocks, and can catch up and participate in agreement as usual.
+
+// catchpointFileVersion is the version of the catchpoint file format.
+const catchpointFileVersion = uint64(1)
+
+// catchpointFileSuffix is the suffix of catchpoint file names, which
+// are named after the catchpoint round.
+const catchpointFileSuffix = ".catchpoint"
+
+// CatchpointHeader describes the contents of a catchpoint.
+type CatchpointHeader struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	Version uint64 `codec:"version"`
+
+	// Round is the catchpoint round, and BlockHash the hash of the
+	// block at that round.
+	Round     basics.Round          `codec:"round"`
+	BlockHash bookkeeping.BlockHash `codec:"blockhash"`
+
+	// BalancesRound is the round of the account state.
+	BalancesRound basics.Round  `codec:"balancesround"`
+	Totals        AccountTotals `codec:"totals"`
+	TotalAccounts uint64        `codec:"accounts"`
+}
+
+// catchpointBalanceRecord is the encoding of a single account in a
+// catchpoint file.
+type catchpointBalanceRecord struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	Address     basics.Address     `codec:"pk"`
+	AccountData basics.AccountData `codec:"ad"`
+}
+
+// Catchpoint holds the contents of a catchpoint file.
+type Catchpoint struct {
+	Header   CatchpointHeader
+	Balances map[basics.Address]basics.AccountData
+
+	// Digest is the hash that block Header.Round+MaxBalLookback commits to.
+	Digest crypto.Digest
+}
+
+// CatchpointLabel identifies a catchpoint by its round and digest.
+// Its string form is "round#digest".
+type CatchpointLabel struct {
+	Round  basics.Round
+	Digest crypto.Digest
+}
+
+// String returns the catchpoint label in the form expected by
+// ParseCatchpointLabel.
+func (cl CatchpointLabel) String() string {
+	return fmt.Sprintf("%d#%s", cl.Round, cl.Digest)
+}
+
+// ParseCatchpointLabel parses a "round#digest" catchpoint label.
+func ParseCatchpointLabel(label string) (cl CatchpointLabel, err error) {
+	parts := strings.Split(label, "#")
+	if len(parts) != 2 {
+		err = fmt.Errorf("catchpoint label %s is not of the form round#digest", label)
+		return
+	}
+
+	rnd, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		err = fmt.Errorf("catchpoint label %s has an invalid round: %v", label, err)
+		return
+	}
+
+	cl.Digest, err = crypto.DigestFromString(parts[1])
+	if err != nil {
+		err = fmt.Errorf("catchpoint label %s has an invalid digest: %v", label, err)
+		return
+	}
+
+	cl.Round = basics.Round(rnd)
+	return
+}
+
+// catchpointBalancesRound returns the round of the account state that a
+// catchpoint at round rnd covers, and whether rnd is a catchpoint round
+// at all under proto, the protocol of block rnd.
+func catchpointBalancesRound(rnd basics.Round, proto config.ConsensusParams) (basics.Round, bool) {
+	if proto.CatchpointInterval == 0 || rnd == 0 {
+		return 0, false
+	}
+	if uint64(rnd)%proto.CatchpointInterval != 0 || uint64(rnd) < proto.MaxBalLookback {
+		return 0, false
+	}
+	return rnd - basics.Round(proto.MaxBalLookback), true
+}
+
+// catchpointCommitRound returns the round of the catchpoint that block
+// rnd commits to, if it commits to one.  proto is the protocol of block
+// rnd.  Whether that round is in fact a catchpoint round depends on the
+// protocol of its own block.
+func catchpointCommitRound(rnd basics.Round, proto config.ConsensusParams) (basics.Round, bool) {
+	lag := basics.Round(proto.MaxBalLookback)
+	if proto.CatchpointInterval == 0 || rnd <= lag {
+		return 0, false
+	}
+	return rnd - lag, true
+}
+
+// catchpointAccounts calls f on every account of a catchpoint, in the
+// order of their addresses, stopping at the first error.
+type catchpointAccounts func(f func(basics.Address, basics.AccountData) error) error
+
+// encodeCatchpoint writes the catchpoint header followed by every
+// non-empty account, in the order of their addresses.  The digest of a
+// catchpoint is the hash of this encoding.  The accounts are visited
+// twice, first to count them for the header, so that they never need
+// to be held in memory.
+func encodeCatchpoint(w io.Writer, hdr CatchpointHeader, accounts catchpointAccounts) error {
+	hdr.Version = catchpointFileVersion
+	hdr.TotalAccounts = 0
+	err := accounts(func(addr basics.Address, data basics.AccountData) error {
+		// the genesis accounts may include empty ones, which are only
+		// pruned from the account DB once they change
+		if !data.IsZero() {
+			hdr.TotalAccounts++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	enc := protocol.NewEncoder(w)
+	err = enc.Encode(hdr)
+	if err != nil {
+		return err
+	}
+
+	written := uint64(0)
+	err = accounts(func(addr basics.Address, data basics.AccountData) error {
+		if data.IsZero() {
+			return nil
+		}
+		written++
+		return enc.Encode(catchpointBalanceRecord{Address: addr, AccountData: data})
+	})
+	if err != nil {
+		return err
+	}
+	if written != hdr.TotalAccounts {
+		return fmt.Errorf("catchpoint accounts changed while encoding: %d != %d", written, hdr.TotalAccounts)
+	}
+	return nil
+}
+
+// catchpointHash computes the digest of a catchpoint from its encoding.
+type catchpointHash struct {
+	hash.Hash
+}
+
+func newCatchpointHash() *catchpointHash {
+	h := &catchpointHash{Hash: crypto.NewHash()}
+	h.Write([]byte(protocol.Catchpoint))
+	return h
+}
+
+func (h *catchpointHash) digest() (d crypto.Digest) {
+	copy(d[:], h.Sum(nil))
+	return
+}
+
+// catchpointDigest computes the digest of a catchpoint.
+func catchpointDigest(hdr CatchpointHeader, accounts catchpointAccounts) (crypto.Digest, error) {
+	h := newCatchpointHash()
+	err := encodeCatchpoint(h, hdr, accounts)
+	if err != nil {
+		return crypto.Digest{}, err
+	}
+	return h.digest(), nil
+}
+
+// writeCatchpoint writes a compressed catchpoint file to w, and returns
+// the digest of the catchpoint.
+func writeCatchpoint(w io.Writer, hdr CatchpointHeader, accounts catchpointAccounts) (crypto.Digest, error) {
+	h := newCatchpointHash()
+	gz := gzip.NewWriter(w)
+	err := encodeCatchpoint(io.MultiWriter(gz, h), hdr, accounts)
+	if err != nil {
+		return crypto.Digest{}, err
+	}
+	err = gz.Close()
+	if err != nil {
+		return crypto.Digest{}, err
+	}
+	return h.digest(), nil
+}
+
+// ReadCatchpoint reads a catchpoint file, and computes the digest of the
+// catchpoint.  The caller is responsible for checking the digest against
+// a trusted catchpoint label or block header.
+func ReadCatchpoint(r io.Reader) (*Catchpoint, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	h := newCatchpointHash()
+	dec := protocol.NewDecoder(io.TeeReader(gz, h))
+
+	cp := &Catchpoint{}
+	err = dec.Decode(&cp.Header)
+	if err != nil {
+		return nil, err
+	}
+	if cp.Header.Version != catchpointFileVersion {
+		return nil, fmt.Errorf("unsupported catchpoint file version %d", cp.Header.Version)
+	}
+
+	cp.Balances = make(map[basics.Address]basics.AccountData)
+	var prev basics.Address
+	for i := uint64(0); i < cp.Header.TotalAccounts; i++ {
+		var rec catchpointBalanceRecord
+		err = dec.Decode(&rec)
+		if err != nil {
+			return nil, fmt.Errorf("catchpoint account %d: %v", i, err)
+		}
+		if i > 0 && bytes.Compare(prev[:], rec.Address[:]) >= 0 {
+			return nil, fmt.Errorf("catchpoint account %d: %v out of order", i, rec.Address)
+		}
+		if rec.AccountData.IsZero() {
+			return nil, fmt.Errorf("catchpoint account %d: %v is empty", i, rec.Address)
+		}
+		cp.Balances[rec.Address] = rec.AccountData
+		prev = rec.Address
+	}
+
+	var extra catchpointBalanceRecord
+	err = dec.Decode(&extra)
+	if err != io.EOF {
+		return nil, fmt.Errorf("catchpoint has more than %d accounts", cp.Header.TotalAccounts)
+	}
+
+	cp.Digest = h.digest()
+	return cp, nil
+}
+
+// catchpointFileName returns the name of the catchpoint file for round rnd
+// in directory dir.
+func catchpointFileName(dir string, rnd basics.Round) string {
+	return filepath.Join(dir, strconv.FormatUint(uint64(rnd), 10)+catchpointFileSuffix)
+}
+
+// saveCatchpointFile writes the catchpoint file for hdr.Round to dir, and
+// returns the digest of the catchpoint.
+func saveCatchpointFile(dir string, hdr CatchpointHeader, accounts catchpointAccounts) (crypto.Digest, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return crypto.Digest{}, err
+	}
+
+	fname := catchpointFileName(dir, hdr.Round)
+	tmpname := fname + ".tmp"
+	f, err := os.OpenFile(tmpname, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return crypto.Digest{}, err
+	}
+
+	digest, err := writeCatchpoint(f, hdr, accounts)
+	closeErr := f.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpname, fname)
+	}
+	if err != nil {
+		os.Remove(tmpname)
+		return crypto.Digest{}, err
+	}
+	return digest, nil
+}
+
+// pruneCatchpointFiles removes all but the history most recent catchpoint
+// files from dir.
+func pruneCatchpointFiles(dir string, history int) error {
+	matches, err := filepath.Glob(filepath.Join(dir, "*"+catchpointFileSuffix))
+	if err != nil {
+		return err
+	}
+
+	var rounds []uint64
+	for _, match := range matches {
+		rnd, err := strconv.ParseUint(strings.TrimSuffix(filepath.Base(match), catchpointFileSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		rounds = append(rounds, rnd)
+	}
+	if len(rounds) <= history {
+		return nil
+	}
+
+	sort.Slice(rounds, func(i, j int) bool { return rounds[i] < rounds[j] })
+	for _, rnd := range rounds[:len(rounds)-history] {
+		err = os.Remove(catchpointFileName(dir, basics.Round(rnd)))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetCatchpointFiles makes the ledger write a catchpoint file to dir
+// for every catchpoint, keeping the history most recent files.  A zero
+// history disables writing catchpoint files.
+func (l *Ledger) SetCatchpointFiles(dir string, history int) {
+	l.trackerMu.Lock()
+	defer l.trackerMu.Unlock()
+
+	if history <= 0 {
+		dir = ""
+	}
+	l.accts.catchpointDir = dir
+	l.accts.catchpointHistory = history
+}
+
+// OpenCatchpointFile opens the catchpoint file for round rnd, if the
+// ledger has written it.
+func (l *Ledger) OpenCatchpointFile(rnd basics.Round) (*os.File, error) {
+	l.trackerMu.RLock()
+	dir := l.accts.catchpointDir
+	l.trackerMu.RUnlock()
+
+	if dir == "" {
+		return nil, fmt.Errorf("ledger does not write catchpoint files")
+	}
+	return os.Open(catchpointFileName(dir, rnd))
+}
+
+// catchpointDigest returns the digest of the catchpoint at round rnd, or
+// a zero digest if rnd is not a catchpoint round.  It does not compute
+// the digest itself, and returns an error if the accounts tracker has not
+// computed it.
+func (l *Ledger) catchpointDigest(rnd basics.Round) (crypto.Digest, error) {
+	blkhdr, err := l.BlockHdr(rnd)
+	if err != nil {
+		return crypto.Digest{}, err
+	}
+
+	_, ok := catchpointBalancesRound(rnd, config.Consensus[blkhdr.CurrentProtocol])
+	if !ok {
+		return crypto.Digest{}, nil
+	}
+
+	l.trackerMu.RLock()
+	defer l.trackerMu.RUnlock()
+	return l.accts.catchpointDigest(rnd)
+}
+
+// InstallCatchpoint replaces the state of the ledger with the account
+// state of a catchpoint.  blocks are the blocks up to and including the
+// round of the account state, along with their certificates; they must
+// cover at least the MaxTxnLife rounds before it.  They are stored
+// without being evaluated.  The caller is responsible for checking the
+// catchpoint and the blocks against a trusted catchpoint label, and for
+// adding the blocks that follow with AddBlock.
+//
+// Nothing else may write to the ledger while the catchpoint is being
+// installed.
+func (l *Ledger) InstallCatchpoint(cp *Catchpoint, blocks []bookkeeping.Block, certs []agreement.Certificate) error {
+	if len(blocks) == 0 || len(blocks) != len(certs) {
+		return fmt.Errorf("InstallCatchpoint: %d blocks and %d certificates", len(blocks), len(certs))
+	}
+
+	last := blocks[len(blocks)-1]
+	if last.Round() != cp.Header.BalancesRound {
+		return fmt.Errorf("InstallCatchpoint: last block %d is not at the catchpoint balances round %d", last.Round(), cp.Header.BalancesRound)
+	}
+	for i := 1; i < len(blocks); i++ {
+		if blocks[i].Round() != blocks[i-1].Round()+1 || blocks[i].Branch != blocks[i-1].Hash() {
+			return fmt.Errorf("InstallCatchpoint: block %d does not follow block %d", blocks[i].Round(), blocks[i-1].Round())
+		}
+	}
+
+	proto := config.Consensus[last.CurrentProtocol]
+	if cp.Header.BalancesRound+1 > blocks[0].Round()+basics.Round(proto.MaxTxnLife) {
+		return fmt.Errorf("InstallCatchpoint: blocks start at %d, need the last %d rounds before %d", blocks[0].Round(), proto.MaxTxnLife, cp.Header.BalancesRound+1)
+	}
+
+	var ot basics.OverflowTracker
+	var totals AccountTotals
+	totals.RewardsLevel = cp.Header.Totals.RewardsLevel
+	for _, data := range cp.Balances {
+		totals.addAccount(proto, data, &ot)
+	}
+	if ot.Overflowed || totals != cp.Header.Totals {
+		return fmt.Errorf("InstallCatchpoint: catchpoint totals do not match its accounts")
+	}
+
+	latest := l.Latest()
+	if latest >= cp.Header.Round {
+		return fmt.Errorf("InstallCatchpoint: ledger is already at round %d, past catchpoint round %d", latest, cp.Header.Round)
+	}
+
+	// Flush and stop the block queue before taking the tracker lock,
+	// since flushing notifies the trackers.
+	l.blockQ.waitCommit(latest)
+	l.blockQ.close()
+
+	l.trackerMu.Lock()
+	defer l.trackerMu.Unlock()
+
+	err := l.trackerDBs.wdb.Atomic(func(tx *sql.Tx) error {
+		err0 := accountsReset(tx, cp.Balances, cp.Header.BalancesRound, cp.Header.Totals)
+		if err0 != nil {
+			return err0
+		}
+		return accountsPutCatchpoint(tx, cp.Header.Round, cp.Digest)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = l.blockDBs.wdb.Atomic(func(tx *sql.Tx) error {
+		return blockReset(tx, blocks, certs)
+	})
+	if err != nil {
+		return err
+	}
+
+	l.blockQ, err = bqInit(l)
+	if err != nil {
+		return err
+	}
+
+	// Reload the trackers that keep ledger state; the others only
+	// follow new blocks.
+	for _, lt := range []ledgerTracker{&l.accts, &l.txTail, &l.bulletin, &l.time} {
+		err = lt.loadFromDisk(l)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
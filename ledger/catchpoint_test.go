@@ -0,0 +1,385 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package ledger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"os"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/go-algorand/agreement"
+	"github.com/algorand/go-algorand/config"
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/logging"
+	"github.com/algorand/go-algorand/protocol"
+	"github.com/algorand/go-algorand/util/execpool"
+)
+
+var protoCatchpoint = protocol.ConsensusVersion("TestCatchpoint")
+
+func init() {
+	params := config.Consensus[protocol.ConsensusCurrentVersion]
+	params.CatchpointInterval = 16
+	params.MaxBalLookback = 4
+	params.MaxTxnLife = 8
+	params.ApprovedUpgrades = map[protocol.ConsensusVersion]bool{}
+	config.Consensus[protoCatchpoint] = params
+}
+
+func randomCatchpointHeader(bals map[basics.Address]basics.AccountData) CatchpointHeader {
+	proto := config.Consensus[protocol.ConsensusCurrentVersion]
+	hdr := CatchpointHeader{
+		Round:         100,
+		BalancesRound: 96,
+	}
+	crypto.RandBytes(hdr.BlockHash[:])
+
+	var ot basics.OverflowTracker
+	for _, data := range bals {
+		hdr.Totals.addAccount(proto, data, &ot)
+	}
+	return hdr
+}
+
+// mapCatchpointAccounts returns the accounts of bals as catchpoint
+// accounts.
+func mapCatchpointAccounts(bals map[basics.Address]basics.AccountData) catchpointAccounts {
+	addrs := make([]basics.Address, 0, len(bals))
+	for addr := range bals {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return bytes.Compare(addrs[i][:], addrs[j][:]) < 0
+	})
+
+	return func(f func(basics.Address, basics.AccountData) error) error {
+		for _, addr := range addrs {
+			err := f(addr, bals[addr])
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func TestCatchpointLabel(t *testing.T) {
+	cl := CatchpointLabel{Round: 12345}
+	crypto.RandBytes(cl.Digest[:])
+
+	parsed, err := ParseCatchpointLabel(cl.String())
+	require.NoError(t, err)
+	require.Equal(t, cl, parsed)
+
+	for _, bad := range []string{"", "12345", "12345#", "x#" + cl.Digest.String(), "1#2#3", "12345#" + cl.Digest.String() + "A"} {
+		_, err = ParseCatchpointLabel(bad)
+		require.Error(t, err, bad)
+	}
+}
+
+func TestCatchpointBalancesRound(t *testing.T) {
+	proto := config.Consensus[protoCatchpoint]
+
+	_, ok := catchpointBalancesRound(0, proto)
+	require.False(t, ok)
+	_, ok = catchpointBalancesRound(17, proto)
+	require.False(t, ok)
+
+	s, ok := catchpointBalancesRound(32, proto)
+	require.True(t, ok)
+	require.Equal(t, basics.Round(28), s)
+
+	_, ok = catchpointBalancesRound(32, config.Consensus[protocol.ConsensusCurrentVersion])
+	require.False(t, ok)
+}
+
+func TestCatchpointCommitRound(t *testing.T) {
+	proto := config.Consensus[protoCatchpoint]
+
+	_, ok := catchpointCommitRound(4, proto)
+	require.False(t, ok)
+
+	r, ok := catchpointCommitRound(36, proto)
+	require.True(t, ok)
+	require.Equal(t, basics.Round(32), r)
+
+	_, ok = catchpointCommitRound(36, config.Consensus[protocol.ConsensusCurrentVersion])
+	require.False(t, ok)
+}
+
+func TestCatchpointWriteRead(t *testing.T) {
+	bals := randomAccounts(50)
+	var zero basics.Address
+	crypto.RandBytes(zero[:])
+	bals[zero] = basics.AccountData{}
+
+	hdr := randomCatchpointHeader(bals)
+
+	var buf bytes.Buffer
+	digest, err := writeCatchpoint(&buf, hdr, mapCatchpointAccounts(bals))
+	require.NoError(t, err)
+	expected, err := catchpointDigest(hdr, mapCatchpointAccounts(bals))
+	require.NoError(t, err)
+	require.Equal(t, expected, digest)
+
+	cp, err := ReadCatchpoint(&buf)
+	require.NoError(t, err)
+	require.Equal(t, digest, cp.Digest)
+	require.Equal(t, hdr.Round, cp.Header.Round)
+	require.Equal(t, hdr.BlockHash, cp.Header.BlockHash)
+	require.Equal(t, hdr.BalancesRound, cp.Header.BalancesRound)
+	require.Equal(t, hdr.Totals, cp.Header.Totals)
+	require.Equal(t, uint64(len(bals)-1), cp.Header.TotalAccounts)
+
+	// Empty accounts are left out of the catchpoint
+	delete(bals, zero)
+	require.Equal(t, bals, cp.Balances)
+}
+
+func TestCatchpointTampered(t *testing.T) {
+	bals := randomAccounts(20)
+	hdr := randomCatchpointHeader(bals)
+
+	var buf bytes.Buffer
+	digest, err := writeCatchpoint(&buf, hdr, mapCatchpointAccounts(bals))
+	require.NoError(t, err)
+
+	zr, err := gzip.NewReader(&buf)
+	require.NoError(t, err)
+	raw, err := ioutil.ReadAll(zr)
+	require.NoError(t, err)
+
+	for _, pos := range []int{10, len(raw) / 2, len(raw) - 1} {
+		tampered := append([]byte{}, raw...)
+		tampered[pos] ^= 1
+
+		var tbuf bytes.Buffer
+		zw := gzip.NewWriter(&tbuf)
+		_, err = zw.Write(tampered)
+		require.NoError(t, err)
+		require.NoError(t, zw.Close())
+
+		cp, err := ReadCatchpoint(&tbuf)
+		if err == nil {
+			require.NotEqual(t, digest, cp.Digest, "tampering at %d", pos)
+		}
+	}
+}
+
+func TestCatchpointFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "catchpoints")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	bals := randomAccounts(10)
+	hdr := randomCatchpointHeader(bals)
+	for rnd := basics.Round(16); rnd <= 64; rnd += 16 {
+		hdr.Round = rnd
+		_, err = saveCatchpointFile(dir, hdr, mapCatchpointAccounts(bals))
+		require.NoError(t, err)
+		require.NoError(t, pruneCatchpointFiles(dir, 2))
+	}
+
+	for rnd := basics.Round(16); rnd <= 64; rnd += 16 {
+		f, err := os.Open(catchpointFileName(dir, rnd))
+		if rnd < 48 {
+			require.True(t, os.IsNotExist(err))
+			continue
+		}
+		require.NoError(t, err)
+
+		cp, err := ReadCatchpoint(f)
+		f.Close()
+		require.NoError(t, err)
+		require.Equal(t, rnd, cp.Header.Round)
+	}
+}
+
+// commitCatchpointTestBlocks waits for l to write its blocks, and lets
+// its trackers flush them.  A node has MaxBalLookback rounds to compute
+// the digest of a catchpoint before a block commits to it, but the tests
+// add blocks faster than the ledger writes them.
+func commitCatchpointTestBlocks(l *Ledger) {
+	l.WaitForCommit(l.Latest())
+	l.notifyCommit(l.Latest())
+}
+
+// addCatchpointTestBlock generates the next block on l, with a payment
+// between two of the genesis accounts.
+func addCatchpointTestBlock(t *testing.T, l *Ledger, initKeys map[basics.Address]*crypto.SignatureSecrets, from, to basics.Address) bookkeeping.Block {
+	backlogPool := execpool.MakeBacklog(nil, 0, execpool.LowPriority, nil)
+	defer backlogPool.Shutdown()
+
+	commitCatchpointTestBlocks(l)
+	prev, err := l.BlockHdr(l.Latest())
+	require.NoError(t, err)
+
+	hdr := bookkeeping.MakeBlock(prev).BlockHeader
+	eval, err := l.StartEvaluator(hdr, DummyVerifiedTxnCache{}, backlogPool)
+	require.NoError(t, err)
+
+	proto := config.Consensus[prev.CurrentProtocol]
+	tx := transactions.Transaction{
+		Type: protocol.PaymentTx,
+		Header: transactions.Header{
+			Sender:      from,
+			Fee:         basics.MicroAlgos{Raw: proto.MinTxnFee},
+			FirstValid:  hdr.Round,
+			LastValid:   hdr.Round,
+			GenesisID:   hdr.GenesisID,
+			GenesisHash: hdr.GenesisHash,
+		},
+		PaymentTxnFields: transactions.PaymentTxnFields{
+			Receiver: to,
+			Amount:   basics.MicroAlgos{Raw: uint64(hdr.Round) * 1000},
+		},
+	}
+	require.NoError(t, eval.Transaction(sign(initKeys, tx), nil))
+
+	vb, err := eval.GenerateBlock()
+	require.NoError(t, err)
+
+	// check that the block also validates
+	_, err = l.Validate(context.Background(), vb.Block(), DummyVerifiedTxnCache{}, backlogPool)
+	require.NoError(t, err)
+
+	require.NoError(t, l.AddValidatedBlock(*vb, agreement.Certificate{}))
+	return vb.Block()
+}
+
+func TestLedgerInstallCatchpoint(t *testing.T) {
+	initBlocks, initAccounts, initKeys := testGenerateInitState(t, protoCatchpoint)
+	initBlocks = initBlocks[:1]
+
+	var addrs []basics.Address
+	for addr := range initAccounts {
+		if addr != testPoolAddr && addr != testSinkAddr {
+			addrs = append(addrs, addr)
+		}
+	}
+
+	l, err := OpenLedger(logging.Base(), t.Name(), true, initBlocks, initAccounts, crypto.Hash([]byte(t.Name())))
+	require.NoError(t, err)
+	defer l.Close()
+
+	blocks := initBlocks
+	for rnd := 1; rnd <= 40; rnd++ {
+		blk := addCatchpointTestBlock(t, l, initKeys, addrs[rnd%len(addrs)], addrs[(rnd+1)%len(addrs)])
+		blocks = append(blocks, blk)
+
+		if rnd%16 == 4 && rnd > 16 {
+			require.False(t, blk.CatchpointHash.IsZero(), "round %d", rnd)
+		} else {
+			require.True(t, blk.CatchpointHash.IsZero(), "round %d", rnd)
+		}
+	}
+
+	// A block with the wrong catchpoint hash does not validate
+	backlogPool := execpool.MakeBacklog(nil, 0, execpool.LowPriority, nil)
+	defer backlogPool.Shutdown()
+	l2, err := OpenLedger(logging.Base(), t.Name()+"-replay", true, initBlocks, initAccounts, crypto.Hash([]byte(t.Name())))
+	require.NoError(t, err)
+	defer l2.Close()
+	for _, blk := range blocks[1:20] {
+		require.NoError(t, l2.AddBlock(blk, agreement.Certificate{}))
+	}
+	commitCatchpointTestBlocks(l2)
+	_, err = l2.Validate(context.Background(), blocks[20], DummyVerifiedTxnCache{}, backlogPool)
+	require.NoError(t, err)
+	bad := blocks[20]
+	bad.CatchpointHash = crypto.Digest{}
+	_, err = l2.Validate(context.Background(), bad, DummyVerifiedTxnCache{}, backlogPool)
+	require.Error(t, err)
+
+	// Looking up a digest that has not been computed fails right away
+	l2.trackerMu.RLock()
+	_, err = l2.accts.catchpointDigest(32)
+	l2.trackerMu.RUnlock()
+	require.Error(t, err)
+
+	// Build the catchpoint at round 32 the way a relay would
+	cpRound := basics.Round(32)
+	balancesRound := basics.Round(28)
+	hdr := CatchpointHeader{
+		Round:         cpRound,
+		BlockHash:     blocks[cpRound].Hash(),
+		BalancesRound: balancesRound,
+	}
+	bals, err := l.AllBalances(balancesRound)
+	require.NoError(t, err)
+	hdr.Totals, err = l.Totals(balancesRound)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	_, err = writeCatchpoint(&buf, hdr, mapCatchpointAccounts(bals))
+	require.NoError(t, err)
+	cp, err := ReadCatchpoint(&buf)
+	require.NoError(t, err)
+	require.Equal(t, blocks[cpRound+4].CatchpointHash, cp.Digest)
+
+	// Install it on a new ledger, along with the blocks for txTail
+	l3, err := OpenLedger(logging.Base(), t.Name()+"-catchpoint", true, initBlocks, initAccounts, crypto.Hash([]byte(t.Name())))
+	require.NoError(t, err)
+	defer l3.Close()
+
+	first := balancesRound + 1 - basics.Round(config.Consensus[protoCatchpoint].MaxTxnLife)
+	certs := make([]agreement.Certificate, balancesRound-first+1)
+
+	err = l3.InstallCatchpoint(cp, blocks[first:balancesRound], certs[1:])
+	require.Error(t, err)
+
+	wait := l3.Wait(cpRound)
+	err = l3.InstallCatchpoint(cp, blocks[first:balancesRound+1], certs)
+	require.NoError(t, err)
+	require.Equal(t, balancesRound, l3.Latest())
+
+	for _, blk := range blocks[balancesRound+1:] {
+		require.NoError(t, l3.AddBlock(blk, agreement.Certificate{}))
+	}
+	l3.WaitForCommit(l3.Latest())
+	<-wait
+
+	latest := l.Latest()
+	require.Equal(t, latest, l3.Latest())
+	for addr := range initAccounts {
+		expected, err := l.Lookup(latest, addr)
+		require.NoError(t, err)
+		actual, err := l3.Lookup(latest, addr)
+		require.NoError(t, err)
+		require.Equal(t, expected, actual)
+	}
+
+	// The installed ledger can keep validating blocks, including the
+	// catchpoint hash of the next catchpoint, which it computes itself,
+	// and detects duplicates.
+	for rnd := latest + 1; rnd <= 56; rnd++ {
+		blk := addCatchpointTestBlock(t, l, initKeys, addrs[rnd%basics.Round(len(addrs))], addrs[(rnd+1)%basics.Round(len(addrs))])
+		commitCatchpointTestBlocks(l3)
+		_, err = l3.Validate(context.Background(), blk, DummyVerifiedTxnCache{}, backlogPool)
+		require.NoError(t, err)
+		require.NoError(t, l3.AddBlock(blk, agreement.Certificate{}))
+	}
+}
@@ -35,6 +35,8 @@ import (
 type roundCowParent interface {
 	lookup(basics.Address) (basics.AccountData, error)
 	isDup(basics.Round, transactions.Txid) (bool, error)
+	isLeased(basics.Round, transactions.Txlease) bool
+	getAssetCreator(basics.AssetIndex) (basics.Address, bool, error)
 }
 
 type roundCowState struct {
@@ -51,19 +53,39 @@ type stateDelta struct {
 	// new Txids for the txtail
 	txids map[transactions.Txid]struct{}
 
+	// new leases for the txtail, mapped to the round they expire
+	txleases map[transactions.Txlease]basics.Round
+
+	// created or deleted assets
+	assets map[basics.AssetIndex]modifiedAsset
+
 	// new block header; read-only
 	hdr *bookkeeping.BlockHeader
 }
 
+// modifiedAsset represents an asset that was created or deleted,
+// along with the address of its creator.
+type modifiedAsset struct {
+	// created is true if the asset was created, and false if it
+	// was deleted.
+	created bool
+
+	// creator is the address of the account whose AssetParams
+	// hold (or held) this asset.
+	creator basics.Address
+}
+
 func makeRoundCowState(b roundCowParent, hdr bookkeeping.BlockHeader) *roundCowState {
 	return &roundCowState{
 		lookupParent: b,
 		commitParent: nil,
 		proto:        config.Consensus[hdr.CurrentProtocol],
 		mods: stateDelta{
-			accts: make(map[basics.Address]accountDelta),
-			txids: make(map[transactions.Txid]struct{}),
-			hdr:   &hdr,
+			accts:    make(map[basics.Address]accountDelta),
+			txids:    make(map[transactions.Txid]struct{}),
+			txleases: make(map[transactions.Txlease]basics.Round),
+			assets:   make(map[basics.AssetIndex]modifiedAsset),
+			hdr:      &hdr,
 		},
 	}
 }
@@ -90,6 +112,27 @@ func (cb *roundCowState) isDup(firstValid basics.Round, txid transactions.Txid)
 	return cb.lookupParent.isDup(firstValid, txid)
 }
 
+func (cb *roundCowState) isLeased(current basics.Round, txl transactions.Txlease) bool {
+	expires, present := cb.mods.txleases[txl]
+	if present && current <= expires {
+		return true
+	}
+
+	return cb.lookupParent.isLeased(current, txl)
+}
+
+func (cb *roundCowState) getAssetCreator(aidx basics.AssetIndex) (basics.Address, bool, error) {
+	delta, ok := cb.mods.assets[aidx]
+	if ok {
+		if delta.created {
+			return delta.creator, true, nil
+		}
+		return basics.Address{}, false, nil
+	}
+
+	return cb.lookupParent.getAssetCreator(aidx)
+}
+
 func (cb *roundCowState) put(addr basics.Address, old basics.AccountData, new basics.AccountData) {
 	prev, present := cb.mods.accts[addr]
 	if present {
@@ -97,10 +140,28 @@ func (cb *roundCowState) put(addr basics.Address, old basics.AccountData, new ba
 	} else {
 		cb.mods.accts[addr] = accountDelta{old: old, new: new}
 	}
+
+	// Keep track of assets whose params were added to or removed
+	// from this account, so that we can find their creator later.
+	for aidx := range new.AssetParams {
+		_, ok := old.AssetParams[aidx]
+		if !ok {
+			cb.mods.assets[aidx] = modifiedAsset{created: true, creator: addr}
+		}
+	}
+	for aidx := range old.AssetParams {
+		_, ok := new.AssetParams[aidx]
+		if !ok {
+			cb.mods.assets[aidx] = modifiedAsset{created: false, creator: addr}
+		}
+	}
 }
 
-func (cb *roundCowState) addTx(txid transactions.Txid) {
+func (cb *roundCowState) addTx(txn transactions.Transaction, txid transactions.Txid) {
 	cb.mods.txids[txid] = struct{}{}
+	if lease, ok := txn.LeaseHeld(); ok {
+		cb.mods.txleases[lease] = txn.LastValid
+	}
 }
 
 func (cb *roundCowState) child() *roundCowState {
@@ -109,9 +170,11 @@ func (cb *roundCowState) child() *roundCowState {
 		commitParent: cb,
 		proto:        cb.proto,
 		mods: stateDelta{
-			accts: make(map[basics.Address]accountDelta),
-			txids: make(map[transactions.Txid]struct{}),
-			hdr:   cb.mods.hdr,
+			accts:    make(map[basics.Address]accountDelta),
+			txids:    make(map[transactions.Txid]struct{}),
+			txleases: make(map[transactions.Txlease]basics.Round),
+			assets:   make(map[basics.AssetIndex]modifiedAsset),
+			hdr:      cb.mods.hdr,
 		},
 	}
 }
@@ -132,6 +195,14 @@ func (cb *roundCowState) commitToParent() {
 	for txid := range cb.mods.txids {
 		cb.commitParent.mods.txids[txid] = struct{}{}
 	}
+
+	for txl, expires := range cb.mods.txleases {
+		cb.commitParent.mods.txleases[txl] = expires
+	}
+
+	for aidx, delta := range cb.mods.assets {
+		cb.commitParent.mods.assets[aidx] = delta
+	}
 }
 
 func (cb *roundCowState) modifiedAccounts() []basics.Address {
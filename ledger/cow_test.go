@@ -38,6 +38,20 @@ func (ml *mockLedger) isDup(firstValid basics.Round, txn transactions.Txid) (boo
 	return false, nil
 }
 
+func (ml *mockLedger) isLeased(current basics.Round, txl transactions.Txlease) bool {
+	return false
+}
+
+func (ml *mockLedger) getAssetCreator(aidx basics.AssetIndex) (basics.Address, bool, error) {
+	for addr, data := range ml.balanceMap {
+		_, ok := data.AssetParams[aidx]
+		if ok {
+			return addr, true, nil
+		}
+	}
+	return basics.Address{}, false, nil
+}
+
 func checkCow(t *testing.T, cow *roundCowState, accts map[basics.Address]basics.AccountData) {
 	for addr, data := range accts {
 		d, err := cow.lookup(addr)
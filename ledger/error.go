@@ -34,6 +34,19 @@ func (tile TransactionInLedgerError) Error() string {
 	return fmt.Sprintf("transaction already in ledger: %v", tile.Txid)
 }
 
+// LeaseInLedgerError is returned when a transaction cannot be added because
+// another transaction from the same sender holds its lease
+type LeaseInLedgerError struct {
+	Txid   transactions.Txid
+	Sender basics.Address
+	Lease  [32]byte
+}
+
+// Error satisfies builtin interface `error`
+func (lile LeaseInLedgerError) Error() string {
+	return fmt.Sprintf("transaction %v using an overlapping lease (sender, lease): (%v, %x)", lile.Txid, lile.Sender, lile.Lease)
+}
+
 // BlockInLedgerError is returned when a block cannot be added because it has already been done
 type BlockInLedgerError struct {
 	LastRound basics.Round
@@ -64,3 +77,29 @@ type ErrNoEntry struct {
 func (err ErrNoEntry) Error() string {
 	return fmt.Sprintf("ledger does not have entry %d (latest %d, committed %d)", err.Round, err.Latest, err.Committed)
 }
+
+// ErrRoundPruned is used to indicate that a block has been deleted from
+// the ledger because it is older than the rounds the ledger retains.
+type ErrRoundPruned struct {
+	Round    basics.Round
+	Earliest basics.Round
+}
+
+// Error satisfies builtin interface `error`
+func (err ErrRoundPruned) Error() string {
+	return fmt.Sprintf("ledger has pruned round %d (earliest available %d)", err.Round, err.Earliest)
+}
+
+// ErrNoAccountHistory is returned when the account state of a round is
+// no longer available: the round is older than what the accounts
+// tracker keeps in memory, and the ledger either does not record account
+// history or has not yet backfilled it as far back as that round.
+type ErrNoAccountHistory struct {
+	Round    basics.Round
+	Earliest basics.Round
+}
+
+// Error satisfies builtin interface `error`
+func (err ErrNoAccountHistory) Error() string {
+	return fmt.Sprintf("ledger has no account state for round %d (earliest available %d)", err.Round, err.Earliest)
+}
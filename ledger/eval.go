@@ -27,6 +27,7 @@ import (
 	"github.com/algorand/go-algorand/data/bookkeeping"
 	"github.com/algorand/go-algorand/data/committee"
 	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/data/transactions/verify"
 	"github.com/algorand/go-algorand/logging"
 	"github.com/algorand/go-algorand/protocol"
 	"github.com/algorand/go-algorand/util/execpool"
@@ -52,6 +53,9 @@ type roundCowBase struct {
 
 	// The round number of the previous block, for looking up prior state.
 	rnd basics.Round
+
+	// The consensus parameters of the block being evaluated.
+	proto config.ConsensusParams
 }
 
 func (x *roundCowBase) lookup(addr basics.Address) (basics.AccountData, error) {
@@ -62,6 +66,14 @@ func (x *roundCowBase) isDup(firstValid basics.Round, txid transactions.Txid) (b
 	return x.l.isDup(firstValid, x.rnd, txid)
 }
 
+func (x *roundCowBase) isLeased(current basics.Round, txl transactions.Txlease) bool {
+	return x.l.isLeased(current, txl, x.proto)
+}
+
+func (x *roundCowBase) getAssetCreator(aidx basics.AssetIndex) (basics.Address, bool, error) {
+	return x.l.getAssetCreator(x.rnd, aidx)
+}
+
 // wrappers for roundCowState to satisfy the (current) transactions.Balances interface
 func (cs *roundCowState) Get(addr basics.Address) (basics.BalanceRecord, error) {
 	acctdata, err := cs.lookup(addr)
@@ -130,6 +142,17 @@ func (cs *roundCowState) Move(from basics.Address, to basics.Address, amt basics
 	return nil
 }
 
+func (cs *roundCowState) GetAssetCreator(aidx basics.AssetIndex) (basics.Address, error) {
+	creator, ok, err := cs.getAssetCreator(aidx)
+	if err != nil {
+		return basics.Address{}, err
+	}
+	if !ok {
+		return basics.Address{}, fmt.Errorf("asset %d does not exist or has been deleted", aidx)
+	}
+	return creator, nil
+}
+
 func (cs *roundCowState) ConsensusParams() config.ConsensusParams {
 	return cs.proto
 }
@@ -150,7 +173,15 @@ type BlockEvaluator struct {
 	block        bookkeeping.Block
 	totalTxBytes int
 
+	// txnCount is the number of transactions committed to the ledger
+	// before the next transaction in this block, if the protocol
+	// supports TxnCounter.
+	txnCount uint64
+
 	verificationPool execpool.BacklogPool
+
+	// ctx bounds the wait for signatures verified over verificationPool.
+	ctx context.Context
 }
 
 type ledgerForEvaluator interface {
@@ -159,7 +190,10 @@ type ledgerForEvaluator interface {
 	Lookup(basics.Round, basics.Address) (basics.AccountData, error)
 	Totals(basics.Round) (AccountTotals, error)
 	isDup(basics.Round, basics.Round, transactions.Txid) (bool, error)
+	isLeased(basics.Round, transactions.Txlease, config.ConsensusParams) bool
 	lookupWithoutRewards(basics.Round, basics.Address) (basics.AccountData, error)
+	getAssetCreator(basics.Round, basics.AssetIndex) (basics.Address, bool, error)
+	catchpointDigest(basics.Round) (crypto.Digest, error)
 }
 
 // StartEvaluator creates a BlockEvaluator, given a ledger and a block header
@@ -173,7 +207,12 @@ func startEvaluator(l ledgerForEvaluator, hdr bookkeeping.BlockHeader, aux *eval
 	if !ok {
 		return nil, ProtocolError(hdr.CurrentProtocol)
 	}
+	return startEvaluatorWithParams(l, hdr, proto, aux, validate, generate, txcache, executionPool)
+}
 
+// startEvaluatorWithParams is like startEvaluator, but evaluates the block
+// under proto instead of the parameters of its protocol version.
+func startEvaluatorWithParams(l ledgerForEvaluator, hdr bookkeeping.BlockHeader, proto config.ConsensusParams, aux *evalAux, validate bool, generate bool, txcache VerifiedTxnCache, executionPool execpool.BacklogPool) (*BlockEvaluator, error) {
 	if aux == nil {
 		aux = &evalAux{}
 	}
@@ -184,7 +223,8 @@ func startEvaluator(l ledgerForEvaluator, hdr bookkeeping.BlockHeader, aux *eval
 		// the block at this round below, so underflow will be caught.
 		// If we are not validating, we must have previously checked
 		// an agreement.Certificate attesting that hdr is valid.
-		rnd: hdr.Round - 1,
+		rnd:   hdr.Round - 1,
+		proto: proto,
 	}
 
 	eval := &BlockEvaluator{
@@ -196,6 +236,7 @@ func startEvaluator(l ledgerForEvaluator, hdr bookkeeping.BlockHeader, aux *eval
 		proto:            proto,
 		genesisHash:      l.GenesisHash(),
 		verificationPool: executionPool,
+		ctx:              context.Background(),
 	}
 
 	if hdr.Round > 0 {
@@ -206,6 +247,10 @@ func startEvaluator(l ledgerForEvaluator, hdr bookkeeping.BlockHeader, aux *eval
 		}
 	}
 
+	if proto.TxnCounter {
+		eval.txnCount = eval.prevHeader.TxnCounter
+	}
+
 	prevTotals, err := l.Totals(eval.prevHeader.Round)
 	if err != nil {
 		return nil, err
@@ -217,11 +262,26 @@ func startEvaluator(l ledgerForEvaluator, hdr bookkeeping.BlockHeader, aux *eval
 		return nil, err
 	}
 
+	// The block commits to the catchpoint MaxBalLookback rounds before
+	// it, if there is one.  Its digest is computed by the accounts
+	// tracker as it flushes; this only looks it up, without waiting.
+	var expectedCatchpoint crypto.Digest
+	if validate || generate {
+		cpRound, ok := catchpointCommitRound(hdr.Round, config.Consensus[hdr.CurrentProtocol])
+		if ok {
+			expectedCatchpoint, err = l.catchpointDigest(cpRound)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	if generate {
 		if eval.proto.SupportGenesisHash {
 			eval.block.BlockHeader.GenesisHash = eval.genesisHash
 		}
 		eval.block.BlockHeader.RewardsState = eval.prevHeader.NextRewardsState(hdr.Round, proto, incentivePoolData.MicroAlgos, prevTotals.RewardUnits())
+		eval.block.BlockHeader.CatchpointHash = expectedCatchpoint
 	}
 	// set the eval state with the current header
 	eval.state = makeRoundCowState(base, eval.block.BlockHeader)
@@ -242,6 +302,10 @@ func startEvaluator(l ledgerForEvaluator, hdr bookkeeping.BlockHeader, aux *eval
 		if eval.proto.SupportGenesisHash && eval.block.BlockHeader.GenesisHash != eval.genesisHash {
 			return nil, fmt.Errorf("wrong genesis hash: %s != %s", eval.block.BlockHeader.GenesisHash, eval.genesisHash)
 		}
+
+		if eval.block.BlockHeader.CatchpointHash != expectedCatchpoint {
+			return nil, fmt.Errorf("wrong catchpoint hash: %v != %v", eval.block.BlockHeader.CatchpointHash, expectedCatchpoint)
+		}
 	}
 
 	// Withdraw rewards from the incentive pool
@@ -274,14 +338,128 @@ func (eval *BlockEvaluator) Round() basics.Round {
 	return eval.block.Round()
 }
 
+// Lookup returns the balance record of addr, as of the transactions added to
+// this block evaluation so far, with rewards applied.
+func (eval *BlockEvaluator) Lookup(addr basics.Address) (basics.BalanceRecord, error) {
+	return eval.state.Get(addr)
+}
+
+// Payset returns the transactions added to this block evaluation so far,
+// along with their ApplyData.
+func (eval *BlockEvaluator) Payset() transactions.Payset {
+	return eval.block.Payset
+}
+
 // Transaction tentatively adds a new transaction as part of this block evaluation.
 // If the transaction cannot be added to the block without violating some constraints,
 // an error is returned and the block evaluator state is unchanged.
 func (eval *BlockEvaluator) Transaction(txn transactions.SignedTxn, ad *transactions.ApplyData) error {
-	var err error
-	var thisTxBytes int
+	txad := transactions.SignedTxnWithAD{
+		SignedTxn: txn,
+	}
+
+	if ad != nil {
+		txad.ApplyData = *ad
+	} else if eval.validate && !eval.generate {
+		return fmt.Errorf("transaction %v: no applyData for validation", txn.ID())
+	}
+
+	return eval.TransactionGroup([]transactions.SignedTxnWithAD{txad})
+}
+
+// TransactionGroup tentatively adds a new transaction group as part of this block evaluation.
+// If the transaction group cannot be added to the block without violating some constraints,
+// an error is returned and the block evaluator state is unchanged.
+func (eval *BlockEvaluator) TransactionGroup(txgroup []transactions.SignedTxnWithAD) error {
+	txnCount := eval.txnCount
+	err := eval.transactionGroup(txgroup)
+	if err != nil {
+		// Roll back the transaction counter along with the rest of
+		// the group's state changes.
+		eval.txnCount = txnCount
+	}
+	return err
+}
+
+func (eval *BlockEvaluator) transactionGroup(txgroup []transactions.SignedTxnWithAD) error {
+	// Nothing to do if there are no transactions.
+	if len(txgroup) == 0 {
+		return nil
+	}
+
+	if len(txgroup) > eval.proto.MaxTxGroupSize && len(txgroup) > 1 {
+		return fmt.Errorf("group size %d exceeds maximum %d", len(txgroup), eval.proto.MaxTxGroupSize)
+	}
+
+	var txibs []transactions.SignedTxnInBlock
+	var group transactions.TxGroup
+	var groupTxBytes int
+
 	cow := eval.state.child()
 
+	// Logic sigs may inspect the other transactions in the group.
+	signedTxGroup := make([]transactions.SignedTxn, len(txgroup))
+	for gi, txad := range txgroup {
+		signedTxGroup[gi] = txad.SignedTxn
+	}
+
+	for gi, txad := range txgroup {
+		var txib transactions.SignedTxnInBlock
+
+		err := eval.transaction(txad.SignedTxn, txad.ApplyData, cow, &txib, signedTxGroup, gi)
+		if err != nil {
+			return err
+		}
+
+		txibs = append(txibs, txib)
+
+		// Check if the transaction group fits in the block, now that we can encode it.
+		if eval.validate {
+			groupTxBytes += len(protocol.Encode(txib))
+			if eval.totalTxBytes+groupTxBytes > eval.proto.MaxTxnBytesPerBlock {
+				return ErrNoSpace
+			}
+		}
+
+		// Make sure all transactions in the group have the same group value
+		if txad.SignedTxn.Txn.Group != txgroup[0].SignedTxn.Txn.Group {
+			return fmt.Errorf("transactionGroup: inconsistent group values: %v != %v",
+				txad.SignedTxn.Txn.Group, txgroup[0].SignedTxn.Txn.Group)
+		}
+
+		if !txad.SignedTxn.Txn.Group.IsZero() {
+			txWithoutGroup := txad.SignedTxn.Txn
+			txWithoutGroup.Group = crypto.Digest{}
+			txWithoutGroup.ResetCaches()
+
+			group.TxGroupHashes = append(group.TxGroupHashes, crypto.Digest(txWithoutGroup.ID()))
+		} else if len(txgroup) > 1 {
+			return fmt.Errorf("transactionGroup: [%d] had zero Group but was submitted in a group of %d", gi, len(txgroup))
+		}
+	}
+
+	// If we had a non-zero Group value, check that all group members are present.
+	if group.TxGroupHashes != nil {
+		if txgroup[0].SignedTxn.Txn.Group != crypto.HashObj(group) {
+			return fmt.Errorf("transactionGroup: incomplete group: %v != %v (%v)",
+				txgroup[0].SignedTxn.Txn.Group, crypto.HashObj(group), group)
+		}
+	}
+
+	eval.block.Payset = append(eval.block.Payset, txibs...)
+	eval.totalTxBytes += groupTxBytes
+	cow.commitToParent()
+
+	return nil
+}
+
+// transaction tentatively executes a new transaction as part of this block evaluation.
+// If the transaction cannot be added to the block without violating some constraints,
+// an error is returned and the block evaluator state is unchanged.
+func (eval *BlockEvaluator) transaction(txn transactions.SignedTxn, ad transactions.ApplyData, parent *roundCowState, txib *transactions.SignedTxnInBlock, txgroup []transactions.SignedTxn, groupIndex int) error {
+	var err error
+	cow := parent.child()
+
 	spec := transactions.SpecialAddresses{
 		FeeSink:     eval.block.BlockHeader.FeeSink,
 		RewardsPool: eval.block.BlockHeader.RewardsPool,
@@ -309,17 +487,43 @@ func (eval *BlockEvaluator) Transaction(txn transactions.SignedTxn, ad *transact
 			return fmt.Errorf("transaction %v: malformed: %v", txn.ID(), err)
 		}
 
+		// Lease held by another transaction?
+		if lease, ok := txn.Txn.LeaseHeld(); ok {
+			if cow.isLeased(eval.block.Round(), lease) {
+				return LeaseInLedgerError{Txid: txn.ID(), Sender: txn.Txn.Sender, Lease: txn.Txn.Lease}
+			}
+		}
+
 		// Properly signed?
 		if eval.txcache == nil || !eval.txcache.Verified(txn) {
-			err = txn.PoolVerify(spec, eval.proto, eval.verificationPool)
+			ctx := verify.Context{
+				Spec:       spec,
+				Proto:      eval.proto,
+				Group:      txgroup,
+				GroupIndex: groupIndex,
+			}
+			err = verify.TxnPool(eval.ctx, &txn, ctx, eval.verificationPool)
 			if err != nil {
 				return fmt.Errorf("transaction %v: failed to verify: %v", txn.ID(), err)
 			}
 		}
+
+		// Authorized by the sender's current spending key?
+		record, err := cow.lookup(txn.Txn.Sender)
+		if err != nil {
+			return err
+		}
+		authorizer := record.AuthAddr
+		if authorizer == (basics.Address{}) {
+			authorizer = txn.Txn.Sender
+		}
+		if txn.Authorizer() != authorizer {
+			return fmt.Errorf("transaction %v: should have been authorized by %v but was actually authorized by %v", txn.ID(), authorizer, txn.Authorizer())
+		}
 	}
 
 	// Apply the transaction, updating the cow balances
-	applyData, err := txn.Txn.Apply(cow, spec)
+	applyData, err := txn.Txn.Apply(cow, spec, eval.txnCount)
 	if err != nil {
 		return fmt.Errorf("transaction %v: %v", txn.ID(), err)
 	}
@@ -327,31 +531,22 @@ func (eval *BlockEvaluator) Transaction(txn transactions.SignedTxn, ad *transact
 	// Validate applyData if we are validating an existing block.
 	// If we are validating and generating, we have no ApplyData yet.
 	if eval.validate && !eval.generate {
-		if ad == nil {
-			return fmt.Errorf("transaction %v: no applyData for validation", txn.ID())
-		}
 		if eval.proto.ApplyData {
-			if *ad != applyData {
-				return fmt.Errorf("transaction %v: applyData mismatch: %v != %v", txn.ID(), *ad, applyData)
+			if ad != applyData {
+				return fmt.Errorf("transaction %v: applyData mismatch: %v != %v", txn.ID(), ad, applyData)
 			}
 		} else {
-			if *ad != (transactions.ApplyData{}) {
+			if ad != (transactions.ApplyData{}) {
 				return fmt.Errorf("transaction %v: applyData not supported", txn.ID())
 			}
 		}
 	}
 
-	// Check if the transaction fits in the block, now that we can encode it.
-	txib, err := eval.block.EncodeSignedTxn(txn, applyData)
+	// Encode the transaction for inclusion in the block.
+	*txib, err = eval.block.EncodeSignedTxn(txn, applyData)
 	if err != nil {
 		return err
 	}
-	if eval.validate {
-		thisTxBytes = len(protocol.Encode(txib))
-		if eval.totalTxBytes+thisTxBytes > eval.proto.MaxTxnBytesPerBlock {
-			return ErrNoSpace
-		}
-	}
 
 	// Check if any affected accounts dipped below MinBalance (unless they are
 	// completely zero, which means the account will be deleted.)
@@ -365,7 +560,7 @@ func (eval *BlockEvaluator) Transaction(txn transactions.SignedTxn, ad *transact
 		// It's always OK to have the account move to an empty state,
 		// because the accounts DB can delete it.  Otherwise, we will
 		// enforce MinBalance.
-		if data == (basics.AccountData{}) {
+		if data.IsZero() {
 			continue
 		}
 
@@ -378,17 +573,19 @@ func (eval *BlockEvaluator) Transaction(txn transactions.SignedTxn, ad *transact
 		}
 
 		dataNew := data.WithUpdatedRewards(eval.proto, rewardlvl)
-		if dataNew.MicroAlgos.Raw < eval.proto.MinBalance {
-			return fmt.Errorf("transaction %v: account %v balance %d below min %d",
-				txn.ID(), addr, dataNew.MicroAlgos.Raw, eval.proto.MinBalance)
+		minBalance := dataNew.MinBalance(eval.proto)
+		if dataNew.MicroAlgos.Raw < minBalance.Raw {
+			return fmt.Errorf("transaction %v: account %v balance %d below min %d (%d assets)",
+				txn.ID(), addr, dataNew.MicroAlgos.Raw, minBalance.Raw, len(dataNew.Assets))
 		}
 	}
 
 	// Remember this TXID (to detect duplicates)
-	cow.addTx(txn.ID())
+	cow.addTx(txn.Txn, txn.ID())
 
-	eval.block.Payset = append(eval.block.Payset, txib)
-	eval.totalTxBytes += thisTxBytes
+	if eval.proto.TxnCounter {
+		eval.txnCount++
+	}
 	cow.commitToParent()
 	return nil
 }
@@ -399,6 +596,11 @@ func (eval *BlockEvaluator) endOfBlock() error {
 
 	if eval.generate {
 		eval.block.TxnRoot = eval.block.Payset.Commit(eval.proto.PaysetCommitFlat)
+		if eval.proto.TxnCounter {
+			eval.block.TxnCounter = eval.txnCount
+		} else {
+			eval.block.TxnCounter = 0
+		}
 	}
 
 	cow.commitToParent()
@@ -413,6 +615,14 @@ func (eval *BlockEvaluator) finalValidation() error {
 		if txnRoot != eval.block.TxnRoot {
 			return fmt.Errorf("txn root wrong: %v != %v", txnRoot, eval.block.TxnRoot)
 		}
+
+		var expectedTxnCount uint64
+		if eval.proto.TxnCounter {
+			expectedTxnCount = eval.txnCount
+		}
+		if eval.block.TxnCounter != expectedTxnCount {
+			return fmt.Errorf("txn count wrong: %d != %d", eval.block.TxnCounter, expectedTxnCount)
+		}
 	}
 
 	return nil
@@ -449,6 +659,7 @@ func (l *Ledger) eval(ctx context.Context, blk bookkeeping.Block, aux *evalAux,
 	if err != nil {
 		return stateDelta{}, evalAux{}, err
 	}
+	eval.ctx = ctx
 
 	// TODO: batch tx sig verification: ingest blk.Payset and output a list of ValidatedTx
 	// Next, transactions
@@ -457,14 +668,14 @@ func (l *Ledger) eval(ctx context.Context, blk bookkeeping.Block, aux *evalAux,
 		return stateDelta{}, evalAux{}, err
 	}
 
-	for _, txn := range payset {
+	for _, txgroup := range groupPayset(payset) {
 		select {
 		case <-ctx.Done():
 			return stateDelta{}, evalAux{}, ctx.Err()
 		default:
 		}
 
-		err = eval.Transaction(txn.SignedTxn, &txn.ApplyData)
+		err = eval.TransactionGroup(txgroup)
 		if err != nil {
 			return stateDelta{}, evalAux{}, err
 		}
@@ -487,6 +698,29 @@ func (l *Ledger) eval(ctx context.Context, blk bookkeeping.Block, aux *evalAux,
 	return eval.state.mods, *eval.aux, nil
 }
 
+// groupPayset splits a block's payset into transaction groups.  Consecutive
+// transactions with the same non-zero Group value form a single group;
+// every other transaction is a group of its own.
+func groupPayset(payset []transactions.SignedTxnWithAD) [][]transactions.SignedTxnWithAD {
+	var res [][]transactions.SignedTxnWithAD
+	var txgroup []transactions.SignedTxnWithAD
+
+	for _, txn := range payset {
+		if len(txgroup) > 0 && (txn.SignedTxn.Txn.Group.IsZero() || txn.SignedTxn.Txn.Group != txgroup[0].SignedTxn.Txn.Group) {
+			res = append(res, txgroup)
+			txgroup = nil
+		}
+
+		txgroup = append(txgroup, txn)
+	}
+
+	if len(txgroup) > 0 {
+		res = append(res, txgroup)
+	}
+
+	return res
+}
+
 // Validate uses the ledger to validate block blk as a candidate next block.
 // It returns an error if blk is not the expected next block, or if blk is
 // not a valid block (e.g., it has duplicate transactions, overspends some
@@ -17,6 +17,7 @@
 package ledger
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -126,3 +127,328 @@ func TestBlockEvaluator(t *testing.T) {
 	require.Equal(t, bal1new.MicroAlgos.Raw, bal1.MicroAlgos.Raw+100)
 	require.Equal(t, bal2new.MicroAlgos.Raw, bal2.MicroAlgos.Raw-minFee.Raw)
 }
+
+func TestBlockEvaluatorAssets(t *testing.T) {
+	blks, accts, addrs, keys := genesis(10)
+	blks[0].CurrentProtocol = protocol.ConsensusFuture
+
+	backlogPool := execpool.MakeBacklog(nil, 0, execpool.LowPriority, nil)
+	defer backlogPool.Shutdown()
+
+	dbName := fmt.Sprintf("%s.%d", t.Name(), crypto.RandUint64())
+	l, err := OpenLedger(logging.Base(), dbName, true, blks, accts, blks[0].BlockHeader.GenesisHash)
+	require.NoError(t, err)
+
+	newBlock := bookkeeping.MakeBlock(blks[len(blks)-1].BlockHeader)
+	eval, err := l.StartEvaluator(newBlock.BlockHeader, nil, backlogPool)
+	require.NoError(t, err)
+
+	header := transactions.Header{
+		Sender:      addrs[0],
+		Fee:         minFee,
+		FirstValid:  newBlock.Round(),
+		LastValid:   newBlock.Round(),
+		GenesisHash: blks[0].BlockHeader.GenesisHash,
+	}
+
+	create := transactions.Transaction{
+		Type:   protocol.AssetConfigTx,
+		Header: header,
+		AssetConfigTxnFields: transactions.AssetConfigTxnFields{
+			AssetParams: basics.AssetParams{
+				Total:     1000,
+				UnitName:  "tok",
+				AssetName: "token",
+				Manager:   addrs[0],
+				Freeze:    addrs[0],
+				Clawback:  addrs[0],
+			},
+		},
+	}
+	err = eval.Transaction(create.Sign(keys[0]), &transactions.ApplyData{})
+	require.NoError(t, err)
+
+	// The asset index is derived from the transaction counter
+	aidx := basics.AssetIndex(1)
+
+	// Sending to an account that has not opted in should fail
+	header.Note = []byte{1}
+	send := transactions.Transaction{
+		Type:   protocol.AssetTransferTx,
+		Header: header,
+		AssetTransferTxnFields: transactions.AssetTransferTxnFields{
+			XferAsset:     aidx,
+			AssetAmount:   100,
+			AssetReceiver: addrs[1],
+		},
+	}
+	err = eval.Transaction(send.Sign(keys[0]), &transactions.ApplyData{})
+	require.Error(t, err)
+
+	optin := transactions.Transaction{
+		Type: protocol.AssetTransferTx,
+		Header: transactions.Header{
+			Sender:      addrs[1],
+			Fee:         minFee,
+			FirstValid:  newBlock.Round(),
+			LastValid:   newBlock.Round(),
+			GenesisHash: blks[0].BlockHeader.GenesisHash,
+		},
+		AssetTransferTxnFields: transactions.AssetTransferTxnFields{
+			XferAsset:     aidx,
+			AssetReceiver: addrs[1],
+		},
+	}
+	err = eval.Transaction(optin.Sign(keys[1]), &transactions.ApplyData{})
+	require.NoError(t, err)
+
+	err = eval.Transaction(send.Sign(keys[0]), &transactions.ApplyData{})
+	require.NoError(t, err)
+
+	header.Note = []byte{2}
+	freeze := transactions.Transaction{
+		Type:   protocol.AssetFreezeTx,
+		Header: header,
+		AssetFreezeTxnFields: transactions.AssetFreezeTxnFields{
+			FreezeAccount: addrs[1],
+			FreezeAsset:   aidx,
+			AssetFrozen:   true,
+		},
+	}
+	err = eval.Transaction(freeze.Sign(keys[0]), &transactions.ApplyData{})
+	require.NoError(t, err)
+
+	// A frozen holding cannot be spent
+	back := optin
+	back.Note = []byte{3}
+	back.AssetAmount = 10
+	back.AssetReceiver = addrs[0]
+	err = eval.Transaction(back.Sign(keys[1]), &transactions.ApplyData{})
+	require.Error(t, err)
+
+	validatedBlock, err := eval.GenerateBlock()
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), validatedBlock.blk.TxnCounter)
+
+	err = l.AddValidatedBlock(*validatedBlock, agreement.Certificate{})
+	require.NoError(t, err)
+
+	creator, err := l.GetAssetCreator(newBlock.Round(), aidx)
+	require.NoError(t, err)
+	require.Equal(t, addrs[0], creator)
+
+	_, err = l.GetAssetCreator(newBlock.Round(), aidx+1)
+	require.Error(t, err)
+
+	bal0, err := l.Lookup(newBlock.Round(), addrs[0])
+	require.NoError(t, err)
+	require.Equal(t, uint64(900), bal0.Assets[aidx].Amount)
+	require.Equal(t, "token", bal0.AssetParams[aidx].AssetName)
+
+	bal1, err := l.Lookup(newBlock.Round(), addrs[1])
+	require.NoError(t, err)
+	require.Equal(t, basics.AssetHolding{Amount: 100, Frozen: true}, bal1.Assets[aidx])
+}
+
+func TestBlockEvaluatorTxGroup(t *testing.T) {
+	blks, accts, addrs, keys := genesis(10)
+	blks[0].CurrentProtocol = protocol.ConsensusFuture
+
+	backlogPool := execpool.MakeBacklog(nil, 0, execpool.LowPriority, nil)
+	defer backlogPool.Shutdown()
+
+	dbName := fmt.Sprintf("%s.%d", t.Name(), crypto.RandUint64())
+	l, err := OpenLedger(logging.Base(), dbName, true, blks, accts, blks[0].BlockHeader.GenesisHash)
+	require.NoError(t, err)
+
+	newBlock := bookkeeping.MakeBlock(blks[len(blks)-1].BlockHeader)
+	eval, err := l.StartEvaluator(newBlock.BlockHeader, nil, backlogPool)
+	require.NoError(t, err)
+
+	txns := make([]transactions.Transaction, 2)
+	var group transactions.TxGroup
+	for i := range txns {
+		txns[i] = transactions.Transaction{
+			Type: protocol.PaymentTx,
+			Header: transactions.Header{
+				Sender:      addrs[i],
+				Fee:         minFee,
+				FirstValid:  newBlock.Round(),
+				LastValid:   newBlock.Round(),
+				GenesisHash: blks[0].BlockHeader.GenesisHash,
+			},
+			PaymentTxnFields: transactions.PaymentTxnFields{
+				Receiver: addrs[i+1],
+				Amount:   basics.MicroAlgos{Raw: 100},
+			},
+		}
+		group.TxGroupHashes = append(group.TxGroupHashes, crypto.Digest(txns[i].ID()))
+	}
+
+	txgroup := make([]transactions.SignedTxnWithAD, len(txns))
+	for i := range txns {
+		txns[i].Group = crypto.HashObj(group)
+		txgroup[i].SignedTxn = txns[i].Sign(keys[i])
+	}
+
+	// An incomplete group is rejected, and does not change the block
+	err = eval.TransactionGroup(txgroup[:1])
+	require.Error(t, err)
+
+	// The members of a group cannot be evaluated on their own
+	err = eval.Transaction(txgroup[0].SignedTxn, &transactions.ApplyData{})
+	require.Error(t, err)
+
+	err = eval.TransactionGroup(txgroup)
+	require.NoError(t, err)
+
+	validatedBlock, err := eval.GenerateBlock()
+	require.NoError(t, err)
+	require.Len(t, validatedBlock.blk.Payset, 2)
+
+	// The block with the complete group validates
+	_, err = l.Validate(context.Background(), validatedBlock.blk, nil, backlogPool)
+	require.NoError(t, err)
+}
+
+func TestBlockEvaluatorLease(t *testing.T) {
+	blks, accts, addrs, keys := genesis(10)
+	blks[0].CurrentProtocol = protocol.ConsensusFuture
+
+	backlogPool := execpool.MakeBacklog(nil, 0, execpool.LowPriority, nil)
+	defer backlogPool.Shutdown()
+
+	dbName := fmt.Sprintf("%s.%d", t.Name(), crypto.RandUint64())
+	l, err := OpenLedger(logging.Base(), dbName, true, blks, accts, blks[0].BlockHeader.GenesisHash)
+	require.NoError(t, err)
+
+	var lease [32]byte
+	crypto.RandBytes(lease[:])
+
+	makeTx := func(sender int, first, last basics.Round) transactions.SignedTxn {
+		txn := transactions.Transaction{
+			Type: protocol.PaymentTx,
+			Header: transactions.Header{
+				Sender:      addrs[sender],
+				Fee:         minFee,
+				FirstValid:  first,
+				LastValid:   last,
+				GenesisHash: blks[0].BlockHeader.GenesisHash,
+				Lease:       lease,
+			},
+			PaymentTxnFields: transactions.PaymentTxnFields{
+				Receiver: addrs[sender+1],
+				Amount:   basics.MicroAlgos{Raw: 100},
+			},
+		}
+		return txn.Sign(keys[sender])
+	}
+
+	// The first transaction acquires the lease until round 3; another
+	// transaction with the same lease cannot join it in the same block,
+	// but the same lease from a different sender is fine.
+	prev := blks[len(blks)-1].BlockHeader
+	newBlock := bookkeeping.MakeBlock(prev)
+	eval, err := l.StartEvaluator(newBlock.BlockHeader, nil, backlogPool)
+	require.NoError(t, err)
+
+	require.NoError(t, eval.Transaction(makeTx(0, 1, 3), &transactions.ApplyData{}))
+	err = eval.Transaction(makeTx(0, 1, 4), &transactions.ApplyData{})
+	require.IsType(t, LeaseInLedgerError{}, err)
+	require.NoError(t, eval.Transaction(makeTx(1, 1, 4), &transactions.ApplyData{}))
+
+	validatedBlock, err := eval.GenerateBlock()
+	require.NoError(t, err)
+	require.NoError(t, l.AddValidatedBlock(*validatedBlock, agreement.Certificate{}))
+	prev = validatedBlock.blk.BlockHeader
+
+	// The lease stays active in later blocks until it expires.
+	later := makeTx(0, 2, 5)
+	for prev.Round < 3 {
+		leased, err := l.Leased(later)
+		require.NoError(t, err)
+		require.True(t, leased)
+
+		newBlock = bookkeeping.MakeBlock(prev)
+		eval, err = l.StartEvaluator(newBlock.BlockHeader, nil, backlogPool)
+		require.NoError(t, err)
+		err = eval.Transaction(later, &transactions.ApplyData{})
+		require.IsType(t, LeaseInLedgerError{}, err)
+
+		validatedBlock, err = eval.GenerateBlock()
+		require.NoError(t, err)
+		require.NoError(t, l.AddValidatedBlock(*validatedBlock, agreement.Certificate{}))
+		prev = validatedBlock.blk.BlockHeader
+	}
+
+	leased, err := l.Leased(later)
+	require.NoError(t, err)
+	require.False(t, leased)
+	newBlock = bookkeeping.MakeBlock(prev)
+	eval, err = l.StartEvaluator(newBlock.BlockHeader, nil, backlogPool)
+	require.NoError(t, err)
+	require.NoError(t, eval.Transaction(later, &transactions.ApplyData{}))
+}
+
+func TestBlockEvaluatorRekey(t *testing.T) {
+	blks, accts, addrs, keys := genesis(10)
+	blks[0].CurrentProtocol = protocol.ConsensusFuture
+
+	backlogPool := execpool.MakeBacklog(nil, 0, execpool.LowPriority, nil)
+	defer backlogPool.Shutdown()
+
+	dbName := fmt.Sprintf("%s.%d", t.Name(), crypto.RandUint64())
+	l, err := OpenLedger(logging.Base(), dbName, true, blks, accts, blks[0].BlockHeader.GenesisHash)
+	require.NoError(t, err)
+
+	newBlock := bookkeeping.MakeBlock(blks[len(blks)-1].BlockHeader)
+	eval, err := l.StartEvaluator(newBlock.BlockHeader, nil, backlogPool)
+	require.NoError(t, err)
+
+	makeTx := func(note byte, rekeyTo basics.Address) transactions.Transaction {
+		return transactions.Transaction{
+			Type: protocol.PaymentTx,
+			Header: transactions.Header{
+				Sender:      addrs[0],
+				Fee:         minFee,
+				FirstValid:  newBlock.Round(),
+				LastValid:   newBlock.Round(),
+				GenesisHash: blks[0].BlockHeader.GenesisHash,
+				Note:        []byte{note},
+				RekeyTo:     rekeyTo,
+			},
+			PaymentTxnFields: transactions.PaymentTxnFields{
+				Receiver: addrs[1],
+				Amount:   basics.MicroAlgos{Raw: 100},
+			},
+		}
+	}
+
+	// Rekey addrs[0] to addrs[1]; the rekey itself is signed by the old key
+	require.NoError(t, eval.Transaction(makeTx(0, addrs[1]).Sign(keys[0]), &transactions.ApplyData{}))
+
+	// The old key no longer authorizes transactions from addrs[0]
+	err = eval.Transaction(makeTx(1, basics.Address{}).Sign(keys[0]), &transactions.ApplyData{})
+	require.Error(t, err)
+
+	// The new key does, but only when it is named as the authorizer
+	stxn := makeTx(2, basics.Address{}).Sign(keys[1])
+	err = eval.Transaction(stxn, &transactions.ApplyData{})
+	require.Error(t, err)
+	stxn.AuthAddr = addrs[1]
+	require.NoError(t, eval.Transaction(stxn, &transactions.ApplyData{}))
+
+	// Rekeying back to the account's own address restores its key
+	stxn = makeTx(3, addrs[0]).Sign(keys[1])
+	stxn.AuthAddr = addrs[1]
+	require.NoError(t, eval.Transaction(stxn, &transactions.ApplyData{}))
+	require.NoError(t, eval.Transaction(makeTx(4, basics.Address{}).Sign(keys[0]), &transactions.ApplyData{}))
+
+	validatedBlock, err := eval.GenerateBlock()
+	require.NoError(t, err)
+	require.NoError(t, l.AddValidatedBlock(*validatedBlock, agreement.Certificate{}))
+
+	data, err := l.Lookup(newBlock.Round(), addrs[0])
+	require.NoError(t, err)
+	require.Equal(t, basics.Address{}, data.AuthAddr)
+}
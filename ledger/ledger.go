@@ -52,6 +52,11 @@ type Ledger struct {
 	// The default is archival mode; it can be changed by SetArchival().
 	archival bool
 
+	// blockRetention is the number of most recent rounds of blocks that
+	// a non-archival ledger keeps, on top of the blocks that the
+	// trackers need.  It can be changed by SetBlockRetention().
+	blockRetention basics.Round
+
 	// genesisHash stores the genesis hash for this ledger.
 	genesisHash crypto.Digest
 
@@ -86,9 +91,6 @@ func OpenLedger(log logging.Logger, dbPathPrefix string, dbMem bool, initBlocks
 	defer func() {
 		if err != nil {
 			l.Close()
-			if l.blockQ != nil {
-				l.blockQ.close()
-			}
 		}
 	}()
 
@@ -187,11 +189,16 @@ func OpenLedger(log logging.Logger, dbPathPrefix string, dbMem bool, initBlocks
 }
 
 // Close reclaims resources used by the ledger (namely, the database connection
-// and goroutines used by trackers).
+// and goroutines used by the block queue and trackers).
 func (l *Ledger) Close() {
+	// The block queue flushes to the block database, and the trackers
+	// may use the databases in the background, so stop them first.
+	if l.blockQ != nil {
+		l.blockQ.close()
+	}
+	l.trackers.close()
 	l.trackerDBs.close()
 	l.blockDBs.close()
-	l.trackers.close()
 }
 
 // SetArchival sets whether the ledger should operate in archival mode or not.
@@ -199,6 +206,28 @@ func (l *Ledger) SetArchival(a bool) {
 	l.archival = a
 }
 
+// SetBlockRetention sets the number of most recent rounds of blocks that
+// a non-archival ledger keeps.  Older blocks are pruned in the background,
+// except for those that the ledger itself still needs.
+func (l *Ledger) SetBlockRetention(rounds uint64) {
+	l.trackerMu.Lock()
+	defer l.trackerMu.Unlock()
+	l.blockRetention = basics.Round(rounds)
+}
+
+// SetAccountHistory sets whether the ledger records the account state of
+// every round, so that Lookup can reconstruct the state of an account at
+// any round, rather than only within MaxBalLookback of the latest round.
+// The history starts when the ledger next flushes account state to disk,
+// and the rounds before it are then backfilled in the background by
+// replaying the stored blocks from genesis, so an archival ledger is
+// required.  Disabling the history deletes it.
+func (l *Ledger) SetAccountHistory(enabled bool) {
+	l.trackerMu.Lock()
+	defer l.trackerMu.Unlock()
+	l.accts.recordHistory = enabled
+}
+
 // RegisterBlockListeners registers listeners that will be called when a
 // new block is added to the ledger.
 func (l *Ledger) RegisterBlockListeners(listeners []BlockListener) {
@@ -216,6 +245,11 @@ func (l *Ledger) notifyCommit(r basics.Round) basics.Round {
 	if l.archival {
 		// Do not forget any blocks.
 		minToSave = 0
+	} else if l.blockRetention > 0 {
+		retain := (r + 1).SubSaturate(l.blockRetention)
+		if retain < minToSave {
+			minToSave = retain
+		}
 	}
 
 	return minToSave
@@ -223,7 +257,9 @@ func (l *Ledger) notifyCommit(r basics.Round) basics.Round {
 
 // Lookup uses the accounts tracker to return the account state for a
 // given account in a particular round.  The account values reflect
-// the changes of all blocks up to and including rnd.
+// the changes of all blocks up to and including rnd.  Rounds older than
+// MaxBalLookback from the latest round are only available if the ledger
+// records account history; otherwise Lookup returns ErrNoAccountHistory.
 func (l *Ledger) Lookup(rnd basics.Round, addr basics.Address) (basics.AccountData, error) {
 	l.trackerMu.RLock()
 	defer l.trackerMu.RUnlock()
@@ -261,6 +297,29 @@ func (l *Ledger) lookupWithoutRewards(rnd basics.Round, addr basics.Address) (ba
 	return l.accts.lookup(rnd, addr, false)
 }
 
+// getAssetCreator returns the address of the account whose balance record
+// contains the params for the given asset, as of round rnd.  The second
+// return value is false if the asset does not exist at that round.
+func (l *Ledger) getAssetCreator(rnd basics.Round, aidx basics.AssetIndex) (basics.Address, bool, error) {
+	l.trackerMu.RLock()
+	defer l.trackerMu.RUnlock()
+
+	return l.accts.getAssetCreator(rnd, aidx)
+}
+
+// GetAssetCreator looks up the address of the account that created
+// asset aidx, as of round rnd.
+func (l *Ledger) GetAssetCreator(rnd basics.Round, aidx basics.AssetIndex) (basics.Address, error) {
+	creator, ok, err := l.getAssetCreator(rnd, aidx)
+	if err != nil {
+		return basics.Address{}, err
+	}
+	if !ok {
+		return basics.Address{}, fmt.Errorf("asset %d does not exist", aidx)
+	}
+	return creator, nil
+}
+
 // Totals returns the totals of all accounts at the end of round rnd.
 func (l *Ledger) Totals(rnd basics.Round) (AccountTotals, error) {
 	l.trackerMu.RLock()
@@ -274,6 +333,12 @@ func (l *Ledger) isDup(firstValid basics.Round, lastValid basics.Round, txid tra
 	return l.txTail.isDup(firstValid, lastValid, txid)
 }
 
+func (l *Ledger) isLeased(current basics.Round, txl transactions.Txlease, proto config.ConsensusParams) bool {
+	l.trackerMu.RLock()
+	defer l.trackerMu.RUnlock()
+	return l.txTail.isLeased(current, txl, proto)
+}
+
 // Latest returns the latest known block round added to the ledger.
 func (l *Ledger) Latest() basics.Round {
 	return l.blockQ.latest()
@@ -294,6 +359,26 @@ func (l *Ledger) Committed(txn transactions.SignedTxn) (bool, error) {
 	return l.txTail.isDup(txn.Txn.First(), l.Latest(), txn.ID())
 }
 
+// Leased uses the transaction tail tracker to check if another transaction
+// from the same sender with the same lease appeared in a block whose lease
+// has not yet expired in the next round.
+func (l *Ledger) Leased(txn transactions.SignedTxn) (bool, error) {
+	lease, ok := txn.Txn.LeaseHeld()
+	if !ok {
+		return false, nil
+	}
+
+	latest := l.Latest()
+	hdr, err := l.BlockHdr(latest)
+	if err != nil {
+		return false, err
+	}
+
+	l.trackerMu.RLock()
+	defer l.trackerMu.RUnlock()
+	return l.txTail.isLeased(latest+1, lease, config.Consensus[hdr.CurrentProtocol]), nil
+}
+
 func (l *Ledger) blockAux(rnd basics.Round) (bookkeeping.Block, evalAux, error) {
 	return l.blockQ.getBlockAux(rnd)
 }
@@ -0,0 +1,110 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package ledger
+
+import (
+	"fmt"
+
+	"github.com/algorand/go-algorand/config"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/util/execpool"
+)
+
+// EvalDifference describes how the evaluation of a block under candidate
+// consensus parameters departs from the block as it was agreed upon.
+// Txid is zero for differences that concern the block as a whole.
+type EvalDifference struct {
+	Round       basics.Round
+	Txid        transactions.Txid
+	Description string
+}
+
+// EvalWithParams re-evaluates blk, the next block of the ledger, under
+// proto instead of the consensus parameters of its protocol version, and
+// returns where the outcome differs from the block: transaction groups that
+// would have been rejected, transactions whose ApplyData changes, and a
+// rewards state or block that could not have been produced.  Groups that
+// are rejected are left out of the rest of the evaluation, so they may
+// cause further differences down the block.  The ledger is not modified;
+// call AddBlock afterwards to move on to the next block.
+func (l *Ledger) EvalWithParams(blk bookkeeping.Block, proto config.ConsensusParams, executionPool execpool.BacklogPool) ([]EvalDifference, error) {
+	if blk.Round() != l.Latest()+1 {
+		return nil, fmt.Errorf("cannot evaluate block %d: latest round is %d", blk.Round(), l.Latest())
+	}
+
+	payset, err := blk.DecodePaysetWithAD()
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []EvalDifference
+	blockDiff := func(format string, args ...interface{}) {
+		diffs = append(diffs, EvalDifference{Round: blk.Round(), Description: fmt.Sprintf(format, args...)})
+	}
+
+	eval, err := startEvaluatorWithParams(l, blk.BlockHeader, proto, nil, true, true, nil, executionPool)
+	if err != nil {
+		blockDiff("cannot start evaluation: %v", err)
+		return diffs, nil
+	}
+
+	if eval.block.RewardsState != blk.RewardsState {
+		blockDiff("rewards state: %+v != %+v", eval.block.RewardsState, blk.RewardsState)
+	}
+
+	for _, txgroup := range groupPayset(payset) {
+		// The evaluator computes the ApplyData itself when generating.
+		unapplied := make([]transactions.SignedTxnWithAD, len(txgroup))
+		for i, txad := range txgroup {
+			unapplied[i].SignedTxn = txad.SignedTxn
+		}
+
+		err = eval.TransactionGroup(unapplied)
+		if err != nil {
+			diffs = append(diffs, EvalDifference{
+				Round:       blk.Round(),
+				Txid:        txgroup[0].ID(),
+				Description: fmt.Sprintf("group of %d rejected: %v", len(txgroup), err),
+			})
+			continue
+		}
+
+		evaluated := eval.block.Payset[len(eval.block.Payset)-len(txgroup):]
+		for i, txad := range txgroup {
+			_, ad, err := eval.block.DecodeSignedTxn(evaluated[i])
+			if err != nil {
+				return nil, err
+			}
+			if ad != txad.ApplyData {
+				diffs = append(diffs, EvalDifference{
+					Round:       blk.Round(),
+					Txid:        txad.ID(),
+					Description: fmt.Sprintf("applyData: %+v != %+v", ad, txad.ApplyData),
+				})
+			}
+		}
+	}
+
+	_, err = eval.GenerateBlock()
+	if err != nil {
+		blockDiff("cannot finish block: %v", err)
+	}
+
+	return diffs, nil
+}
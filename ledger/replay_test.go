@@ -0,0 +1,94 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package ledger
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/go-algorand/config"
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/logging"
+	"github.com/algorand/go-algorand/protocol"
+	"github.com/algorand/go-algorand/util/execpool"
+)
+
+func TestEvalWithParams(t *testing.T) {
+	blks, accts, addrs, keys := genesis(10)
+
+	backlogPool := execpool.MakeBacklog(nil, 0, execpool.LowPriority, nil)
+	defer backlogPool.Shutdown()
+
+	dbName := fmt.Sprintf("%s.%d", t.Name(), crypto.RandUint64())
+	l, err := OpenLedger(logging.Base(), dbName, true, blks, accts, blks[0].BlockHeader.GenesisHash)
+	require.NoError(t, err)
+	defer l.Close()
+
+	newBlock := bookkeeping.MakeBlock(blks[len(blks)-1].BlockHeader)
+	eval, err := l.StartEvaluator(newBlock.BlockHeader, nil, backlogPool)
+	require.NoError(t, err)
+
+	makeTx := func(sender, receiver int, fee uint64) transactions.SignedTxn {
+		txn := transactions.Transaction{
+			Type: protocol.PaymentTx,
+			Header: transactions.Header{
+				Sender:      addrs[sender],
+				Fee:         basics.MicroAlgos{Raw: fee},
+				FirstValid:  newBlock.Round(),
+				LastValid:   newBlock.Round(),
+				GenesisHash: blks[0].BlockHeader.GenesisHash,
+			},
+			PaymentTxnFields: transactions.PaymentTxnFields{
+				Receiver: addrs[receiver],
+				Amount:   basics.MicroAlgos{Raw: 100},
+			},
+		}
+		return txn.Sign(keys[sender])
+	}
+
+	cheap := makeTx(0, 1, minFee.Raw)
+	expensive := makeTx(2, 3, 2*minFee.Raw)
+	require.NoError(t, eval.Transaction(cheap, nil))
+	require.NoError(t, eval.Transaction(expensive, nil))
+	validatedBlock, err := eval.GenerateBlock()
+	require.NoError(t, err)
+	blk := validatedBlock.Block()
+
+	// Under its own parameters the block evaluates the same way
+	proto := config.Consensus[blk.CurrentProtocol]
+	diffs, err := l.EvalWithParams(blk, proto, backlogPool)
+	require.NoError(t, err)
+	require.Empty(t, diffs)
+
+	// A higher minimum fee rejects the cheap transaction only
+	proto.MinTxnFee = 2 * minFee.Raw
+	diffs, err = l.EvalWithParams(blk, proto, backlogPool)
+	require.NoError(t, err)
+	require.Len(t, diffs, 1)
+	require.Equal(t, blk.Round(), diffs[0].Round)
+	require.Equal(t, cheap.ID(), diffs[0].Txid)
+
+	// The ledger is left untouched
+	require.Equal(t, newBlock.Round()-1, l.Latest())
+	_, err = l.EvalWithParams(bookkeeping.MakeBlock(blk.BlockHeader), proto, backlogPool)
+	require.Error(t, err)
+}
@@ -17,6 +17,7 @@
 package ledger
 
 import (
+	"github.com/algorand/go-algorand/crypto"
 	"github.com/algorand/go-algorand/data/basics"
 	"github.com/algorand/go-algorand/data/bookkeeping"
 	"github.com/algorand/go-algorand/logging"
@@ -84,6 +85,7 @@ type ledgerForTracker interface {
 	trackerEvalVerified(bookkeeping.Block, evalAux) (stateDelta, error)
 
 	Latest() basics.Round
+	GenesisHash() crypto.Digest
 	Block(basics.Round) (bookkeeping.Block, error)
 	BlockHdr(basics.Round) (bookkeeping.BlockHeader, error)
 	blockAux(basics.Round) (bookkeeping.Block, evalAux, error)
@@ -26,8 +26,9 @@ import (
 )
 
 type roundTxMembers struct {
-	txids map[transactions.Txid]struct{}
-	proto config.ConsensusParams
+	txids    map[transactions.Txid]struct{}
+	txleases map[transactions.Txlease]basics.Round // map of transaction lease to when it expires
+	proto    config.ConsensusParams
 }
 
 type txTail struct {
@@ -61,11 +62,15 @@ func (t *txTail) loadFromDisk(l ledgerForTracker) error {
 		}
 
 		t.recent[old] = roundTxMembers{
-			txids: make(map[transactions.Txid]struct{}),
-			proto: config.Consensus[blk.CurrentProtocol],
+			txids:    make(map[transactions.Txid]struct{}),
+			txleases: make(map[transactions.Txlease]basics.Round),
+			proto:    config.Consensus[blk.CurrentProtocol],
 		}
 		for _, tx := range payset {
 			t.recent[old].txids[tx.ID()] = struct{}{}
+			if lease, ok := tx.Txn.LeaseHeld(); ok {
+				t.recent[old].txleases[lease] = tx.Txn.LastValid
+			}
 		}
 	}
 
@@ -84,8 +89,9 @@ func (t *txTail) newBlock(blk bookkeeping.Block, delta stateDelta) {
 	}
 
 	t.recent[rnd] = roundTxMembers{
-		txids: delta.txids,
-		proto: config.Consensus[blk.CurrentProtocol],
+		txids:    delta.txids,
+		txleases: delta.txleases,
+		proto:    config.Consensus[blk.CurrentProtocol],
 	}
 }
 
@@ -115,3 +121,21 @@ func (t *txTail) isDup(firstValid basics.Round, lastValid basics.Round, txid tra
 
 	return false, nil
 }
+
+// isLeased reports whether the (sender, lease) pair is held by a transaction
+// committed in one of the MaxTxnLife rounds preceding current, where proto
+// holds the consensus parameters of round current.  A lease expires after the
+// LastValid round of the transaction that acquired it, and since that
+// transaction was valid for at most MaxTxnLife rounds, older rounds cannot
+// hold an active lease.
+func (t *txTail) isLeased(current basics.Round, txl transactions.Txlease, proto config.ConsensusParams) bool {
+	maxlife := basics.Round(proto.MaxTxnLife)
+	for rnd := current.SubSaturate(maxlife); rnd < current; rnd++ {
+		expires, ok := t.recent[rnd].txleases[txl]
+		if ok && current <= expires {
+			return true
+		}
+	}
+
+	return false
+}
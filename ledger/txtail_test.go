@@ -0,0 +1,48 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package ledger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/go-algorand/config"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/protocol"
+)
+
+func TestTxTailLeaseWithoutPreviousRound(t *testing.T) {
+	proto := config.Consensus[protocol.ConsensusCurrentVersion]
+	lease := transactions.Txlease{Sender: basics.Address{0x01}, Lease: [32]byte{0x02}}
+
+	// The round before the one being checked is not tracked; the lease
+	// is still found from the consensus parameters of the checked round.
+	tail := txTail{recent: map[basics.Round]roundTxMembers{
+		5: {
+			txids:    map[transactions.Txid]struct{}{},
+			txleases: map[transactions.Txlease]basics.Round{lease: 20},
+			proto:    proto,
+		},
+	}}
+	require.True(t, tail.isLeased(10, lease, proto))
+	require.False(t, tail.isLeased(21, lease, proto))
+
+	other := transactions.Txlease{Sender: basics.Address{0x01}, Lease: [32]byte{0x03}}
+	require.False(t, tail.isLeased(10, other, proto))
+}
@@ -17,6 +17,7 @@
 package libgoal
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -444,7 +445,7 @@ func (c *Client) signAndBroadcastTransactionWithWallet(walletHandle, pw []byte,
 	if err != nil {
 		return transactions.Transaction{}, err
 	}
-	resp0, err := kmd.SignTransaction(walletHandle, pw, tx)
+	resp0, err := kmd.SignTransaction(walletHandle, pw, crypto.PublicKey{}, tx)
 	if err != nil {
 		return transactions.Transaction{}, err
 	}
@@ -560,6 +561,42 @@ func (c *Client) Status() (resp models.NodeStatus, err error) {
 	return
 }
 
+// Catchup asks the node to catch up to the given catchpoint label
+func (c *Client) Catchup(catchpoint string) (resp models.NodeStatus, err error) {
+	algod, err := c.ensureAlgodClient()
+	if err == nil {
+		resp, err = algod.Catchup(catchpoint)
+	}
+	return
+}
+
+// UpgradeStatus returns the progress of a consensus protocol upgrade
+func (c *Client) UpgradeStatus() (resp models.UpgradeStatus, err error) {
+	algod, err := c.ensureAlgodClient()
+	if err == nil {
+		resp, err = algod.UpgradeStatus()
+	}
+	return
+}
+
+// DevModeAdvanceRound asks a dev mode node to seal a block with its pending transactions
+func (c *Client) DevModeAdvanceRound() (resp models.DevModeStatus, err error) {
+	algod, err := c.ensureAlgodClient()
+	if err == nil {
+		resp, err = algod.DevModeAdvanceRound()
+	}
+	return
+}
+
+// DevModeAdvanceClock asks a dev mode node to move its clock forward by the given number of seconds
+func (c *Client) DevModeAdvanceClock(seconds uint64) (resp models.DevModeStatus, err error) {
+	algod, err := c.ensureAlgodClient()
+	if err == nil {
+		resp, err = algod.DevModeAdvanceClock(seconds)
+	}
+	return
+}
+
 // AccountInformation takes an address and returns its information
 func (c *Client) AccountInformation(account string) (resp models.Account, err error) {
 	algod, err := c.ensureAlgodClient()
@@ -569,6 +606,15 @@ func (c *Client) AccountInformation(account string) (resp models.Account, err er
 	return
 }
 
+// AccountInformationAtRound takes an address and returns its information as of the given round
+func (c *Client) AccountInformationAtRound(account string, round uint64) (resp models.Account, err error) {
+	algod, err := c.ensureAlgodClient()
+	if err == nil {
+		resp, err = algod.AccountInformationAtRound(account, round)
+	}
+	return
+}
+
 // TransactionInformation takes an address and associated txid and return its information
 func (c *Client) TransactionInformation(addr, txid string) (resp models.Transaction, err error) {
 	algod, err := c.ensureAlgodClient()
@@ -597,6 +643,27 @@ func (c *Client) Block(round uint64) (resp models.Block, err error) {
 	return
 }
 
+// StreamBlocks calls handler for each block committed by the node, starting at round,
+// until ctx is cancelled, handler returns an error, or the node ends the stream
+func (c *Client) StreamBlocks(ctx context.Context, round uint64, handler func(models.Block) error) error {
+	algod, err := c.ensureAlgodClient()
+	if err != nil {
+		return err
+	}
+	return algod.StreamBlocks(ctx, round, handler)
+}
+
+// StreamPendingTransactionEvents calls handler for each change to the node's transaction pool,
+// restricted to the given transactions if txids is not empty, until ctx is cancelled, handler
+// returns an error, or the node ends the stream
+func (c *Client) StreamPendingTransactionEvents(ctx context.Context, txids []string, handler func(models.PendingTransactionEvent) error) error {
+	algod, err := c.ensureAlgodClient()
+	if err != nil {
+		return err
+	}
+	return algod.StreamPendingTransactionEvents(ctx, txids, handler)
+}
+
 // HealthCheck returns an error if something is wrong
 func (c *Client) HealthCheck() error {
 	algod, err := c.ensureAlgodClient()
@@ -18,9 +18,11 @@ package libgoal
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/algorand/go-algorand/config"
 	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/daemon/algod/api/client/models"
 	"github.com/algorand/go-algorand/data/account"
 	"github.com/algorand/go-algorand/data/basics"
 	"github.com/algorand/go-algorand/data/transactions"
@@ -29,13 +31,27 @@ import (
 
 // SignTransactionWithWallet signs the passed transaction with keys from the wallet associated with the passed walletHandle
 func (c *Client) SignTransactionWithWallet(walletHandle, pw []byte, utx transactions.Transaction) (stx transactions.SignedTxn, err error) {
+	return c.signTransactionWithWallet(walletHandle, pw, crypto.PublicKey{}, utx)
+}
+
+// SignTransactionWithWalletAndSigner signs the passed transaction with the key of signerAddr from the wallet associated
+// with the passed walletHandle.  This signs for a sender that has been rekeyed to signerAddr.
+func (c *Client) SignTransactionWithWalletAndSigner(walletHandle, pw []byte, signerAddr string, utx transactions.Transaction) (stx transactions.SignedTxn, err error) {
+	addr, err := basics.UnmarshalChecksumAddress(signerAddr)
+	if err != nil {
+		return
+	}
+	return c.signTransactionWithWallet(walletHandle, pw, crypto.PublicKey(addr), utx)
+}
+
+func (c *Client) signTransactionWithWallet(walletHandle, pw []byte, pk crypto.PublicKey, utx transactions.Transaction) (stx transactions.SignedTxn, err error) {
 	kmd, err := c.ensureKmdClient()
 	if err != nil {
 		return
 	}
 
 	// Sign the transaction
-	resp, err := kmd.SignTransaction(walletHandle, pw, utx)
+	resp, err := kmd.SignTransaction(walletHandle, pw, pk, utx)
 	if err != nil {
 		return
 	}
@@ -48,6 +64,12 @@ func (c *Client) SignTransactionWithWallet(walletHandle, pw []byte, utx transact
 // MultisigSignTransactionWithWallet creates a multisig (or adds to an existing partial multisig, if one is provided), signing with the key corresponding to the given address and using the specified wallet
 // TODO instead of returning MultisigSigs, accept and return blobs
 func (c *Client) MultisigSignTransactionWithWallet(walletHandle, pw []byte, utx transactions.Transaction, signerAddr string, partial crypto.MultisigSig) (msig crypto.MultisigSig, err error) {
+	return c.MultisigSignTransactionWithWalletAndSigner(walletHandle, pw, utx, signerAddr, partial, basics.Address{})
+}
+
+// MultisigSignTransactionWithWalletAndSigner is like MultisigSignTransactionWithWallet, but signs for the multisig
+// address msigAddr that the sender has been rekeyed to.  An empty msigAddr signs for the sender.
+func (c *Client) MultisigSignTransactionWithWalletAndSigner(walletHandle, pw []byte, utx transactions.Transaction, signerAddr string, partial crypto.MultisigSig, msigAddr basics.Address) (msig crypto.MultisigSig, err error) {
 	txBytes := protocol.Encode(utx)
 	addr, err := basics.UnmarshalChecksumAddress(signerAddr)
 	if err != nil {
@@ -57,7 +79,7 @@ func (c *Client) MultisigSignTransactionWithWallet(walletHandle, pw []byte, utx
 	if err != nil {
 		return
 	}
-	resp, err := kmd.MultisigSignTransaction(walletHandle, pw, txBytes, crypto.PublicKey(addr), partial)
+	resp, err := kmd.MultisigSignTransaction(walletHandle, pw, txBytes, crypto.PublicKey(addr), partial, crypto.Digest(msigAddr))
 	if err != nil {
 		return
 	}
@@ -78,6 +100,41 @@ func (c *Client) BroadcastTransaction(stx transactions.SignedTxn) (txid string,
 	return resp.TxID, nil
 }
 
+// BroadcastTransactionGroup broadcasts a signed transaction group to the network using algod
+func (c *Client) BroadcastTransactionGroup(txgroup []transactions.SignedTxn) error {
+	algod, err := c.ensureAlgodClient()
+	if err != nil {
+		return err
+	}
+	return algod.SendRawTransactionGroup(txgroup)
+}
+
+// DryRun evaluates signed or unsigned transactions against the latest
+// ledger state using algod, without broadcasting them
+func (c *Client) DryRun(txns []transactions.SignedTxn) (resp models.DryRunResults, err error) {
+	algod, err := c.ensureAlgodClient()
+	if err == nil {
+		resp, err = algod.DryRun(txns)
+	}
+	return
+}
+
+// GroupID computes the group ID for a list of transactions. The
+// transactions must not have their Group field set yet.
+func (c *Client) GroupID(txgroup []transactions.Transaction) (gid crypto.Digest, err error) {
+	var group transactions.TxGroup
+	for _, tx := range txgroup {
+		if !tx.Group.IsZero() {
+			err = fmt.Errorf("tx %v already has a group %v", tx.ID(), tx.Group)
+			return
+		}
+
+		group.TxGroupHashes = append(group.TxGroupHashes, crypto.Digest(tx.ID()))
+	}
+
+	return crypto.HashObj(group), nil
+}
+
 // SignAndBroadcastTransaction signs the unsigned transaction with keys from the default wallet, and broadcasts it
 func (c *Client) SignAndBroadcastTransaction(walletHandle, pw []byte, utx transactions.Transaction) (txid string, err error) {
 	// Sign the transaction
@@ -220,3 +277,258 @@ func (c *Client) MakeUnsignedGoOfflineTx(address string, round, txValidRounds, f
 	}
 	return goOfflineTransaction, nil
 }
+
+// FillUnsignedTxTemplate fills in header fields in a partially-filled-in transaction.
+func (c *Client) FillUnsignedTxTemplate(sender string, firstValid, lastValid, fee uint64, tx transactions.Transaction) (transactions.Transaction, error) {
+	// Parse the address
+	parsedAddr, err := basics.UnmarshalChecksumAddress(sender)
+	if err != nil {
+		return transactions.Transaction{}, err
+	}
+
+	// Get current round, protocol, genesis ID
+	params, err := c.SuggestedParams()
+	if err != nil {
+		return transactions.Transaction{}, err
+	}
+
+	cparams, ok := config.Consensus[protocol.ConsensusVersion(params.ConsensusVersion)]
+	if !ok {
+		return transactions.Transaction{}, errors.New("unknown consensus version")
+	}
+
+	if firstValid == 0 {
+		firstValid = params.LastRound + 1
+	}
+	if lastValid == 0 {
+		lastValid = firstValid + cparams.MaxTxnLife
+	}
+	if firstValid > lastValid {
+		return transactions.Transaction{}, fmt.Errorf("cannot construct transaction: txn would first be valid on round %d which is after last valid round %d", firstValid, lastValid)
+	}
+
+	tx.Header.Sender = parsedAddr
+	tx.Header.Fee = basics.MicroAlgos{Raw: fee}
+	tx.Header.FirstValid = basics.Round(firstValid)
+	tx.Header.LastValid = basics.Round(lastValid)
+
+	tx.Header.GenesisID = params.GenesisID
+	if cparams.SupportGenesisHash {
+		copy(tx.Header.GenesisHash[:], params.GenesisHash)
+	}
+
+	// Default to the suggested fee, if the caller didn't supply it
+	// Fee is tricky, should taken care last. We encode the final transaction to get the size post signing and encoding
+	// Then, we multiply it by the suggested fee per byte.
+	if fee == 0 {
+		tx.Fee = basics.MulAIntSaturate(basics.MicroAlgos{Raw: params.Fee}, tx.EstimateEncodedSize())
+		if tx.Fee.Raw < cparams.MinTxnFee {
+			tx.Fee.Raw = cparams.MinTxnFee
+		}
+	}
+
+	return tx, nil
+}
+
+// MakeUnsignedAssetCreateTx creates a tx template for creating
+// an asset.
+//
+// Call FillUnsignedTxTemplate afterwards to fill out common fields in
+// the resulting transaction template.
+func (c *Client) MakeUnsignedAssetCreateTx(total uint64, defaultFrozen bool, manager string, reserve string, freeze string, clawback string, unitName string, assetName string, url string, metadataHash []byte) (transactions.Transaction, error) {
+	var tx transactions.Transaction
+	var err error
+
+	tx.Type = protocol.AssetConfigTx
+	tx.AssetParams = basics.AssetParams{
+		Total:         total,
+		DefaultFrozen: defaultFrozen,
+		UnitName:      unitName,
+		AssetName:     assetName,
+		URL:           url,
+	}
+
+	if len(metadataHash) > len(tx.AssetParams.MetadataHash) {
+		return tx, fmt.Errorf("metadata hash too long: %d > %d", len(metadataHash), len(tx.AssetParams.MetadataHash))
+	}
+	copy(tx.AssetParams.MetadataHash[:], metadataHash)
+
+	if manager != "" {
+		tx.AssetParams.Manager, err = basics.UnmarshalChecksumAddress(manager)
+		if err != nil {
+			return tx, err
+		}
+	}
+
+	if reserve != "" {
+		tx.AssetParams.Reserve, err = basics.UnmarshalChecksumAddress(reserve)
+		if err != nil {
+			return tx, err
+		}
+	}
+
+	if freeze != "" {
+		tx.AssetParams.Freeze, err = basics.UnmarshalChecksumAddress(freeze)
+		if err != nil {
+			return tx, err
+		}
+	}
+
+	if clawback != "" {
+		tx.AssetParams.Clawback, err = basics.UnmarshalChecksumAddress(clawback)
+		if err != nil {
+			return tx, err
+		}
+	}
+
+	return tx, nil
+}
+
+// MakeUnsignedAssetDestroyTx creates a tx template for destroying
+// an asset, removing it from the record.
+// All outstanding asset amount must be held by the creator,
+// and this transaction must be issued by the asset manager.
+//
+// Call FillUnsignedTxTemplate afterwards to fill out common fields in
+// the resulting transaction template.
+func (c *Client) MakeUnsignedAssetDestroyTx(index uint64) (transactions.Transaction, error) {
+	var tx transactions.Transaction
+	tx.Type = protocol.AssetConfigTx
+	tx.ConfigAsset = basics.AssetIndex(index)
+	return tx, nil
+}
+
+// MakeUnsignedAssetConfigTx creates a tx template for changing the
+// keys for an asset.  A nil pointer for a new key argument means no
+// change to existing key.  An empty string means a zero key (which
+// cannot be changed after becoming zero).
+//
+// Call FillUnsignedTxTemplate afterwards to fill out common fields in
+// the resulting transaction template.
+func (c *Client) MakeUnsignedAssetConfigTx(creator string, index uint64, newManager *string, newReserve *string, newFreeze *string, newClawback *string) (transactions.Transaction, error) {
+	var tx transactions.Transaction
+	var err error
+
+	// Get the existing asset params, so that unchanged keys are preserved
+	info, err := c.AccountInformation(creator)
+	if err != nil {
+		return tx, err
+	}
+
+	params, ok := info.AssetParams[index]
+	if !ok {
+		return tx, fmt.Errorf("asset %d not found in account %s", index, creator)
+	}
+
+	if newManager == nil {
+		newManager = &params.ManagerAddr
+	}
+
+	if newReserve == nil {
+		newReserve = &params.ReserveAddr
+	}
+
+	if newFreeze == nil {
+		newFreeze = &params.FreezeAddr
+	}
+
+	if newClawback == nil {
+		newClawback = &params.ClawbackAddr
+	}
+
+	tx.Type = protocol.AssetConfigTx
+	tx.ConfigAsset = basics.AssetIndex(index)
+
+	if *newManager != "" {
+		tx.AssetParams.Manager, err = basics.UnmarshalChecksumAddress(*newManager)
+		if err != nil {
+			return tx, err
+		}
+	}
+
+	if *newReserve != "" {
+		tx.AssetParams.Reserve, err = basics.UnmarshalChecksumAddress(*newReserve)
+		if err != nil {
+			return tx, err
+		}
+	}
+
+	if *newFreeze != "" {
+		tx.AssetParams.Freeze, err = basics.UnmarshalChecksumAddress(*newFreeze)
+		if err != nil {
+			return tx, err
+		}
+	}
+
+	if *newClawback != "" {
+		tx.AssetParams.Clawback, err = basics.UnmarshalChecksumAddress(*newClawback)
+		if err != nil {
+			return tx, err
+		}
+	}
+
+	return tx, nil
+}
+
+// MakeUnsignedAssetSendTx creates a tx template for sending assets.
+// To allocate a slot for a particular asset, send a zero amount to self.
+//
+// The semantics of the sender address and closeTo address are as for
+// an ordinary payment.  If clawbackSource is non-empty, the transaction
+// revokes assets from clawbackSource; in that case, the transaction
+// must be sent by the asset's clawback account.
+//
+// Call FillUnsignedTxTemplate afterwards to fill out common fields in
+// the resulting transaction template.
+func (c *Client) MakeUnsignedAssetSendTx(index uint64, amount uint64, recipient string, closeTo string, clawbackSource string) (transactions.Transaction, error) {
+	var tx transactions.Transaction
+	var err error
+
+	tx.Type = protocol.AssetTransferTx
+	tx.XferAsset = basics.AssetIndex(index)
+	tx.AssetAmount = amount
+
+	if recipient != "" {
+		tx.AssetReceiver, err = basics.UnmarshalChecksumAddress(recipient)
+		if err != nil {
+			return tx, err
+		}
+	}
+
+	if closeTo != "" {
+		tx.AssetCloseTo, err = basics.UnmarshalChecksumAddress(closeTo)
+		if err != nil {
+			return tx, err
+		}
+	}
+
+	if clawbackSource != "" {
+		tx.AssetSender, err = basics.UnmarshalChecksumAddress(clawbackSource)
+		if err != nil {
+			return tx, err
+		}
+	}
+
+	return tx, nil
+}
+
+// MakeUnsignedAssetFreezeTx creates a tx template for freezing assets.
+//
+// Call FillUnsignedTxTemplate afterwards to fill out common fields in
+// the resulting transaction template.
+func (c *Client) MakeUnsignedAssetFreezeTx(index uint64, accountToChange string, newFreezeSetting bool) (transactions.Transaction, error) {
+	var tx transactions.Transaction
+	var err error
+
+	tx.Type = protocol.AssetFreezeTx
+	tx.FreezeAsset = basics.AssetIndex(index)
+
+	tx.FreezeAccount, err = basics.UnmarshalChecksumAddress(accountToChange)
+	if err != nil {
+		return tx, err
+	}
+
+	tx.AssetFrozen = newFreezeSetting
+
+	return tx, nil
+}
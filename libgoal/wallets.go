@@ -220,3 +220,37 @@ func (c *Client) ExportMasterDerivationKey(wh []byte, pw []byte) (mdk crypto.Mas
 	// Return the mdk from the response
 	return resp.MasterDerivationKey, nil
 }
+
+// BackupWallet returns an encrypted archive of everything in the given wallet
+func (c *Client) BackupWallet(wh []byte, pw []byte, backupPw []byte) (backup []byte, err error) {
+	kmd, err := c.ensureKmdClient()
+	if err != nil {
+		return
+	}
+
+	// Back up the wallet
+	resp, err := kmd.BackupWallet(wh, pw, backupPw)
+	if err != nil {
+		return
+	}
+
+	// Return the archive from the response
+	return resp.Backup, nil
+}
+
+// RestoreWallet creates a wallet from an archive made by BackupWallet,
+// returning the new wallet
+func (c *Client) RestoreWallet(backup []byte, backupPw []byte, name []byte, pw []byte, renameOnConflict bool) (wallet kmdapi.APIV1Wallet, err error) {
+	kmd, err := c.ensureKmdClient()
+	if err != nil {
+		return
+	}
+
+	// Restore the wallet
+	resp, err := kmd.RestoreWallet(defaultWalletDriver, backup, backupPw, name, pw, renameOnConflict)
+	if err != nil {
+		return
+	}
+
+	return resp.Wallet, nil
+}
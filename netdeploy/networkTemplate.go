@@ -208,6 +208,11 @@ func (t NetworkTemplate) Validate() error {
 		return fmt.Errorf("invalid template: at least one relay is required")
 	}
 
+	// Dev mode nodes seal blocks on their own, so they cannot share a network
+	if t.Genesis.DevMode && len(t.Nodes) != 1 {
+		return fmt.Errorf("invalid template: dev mode networks must have exactly one node")
+	}
+
 	return nil
 }
 
@@ -0,0 +1,337 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package network
+
+import (
+	"bytes"
+	"container/heap"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/protocol"
+)
+
+// Peers prove ownership of a long-term Ed25519 identity key while they
+// connect.  The dialing node sends its key and a random challenge in the
+// IdentityChallengeHeader of its websocket request.  The accepting node
+// replies, in the IdentityChallengeResponseHeader, with its own key and a
+// challenge of its own, signed together with the dialer's challenge.  Once
+// the connection is up, the dialer signs both challenges together with the
+// accepting node's key, and sends them in a NetIdentityTag message.  Binding
+// the proof to the whole exchange and to the node it is meant for keeps a
+// middleman from relaying a proof obtained on another connection.
+//
+// Verified identities let us detect duplicate connections to the same node,
+// match PriorityPeers by key, and keep the netprio weight of a node across
+// reconnections.  Peers that do not take part in the handshake are accepted,
+// but remain unidentified.
+
+// IdentityChallengeHeader carries the identity key and challenge of a dialing node.
+const IdentityChallengeHeader = "X-Algorand-IdentityChallenge"
+
+// IdentityChallengeResponseHeader carries the signed identity reply of the node accepting a connection.
+const IdentityChallengeResponseHeader = "X-Algorand-IdentityChallengeResponse"
+
+var errNoIdentity = errors.New("peer did not reply to identity challenge")
+
+type identityChallengeValue [32]byte
+
+func newIdentityChallengeValue() (c identityChallengeValue) {
+	crypto.RandBytes(c[:])
+	return
+}
+
+type identityChallenge struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	Key       crypto.PublicKey       `codec:"pk"`
+	Challenge identityChallengeValue `codec:"c"`
+}
+
+type identityReply struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	Key               crypto.PublicKey       `codec:"pk"`
+	Challenge         identityChallengeValue `codec:"c"`
+	ResponseChallenge identityChallengeValue `codec:"rc"`
+}
+
+// ToBeHashed implements the crypto.Hashable interface.
+func (r identityReply) ToBeHashed() (protocol.HashID, []byte) {
+	return protocol.NetIdentityReply, protocol.Encode(r)
+}
+
+type identityReplySigned struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	Reply     identityReply    `codec:"r"`
+	Signature crypto.Signature `codec:"sig"`
+}
+
+type identityProof struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	// Key is the identity key of the node the proof is meant for
+	Key               crypto.PublicKey       `codec:"pk"`
+	Challenge         identityChallengeValue `codec:"c"`
+	ResponseChallenge identityChallengeValue `codec:"rc"`
+}
+
+// ToBeHashed implements the crypto.Hashable interface.
+func (p identityProof) ToBeHashed() (protocol.HashID, []byte) {
+	return protocol.NetIdentityProof, protocol.Encode(p)
+}
+
+type identityProofSigned struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	Proof     identityProof    `codec:"p"`
+	Signature crypto.Signature `codec:"sig"`
+}
+
+// IdentityString is the printable form of a peer identity key, as used in
+// PriorityPeers.
+func IdentityString(key crypto.PublicKey) string {
+	return base64.StdEncoding.EncodeToString(key[:])
+}
+
+// LoadIdentity reads the identity key stored at filename, or generates and
+// stores a new one if the file does not exist.
+func LoadIdentity(filename string) (*crypto.SignatureSecrets, error) {
+	var seed crypto.Seed
+	raw, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		crypto.RandBytes(seed[:])
+		err = ioutil.WriteFile(filename, seed[:], 0600)
+		if err != nil {
+			return nil, fmt.Errorf("could not write identity key %s: %v", filename, err)
+		}
+		return crypto.GenerateSignatureSecrets(seed), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read identity key %s: %v", filename, err)
+	}
+	if len(raw) != len(seed) {
+		return nil, fmt.Errorf("identity key %s has %d bytes, expected %d", filename, len(raw), len(seed))
+	}
+	copy(seed[:], raw)
+	return crypto.GenerateSignatureSecrets(seed), nil
+}
+
+// SetIdentity sets the long-term identity key the node proves to its peers.
+// It must be called before Start; otherwise the node uses a key that only
+// lasts as long as the process.
+func (wn *WebsocketNetwork) SetIdentity(identity *crypto.SignatureSecrets) {
+	wn.identity = identity
+}
+
+// Identity returns the identity key of this node.
+func (wn *WebsocketNetwork) Identity() crypto.PublicKey {
+	return wn.identity.SignatureVerifier
+}
+
+// attachIdentityChallenge adds our identity challenge to the headers of an
+// outgoing connection request.
+func (wn *WebsocketNetwork) attachIdentityChallenge(header http.Header) identityChallengeValue {
+	c := identityChallenge{
+		Key:       wn.identity.SignatureVerifier,
+		Challenge: newIdentityChallengeValue(),
+	}
+	header.Set(IdentityChallengeHeader, base64.StdEncoding.EncodeToString(protocol.Encode(c)))
+	return c.Challenge
+}
+
+// replyToIdentityChallenge answers the identity challenge in the headers of
+// an incoming connection request, if there is one.  It returns the key the
+// peer claims, and the proof it must sign to prove it.
+func (wn *WebsocketNetwork) replyToIdentityChallenge(requestHeader http.Header, responseHeader http.Header) (claimed crypto.PublicKey, expected identityProof, ok bool) {
+	encoded := requestHeader.Get(IdentityChallengeHeader)
+	if encoded == "" {
+		return
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		wn.log.Infof("bad identity challenge: %v", err)
+		return
+	}
+	var c identityChallenge
+	err = protocol.Decode(raw, &c)
+	if err != nil {
+		wn.log.Infof("bad identity challenge: %v", err)
+		return
+	}
+
+	reply := identityReply{
+		Key:               wn.identity.SignatureVerifier,
+		Challenge:         c.Challenge,
+		ResponseChallenge: newIdentityChallengeValue(),
+	}
+	signed := identityReplySigned{Reply: reply, Signature: wn.identity.Sign(reply)}
+	responseHeader.Set(IdentityChallengeResponseHeader, base64.StdEncoding.EncodeToString(protocol.Encode(signed)))
+	expected = identityProof{
+		Key:               reply.Key,
+		Challenge:         reply.Challenge,
+		ResponseChallenge: reply.ResponseChallenge,
+	}
+	return c.Key, expected, true
+}
+
+// verifyIdentityReply checks the identity reply in the response to an
+// outgoing connection request, and returns the verified key of the peer and
+// the proof we must sign for it.
+func (wn *WebsocketNetwork) verifyIdentityReply(responseHeader http.Header, challenge identityChallengeValue) (key crypto.PublicKey, proof identityProof, err error) {
+	encoded := responseHeader.Get(IdentityChallengeResponseHeader)
+	if encoded == "" {
+		err = errNoIdentity
+		return
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return
+	}
+	var signed identityReplySigned
+	err = protocol.Decode(raw, &signed)
+	if err != nil {
+		return
+	}
+	if signed.Reply.Challenge != challenge {
+		err = fmt.Errorf("identity reply is for another challenge")
+		return
+	}
+	if !signed.Reply.Key.Verify(signed.Reply, signed.Signature) {
+		err = fmt.Errorf("bad identity reply signature")
+		return
+	}
+	proof = identityProof{
+		Key:               signed.Reply.Key,
+		Challenge:         signed.Reply.Challenge,
+		ResponseChallenge: signed.Reply.ResponseChallenge,
+	}
+	return signed.Reply.Key, proof, nil
+}
+
+// identityProofMessage returns the NetIdentityTag message proving our
+// identity with proof.
+func (wn *WebsocketNetwork) identityProofMessage(proof identityProof) []byte {
+	signed := identityProofSigned{Proof: proof, Signature: wn.identity.Sign(proof)}
+	return append([]byte(protocol.NetIdentityTag), protocol.Encode(signed)...)
+}
+
+// verifyIdentityProof checks that data is a proof, signed by claimed, of
+// exactly the exchange that expected describes.
+func verifyIdentityProof(data []byte, claimed crypto.PublicKey, expected identityProof) bool {
+	var signed identityProofSigned
+	err := protocol.Decode(data, &signed)
+	if err != nil {
+		return false
+	}
+	return signed.Proof == expected && claimed.Verify(signed.Proof, signed.Signature)
+}
+
+// takeIdentityExpected returns the proof the peer is expected to sign and
+// clears it, so that a proof is only good once.
+func (wp *wsPeer) takeIdentityExpected() identityProof {
+	wp.identityLock.Lock()
+	defer wp.identityLock.Unlock()
+	expected := wp.identityExpected
+	wp.identityExpected = identityProof{}
+	return expected
+}
+
+func identityProofHandler(message IncomingMessage) OutgoingMessage {
+	wn := message.Net.(*WebsocketNetwork)
+	peer := message.Sender.(*wsPeer)
+	if peer.outgoing {
+		return OutgoingMessage{}
+	}
+	expected := peer.takeIdentityExpected()
+	if expected == (identityProof{}) {
+		// we did not ask this peer to prove anything, or it already did
+		return OutgoingMessage{}
+	}
+
+	if !verifyIdentityProof(message.Data, peer.identityClaim, expected) {
+		wn.log.Warnf("peer %s failed to prove identity %s", peer.rootURL, IdentityString(peer.identityClaim))
+		wn.wg.Add(1)
+		go wn.disconnectThread(peer, disconnectBadIdentity)
+		return OutgoingMessage{}
+	}
+	if wn.reputation.banned(IdentityString(peer.identityClaim)) {
+		wn.log.Infof("peer %s has banned identity %s", peer.rootURL, IdentityString(peer.identityClaim))
+		wn.wg.Add(1)
+		go wn.disconnectThread(peer, disconnectBanned)
+		return OutgoingMessage{}
+	}
+
+	if !wn.identifyPeer(peer, peer.identityClaim) {
+		wn.wg.Add(1)
+		go wn.disconnectThread(peer, disconnectDuplicateConnection)
+	}
+	return OutgoingMessage{}
+}
+
+var identityHandlers = []TaggedMessageHandler{
+	TaggedMessageHandler{protocol.NetIdentityTag, HandlerFunc(identityProofHandler)},
+}
+
+// identifyPeer records that peer has proven it holds key.  It returns false
+// if peer duplicates a connection we keep instead, in which case the caller
+// must drop peer; if peer replaces an existing connection, identifyPeer drops
+// the existing one.
+func (wn *WebsocketNetwork) identifyPeer(peer *wsPeer, key crypto.PublicKey) bool {
+	if key == wn.identity.SignatureVerifier {
+		wn.log.Debugf("peer %s has our own identity, am I talking to myself?", peer.rootURL)
+		return false
+	}
+
+	wn.peersLock.Lock()
+	defer wn.peersLock.Unlock()
+
+	existing := wn.peersByIdentity[key]
+	if existing != nil && existing != peer {
+		// Two connections between the same pair of nodes.  If they were
+		// dialed in opposite directions, both ends keep the one dialed by
+		// the node with the smaller key, so that they agree on which to drop.
+		if existing.outgoing == peer.outgoing {
+			wn.log.Infof("peer %s is already connected as %s", peer.rootURL, existing.rootURL)
+			return false
+		}
+		keepOutgoing := bytes.Compare(wn.identity.SignatureVerifier[:], key[:]) < 0
+		if peer.outgoing != keepOutgoing {
+			wn.log.Infof("peer %s is already connected as %s", peer.rootURL, existing.rootURL)
+			return false
+		}
+		wn.log.Infof("peer %s replaces duplicate connection %s", peer.rootURL, existing.rootURL)
+		wn.wg.Add(1)
+		go wn.disconnectThread(existing, disconnectDuplicateConnection)
+	}
+
+	wn.peersByIdentity[key] = peer
+	peer.identity = key
+	peer.identityVerified = true
+	if peer.peerIndex < len(wn.peers) && wn.peers[peer.peerIndex] == peer {
+		// the peer may now match PriorityPeers
+		heap.Fix(peersHeap{wn}, peer.peerIndex)
+	}
+	wn.prioTracker.restorePriority(peer)
+	return true
+}
@@ -0,0 +1,222 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package network
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/go-deadlock"
+
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/protocol"
+)
+
+// settablePhonebook can have its entry set while the network is running.
+type settablePhonebook struct {
+	lock  deadlock.Mutex
+	entry string
+}
+
+func (p *settablePhonebook) GetAddresses(n int) []string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.entry == "" {
+		return nil
+	}
+	return []string{p.entry}
+}
+
+func (p *settablePhonebook) set(entry string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.entry = entry
+}
+
+// identifiedPeers returns the identities of the peers of wn, and whether all
+// of them are verified.
+func identifiedPeers(wn *WebsocketNetwork) (ids []crypto.PublicKey, verified bool) {
+	wn.peersLock.RLock()
+	defer wn.peersLock.RUnlock()
+	verified = true
+	for _, peer := range wn.peers {
+		ids = append(ids, peer.identity)
+		verified = verified && peer.identityVerified
+	}
+	return
+}
+
+func waitIdentified(t *testing.T, wn *WebsocketNetwork, expected crypto.PublicKey) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		ids, verified := identifiedPeers(wn)
+		if verified && len(ids) == 1 && ids[0] == expected {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	ids, verified := identifiedPeers(wn)
+	t.Fatalf("peers not identified as %s: %v (verified %v)", IdentityString(expected), ids, verified)
+}
+
+func TestWebsocketNetworkIdentity(t *testing.T) {
+	netA := makeTestWebsocketNode(t)
+	netA.config.GossipFanout = 1
+	netA.Start()
+	defer func() { t.Log("stopping A"); netA.Stop(); t.Log("A done") }()
+
+	netB := makeTestWebsocketNode(t)
+	netB.config.GossipFanout = 1
+	addrA, postListen := netA.Address()
+	require.True(t, postListen)
+	netB.phonebook = &oneEntryPhonebook{addrA}
+	netB.Start()
+	defer func() { t.Log("stopping B"); netB.Stop(); t.Log("B done") }()
+
+	waitIdentified(t, netA, netB.Identity())
+	waitIdentified(t, netB, netA.Identity())
+}
+
+func TestWebsocketNetworkDuplicateIdentity(t *testing.T) {
+	phonebookA := &settablePhonebook{}
+	netA := makeTestWebsocketNode(t)
+	netA.config.GossipFanout = 1
+	netA.phonebook = phonebookA
+	netA.Start()
+	defer func() { t.Log("stopping A"); netA.Stop(); t.Log("A done") }()
+
+	netB := makeTestWebsocketNode(t)
+	netB.config.GossipFanout = 1
+	addrA, postListen := netA.Address()
+	require.True(t, postListen)
+	netB.phonebook = &oneEntryPhonebook{addrA}
+	netB.Start()
+	defer func() { t.Log("stopping B"); netB.Stop(); t.Log("B done") }()
+
+	// A dials B too, making a second connection between them
+	addrB, postListen := netB.Address()
+	require.True(t, postListen)
+	phonebookA.set(addrB)
+	netA.RequestConnectOutgoing(false, nil)
+
+	// both keep one connection, dialed by the node with the smaller key
+	waitIdentified(t, netA, netB.Identity())
+	waitIdentified(t, netB, netA.Identity())
+}
+
+// proveIdentity runs the identity handshake of dialer connecting to
+// acceptor, and returns the proof message that dialer sends.
+func proveIdentity(t *testing.T, dialer, acceptor *WebsocketNetwork) (claimed crypto.PublicKey, expected identityProof, message []byte) {
+	requestHeader := make(http.Header)
+	responseHeader := make(http.Header)
+	challenge := dialer.attachIdentityChallenge(requestHeader)
+	claimed, expected, ok := acceptor.replyToIdentityChallenge(requestHeader, responseHeader)
+	require.True(t, ok)
+	key, proof, err := dialer.verifyIdentityReply(responseHeader, challenge)
+	require.NoError(t, err)
+	require.Equal(t, acceptor.Identity(), key)
+	message = dialer.identityProofMessage(proof)
+	return claimed, expected, message[len(protocol.NetIdentityTag):]
+}
+
+func TestIdentityProof(t *testing.T) {
+	victim := makeTestWebsocketNode(t)
+	honest := makeTestWebsocketNode(t)
+	mallory := makeTestWebsocketNode(t)
+
+	claimed, expected, proof := proveIdentity(t, honest, victim)
+	require.Equal(t, honest.Identity(), claimed)
+	require.True(t, verifyIdentityProof(proof, claimed, expected))
+
+	// Mallory dials the victim claiming the honest node's key...
+	requestHeader := make(http.Header)
+	responseHeader := make(http.Header)
+	c := identityChallenge{Key: honest.Identity(), Challenge: newIdentityChallengeValue()}
+	requestHeader.Set(IdentityChallengeHeader, base64.StdEncoding.EncodeToString(protocol.Encode(c)))
+	claimed, expected, ok := victim.replyToIdentityChallenge(requestHeader, responseHeader)
+	require.True(t, ok)
+	require.Equal(t, honest.Identity(), claimed)
+
+	// ...and, when the honest node dials Mallory, hands it the victim's
+	// challenge to sign.
+	requestHeader = make(http.Header)
+	responseHeader = make(http.Header)
+	honestChallenge := honest.attachIdentityChallenge(requestHeader)
+	reply := identityReply{
+		Key:               mallory.Identity(),
+		Challenge:         honestChallenge,
+		ResponseChallenge: expected.ResponseChallenge,
+	}
+	signed := identityReplySigned{Reply: reply, Signature: mallory.identity.Sign(reply)}
+	responseHeader.Set(IdentityChallengeResponseHeader, base64.StdEncoding.EncodeToString(protocol.Encode(signed)))
+	_, relayed, err := honest.verifyIdentityReply(responseHeader, honestChallenge)
+	require.NoError(t, err)
+	message := honest.identityProofMessage(relayed)
+
+	// The relayed proof is for Mallory's connection, not the victim's.
+	require.False(t, verifyIdentityProof(message[len(protocol.NetIdentityTag):], claimed, expected))
+
+	// Nor does a proof meant for the victim work twice.
+	_, expected, _ = proveIdentity(t, honest, victim)
+	require.False(t, verifyIdentityProof(proof, honest.Identity(), expected))
+}
+
+func TestPriorityPeersByIdentity(t *testing.T) {
+	wn := makeTestWebsocketNode(t)
+	peer := &wsPeer{wsPeerCore: wsPeerCore{originAddress: "10.0.0.1"}}
+	var seed crypto.Seed
+	crypto.RandBytes(seed[:])
+	identity := crypto.GenerateSignatureSecrets(seed).SignatureVerifier
+
+	wn.config.PriorityPeers = map[string]bool{IdentityString(identity): true}
+	require.False(t, checkPrioPeers(wn, peer))
+
+	// a claimed key does not count until it is proven
+	peer.identity = identity
+	require.False(t, checkPrioPeers(wn, peer))
+
+	peer.identityVerified = true
+	require.True(t, checkPrioPeers(wn, peer))
+
+	wn.config.PriorityPeers = map[string]bool{"10.0.0.1": true}
+	require.True(t, checkPrioPeers(wn, peer))
+}
+
+func TestLoadIdentity(t *testing.T) {
+	dir, err := ioutil.TempDir("", "identity")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "peer_identity.key")
+
+	first, err := LoadIdentity(filename)
+	require.NoError(t, err)
+	second, err := LoadIdentity(filename)
+	require.NoError(t, err)
+	require.Equal(t, first.SignatureVerifier, second.SignatureVerifier)
+
+	err = ioutil.WriteFile(filename, []byte("short"), 0600)
+	require.NoError(t, err)
+	_, err = LoadIdentity(filename)
+	require.Error(t, err)
+}
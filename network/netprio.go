@@ -19,6 +19,7 @@ package network
 import (
 	"container/heap"
 
+	"github.com/algorand/go-algorand/crypto"
 	"github.com/algorand/go-algorand/data/basics"
 	"github.com/algorand/go-algorand/protocol"
 )
@@ -75,16 +76,29 @@ type prioTracker struct {
 	// this map under its peerAddress.
 	peerByAddress map[basics.Address]*wsPeer
 
+	// Priority last proven by each peer identity, so that a node
+	// keeps its weight when it reconnects.  There is at most one
+	// identity per address, found through identityByAddress.
+	prioByIdentity    map[crypto.PublicKey]identityPrio
+	identityByAddress map[basics.Address]crypto.PublicKey
+
 	wn *WebsocketNetwork
 }
 
 func newPrioTracker(wn *WebsocketNetwork) *prioTracker {
 	return &prioTracker{
-		peerByAddress: make(map[basics.Address]*wsPeer),
-		wn:            wn,
+		peerByAddress:     make(map[basics.Address]*wsPeer),
+		prioByIdentity:    make(map[crypto.PublicKey]identityPrio),
+		identityByAddress: make(map[basics.Address]crypto.PublicKey),
+		wn:                wn,
 	}
 }
 
+type identityPrio struct {
+	addr   basics.Address
+	weight uint64
+}
+
 func (pt *prioTracker) setPriority(peer *wsPeer, addr basics.Address, weight uint64) {
 	wn := pt.wn
 
@@ -125,6 +139,57 @@ func (pt *prioTracker) setPriority(peer *wsPeer, addr basics.Address, weight uin
 	peer.prioAddress = addr
 	peer.prioWeight = weight
 	heap.Fix(peersHeap{wn}, peer.peerIndex)
+
+	if peer.identityVerified {
+		pt.rememberPriority(peer)
+	}
+}
+
+// rememberPriority records the priority of a peer under its identity.
+// Only weighted identities are kept, one per address, which bounds the
+// records by the number of online accounts.
+func (pt *prioTracker) rememberPriority(peer *wsPeer) {
+	if peer.prioWeight == 0 {
+		prio, ok := pt.prioByIdentity[peer.identity]
+		if ok && prio.addr == peer.prioAddress {
+			pt.forgetPriority(peer.identity)
+		}
+		return
+	}
+
+	pt.forgetPriority(peer.identity)
+	old, ok := pt.identityByAddress[peer.prioAddress]
+	if ok {
+		pt.forgetPriority(old)
+	}
+	pt.prioByIdentity[peer.identity] = identityPrio{addr: peer.prioAddress, weight: peer.prioWeight}
+	pt.identityByAddress[peer.prioAddress] = peer.identity
+}
+
+func (pt *prioTracker) forgetPriority(identity crypto.PublicKey) {
+	prio, ok := pt.prioByIdentity[identity]
+	if !ok {
+		return
+	}
+	delete(pt.prioByIdentity, identity)
+	if pt.identityByAddress[prio.addr] == identity {
+		delete(pt.identityByAddress, prio.addr)
+	}
+}
+
+// restorePriority gives a peer that just proved its identity the
+// priority that identity last proved, unless the peer has proven
+// one of its own on this connection.
+func (pt *prioTracker) restorePriority(peer *wsPeer) {
+	if peer.prioWeight != 0 {
+		pt.rememberPriority(peer)
+		return
+	}
+
+	prio, ok := pt.prioByIdentity[peer.identity]
+	if ok {
+		pt.setPriority(peer, prio.addr, prio.weight)
+	}
 }
 
 func (pt *prioTracker) removePeer(peer *wsPeer) {
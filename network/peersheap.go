@@ -78,6 +78,10 @@ func checkPrioPeers(wn *WebsocketNetwork, wp *wsPeer) bool {
 		return false
 	}
 
+	if wp.identityVerified && pp[IdentityString(wp.identity)] {
+		return true
+	}
+
 	addr := wp.OriginAddress()
 	if addr == "" {
 		return false
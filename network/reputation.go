@@ -0,0 +1,308 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package network
+
+import (
+	"net"
+	"sort"
+	"time"
+
+	"github.com/algorand/go-deadlock"
+
+	"github.com/algorand/go-algorand/util/metrics"
+)
+
+// Peers lose reputation when they misbehave: when a handler reports an
+// invalid message from them, when they violate the wire protocol, or when
+// they are too slow to take the messages we send them.  Reputation comes
+// back slowly over time.  A peer whose reputation runs out is banned for a
+// while, and each further ban lasts twice as long as the previous one.
+//
+// Reputation is kept per identity key for peers that proved their identity,
+// and per remote host for other peers, so that it carries across
+// connections.  Peers whose host may be shared with other nodes (loopback,
+// link-local and private addresses, e.g. behind a NAT or on the same
+// machine) and that did not prove their identity are never banned, since
+// the ban would cut off every node on that host.
+
+const (
+	reputationMax = 100
+
+	// reputationRecoveryInterval is how long it takes a peer to win back
+	// one point of reputation.
+	reputationRecoveryInterval = time.Minute
+
+	penaltyInvalidMessage    = 30
+	penaltyProtocolViolation = 50
+	penaltySlowWrite         = 10
+
+	banDurationMin = 10 * time.Minute
+	banDurationMax = 24 * time.Hour
+
+	// reputationMaxTracked bounds the number of hosts and identities we
+	// keep reputation records for; past it, fully recovered records are
+	// forgotten.
+	reputationMaxTracked = 10000
+)
+
+// sharedHostNets are the private address ranges that may hide several
+// nodes behind one host.
+var sharedHostNets = parseCIDRs("10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "100.64.0.0/10", "fc00::/7")
+
+var networkPeersBanned = metrics.MakeCounter(metrics.MetricName{Name: "algod_network_peers_banned_total", Description: "number of peer bans"})
+
+// disconnectPenalties gives the reputation a peer loses when we drop it for
+// each reason.  Reasons that are not listed say nothing about the peer.
+var disconnectPenalties = map[disconnectReason]int{
+	disconnectBadData:     penaltyInvalidMessage,
+	disconnectBadIdentity: penaltyProtocolViolation,
+	disconnectTooSlow:     penaltySlowWrite,
+	disconnectSlowConn:    penaltySlowWrite,
+}
+
+// PeerBan describes a host or peer identity we refuse to talk to until the
+// ban expires.
+type PeerBan struct {
+	// Peer is the host name or address, or the identity key of the peer.
+	Peer string
+
+	// Reason is the misbehavior that cost the peer its last reputation.
+	Reason string
+
+	// Expires is when the ban is lifted.
+	Expires time.Time
+
+	// Bans counts the times this peer was banned, including this one.
+	Bans int
+}
+
+type peerReputation struct {
+	score   int
+	updated time.Time
+	bans    int
+}
+
+// reputationTracker holds the reputation of peers and the active bans.
+// It is safe for concurrent use.
+type reputationTracker struct {
+	lock        deadlock.Mutex
+	reputations map[string]*peerReputation
+	bans        map[string]PeerBan
+
+	// now is time.Now, except in tests
+	now func() time.Time
+}
+
+func makeReputationTracker() *reputationTracker {
+	return &reputationTracker{
+		reputations: make(map[string]*peerReputation),
+		bans:        make(map[string]PeerBan),
+		now:         time.Now,
+	}
+}
+
+// penalize takes penalty points of reputation from key.  If that leaves
+// key with no reputation, key is banned and penalize returns the ban.
+func (rt *reputationTracker) penalize(key string, penalty int, reason string) (ban PeerBan, banned bool) {
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+
+	now := rt.now()
+	rep := rt.reputations[key]
+	if rep == nil {
+		if len(rt.reputations) >= reputationMaxTracked {
+			rt.prune(now)
+		}
+		rep = &peerReputation{score: reputationMax, updated: now}
+		rt.reputations[key] = rep
+	}
+	rep.recover(now)
+	rep.score -= penalty
+	if rep.score > 0 {
+		return
+	}
+
+	duration := banDurationMax
+	if rep.bans < 16 && banDurationMin<<uint(rep.bans) < banDurationMax {
+		duration = banDurationMin << uint(rep.bans)
+	}
+	rep.bans++
+	rep.score = reputationMax
+	ban = PeerBan{Peer: key, Reason: reason, Expires: now.Add(duration), Bans: rep.bans}
+	rt.bans[key] = ban
+	return ban, true
+}
+
+// recover gives back the reputation earned since the last update.
+func (rep *peerReputation) recover(now time.Time) {
+	earned := int(now.Sub(rep.updated) / reputationRecoveryInterval)
+	if earned <= 0 {
+		return
+	}
+	rep.score += earned
+	if rep.score > reputationMax {
+		rep.score = reputationMax
+	}
+	rep.updated = rep.updated.Add(time.Duration(earned) * reputationRecoveryInterval)
+}
+
+// prune forgets the records of peers that are fully recovered and not banned.
+func (rt *reputationTracker) prune(now time.Time) {
+	for key, rep := range rt.reputations {
+		rep.recover(now)
+		_, banned := rt.bans[key]
+		if rep.score >= reputationMax && !banned {
+			delete(rt.reputations, key)
+		}
+	}
+}
+
+// banned reports whether any of keys is banned.
+func (rt *reputationTracker) banned(keys ...string) bool {
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+
+	now := rt.now()
+	for _, key := range keys {
+		ban, ok := rt.bans[key]
+		if !ok {
+			continue
+		}
+		if now.Before(ban.Expires) {
+			return true
+		}
+		delete(rt.bans, key)
+	}
+	return false
+}
+
+// list returns the active bans, soonest to expire first.
+func (rt *reputationTracker) list() []PeerBan {
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+
+	now := rt.now()
+	bans := make([]PeerBan, 0, len(rt.bans))
+	for key, ban := range rt.bans {
+		if !now.Before(ban.Expires) {
+			delete(rt.bans, key)
+			continue
+		}
+		bans = append(bans, ban)
+	}
+	sort.Slice(bans, func(i, j int) bool { return bans[i].Expires.Before(bans[j].Expires) })
+	return bans
+}
+
+// peerHost returns the host a peer connects from or, for peers we dialed,
+// the host we dialed.
+func peerHost(peer *wsPeer) string {
+	host := peer.OriginAddress()
+	if host != "" {
+		return host
+	}
+	parsedURL, err := ParseHostOrURL(peer.rootURL)
+	if err != nil {
+		return peer.rootURL
+	}
+	return parsedURL.Hostname()
+}
+
+// reputationKeys returns the keys the reputation of peer is kept under,
+// which is none if the peer cannot be told apart from other nodes.
+// The caller must hold wn.peersLock.
+func reputationKeys(peer *wsPeer) []string {
+	if peer.identityVerified {
+		return []string{IdentityString(peer.identity)}
+	}
+	host := peerHost(peer)
+	if sharedHost(host) {
+		return nil
+	}
+	return []string{host}
+}
+
+// sharedHost reports whether host is a loopback, link-local or private
+// address, which other nodes may share.
+func sharedHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsUnspecified() {
+		return true
+	}
+	for _, ipnet := range sharedHostNets {
+		if ipnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, ipnet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets[i] = ipnet
+	}
+	return nets
+}
+
+// penalizePeer takes penalty points of reputation from peer, and bans it if
+// it has none left.  Priority peers are never banned.
+func (wn *WebsocketNetwork) penalizePeer(peer *wsPeer, penalty int, reason string) {
+	if wn.config.DisablePeerBans {
+		return
+	}
+
+	wn.peersLock.RLock()
+	exempt := checkPrioPeers(wn, peer)
+	keys := reputationKeys(peer)
+	wn.peersLock.RUnlock()
+	if exempt || len(keys) == 0 {
+		return
+	}
+
+	for _, key := range keys {
+		ban, banned := wn.reputation.penalize(key, penalty, reason)
+		if banned {
+			networkPeersBanned.Inc(nil)
+			wn.log.Warnf("banning peer %s until %v: %s", ban.Peer, ban.Expires, reason)
+		}
+	}
+}
+
+// hostBanned reports whether the host of a peer address is banned.
+func (wn *WebsocketNetwork) hostBanned(addr string) bool {
+	parsedURL, err := ParseHostOrURL(addr)
+	if err != nil {
+		return false
+	}
+	return wn.reputation.banned(parsedURL.Hostname())
+}
+
+// BannedPeers returns the hosts and peer identities that are currently banned.
+func (wn *WebsocketNetwork) BannedPeers() []PeerBan {
+	return wn.reputation.list()
+}
@@ -0,0 +1,131 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/go-algorand/crypto"
+)
+
+func TestReputationBan(t *testing.T) {
+	now := time.Now()
+	rt := makeReputationTracker()
+	rt.now = func() time.Time { return now }
+
+	// three invalid messages in a row are forgiven, the fourth is not
+	for i := 0; i < 3; i++ {
+		_, banned := rt.penalize("10.0.0.1", penaltyInvalidMessage, "bad data")
+		require.False(t, banned)
+	}
+	ban, banned := rt.penalize("10.0.0.1", penaltyInvalidMessage, "bad data")
+	require.True(t, banned)
+	require.Equal(t, "10.0.0.1", ban.Peer)
+	require.Equal(t, now.Add(banDurationMin), ban.Expires)
+	require.True(t, rt.banned("10.0.0.2", "10.0.0.1"))
+	require.False(t, rt.banned("10.0.0.2"))
+	require.Equal(t, []PeerBan{ban}, rt.list())
+
+	now = now.Add(banDurationMin)
+	require.False(t, rt.banned("10.0.0.1"))
+	require.Empty(t, rt.list())
+
+	// the next ban lasts twice as long
+	_, banned = rt.penalize("10.0.0.1", reputationMax, "bad data")
+	require.True(t, banned)
+	ban = rt.list()[0]
+	require.Equal(t, 2, ban.Bans)
+	require.Equal(t, now.Add(2*banDurationMin), ban.Expires)
+}
+
+func TestReputationRecovery(t *testing.T) {
+	now := time.Now()
+	rt := makeReputationTracker()
+	rt.now = func() time.Time { return now }
+
+	_, banned := rt.penalize("10.0.0.1", reputationMax-1, "slow")
+	require.False(t, banned)
+
+	// reputation comes back over time, but never above the maximum
+	now = now.Add(1000 * reputationRecoveryInterval)
+	_, banned = rt.penalize("10.0.0.1", reputationMax-1, "slow")
+	require.False(t, banned)
+
+	now = now.Add(reputationRecoveryInterval / 2)
+	_, banned = rt.penalize("10.0.0.1", 1, "slow")
+	require.True(t, banned)
+}
+
+func TestWebsocketNetworkBannedHost(t *testing.T) {
+	netA := makeTestWebsocketNode(t)
+	netA.config.GossipFanout = 1
+	netA.Start()
+	defer func() { t.Log("stopping A"); netA.Stop(); t.Log("A done") }()
+	// misbehaving peers never get loopback hosts banned, but a ban on
+	// record is enforced whatever the host
+	_, banned := netA.reputation.penalize("127.0.0.1", reputationMax, "test")
+	require.True(t, banned)
+
+	netB := makeTestWebsocketNode(t)
+	netB.config.GossipFanout = 1
+	addrA, postListen := netA.Address()
+	require.True(t, postListen)
+	netB.phonebook = &oneEntryPhonebook{addrA}
+	netB.Start()
+	defer func() { t.Log("stopping B"); netB.Stop(); t.Log("B done") }()
+
+	time.Sleep(500 * time.Millisecond)
+	require.Equal(t, 0, netA.NumPeers())
+	require.Equal(t, 0, netB.NumPeers())
+	require.Len(t, netA.BannedPeers(), 1)
+}
+
+func TestReputationKeys(t *testing.T) {
+	for _, host := range []string{"localhost", "127.0.0.1", "::1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "100.64.0.1", "169.254.0.1", "fd00::1", "fe80::1"} {
+		peer := &wsPeer{wsPeerCore: wsPeerCore{originAddress: host}}
+		require.Empty(t, reputationKeys(peer), host)
+	}
+	for _, host := range []string{"8.8.8.8", "172.32.0.1", "2001:db8::1", "relay.example.com"} {
+		peer := &wsPeer{wsPeerCore: wsPeerCore{originAddress: host}}
+		require.Equal(t, []string{host}, reputationKeys(peer))
+	}
+
+	// a peer that proved its identity is known by it alone, wherever it connects from
+	var seed crypto.Seed
+	crypto.RandBytes(seed[:])
+	identity := crypto.GenerateSignatureSecrets(seed).SignatureVerifier
+	for _, host := range []string{"127.0.0.1", "8.8.8.8"} {
+		peer := &wsPeer{wsPeerCore: wsPeerCore{originAddress: host}, identity: identity, identityVerified: true}
+		require.Equal(t, []string{IdentityString(identity)}, reputationKeys(peer))
+	}
+}
+
+func TestPenalizeSharedHost(t *testing.T) {
+	wn := makeTestWebsocketNode(t)
+
+	local := &wsPeer{wsPeerCore: wsPeerCore{originAddress: "127.0.0.1"}}
+	wn.penalizePeer(local, reputationMax, "test")
+	require.Empty(t, wn.BannedPeers())
+
+	remote := &wsPeer{wsPeerCore: wsPeerCore{originAddress: "8.8.8.8"}}
+	wn.penalizePeer(remote, reputationMax, "test")
+	require.Len(t, wn.BannedPeers(), 1)
+	require.Equal(t, "8.8.8.8", wn.BannedPeers()[0].Peer)
+}
@@ -0,0 +1,139 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package network
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/algorand/go-algorand/config"
+)
+
+var errMutualTLSConfig = errors.New("RequireMutualTLS needs TLSCertFile, TLSKeyFile and TLSCACertFile")
+
+// makeTLSConfigs builds the TLS configurations for serving and for dialing
+// peers.  Either may be nil: serverConfig when we do not serve TLS, and
+// clientConfig when we only dial plain connections by default.
+func makeTLSConfigs(cfg config.Local) (serverConfig *tls.Config, clientConfig *tls.Config, err error) {
+	var certs []tls.Certificate
+	if cfg.TLSCertFile != "" && cfg.TLSKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not load TLS certificate: %v", err)
+		}
+		certs = []tls.Certificate{cert}
+	}
+
+	var roots *x509.CertPool
+	if cfg.TLSCACertFile != "" {
+		pem, err := ioutil.ReadFile(cfg.TLSCACertFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not read TLS CA certificates: %v", err)
+		}
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, nil, fmt.Errorf("no certificates found in %s", cfg.TLSCACertFile)
+		}
+	}
+
+	if cfg.RequireMutualTLS && (certs == nil || roots == nil) {
+		return nil, nil, errMutualTLSConfig
+	}
+
+	if certs != nil {
+		serverConfig = &tls.Config{
+			Certificates: certs,
+			MinVersion:   tls.VersionTLS12,
+		}
+		if roots != nil {
+			serverConfig.ClientCAs = roots
+			serverConfig.ClientAuth = tls.VerifyClientCertIfGiven
+			if cfg.RequireMutualTLS {
+				serverConfig.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+		}
+	}
+
+	if cfg.EnableOutgoingTLS || cfg.RequireMutualTLS {
+		clientConfig = &tls.Config{
+			Certificates: certs,
+			RootCAs:      roots,
+			MinVersion:   tls.VersionTLS12,
+		}
+	}
+	return serverConfig, clientConfig, nil
+}
+
+// setupTLS loads the TLS configuration of the network.  It must be called
+// after setup and before Start.
+func (wn *WebsocketNetwork) setupTLS() error {
+	serverConfig, clientConfig, err := makeTLSConfigs(wn.config)
+	if err != nil {
+		return err
+	}
+
+	wn.serverTLS = serverConfig
+	wn.server.TLSConfig = serverConfig
+	wn.clientTLS = clientConfig
+	wn.dialer.TLSClientConfig = clientConfig
+	if clientConfig != nil {
+		wn.httpTransport = &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+			TLSHandshakeTimeout: 10 * time.Second,
+			TLSClientConfig:     clientConfig,
+		}
+	}
+	return nil
+}
+
+// peerRootURL returns the root URL at which we reach a peer listed at addr.
+// When we dial TLS by default, "host:port" addresses are reached over https,
+// and when we require mutual TLS, so are plain http URLs.
+func (wn *WebsocketNetwork) peerRootURL(addr string) string {
+	if wn.clientTLS == nil {
+		return addr
+	}
+	if HostColonPortPattern.MatchString(addr) {
+		return "https://" + addr
+	}
+	if wn.config.RequireMutualTLS && strings.HasPrefix(addr, "http://") {
+		return "https://" + strings.TrimPrefix(addr, "http://")
+	}
+	return addr
+}
+
+// makePeerCore returns the wsPeerCore of a peer whose root URL is rootURL.
+func (wn *WebsocketNetwork) makePeerCore(rootURL string) wsPeerCore {
+	return wsPeerCore{
+		net:     wn,
+		rootURL: rootURL,
+		client:  http.Client{Transport: wn.httpTransport},
+	}
+}
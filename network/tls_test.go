@@ -0,0 +1,199 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package network
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testCA struct {
+	t    *testing.T
+	dir  string
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func makeTestCA(t *testing.T, dir string) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test network CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	ca := &testCA{t: t, dir: dir, cert: cert, key: key}
+	ca.writePEM("ca.pem", "CERTIFICATE", der)
+	return ca
+}
+
+func (ca *testCA) writePEM(name, blockType string, der []byte) string {
+	path := filepath.Join(ca.dir, name)
+	err := ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600)
+	require.NoError(ca.t, err)
+	return path
+}
+
+// issue returns the certificate and key files of a new certificate for name,
+// valid for 127.0.0.1 as both a server and a client.
+func (ca *testCA) issue(name string, serial int64) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(ca.t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(ca.t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(ca.t, err)
+	return ca.writePEM(name+".pem", "CERTIFICATE", der), ca.writePEM(name+".key", "EC PRIVATE KEY", keyDer)
+}
+
+func TestMakeTLSConfigs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	ca := makeTestCA(t, dir)
+	certFile, keyFile := ca.issue("node", 2)
+
+	conf := defaultConfig
+	server, client, err := makeTLSConfigs(conf)
+	require.NoError(t, err)
+	require.Nil(t, server)
+	require.Nil(t, client)
+
+	conf.RequireMutualTLS = true
+	conf.TLSCertFile = certFile
+	conf.TLSKeyFile = keyFile
+	_, _, err = makeTLSConfigs(conf)
+	require.Equal(t, errMutualTLSConfig, err)
+
+	conf.TLSCACertFile = filepath.Join(dir, "ca.pem")
+	server, client, err = makeTLSConfigs(conf)
+	require.NoError(t, err)
+	require.NotNil(t, server)
+	require.NotNil(t, client)
+	require.Len(t, client.Certificates, 1)
+
+	wn := makeTestWebsocketNodeWithConfig(t, conf)
+	require.NoError(t, wn.setupTLS())
+	require.Equal(t, "https://r1.example.com:4160", wn.peerRootURL("r1.example.com:4160"))
+	require.Equal(t, "https://r1.example.com:4160", wn.peerRootURL("http://r1.example.com:4160"))
+	_, err = wn.addrToGossipAddr("ws://r1.example.com:4160")
+	require.Equal(t, errBadAddr, err)
+
+	conf.RequireMutualTLS = false
+	conf.EnableOutgoingTLS = true
+	wn = makeTestWebsocketNodeWithConfig(t, conf)
+	require.NoError(t, wn.setupTLS())
+	require.Equal(t, "https://r1.example.com:4160", wn.peerRootURL("r1.example.com:4160"))
+	require.Equal(t, "http://r1.example.com:4160", wn.peerRootURL("http://r1.example.com:4160"))
+}
+
+// Two nodes requiring mutual TLS talk to each other, and a node without a
+// client certificate cannot connect to them.
+func TestWebsocketNetworkMutualTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	ca := makeTestCA(t, dir)
+
+	makeNode := func(name string, serial int64) *WebsocketNetwork {
+		conf := defaultConfig
+		conf.GossipFanout = 1
+		conf.RequireMutualTLS = true
+		conf.TLSCACertFile = filepath.Join(dir, "ca.pem")
+		conf.TLSCertFile, conf.TLSKeyFile = ca.issue(name, serial)
+		wn := makeTestWebsocketNodeWithConfig(t, conf)
+		require.NoError(t, wn.setupTLS())
+		return wn
+	}
+
+	netA := makeNode("relay", 2)
+	netA.Start()
+	defer netA.Stop()
+	addrA, postListen := netA.Address()
+	require.True(t, postListen)
+	require.True(t, strings.HasPrefix(addrA, "https://"))
+
+	netB := makeNode("node", 3)
+	netB.phonebook = &oneEntryPhonebook{strings.TrimPrefix(addrA, "https://")}
+	netB.Start()
+	defer netB.Stop()
+	counter := newMessageCounter(t, 1)
+	counterDone := counter.done
+	netB.RegisterHandlers([]TaggedMessageHandler{TaggedMessageHandler{Tag: debugTag, MessageHandler: counter}})
+
+	readyTimeout := time.NewTimer(2 * time.Second)
+	waitReady(t, netA, readyTimeout.C)
+	waitReady(t, netB, readyTimeout.C)
+
+	netA.Broadcast(context.Background(), debugTag, []byte("foo"), false, nil)
+	select {
+	case <-counterDone:
+	case <-time.After(2 * time.Second):
+		t.Errorf("timeout, count=%d, wanted 1", counter.count)
+	}
+
+	peers := netB.GetPeers(PeersConnectedOut)
+	require.Len(t, peers, 1)
+	hpeer := peers[0].(HTTPPeer)
+	require.Equal(t, addrA, hpeer.GetAddress())
+	response, err := hpeer.GetHTTPClient().Get(addrA)
+	require.NoError(t, err)
+	response.Body.Close()
+
+	// Trusting the CA is not enough without a client certificate
+	conf := defaultConfig
+	conf.EnableOutgoingTLS = true
+	conf.TLSCACertFile = filepath.Join(dir, "ca.pem")
+	netC := makeTestWebsocketNodeWithConfig(t, conf)
+	require.NoError(t, netC.setupTLS())
+	_, err = (&http.Client{Transport: netC.httpTransport}).Get(addrA)
+	require.Error(t, err)
+}
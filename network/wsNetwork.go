@@ -19,6 +19,7 @@ package network
 import (
 	"container/heap"
 	"context"
+	"crypto/tls"
 	"encoding/base64"
 	"errors"
 	"fmt"
@@ -257,6 +258,13 @@ type WebsocketNetwork struct {
 	router   *mux.Router
 	scheme   string // are we serving http or https ?
 
+	// serverTLS is the TLS configuration we serve with, if any, and
+	// clientTLS the one we dial peers with by default, if any.
+	serverTLS     *tls.Config
+	clientTLS     *tls.Config
+	dialer        websocket.Dialer
+	httpTransport http.RoundTripper
+
 	upgrader websocket.Upgrader
 
 	config config.Local
@@ -307,6 +315,14 @@ type WebsocketNetwork struct {
 	prioTracker      *prioTracker
 	prioResponseChan chan *wsPeer
 
+	// identity is the key this node proves to its peers, and
+	// peersByIdentity holds the connected peers that proved theirs.
+	// peersByIdentity is protected by peersLock.
+	identity        *crypto.SignatureSecrets
+	peersByIdentity map[crypto.PublicKey]*wsPeer
+
+	reputation *reputationTracker
+
 	// once we detect that we have a misconfigured UseForwardedForAddress, we set this and write an warning message.
 	misconfiguredUseForwardedForAddress bool
 
@@ -423,6 +439,9 @@ func (wn *WebsocketNetwork) disconnect(badnode Peer, reason disconnectReason) {
 		return
 	}
 	peer := badnode.(*wsPeer)
+	if penalty, ok := disconnectPenalties[reason]; ok {
+		wn.penalizePeer(peer, penalty, string(reason))
+	}
 	peer.CloseAndWait()
 	wn.removePeer(peer, reason)
 }
@@ -494,7 +513,8 @@ func (wn *WebsocketNetwork) GetPeers(options ...PeerOption) []Peer {
 			var addrs []string
 			addrs = wn.phonebook.GetAddresses(1000)
 			for _, addr := range addrs {
-				outPeers = append(outPeers, &wsPeerCore{net: wn, rootURL: addr})
+				peerCore := wn.makePeerCore(wn.peerRootURL(addr))
+				outPeers = append(outPeers, &peerCore)
 			}
 		case PeersConnectedIn:
 			wn.peersLock.RLock()
@@ -515,6 +535,7 @@ func (wn *WebsocketNetwork) setup() {
 	wn.upgrader.EnableCompression = false
 	wn.router = mux.NewRouter()
 	wn.router.Handle(GossipNetworkPath, wn)
+	wn.dialer = websocketDialer
 	wn.server.Handler = wn.router
 	wn.server.ReadHeaderTimeout = httpServerReadHeaderTimeout
 	wn.server.WriteTimeout = httpServerWriteTimeout
@@ -538,6 +559,13 @@ func (wn *WebsocketNetwork) setup() {
 	wn.tryConnectAddrs = make(map[string]int64)
 	wn.eventualReadyDelay = time.Minute
 	wn.prioTracker = newPrioTracker(wn)
+	wn.peersByIdentity = make(map[crypto.PublicKey]*wsPeer)
+	wn.reputation = makeReputationTracker()
+	if wn.identity == nil {
+		var seed crypto.Seed
+		crypto.RandBytes(seed[:])
+		wn.identity = crypto.GenerateSignatureSecrets(seed)
+	}
 	if wn.slowWritingPeerMonitorInterval == 0 {
 		wn.slowWritingPeerMonitorInterval = slowWritingPeerMonitorInterval
 	}
@@ -621,7 +649,7 @@ func (wn *WebsocketNetwork) Start() {
 		wn.listener = netutil.LimitListener(listener, wn.config.IncomingConnectionsLimit)
 		wn.log.Debugf("listening on %s", wn.listener.Addr().String())
 	}
-	if wn.config.TLSCertFile != "" && wn.config.TLSKeyFile != "" {
+	if wn.serverTLS != nil {
 		wn.scheme = "https"
 	} else {
 		wn.scheme = "http"
@@ -629,6 +657,7 @@ func (wn *WebsocketNetwork) Start() {
 	wn.meshUpdateRequests <- meshRequest{false, nil}
 	wn.RegisterHandlers(pingHandlers)
 	wn.RegisterHandlers(prioHandlers)
+	wn.RegisterHandlers(identityHandlers)
 	if wn.listener != nil {
 		wn.wg.Add(1)
 		go wn.httpdThread()
@@ -650,13 +679,15 @@ func (wn *WebsocketNetwork) Start() {
 	wn.wg.Add(1)
 	go wn.prioWeightRefresh()
 	wn.log.Infof("serving genesisID=%#v on %#v", wn.GenesisID, wn.PublicAddress())
+	wn.log.Infof("peer identity %s", IdentityString(wn.Identity()))
 }
 
 func (wn *WebsocketNetwork) httpdThread() {
 	defer wn.wg.Done()
 	var err error
-	if wn.config.TLSCertFile != "" && wn.config.TLSKeyFile != "" {
-		err = wn.server.ServeTLS(wn.listener, wn.config.TLSCertFile, wn.config.TLSKeyFile)
+	if wn.serverTLS != nil {
+		// certificates come from wn.server.TLSConfig
+		err = wn.server.ServeTLS(wn.listener, "", "")
 	} else {
 		err = wn.server.Serve(wn.listener)
 	}
@@ -843,8 +874,25 @@ func (wn *WebsocketNetwork) ServeHTTP(response http.ResponseWriter, request *htt
 		return
 	}
 
+	if wn.reputation.banned(remoteHost) {
+		networkConnectionsDroppedTotal.Inc(map[string]string{"reason": "banned"})
+		wn.log.EventWithDetails(telemetryspec.Network, telemetryspec.ConnectPeerFailEvent,
+			telemetryspec.ConnectPeerFailEventDetails{
+				Address:      remoteHost,
+				HostName:     request.Header.Get(TelemetryIDHeader),
+				Incoming:     true,
+				InstanceName: request.Header.Get(InstanceNameHeader),
+				Reason:       "Banned",
+			})
+		response.WriteHeader(http.StatusForbidden)
+		return
+	}
+
 	// TODO: rate limit incoming connections. (must wait at least Duration between disconnect and connect? no more than N connect attempts per Duration?)
 	wn.log.Debugf("inbound from %s", request.RemoteAddr)
+	if request.TLS != nil && len(request.TLS.VerifiedChains) > 0 {
+		wn.log.Debugf("inbound from %s authenticated as %s", request.RemoteAddr, request.TLS.VerifiedChains[0][0].Subject)
+	}
 	ok, otherTelemetryGUID, otherPublicAddr, otherInstanceName := wn.checkHeaders(request.Header, request.RemoteAddr, originIP)
 	if !ok {
 		networkConnectionsDroppedTotal.Inc(map[string]string{"reason": "bad header"})
@@ -857,6 +905,7 @@ func (wn *WebsocketNetwork) ServeHTTP(response http.ResponseWriter, request *htt
 		challenge = wn.prioScheme.NewPrioChallenge()
 		requestHeader.Set(PriorityChallengeHeader, challenge)
 	}
+	identityClaim, identityExpected, _ := wn.replyToIdentityChallenge(request.Header, requestHeader)
 	conn, err := wn.upgrader.Upgrade(response, request, requestHeader)
 	if err != nil {
 		wn.log.Info("ws upgrade fail ", err)
@@ -865,17 +914,16 @@ func (wn *WebsocketNetwork) ServeHTTP(response http.ResponseWriter, request *htt
 	}
 
 	peer := &wsPeer{
-		wsPeerCore: wsPeerCore{
-			net:           wn,
-			rootURL:       otherPublicAddr,
-			originAddress: remoteHost,
-		},
+		wsPeerCore:        wn.makePeerCore(wn.peerRootURL(otherPublicAddr)),
 		conn:              conn,
 		outgoing:          false,
 		InstanceName:      otherInstanceName,
 		incomingMsgFilter: wn.incomingMsgFilter,
 		prioChallenge:     challenge,
+		identityClaim:     identityClaim,
+		identityExpected:  identityExpected,
 	}
+	peer.originAddress = remoteHost
 	peer.TelemetryGUID = otherTelemetryGUID
 	peer.init(wn.config, wn.outgoingMessagesBufferSize)
 	wn.addPeer(peer)
@@ -1183,10 +1231,11 @@ func (wn *WebsocketNetwork) meshThread() {
 				if na == wn.config.PublicAddress {
 					continue
 				}
-				gossipAddr, ok := wn.tryConnectReserveAddr(na)
+				addr := wn.peerRootURL(na)
+				gossipAddr, ok := wn.tryConnectReserveAddr(addr)
 				if ok {
 					wn.wg.Add(1)
-					go wn.tryConnect(na, gossipAddr)
+					go wn.tryConnect(addr, gossipAddr)
 					need--
 					if need == 0 {
 						break
@@ -1422,6 +1471,10 @@ func (wn *WebsocketNetwork) addrToGossipAddr(addr string) (string, error) {
 	if parsedURL.Scheme == "" {
 		parsedURL.Scheme = "ws"
 	}
+	if wn.config.RequireMutualTLS && parsedURL.Scheme != "wss" {
+		wn.log.Warnf("not connecting to %#v without TLS: RequireMutualTLS is set", addr)
+		return "", errBadAddr
+	}
 	parsedURL.Path = strings.Replace(path.Join(parsedURL.Path, GossipNetworkPath), "{genesisID}", wn.GenesisID, -1)
 	return parsedURL.String(), nil
 }
@@ -1482,11 +1535,16 @@ func (wn *WebsocketNetwork) tryConnect(addr, gossipAddr string) {
 		}
 	}()
 	defer wn.wg.Done()
+	if wn.hostBanned(addr) {
+		wn.log.Debugf("not connecting to banned peer %s", addr)
+		return
+	}
 	requestHeader := make(http.Header)
 	wn.setHeaders(requestHeader)
 	myInstanceName := wn.log.GetInstanceName()
 	requestHeader.Set(InstanceNameHeader, myInstanceName)
-	conn, response, err := websocketDialer.DialContext(wn.ctx, gossipAddr, requestHeader)
+	identityChallenge := wn.attachIdentityChallenge(requestHeader)
+	conn, response, err := wn.dialer.DialContext(wn.ctx, gossipAddr, requestHeader)
 	if err != nil {
 		wn.log.Warnf("ws connect(%s) fail: %s", gossipAddr, err)
 		return
@@ -1496,11 +1554,33 @@ func (wn *WebsocketNetwork) tryConnect(addr, gossipAddr string) {
 	if !ok {
 		return
 	}
-	peer := &wsPeer{wsPeerCore: wsPeerCore{net: wn, rootURL: addr}, conn: conn, outgoing: true, incomingMsgFilter: wn.incomingMsgFilter}
+	peer := &wsPeer{wsPeerCore: wn.makePeerCore(addr), conn: conn, outgoing: true, incomingMsgFilter: wn.incomingMsgFilter}
 	peer.TelemetryGUID = otherTelemetryGUID
+	identity, identityProof, err := wn.verifyIdentityReply(response.Header, identityChallenge)
+	if err == nil {
+		if wn.reputation.banned(IdentityString(identity)) {
+			wn.log.Debugf("not connecting to banned peer %s", IdentityString(identity))
+			conn.Close()
+			return
+		}
+		if !wn.identifyPeer(peer, identity) {
+			conn.Close()
+			return
+		}
+	} else if err != errNoIdentity {
+		wn.log.Warnf("ws connect(%s) bad identity reply: %v", gossipAddr, err)
+		conn.Close()
+		return
+	}
 	peer.init(wn.config, wn.outgoingMessagesBufferSize)
 	wn.addPeer(peer)
 	localAddr, _ := wn.Address()
+	if err == nil {
+		sent := peer.writeNonBlock(wn.identityProofMessage(identityProof), true, crypto.Digest{}, time.Now())
+		if !sent {
+			wn.log.With("remote", addr).With("local", localAddr).Warnf("could not send identity proof to %v", addr)
+		}
+	}
 	wn.log.With("event", "ConnectedOut").With("remote", addr).With("local", localAddr).Infof("Made outgoing connection to peer %v", addr)
 	wn.log.EventWithDetails(telemetryspec.Network, telemetryspec.ConnectPeerEvent,
 		telemetryspec.PeerEventDetails{
@@ -1534,6 +1614,10 @@ func NewWebsocketNetwork(log logging.Logger, config config.Local, phonebook Phon
 	wn = &WebsocketNetwork{log: log, config: config, phonebook: outerPhonebook, GenesisID: genesisID, NetworkID: networkID}
 
 	wn.setup()
+	err = wn.setupTLS()
+	if err != nil {
+		return nil, err
+	}
 	return wn, nil
 }
 
@@ -1596,6 +1680,9 @@ func (wn *WebsocketNetwork) removePeer(peer *wsPeer, reason disconnectReason) {
 		heap.Remove(peersHeap{wn}, peer.peerIndex)
 		wn.prioTracker.removePeer(peer)
 	}
+	if peer.identityVerified && wn.peersByIdentity[peer.identity] == peer {
+		delete(wn.peersByIdentity, peer.identity)
+	}
 	wn.countPeersSetGauges()
 }
 
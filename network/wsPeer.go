@@ -91,6 +91,9 @@ const disconnectReadError disconnectReason = "ReadError"
 const disconnectWriteError disconnectReason = "WriteError"
 const disconnectIdleConn disconnectReason = "IdleConnection"
 const disconnectSlowConn disconnectReason = "SlowConnection"
+const disconnectDuplicateConnection disconnectReason = "DuplicateConnection"
+const disconnectBadIdentity disconnectReason = "BadIdentity"
+const disconnectBanned disconnectReason = "Banned"
 
 type wsPeer struct {
 	// lastPacketTime contains the UnixNano at the last time a successfull communication was made with the peer.
@@ -144,6 +147,18 @@ type wsPeer struct {
 
 	prioAddress basics.Address
 	prioWeight  uint64
+
+	// Identity key the peer claimed on an incoming connection, and the
+	// proof it must sign to prove it.  identityExpected is protected by
+	// identityLock.
+	identityClaim    crypto.PublicKey
+	identityLock     deadlock.Mutex
+	identityExpected identityProof
+
+	// Identity key the peer has proven to hold, if identityVerified.
+	// Protected by wn.peersLock.
+	identity         crypto.PublicKey
+	identityVerified bool
 }
 
 // HTTPPeer is what the opaque Peer might be.
@@ -264,6 +279,7 @@ func (wp *wsPeer) readLoop() {
 		if mtype != websocket.BinaryMessage {
 			wp.net.log.Errorf("peer sent non websocket-binary message: %#v", mtype)
 			networkConnectionsDroppedTotal.Inc(map[string]string{"reason": "protocol"})
+			wp.net.penalizePeer(wp, penaltyProtocolViolation, "non-binary message")
 			return
 		}
 		var tag [2]byte
@@ -277,6 +293,9 @@ func (wp *wsPeer) readLoop() {
 		err = slurper.Read(reader)
 		if err != nil {
 			wp.reportReadErr(err)
+			if err == ErrIncomingMsgTooLarge {
+				wp.net.penalizePeer(wp, penaltyProtocolViolation, "message too large")
+			}
 			return
 		}
 		msg.processing = wp.processed
@@ -333,6 +352,7 @@ func (wp *wsPeer) handleFilterMessage(msg IncomingMessage) {
 	}
 	if len(msg.Data) != crypto.DigestSize {
 		wp.net.log.Warnf("bad filter message size %d", len(msg.Data))
+		wp.net.penalizePeer(wp, penaltyProtocolViolation, "bad filter message")
 		return
 	}
 	var digest crypto.Digest
@@ -352,6 +372,7 @@ func (wp *wsPeer) writeLoopSend(msg sendMessage) (exit bool) {
 	if msgWaitDuration > maxMessageQueueDuration {
 		wp.net.log.Warnf("peer stale enqueued message %dms", msgWaitDuration.Nanoseconds()/1000000)
 		networkConnectionsDroppedTotal.Inc(map[string]string{"reason": "stale message"})
+		wp.net.penalizePeer(wp, penaltySlowWrite, "stale message")
 		return true
 	}
 	atomic.StoreInt64(&wp.intermittentOutgoingMessageEnqueueTime, msg.enqueued.UnixNano())
@@ -0,0 +1,77 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"github.com/algorand/go-algorand/data/bookkeeping"
+)
+
+// blockSubscriptionBacklog is the number of blocks buffered for each
+// block subscription.  A subscriber that falls further behind than this
+// is dropped, and must subscribe again from the last round it saw.
+const blockSubscriptionBacklog = 64
+
+// BlockSubscription delivers the blocks added to the ledger after it was
+// created, in round order.  C is closed if the subscriber falls too far
+// behind, or when the subscription is closed.
+type BlockSubscription struct {
+	C <-chan bookkeeping.Block
+
+	c      chan bookkeeping.Block
+	stream *blockStream
+}
+
+// Close stops delivery of blocks to the subscription.
+func (s *BlockSubscription) Close() {
+	s.stream.unsubscribe(s)
+}
+
+func (s *BlockSubscription) offer(v interface{}) bool {
+	select {
+	case s.c <- v.(bookkeeping.Block):
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *BlockSubscription) closeChannel() {
+	close(s.c)
+}
+
+// blockStream is a BlockListener that fans out new blocks to block
+// subscriptions, such as the streaming block API.
+type blockStream struct {
+	fanout
+}
+
+func makeBlockStream() *blockStream {
+	return &blockStream{fanout: makeFanout()}
+}
+
+func (bs *blockStream) subscribe() *BlockSubscription {
+	c := make(chan bookkeeping.Block, blockSubscriptionBacklog)
+	sub := &BlockSubscription{C: c, c: c, stream: bs}
+	bs.fanout.subscribe(sub)
+	return sub
+}
+
+// OnNewBlock implements the BlockListener interface.  It never blocks on
+// a slow subscriber; it drops the subscriber instead.
+func (bs *blockStream) OnNewBlock(block bookkeeping.Block) {
+	bs.send(block)
+}
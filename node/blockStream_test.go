@@ -0,0 +1,77 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+)
+
+func blockAt(rnd basics.Round) bookkeeping.Block {
+	var blk bookkeeping.Block
+	blk.BlockHeader.Round = rnd
+	return blk
+}
+
+func TestBlockStreamFanOut(t *testing.T) {
+	bs := makeBlockStream()
+	sub1 := bs.subscribe()
+	sub2 := bs.subscribe()
+
+	bs.OnNewBlock(blockAt(1))
+	bs.OnNewBlock(blockAt(2))
+
+	for _, sub := range []*BlockSubscription{sub1, sub2} {
+		require.Equal(t, basics.Round(1), (<-sub.C).Round())
+		require.Equal(t, basics.Round(2), (<-sub.C).Round())
+	}
+
+	sub1.Close()
+	_, ok := <-sub1.C
+	require.False(t, ok)
+
+	// Closing twice is harmless, and the other subscriber keeps receiving blocks.
+	sub1.Close()
+	bs.OnNewBlock(blockAt(3))
+	require.Equal(t, basics.Round(3), (<-sub2.C).Round())
+	sub2.Close()
+}
+
+func TestBlockStreamDropsSlowSubscriber(t *testing.T) {
+	bs := makeBlockStream()
+	slow := bs.subscribe()
+	fast := bs.subscribe()
+
+	for rnd := basics.Round(1); rnd <= blockSubscriptionBacklog+1; rnd++ {
+		bs.OnNewBlock(blockAt(rnd))
+		require.Equal(t, rnd, (<-fast.C).Round())
+	}
+
+	// The slow subscriber gets the buffered blocks, then sees its channel closed.
+	for rnd := basics.Round(1); rnd <= blockSubscriptionBacklog; rnd++ {
+		require.Equal(t, rnd, (<-slow.C).Round())
+	}
+	_, ok := <-slow.C
+	require.False(t, ok)
+
+	slow.Close()
+	fast.Close()
+}
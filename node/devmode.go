@@ -0,0 +1,222 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/algorand/go-algorand/agreement"
+	"github.com/algorand/go-algorand/config"
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/data/committee"
+	"github.com/algorand/go-algorand/data/pools"
+	"github.com/algorand/go-algorand/logging/telemetryspec"
+)
+
+// A node of a network whose genesis sets DevMode does not run agreement.
+// Instead it seals a block out of its transaction pool whenever the pool
+// admits a transaction, be it submitted through the API or relayed by a
+// peer, or when asked to advance the round, and adds the block to its
+// ledger right away.  The timestamps of those blocks
+// follow the node's clock, which can be moved forward to test time-dependent
+// behavior without waiting.
+
+// ErrNotDevMode is returned by the dev mode controls of a node whose network
+// is not in dev mode.
+var ErrNotDevMode = errors.New("node is not running in dev mode")
+
+// DevModeSealError is returned by BroadcastSignedTxGroup on a dev mode node
+// when the pool admitted the transactions, but sealing them into a block
+// failed.  The transactions remain pending.
+type DevModeSealError struct {
+	Err error
+}
+
+// Error satisfies builtin interface `error`
+func (err DevModeSealError) Error() string {
+	return fmt.Sprintf("transactions are pending, but no block was sealed: %v", err.Err)
+}
+
+// devModeAssembleTime bounds the time spent filling a dev mode block.
+const devModeAssembleTime = time.Second
+
+// devModeSealer is a TxEventListener that seals a block whenever the
+// transaction pool admits a transaction.  OnTxEvent is called with the pool
+// locked, so it only counts the admission and wakes up run, which seals.
+type devModeSealer struct {
+	// admitted counts the transactions the pool admitted, and sealed how
+	// many of them were pending when the latest block that took in every
+	// pending transaction was assembled.  sealed is protected by
+	// node.devModeMu.
+	admitted uint64
+	sealed   uint64
+
+	node *AlgorandFullNode
+	wake chan struct{}
+}
+
+func makeDevModeSealer(node *AlgorandFullNode) *devModeSealer {
+	return &devModeSealer{
+		node: node,
+		wake: make(chan struct{}, 1),
+	}
+}
+
+// OnTxEvent implements the pools.TxEventListener interface.
+func (s *devModeSealer) OnTxEvent(ev pools.TxEvent) {
+	if ev.Type != pools.TxAdmitted {
+		return
+	}
+	atomic.AddUint64(&s.admitted, 1)
+	s.nudge()
+}
+
+// nudge wakes up run, unless it is already due to wake up.
+func (s *devModeSealer) nudge() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run seals the transactions the pool admits until ctx is done.
+func (s *devModeSealer) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		}
+
+		_, err := s.node.devModeSealPending()
+		if err != nil {
+			s.node.log.Errorf("dev mode: %v", err)
+		}
+	}
+}
+
+// devModeSealPending seals blocks until every transaction the pool admitted
+// made it into one, and returns the latest round.
+func (node *AlgorandFullNode) devModeSealPending() (basics.Round, error) {
+	node.devModeMu.Lock()
+	defer node.devModeMu.Unlock()
+
+	for atomic.LoadUint64(&node.devModeSealer.admitted) != node.devModeSealer.sealed {
+		_, err := node.devModeSealBlockLocked()
+		if err != nil {
+			return 0, err
+		}
+	}
+	return node.ledger.Latest(), nil
+}
+
+// devModeSealBlock assembles a block out of the transaction pool and adds it
+// to the ledger, with an empty certificate.
+func (node *AlgorandFullNode) devModeSealBlock() (basics.Round, error) {
+	node.devModeMu.Lock()
+	defer node.devModeMu.Unlock()
+	return node.devModeSealBlockLocked()
+}
+
+func (node *AlgorandFullNode) devModeSealBlockLocked() (basics.Round, error) {
+	admitted := atomic.LoadUint64(&node.devModeSealer.admitted)
+	latest := node.ledger.Latest()
+	prev, err := node.ledger.BlockHdr(latest)
+	if err != nil {
+		return 0, fmt.Errorf("could not read block %d: %v", latest, err)
+	}
+
+	blk := bookkeeping.MakeBlock(prev)
+	blk.TimeStamp = devModeTimestamp(prev, config.Consensus[blk.CurrentProtocol], time.Now().Add(node.devModeClockOffset))
+
+	eval, err := node.ledger.StartEvaluator(blk.BlockHeader, node.transactionPool, node.highPriorityCryptoVerificationPool)
+	if err != nil {
+		return 0, fmt.Errorf("could not start evaluator for round %d: %v", blk.Round(), err)
+	}
+	stats := node.ledger.AssemblePayset(node.transactionPool, eval, time.Now().Add(devModeAssembleTime))
+	vb, err := eval.GenerateBlock()
+	if err != nil {
+		return 0, fmt.Errorf("could not generate block for round %d: %v", blk.Round(), err)
+	}
+
+	// There is no VRF to draw the seed from, so chain it from the previous one.
+	sealed := vb.WithSeed(committee.Seed(crypto.Hash(prev.Seed[:])))
+	cert := agreement.Certificate{Round: blk.Round()}
+	cert.Proposal.BlockDigest = sealed.Block().Digest()
+	err = node.ledger.AddValidatedBlock(sealed, cert)
+	if err != nil {
+		return 0, fmt.Errorf("could not add block for round %d: %v", blk.Round(), err)
+	}
+
+	// A block that ran out of space or time left pending transactions out,
+	// so have the sealer put them in another one.  If it could not take any
+	// of them, another block would not do better.
+	cutShort := stats.StopReason == telemetryspec.AssembleBlockFull || stats.StopReason == telemetryspec.AssembleBlockTimeout
+	if cutShort && stats.IncludedCount > 0 {
+		node.devModeSealer.nudge()
+	} else {
+		node.devModeSealer.sealed = admitted
+	}
+	return blk.Round(), nil
+}
+
+// devModeTimestamp returns the timestamp of the block following prev, as
+// close to now as the consensus rules let it be.
+func devModeTimestamp(prev bookkeeping.BlockHeader, proto config.ConsensusParams, now time.Time) int64 {
+	timestamp := now.Unix()
+	if prev.TimeStamp > 0 {
+		if timestamp < prev.TimeStamp {
+			timestamp = prev.TimeStamp
+		} else if timestamp > prev.TimeStamp+proto.MaxTimestampIncrement {
+			timestamp = prev.TimeStamp + proto.MaxTimestampIncrement
+		}
+	}
+	return timestamp
+}
+
+// DevModeAdvanceRound seals a block with the pending transactions, if any,
+// and returns its round.
+func (node *AlgorandFullNode) DevModeAdvanceRound() (basics.Round, error) {
+	if !node.devMode {
+		return 0, ErrNotDevMode
+	}
+	return node.devModeSealBlock()
+}
+
+// DevModeAdvanceClock moves the clock used for block timestamps forward by
+// d, and returns how far ahead of the wall clock it now is.  Consensus rules
+// limit how much the timestamp may grow from one block to the next, so a
+// large jump shows up over several blocks.
+func (node *AlgorandFullNode) DevModeAdvanceClock(d time.Duration) (time.Duration, error) {
+	if !node.devMode {
+		return 0, ErrNotDevMode
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("cannot move the clock back by %v", -d)
+	}
+
+	node.devModeMu.Lock()
+	defer node.devModeMu.Unlock()
+	node.devModeClockOffset += d
+	return node.devModeClockOffset, nil
+}
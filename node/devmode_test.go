@@ -0,0 +1,195 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/go-algorand/config"
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/logging"
+	"github.com/algorand/go-algorand/protocol"
+)
+
+// makeDevModeNode starts a node of a dev mode network that runs the given
+// consensus version, with sender holding some algos.
+func makeDevModeNode(t *testing.T, version protocol.ConsensusVersion, sender basics.Address) (*AlgorandFullNode, bookkeeping.Genesis, func()) {
+	proto := config.Consensus[version]
+	genesis := bookkeeping.Genesis{
+		SchemaID:    "go-test-devmode-genesis",
+		Proto:       version,
+		Network:     config.Devtestnet,
+		FeeSink:     sinkAddr.GetUserAddress(),
+		RewardsPool: poolAddr.GetUserAddress(),
+		DevMode:     true,
+		Allocation: []bookkeeping.GenesisAllocation{
+			{Address: sender.GetUserAddress(), State: basics.MakeAccountData(basics.Offline, basics.MicroAlgos{Raw: 10000000})},
+			{Address: sinkAddr.GetUserAddress(), State: basics.MakeAccountData(basics.NotParticipating, basics.MicroAlgos{Raw: proto.MinBalance})},
+			{Address: poolAddr.GetUserAddress(), State: basics.MakeAccountData(basics.NotParticipating, basics.MicroAlgos{Raw: proto.MinBalance})},
+		},
+	}
+
+	rootDir, err := ioutil.TempDir("", "devmode")
+	require.NoError(t, err)
+
+	node, err := MakeFull(logging.TestingLog(t), rootDir, config.GetDefaultLocal(), "", genesis)
+	if err != nil {
+		os.RemoveAll(rootDir)
+	}
+	require.NoError(t, err)
+	node.Start()
+	return node, genesis, func() {
+		node.Stop()
+		os.RemoveAll(rootDir)
+	}
+}
+
+func TestDevMode(t *testing.T) {
+	proto := config.Consensus[protocol.ConsensusCurrentVersion]
+
+	var seed crypto.Seed
+	crypto.RandBytes(seed[:])
+	secrets := crypto.GenerateSignatureSecrets(seed)
+	sender := basics.Address(secrets.SignatureVerifier)
+	var receiver basics.Address
+	crypto.RandBytes(receiver[:])
+
+	node, genesis, cleanup := makeDevModeNode(t, protocol.ConsensusCurrentVersion, sender)
+	defer cleanup()
+	require.Equal(t, basics.Round(0), node.LatestRound())
+
+	// a transaction is committed right away
+	tx := transactions.Transaction{
+		Type: protocol.PaymentTx,
+		Header: transactions.Header{
+			Sender:      sender,
+			Fee:         basics.MicroAlgos{Raw: proto.MinTxnFee},
+			FirstValid:  0,
+			LastValid:   100,
+			GenesisID:   genesis.ID(),
+			GenesisHash: crypto.HashObj(genesis),
+		},
+		PaymentTxnFields: transactions.PaymentTxnFields{
+			Receiver: receiver,
+			Amount:   basics.MicroAlgos{Raw: 1000000},
+		},
+	}
+	err := node.BroadcastSignedTxGroup([]transactions.SignedTxn{tx.Sign(secrets)})
+	require.NoError(t, err)
+	require.Equal(t, basics.Round(1), node.LatestRound())
+	found, ok := node.GetTransaction(receiver, tx.ID(), 1, 1)
+	require.True(t, ok)
+	require.Equal(t, basics.Round(1), found.ConfirmedRound)
+
+	// empty blocks on request, with timestamps from the advanced clock
+	offset, err := node.DevModeAdvanceClock(time.Duration(proto.MaxTimestampIncrement) * time.Second)
+	require.NoError(t, err)
+	require.Equal(t, time.Duration(proto.MaxTimestampIncrement)*time.Second, offset)
+	before, err := node.ledger.BlockHdr(1)
+	require.NoError(t, err)
+
+	round, err := node.DevModeAdvanceRound()
+	require.NoError(t, err)
+	require.Equal(t, basics.Round(2), round)
+	after, err := node.ledger.BlockHdr(2)
+	require.NoError(t, err)
+	require.True(t, after.TimeStamp >= before.TimeStamp+proto.MaxTimestampIncrement-1)
+	require.NotEqual(t, before.Seed, after.Seed)
+
+	_, err = node.DevModeAdvanceClock(-time.Second)
+	require.Error(t, err)
+
+	// a transaction that reaches the pool some other way, such as from a
+	// peer, is sealed too, in a block of its own
+	relayed := tx
+	relayed.Amount.Raw++
+	err = node.transactionPool.Remember([]transactions.SignedTxn{relayed.Sign(secrets)})
+	require.NoError(t, err)
+	for i := 0; i < 100 && node.LatestRound() < 3; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.Equal(t, basics.Round(3), node.LatestRound())
+	found, ok = node.GetTransaction(receiver, relayed.ID(), 3, 3)
+	require.True(t, ok)
+	require.Equal(t, basics.Round(3), found.ConfirmedRound)
+
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, basics.Round(3), node.LatestRound())
+}
+
+func TestDevModeFullBlocks(t *testing.T) {
+	var seed crypto.Seed
+	crypto.RandBytes(seed[:])
+	secrets := crypto.GenerateSignatureSecrets(seed)
+	sender := basics.Address(secrets.SignatureVerifier)
+	var receiver basics.Address
+	crypto.RandBytes(receiver[:])
+
+	tx := func(genesis bookkeeping.Genesis, amount uint64) transactions.SignedTxn {
+		return transactions.Transaction{
+			Type: protocol.PaymentTx,
+			Header: transactions.Header{
+				Sender:      sender,
+				Fee:         basics.MicroAlgos{Raw: config.Consensus[genesis.Proto].MinTxnFee},
+				FirstValid:  0,
+				LastValid:   100,
+				GenesisID:   genesis.ID(),
+				GenesisHash: crypto.HashObj(genesis),
+			},
+			PaymentTxnFields: transactions.PaymentTxnFields{
+				Receiver: receiver,
+				Amount:   basics.MicroAlgos{Raw: amount},
+			},
+		}.Sign(secrets)
+	}
+
+	// blocks that only have room for a single payment
+	const smallBlocks = protocol.ConsensusVersion("test-devmode-small-blocks")
+	params := config.Consensus[protocol.ConsensusCurrentVersion]
+	params.MaxTxnBytesPerBlock = len(protocol.Encode(tx(bookkeeping.Genesis{Proto: protocol.ConsensusCurrentVersion}, 1000000)))
+	config.Consensus[smallBlocks] = params
+
+	node, genesis, cleanup := makeDevModeNode(t, smallBlocks, sender)
+	defer cleanup()
+
+	// transactions that do not fit in one block are sealed in as many as it takes
+	var txids []transactions.Txid
+	for i := uint64(0); i < 3; i++ {
+		stxn := tx(genesis, 1000000+i)
+		txids = append(txids, stxn.ID())
+		err := node.transactionPool.Remember([]transactions.SignedTxn{stxn})
+		require.NoError(t, err)
+	}
+	stxn := tx(genesis, 1000003)
+	txids = append(txids, stxn.ID())
+	err := node.BroadcastSignedTxGroup([]transactions.SignedTxn{stxn})
+	require.NoError(t, err)
+
+	require.Equal(t, basics.Round(len(txids)), node.LatestRound())
+	for _, txid := range txids {
+		_, ok := node.GetTransaction(receiver, txid, 1, node.LatestRound())
+		require.True(t, ok)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"fmt"
+
+	"github.com/algorand/go-algorand/config"
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/data"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/util/execpool"
+)
+
+// DryRunTxnResult is the outcome of evaluating a transaction in a dry run.
+type DryRunTxnResult struct {
+	Txn       transactions.SignedTxn
+	ApplyData transactions.ApplyData
+
+	// Err is set if the transaction, or the group it belongs to, would be
+	// rejected by the ledger.
+	Err error
+
+	// Accounts holds the resulting state of the accounts that the
+	// transaction touches, after its whole group was applied.
+	Accounts []basics.BalanceRecord
+}
+
+// DryRunResult is the outcome of evaluating a list of transactions in a dry run.
+type DryRunResult struct {
+	// Round is the round in which the transactions were evaluated, i.e.
+	// the round after the latest round in the ledger.
+	Round basics.Round
+	Txns  []DryRunTxnResult
+}
+
+// dryRunTxnCache lets the evaluator skip signature verification for
+// transactions that were submitted without any signature, so that they
+// can be dry-run before being signed.  Signed transactions are verified
+// as usual.
+type dryRunTxnCache struct{}
+
+func (dryRunTxnCache) Verified(txn transactions.SignedTxn) bool {
+	return txn.Sig == (crypto.Signature{}) && txn.Msig.Blank() && txn.Lsig.Blank()
+}
+
+// dryRunGroups splits txns into transaction groups: consecutive transactions
+// with the same non-zero Group form a group, and every other transaction is
+// a group of its own.
+func dryRunGroups(txns []transactions.SignedTxn) [][]transactions.SignedTxn {
+	var groups [][]transactions.SignedTxn
+	for i, txn := range txns {
+		if i > 0 && !txn.Txn.Group.IsZero() && txn.Txn.Group == txns[i-1].Txn.Group {
+			groups[len(groups)-1] = append(groups[len(groups)-1], txn)
+			continue
+		}
+		groups = append(groups, []transactions.SignedTxn{txn})
+	}
+	return groups
+}
+
+// dryRun evaluates txns on top of the latest block in the ledger, with an
+// evaluator whose results are thrown away.  Groups are evaluated in order,
+// so a group sees the effects of the groups before it that succeeded.
+func dryRun(l *data.Ledger, txns []transactions.SignedTxn, verificationPool execpool.BacklogPool) (res DryRunResult, err error) {
+	prev, err := l.BlockHdr(l.Latest())
+	if err != nil {
+		return
+	}
+
+	blk := bookkeeping.MakeBlock(prev)
+	eval, err := l.StartEvaluator(blk.BlockHeader, dryRunTxnCache{}, verificationPool)
+	if err != nil {
+		return
+	}
+
+	res.Round = blk.Round()
+	spec := transactions.SpecialAddresses{
+		FeeSink:     blk.FeeSink,
+		RewardsPool: blk.RewardsPool,
+	}
+	proto := config.Consensus[blk.CurrentProtocol]
+
+	for _, group := range dryRunGroups(txns) {
+		txgroup := make([]transactions.SignedTxnWithAD, len(group))
+		for i, txn := range group {
+			txgroup[i].SignedTxn = txn
+		}
+
+		groupErr := eval.TransactionGroup(txgroup)
+		var payset []transactions.SignedTxnInBlock
+		if groupErr == nil {
+			payset = eval.Payset()
+			payset = payset[len(payset)-len(group):]
+		}
+
+		for i, txn := range group {
+			result := DryRunTxnResult{
+				Txn: txn,
+				Err: groupErr,
+			}
+			if groupErr == nil {
+				result.ApplyData = payset[i].ApplyData
+			}
+
+			seen := make(map[basics.Address]bool)
+			for _, addr := range txn.Txn.RelevantAddrs(spec, proto) {
+				if addr.IsZero() || seen[addr] {
+					continue
+				}
+				seen[addr] = true
+
+				record, err := eval.Lookup(addr)
+				if err != nil {
+					return DryRunResult{}, fmt.Errorf("dry run: could not look up %v: %v", addr, err)
+				}
+				result.Accounts = append(result.Accounts, record)
+			}
+			res.Txns = append(res.Txns, result)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,128 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/go-algorand/config"
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/data"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/logging"
+	"github.com/algorand/go-algorand/protocol"
+	"github.com/algorand/go-algorand/util/execpool"
+)
+
+func TestDryRun(t *testing.T) {
+	proto := config.Consensus[protocol.ConsensusCurrentVersion]
+	genesisHash := crypto.Digest{1}
+
+	var poolAddr, sinkAddr basics.Address
+	crypto.RandBytes(poolAddr[:])
+	crypto.RandBytes(sinkAddr[:])
+
+	secrets := make([]*crypto.SignatureSecrets, 3)
+	addrs := make([]basics.Address, 3)
+	genesis := make(map[basics.Address]basics.AccountData)
+	for i := range secrets {
+		var seed crypto.Seed
+		crypto.RandBytes(seed[:])
+		secrets[i] = crypto.GenerateSignatureSecrets(seed)
+		addrs[i] = basics.Address(secrets[i].SignatureVerifier)
+	}
+	genesis[addrs[0]] = basics.MakeAccountData(basics.Offline, basics.MicroAlgos{Raw: 10000000})
+	genesis[addrs[1]] = basics.MakeAccountData(basics.Offline, basics.MicroAlgos{Raw: 10000000})
+	genesis[poolAddr] = basics.MakeAccountData(basics.NotParticipating, basics.MicroAlgos{Raw: 100000 * uint64(proto.RewardsRateRefreshInterval)})
+	genesis[sinkAddr] = basics.MakeAccountData(basics.NotParticipating, basics.MicroAlgos{Raw: proto.MinBalance})
+
+	l, err := data.LoadLedger(logging.TestingLog(t), t.Name(), true, protocol.ConsensusCurrentVersion, data.MakeGenesisBalances(genesis, sinkAddr, poolAddr), "test", genesisHash, nil)
+	require.NoError(t, err)
+	defer l.Close()
+
+	pay := func(from, to basics.Address, amount uint64) transactions.Transaction {
+		return transactions.Transaction{
+			Type: protocol.PaymentTx,
+			Header: transactions.Header{
+				Sender:      from,
+				Fee:         basics.MicroAlgos{Raw: proto.MinTxnFee},
+				FirstValid:  0,
+				LastValid:   10,
+				GenesisHash: genesisHash,
+			},
+			PaymentTxnFields: transactions.PaymentTxnFields{
+				Receiver: to,
+				Amount:   basics.MicroAlgos{Raw: amount},
+			},
+		}
+	}
+
+	unsigned := transactions.SignedTxn{Txn: pay(addrs[0], addrs[2], 1000000)}
+	overspend := transactions.SignedTxn{Txn: pay(addrs[0], addrs[2], 100000000)}
+	badSig := pay(addrs[1], addrs[2], 1000000).Sign(secrets[0])
+	signed := pay(addrs[1], addrs[2], 2000000).Sign(secrets[1])
+
+	backlogPool := execpool.MakeBacklog(nil, 0, execpool.LowPriority, nil)
+	defer backlogPool.Shutdown()
+
+	res, err := dryRun(l, []transactions.SignedTxn{unsigned, overspend, badSig, signed}, backlogPool)
+	require.NoError(t, err)
+	require.Equal(t, l.Latest()+1, res.Round)
+	require.Len(t, res.Txns, 4)
+
+	balances := func(res DryRunTxnResult) map[basics.Address]uint64 {
+		m := make(map[basics.Address]uint64)
+		for _, br := range res.Accounts {
+			m[br.Addr] = br.MicroAlgos.Raw
+		}
+		return m
+	}
+
+	require.NoError(t, res.Txns[0].Err)
+	require.Equal(t, uint64(1000000), balances(res.Txns[0])[addrs[2]])
+	require.Equal(t, 10000000-1000000-proto.MinTxnFee+res.Txns[0].ApplyData.SenderRewards.Raw, balances(res.Txns[0])[addrs[0]])
+
+	require.Error(t, res.Txns[1].Err)
+	require.Error(t, res.Txns[2].Err)
+
+	// Later transactions see the effects of the earlier successful ones.
+	require.NoError(t, res.Txns[3].Err)
+	require.Equal(t, uint64(3000000), balances(res.Txns[3])[addrs[2]])
+
+	// Nothing was committed.
+	require.Equal(t, basics.Round(0), l.Latest())
+	acct, err := l.Lookup(l.Latest(), addrs[2])
+	require.NoError(t, err)
+	require.True(t, acct.IsZero())
+}
+
+func TestDryRunGroups(t *testing.T) {
+	var a, b transactions.SignedTxn
+	a.Txn.Group = crypto.Digest{1}
+	b.Txn.Group = crypto.Digest{2}
+	var single transactions.SignedTxn
+
+	groups := dryRunGroups([]transactions.SignedTxn{single, a, a, single, single, b, a})
+	lens := make([]int, len(groups))
+	for i, g := range groups {
+		lens[i] = len(g)
+	}
+	require.Equal(t, []int{1, 2, 1, 1, 1, 1}, lens)
+}
@@ -0,0 +1,72 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"github.com/algorand/go-deadlock"
+)
+
+// fanoutSubscriber is a subscription of a fanout, with its own typed
+// channel.
+type fanoutSubscriber interface {
+	// offer delivers v to the subscriber without blocking, and returns
+	// false if the subscriber's backlog is full.
+	offer(v interface{}) bool
+	// closeChannel closes the subscriber's channel.
+	closeChannel()
+}
+
+// fanout delivers values to a set of subscribers, such as the block and
+// transaction pool event streams.  It never blocks on a slow subscriber;
+// it drops the subscriber and closes its channel instead.
+type fanout struct {
+	mu   deadlock.Mutex
+	subs map[fanoutSubscriber]struct{}
+}
+
+func makeFanout() fanout {
+	return fanout{
+		subs: make(map[fanoutSubscriber]struct{}),
+	}
+}
+
+func (f *fanout) subscribe(sub fanoutSubscriber) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.subs[sub] = struct{}{}
+}
+
+func (f *fanout) unsubscribe(sub fanoutSubscriber) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.subs[sub]; ok {
+		delete(f.subs, sub)
+		sub.closeChannel()
+	}
+}
+
+func (f *fanout) send(v interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for sub := range f.subs {
+		if !sub.offer(v) {
+			delete(f.subs, sub)
+			sub.closeChannel()
+		}
+	}
+}
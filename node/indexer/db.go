@@ -18,41 +18,99 @@ package indexer
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
 
+	"github.com/algorand/go-algorand/data/basics"
 	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/protocol"
 	"github.com/algorand/go-algorand/util/db"
 )
 
 const (
 	dbName  = "indexer.sqlite"
 	maxRows = 100
+
+	// maxPageRows is the largest page of transactions that a single query may return
+	maxPageRows = 1000
+
+	// notePrefixLen is the number of leading note bytes kept in the note prefix index
+	notePrefixLen = 32
+
+	// schemaVersion is bumped whenever the tables change in a way that requires re-indexing
+	schemaVersion = 2
 )
 
+var paramsSchema = `
+	CREATE TABLE IF NOT EXISTS params(
+		k CHAR(15) PRIMARY KEY DEFAULT NULL,
+		v INTEGER DEFAULT NULL,
+		UNIQUE (k)
+	);
+
+	INSERT OR IGNORE INTO params (k, v) VALUES ('maxRound', 1);
+`
+
 var schema = `
 	CREATE TABLE IF NOT EXISTS transactions(
 		txid CHAR(52) PRIMARY KEY NOT NULL,
 		from_addr CHAR(58) DEFAULT NULL,
 		to_addr CHAR(58) DEFAULT NULL,
 		round INTEGER DEFAULT NULL,
-		created_at INTEGER
+		created_at INTEGER,
+		intra INTEGER NOT NULL DEFAULT 0,
+		type CHAR(8) DEFAULT NULL,
+		amount INTEGER DEFAULT 0,
+		close_to_addr CHAR(58) DEFAULT NULL,
+		note BLOB DEFAULT NULL
 	);
 
-	CREATE TABLE IF NOT EXISTS params(
-		k CHAR(15) PRIMARY KEY DEFAULT NULL,
-		v INTEGER DEFAULT NULL,
-		UNIQUE (k)
+	CREATE TABLE IF NOT EXISTS accounts(
+		addr CHAR(58) NOT NULL,
+		round INTEGER NOT NULL,
+		intra INTEGER NOT NULL,
+		PRIMARY KEY (addr, round, intra)
 	);
 
-	INSERT OR IGNORE INTO params (k, v) VALUES ('maxRound', 1);
+	CREATE TABLE IF NOT EXISTS notes(
+		prefix BLOB NOT NULL,
+		round INTEGER NOT NULL,
+		intra INTEGER NOT NULL,
+		PRIMARY KEY (prefix, round, intra)
+	);
 
 	CREATE INDEX IF NOT EXISTS idx ON transactions (
 		created_at	DESC,
 		from_addr,
 		to_addr
 	);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_position ON transactions (
+		round,
+		intra
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_type ON transactions (
+		type,
+		round,
+		intra
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_close_to ON transactions (
+		close_to_addr,
+		round,
+		intra
+	);
 `
 
+// ErrMalformedCursor is returned when a query is given a cursor that was not
+// returned by GetTransactions.
+var ErrMalformedCursor = errors.New("malformed cursor")
+
 // Transaction represents a transaction in the system
 type Transaction struct {
 	TXID      string
@@ -60,6 +118,48 @@ type Transaction struct {
 	To        string `db:"to_addr_r"`
 	Round     uint32
 	CreatedAt uint32 `db:"created_at"`
+
+	// Intra is the position of the transaction in its block
+	Intra   uint32
+	Type    string
+	Amount  uint64
+	CloseTo string `db:"close_to_addr"`
+	Note    []byte
+}
+
+// TransactionFilter selects the transactions returned by GetTransactions.
+// Zero-valued fields do not restrict the result.
+type TransactionFilter struct {
+	// Address matches transactions in which the address takes any part:
+	// sender, receiver, close-to address, asset sender or receiver, or the
+	// account of an asset freeze.
+	Address string
+
+	// Type matches the transaction type, e.g. "pay" or "axfer"
+	Type string
+
+	// MinAmount and MaxAmount bound the amount transferred, in MicroAlgos
+	// for payments and in units of the asset for asset transfers.
+	MinAmount uint64
+	MaxAmount uint64
+
+	// FirstRound and LastRound bound the round of the transaction
+	FirstRound uint64
+	LastRound  uint64
+
+	// CloseTo matches the close-to address of a payment or an asset transfer
+	CloseTo string
+
+	// NotePrefix matches transactions whose note starts with these bytes
+	NotePrefix []byte
+
+	// Cursor resumes a previous query right after the transaction it points
+	// at, as returned by GetTransactions.
+	Cursor string
+
+	// Limit is the maximal number of transactions to return; it defaults to
+	// 100 and is capped at 1000.
+	Limit uint64
 }
 
 // DB is a the db access layer for Indexer
@@ -90,7 +190,7 @@ func MakeIndexerDB(dbPath string, inMemory bool) (*DB, error) {
 	}
 	idb.dbw = dbw
 
-	_, err = dbw.Handle.Exec(schema)
+	err = dbw.Atomic(upgradeSchema)
 	if err != nil {
 		return &DB{}, err
 	}
@@ -98,6 +198,84 @@ func MakeIndexerDB(dbPath string, inMemory bool) (*DB, error) {
 	return idb, nil
 }
 
+// upgradeSchema creates the indexer tables. Tables written by an older
+// version of the indexer are dropped, so that the indexer re-indexes the
+// blocks from the start of the ledger.
+func upgradeSchema(tx *sql.Tx) error {
+	_, err := tx.Exec(paramsSchema)
+	if err != nil {
+		return err
+	}
+
+	var version uint64
+	err = tx.QueryRow("SELECT v FROM params WHERE k = 'schemaVersion'").Scan(&version)
+	if err == sql.ErrNoRows {
+		// The first indexer schema did not record its version
+		version = 1
+	} else if err != nil {
+		return err
+	}
+
+	if version < schemaVersion {
+		_, err = tx.Exec(`
+			DROP TABLE IF EXISTS transactions;
+			DROP TABLE IF EXISTS accounts;
+			DROP TABLE IF EXISTS notes;
+			UPDATE params SET v = 1 WHERE k = 'maxRound';
+		`)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(schema)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("INSERT OR REPLACE INTO params (k, v) VALUES ('schemaVersion', $1);", schemaVersion)
+	return err
+}
+
+// transactionAmount returns the amount that a transaction transfers, clamped
+// to the range of an SQLite integer.
+func transactionAmount(txn transactions.Transaction) uint64 {
+	var amount uint64
+	switch txn.Type {
+	case protocol.PaymentTx:
+		amount = txn.Amount.Raw
+	case protocol.AssetTransferTx:
+		amount = txn.AssetAmount
+	}
+	if amount > math.MaxInt64 {
+		amount = math.MaxInt64
+	}
+	return amount
+}
+
+// transactionCloseTo returns the close-to address of a transaction, or the
+// empty string if it has none.
+func transactionCloseTo(txn transactions.Transaction) string {
+	switch {
+	case txn.CloseRemainderTo != (basics.Address{}):
+		return txn.CloseRemainderTo.GetChecksumAddress().String()
+	case txn.AssetCloseTo != (basics.Address{}):
+		return txn.AssetCloseTo.GetChecksumAddress().String()
+	}
+	return ""
+}
+
+// transactionAccounts returns the addresses that take part in a transaction.
+func transactionAccounts(txn transactions.Transaction) []string {
+	var addrs []string
+	for _, addr := range []basics.Address{txn.Sender, txn.Receiver, txn.CloseRemainderTo, txn.AssetSender, txn.AssetReceiver, txn.AssetCloseTo, txn.FreezeAccount} {
+		if addr != (basics.Address{}) {
+			addrs = append(addrs, addr.GetChecksumAddress().String())
+		}
+	}
+	return addrs
+}
+
 // AddBlock takes an Algorand block and stores its transactions in the DB.
 func (idb *DB) AddBlock(b bookkeeping.Block) error {
 	err := idb.dbw.Atomic(func(tx *sql.Tx) error {
@@ -112,21 +290,56 @@ func (idb *DB) AddBlock(b bookkeeping.Block) error {
 			return fmt.Errorf("tryign to add a future block %d, where the last one is %d", b.Round(), rnd)
 		}
 
-		stmt, err := tx.Prepare("INSERT INTO transactions (txid, from_addr, to_addr, round, created_at) VALUES($1,  $2, $3, $4, $5);")
+		stmt, err := tx.Prepare("INSERT INTO transactions (txid, from_addr, to_addr, round, created_at, intra, type, amount, close_to_addr, note) VALUES($1,  $2, $3, $4, $5, $6, $7, $8, $9, $10);")
 		if err != nil {
 			return err
 		}
 		defer stmt.Close()
 
+		acctStmt, err := tx.Prepare("INSERT OR IGNORE INTO accounts (addr, round, intra) VALUES($1, $2, $3);")
+		if err != nil {
+			return err
+		}
+		defer acctStmt.Close()
+
+		noteStmt, err := tx.Prepare("INSERT INTO notes (prefix, round, intra) VALUES($1, $2, $3);")
+		if err != nil {
+			return err
+		}
+		defer noteStmt.Close()
+
 		payset, err := b.DecodePayset()
 		if err != nil {
 			return err
 		}
-		for _, txn := range payset {
-			_, err = stmt.Exec(txn.ID().String(), txn.Txn.Sender.GetChecksumAddress().String(), txn.Txn.Receiver.GetChecksumAddress().String(), b.Round(), b.TimeStamp)
+		for intra, txn := range payset {
+			var closeTo interface{}
+			if addr := transactionCloseTo(txn.Txn); addr != "" {
+				closeTo = addr
+			}
+			_, err = stmt.Exec(txn.ID().String(), txn.Txn.Sender.GetChecksumAddress().String(), txn.Txn.Receiver.GetChecksumAddress().String(), b.Round(), b.TimeStamp,
+				intra, string(txn.Txn.Type), transactionAmount(txn.Txn), closeTo, txn.Txn.Note)
 			if err != nil {
 				return err
 			}
+
+			for _, addr := range transactionAccounts(txn.Txn) {
+				_, err = acctStmt.Exec(addr, b.Round(), intra)
+				if err != nil {
+					return err
+				}
+			}
+
+			if len(txn.Txn.Note) > 0 {
+				prefix := txn.Txn.Note
+				if len(prefix) > notePrefixLen {
+					prefix = prefix[:notePrefixLen]
+				}
+				_, err = noteStmt.Exec(prefix, b.Round(), intra)
+				if err != nil {
+					return err
+				}
+			}
 		}
 
 		stmt2, err := tx.Prepare("UPDATE params SET v = $1 WHERE k = 'maxRound';")
@@ -261,6 +474,177 @@ func (idb *DB) GetTransactionsRoundsByAddrAndDate(addr string, top uint64, from,
 	return rounds, nil
 }
 
+// makeCursor returns the cursor that resumes a query right after the
+// transaction at the given position.
+func makeCursor(round, intra uint32) string {
+	return fmt.Sprintf("%d:%d", round, intra)
+}
+
+// parseCursor returns the position of the transaction a cursor points at.
+func parseCursor(cursor string) (round, intra uint64, err error) {
+	parts := strings.Split(cursor, ":")
+	if len(parts) != 2 {
+		return 0, 0, ErrMalformedCursor
+	}
+	// sqlite integers are signed 64-bit, so neither part may exceed math.MaxInt64
+	round, err = strconv.ParseUint(parts[0], 10, 63)
+	if err != nil {
+		return 0, 0, ErrMalformedCursor
+	}
+	intra, err = strconv.ParseUint(parts[1], 10, 63)
+	if err != nil {
+		return 0, 0, ErrMalformedCursor
+	}
+	return round, intra, nil
+}
+
+// prefixUpperBound returns the smallest byte string that is greater than
+// every string starting with prefix, or nil if there is none.
+func prefixUpperBound(prefix []byte) []byte {
+	bound := append([]byte{}, prefix...)
+	for i := len(bound) - 1; i >= 0; i-- {
+		if bound[i] < 0xff {
+			bound[i]++
+			return bound[:i+1]
+		}
+	}
+	return nil
+}
+
+// GetTransactions returns the transactions that match the filter, ordered
+// by round and by position in the block. If more transactions match than
+// the filter's limit, it also returns a cursor that resumes the query where
+// this page ended; otherwise the cursor is empty.
+func (idb *DB) GetTransactions(filter TransactionFilter) ([]Transaction, string, error) {
+	var joins []string
+	var conds []string
+	var args []interface{}
+
+	if filter.Address != "" {
+		joins = append(joins, "JOIN accounts a ON a.round = t.round AND a.intra = t.intra")
+		conds = append(conds, "a.addr = ?")
+		args = append(args, filter.Address)
+	}
+
+	if len(filter.NotePrefix) > 0 {
+		prefix := filter.NotePrefix
+		if len(prefix) > notePrefixLen {
+			prefix = prefix[:notePrefixLen]
+			// the index only holds the first notePrefixLen bytes; compare the rest against the note itself
+			conds = append(conds, "substr(t.note, 1, ?) = ?")
+			args = append(args, len(filter.NotePrefix), filter.NotePrefix)
+		}
+		joins = append(joins, "JOIN notes n ON n.round = t.round AND n.intra = t.intra")
+		conds = append(conds, "n.prefix >= ?")
+		args = append(args, prefix)
+		if bound := prefixUpperBound(prefix); bound != nil {
+			conds = append(conds, "n.prefix < ?")
+			args = append(args, bound)
+		}
+	}
+
+	if filter.Type != "" {
+		conds = append(conds, "t.type = ?")
+		args = append(args, filter.Type)
+	}
+
+	if filter.MinAmount > 0 {
+		if filter.MinAmount > math.MaxInt64 {
+			filter.MinAmount = math.MaxInt64
+		}
+		conds = append(conds, "t.amount >= ?")
+		args = append(args, filter.MinAmount)
+	}
+	if filter.MaxAmount > 0 && filter.MaxAmount < math.MaxInt64 {
+		conds = append(conds, "t.amount <= ?")
+		args = append(args, filter.MaxAmount)
+	}
+
+	if filter.FirstRound > 0 {
+		if filter.FirstRound > math.MaxInt64 {
+			filter.FirstRound = math.MaxInt64
+		}
+		conds = append(conds, "t.round >= ?")
+		args = append(args, filter.FirstRound)
+	}
+	if filter.LastRound > 0 && filter.LastRound < math.MaxInt64 {
+		conds = append(conds, "t.round <= ?")
+		args = append(args, filter.LastRound)
+	}
+
+	if filter.CloseTo != "" {
+		conds = append(conds, "t.close_to_addr = ?")
+		args = append(args, filter.CloseTo)
+	}
+
+	if filter.Cursor != "" {
+		round, intra, err := parseCursor(filter.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		conds = append(conds, "(t.round > ? OR (t.round = ? AND t.intra > ?))")
+		args = append(args, round, round, intra)
+	}
+
+	limit := filter.Limit
+	if limit == 0 {
+		limit = maxRows
+	}
+	if limit > maxPageRows {
+		limit = maxPageRows
+	}
+
+	query := `
+		SELECT
+			t.txid,
+			t.from_addr,
+			t.to_addr,
+			t.round,
+			t.created_at,
+			t.intra,
+			t.type,
+			t.amount,
+			IFNULL(t.close_to_addr, ''),
+			t.note
+		FROM
+			transactions t ` + strings.Join(joins, " ")
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	// fetch one more row than requested, to tell whether there is another page
+	query += " ORDER BY t.round, t.intra LIMIT ?;"
+	args = append(args, limit+1)
+
+	rows, err := idb.dbr.Handle.Query(query, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var txns []Transaction
+	for rows.Next() {
+		var txn Transaction
+		err := rows.Scan(&txn.TXID, &txn.From, &txn.To, &txn.Round, &txn.CreatedAt, &txn.Intra, &txn.Type, &txn.Amount, &txn.CloseTo, &txn.Note)
+		if err != nil {
+			return nil, "", err
+		}
+		txns = append(txns, txn)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, "", err
+	}
+
+	var cursor string
+	if uint64(len(txns)) > limit {
+		txns = txns[:limit]
+		last := txns[len(txns)-1]
+		cursor = makeCursor(last.Round, last.Intra)
+	}
+	return txns, cursor, nil
+}
+
 // MaxRound returns the latest block in the DB
 func (idb *DB) MaxRound() (uint64, error) {
 	var rnd uint64
@@ -89,6 +89,13 @@ func (idx *Indexer) GetRoundsByAddress(addr string, top uint64) ([]uint64, error
 	return rounds, nil
 }
 
+// GetTransactions returns a page of the transactions that match the filter,
+// and the cursor that fetches the next page, or an empty cursor if this page
+// is the last one.
+func (idx *Indexer) GetTransactions(filter TransactionFilter) ([]Transaction, string, error) {
+	return idx.IDB.GetTransactions(filter)
+}
+
 // NewBlock takes a block and updates the DB
 // If the block exists, return nil.the block must be the next block
 func (idx *Indexer) NewBlock(b bookkeeping.Block) error {
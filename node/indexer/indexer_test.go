@@ -17,6 +17,7 @@
 package indexer
 
 import (
+	"math"
 	"math/rand"
 	"os"
 	"testing"
@@ -102,6 +103,140 @@ func (s *IndexSuite) TestIndexer_GetRoundsByAddress() {
 	require.Equal(s.T(), count, len(res))
 }
 
+func (s *IndexSuite) TestIndexer_GetTransactionsPagination() {
+	var count int
+	for _, txn := range s.txns {
+		if txn.Txn.Amount.Raw >= 100 && txn.Txn.Amount.Raw <= 200 {
+			count++
+		}
+	}
+
+	filter := TransactionFilter{
+		Type:       string(protocol.PaymentTx),
+		MinAmount:  100,
+		MaxAmount:  200,
+		FirstRound: 9,
+		LastRound:  9,
+		Limit:      50,
+	}
+	var got []Transaction
+	for {
+		txns, cursor, err := s.idx.GetTransactions(filter)
+		require.NoError(s.T(), err)
+		require.True(s.T(), len(txns) <= 50)
+		got = append(got, txns...)
+		if cursor == "" {
+			break
+		}
+		filter.Cursor = cursor
+	}
+	require.Equal(s.T(), count, len(got))
+	for i, txn := range got {
+		require.Equal(s.T(), uint32(9), txn.Round)
+		require.True(s.T(), txn.Amount >= 100 && txn.Amount <= 200)
+		if i > 0 {
+			require.True(s.T(), got[i-1].Intra < txn.Intra)
+		}
+	}
+
+	txns, _, err := s.idx.GetTransactions(TransactionFilter{Type: string(protocol.KeyRegistrationTx)})
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), txns)
+}
+
+func (s *IndexSuite) TestIndexer_GetTransactionsByAddress() {
+	var count int
+	for _, txn := range s.txns {
+		if txn.Txn.Sender == s.addrs[0] || txn.Txn.Receiver == s.addrs[0] {
+			count++
+		}
+	}
+
+	txns, _, err := s.idx.GetTransactions(TransactionFilter{Address: s.addrs[0].GetUserAddress(), FirstRound: 9, Limit: maxPageRows})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), count, len(txns))
+}
+
+func TestGetTransactionsNoteAndCloseTo(t *testing.T) {
+	idx, err := MakeIndexer(t.Name(), &TestLedger{}, true)
+	require.NoError(t, err)
+	defer idx.Shutdown()
+
+	_, txns, _, addrs := generateTestObjects(10, 5)
+	closeTo := keypair()
+	longNote := make([]byte, notePrefixLen+10)
+	longNote[notePrefixLen+5] = 1
+
+	b := bookkeeping.Block{
+		BlockHeader: bookkeeping.BlockHeader{
+			Round:     basics.Round(2),
+			TimeStamp: time.Now().Unix(),
+		},
+	}
+	for i, tx := range txns {
+		switch i {
+		case 1, 2:
+			tx.Txn.Note = []byte("invoice:1234")
+		case 3:
+			tx.Txn.Note = []byte("invoiced")
+		case 4:
+			tx.Txn.Note = longNote
+		case 5:
+			tx.Txn.CloseRemainderTo = basics.Address(closeTo.SignatureVerifier)
+		}
+		txib, err := b.EncodeSignedTxn(tx, transactions.ApplyData{})
+		require.NoError(t, err)
+		b.Payset = append(b.Payset, txib)
+	}
+	require.NoError(t, idx.NewBlock(b))
+
+	res, _, err := idx.GetTransactions(TransactionFilter{NotePrefix: []byte("invoice:")})
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	require.Equal(t, []byte("invoice:1234"), res[0].Note)
+
+	res, _, err = idx.GetTransactions(TransactionFilter{NotePrefix: []byte("invoice")})
+	require.NoError(t, err)
+	require.Len(t, res, 3)
+
+	res, _, err = idx.GetTransactions(TransactionFilter{NotePrefix: longNote})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	res, _, err = idx.GetTransactions(TransactionFilter{NotePrefix: longNote[:notePrefixLen+1]})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	res, _, err = idx.GetTransactions(TransactionFilter{NotePrefix: append(longNote[:notePrefixLen:notePrefixLen], 1)})
+	require.NoError(t, err)
+	require.Empty(t, res)
+
+	closeToAddr := basics.Address(closeTo.SignatureVerifier).GetUserAddress()
+	res, _, err = idx.GetTransactions(TransactionFilter{CloseTo: closeToAddr})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.Equal(t, closeToAddr, res[0].CloseTo)
+	require.Equal(t, txns[5].ID().String(), res[0].TXID)
+
+	// the close-to address takes part in the transaction, too
+	res, _, err = idx.GetTransactions(TransactionFilter{Address: closeToAddr})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+
+	_, _, err = idx.GetTransactions(TransactionFilter{Address: addrs[0].GetUserAddress(), Cursor: "bad"})
+	require.Equal(t, ErrMalformedCursor, err)
+	_, _, err = idx.GetTransactions(TransactionFilter{Address: addrs[0].GetUserAddress(), Cursor: "9223372036854775808:0"})
+	require.Equal(t, ErrMalformedCursor, err)
+	_, _, err = idx.GetTransactions(TransactionFilter{Address: addrs[0].GetUserAddress(), Cursor: "1:9223372036854775808"})
+	require.Equal(t, ErrMalformedCursor, err)
+
+	// round filters that do not fit in a sqlite integer are clamped rather than failing the query
+	res, _, err = idx.GetTransactions(TransactionFilter{CloseTo: closeToAddr, FirstRound: math.MaxUint64})
+	require.NoError(t, err)
+	require.Empty(t, res)
+	res, _, err = idx.GetTransactions(TransactionFilter{CloseTo: closeToAddr, LastRound: math.MaxUint64})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+}
+
 func TestExampleTestSuite(t *testing.T) {
 	suite.Run(t, new(IndexSuite))
 }
@@ -97,7 +97,7 @@ func (node *AlgorandFullNode) MakePrioResponse(challenge string) []byte {
 		return nil
 	}
 
-	signer := maxPart.VotingSigner()
+	signer := maxPart.Signer()
 	ephID := basics.OneTimeIDForRound(voteRound, signer.KeyDilution(proto))
 
 	rs.Round = voteRound
@@ -30,12 +30,14 @@ import (
 	"github.com/algorand/go-algorand/catchup"
 	"github.com/algorand/go-algorand/config"
 	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/daemon/partsigner"
 	"github.com/algorand/go-algorand/data"
 	"github.com/algorand/go-algorand/data/account"
 	"github.com/algorand/go-algorand/data/basics"
 	"github.com/algorand/go-algorand/data/bookkeeping"
 	"github.com/algorand/go-algorand/data/pools"
 	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/data/transactions/verify"
 	"github.com/algorand/go-algorand/ledger"
 	"github.com/algorand/go-algorand/logging"
 	"github.com/algorand/go-algorand/network"
@@ -76,12 +78,16 @@ func (status StatusReport) TimeSinceLastRound() time.Duration {
 type Full interface {
 	GetSupply() basics.SupplyDetail
 	GetBalanceAndStatus(address basics.Address) (money basics.MicroAlgos, rewards basics.MicroAlgos, moneyWithoutPendingRewards basics.MicroAlgos, status basics.Status, round basics.Round, err error)
-	BroadcastSignedTxn(signed transactions.SignedTxn) (transactions.Txid, error)
+	GetBalanceAndStatusAt(address basics.Address, round basics.Round) (money basics.MicroAlgos, rewards basics.MicroAlgos, moneyWithoutPendingRewards basics.MicroAlgos, status basics.Status, err error)
+	GetAccountData(address basics.Address, round basics.Round) (basics.AccountData, error)
+	GetAssetCreator(aidx basics.AssetIndex, round basics.Round) (basics.Address, error)
+	BroadcastSignedTxGroup(txgroup []transactions.SignedTxn) error
 	ListTxns(address basics.Address, minRound basics.Round, maxRound basics.Round) ([]TxnWithStatus, error)
 	GetTransaction(address basics.Address, txID transactions.Txid, minRound basics.Round, maxRound basics.Round) (TxnWithStatus, bool)
 	GetPendingTransaction(txID transactions.Txid) (TxnWithStatus, bool)
 	SuggestedFee() basics.MicroAlgos
 	PoolStats() PoolStats
+	PoolAdmission() pools.AdmissionParams
 	GetBlock(r basics.Round) (bookkeeping.Block, agreement.Certificate, error)
 	ExtendPeerList(args ...string)
 	LatestRound() basics.Round
@@ -95,6 +101,14 @@ type Full interface {
 	GenesisHash() crypto.Digest
 	Indexer() (*indexer.Indexer, error)
 	GetTransactionByID(txid transactions.Txid, rnd basics.Round) (TxnWithStatus, error)
+	StartCatchup(catchpoint string) error
+	SubscribeBlocks() *BlockSubscription
+	SubscribeTxEvents() *TxEventSubscription
+	UpgradeStatus() (UpgradeStatus, error)
+	DryRun(txns []transactions.SignedTxn) (DryRunResult, error)
+	BannedPeers() []network.PeerBan
+	DevModeAdvanceRound() (basics.Round, error)
+	DevModeAdvanceClock(d time.Duration) (time.Duration, error)
 }
 
 // AlgorandFullNode is a concrete implementation of the Full interface
@@ -113,13 +127,18 @@ type AlgorandFullNode struct {
 	transactionPool *pools.TransactionPool
 	txHandler       *data.TxHandler
 	accountManager  *data.AccountManager
+	partSigner      *partsigner.Client
 	feeTracker      *pools.FeeTracker
 
-	algorandService *agreement.Service
-	syncer          *catchup.Service
+	algorandService   *agreement.Service
+	syncer            *catchup.Service
+	catchpointService *catchup.CatchpointService
 
 	indexer *indexer.Indexer
 
+	blockStream   *blockStream
+	txEventStream *txEventStream
+
 	rootDir     string
 	genesisID   string
 	genesisHash crypto.Digest
@@ -138,6 +157,12 @@ type AlgorandFullNode struct {
 	wsFetcherService *rpcs.WsFetcherService // to handle inbound gossip msgs for fetching over gossip
 
 	oldKeyDeletionNotify chan struct{}
+
+	// devMode is set by the genesis of dev mode networks; see devmode.go.
+	devMode            bool
+	devModeMu          deadlock.Mutex
+	devModeClockOffset time.Duration
+	devModeSealer      *devModeSealer
 }
 
 // TxnWithStatus represents information about a single transaction,
@@ -169,6 +194,7 @@ func MakeFull(log logging.Logger, rootDir string, cfg config.Local, phonebookDir
 	node.log = log.With("name", cfg.NetAddress)
 	node.genesisID = genesis.ID()
 	node.genesisHash = crypto.HashObj(genesis)
+	node.devMode = genesis.DevMode
 
 	addrs, err := config.LoadPhonebook(phonebookDir)
 	if err != nil {
@@ -183,8 +209,21 @@ func MakeFull(log logging.Logger, rootDir string, cfg config.Local, phonebookDir
 		return nil, err
 	}
 	p2pNode.SetPrioScheme(node)
+	identity, err := network.LoadIdentity(filepath.Join(rootDir, config.PeerIdentityFilename))
+	if err != nil {
+		log.Errorf("could not load peer identity: %v", err)
+		return nil, err
+	}
+	p2pNode.SetIdentity(identity)
 	node.net = p2pNode
 	node.accountManager = data.MakeAccountManager(log)
+	if cfg.ParticipationSignerSocket != "" {
+		socket := cfg.ParticipationSignerSocket
+		if !filepath.IsAbs(socket) {
+			socket = filepath.Join(rootDir, socket)
+		}
+		node.partSigner = partsigner.MakeClient(socket, log)
+	}
 
 	accountListener := makeTopAccountListener(log)
 
@@ -219,8 +258,22 @@ func MakeFull(log logging.Logger, rootDir string, cfg config.Local, phonebookDir
 	}
 
 	node.ledger.SetArchival(cfg.Archival)
+	node.ledger.SetBlockRetention(cfg.BlockRetentionRounds)
+	node.ledger.SetAccountHistory(cfg.EnableAccountHistory && cfg.Archival)
+	if cfg.CatchpointFileHistoryLength > 0 {
+		node.ledger.SetCatchpointFiles(filepath.Join(genesisDir, config.CatchpointDirectory), cfg.CatchpointFileHistoryLength)
+	}
 	node.transactionPool = pools.MakeTransactionPool(node.ledger, cfg.TxPoolExponentialIncreaseFactor, cfg.TxPoolSize, cfg.EnableAssembleStats)
-	node.ledger.RegisterBlockListeners([]ledger.BlockListener{node.transactionPool})
+	node.transactionPool.SetMaxPendingPerSender(cfg.TxPoolMaxPerSender)
+	node.transactionPool.SetReplaceByNote(cfg.TxPoolReplaceByNote)
+	node.txEventStream = makeTxEventStream()
+	node.transactionPool.RegisterEventListeners([]pools.TxEventListener{node.txEventStream})
+	if node.devMode {
+		node.devModeSealer = makeDevModeSealer(node)
+		node.transactionPool.RegisterEventListeners([]pools.TxEventListener{node.devModeSealer})
+	}
+	node.blockStream = makeBlockStream()
+	node.ledger.RegisterBlockListeners([]ledger.BlockListener{node.transactionPool, node.blockStream})
 	node.txHandler = data.MakeTxHandler(node.transactionPool, node.ledger, node.net, node.genesisID, node.genesisHash, node.lowPriorityCryptoVerificationPool)
 	node.feeTracker, err = pools.MakeFeeTracker()
 	if err != nil {
@@ -238,6 +291,7 @@ func MakeFull(log logging.Logger, rootDir string, cfg config.Local, phonebookDir
 	}
 
 	node.ledgerService = rpcs.RegisterLedgerService(cfg, node.ledger, p2pNode, node.genesisID)
+	rpcs.RegisterCatchpointService(node.ledger, p2pNode, node.genesisID)
 	node.wsFetcherService = rpcs.RegisterWsFetcherService(node.log, p2pNode)
 	rpcs.RegisterTxService(node.transactionPool, p2pNode, node.genesisID, cfg.TxPoolSize, cfg.TxSyncServeResponseSize)
 
@@ -329,9 +383,13 @@ func (node *AlgorandFullNode) Start() {
 	node.net.Start()
 	node.config.NetAddress, _ = node.net.Address()
 
-	node.syncer.Start()
-	node.algorandService.Start()
-	node.txPoolSyncer.Start(node.syncer.InitialSyncDone)
+	if node.devMode {
+		node.log.Info("dev mode: sealing blocks locally instead of running agreement")
+	} else {
+		node.syncer.Start()
+		node.algorandService.Start()
+		node.txPoolSyncer.Start(node.syncer.InitialSyncDone)
+	}
 	node.ledgerService.Start()
 	node.txHandler.Start()
 
@@ -340,6 +398,10 @@ func (node *AlgorandFullNode) Start() {
 	node.ctx = ctx
 	node.cancelCtx = cancel
 
+	if node.devMode {
+		go node.devModeSealer.run(node.ctx)
+	}
+
 	// start indexer
 	if idx, err := node.Indexer(); err == nil {
 		err := idx.Start()
@@ -380,9 +442,17 @@ func (node *AlgorandFullNode) Stop() {
 
 	node.txHandler.Stop()
 	node.net.Stop()
-	node.algorandService.Shutdown()
-	node.syncer.Stop()
-	node.txPoolSyncer.Stop()
+	if !node.devMode {
+		node.algorandService.Shutdown()
+		if node.catchpointService != nil {
+			// the syncer was already stopped when catching up started
+			node.catchpointService.Stop()
+			node.catchpointService = nil
+		} else {
+			node.syncer.Stop()
+		}
+		node.txPoolSyncer.Stop()
+	}
 	node.ledgerService.Stop()
 	node.highPriorityCryptoVerificationPool.Shutdown()
 	node.lowPriorityCryptoVerificationPool.Shutdown()
@@ -405,6 +475,16 @@ func (node *AlgorandFullNode) getExistingPartHandle(filename string) (db.Accesso
 	return db.Accessor{}, err
 }
 
+// BannedPeers returns the peers that the node has temporarily banned for
+// misbehaving.
+func (node *AlgorandFullNode) BannedPeers() []network.PeerBan {
+	wn, ok := node.net.(*network.WebsocketNetwork)
+	if !ok {
+		return nil
+	}
+	return wn.BannedPeers()
+}
+
 // GetSupply returns the current supply reported by the ledger
 func (node *AlgorandFullNode) GetSupply() basics.SupplyDetail {
 	latest := node.ledger.Latest()
@@ -426,8 +506,25 @@ func (node *AlgorandFullNode) GetBalanceAndStatus(address basics.Address) (money
 	return node.ledger.BalanceAndStatus(address)
 }
 
-// BroadcastSignedTxn broadcasts a transaction that has already been signed.
-func (node *AlgorandFullNode) BroadcastSignedTxn(signed transactions.SignedTxn) (transactions.Txid, error) {
+// GetBalanceAndStatusAt is like GetBalanceAndStatus, but for the account as of the given round
+func (node *AlgorandFullNode) GetBalanceAndStatusAt(address basics.Address, round basics.Round) (money basics.MicroAlgos, rewards basics.MicroAlgos, moneyWithoutPendingRewards basics.MicroAlgos, status basics.Status, err error) {
+	return node.ledger.BalanceAndStatusAt(address, round)
+}
+
+// GetAccountData returns the full account record of address as of the given round
+func (node *AlgorandFullNode) GetAccountData(address basics.Address, round basics.Round) (basics.AccountData, error) {
+	return node.ledger.Lookup(round, address)
+}
+
+// GetAssetCreator returns the address of the account that created asset aidx, as of the given round
+func (node *AlgorandFullNode) GetAssetCreator(aidx basics.AssetIndex, round basics.Round) (basics.Address, error) {
+	return node.ledger.GetAssetCreator(round, aidx)
+}
+
+// BroadcastSignedTxGroup broadcasts a transaction group that has already been signed.
+// The group is remembered and broadcast as a single unit.  A group that is
+// already pending is broadcast again.
+func (node *AlgorandFullNode) BroadcastSignedTxGroup(txgroup []transactions.SignedTxn) error {
 	lastRound := node.ledger.LastRound()
 	b, err := node.ledger.BlockHdr(lastRound)
 	if err != nil {
@@ -439,24 +536,41 @@ func (node *AlgorandFullNode) BroadcastSignedTxn(signed transactions.SignedTxn)
 	}
 	proto := config.Consensus[b.CurrentProtocol]
 
-	err = signed.Verify(spec, proto)
+	err = verify.TxnGroup(txgroup, spec, proto)
 	if err != nil {
-		node.log.Warnf("malformed transaction: %v - transaction was %+v", err, signed)
-		return transactions.Txid{}, err
+		node.log.Warnf("malformed transaction: %v - transaction group was %+v", err, txgroup)
+		return err
 	}
-	err = node.transactionPool.Remember(signed)
-	if err != nil {
-		node.log.Infof("rejected by local pool: %v - transaction was %+v", err, signed)
-		return transactions.Txid{}, err
+	err = node.transactionPool.Remember(txgroup)
+	if err != nil && !node.transactionPool.Rebroadcast(txgroup) {
+		node.log.Infof("rejected by local pool: %v - transaction group was %+v", err, txgroup)
+		return err
+	}
+
+	if node.devMode {
+		// The sealer may have sealed the group already; if not, seal it
+		// now so that it is committed when we return.
+		_, err = node.devModeSealPending()
+		if err != nil {
+			node.log.Errorf("dev mode: %v", err)
+			return DevModeSealError{Err: err}
+		}
+		return nil
 	}
 
-	err = node.net.Broadcast(context.TODO(), protocol.TxnTag, protocol.Encode(signed), true, nil)
+	var enc []byte
+	var txids []transactions.Txid
+	for _, tx := range txgroup {
+		enc = append(enc, protocol.Encode(tx)...)
+		txids = append(txids, tx.ID())
+	}
+	err = node.net.Broadcast(context.TODO(), protocol.TxnTag, enc, true, nil)
 	if err != nil {
-		node.log.Infof("failure broadcasting transaction to network: %v - transaction was %+v", err, signed)
-		return transactions.Txid{}, err
+		node.log.Infof("failure broadcasting transaction to network: %v - transaction group was %+v", err, txgroup)
+		return err
 	}
-	node.log.Infof("Sent signed tx %s", signed.ID())
-	return signed.ID(), nil
+	node.log.Infof("Sent signed tx group with IDs %v", txids)
+	return nil
 }
 
 // ListTxns returns SignedTxns associated with a specific account in a range of Rounds (inclusive).
@@ -622,6 +736,11 @@ func (node *AlgorandFullNode) PoolStats() PoolStats {
 	}
 }
 
+// PoolAdmission returns what the transaction pool currently requires of a new transaction.
+func (node *AlgorandFullNode) PoolAdmission() pools.AdmissionParams {
+	return node.transactionPool.AdmissionParams()
+}
+
 // ExtendPeerList dynamically adds a peer to a node's peer list.
 func (node *AlgorandFullNode) ExtendPeerList(peers ...string) {
 	node.phonebook.ExtendPeerList(peers)
@@ -642,10 +761,34 @@ func (node *AlgorandFullNode) WaitForRound(r basics.Round) chan struct{} {
 	return node.ledger.Wait(r)
 }
 
+// SubscribeBlocks returns a subscription that delivers every block added
+// to the ledger from now on.  The caller must Close the subscription.
+func (node *AlgorandFullNode) SubscribeBlocks() *BlockSubscription {
+	return node.blockStream.subscribe()
+}
+
+// SubscribeTxEvents returns a subscription that delivers every change to
+// the transaction pool made from now on.  The caller must close it.
+func (node *AlgorandFullNode) SubscribeTxEvents() *TxEventSubscription {
+	return node.txEventStream.subscribe()
+}
+
+// DryRun evaluates txns against the latest ledger state, as if they were
+// included in the next block, without committing or broadcasting them.
+// Transactions that carry no signature are evaluated without verifying one.
+func (node *AlgorandFullNode) DryRun(txns []transactions.SignedTxn) (DryRunResult, error) {
+	return dryRun(node.ledger, txns, node.lowPriorityCryptoVerificationPool)
+}
+
 // SuggestedFee returns the suggested fee per byte recommended to ensure a new transaction is processed in a timely fashion.
+// It is never below the fee per byte the transaction pool currently requires to accept a transaction.
 // Caller should set fee to max(MinTxnFee, SuggestedFee() * len(encoded SignedTxn))
 func (node *AlgorandFullNode) SuggestedFee() basics.MicroAlgos {
-	return node.feeTracker.EstimateFee()
+	fee := node.feeTracker.EstimateFee()
+	if poolFee := node.transactionPool.AdmissionParams().FeePerByte; poolFee > fee.Raw {
+		fee.Raw = poolFee
+	}
+	return fee
 }
 
 // GetPendingTxnsFromPool returns a snapshot of every pending transactions from the node's transaction pool in a slice.
@@ -669,6 +812,10 @@ func (node *AlgorandFullNode) checkForParticipationKeys() {
 }
 
 func (node *AlgorandFullNode) loadParticipationKeys() error {
+	if node.partSigner != nil {
+		return node.loadRemoteParticipationKeys()
+	}
+
 	// Generate a list of all potential participation key files
 	genesisDir := filepath.Join(node.rootDir, node.genesisID)
 	files, err := ioutil.ReadDir(genesisDir)
@@ -718,6 +865,22 @@ func (node *AlgorandFullNode) loadParticipationKeys() error {
 	return nil
 }
 
+// loadRemoteParticipationKeys asks the participation signer which keys it holds.
+func (node *AlgorandFullNode) loadRemoteParticipationKeys() error {
+	parts, err := node.partSigner.Participations()
+	if err != nil {
+		return fmt.Errorf("AlgorandFullNode.loadRemoteParticipationKeys: %v", err)
+	}
+
+	for _, part := range parts {
+		// Tell the AccountManager about the Participation (dupes don't matter)
+		if node.accountManager.AddParticipation(part) {
+			node.log.Infof("Loaded participation keys from signer: %s", part.Address())
+		}
+	}
+	return nil
+}
+
 func (node *AlgorandFullNode) txPoolGaugeThread() {
 	txPoolGuage := metrics.MakeGauge(metrics.MetricName{Name: "algod_tx_pool_count", Description: "current number of available transactions in pool"})
 	ticker := time.NewTicker(10 * time.Second)
@@ -813,3 +976,50 @@ func (node *AlgorandFullNode) GetTransactionByID(txid transactions.Txid, rnd bas
 		ApplyData:      stx.ApplyData,
 	}, nil
 }
+
+// StartCatchup starts catching up to the given catchpoint label in the
+// background, instead of replaying every block up to it.  The regular
+// catchup service resumes once the node reaches the catchpoint.
+func (node *AlgorandFullNode) StartCatchup(catchpoint string) error {
+	label, err := ledger.ParseCatchpointLabel(catchpoint)
+	if err != nil {
+		return err
+	}
+
+	node.mu.Lock()
+	defer node.mu.Unlock()
+
+	if node.devMode {
+		return fmt.Errorf("dev mode nodes do not catch up")
+	}
+	if node.catchpointService != nil {
+		return fmt.Errorf("already catching up to a catchpoint")
+	}
+	if latest := node.ledger.Latest(); latest >= label.Round {
+		return fmt.Errorf("ledger is at round %d, past catchpoint round %d", latest, label.Round)
+	}
+
+	node.syncer.Stop()
+	node.catchpointService = catchup.MakeCatchpointService(node.log, node.net, node.ledger, label)
+	go node.catchupToCatchpoint(node.catchpointService)
+	return nil
+}
+
+func (node *AlgorandFullNode) catchupToCatchpoint(cs *catchup.CatchpointService) {
+	err := cs.Run()
+	if err != nil {
+		node.log.Warnf("catchpoint catchup failed: %v", err)
+	}
+
+	node.mu.Lock()
+	defer node.mu.Unlock()
+
+	if node.catchpointService != cs {
+		// the node was stopped
+		return
+	}
+	node.catchpointService = nil
+
+	node.syncer = catchup.MakeService(node.log, node.config, node.net, node.ledger, node.wsFetcherService, node.lowPriorityCryptoVerificationPool)
+	node.syncer.Start()
+}
@@ -0,0 +1,77 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"github.com/algorand/go-algorand/data/pools"
+)
+
+// txEventSubscriptionBacklog is the number of transaction pool events
+// buffered for each subscription.  A subscriber that falls further behind
+// than this is dropped.
+const txEventSubscriptionBacklog = 1024
+
+// TxEventSubscription delivers the changes to the transaction pool made
+// after it was created, in order.  C is closed if the subscriber falls too
+// far behind, or when the subscription is closed.
+type TxEventSubscription struct {
+	C <-chan pools.TxEvent
+
+	c      chan pools.TxEvent
+	stream *txEventStream
+}
+
+// Close stops delivery of events to the subscription.
+func (s *TxEventSubscription) Close() {
+	s.stream.unsubscribe(s)
+}
+
+func (s *TxEventSubscription) offer(v interface{}) bool {
+	select {
+	case s.c <- v.(pools.TxEvent):
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *TxEventSubscription) closeChannel() {
+	close(s.c)
+}
+
+// txEventStream is a TxEventListener that fans out transaction pool
+// events to subscriptions, such as the pending transaction stream API.
+type txEventStream struct {
+	fanout
+}
+
+func makeTxEventStream() *txEventStream {
+	return &txEventStream{fanout: makeFanout()}
+}
+
+func (es *txEventStream) subscribe() *TxEventSubscription {
+	c := make(chan pools.TxEvent, txEventSubscriptionBacklog)
+	sub := &TxEventSubscription{C: c, c: c, stream: es}
+	es.fanout.subscribe(sub)
+	return sub
+}
+
+// OnTxEvent implements the pools.TxEventListener interface.  It never
+// blocks on a slow subscriber; it drops the subscriber instead.
+func (es *txEventStream) OnTxEvent(ev pools.TxEvent) {
+	es.send(ev)
+}
@@ -0,0 +1,51 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/pools"
+)
+
+func TestTxEventStreamDropsSlowSubscriber(t *testing.T) {
+	es := makeTxEventStream()
+	slow := es.subscribe()
+	fast := es.subscribe()
+
+	for rnd := basics.Round(1); rnd <= txEventSubscriptionBacklog+1; rnd++ {
+		es.OnTxEvent(pools.TxEvent{Type: pools.TxCommitted, Round: rnd})
+		require.Equal(t, rnd, (<-fast.C).Round)
+	}
+
+	// The slow subscriber gets the buffered events, then sees its channel closed.
+	for rnd := basics.Round(1); rnd <= txEventSubscriptionBacklog; rnd++ {
+		require.Equal(t, rnd, (<-slow.C).Round)
+	}
+	_, ok := <-slow.C
+	require.False(t, ok)
+
+	// Closing twice is harmless.
+	slow.Close()
+	fast.Close()
+	fast.Close()
+	_, ok = <-fast.C
+	require.False(t, ok)
+}
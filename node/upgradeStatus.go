@@ -0,0 +1,199 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/algorand/go-algorand/agreement"
+	"github.com/algorand/go-algorand/config"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/ledger"
+	"github.com/algorand/go-algorand/protocol"
+)
+
+// maxUpgradeSupportRounds bounds the number of recent blocks whose
+// proposers are tallied by UpgradeStatus, so that the call stays cheap
+// even for protocols with a long upgrade voting period.
+const maxUpgradeSupportRounds = 1000
+
+// UpgradeSupport is the support for a protocol version among the proposers
+// of recent blocks.
+type UpgradeSupport struct {
+	Version protocol.ConsensusVersion
+
+	// Proposers is the number of distinct accounts whose most recent block
+	// supports Version, and Blocks the number of blocks that do.
+	Proposers int
+	Blocks    int
+
+	// OnlineStake is the current online stake of those proposers.
+	OnlineStake basics.MicroAlgos
+
+	// Supported is whether this node can run Version.
+	Supported bool
+}
+
+// UpgradeStatus reports on the progress of a consensus protocol upgrade.
+type UpgradeStatus struct {
+	LastRound basics.Round
+
+	CurrentProtocol        protocol.ConsensusVersion
+	NextProtocol           protocol.ConsensusVersion
+	NextProtocolApprovals  uint64
+	NextProtocolVoteBefore basics.Round
+	NextProtocolSwitchOn   basics.Round
+	NextProtocolSupported  bool
+
+	// UpgradeThreshold and UpgradeVoteRounds are the parameters of the
+	// current protocol: an upgrade needs UpgradeThreshold approvals among
+	// UpgradeVoteRounds blocks.
+	UpgradeThreshold  uint64
+	UpgradeVoteRounds uint64
+
+	// Support tallies the versions supported by the proposers of the
+	// blocks from FirstSampledRound to LastRound, in decreasing order of
+	// online stake.
+	FirstSampledRound basics.Round
+	Support           []UpgradeSupport
+
+	// OnlineStake is the total online stake as of LastRound.
+	OnlineStake basics.MicroAlgos
+}
+
+// upgradeStatusLedger is the part of the ledger that upgradeStatus uses.
+type upgradeStatusLedger interface {
+	LastRound() basics.Round
+	BlockCert(basics.Round) (bookkeeping.Block, agreement.Certificate, error)
+	Lookup(basics.Round, basics.Address) (basics.AccountData, error)
+	Totals(basics.Round) (ledger.AccountTotals, error)
+}
+
+// UpgradeStatus reports on the progress of a consensus protocol upgrade,
+// and on the versions that the proposers of recent blocks support.
+func (node *AlgorandFullNode) UpgradeStatus() (UpgradeStatus, error) {
+	return upgradeStatus(node.ledger)
+}
+
+func upgradeStatus(l upgradeStatusLedger) (UpgradeStatus, error) {
+	last := l.LastRound()
+	blk, _, err := l.BlockCert(last)
+	if err != nil {
+		return UpgradeStatus{}, err
+	}
+	proto, ok := config.Consensus[blk.CurrentProtocol]
+	if !ok {
+		return UpgradeStatus{}, fmt.Errorf("unknown protocol version %v", blk.CurrentProtocol)
+	}
+	totals, err := l.Totals(last)
+	if err != nil {
+		return UpgradeStatus{}, err
+	}
+
+	status := UpgradeStatus{
+		LastRound:              last,
+		CurrentProtocol:        blk.CurrentProtocol,
+		NextProtocol:           blk.NextProtocol,
+		NextProtocolApprovals:  blk.NextProtocolApprovals,
+		NextProtocolVoteBefore: blk.NextProtocolVoteBefore,
+		NextProtocolSwitchOn:   blk.NextProtocolSwitchOn,
+		UpgradeThreshold:       proto.UpgradeThreshold,
+		UpgradeVoteRounds:      proto.UpgradeVoteRounds,
+		OnlineStake:            totals.Online.Money,
+	}
+	if blk.NextProtocol != "" {
+		_, status.NextProtocolSupported = config.Consensus[blk.NextProtocol]
+	}
+
+	window := proto.UpgradeVoteRounds
+	if window == 0 || window > maxUpgradeSupportRounds {
+		window = maxUpgradeSupportRounds
+	}
+
+	// Walk back from the last block, so that the most recent vote of each
+	// proposer is the one that counts.  Stop early at blocks that are no
+	// longer available, e.g. on a non-archival node.
+	supported := make(map[basics.Address]protocol.ConsensusVersion)
+	blocks := make(map[protocol.ConsensusVersion]int)
+	status.FirstSampledRound = last + 1
+	for rnd := last; rnd > 0 && uint64(last-rnd) < window; rnd-- {
+		blk, cert, err := l.BlockCert(rnd)
+		if err != nil {
+			break
+		}
+		status.FirstSampledRound = rnd
+
+		version, ok := supportedVersion(blk.BlockHeader)
+		if !ok {
+			continue
+		}
+		blocks[version]++
+		proposer := cert.Proposal.OriginalProposer
+		if _, seen := supported[proposer]; !seen {
+			supported[proposer] = version
+		}
+	}
+
+	support := make(map[protocol.ConsensusVersion]*UpgradeSupport)
+	for version, n := range blocks {
+		_, supported := config.Consensus[version]
+		support[version] = &UpgradeSupport{Version: version, Blocks: n, Supported: supported}
+	}
+	for proposer, version := range supported {
+		s := support[version]
+		s.Proposers++
+
+		data, err := l.Lookup(last, proposer)
+		if err != nil {
+			return UpgradeStatus{}, err
+		}
+		// Count the pending rewards as well, as the online totals do.
+		if data.Status == basics.Online {
+			data = data.WithUpdatedRewards(proto, blk.RewardsLevel)
+			s.OnlineStake.Raw += data.MicroAlgos.Raw
+		}
+	}
+
+	for _, s := range support {
+		status.Support = append(status.Support, *s)
+	}
+	sort.Slice(status.Support, func(i, j int) bool {
+		if status.Support[i].OnlineStake != status.Support[j].OnlineStake {
+			return status.Support[j].OnlineStake.LessThan(status.Support[i].OnlineStake)
+		}
+		return status.Support[i].Version < status.Support[j].Version
+	})
+	return status, nil
+}
+
+// supportedVersion returns the protocol version that the proposer of a
+// block supports, going by its upgrade vote, and false if the block says
+// nothing about it (a vote is over but the upgrade has not happened yet).
+func supportedVersion(hdr bookkeeping.BlockHeader) (protocol.ConsensusVersion, bool) {
+	switch {
+	case hdr.UpgradePropose != "":
+		return hdr.UpgradePropose, true
+	case hdr.UpgradeApprove:
+		return hdr.NextProtocol, true
+	case hdr.NextProtocol != "" && hdr.Round >= hdr.NextProtocolVoteBefore:
+		return "", false
+	default:
+		return hdr.CurrentProtocol, true
+	}
+}
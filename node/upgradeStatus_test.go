@@ -0,0 +1,131 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/algorand/go-algorand/agreement"
+	"github.com/algorand/go-algorand/config"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/ledger"
+	"github.com/algorand/go-algorand/protocol"
+)
+
+type upgradeTestLedger struct {
+	blocks    []bookkeeping.Block
+	proposers []basics.Address
+	accounts  map[basics.Address]basics.AccountData
+	earliest  basics.Round
+}
+
+func (l *upgradeTestLedger) LastRound() basics.Round {
+	return basics.Round(len(l.blocks) - 1)
+}
+
+func (l *upgradeTestLedger) BlockCert(rnd basics.Round) (bookkeeping.Block, agreement.Certificate, error) {
+	if rnd < l.earliest {
+		return bookkeeping.Block{}, agreement.Certificate{}, fmt.Errorf("round %d pruned", rnd)
+	}
+	var cert agreement.Certificate
+	cert.Proposal.OriginalProposer = l.proposers[rnd]
+	return l.blocks[rnd], cert, nil
+}
+
+func (l *upgradeTestLedger) Lookup(rnd basics.Round, addr basics.Address) (basics.AccountData, error) {
+	return l.accounts[addr], nil
+}
+
+func (l *upgradeTestLedger) Totals(rnd basics.Round) (ledger.AccountTotals, error) {
+	var totals ledger.AccountTotals
+	blk := l.blocks[rnd]
+	for _, data := range l.accounts {
+		if data.Status == basics.Online {
+			data = data.WithUpdatedRewards(config.Consensus[blk.CurrentProtocol], blk.RewardsLevel)
+			totals.Online.Money.Raw += data.MicroAlgos.Raw
+		}
+	}
+	return totals, nil
+}
+
+func TestUpgradeStatus(t *testing.T) {
+	current := protocol.ConsensusCurrentVersion
+	next := protocol.ConsensusVersion("test-next")
+
+	var alice, bob, carol basics.Address
+	alice[0], bob[0], carol[0] = 1, 2, 3
+
+	l := &upgradeTestLedger{
+		accounts: map[basics.Address]basics.AccountData{
+			alice: {Status: basics.Online, MicroAlgos: basics.MicroAlgos{Raw: 300e6}},
+			bob:   {Status: basics.Online, MicroAlgos: basics.MicroAlgos{Raw: 200e6}},
+			carol: {Status: basics.Offline, MicroAlgos: basics.MicroAlgos{Raw: 1000}},
+		},
+	}
+	addBlock := func(proposer basics.Address, vote bookkeeping.UpgradeVote, state bookkeeping.UpgradeState) {
+		var blk bookkeeping.Block
+		blk.BlockHeader.Round = basics.Round(len(l.blocks))
+		blk.UpgradeVote = vote
+		blk.UpgradeState = state
+		// every whole algo has earned 2 microalgos of rewards since genesis
+		blk.RewardsLevel = 2
+		l.blocks = append(l.blocks, blk)
+		l.proposers = append(l.proposers, proposer)
+	}
+
+	idle := bookkeeping.UpgradeState{CurrentProtocol: current}
+	voting := bookkeeping.UpgradeState{CurrentProtocol: current, NextProtocol: next, NextProtocolVoteBefore: 100, NextProtocolSwitchOn: 200}
+
+	addBlock(basics.Address{}, bookkeeping.UpgradeVote{}, idle)
+	// bob first stays on the current version, then approves the upgrade
+	addBlock(bob, bookkeeping.UpgradeVote{}, idle)
+	addBlock(alice, bookkeeping.UpgradeVote{UpgradePropose: next}, idle)
+	voting.NextProtocolApprovals = 1
+	addBlock(bob, bookkeeping.UpgradeVote{UpgradeApprove: true}, voting)
+	addBlock(carol, bookkeeping.UpgradeVote{}, voting)
+
+	status, err := upgradeStatus(l)
+	require.NoError(t, err)
+	require.Equal(t, basics.Round(4), status.LastRound)
+	require.Equal(t, current, status.CurrentProtocol)
+	require.Equal(t, next, status.NextProtocol)
+	require.Equal(t, uint64(1), status.NextProtocolApprovals)
+	require.Equal(t, basics.Round(100), status.NextProtocolVoteBefore)
+	require.Equal(t, basics.Round(200), status.NextProtocolSwitchOn)
+	require.False(t, status.NextProtocolSupported)
+	require.NotZero(t, status.UpgradeThreshold)
+	require.Equal(t, basics.Round(1), status.FirstSampledRound)
+	require.Equal(t, basics.MicroAlgos{Raw: 500e6 + 1000}, status.OnlineStake)
+	require.Equal(t, []UpgradeSupport{
+		{Version: next, Proposers: 2, Blocks: 2, OnlineStake: basics.MicroAlgos{Raw: 500e6 + 1000}},
+		{Version: current, Proposers: 1, Blocks: 2, OnlineStake: basics.MicroAlgos{}, Supported: true},
+	}, status.Support)
+
+	// blocks that are no longer available are left out of the tally
+	l.earliest = 3
+	status, err = upgradeStatus(l)
+	require.NoError(t, err)
+	require.Equal(t, basics.Round(3), status.FirstSampledRound)
+	require.Equal(t, []UpgradeSupport{
+		{Version: next, Proposers: 1, Blocks: 1, OnlineStake: basics.MicroAlgos{Raw: 200e6 + 400}},
+		{Version: current, Proposers: 1, Blocks: 1, OnlineStake: basics.MicroAlgos{}, Supported: true},
+	}, status.Support)
+}
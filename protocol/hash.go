@@ -31,17 +31,23 @@ const (
 	AgreementSelector HashID = "AS"
 	BlockHeader       HashID = "BH"
 	BalanceRecord     HashID = "BR"
+	Catchpoint        HashID = "CP"
 	Credential        HashID = "CR"
 	Genesis           HashID = "GE"
 	Message           HashID = "MX"
+	NetIdentityProof  HashID = "NIP"
+	NetIdentityReply  HashID = "NIR"
 	NetPrioResponse   HashID = "NPR"
 	OneTimeSigKey1    HashID = "OT1"
 	OneTimeSigKey2    HashID = "OT2"
 	PaysetFlat        HashID = "PF"
 	Payload           HashID = "PL"
+	Program           HashID = "Program"
+	ProgramData       HashID = "ProgData"
 	ProposerSeed      HashID = "PS"
 	Seed              HashID = "SD"
 	TestHashable      HashID = "TE"
+	TxGroup           HashID = "TG"
 	Transaction       HashID = "TX"
 	Vote              HashID = "VO"
 )
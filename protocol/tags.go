@@ -25,6 +25,7 @@ const (
 	UnknownMsgTag      Tag = "??"
 	AgreementVoteTag   Tag = "AV"
 	MsgSkipTag         Tag = "MS"
+	NetIdentityTag     Tag = "NI"
 	NetPrioResponseTag Tag = "NP"
 	PingTag            Tag = "pi"
 	PingReplyTag       Tag = "pj"
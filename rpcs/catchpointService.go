@@ -0,0 +1,136 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package rpcs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/algorand/go-algorand/data"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/ledger"
+	"github.com/algorand/go-algorand/logging"
+	"github.com/algorand/go-algorand/network"
+)
+
+const catchpointResponseContentType = "application/x-algorand-catchpoint-v1"
+
+// CatchpointServiceCatchpointPath is the path to register CatchpointService as a handler for when using gorilla/mux
+const CatchpointServiceCatchpointPath = "/v{version:[0-9.]+}/{genesisID}/catchpoint/{round:[0-9a-z]+}"
+
+// CatchpointService serves the catchpoint files written by the ledger
+type CatchpointService struct {
+	ledger    *data.Ledger
+	genesisID string
+}
+
+// RegisterCatchpointService creates a CatchpointService around the provided Ledger and registers it for RPC with the provided Registrar
+func RegisterCatchpointService(ledger *data.Ledger, registrar Registrar, genesisID string) *CatchpointService {
+	service := &CatchpointService{ledger: ledger, genesisID: genesisID}
+	registrar.RegisterHTTPHandler(CatchpointServiceCatchpointPath, service)
+	return service
+}
+
+// ServeHTTP returns the catchpoint file for /v{version}/{genesisID}/catchpoint/{round}
+func (cs *CatchpointService) ServeHTTP(response http.ResponseWriter, request *http.Request) {
+	pathVars := mux.Vars(request)
+	if pathVars["version"] != "1" {
+		logging.Base().Debug("http catchpoint bad version", pathVars["version"])
+		response.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if pathVars["genesisID"] != cs.genesisID {
+		logging.Base().Debugf("http catchpoint bad genesisID mine=%#v theirs=%#v", cs.genesisID, pathVars["genesisID"])
+		response.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	round, err := strconv.ParseUint(pathVars["round"], 36, 64)
+	if err != nil {
+		logging.Base().Debug("http catchpoint round parse fail", pathVars["round"], err)
+		response.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	f, err := cs.ledger.OpenCatchpointFile(basics.Round(round))
+	if err != nil {
+		response.Header().Set("Cache-Control", ledgerResponseMissingBlockCacheControl)
+		response.WriteHeader(http.StatusNotFound)
+		return
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		logging.Base().Warnf("CatchpointService: failed to stat catchpoint %d: %v", round, err)
+		response.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	response.Header().Set("Content-Type", catchpointResponseContentType)
+	response.Header().Set("Content-Length", strconv.FormatInt(stat.Size(), 10))
+	response.Header().Set("Cache-Control", ledgerResponseHasBlockCacheControl)
+	response.WriteHeader(http.StatusOK)
+	_, err = io.Copy(response, f)
+	if err != nil {
+		logging.Base().Warn("http catchpoint write failed ", err)
+	}
+}
+
+// FetchCatchpoint downloads the catchpoint for round r from peer and
+// decodes it.  The caller must check the catchpoint digest against a
+// trusted label.
+func FetchCatchpoint(ctx context.Context, log logging.Logger, peer network.HTTPPeer, r basics.Round) (*ledger.Catchpoint, error) {
+	parsedURL, err := network.ParseHostOrURL(peer.GetAddress())
+	if err != nil {
+		return nil, err
+	}
+	parsedURL.Path = peer.PrepareURL(path.Join(parsedURL.Path, "/v1/{genesisID}/catchpoint/"+strconv.FormatUint(uint64(r), 36)))
+	catchpointURL := parsedURL.String()
+	log.Debugf("catchpoint GET %#v peer %#v %T", catchpointURL, peer, peer)
+	request, err := http.NewRequest("GET", catchpointURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	request = request.WithContext(ctx)
+	response, err := peer.GetHTTPClient().Do(request)
+	if err != nil {
+		log.Debugf("GET %#v : %s", catchpointURL, err)
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("peer %s does not have catchpoint %d", peer.GetAddress(), r)
+	default:
+		return nil, fmt.Errorf("FetchCatchpoint error response status code %d", response.StatusCode)
+	}
+
+	contentType := response.Header.Get("Content-Type")
+	if contentType != catchpointResponseContentType {
+		return nil, fmt.Errorf("http catchpoint fetcher invalid content type '%s'", contentType)
+	}
+
+	return ledger.ReadCatchpoint(response.Body)
+}
@@ -158,6 +158,14 @@ func (ls *LedgerService) ServeHTTP(response http.ResponseWriter, request *http.R
 			response.Header().Set("Cache-Control", ledgerResponseMissingBlockCacheControl)
 			response.WriteHeader(http.StatusNotFound)
 			return
+		case ledger.ErrRoundPruned:
+			// entry was deleted by a non-archival node; report why,
+			// but keep the status that fetchers treat as a missing block.
+			response.Header().Set("Cache-Control", ledgerResponseMissingBlockCacheControl)
+			response.Header().Set("Content-Type", "text/plain")
+			response.WriteHeader(http.StatusNotFound)
+			response.Write([]byte(err.Error()))
+			return
 		default:
 			// unexpected error.
 			logging.Base().Warnf("ServeHTTP : failed to retrieve block %d %v", round, err)
@@ -146,20 +146,42 @@ func (txs *TxService) getFilteredTxns(bloom *bloom.Filter) (txns []transactions.
 
 	missingTxns := make([]transactions.SignedTxn, 0)
 	encodedLength := 0
-	for _, tx := range pendingTxns {
-		id := tx.ID()
-		if !bloom.Test(id[:]) {
-			txLength := tx.GetEncodedLength()
-			if encodedLength+txLength > txs.responseSizeLimit {
-				break
+	for _, txgroup := range splitTxGroups(pendingTxns) {
+		// a transaction group is sent as a whole if any of its members is missing.
+		missing := false
+		txGroupLength := 0
+		for _, tx := range txgroup {
+			id := tx.ID()
+			if !bloom.Test(id[:]) {
+				missing = true
 			}
-			missingTxns = append(missingTxns, tx)
-			encodedLength += txLength
+			txGroupLength += tx.GetEncodedLength()
 		}
+		if !missing {
+			continue
+		}
+		if encodedLength+txGroupLength > txs.responseSizeLimit {
+			break
+		}
+		missingTxns = append(missingTxns, txgroup...)
+		encodedLength += txGroupLength
 	}
 	return missingTxns
 }
 
+// splitTxGroups splits a list of transactions into transaction groups,
+// assuming that the members of each group appear consecutively.
+func splitTxGroups(txns []transactions.SignedTxn) (txgroups [][]transactions.SignedTxn) {
+	for i, tx := range txns {
+		if i > 0 && !tx.Txn.Group.IsZero() && tx.Txn.Group == txns[i-1].Txn.Group {
+			txgroups[len(txgroups)-1] = append(txgroups[len(txgroups)-1], tx)
+			continue
+		}
+		txgroups = append(txgroups, []transactions.SignedTxn{tx})
+	}
+	return
+}
+
 func (txs *TxService) updateTxCache() (pendingTxns []transactions.SignedTxn) {
 	currentUnixTime := time.Now().Unix()
 	txs.mu.RLock()
@@ -133,17 +133,24 @@ func (syncer *TxSyncer) syncFromClient(client TxSyncClient) error {
 	}
 
 	// test to see if all the transaction that we've received honor the bloom filter constraints
-	// that we've requested.
-	for _, tx := range txns {
-		tx.InitCaches()
-		txID := tx.ID()
-		if filter.Test(txID[:]) {
-			// we just found a transaction that shouldn't have been included in the response.
+	// that we've requested. a transaction group is included as a whole, so at least one of its
+	// members has to be missing from the bloom filter.
+	for _, txgroup := range splitTxGroups(txns) {
+		missing := false
+		for i := range txgroup {
+			txgroup[i].InitCaches()
+			txID := txgroup[i].ID()
+			if !filter.Test(txID[:]) {
+				missing = true
+			}
+		}
+		if !missing {
+			// we just found a transaction group that shouldn't have been included in the response.
 			client.Close()
 			return fmt.Errorf("TxSyncer.Sync: peer %v sent a transaction that was included in the bloom filter", client.Address())
 		}
-		// send the transaction to the trasaction pool
-		if syncer.handler.Handle(tx) != nil {
+		// send the transaction group to the trasaction pool
+		if syncer.handler.Handle(txgroup) != nil {
 			client.Close()
 			return fmt.Errorf("TxSyncer.Sync: peer %v sent invalid transaction", client.Address())
 		}
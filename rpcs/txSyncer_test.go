@@ -81,7 +81,7 @@ type mockHandler struct {
 	err            error
 }
 
-func (handler *mockHandler) Handle(txn transactions.SignedTxn) error {
+func (handler *mockHandler) Handle(txgroup []transactions.SignedTxn) error {
 	atomic.AddInt32(&handler.messageCounter, 1)
 	return handler.err
 }
@@ -0,0 +1,236 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/algorand/go-algorand/agreement"
+	"github.com/algorand/go-algorand/agreement/fuzzer"
+	"github.com/algorand/go-algorand/config"
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/data"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/data/committee"
+	"github.com/algorand/go-algorand/data/pools"
+	"github.com/algorand/go-algorand/ledger"
+	"github.com/algorand/go-algorand/logging"
+	"github.com/algorand/go-algorand/protocol"
+	"github.com/algorand/go-algorand/util/execpool"
+)
+
+// simNode is a full node of the simulated network: a ledger and a
+// transaction pool, with the agreement service run by the fuzzer on top.
+type simNode struct {
+	ledger *nodeLedger
+	pool   *pools.TransactionPool
+}
+
+// nodeLedger implements fuzzer.NodeLedger over a data.Ledger. Blocks that
+// agreement certifies without the node having them are copied from the
+// ledgers of the other nodes through the fuzzer.
+type nodeLedger struct {
+	*data.Ledger
+	*fuzzer.DigestSync
+
+	sync fuzzer.LedgerSyncFunc
+}
+
+// EnsureBlock implements agreement.LedgerWriter.EnsureBlock.
+func (l *nodeLedger) EnsureBlock(e bookkeeping.Block, c agreement.Certificate) {
+	l.Ledger.EnsureBlock(&e, c)
+}
+
+// EnsureValidatedBlock implements agreement.LedgerWriter.EnsureValidatedBlock.
+func (l *nodeLedger) EnsureValidatedBlock(ve agreement.ValidatedBlock, c agreement.Certificate) {
+	l.Ledger.EnsureValidatedBlock(ve.(validatedBlock).vb, c)
+}
+
+// EnsureDigest implements agreement.LedgerWriter.EnsureDigest.
+func (l *nodeLedger) EnsureDigest(c agreement.Certificate, quit chan struct{}, verifier *agreement.AsyncVoteVerifier) {
+	if c.Round < l.NextRound() {
+		// the block is already there; if it doesn't match the certificate, the report will show the fork.
+		return
+	}
+	l.DigestSync.EnsureDigest(func() bool {
+		return l.sync(l, c.Round, c)
+	}, quit)
+}
+
+// ClearNotifications implements fuzzer.NodeLedger.ClearNotifications. The
+// notifications of a data.Ledger are safe to keep across agreement restarts.
+func (l *nodeLedger) ClearNotifications() {
+}
+
+// Catchup implements fuzzer.NodeLedger.Catchup.
+func (l *nodeLedger) Catchup(source fuzzer.NodeLedger, targetNextRound basics.Round) {
+	o := source.(*nodeLedger)
+	for r := l.NextRound(); r < targetNextRound; r++ {
+		blk, cert, err := o.BlockCert(r)
+		if err != nil {
+			logging.Base().Errorf("simulator: unable to copy block %d: %v", r, err)
+			return
+		}
+		l.Ledger.EnsureBlock(&blk, cert)
+	}
+	<-l.Wait(targetNextRound - 1)
+}
+
+// validatedBlock satisfies agreement.ValidatedBlock
+type validatedBlock struct {
+	vb *ledger.ValidatedBlock
+}
+
+// WithSeed satisfies the agreement.ValidatedBlock interface.
+func (vb validatedBlock) WithSeed(s committee.Seed) agreement.ValidatedBlock {
+	lvb := vb.vb.WithSeed(s)
+	return validatedBlock{vb: &lvb}
+}
+
+// Block satisfies the agreement.ValidatedBlock interface.
+func (vb validatedBlock) Block() bookkeeping.Block {
+	return vb.vb.Block()
+}
+
+// blockFactory assembles the proposals of a node out of its transaction pool.
+type blockFactory struct {
+	l                *data.Ledger
+	tp               *pools.TransactionPool
+	verificationPool execpool.BacklogPool
+
+	// now returns the time of the virtual clock, in seconds since the epoch.
+	now func() int64
+}
+
+// AssembleBlock implements agreement.BlockFactory.AssembleBlock.
+func (f blockFactory) AssembleBlock(round basics.Round, deadline time.Time) (agreement.ValidatedBlock, error) {
+	prev, err := f.l.BlockHdr(round - 1)
+	if err != nil {
+		return nil, fmt.Errorf("could not make proposals at round %d: could not read block from ledger: %v", round, err)
+	}
+
+	// MakeBlock stamps the block with the wall clock; stamp it with the
+	// virtual clock instead, within the same bounds, so that a run repeats.
+	hdr := bookkeeping.MakeBlock(prev).BlockHeader
+	hdr.TimeStamp = f.now()
+	if prev.TimeStamp > 0 {
+		maxIncrement := config.Consensus[hdr.CurrentProtocol].MaxTimestampIncrement
+		if hdr.TimeStamp < prev.TimeStamp {
+			hdr.TimeStamp = prev.TimeStamp
+		} else if hdr.TimeStamp > prev.TimeStamp+maxIncrement {
+			hdr.TimeStamp = prev.TimeStamp + maxIncrement
+		}
+	}
+
+	eval, err := f.l.StartEvaluator(hdr, f.tp, f.verificationPool)
+	if err != nil {
+		return nil, fmt.Errorf("could not make proposals at round %d: could not start evaluator: %v", round, err)
+	}
+	f.l.AssemblePayset(f.tp, eval, deadline)
+
+	lvb, err := eval.GenerateBlock()
+	if err != nil {
+		return nil, fmt.Errorf("could not make proposals at round %d: could not finish evaluator: %v", round, err)
+	}
+	return validatedBlock{vb: lvb}, nil
+}
+
+// blockValidator validates the proposals a node receives against its ledger.
+type blockValidator struct {
+	l                *data.Ledger
+	tp               *pools.TransactionPool
+	verificationPool execpool.BacklogPool
+}
+
+// Validate implements agreement.BlockValidator.Validate.
+func (v blockValidator) Validate(ctx context.Context, e bookkeeping.Block) (agreement.ValidatedBlock, error) {
+	lvb, err := v.l.Validate(ctx, e, v.tp, v.verificationPool)
+	if err != nil {
+		return nil, err
+	}
+	return validatedBlock{vb: lvb}, nil
+}
+
+// genesis holds what every node of the network needs to create its ledger.
+type genesis struct {
+	id          string
+	hash        crypto.Digest
+	proto       protocol.ConsensusVersion
+	timestamp   int64
+	feeSink     basics.Address
+	rewardsPool basics.Address
+	accounts    map[basics.Address]basics.AccountData
+}
+
+// makeGenesis creates the genesis of a network at the given Unix time,
+// where every payer holds payerBalance microalgos.
+func makeGenesis(name string, timestamp int64, payers []*crypto.SignatureSecrets, payerBalance uint64) genesis {
+	g := genesis{
+		id:          name + "-v1",
+		hash:        crypto.Hash([]byte(name)),
+		proto:       protocol.ConsensusCurrentVersion,
+		timestamp:   timestamp,
+		feeSink:     basics.Address(crypto.Hash([]byte(name + " fee sink"))),
+		rewardsPool: basics.Address(crypto.Hash([]byte(name + " rewards pool"))),
+		accounts:    make(map[basics.Address]basics.AccountData),
+	}
+	proto := config.Consensus[g.proto]
+	g.accounts[g.feeSink] = basics.MakeAccountData(basics.NotParticipating, basics.MicroAlgos{Raw: proto.MinBalance})
+	g.accounts[g.rewardsPool] = basics.MakeAccountData(basics.NotParticipating, basics.MicroAlgos{Raw: 100000 * proto.RewardsRateRefreshInterval})
+	for _, payer := range payers {
+		g.accounts[basics.Address(payer.SignatureVerifier)] = basics.MakeAccountData(basics.Offline, basics.MicroAlgos{Raw: payerBalance})
+	}
+	return g
+}
+
+// nodeFactory returns the fuzzer.NodeFactory creating the full nodes of the
+// simulator, with in-memory ledgers under the given prefix.
+func (s *Simulator) nodeFactory(prefix string) fuzzer.NodeFactory {
+	return func(nodeID int, balances map[basics.Address]basics.BalanceRecord, sync fuzzer.LedgerSyncFunc) (fuzzer.NodeComponents, error) {
+		accounts := make(map[basics.Address]basics.AccountData, len(s.genesis.accounts)+len(balances))
+		for addr, ad := range s.genesis.accounts {
+			accounts[addr] = ad
+		}
+		for addr, rec := range balances {
+			accounts[addr] = rec.AccountData
+		}
+		bootstrap := data.MakeTimestampedGenesisBalances(accounts, s.genesis.feeSink, s.genesis.rewardsPool, s.genesis.timestamp)
+
+		dbPrefix := fmt.Sprintf("%s-node%d", prefix, nodeID)
+		l, err := data.LoadLedger(logging.Base(), dbPrefix, true, s.genesis.proto, bootstrap, s.genesis.id, s.genesis.hash, nil)
+		if err != nil {
+			return fuzzer.NodeComponents{}, err
+		}
+		cfg := config.GetDefaultLocal()
+		pool := pools.MakeTransactionPool(l, cfg.TxPoolExponentialIncreaseFactor, cfg.TxPoolSize, false)
+		l.RegisterBlockListeners([]ledger.BlockListener{pool})
+
+		node := &simNode{
+			ledger: &nodeLedger{Ledger: l, DigestSync: fuzzer.MakeDigestSync(), sync: sync},
+			pool:   pool,
+		}
+		s.nodes[nodeID] = node
+		return fuzzer.NodeComponents{
+			Ledger:         node.ledger,
+			BlockFactory:   blockFactory{l: l, tp: pool, verificationPool: s.verificationPool, now: s.virtualTime},
+			BlockValidator: blockValidator{l: l, tp: pool, verificationPool: s.verificationPool},
+		}, nil
+	}
+}
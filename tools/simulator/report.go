@@ -0,0 +1,131 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package simulator
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/algorand/go-algorand/data/basics"
+)
+
+// Report summarizes how the network fared through a scenario. The times are
+// virtual, measured in steps of the fuzzer clock.
+type Report struct {
+	Scenario string
+	Duration time.Duration
+
+	// Rounds is the highest round reached by any node, and NodeRounds the
+	// latest round of every node.
+	Rounds     basics.Round
+	NodeRounds []basics.Round
+
+	TxnsSubmitted int
+	TxnsCommitted int
+	// TxnsRejected counts the payments none of the running nodes accepted.
+	TxnsRejected int
+
+	// FinalityLatency is the time from the submission of a payment until
+	// the first node commits the block holding it.
+	FinalityLatency LatencyStats
+	// RoundTime is the time between two consecutive rounds, as first
+	// committed by any node.
+	RoundTime LatencyStats
+
+	Forks  []Fork
+	Stalls []Stall
+}
+
+// LatencyStats summarizes a set of durations.
+type LatencyStats struct {
+	Count int
+	Min   time.Duration
+	Mean  time.Duration
+	P50   time.Duration
+	P95   time.Duration
+	Max   time.Duration
+}
+
+// Fork is a round for which some nodes committed a different block than the
+// first node which committed the round.
+type Fork struct {
+	Round basics.Round
+	Nodes []int
+}
+
+// Stall is a period over which none of the nodes committed a new round.
+type Stall struct {
+	Start    time.Duration
+	Duration time.Duration
+	// Round is the latest round committed before the stall.
+	Round basics.Round
+}
+
+func makeLatencyStats(samples []time.Duration) (stats LatencyStats) {
+	if len(samples) == 0 {
+		return
+	}
+	sorted := append([]time.Duration{}, samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum time.Duration
+	for _, d := range sorted {
+		sum += d
+	}
+	stats.Count = len(sorted)
+	stats.Min = sorted[0]
+	stats.Max = sorted[len(sorted)-1]
+	stats.Mean = sum / time.Duration(len(sorted))
+	stats.P50 = sorted[(len(sorted)-1)*50/100]
+	stats.P95 = sorted[(len(sorted)-1)*95/100]
+	return
+}
+
+func (s LatencyStats) String() string {
+	if s.Count == 0 {
+		return "no samples"
+	}
+	return fmt.Sprintf("min %v, mean %v, p50 %v, p95 %v, max %v (%d samples)", s.Min, s.Mean.Round(time.Millisecond), s.P50, s.P95, s.Max, s.Count)
+}
+
+// WriteText writes the report in a human readable form.
+func (r *Report) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "Scenario:          %s\n", r.Scenario)
+	fmt.Fprintf(w, "Virtual time:      %v\n", r.Duration)
+	fmt.Fprintf(w, "Rounds:            %d (per node: %v)\n", r.Rounds, r.NodeRounds)
+	fmt.Fprintf(w, "Transactions:      %d submitted, %d committed, %d rejected\n", r.TxnsSubmitted, r.TxnsCommitted, r.TxnsRejected)
+	fmt.Fprintf(w, "Finality latency:  %v\n", r.FinalityLatency)
+	fmt.Fprintf(w, "Round time:        %v\n", r.RoundTime)
+	if len(r.Forks) == 0 {
+		fmt.Fprintf(w, "Forks:             none\n")
+	} else {
+		fmt.Fprintf(w, "Forks:             %d\n", len(r.Forks))
+		for _, f := range r.Forks {
+			fmt.Fprintf(w, "  round %d: nodes %v disagree with the first block committed\n", f.Round, f.Nodes)
+		}
+	}
+	if len(r.Stalls) == 0 {
+		fmt.Fprintf(w, "Stalls:            none\n")
+	} else {
+		fmt.Fprintf(w, "Stalls:            %d\n", len(r.Stalls))
+		for _, s := range r.Stalls {
+			fmt.Fprintf(w, "  at %v after round %d, for %v\n", s.Start, s.Round, s.Duration)
+		}
+	}
+}
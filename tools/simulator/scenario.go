@@ -0,0 +1,204 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/algorand/go-algorand/agreement/fuzzer"
+)
+
+// maxNodes is the number of participation keys the fuzzer has ready.
+const maxNodes = 64
+
+// defaultGenesisTimestamp is the genesis time of scenarios that do not set
+// one: midnight UTC on January 1st, 2020.
+const defaultGenesisTimestamp = 1577836800
+
+// Scenario describes a simulated network, the load it carries and the faults
+// it goes through. All the times are in the virtual time of the simulation.
+type Scenario struct {
+	Name  string `yaml:"name"`
+	Nodes int    `yaml:"nodes"`
+
+	// Duration is how long the network runs for.
+	Duration time.Duration `yaml:"duration"`
+
+	// StallThreshold is how long the network may go without a new round
+	// before the report counts a stall.
+	StallThreshold time.Duration `yaml:"stallThreshold"`
+
+	// Accounts is the number of funded accounts sending payments to each other.
+	Accounts int `yaml:"accounts"`
+	// TxnRate is the number of payments submitted per second.
+	TxnRate float64 `yaml:"txnRate"`
+	// Seed drives the choice of the payments, so that runs are repeatable.
+	Seed int64 `yaml:"seed"`
+	// GenesisTimestamp is the Unix time of the genesis block. It does not
+	// depend on when the simulation runs, so that runs are repeatable.
+	GenesisTimestamp int64 `yaml:"genesisTimestamp"`
+
+	// Filters are agreement fuzzer network filters applied to every node,
+	// in the format of the agreement/fuzzer testdata configurations.
+	Filters []interface{} `yaml:"filters"`
+
+	// Faults are applied in order of their At time.
+	Faults []Fault `yaml:"faults"`
+
+	// Traces keeps the agreement cadaver files of the nodes.
+	Traces bool `yaml:"traces"`
+}
+
+// Fault is a change of the network topology at a given time. A crashed node
+// is cut off from its peers and stops receiving transactions until it is
+// restarted, at which point its agreement service is recovered from its
+// crash state the way a restarted algod would.
+type Fault struct {
+	At time.Duration `yaml:"at"`
+
+	Crash   []int `yaml:"crash"`
+	Restart []int `yaml:"restart"`
+
+	// Partition splits the network in the listed groups of nodes. The
+	// nodes not listed form a group of their own.
+	Partition [][]int `yaml:"partition"`
+	// Heal removes the partitions.
+	Heal bool `yaml:"heal"`
+}
+
+// LoadScenario reads a YAML scenario file.
+func LoadScenario(filename string) (*Scenario, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	s, err := ParseScenario(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	return s, nil
+}
+
+// ParseScenario decodes a YAML scenario, filling in the defaults of the
+// fields left out, and validates it.
+func ParseScenario(b []byte) (*Scenario, error) {
+	s := &Scenario{
+		Name:             "simnet",
+		StallThreshold:   30 * time.Second,
+		Accounts:         10,
+		Seed:             1,
+		GenesisTimestamp: defaultGenesisTimestamp,
+	}
+	if err := yaml.UnmarshalStrict(b, s); err != nil {
+		return nil, err
+	}
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(s.Faults, func(i, j int) bool {
+		return s.Faults[i].At < s.Faults[j].At
+	})
+	return s, nil
+}
+
+func (s *Scenario) validate() error {
+	if s.Nodes <= 0 || s.Nodes > maxNodes {
+		return fmt.Errorf("nodes must be between 1 and %d, not %d", maxNodes, s.Nodes)
+	}
+	if s.Duration <= 0 {
+		return fmt.Errorf("duration must be positive")
+	}
+	if s.StallThreshold <= 0 {
+		return fmt.Errorf("stallThreshold must be positive")
+	}
+	if s.GenesisTimestamp < 0 {
+		return fmt.Errorf("genesisTimestamp cannot be negative")
+	}
+	if s.TxnRate < 0 {
+		return fmt.Errorf("txnRate cannot be negative")
+	}
+	if s.TxnRate > 0 && s.Accounts < 2 {
+		return fmt.Errorf("payments need at least 2 accounts, not %d", s.Accounts)
+	}
+	for i, f := range s.Faults {
+		if f.At < 0 || f.At > s.Duration {
+			return fmt.Errorf("fault %d: at %v is outside of the scenario duration", i, f.At)
+		}
+		nodes := append(append([]int{}, f.Crash...), f.Restart...)
+		seen := make(map[int]bool)
+		for _, group := range f.Partition {
+			for _, node := range group {
+				if seen[node] {
+					return fmt.Errorf("fault %d: node %d is in more than one partition", i, node)
+				}
+				seen[node] = true
+			}
+			nodes = append(nodes, group...)
+		}
+		for _, node := range nodes {
+			if node < 0 || node >= s.Nodes {
+				return fmt.Errorf("fault %d: there is no node %d", i, node)
+			}
+		}
+	}
+	_, err := s.filterFactories()
+	return err
+}
+
+// filterFactories creates the fuzzer filters of the scenario.
+func (s *Scenario) filterFactories() ([]fuzzer.NetworkFilterFactory, error) {
+	factories := make([]fuzzer.NetworkFilterFactory, 0, len(s.Filters))
+	for i, filter := range s.Filters {
+		b, err := json.Marshal(jsonValue(filter))
+		if err != nil {
+			return nil, fmt.Errorf("filter %d: %v", i, err)
+		}
+		factory := fuzzer.UnmarshalFilter(b)
+		if factory == nil {
+			return nil, fmt.Errorf("filter %d is not a valid network filter: %s", i, b)
+		}
+		factories = append(factories, factory)
+	}
+	return factories, nil
+}
+
+// jsonValue converts the maps decoded from YAML, which may have keys of any
+// type, into maps which encoding/json accepts.
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[fmt.Sprint(key)] = jsonValue(value)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, value := range v {
+			l[i] = jsonValue(value)
+		}
+		return l
+	default:
+		return v
+	}
+}
@@ -0,0 +1,377 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package simulator
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand"
+	"path/filepath"
+	"time"
+
+	"github.com/algorand/go-algorand/agreement/fuzzer"
+	"github.com/algorand/go-algorand/config"
+	"github.com/algorand/go-algorand/crypto"
+	"github.com/algorand/go-algorand/data/basics"
+	"github.com/algorand/go-algorand/data/bookkeeping"
+	"github.com/algorand/go-algorand/data/transactions"
+	"github.com/algorand/go-algorand/logging"
+	"github.com/algorand/go-algorand/protocol"
+	"github.com/algorand/go-algorand/util/execpool"
+)
+
+// payerBalance is the genesis balance of every account sending payments.
+const payerBalance = 1000000000000
+
+// Simulator runs a network of full nodes through a scenario. The agreement
+// services of the nodes exchange their messages through the agreement
+// fuzzer, which applies the network filters of the scenario and drives a
+// virtual clock that only moves forward once every message has been handled,
+// so that a scenario plays out much the same way however fast the machine is.
+//
+// Transactions are not gossiped: a payment is handed to the pools of all
+// the running nodes at once.
+type Simulator struct {
+	scenario *Scenario
+	fuzzer   *fuzzer.Fuzzer
+	nodes    []*simNode
+	genesis  genesis
+	payers   []*crypto.SignatureSecrets
+	rand     *rand.Rand
+
+	cryptoPool       execpool.ExecutionPool
+	verificationPool execpool.BacklogPool
+
+	crashed    []bool
+	restarting []bool
+	partition  []int
+	nextFault  int
+	txnBudget  float64
+	txnCounter uint64
+
+	report       Report
+	pending      map[transactions.Txid]int
+	scanned      []basics.Round
+	rounds       map[basics.Round]roundInfo
+	forks        map[basics.Round][]int
+	lastProgress int
+	stallStart   int
+	latencies    []time.Duration
+	roundTimes   []time.Duration
+}
+
+// roundInfo is the first block committed by any of the nodes for a round.
+type roundInfo struct {
+	digest crypto.Digest
+	tick   int
+}
+
+// MakeSimulator creates the network of a scenario. The logs of the nodes,
+// and their agreement traces when enabled, go into dataDir.
+func MakeSimulator(scenario *Scenario, dataDir string) (*Simulator, error) {
+	filters, err := scenario.filterFactories()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Simulator{
+		scenario:   scenario,
+		nodes:      make([]*simNode, scenario.Nodes),
+		rand:       rand.New(rand.NewSource(scenario.Seed)),
+		crashed:    make([]bool, scenario.Nodes),
+		restarting: make([]bool, scenario.Nodes),
+		partition:  make([]int, scenario.Nodes),
+		pending:    make(map[transactions.Txid]int),
+		scanned:    make([]basics.Round, scenario.Nodes),
+		rounds:     make(map[basics.Round]roundInfo),
+		forks:      make(map[basics.Round][]int),
+		stallStart: -1,
+	}
+	for i := 0; i < scenario.Accounts; i++ {
+		seed := crypto.Seed(crypto.Hash([]byte(fmt.Sprintf("%s-payer-%d", scenario.Name, i))))
+		s.payers = append(s.payers, crypto.GenerateSignatureSecrets(seed))
+	}
+	for i := range s.scanned {
+		s.scanned[i] = 1
+	}
+	s.genesis = makeGenesis(scenario.Name, scenario.GenesisTimestamp, s.payers, payerBalance)
+	s.cryptoPool = execpool.MakePool(s)
+	s.verificationPool = execpool.MakeBacklog(s.cryptoPool, 2*s.cryptoPool.GetParallelism(), execpool.HighPriority, s)
+
+	name := filepath.Join(dataDir, scenario.Name)
+	s.fuzzer = fuzzer.MakeFuzzer(fuzzer.FuzzerConfig{
+		FuzzerName:    name,
+		NodesCount:    scenario.Nodes,
+		Filters:       filters,
+		LogLevel:      logging.Info,
+		DisableTraces: !scenario.Traces,
+		NodeFactory:   s.nodeFactory(name),
+	})
+	if s.fuzzer == nil {
+		s.closeNodes()
+		return nil, fmt.Errorf("unable to create the network, see %s.log", name)
+	}
+	return s, nil
+}
+
+// Run plays the scenario and reports on how the network fared.
+func (s *Simulator) Run() *Report {
+	s.fuzzer.Start()
+
+	granularity := s.fuzzer.TickGranularity()
+	endTick := int(s.scenario.Duration / granularity)
+	stallTicks := int(s.scenario.StallThreshold / granularity)
+	s.lastProgress = s.fuzzer.WallClock()
+	for tick := s.fuzzer.WallClock(); tick < endTick; tick = s.fuzzer.WallClock() {
+		s.applyFaults(tick)
+		s.restartNodes()
+		s.submitPayments(tick)
+		// stalls are told from the rounds rather than from the network activity.
+		s.fuzzer.RunUntil(tick+1, math.MaxInt32)
+		s.observe(s.fuzzer.WallClock(), stallTicks)
+	}
+	s.closeStall(s.fuzzer.WallClock())
+
+	s.report.Scenario = s.scenario.Name
+	s.report.Duration = time.Duration(s.fuzzer.WallClock()) * granularity
+	s.report.NodeRounds = make([]basics.Round, len(s.nodes))
+	for i, node := range s.nodes {
+		s.report.NodeRounds[i] = node.ledger.Latest()
+		if s.report.NodeRounds[i] > s.report.Rounds {
+			s.report.Rounds = s.report.NodeRounds[i]
+		}
+	}
+	s.report.TxnsCommitted = len(s.latencies)
+	s.report.FinalityLatency = makeLatencyStats(s.latencies)
+	s.report.RoundTime = makeLatencyStats(s.roundTimes)
+	for r := basics.Round(1); r <= s.report.Rounds; r++ {
+		if nodes, has := s.forks[r]; has {
+			s.report.Forks = append(s.report.Forks, Fork{Round: r, Nodes: nodes})
+		}
+	}
+	return &s.report
+}
+
+// virtualTime returns the Unix time of the virtual clock: the genesis time
+// plus the virtual time that has passed since the start of the run.
+func (s *Simulator) virtualTime() int64 {
+	elapsed := time.Duration(s.fuzzer.WallClock()) * s.fuzzer.TickGranularity()
+	return s.genesis.timestamp + int64(elapsed/time.Second)
+}
+
+// Shutdown stops the nodes and releases their resources.
+func (s *Simulator) Shutdown() {
+	s.fuzzer.Shutdown()
+	s.closeNodes()
+}
+
+func (s *Simulator) closeNodes() {
+	for _, node := range s.nodes {
+		if node != nil {
+			node.ledger.Close()
+		}
+	}
+	s.verificationPool.Shutdown()
+	s.cryptoPool.Shutdown()
+}
+
+// applyFaults applies the faults which are due by the given tick.
+func (s *Simulator) applyFaults(tick int) {
+	granularity := s.fuzzer.TickGranularity()
+	faults := s.scenario.Faults
+	changed := false
+	for ; s.nextFault < len(faults) && int(faults[s.nextFault].At/granularity) <= tick; s.nextFault++ {
+		f := faults[s.nextFault]
+		for _, node := range f.Crash {
+			s.crashed[node] = true
+		}
+		for _, node := range f.Restart {
+			s.crashed[node] = false
+			s.restarting[node] = true
+		}
+		if f.Heal {
+			for i := range s.partition {
+				s.partition[i] = 0
+			}
+		}
+		if len(f.Partition) > 0 {
+			for i := range s.partition {
+				s.partition[i] = len(f.Partition)
+			}
+			for group, nodes := range f.Partition {
+				for _, node := range nodes {
+					s.partition[node] = group
+				}
+			}
+		}
+		changed = true
+	}
+	if !changed {
+		return
+	}
+	for a := 0; a < len(s.nodes); a++ {
+		for b := a + 1; b < len(s.nodes); b++ {
+			if !s.crashed[a] && !s.crashed[b] && s.partition[a] == s.partition[b] {
+				s.fuzzer.Reconnect(a, b)
+			} else {
+				s.fuzzer.Disconnect(a, b)
+			}
+		}
+	}
+}
+
+// restartNodes recovers the agreement service of the restarted nodes. A node
+// waiting on its ledger for a block is restarted once it has the block.
+func (s *Simulator) restartNodes() {
+	for i, restarting := range s.restarting {
+		if restarting && !s.nodes[i].ledger.IsEnsuringDigest() {
+			s.fuzzer.CrashNode(i)
+			s.restarting[i] = false
+		}
+	}
+}
+
+// submitPayments hands the payments due by the given tick to the running nodes.
+func (s *Simulator) submitPayments(tick int) {
+	s.txnBudget += s.scenario.TxnRate * s.fuzzer.TickGranularity().Seconds()
+	for ; s.txnBudget >= 1; s.txnBudget-- {
+		s.submitPayment(tick)
+	}
+}
+
+func (s *Simulator) submitPayment(tick int) {
+	var latest basics.Round
+	running := false
+	for i, node := range s.nodes {
+		if !s.crashed[i] {
+			running = true
+			if node.ledger.Latest() > latest {
+				latest = node.ledger.Latest()
+			}
+		}
+	}
+	if !running {
+		return
+	}
+
+	proto := config.Consensus[s.genesis.proto]
+	from := s.rand.Intn(len(s.payers))
+	to := (from + 1 + s.rand.Intn(len(s.payers)-1)) % len(s.payers)
+	s.txnCounter++
+	note := make([]byte, 8)
+	binary.BigEndian.PutUint64(note, s.txnCounter)
+	tx := transactions.Transaction{
+		Type: protocol.PaymentTx,
+		Header: transactions.Header{
+			Sender:      basics.Address(s.payers[from].SignatureVerifier),
+			Fee:         basics.MicroAlgos{Raw: proto.MinTxnFee},
+			FirstValid:  latest,
+			LastValid:   latest + basics.Round(proto.MaxTxnLife),
+			Note:        note,
+			GenesisID:   s.genesis.id,
+			GenesisHash: s.genesis.hash,
+		},
+		PaymentTxnFields: transactions.PaymentTxnFields{
+			Receiver: basics.Address(s.payers[to].SignatureVerifier),
+			Amount:   basics.MicroAlgos{Raw: uint64(1 + s.rand.Intn(1000))},
+		},
+	}
+	stxn := tx.Sign(s.payers[from])
+
+	s.report.TxnsSubmitted++
+	accepted := false
+	for i, node := range s.nodes {
+		if !s.crashed[i] && node.pool.Remember([]transactions.SignedTxn{stxn}) == nil {
+			accepted = true
+		}
+	}
+	if !accepted {
+		s.report.TxnsRejected++
+		return
+	}
+	s.pending[stxn.ID()] = tick
+}
+
+// observe records the blocks the nodes have committed since the last tick.
+func (s *Simulator) observe(tick, stallTicks int) {
+	granularity := s.fuzzer.TickGranularity()
+	progress := false
+	for i, node := range s.nodes {
+		for latest := node.ledger.Latest(); s.scanned[i] <= latest; s.scanned[i]++ {
+			r := s.scanned[i]
+			blk, err := node.ledger.Block(r)
+			if err != nil {
+				break
+			}
+			info, seen := s.rounds[r]
+			if !seen {
+				s.rounds[r] = roundInfo{digest: blk.Digest(), tick: tick}
+				if prev, has := s.rounds[r-1]; has {
+					s.roundTimes = append(s.roundTimes, time.Duration(tick-prev.tick)*granularity)
+				}
+				s.commit(blk, tick)
+				progress = true
+			} else if info.digest != blk.Digest() {
+				s.forks[r] = append(s.forks[r], i)
+			}
+		}
+	}
+
+	if progress {
+		s.closeStall(tick)
+		s.lastProgress = tick
+	} else if s.stallStart < 0 && tick-s.lastProgress > stallTicks {
+		s.stallStart = s.lastProgress
+	}
+}
+
+// commit records the finality latency of the payments in blk.
+func (s *Simulator) commit(blk bookkeeping.Block, tick int) {
+	payset, err := blk.DecodePayset()
+	if err != nil {
+		logging.Base().Errorf("simulator: unable to decode the payset of block %d: %v", blk.Round(), err)
+		return
+	}
+	for _, stxn := range payset {
+		txid := stxn.ID()
+		if submitted, has := s.pending[txid]; has {
+			s.latencies = append(s.latencies, time.Duration(tick-submitted)*s.fuzzer.TickGranularity())
+			delete(s.pending, txid)
+		}
+	}
+}
+
+// closeStall ends the ongoing stall, if any, at the given tick.
+func (s *Simulator) closeStall(tick int) {
+	if s.stallStart < 0 {
+		return
+	}
+	granularity := s.fuzzer.TickGranularity()
+	var round basics.Round
+	for r := range s.rounds {
+		if s.rounds[r].tick <= s.stallStart && r > round {
+			round = r
+		}
+	}
+	s.report.Stalls = append(s.report.Stalls, Stall{
+		Start:    time.Duration(s.stallStart) * granularity,
+		Duration: time.Duration(tick-s.stallStart) * granularity,
+		Round:    round,
+	})
+	s.stallStart = -1
+}
@@ -0,0 +1,98 @@
+// Copyright (C) 2019 Algorand, Inc.
+// This file is part of go-algorand
+//
+// go-algorand is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// go-algorand is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with go-algorand.  If not, see <https://www.gnu.org/licenses/>.
+
+package simulator
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScenario(t *testing.T) {
+	s, err := ParseScenario([]byte(`
+name: delayed
+nodes: 3
+duration: 1m
+txnRate: 2
+filters:
+  - name: MessageDelayFilter
+    upStreamTickDelay:
+      0:
+        "*": 2
+faults:
+  - at: 20s
+    heal: true
+  - at: 10s
+    partition: [[0, 1]]
+`))
+	require.NoError(t, err)
+	require.Equal(t, time.Minute, s.Duration)
+	require.Equal(t, 10, s.Accounts)
+	require.Equal(t, int64(defaultGenesisTimestamp), s.GenesisTimestamp)
+	require.Equal(t, 10*time.Second, s.Faults[0].At)
+	require.Equal(t, [][]int{{0, 1}}, s.Faults[0].Partition)
+	require.True(t, s.Faults[1].Heal)
+	filters, err := s.filterFactories()
+	require.NoError(t, err)
+	require.Len(t, filters, 1)
+
+	_, err = ParseScenario([]byte("nodes: 3\nduration: 1m\nfilters: [{name: NoSuchFilter}]\n"))
+	require.Error(t, err)
+	_, err = ParseScenario([]byte("nodes: 3\nduration: 1m\nfaults: [{at: 10s, crash: [3]}]\n"))
+	require.Error(t, err)
+	_, err = ParseScenario([]byte("nodes: 3\nduration: 1m\nunknown: 1\n"))
+	require.Error(t, err)
+	_, err = ParseScenario([]byte("nodes: 3\nduration: 1m\ngenesisTimestamp: -1\n"))
+	require.Error(t, err)
+}
+
+func TestSimulatorPartition(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dataDir, err := ioutil.TempDir("", "simulator")
+	require.NoError(t, err)
+	defer os.RemoveAll(dataDir)
+
+	s, err := LoadScenario("testdata/partition.yaml")
+	require.NoError(t, err)
+
+	sim, err := MakeSimulator(s, dataDir)
+	require.NoError(t, err)
+	report := sim.Run()
+
+	// blocks are stamped with the virtual clock, not the wall clock
+	for _, node := range sim.nodes {
+		hdr, err := node.ledger.BlockHdr(node.ledger.Latest())
+		require.NoError(t, err)
+		require.True(t, hdr.TimeStamp >= s.GenesisTimestamp)
+		require.True(t, hdr.TimeStamp <= s.GenesisTimestamp+int64(s.Duration/time.Second))
+	}
+	sim.Shutdown()
+
+	require.Empty(t, report.Forks)
+	require.NotEmpty(t, report.Stalls, "no quorum on either side of the partition")
+	require.True(t, report.Rounds > 5)
+	require.True(t, report.TxnsCommitted > 0)
+	require.True(t, report.FinalityLatency.Max > 0)
+	for _, r := range report.NodeRounds {
+		require.True(t, report.Rounds-r <= 1)
+	}
+}